@@ -0,0 +1,495 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// DefaultBatchTargetSize is the default buffered size at which a Batcher cuts a blob.
+const DefaultBatchTargetSize = 1 << 20
+
+// batchPointSize is the buffered size of one data point without its tag.
+const batchPointSize = 16
+
+// BatcherConfig holds Batcher cut limits and encoder settings.
+type BatcherConfig struct {
+	targetSize  int
+	maxDuration time.Duration
+	maxMetrics  int
+	lateness    time.Duration
+	encoderOpts []NumericEncoderOption
+}
+
+// BatcherOption represents a functional option for configuring a Batcher.
+type BatcherOption = options.Option[*BatcherConfig]
+
+// Batcher buffers data points from a stream and cuts them into numeric blobs.
+//
+// Points may arrive in any metric order: the batcher groups them per metric, starts
+// and ends metrics itself, and encodes a blob whenever a limit is reached. Finished
+// blobs are passed to the callback given to NewBatcher. A batcher identifies metrics
+// either by name (Add) or by ID (AddID) for its whole lifetime; mixing the two
+// returns ErrMixedIdentifierMode.
+//
+// A Batcher is not safe for concurrent use.
+type Batcher struct {
+	cfg       BatcherConfig
+	onBlob    func(data []byte) error
+	unit      format.TimeUnit
+	hasTag    bool
+	maxPoints int
+	mode      metricIdentifierMode
+
+	cur     *batch
+	late    map[int64]*batch // late batches keyed by lateness window index
+	start   int64            // start of the current blob, or of the last one emitted
+	started bool
+}
+
+// batch holds the buffered points of one blob.
+type batch struct {
+	metrics []*batchMetric
+	byID    map[uint64]*batchMetric
+	byName  map[string]*batchMetric
+	minTs   int64
+	maxTs   int64
+	points  int
+	size    int
+}
+
+// batchMetric holds the buffered points of one metric.
+type batchMetric struct {
+	id   uint64
+	name string
+	ts   []int64
+	vals []float64
+	tags []string
+}
+
+// NewBatcher creates a Batcher that passes each finished blob to onBlob.
+//
+// By default a blob is cut once roughly DefaultBatchTargetSize bytes of points are
+// buffered, or earlier when a metric reaches the encoder's per-metric point limit or
+// the blob reaches MaxMetricCount metrics. Call Flush (or Close) to emit the last,
+// partial blob.
+//
+// Parameters:
+//   - onBlob: Callback receiving the bytes of each finished blob; the slice is not reused
+//   - opts: Cut limits and encoder options (WithBatchTargetSize, WithBatchMaxDuration,
+//     WithBatchMaxMetrics, WithBatchEncoderOptions)
+//
+// Returns:
+//   - *Batcher: New batcher with no buffered points
+//   - error: Invalid option or encoder option error
+//
+// Example:
+//
+//	batcher, _ := blob.NewBatcher(func(data []byte) error {
+//	    return store.Put(data)
+//	}, blob.WithBatchMaxDuration(time.Minute))
+//	for msg := range consumer.Messages() {
+//	    if err := batcher.Add(msg.Metric, msg.TimestampMicros, msg.Value, msg.Tag); err != nil {
+//	        return err
+//	    }
+//	}
+//	return batcher.Close()
+func NewBatcher(onBlob func(data []byte) error, opts ...BatcherOption) (*Batcher, error) {
+	if onBlob == nil {
+		return nil, errors.New("batcher callback must not be nil")
+	}
+
+	cfg := BatcherConfig{
+		targetSize: DefaultBatchTargetSize,
+		maxMetrics: MaxMetricCount,
+	}
+	if err := options.Apply(&cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	// A probe encoder validates the encoder options and exposes their effective settings.
+	probe, err := NewNumericEncoder(time.Unix(0, 0), cfg.encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Batcher{
+		cfg:       cfg,
+		onBlob:    onBlob,
+		unit:      probe.tsUnit,
+		hasTag:    probe.hasTag,
+		maxPoints: probe.MaxDataPoints(),
+		mode:      modeUndefined,
+		cur:       newBatch(),
+		late:      make(map[int64]*batch),
+	}
+
+	return b, nil
+}
+
+func newBatch() *batch {
+	return &batch{
+		byID:   make(map[uint64]*batchMetric),
+		byName: make(map[string]*batchMetric),
+	}
+}
+
+// WithBatchTargetSize sets the buffered size in bytes at which a blob is cut.
+//
+// The size counts 16 bytes per point plus tag bytes, before encoding and compression,
+// so finished blobs are usually much smaller. Default is DefaultBatchTargetSize.
+func WithBatchTargetSize(bytes int) BatcherOption {
+	return options.New(func(cfg *BatcherConfig) error {
+		if bytes <= 0 {
+			return fmt.Errorf("invalid batch target size: %d, must be positive", bytes)
+		}
+		cfg.targetSize = bytes
+
+		return nil
+	})
+}
+
+// WithBatchMaxDuration limits the time span covered by one blob. A point whose
+// timestamp would stretch the buffered span beyond d cuts the blob first. Timestamps
+// are interpreted in the unit set by WithTimestampUnit. Default is no limit.
+func WithBatchMaxDuration(d time.Duration) BatcherOption {
+	return options.New(func(cfg *BatcherConfig) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid batch max duration: %v, must be positive", d)
+		}
+		cfg.maxDuration = d
+
+		return nil
+	})
+}
+
+// WithBatchMaxMetrics limits the number of metrics in one blob, between 1 and
+// MaxMetricCount. Default is MaxMetricCount.
+func WithBatchMaxMetrics(n int) BatcherOption {
+	return options.New(func(cfg *BatcherConfig) error {
+		if n <= 0 || n > MaxMetricCount {
+			return fmt.Errorf("invalid batch max metrics: %d, must be between 1 and %d", n, MaxMetricCount)
+		}
+		cfg.maxMetrics = n
+
+		return nil
+	})
+}
+
+// WithBatchLateness routes late points into separate blobs instead of the current one.
+//
+// A point older than the current blob's start (the timestamp of its first point) is
+// late. Late points at most window older than that start are buffered into one late
+// blob per window, aligned to multiples of window; older points are rejected with
+// ErrLateDataPoint. Late blobs go to the same callback, right after the current blob
+// whenever it is cut or flushed. Default is no lateness handling: late points join
+// the current blob and move its start back.
+func WithBatchLateness(window time.Duration) BatcherOption {
+	return options.New(func(cfg *BatcherConfig) error {
+		if window <= 0 {
+			return fmt.Errorf("invalid batch lateness window: %v, must be positive", window)
+		}
+		cfg.lateness = window
+
+		return nil
+	})
+}
+
+// WithBatchEncoderOptions sets the options used to create the encoder of every blob.
+func WithBatchEncoderOptions(opts ...NumericEncoderOption) BatcherOption {
+	return options.NoError(func(cfg *BatcherConfig) {
+		cfg.encoderOpts = append(cfg.encoderOpts, opts...)
+	})
+}
+
+// Add buffers a data point of the named metric, cutting a blob when a limit is hit.
+//
+// Parameters:
+//   - metricName: Metric name (must be non-empty)
+//   - timestamp: Timestamp in the encoder's timestamp unit (microseconds by default)
+//   - value: Data point value
+//   - tag: Optional tag, dropped unless tags are enabled in the encoder options
+//
+// Returns:
+//   - error: ErrInvalidMetricName, ErrMixedIdentifierMode, ErrLateDataPoint, or an
+//     encoding or callback error from a cut
+func (b *Batcher) Add(metricName string, timestamp int64, value float64, tag string) error {
+	if metricName == "" {
+		return fmt.Errorf("%w: metric name must be non-empty", errs.ErrInvalidMetricName)
+	}
+	if b.mode == modeUserID {
+		return fmt.Errorf("%w: cannot use Add after AddID", errs.ErrMixedIdentifierMode)
+	}
+	b.mode = modeNameManaged
+
+	return b.add(0, metricName, timestamp, value, tag)
+}
+
+// AddID buffers a data point of the metric with the given ID, cutting a blob when a
+// limit is hit.
+//
+// Parameters:
+//   - metricID: Metric ID (must be non-zero)
+//   - timestamp: Timestamp in the encoder's timestamp unit (microseconds by default)
+//   - value: Data point value
+//   - tag: Optional tag, dropped unless tags are enabled in the encoder options
+//
+// Returns:
+//   - error: ErrInvalidMetricID, ErrMixedIdentifierMode, ErrLateDataPoint, or an
+//     encoding or callback error from a cut
+func (b *Batcher) AddID(metricID uint64, timestamp int64, value float64, tag string) error {
+	if metricID == 0 {
+		return errs.ErrInvalidMetricID
+	}
+	if b.mode == modeNameManaged {
+		return fmt.Errorf("%w: cannot use AddID after Add", errs.ErrMixedIdentifierMode)
+	}
+	b.mode = modeUserID
+
+	return b.add(metricID, "", timestamp, value, tag)
+}
+
+// Flush encodes the buffered points into blobs and passes them to the callback:
+// first the current blob, then any late blobs in time order. It does nothing when
+// no points are buffered.
+//
+// If encoding or the callback fails, the points not yet emitted stay buffered so
+// Flush can be retried; call Reset to discard them instead.
+//
+// Returns:
+//   - error: Encoding error or the callback's error
+func (b *Batcher) Flush() error {
+	if err := b.emit(b.cur); err != nil {
+		return err
+	}
+
+	keys := slices.Sorted(maps.Keys(b.late))
+	for _, key := range keys {
+		if err := b.emit(b.late[key]); err != nil {
+			return err
+		}
+		delete(b.late, key)
+	}
+
+	return nil
+}
+
+// Close flushes the remaining buffered points. The batcher stays usable.
+func (b *Batcher) Close() error {
+	return b.Flush()
+}
+
+// Reset discards the buffered points, including late ones, without emitting them.
+func (b *Batcher) Reset() {
+	b.cur.reset()
+	clear(b.late)
+}
+
+// Len returns the number of buffered data points, including late ones.
+func (b *Batcher) Len() int {
+	n := b.cur.points
+	for _, bt := range b.late {
+		n += bt.points
+	}
+
+	return n
+}
+
+// LateLen returns the number of buffered late data points.
+func (b *Batcher) LateLen() int {
+	return b.Len() - b.cur.points
+}
+
+// MetricCount returns the number of metrics in the current blob.
+func (b *Batcher) MetricCount() int {
+	return len(b.cur.metrics)
+}
+
+// Size returns the buffered size of the current blob in bytes, as compared against
+// the target size.
+func (b *Batcher) Size() int {
+	return b.cur.size
+}
+
+// add routes a point to the current blob or, when late, to its late batch.
+func (b *Batcher) add(metricID uint64, metricName string, timestamp int64, value float64, tag string) error {
+	if b.cfg.lateness > 0 && b.started && timestamp < b.start {
+		return b.addLate(metricID, metricName, timestamp, value, tag)
+	}
+
+	if b.needsCut(b.cur, b.cur.lookup(metricID, metricName), timestamp) {
+		if err := b.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if b.cur.points == 0 {
+		b.start, b.started = timestamp, true
+	}
+	b.cur.add(metricID, metricName, timestamp, value, tag, b.hasTag)
+
+	if b.cur.size >= b.cfg.targetSize {
+		return b.Flush()
+	}
+
+	return nil
+}
+
+// addLate buffers a late point into the batch of its lateness window.
+func (b *Batcher) addLate(metricID uint64, metricName string, timestamp int64, value float64, tag string) error {
+	window := max(int64(b.cfg.lateness/b.unit.Duration()), 1)
+	if uint64(b.start-timestamp) > uint64(window) { //nolint: gosec
+		return fmt.Errorf("%w: timestamp %d is more than %v before blob start %d",
+			errs.ErrLateDataPoint, timestamp, b.cfg.lateness, b.start)
+	}
+
+	key := timestamp / window
+	if timestamp%window != 0 && timestamp < 0 {
+		key--
+	}
+
+	bt, ok := b.late[key]
+	if !ok {
+		bt = newBatch()
+		b.late[key] = bt
+	}
+
+	if b.needsCut(bt, bt.lookup(metricID, metricName), timestamp) {
+		if err := b.emit(bt); err != nil {
+			return err
+		}
+	}
+	bt.add(metricID, metricName, timestamp, value, tag, b.hasTag)
+
+	if bt.size >= b.cfg.targetSize {
+		return b.emit(bt)
+	}
+
+	return nil
+}
+
+// needsCut reports whether bt must be emitted before adding a point with the
+// given timestamp to m (nil for a new metric).
+func (b *Batcher) needsCut(bt *batch, m *batchMetric, timestamp int64) bool {
+	if bt.points == 0 {
+		return false
+	}
+
+	if m == nil && len(bt.metrics) >= b.cfg.maxMetrics {
+		return true
+	}
+	if m != nil && len(m.ts) >= b.maxPoints {
+		return true
+	}
+
+	if b.cfg.maxDuration > 0 {
+		lo, hi := min(bt.minTs, timestamp), max(bt.maxTs, timestamp)
+		if uint64(hi-lo) > uint64(b.cfg.maxDuration/b.unit.Duration()) { //nolint: gosec
+			return true
+		}
+	}
+
+	return false
+}
+
+// emit encodes bt, passes the blob to the callback and empties bt.
+func (b *Batcher) emit(bt *batch) error {
+	if bt.points == 0 {
+		return nil
+	}
+
+	data, err := b.encode(bt)
+	if err != nil {
+		return err
+	}
+
+	if err := b.onBlob(data); err != nil {
+		return err
+	}
+	bt.reset()
+
+	return nil
+}
+
+// encode builds a blob from the metrics of bt, in first-seen order.
+func (b *Batcher) encode(bt *batch) ([]byte, error) {
+	enc, err := NewNumericEncoder(b.unit.Time(bt.minTs), b.cfg.encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range bt.metrics {
+		if m.name != "" {
+			err = enc.StartMetricName(m.name, len(m.ts))
+		} else {
+			err = enc.StartMetricID(m.id, len(m.ts))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := enc.AddDataPoints(m.ts, m.vals, m.tags); err != nil {
+			return nil, err
+		}
+
+		if err := enc.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return enc.Finish()
+}
+
+// lookup returns the buffered metric with the given name (or ID when name is
+// empty), or nil.
+func (bt *batch) lookup(metricID uint64, metricName string) *batchMetric {
+	if metricName != "" {
+		return bt.byName[metricName]
+	}
+
+	return bt.byID[metricID]
+}
+
+// add appends a point to its metric, creating the metric on first use.
+func (bt *batch) add(metricID uint64, metricName string, timestamp int64, value float64, tag string, hasTag bool) {
+	m := bt.lookup(metricID, metricName)
+	if m == nil {
+		m = &batchMetric{id: metricID, name: metricName}
+		bt.metrics = append(bt.metrics, m)
+		if metricName != "" {
+			bt.byName[metricName] = m
+		} else {
+			bt.byID[metricID] = m
+		}
+	}
+
+	m.ts = append(m.ts, timestamp)
+	m.vals = append(m.vals, value)
+	bt.size += batchPointSize
+	if hasTag {
+		m.tags = append(m.tags, tag)
+		bt.size += len(tag)
+	}
+
+	if bt.points == 0 || timestamp < bt.minTs {
+		bt.minTs = timestamp
+	}
+	if bt.points == 0 || timestamp > bt.maxTs {
+		bt.maxTs = timestamp
+	}
+	bt.points++
+}
+
+func (bt *batch) reset() {
+	bt.metrics = nil
+	clear(bt.byID)
+	clear(bt.byName)
+	bt.minTs, bt.maxTs = 0, 0
+	bt.points, bt.size = 0, 0
+}
@@ -0,0 +1,198 @@
+package blob
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func collectBlobs(t *testing.T, blobs *[][]byte) func([]byte) error {
+	t.Helper()
+
+	return func(data []byte) error {
+		*blobs = append(*blobs, data)
+		return nil
+	}
+}
+
+func TestBatcher_InterleavedMetrics(t *testing.T) {
+	var blobs [][]byte
+	b, err := NewBatcher(collectBlobs(t, &blobs), WithBatchEncoderOptions(WithTagsEnabled(true)))
+	require.NoError(t, err)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 10 {
+		ts := base.Add(time.Duration(i) * time.Second).UnixMicro()
+		require.NoError(t, b.Add("cpu", ts, float64(i), "host=a"))
+		require.NoError(t, b.Add("mem", ts, float64(i*10), ""))
+	}
+	require.Equal(t, 20, b.Len())
+	require.Equal(t, 2, b.MetricCount())
+	require.Empty(t, blobs, "no limit reached yet")
+
+	require.NoError(t, b.Close())
+	require.Len(t, blobs, 1)
+	require.Zero(t, b.Len())
+	require.Zero(t, b.Size())
+	require.NoError(t, b.Flush(), "flushing an empty batcher is a no-op")
+	require.Len(t, blobs, 1)
+
+	decoder, err := NewNumericDecoder(blobs[0])
+	require.NoError(t, err)
+	decoded, err := decoder.Decode()
+	require.NoError(t, err)
+	require.True(t, decoded.StartTime().Equal(base))
+
+	var values []float64
+	for _, dp := range decoded.AllByName("mem") {
+		values = append(values, dp.Val)
+	}
+	require.Equal(t, []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, values)
+
+	tag, ok := decoded.TagAtByName("cpu", 3)
+	require.True(t, ok)
+	require.Equal(t, "host=a", tag)
+}
+
+func TestBatcher_Cuts(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []BatcherOption
+		blobs int
+	}{
+		{name: "TargetSize", opts: []BatcherOption{WithBatchTargetSize(4 * batchPointSize)}, blobs: 3},
+		{name: "MaxDuration", opts: []BatcherOption{WithBatchMaxDuration(5 * time.Second)}, blobs: 2},
+		{name: "MaxMetrics", opts: []BatcherOption{WithBatchMaxMetrics(1)}, blobs: 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blobs [][]byte
+			b, err := NewBatcher(collectBlobs(t, &blobs), tt.opts...)
+			require.NoError(t, err)
+
+			// 12 points alternating between two metrics, one second apart.
+			for i := range 12 {
+				name := "a"
+				if i%2 == 1 {
+					name = "b"
+				}
+				require.NoError(t, b.Add(name, int64(i)*time.Second.Microseconds(), float64(i), ""))
+			}
+			require.NoError(t, b.Close())
+			require.Len(t, blobs, tt.blobs)
+
+			set, err := DecodeBlobSet(blobs...)
+			require.NoError(t, err)
+			require.Equal(t, 6, set.MetricLenByName("a"))
+			require.Equal(t, 6, set.MetricLenByName("b"))
+		})
+	}
+}
+
+func TestBatcher_MaxDurationUsesTimestampUnit(t *testing.T) {
+	var blobs [][]byte
+	b, err := NewBatcher(collectBlobs(t, &blobs),
+		WithBatchMaxDuration(time.Minute),
+		WithBatchEncoderOptions(WithTimestampUnit(format.TimeUnitSecond)),
+	)
+	require.NoError(t, err)
+
+	for _, ts := range []int64{100, 160, 161, 300} {
+		require.NoError(t, b.AddID(7, ts, 1, ""))
+	}
+	require.NoError(t, b.Close())
+	require.Len(t, blobs, 3)
+
+	decoded, err := NewNumericDecoder(blobs[1])
+	require.NoError(t, err)
+	blob, err := decoded.Decode()
+	require.NoError(t, err)
+	require.Equal(t, format.TimeUnitSecond, blob.TimestampUnit())
+	require.True(t, blob.StartTime().Equal(time.Unix(161, 0)))
+}
+
+func TestBatcher_Errors(t *testing.T) {
+	_, err := NewBatcher(nil)
+	require.Error(t, err)
+	_, err = NewBatcher(func([]byte) error { return nil }, WithBatchMaxMetrics(MaxMetricCount+1))
+	require.Error(t, err)
+	_, err = NewBatcher(func([]byte) error { return nil }, WithBatchEncoderOptions(WithValueEncoding(format.TypeDelta)))
+	require.Error(t, err)
+
+	errSink := errors.New("sink unavailable")
+	var blobs [][]byte
+	fail := true
+	b, err := NewBatcher(func(data []byte) error {
+		if fail {
+			return errSink
+		}
+		blobs = append(blobs, data)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, b.Add("", 1, 1, ""), errs.ErrInvalidMetricName)
+	require.ErrorIs(t, b.AddID(0, 1, 1, ""), errs.ErrInvalidMetricID)
+	require.NoError(t, b.AddID(1, 1, 1, ""))
+	require.ErrorIs(t, b.Add("cpu", 2, 2, ""), errs.ErrMixedIdentifierMode)
+
+	require.ErrorIs(t, b.Flush(), errSink)
+	require.Equal(t, 1, b.Len(), "points stay buffered after a failed flush")
+
+	fail = false
+	require.NoError(t, b.Flush())
+	require.Len(t, blobs, 1)
+
+	require.NoError(t, b.AddID(1, 2, 2, ""))
+	b.Reset()
+	require.Zero(t, b.Len())
+	require.NoError(t, b.Flush())
+	require.Len(t, blobs, 1)
+}
+
+func TestBatcher_Lateness(t *testing.T) {
+	var blobs [][]byte
+	b, err := NewBatcher(collectBlobs(t, &blobs), WithBatchLateness(10*time.Second))
+	require.NoError(t, err)
+
+	sec := func(s int64) int64 { return s * time.Second.Microseconds() }
+
+	require.NoError(t, b.Add("cpu", sec(105), 1, ""))
+	require.NoError(t, b.Add("cpu", sec(106), 2, ""))
+	require.NoError(t, b.Add("cpu", sec(101), 3, ""), "late, window [100s, 110s)")
+	require.NoError(t, b.Add("mem", sec(96), 4, ""), "late, window [90s, 100s)")
+	require.ErrorIs(t, b.Add("cpu", sec(94), 5, ""), errs.ErrLateDataPoint)
+	require.NoError(t, b.Add("cpu", sec(107), 6, ""))
+	require.Equal(t, 5, b.Len())
+	require.Equal(t, 2, b.LateLen())
+	require.Equal(t, 1, b.MetricCount())
+
+	require.NoError(t, b.Close())
+	require.Len(t, blobs, 3)
+	require.Zero(t, b.Len())
+
+	starts := make([]int64, 0, len(blobs))
+	for _, data := range blobs {
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		decoded, err := decoder.Decode()
+		require.NoError(t, err)
+		starts = append(starts, decoded.StartTime().UnixMicro())
+	}
+	require.Equal(t, []int64{sec(105), sec(96), sec(101)}, starts, "current blob first, then late blobs in time order")
+
+	// The watermark survives the flush: points before the last blob's start are still late.
+	require.NoError(t, b.Add("cpu", sec(100), 7, ""))
+	require.Equal(t, 1, b.LateLen())
+	b.Reset()
+	require.Zero(t, b.Len())
+
+	_, err = NewBatcher(collectBlobs(t, &blobs), WithBatchLateness(0))
+	require.Error(t, err)
+}
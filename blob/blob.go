@@ -1,12 +1,15 @@
 package blob
 
 import (
+	"iter"
 	"slices"
 	"sync"
 	"time"
 
+	"github.com/arloliu/mebo/encoding"
 	"github.com/arloliu/mebo/endian"
 	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
 	"github.com/arloliu/mebo/internal/hash"
 	"github.com/arloliu/mebo/section"
 )
@@ -23,6 +26,19 @@ func initEngines() {
 	bigEndianEngine = endian.GetBigEndianEngine()
 }
 
+// allTimes adapts a timestamp iterator in the given unit to an indexed time.Time iterator.
+func allTimes(timestamps iter.Seq[int64], unit format.TimeUnit) iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		i := 0
+		for ts := range timestamps {
+			if !yield(i, unit.Time(ts)) {
+				return
+			}
+			i++
+		}
+	}
+}
+
 // BlobReader represents common interface for both NumericBlob and TextBlob.
 // It is a type-erased interface for accessing blob metadata and data.
 //
@@ -87,6 +103,8 @@ type blobBase struct {
 	sameByteOrder   bool                // Whether the blob uses the same byte order as the system (hot: decoder optimization)
 	endianType      uint8               // 0=little, 1=big (warm: Engine() only)
 	startTimeMicros int64               // Unix timestamp in microseconds (warm: metadata queries)
+	blobID          *lazyBlobID         // Blob identity computed on first use, nil if unknown (cold)
+	interner        *ienc.Interner      // Optional decoded string interner, nil if disabled (cold)
 }
 
 const (
@@ -112,10 +130,97 @@ type indexEntry interface {
 // This avoids the 64-byte cache line stride of []NumericIndexEntry and eliminates
 // interface dispatch overhead from the generic GetMetricID() call.
 type indexMaps[T indexEntry] struct {
-	byID      map[uint64]T // V1: primary lookup; V2: nil
-	byName    map[string]T // metricName → IndexEntry (nil if no collisions occurred)
-	sorted    []T          // V2: primary lookup (sorted by MetricID); V1: nil
-	sortedIDs []uint64     // V2: parallel MetricID slice for binary search; V1: nil
+	byID      map[uint64]T  // V1: primary lookup; V2: nil
+	byName    map[string]T  // metricName → IndexEntry (nil if no collisions occurred)
+	sorted    []T           // V2: primary lookup (sorted by MetricID); V1: nil
+	sortedIDs []uint64      // V2: parallel MetricID slice for binary search; V1: nil
+	lazy      *lazyIndex[T] // deferred index built on first use (lazy decoding only), nil otherwise
+	names     *lazyNames[T] // compressed metric names parsed on first name lookup, nil otherwise
+}
+
+// lazyIndex defers building a blob's index until its first use.
+//
+// Decoded blobs are passed by value, so the pointer is shared by every copy and
+// the index is built at most once, safely under concurrent first lookups.
+type lazyIndex[T indexEntry] struct {
+	once  sync.Once
+	build func() indexMaps[T]
+	index indexMaps[T]
+}
+
+// lazyNames defers decoding a compressed metric names payload until the first
+// name lookup.
+//
+// A payload that fails to decode or verify leaves the blob without metric names,
+// so name lookups fall back to hashing; err records the failure.
+type lazyNames[T indexEntry] struct {
+	once   sync.Once
+	count  int // number of metrics, known without parsing the names
+	parse  func() (map[string]T, error)
+	byName map[string]T
+	err    error
+}
+
+// newLazyIndexMaps returns an index that calls build on first use.
+func newLazyIndexMaps[T indexEntry](build func() indexMaps[T]) indexMaps[T] {
+	return indexMaps[T]{lazy: &lazyIndex[T]{build: build}}
+}
+
+// resolve returns the built index, building a deferred index on first call.
+func (m indexMaps[T]) resolve() indexMaps[T] {
+	if m.lazy == nil {
+		return m
+	}
+
+	m.lazy.once.Do(func() {
+		m.lazy.index = m.lazy.build()
+		m.lazy.build = nil
+	})
+
+	return m.lazy.index
+}
+
+// nameMap returns the metric name map, parsing compressed metric names on first call.
+// Returns nil if the blob has no (valid) metric names.
+func (m indexMaps[T]) nameMap() map[string]T {
+	m = m.resolve()
+	if m.names == nil {
+		return m.byName
+	}
+
+	m.names.once.Do(func() {
+		m.names.byName, m.names.err = m.names.parse()
+		m.names.parse = nil
+	})
+
+	return m.names.byName
+}
+
+// namesErr returns the error that prevented compressed metric names from being
+// parsed, or nil.
+func (m indexMaps[T]) namesErr() error {
+	m = m.resolve()
+	if m.names == nil {
+		return nil
+	}
+	m.nameMap()
+
+	return m.names.err
+}
+
+// nameIndex maps metric names to their index entries; names[i] belongs to entries[i].
+// Returns nil when the blob stores no metric names.
+func nameIndex[T indexEntry](names []string, entries []T) map[string]T {
+	if len(names) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]T, len(names))
+	for i, name := range names {
+		byName[name] = entries[i]
+	}
+
+	return byName
 }
 
 // StartTime returns the start time of the blob.
@@ -128,6 +233,24 @@ func (b blobBase) StartTime() time.Time {
 	return time.UnixMicro(b.startTimeMicros).UTC()
 }
 
+// BlobID returns the deterministic identity of the encoded blob this blob was
+// decoded from. It equals NumericBlobID or TextBlobID of the encoded bytes, so
+// deduplication layers can compare decoded and raw blobs alike.
+//
+// The identity is hashed from the encoded bytes on the first call rather than
+// at decode time, so the encoded blob passed to the decoder must not be
+// modified before then.
+//
+// Returns:
+//   - uint64: Blob identity
+//   - bool: false if the identity is unknown (e.g., blobs assembled by
+//     NewNumericBlobFromParts or recovered by DecodePartial)
+func (b blobBase) BlobID() (uint64, bool) {
+	id := b.blobID.get()
+
+	return id, id != 0
+}
+
 // Engine returns the endian engine for byte order operations.
 // Internal helper for decoder selection.
 func (b blobBase) Engine() endian.EndianEngine {
@@ -177,6 +300,31 @@ func (b blobBase) ValueEncoding() format.EncodingType {
 	return b.valEncType
 }
 
+// TimestampCapabilities returns the access capabilities of the decoder for the
+// blob's timestamp encoding, so callers can choose between direct TimestampAt
+// calls and materializing the column.
+//
+// Returns:
+//   - encoding.Capabilities: The capabilities, or the zero value for an unknown encoding
+//
+// Example:
+//
+//	if !blob.TimestampCapabilities().RandomAccess {
+//	    material := blob.Materialize() // Avoid O(n) TimestampAt calls
+//	}
+func (b blobBase) TimestampCapabilities() encoding.Capabilities {
+	switch b.tsEncType { //nolint: exhaustive
+	case format.TypeRaw:
+		return encoding.CapabilitiesOf(ienc.NewTimestampRawDecoder(b.Engine()))
+	case format.TypeDelta:
+		return encoding.CapabilitiesOf(ienc.NewTimestampDeltaDecoder())
+	case format.TypeDeltaPacked:
+		return encoding.CapabilitiesOf(ienc.NewTimestampDeltaPackedDecoder())
+	default:
+		return encoding.Capabilities{}
+	}
+}
+
 // IsV2Layout returns whether blob uses the V2 on-wire layout.
 // V2 layout controls container structure (sorted index, optional shared timestamps)
 // but does NOT affect encoder/decoder algorithm selection — codecs are orthogonal.
@@ -198,6 +346,11 @@ func (b blobBase) HasMetricNames() bool {
 // If metric names are available (collision occurred), returns len(byName),
 // otherwise returns len(byID) or len(sorted).
 func (m indexMaps[T]) MetricCount() int {
+	m = m.resolve()
+	if m.names != nil {
+		return m.names.count
+	}
+
 	if m.byName != nil {
 		return len(m.byName)
 	}
@@ -211,6 +364,7 @@ func (m indexMaps[T]) MetricCount() int {
 
 // HasMetricID checks if the given metric ID exists in the blob.
 func (m indexMaps[T]) HasMetricID(metricID uint64) bool {
+	m = m.resolve()
 	if m.sortedIDs != nil {
 		_, found := slices.BinarySearch(m.sortedIDs, metricID)
 
@@ -232,8 +386,8 @@ func (m indexMaps[T]) HasMetricID(metricID uint64) bool {
 //
 // Returns false if the metric is not found by either lookup path.
 func (m indexMaps[T]) HasMetricName(metricName string) bool {
-	if m.byName != nil {
-		_, ok := m.byName[metricName]
+	if byName := m.nameMap(); byName != nil {
+		_, ok := byName[metricName]
 
 		return ok
 	}
@@ -245,6 +399,7 @@ func (m indexMaps[T]) HasMetricName(metricName string) bool {
 // The slice is newly allocated to prevent external modification.
 // V2 sorted index returns IDs in deterministic sorted order.
 func (m indexMaps[T]) MetricIDs() []uint64 {
+	m = m.resolve()
 	if m.sortedIDs != nil {
 		return slices.Clone(m.sortedIDs)
 	}
@@ -261,11 +416,12 @@ func (m indexMaps[T]) MetricIDs() []uint64 {
 // Returns an empty slice if the blob doesn't have metric names (byName is nil).
 // The slice is newly allocated to prevent external modification.
 func (m indexMaps[T]) MetricNames() []string {
-	if m.byName == nil {
+	byName := m.nameMap()
+	if byName == nil {
 		return []string{}
 	}
-	names := make([]string, 0, len(m.byName))
-	for name := range m.byName {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
 		names = append(names, name)
 	}
 
@@ -276,6 +432,7 @@ func (m indexMaps[T]) MetricNames() []string {
 // Uses binary search on V2 sorted index, map lookup on V1.
 // Returns (entry, true) if found, or (zero-value, false) if not found.
 func (m indexMaps[T]) GetByID(metricID uint64) (T, bool) {
+	m = m.resolve()
 	if m.sortedIDs != nil {
 		i, found := slices.BinarySearch(m.sortedIDs, metricID)
 		if found {
@@ -302,8 +459,8 @@ func (m indexMaps[T]) GetByID(metricID uint64) (T, bool) {
 //
 // Returns (entry, true) if found, or (zero-value, false) if not found.
 func (m indexMaps[T]) GetByName(metricName string) (T, bool) {
-	if m.byName != nil {
-		entry, ok := m.byName[metricName]
+	if byName := m.nameMap(); byName != nil {
+		entry, ok := byName[metricName]
 
 		return entry, ok
 	}
@@ -338,6 +495,7 @@ func (m indexMaps[T]) LenByName(metricName string) int {
 // V1 map iterates in arbitrary order.
 // Return false from fn to stop iteration.
 func (m indexMaps[T]) ForEach(fn func(T) bool) {
+	m = m.resolve()
 	if m.sorted != nil {
 		for _, e := range m.sorted {
 			if !fn(e) {
@@ -358,11 +516,12 @@ func (m indexMaps[T]) ForEach(fn func(T) bool) {
 // At returns the entry at position i for direct indexed access.
 // Only valid for V2 sorted index. Panics if index is out of range.
 func (m indexMaps[T]) At(i int) T {
-	return m.sorted[i]
+	return m.resolve().sorted[i]
 }
 
 // IsEmpty returns whether the index contains no entries.
 func (m indexMaps[T]) IsEmpty() bool {
+	m = m.resolve()
 	if m.sorted != nil {
 		return len(m.sorted) == 0
 	}
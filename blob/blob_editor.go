@@ -0,0 +1,784 @@
+package blob
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// BlobEditor is a copy-on-write editing session for an encoded numeric blob.
+//
+// It stages modifications (dropping metrics, appending data points, changing tags)
+// and writes a new blob on Finish. The source blob is never modified, and only the
+// edited metrics are decoded and re-encoded: the encoded data of untouched metrics
+// is copied byte-for-byte, and a compressed payload section is reused as-is when no
+// metric in it changed (for example, tag edits leave the timestamp and value
+// sections untouched). Small edits to large blobs therefore cost a fraction of a
+// full decode and re-encode.
+//
+// Appended values go through the blob's recorded value precision or quantization,
+// like values added to an encoder. Blobs with shared timestamps or metric references
+// are not supported, because their payloads are shared between metrics.
+//
+// A BlobEditor is not safe for concurrent use.
+type BlobEditor struct {
+	source   []byte // encoded blob passed to NewBlobEditor
+	data     []byte // source in the standard layout
+	header   section.NumericHeader
+	blob     NumericBlob
+	raw      decodedPayloads             // compressed payload sections of data
+	names    []string                    // metric names in index order (nil if absent)
+	namesEnd int                         // end of the metric names payload in data
+	indexOff int                         // start of the index entries in data
+	entries  []section.NumericIndexEntry // index entries in index order, with absolute offsets
+	unit     int                         // index offset delta unit in bytes
+	byID     map[uint64]int              // metric ID → index position, -1 if shared by several names
+	byName   map[string]int              // metric name → index position (nil if names are absent)
+	edits    []metricEdit                // staged edits per index position
+}
+
+// metricEdit holds the staged edits of one metric.
+type metricEdit struct {
+	dropped    bool
+	timestamps []int64        // appended timestamps
+	values     []float64      // appended values
+	tags       []string       // appended tags (nil if the blob has no tags)
+	setTags    map[int]string // replaced tags keyed by data point index
+	sketch     []byte         // rebuilt sketch body (blobs with sketches only)
+	tagSummary []byte         // rebuilt tag summary body (blobs with tag summaries only)
+	sum        []byte         // rebuilt value sum body (blobs with value sums only)
+}
+
+// NewBlobEditor opens an encoded numeric blob for editing.
+//
+// Blobs written with WithSmallHeader are edited in the standard layout, which
+// Finish writes when changes are staged.
+//
+// Parameters:
+//   - data: Encoded numeric blob; it must stay unmodified while the editor is in use
+//
+// Returns:
+//   - *BlobEditor: Editor with no staged changes
+//   - error: Decoding errors, or ErrUnsupportedBlobFeature for blobs with shared
+//     timestamps or metric references
+//
+// Example:
+//
+//	editor, err := blob.NewBlobEditor(data)
+//	if err != nil {
+//	    return err
+//	}
+//	_ = editor.DropMetricByName("debug.counter")
+//	_ = editor.AppendDataPointsByName("cpu.usage", []int64{ts}, []float64{42.5}, nil)
+//	edited, err := editor.Finish()
+func NewBlobEditor(data []byte) (*BlobEditor, error) {
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := decoder.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.header.Flag.HasSharedTimestamps() {
+		return nil, fmt.Errorf("%w: shared timestamps", errs.ErrUnsupportedBlobFeature)
+	}
+	if _, ok := blob.metadata.Get(section.MetadataKeyMetricReferences); ok {
+		return nil, fmt.Errorf("%w: metric references", errs.ErrUnsupportedBlobFeature)
+	}
+
+	names, namesEnd, err := decoder.parseMetricNames()
+	if err != nil {
+		return nil, err
+	}
+
+	_, indexOff, err := decoder.parseMetadata(namesEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := decoder.payloadSections()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, _, err := decoder.parseIndexEntries(indexOff, len(blob.tsPayload), len(blob.valPayload), len(blob.tagPayload), false)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &BlobEditor{
+		source:   data,
+		data:     decoder.data,
+		header:   *decoder.header,
+		blob:     blob,
+		raw:      raw,
+		names:    names,
+		namesEnd: namesEnd,
+		indexOff: indexOff,
+		entries:  entries,
+		unit:     max(decoder.offsetUnit, 1),
+		byID:     make(map[uint64]int, len(entries)),
+		edits:    make([]metricEdit, len(entries)),
+	}
+
+	for i, entry := range entries {
+		if _, dup := e.byID[entry.MetricID]; dup {
+			e.byID[entry.MetricID] = -1
+		} else {
+			e.byID[entry.MetricID] = i
+		}
+	}
+
+	if names != nil {
+		e.byName = make(map[string]int, len(names))
+		for i, name := range names {
+			e.byName[name] = i
+		}
+	}
+
+	return e, nil
+}
+
+// DropMetric removes the metric with the given ID from the edited blob.
+//
+// Returns:
+//   - error: ErrMetricNotFound if the metric does not exist or was already dropped,
+//     or ErrHashCollision if several names share the ID (use DropMetricByName)
+func (e *BlobEditor) DropMetric(metricID uint64) error {
+	pos, err := e.position(metricID)
+	if err != nil {
+		return err
+	}
+	e.edits[pos] = metricEdit{dropped: true}
+
+	return nil
+}
+
+// DropMetricByName removes the metric with the given name from the edited blob.
+//
+// Returns:
+//   - error: ErrMetricNotFound if the metric does not exist or was already dropped
+func (e *BlobEditor) DropMetricByName(metricName string) error {
+	pos, err := e.positionByName(metricName)
+	if err != nil {
+		return err
+	}
+	e.edits[pos] = metricEdit{dropped: true}
+
+	return nil
+}
+
+// AppendDataPoints stages data points to append to the metric with the given ID.
+//
+// Parameters:
+//   - metricID: The metric to append to
+//   - timestamps: Timestamps in the blob's timestamp unit
+//   - values: Values, one per timestamp
+//   - tags: Tags, one per timestamp, or nil for empty tags
+//
+// Returns:
+//   - error: ErrMetricNotFound, ErrHashCollision (use AppendDataPointsByName),
+//     ErrDataPointCountMismatch for mismatched lengths, or ErrTagsDisabled for
+//     non-empty tags on a blob without tags
+func (e *BlobEditor) AppendDataPoints(metricID uint64, timestamps []int64, values []float64, tags []string) error {
+	pos, err := e.position(metricID)
+	if err != nil {
+		return err
+	}
+
+	return e.appendDataPoints(pos, timestamps, values, tags)
+}
+
+// AppendDataPointsByName stages data points to append to the metric with the given
+// name. See AppendDataPoints for details.
+func (e *BlobEditor) AppendDataPointsByName(metricName string, timestamps []int64, values []float64, tags []string) error {
+	pos, err := e.positionByName(metricName)
+	if err != nil {
+		return err
+	}
+
+	return e.appendDataPoints(pos, timestamps, values, tags)
+}
+
+// SetTag stages a new tag for the data point at index of the metric with the given ID.
+// The index may refer to an appended data point.
+//
+// Returns:
+//   - error: ErrMetricNotFound, ErrHashCollision (use SetTagByName),
+//     ErrIndexOutOfRange, or ErrTagsDisabled for blobs without tags
+func (e *BlobEditor) SetTag(metricID uint64, index int, tag string) error {
+	pos, err := e.position(metricID)
+	if err != nil {
+		return err
+	}
+
+	return e.setTag(pos, index, tag)
+}
+
+// SetTagByName stages a new tag for the data point at index of the metric with the
+// given name. See SetTag for details.
+func (e *BlobEditor) SetTagByName(metricName string, index int, tag string) error {
+	pos, err := e.positionByName(metricName)
+	if err != nil {
+		return err
+	}
+
+	return e.setTag(pos, index, tag)
+}
+
+// HasChanges reports whether any modification is staged.
+func (e *BlobEditor) HasChanges() bool {
+	for i := range e.edits {
+		if e.edits[i].touched() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Finish writes a new blob with all staged modifications applied.
+//
+// Metrics keep their order and the blob keeps its header settings: encodings,
+// compression, byte order, layout version and metadata. Finish can be called
+// again after staging further modifications.
+//
+// Returns:
+//   - []byte: Newly allocated blob; a copy of the source when nothing is staged
+//   - error: ErrNoMetricsAdded if every metric was dropped, ErrOffsetOutOfRange when
+//     a V1 layout blob outgrows its index entries, or encoding and compression errors
+func (e *BlobEditor) Finish() ([]byte, error) {
+	if !e.HasChanges() {
+		return slices.Clone(e.source), nil
+	}
+
+	flag := e.header.Flag
+	hasTag := flag.HasTag()
+
+	tsBuf := make([]byte, 0, len(e.blob.tsPayload))
+	valBuf := make([]byte, 0, len(e.blob.valPayload))
+	tagBuf := make([]byte, 0, len(e.blob.tagPayload))
+	entries := make([]section.NumericIndexEntry, 0, len(e.entries))
+	var names []string
+	var dropped, columnsChanged, tagsChanged bool
+	var lastTs, lastVal, lastTag int
+
+	for i, src := range e.entries {
+		edit := &e.edits[i]
+		if edit.dropped {
+			dropped, columnsChanged, tagsChanged = true, true, true
+			continue
+		}
+
+		count := src.Count
+		tsBytes := e.blob.tsPayload[src.TimestampOffset : src.TimestampOffset+src.TimestampLength]
+		valBytes := e.blob.valPayload[src.ValueOffset : src.ValueOffset+src.ValueLength]
+		var tagBytes []byte
+		if hasTag {
+			tagBytes = e.blob.tagPayload[src.TagOffset : src.TagOffset+src.TagLength]
+		}
+
+		if edit.touched() {
+			encTs, encVal, encTag, err := e.encodeMetric(src, edit)
+			if err != nil {
+				return nil, err
+			}
+
+			// Tag-only edits keep the timestamp and value bytes of the source.
+			if len(edit.timestamps) > 0 {
+				count += len(edit.timestamps)
+				tsBytes, valBytes = encTs, encVal
+				columnsChanged = true
+			}
+			tagBytes = encTag
+			if hasTag && e.blob.tagBitmap {
+				tagBytes, _ = ienc.AppendBitmapTags(nil, encTag, count)
+			}
+			tagsChanged = true
+		}
+
+		// Buffers stay padded to the offset unit, so deltas are whole units.
+		entry := section.NewNumericIndexEntry(src.MetricID, count)
+		entry.TimestampOffset = (len(tsBuf) - lastTs) / e.unit
+		entry.ValueOffset = (len(valBuf) - lastVal) / e.unit
+		lastTs, lastVal = len(tsBuf), len(valBuf)
+		if hasTag {
+			entry.TagOffset = (len(tagBuf) - lastTag) / e.unit
+			lastTag = len(tagBuf)
+		}
+		entries = append(entries, entry)
+
+		tsBuf = appendPadding(append(tsBuf, tsBytes...), e.unit)
+		valBuf = appendPadding(append(valBuf, valBytes...), e.unit)
+		tagBuf = appendPadding(append(tagBuf, tagBytes...), e.unit)
+
+		if e.names != nil {
+			names = append(names, e.names[i])
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errs.ErrNoMetricsAdded
+	}
+
+	var layoutVersion uint8
+	if flag.IsV2() {
+		layoutVersion = 2
+	} else if err := validateV1Entries(entries); err != nil {
+		return nil, err
+	}
+
+	// Reuse the compressed sections whose contents did not change.
+	tsPayload, valPayload, tagPayload := e.raw.tsPayload, e.raw.valPayload, e.raw.tagPayload
+	if columnsChanged {
+		var err error
+		if tsPayload, err = compressPayload(flag.TimestampCompression(), tsBuf); err != nil {
+			return nil, fmt.Errorf("failed to compress timestamp payload: %w", err)
+		}
+		if valPayload, err = compressPayload(flag.ValueCompression(), valBuf); err != nil {
+			return nil, fmt.Errorf("failed to compress value payload: %w", err)
+		}
+	}
+	if hasTag && tagsChanged {
+		var err error
+		if tagPayload, err = compressPayload(tagCompression(e.blob.metadata), tagBuf); err != nil {
+			return nil, fmt.Errorf("failed to compress tag payload: %w", err)
+		}
+	}
+
+	header := e.header
+	namesPayload := e.data[e.header.Size():e.namesEnd]
+	if dropped && names != nil {
+		var err error
+		if namesPayload, err = reencodeNumericMetricNames(names, &header.Flag, e.blob.Engine()); err != nil {
+			return nil, err
+		}
+	}
+	metadata := e.data[e.namesEnd:e.indexOff]
+	if (columnsChanged && (e.blob.HasTimeBounds() || e.blob.HasSketches() || e.blob.HasValueSums())) ||
+		(tagsChanged && e.blob.HasTagSummaries()) {
+		metadata = e.updatedMetadata(dropped)
+	}
+
+	magic, entrySize := selectNumericIndexFormat(layoutVersion, hasTag, entries)
+	indexSize := entrySize * len(entries)
+
+	if layoutVersion >= 2 {
+		header.Flag.Options = (header.Flag.Options &^ section.MagicNumberMask) | magic
+	}
+	header.MetricCount = uint32(len(entries)) //nolint: gosec
+	header.Wide = false
+	blobSize := layoutNumericHeader(&header, len(namesPayload)+len(metadata), indexSize, len(tsPayload), len(valPayload), len(tagPayload))
+
+	out := make([]byte, blobSize)
+	offset := copy(out, header.Bytes())
+	offset += copy(out[offset:], namesPayload)
+	offset += copy(out[offset:], metadata)
+	writeNumericIndexEntries(out, offset, magic, entries, e.blob.Engine())
+	offset += indexSize
+	offset += copy(out[offset:], tsPayload)
+	offset += copy(out[offset:], valPayload)
+	copy(out[offset:], tagPayload)
+
+	return out, nil
+}
+
+// UpdateTags rewrites the tags of one metric in an encoded numeric blob, for
+// enrichment pipelines that attach labels after the fact.
+//
+// The update function is called for every data point of the metric with its
+// index and current tag, and returns the new tag. Only the tag payload is
+// re-encoded: the timestamp and value payloads are copied verbatim. See
+// BlobEditor for the supported blobs; use a BlobEditor to update the tags of
+// several metrics in one rewrite.
+//
+// Parameters:
+//   - data: Encoded numeric blob (not modified)
+//   - metricID: The metric whose tags to update
+//   - update: Returns the new tag of the data point at index i, given its old tag
+//
+// Returns:
+//   - []byte: Newly allocated blob; a copy of data when no tag changed
+//   - error: ErrMetricNotFound, ErrHashCollision if several names share the ID,
+//     ErrTagsDisabled for blobs without tags, or the errors of NewBlobEditor and
+//     BlobEditor.Finish
+//
+// Example:
+//
+//	enriched, err := blob.UpdateTags(data, metricID, func(i int, oldTag string) string {
+//	    return oldTag + ",region=eu"
+//	})
+func UpdateTags(data []byte, metricID uint64, update func(i int, oldTag string) string) ([]byte, error) {
+	editor, err := NewBlobEditor(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := editor.position(metricID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !editor.header.Flag.HasTag() {
+		return nil, errs.ErrTagsDisabled
+	}
+
+	for i, dp := range editor.blob.allFromEntry(editor.entries[pos]) {
+		if tag := update(i, dp.Tag); tag != dp.Tag {
+			if err := editor.setTag(pos, i, tag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return editor.Finish()
+}
+
+// compressPayload compresses a payload section with the given compression type.
+func compressPayload(compression format.CompressionType, data []byte) ([]byte, error) {
+	codec, err := compress.GetCodec(compression)
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.Compress(data)
+}
+
+// validateV1Entries checks that index entries holding offset deltas fit the
+// compact V1 index entry format.
+func validateV1Entries(entries []section.NumericIndexEntry) error {
+	for _, entry := range entries {
+		if entry.TimestampOffset > section.NumericMaxOffset ||
+			entry.ValueOffset > section.NumericMaxOffset ||
+			entry.TagOffset > section.NumericMaxOffset ||
+			entry.Count > math.MaxUint16 {
+			return fmt.Errorf("%w: metric 0x%016x no longer fits a V1 index entry", errs.ErrOffsetOutOfRange, entry.MetricID)
+		}
+	}
+
+	return nil
+}
+
+// updatedMetadata returns the metadata section of the blob with the time bounds
+// sketch, tag summary and value sum records updated to cover the staged edits.
+func (e *BlobEditor) updatedMetadata(dropped bool) []byte {
+	engine := e.blob.Engine()
+	md := cloneMetadata(e.blob.metadata)
+	if e.blob.HasTimeBounds() {
+		minTs, maxTs := e.updatedTimeBounds(dropped)
+		md.Set(section.MetadataKeyTimeBounds, encodeTimeBounds(minTs, maxTs, engine))
+	}
+	if e.blob.HasSketches() {
+		md.Set(section.MetadataKeyMetricSketches, e.updatedDirectory(section.MetadataKeyMetricSketches,
+			func(edit *metricEdit) []byte { return edit.sketch },
+			func(dst, body []byte) ([]byte, error) {
+				digest, err := parseSketchBody(body, engine)
+				if err != nil {
+					return nil, err
+				}
+
+				return appendSketchBody(dst, digest, engine), nil
+			}))
+	}
+	if e.blob.HasTagSummaries() {
+		md.Set(section.MetadataKeyTagSummaries, e.updatedDirectory(section.MetadataKeyTagSummaries,
+			func(edit *metricEdit) []byte { return edit.tagSummary },
+			func(dst, body []byte) ([]byte, error) {
+				summary, err := parseTagSummaryBody(body)
+				if err != nil {
+					return nil, err
+				}
+
+				return appendTagSummaryBody(dst, summary), nil
+			}))
+	}
+	if e.blob.HasValueSums() {
+		md.Set(section.MetadataKeyValueSums, e.updatedDirectory(section.MetadataKeyValueSums,
+			func(edit *metricEdit) []byte { return edit.sum },
+			func(dst, body []byte) ([]byte, error) {
+				sum, err := parseValueSumBody(body, engine)
+				if err != nil {
+					return nil, err
+				}
+
+				return appendValueSumBody(dst, sum, engine), nil
+			}))
+	}
+
+	metadata := make([]byte, md.Size())
+	md.WriteToSlice(metadata, 0, engine)
+
+	return metadata
+}
+
+// updatedTimeBounds returns the time bounds covering the staged edits. Appended
+// data points only widen the recorded bounds; when metrics were dropped, the
+// timestamps of the kept metrics are scanned.
+func (e *BlobEditor) updatedTimeBounds(dropped bool) (minTs, maxTs int64) {
+	minTs, maxTs, ok := e.blob.timeBounds()
+	if dropped {
+		ok = false
+	}
+
+	widen := func(ts int64) {
+		if !ok {
+			minTs, maxTs, ok = ts, ts, true
+		}
+		minTs, maxTs = min(minTs, ts), max(maxTs, ts)
+	}
+
+	for i, src := range e.entries {
+		edit := &e.edits[i]
+		if edit.dropped {
+			continue
+		}
+		if dropped {
+			for ts := range e.blob.allTimestampsFromEntry(src) {
+				widen(ts)
+			}
+		}
+		for _, ts := range edit.timestamps {
+			widen(ts)
+		}
+	}
+
+	return minTs, maxTs
+}
+
+// updatedDirectory returns the metric directory record under key for the kept
+// metrics, using the bodies rebuilt for edited metrics. Unchanged bodies are
+// re-serialized by reencode to cut them from the record.
+func (e *BlobEditor) updatedDirectory(key section.MetadataKey, rebuilt func(*metricEdit) []byte,
+	reencode func(dst, body []byte) ([]byte, error),
+) []byte {
+	engine := e.blob.Engine()
+	record, _ := e.blob.metadata.Get(key)
+
+	entries := make([]metricDirEntry, 0, len(e.entries))
+	var bodies []byte
+	for i, src := range e.entries {
+		edit := &e.edits[i]
+		if edit.dropped {
+			continue
+		}
+
+		if body := rebuilt(edit); body != nil {
+			entries = append(entries, metricDirEntry{metricID: src.MetricID, offset: len(bodies)})
+			bodies = append(bodies, body...)
+
+			continue
+		}
+
+		body, ok := findMetricBody(record, src.MetricID, engine)
+		if !ok {
+			continue
+		}
+		next, err := reencode(bodies, body)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, metricDirEntry{metricID: src.MetricID, offset: len(bodies)})
+		bodies = next
+	}
+
+	return encodeMetricDirectory(entries, bodies, engine)
+}
+
+// touched reports whether the edit modifies the metric.
+func (m *metricEdit) touched() bool {
+	return m.dropped || len(m.timestamps) > 0 || len(m.setTags) > 0
+}
+
+// position returns the index position of a metric that is not dropped.
+func (e *BlobEditor) position(metricID uint64) (int, error) {
+	pos, ok := e.byID[metricID]
+	if !ok {
+		return 0, fmt.Errorf("%w: metric ID 0x%016x", errs.ErrMetricNotFound, metricID)
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("%w: metric ID 0x%016x is shared by several names, edit by name", errs.ErrHashCollision, metricID)
+	}
+	if e.edits[pos].dropped {
+		return 0, fmt.Errorf("%w: metric ID 0x%016x was dropped", errs.ErrMetricNotFound, metricID)
+	}
+
+	return pos, nil
+}
+
+// positionByName returns the index position of a named metric that is not dropped.
+// Blobs without metric names resolve the name by its hashed ID.
+func (e *BlobEditor) positionByName(metricName string) (int, error) {
+	if e.byName == nil {
+		return e.position(hash.ID(metricName))
+	}
+
+	pos, ok := e.byName[metricName]
+	if !ok || e.edits[pos].dropped {
+		return 0, fmt.Errorf("%w: %q", errs.ErrMetricNotFound, metricName)
+	}
+
+	return pos, nil
+}
+
+func (e *BlobEditor) appendDataPoints(pos int, timestamps []int64, values []float64, tags []string) error {
+	if len(timestamps) != len(values) {
+		return fmt.Errorf("%w: %d timestamps, %d values", errs.ErrDataPointCountMismatch, len(timestamps), len(values))
+	}
+	if tags != nil && len(tags) != len(timestamps) {
+		return fmt.Errorf("%w: %d timestamps, %d tags", errs.ErrDataPointCountMismatch, len(timestamps), len(tags))
+	}
+
+	hasTag := e.header.Flag.HasTag()
+	if !hasTag && slices.ContainsFunc(tags, func(tag string) bool { return tag != "" }) {
+		return errs.ErrTagsDisabled
+	}
+
+	edit := &e.edits[pos]
+	edit.timestamps = append(edit.timestamps, timestamps...)
+	edit.values = append(edit.values, values...)
+	if hasTag {
+		if tags == nil {
+			tags = make([]string, len(timestamps))
+		}
+		edit.tags = append(edit.tags, tags...)
+	}
+
+	return nil
+}
+
+func (e *BlobEditor) setTag(pos int, index int, tag string) error {
+	if !e.header.Flag.HasTag() {
+		return errs.ErrTagsDisabled
+	}
+
+	edit := &e.edits[pos]
+	count := e.entries[pos].Count + len(edit.timestamps)
+	if index < 0 || index >= count {
+		return fmt.Errorf("%w: index %d, metric has %d data points", errs.ErrIndexOutOfRange, index, count)
+	}
+
+	if edit.setTags == nil {
+		edit.setTags = make(map[int]string)
+	}
+	edit.setTags[index] = tag
+
+	return nil
+}
+
+// encodeMetric decodes a metric, applies its edit and encodes it again with the
+// blob's encodings, returning the encoded timestamp, value and tag bytes.
+func (e *BlobEditor) encodeMetric(src section.NumericIndexEntry, edit *metricEdit) (tsBytes, valBytes, tagBytes []byte, err error) {
+	hasTag := e.header.Flag.HasTag()
+	count := src.Count + len(edit.timestamps)
+
+	timestamps := make([]int64, 0, count)
+	values := make([]float64, 0, count)
+	var tags []string
+	if hasTag {
+		tags = make([]string, 0, count)
+	}
+
+	for _, dp := range e.blob.allFromEntry(src) {
+		timestamps = append(timestamps, dp.Ts)
+		values = append(values, dp.Val)
+		if hasTag {
+			tags = append(tags, dp.Tag)
+		}
+	}
+	if len(timestamps) != src.Count {
+		return nil, nil, nil, fmt.Errorf("%w: metric 0x%016x decoded %d of %d data points",
+			errs.ErrDataPointCountMismatch, src.MetricID, len(timestamps), src.Count)
+	}
+
+	timestamps = append(timestamps, edit.timestamps...)
+	values = append(values, edit.values...)
+	if hasTag {
+		tags = append(tags, edit.tags...)
+		for _, index := range slices.Sorted(maps.Keys(edit.setTags)) {
+			tags[index] = edit.setTags[index]
+		}
+	}
+
+	encoder, err := NewNumericEncoder(e.blob.StartTime(), e.encoderOptions()...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer encoder.releasePooledSlices()
+	defer encoder.tsEncoder.Finish()
+	defer encoder.valEncoder.Finish()
+	defer encoder.tagEncoder.Finish()
+
+	if err := encoder.StartMetricID(src.MetricID, count); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := encoder.AddDataPoints(timestamps, values, tags); err != nil {
+		return nil, nil, nil, err
+	}
+	if err := encoder.EndMetric(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	tsBytes = slices.Clone(encoder.tsEncoder.Bytes())
+	valBytes = slices.Clone(encoder.valEncoder.Bytes())
+	if len(encoder.sketchEntries) > 0 {
+		edit.sketch = slices.Clone(encoder.sketchBodies)
+	}
+	if len(encoder.tagSummaryEntries) > 0 {
+		edit.tagSummary = slices.Clone(encoder.tagSummaryBodies)
+	}
+	if len(encoder.sumEntries) > 0 {
+		edit.sum = slices.Clone(encoder.sumBodies)
+	}
+	if hasTag {
+		tagBytes = slices.Clone(encoder.tagEncoder.Bytes())
+	}
+
+	return tsBytes, valBytes, tagBytes, nil
+}
+
+// encoderOptions returns encoder options that reproduce the blob's per-metric encoding.
+func (e *BlobEditor) encoderOptions() []NumericEncoderOption {
+	flag := e.header.Flag
+	opts := []NumericEncoderOption{
+		WithTimestampEncoding(flag.TimestampEncoding()),
+		WithValueEncoding(flag.ValueEncoding()),
+		WithTagsEnabled(flag.HasTag()),
+	}
+
+	if flag.IsBigEndian() {
+		opts = append(opts, WithBigEndian())
+	}
+	if flag.IsV2() {
+		opts = append(opts, WithBlobLayoutV2())
+	}
+	if unit := e.blob.TimestampUnit(); unit != format.TimeUnitMicrosecond {
+		opts = append(opts, WithTimestampUnit(unit))
+	}
+	if decimals, ok := e.blob.ValuePrecision(); ok {
+		opts = append(opts, WithValuePrecision(decimals))
+	} else if step, ok := e.blob.QuantizationStep(); ok {
+		opts = append(opts, WithQuantization(step))
+	}
+	if e.blob.HasSketches() {
+		opts = append(opts, WithSketches(true))
+	}
+	if e.blob.HasTagSummaries() {
+		opts = append(opts, WithTagSummaries(true))
+	}
+	if e.blob.HasValueSums() {
+		opts = append(opts, WithValueSums(true))
+	}
+
+	return opts
+}
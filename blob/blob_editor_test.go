@@ -0,0 +1,352 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+var editorStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// createEditorTestBlob encodes "cpu", "mem" and "disk" with 10 data points each.
+func createEditorTestBlob(t *testing.T, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(editorStartTime, opts...)
+	require.NoError(t, err)
+
+	for m, name := range []string{"cpu", "mem", "disk"} {
+		require.NoError(t, encoder.StartMetricName(name, 10))
+		for i := range 10 {
+			ts := editorStartTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(m*100+i)+0.5, name))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func decodeEditorTestBlob(t *testing.T, data []byte) (NumericBlob, decodedPayloads) {
+	t.Helper()
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	raw, err := decoder.payloadSections()
+	require.NoError(t, err)
+
+	return blob, raw
+}
+
+func collectDataPoints(blob NumericBlob, metricID uint64) []NumericDataPoint {
+	var points []NumericDataPoint
+	for _, dp := range blob.All(metricID) {
+		points = append(points, dp)
+	}
+
+	return points
+}
+
+func TestBlobEditor_NoChanges(t *testing.T) {
+	data := createEditorTestBlob(t, WithTagsEnabled(true))
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.False(t, editor.HasChanges())
+
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+	require.Equal(t, data, edited)
+}
+
+func TestBlobEditor_DropMetric(t *testing.T) {
+	data := createEditorTestBlob(t, WithTagsEnabled(true))
+	source, _ := decodeEditorTestBlob(t, data)
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.DropMetric(hash.ID("mem")))
+	require.ErrorIs(t, editor.DropMetric(hash.ID("mem")), errs.ErrMetricNotFound)
+	require.True(t, editor.HasChanges())
+
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+	require.Less(t, len(edited), len(data))
+
+	blob, _ := decodeEditorTestBlob(t, edited)
+	require.Equal(t, 2, blob.MetricCount())
+	require.False(t, blob.HasMetricID(hash.ID("mem")))
+	for _, name := range []string{"cpu", "disk"} {
+		id := hash.ID(name)
+		require.Equal(t, collectDataPoints(source, id), collectDataPoints(blob, id), name)
+	}
+}
+
+func TestBlobEditor_AppendDataPoints(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "V2", opts: []NumericEncoderOption{WithTagsEnabled(true), WithBlobLayoutV2()}},
+		{name: "Compressed", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithTimestampCompression(format.CompressionZstd),
+			WithValueCompression(format.CompressionZstd),
+		}},
+		{name: "BigEndian", opts: []NumericEncoderOption{WithTagsEnabled(true), WithBigEndian()}},
+		{name: "WordAligned", opts: []NumericEncoderOption{WithTagsEnabled(true), WithWordAlignedOffsets()}},
+		{name: "ValueAligned", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithValueEncoding(format.TypeRaw),
+			WithValueAlignment(32),
+		}},
+		{name: "Raw", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithTimestampEncoding(format.TypeRaw),
+			WithValueEncoding(format.TypeRaw),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := createEditorTestBlob(t, tt.opts...)
+			source, _ := decodeEditorTestBlob(t, data)
+
+			editor, err := NewBlobEditor(data)
+			require.NoError(t, err)
+
+			ts := []int64{
+				editorStartTime.Add(10 * time.Second).UnixMicro(),
+				editorStartTime.Add(11 * time.Second).UnixMicro(),
+			}
+			require.NoError(t, editor.AppendDataPointsByName("mem", ts, []float64{1000.25, 1001.75}, []string{"new", ""}))
+
+			edited, err := editor.Finish()
+			require.NoError(t, err)
+
+			blob, _ := decodeEditorTestBlob(t, edited)
+			require.Equal(t, 3, blob.MetricCount())
+
+			want := append(collectDataPoints(source, hash.ID("mem")),
+				NumericDataPoint{Ts: ts[0], Val: 1000.25, Tag: "new"},
+				NumericDataPoint{Ts: ts[1], Val: 1001.75, Tag: ""},
+			)
+			require.Equal(t, want, collectDataPoints(blob, hash.ID("mem")))
+
+			for _, name := range []string{"cpu", "disk"} {
+				id := hash.ID(name)
+				require.Equal(t, collectDataPoints(source, id), collectDataPoints(blob, id), name)
+			}
+		})
+	}
+}
+
+func TestBlobEditor_SetTagReusesSections(t *testing.T) {
+	data := createEditorTestBlob(t,
+		WithTagsEnabled(true),
+		WithValueCompression(format.CompressionZstd),
+	)
+	_, sourceRaw := decodeEditorTestBlob(t, data)
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.SetTag(hash.ID("disk"), 3, "changed"))
+
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, raw := decodeEditorTestBlob(t, edited)
+	require.Equal(t, sourceRaw.tsPayload, raw.tsPayload)
+	require.Equal(t, sourceRaw.valPayload, raw.valPayload)
+	require.NotEqual(t, sourceRaw.tagPayload, raw.tagPayload)
+
+	tag, ok := blob.TagAt(hash.ID("disk"), 3)
+	require.True(t, ok)
+	require.Equal(t, "changed", tag)
+	tag, ok = blob.TagAt(hash.ID("disk"), 4)
+	require.True(t, ok)
+	require.Equal(t, "disk", tag)
+}
+
+func TestBlobEditor_SetTagOnAppendedPoint(t *testing.T) {
+	data := createEditorTestBlob(t, WithTagsEnabled(true))
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+
+	ts := editorStartTime.Add(10 * time.Second).UnixMicro()
+	require.NoError(t, editor.AppendDataPoints(hash.ID("cpu"), []int64{ts}, []float64{7}, nil))
+	require.NoError(t, editor.SetTag(hash.ID("cpu"), 10, "appended"))
+	require.ErrorIs(t, editor.SetTag(hash.ID("cpu"), 11, "x"), errs.ErrIndexOutOfRange)
+
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, _ := decodeEditorTestBlob(t, edited)
+	tag, ok := blob.TagAt(hash.ID("cpu"), 10)
+	require.True(t, ok)
+	require.Equal(t, "appended", tag)
+}
+
+func TestBlobEditor_MetricNames(t *testing.T) {
+	// A detected collision makes the encoder store metric names
+	encoder, err := NewNumericEncoder(editorStartTime)
+	require.NoError(t, err)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		require.NoError(t, encoder.StartMetricName(name, 1))
+		require.NoError(t, encoder.AddDataPoint(editorStartTime.UnixMicro(), 1, ""))
+		require.NoError(t, encoder.EndMetric())
+	}
+	encoder.hasCollision = true
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	source, _ := decodeEditorTestBlob(t, data)
+	require.ElementsMatch(t, []string{"alpha", "beta", "gamma"}, source.MetricNames())
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.DropMetricByName("beta"))
+	require.ErrorIs(t, editor.DropMetricByName("beta"), errs.ErrMetricNotFound)
+	require.ErrorIs(t, editor.DropMetricByName("delta"), errs.ErrMetricNotFound)
+
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, _ := decodeEditorTestBlob(t, edited)
+	require.ElementsMatch(t, []string{"alpha", "gamma"}, blob.MetricNames())
+	require.True(t, blob.HasMetricName("gamma"))
+}
+
+func TestBlobEditor_Errors(t *testing.T) {
+	data := createEditorTestBlob(t)
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, editor.DropMetricByName("missing"), errs.ErrMetricNotFound)
+	require.ErrorIs(t, editor.SetTagByName("cpu", 0, "x"), errs.ErrTagsDisabled)
+	require.ErrorIs(t, editor.AppendDataPointsByName("cpu", []int64{1}, []float64{1}, []string{"x"}), errs.ErrTagsDisabled)
+	require.ErrorIs(t, editor.AppendDataPointsByName("cpu", []int64{1, 2}, []float64{1}, nil), errs.ErrDataPointCountMismatch)
+	require.False(t, editor.HasChanges())
+
+	for _, name := range []string{"cpu", "mem", "disk"} {
+		require.NoError(t, editor.DropMetricByName(name))
+	}
+	_, err = editor.Finish()
+	require.ErrorIs(t, err, errs.ErrNoMetricsAdded)
+
+	_, err = NewBlobEditor(data[:10])
+	require.Error(t, err)
+}
+
+func TestBlobEditor_UnsupportedFeatures(t *testing.T) {
+	encoder, err := NewNumericEncoder(editorStartTime, WithSharedTimestamps())
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, encoder.StartMetricName(name, 3))
+		for i := range 3 {
+			require.NoError(t, encoder.AddDataPoint(editorStartTime.Add(time.Duration(i)*time.Second).UnixMicro(), 1, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	_, err = NewBlobEditor(data)
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestBlobEditor_TagPresenceBitmap(t *testing.T) {
+	encoder, err := NewNumericEncoder(editorStartTime, WithTagsEnabled(true))
+	require.NoError(t, err)
+	for id := uint64(1); id <= 2; id++ {
+		require.NoError(t, encoder.StartMetricID(id, 32))
+		for i := range 32 {
+			tag := ""
+			if i == 5 {
+				tag = "spike"
+			}
+			require.NoError(t, encoder.AddDataPoint(editorStartTime.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), tag))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	source, _ := decodeEditorTestBlob(t, data)
+	require.True(t, source.tagBitmap)
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.SetTag(2, 20, "manual"))
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, _ := decodeEditorTestBlob(t, edited)
+	require.True(t, blob.tagBitmap)
+	for id := uint64(1); id <= 2; id++ {
+		tags := slices.Collect(blob.AllTags(id))
+		require.Len(t, tags, 32)
+		require.Equal(t, "spike", tags[5])
+	}
+	tag, ok := blob.TagAt(2, 20)
+	require.True(t, ok)
+	require.Equal(t, "manual", tag)
+	tag, ok = blob.TagAt(1, 20)
+	require.True(t, ok)
+	require.Empty(t, tag)
+}
+
+func TestUpdateTags(t *testing.T) {
+	data := createEditorTestBlob(t,
+		WithTagsEnabled(true),
+		WithTimestampCompression(format.CompressionZstd),
+	)
+	_, sourceRaw := decodeEditorTestBlob(t, data)
+
+	updated, err := UpdateTags(data, hash.ID("mem"), func(i int, oldTag string) string {
+		if i%2 == 0 {
+			return oldTag
+		}
+
+		return oldTag + ",region=eu"
+	})
+	require.NoError(t, err)
+
+	blob, raw := decodeEditorTestBlob(t, updated)
+	require.Equal(t, sourceRaw.tsPayload, raw.tsPayload)
+	require.Equal(t, sourceRaw.valPayload, raw.valPayload)
+
+	tags := slices.Collect(blob.AllTags(hash.ID("mem")))
+	require.Equal(t, "mem", tags[0])
+	require.Equal(t, "mem,region=eu", tags[1])
+	require.Equal(t, slices.Repeat([]string{"cpu"}, 10), slices.Collect(blob.AllTags(hash.ID("cpu"))))
+
+	// No change returns a copy of the source.
+	same, err := UpdateTags(data, hash.ID("cpu"), func(_ int, oldTag string) string { return oldTag })
+	require.NoError(t, err)
+	require.Equal(t, data, same)
+}
+
+func TestUpdateTags_Errors(t *testing.T) {
+	keep := func(_ int, oldTag string) string { return oldTag }
+
+	_, err := UpdateTags(createEditorTestBlob(t, WithTagsEnabled(true)), hash.ID("missing"), keep)
+	require.ErrorIs(t, err, errs.ErrMetricNotFound)
+
+	_, err = UpdateTags(createEditorTestBlob(t), hash.ID("cpu"), keep)
+	require.ErrorIs(t, err, errs.ErrTagsDisabled)
+}
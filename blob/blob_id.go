@@ -0,0 +1,135 @@
+package blob
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/section"
+)
+
+// NumericBlobID computes the identity of an encoded numeric blob directly from its
+// bytes, without decompressing payloads or parsing the index.
+//
+// The identity is the xxHash64 of the blob head (header, metric names, metadata,
+// index and shared timestamp table) followed by the xxHash64 checksum of each
+// stored payload section (timestamps, values, tags). Byte-identical blobs always
+// share an ID, which lets deduplication layers detect re-uploaded blobs cheaply.
+// It matches NumericBlob.BlobID of the decoded blob and NumericBlobParts.BlobID
+// of its split parts.
+//
+// Parameters:
+//   - data: Encoded numeric blob
+//
+// Returns:
+//   - uint64: Deterministic blob identity
+//   - error: Header parsing error or invalid payload offsets
+func NumericBlobID(data []byte) (uint64, error) {
+	data, err := standardNumericBlob(data)
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := section.ParseNumericHeader(data)
+	if err != nil {
+		return 0, err
+	}
+
+	tsOffset := int(header.TimestampPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	valOffset := int(header.ValuePayloadOffset)    //nolint: gosec // Parse bounds offsets to the int range
+	tagOffset := int(header.TagPayloadOffset)      //nolint: gosec // Parse bounds offsets to the int range
+
+	if tsOffset < header.Size() || tsOffset > len(data) {
+		return 0, errs.ErrInvalidTimestampPayloadOffset
+	}
+
+	if valOffset < tsOffset || valOffset > len(data) {
+		return 0, errs.ErrInvalidValuePayloadOffset
+	}
+
+	if tagOffset < valOffset || tagOffset > len(data) {
+		return 0, errs.ErrInvalidTagPayloadOffset
+	}
+
+	return computeBlobID(data[:tsOffset], data[tsOffset:valOffset], data[valOffset:tagOffset], data[tagOffset:]), nil
+}
+
+// TextBlobID computes the identity of an encoded text blob directly from its
+// bytes, without decompressing the data section or parsing the index.
+//
+// The identity is the xxHash64 of the blob head (header, metric names and index)
+// followed by the xxHash64 checksum of the stored data section. It matches
+// TextBlob.BlobID of the decoded blob.
+//
+// Parameters:
+//   - data: Encoded text blob
+//
+// Returns:
+//   - uint64: Deterministic blob identity
+//   - error: Header parsing error or invalid data offset
+func TextBlobID(data []byte) (uint64, error) {
+	if len(data) < section.HeaderSize {
+		return 0, errs.ErrInvalidHeaderSize
+	}
+
+	var header section.TextHeader
+	if err := header.Parse(data[:section.HeaderSize]); err != nil {
+		return 0, err
+	}
+
+	dataOffset := int(header.DataOffset)
+	if dataOffset < section.HeaderSize || dataOffset > len(data) {
+		return 0, errs.ErrInvalidTimestampPayloadOffset
+	}
+
+	return computeBlobID(data[:dataOffset], data[dataOffset:]), nil
+}
+
+// lazyBlobID is the identity of a decoded blob, hashed from the encoded blob on
+// the first BlobID call so that decoding does not pay for it. It keeps the
+// encoded head and payload sections reachable until then.
+type lazyBlobID struct {
+	once     sync.Once
+	head     []byte
+	sections [][]byte
+	id       uint64
+}
+
+// newLazyBlobID returns the identity of the blob with the given head and
+// payload sections, see computeBlobID.
+func newLazyBlobID(head []byte, sections ...[]byte) *lazyBlobID {
+	return &lazyBlobID{head: head, sections: sections}
+}
+
+// get returns the identity, computing it on the first call, or 0 if l is nil.
+func (l *lazyBlobID) get() uint64 {
+	if l == nil {
+		return 0
+	}
+
+	l.once.Do(func() {
+		l.id = computeBlobID(l.head, l.sections...)
+		l.head, l.sections = nil, nil
+	})
+
+	return l.id
+}
+
+// computeBlobID hashes the blob head followed by the checksum of each payload section.
+// Hashing section checksums rather than the sections themselves keeps the ID
+// independent of how the sections are stored (contiguous or split into parts).
+func computeBlobID(head []byte, sections ...[]byte) uint64 {
+	var digest xxhash.Digest
+	digest.Reset()
+	_, _ = digest.Write(head)
+
+	var sum [8]byte
+	for _, s := range sections {
+		binary.LittleEndian.PutUint64(sum[:], xxhash.Sum64(s))
+		_, _ = digest.Write(sum[:])
+	}
+
+	return digest.Sum64()
+}
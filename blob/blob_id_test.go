@@ -0,0 +1,92 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericBlobID(t *testing.T) {
+	data := encodePartsTestBlob(t, WithTagsEnabled(true), WithValueCompression(format.CompressionZstd))
+
+	id, err := NumericBlobID(data)
+	require.NoError(t, err)
+
+	again, err := NumericBlobID(append([]byte(nil), data...))
+	require.NoError(t, err)
+	require.Equal(t, id, again, "identical bytes must share an ID")
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	// Decode defers hashing to the first BlobID call, shared by blob copies
+	require.NotNil(t, blob.blobID.head)
+	blobCopy := blob
+	blobID, ok := blob.BlobID()
+	require.True(t, ok)
+	require.Equal(t, id, blobID)
+	require.Nil(t, blobCopy.blobID.head)
+
+	parts, err := SplitNumericBlob(data)
+	require.NoError(t, err)
+	partsID, err := parts.BlobID()
+	require.NoError(t, err)
+	require.Equal(t, id, partsID)
+
+	partsDecoder, err := NewNumericDecoderFromParts(parts)
+	require.NoError(t, err)
+	partsBlob, err := partsDecoder.Decode()
+	require.NoError(t, err)
+	partsBlobID, ok := partsBlob.BlobID()
+	require.True(t, ok)
+	require.Equal(t, id, partsBlobID)
+
+	// Any changed payload byte changes the ID
+	changed := append([]byte(nil), data...)
+	changed[len(changed)-1] ^= 0x01
+	changedID, err := NumericBlobID(changed)
+	require.NoError(t, err)
+	require.NotEqual(t, id, changedID)
+
+	_, err = NumericBlobID(data[:8])
+	require.ErrorIs(t, err, errs.ErrInvalidHeaderSize)
+
+	_, err = NumericBlobID(data[:len(data)/2])
+	require.Error(t, err)
+}
+
+func TestTextBlobID(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewTextEncoder(startTime)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 2))
+	require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), "up", ""))
+	require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Second).UnixMicro(), "down", ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	id, err := TextBlobID(data)
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	blobID, ok := blob.BlobID()
+	require.True(t, ok)
+	require.Equal(t, id, blobID)
+
+	_, err = TextBlobID(data[:8])
+	require.ErrorIs(t, err, errs.ErrInvalidHeaderSize)
+
+	var empty NumericBlob
+	_, ok = empty.BlobID()
+	require.False(t, ok)
+}
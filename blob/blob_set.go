@@ -2,9 +2,14 @@ package blob
 
 import (
 	"cmp"
+	"fmt"
 	"iter"
+	"math"
 	"slices"
+	"time"
 
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
 	"github.com/arloliu/mebo/section"
 )
 
@@ -123,9 +128,75 @@ type BlobSetIndexer interface {
 //   - Type-specific queries avoid type assertions and skip irrelevant blobs
 //   - Better CPU cache locality with similar data together
 //   - Generic queries check numeric first (95% of typical workloads)
+//
+// Name lookups consult each blob's stored metric names first and fall back to the
+// hashed metric ID for blobs without names. When blobs with names show that several
+// names share one ID, the fallback is refused for that ID: blobs without names cannot
+// tell which of the names they hold, so by-name queries skip them and
+// ResolveMetricName reports ErrAmbiguousMetric.
 type BlobSet struct {
-	numericBlobs []NumericBlob // Sorted by StartTime
-	textBlobs    []TextBlob    // Sorted by StartTime
+	numericBlobs []NumericBlob       // Sorted by StartTime
+	textBlobs    []TextBlob          // Sorted by StartTime
+	eventBlobs   []EventBlob         // Sorted by StartTime, see WithEventBlobs
+	ambiguous    map[uint64][]string // Metric IDs shared by several names, nil if none
+	hooks        ReadHooks           // Scan instrumentation, see WithReadHooks (nil if none)
+}
+
+// StatsTopN is the number of largest metrics reported in BlobSetStats.Largest.
+const StatsTopN = 10
+
+// MetricStats aggregates the statistics of a single metric across all blobs of a BlobSet.
+type MetricStats struct {
+	// MetricID is the metric ID.
+	MetricID uint64
+
+	// Name is the metric name, or empty if no blob carries a metric names payload.
+	Name string
+
+	// IsText reports whether the metric is stored in text blobs.
+	IsText bool
+
+	// BlobCount is the number of blobs containing the metric.
+	BlobCount int
+
+	// PointCount is the total number of data points across blobs.
+	PointCount int
+
+	// Bytes is the total size of the metric's decoded payload ranges (timestamps,
+	// values and tags) across blobs. Shared timestamp columns are counted for every
+	// metric referencing them.
+	Bytes int
+
+	// FirstTimestamp is the earliest timestamp of the metric (microseconds).
+	FirstTimestamp int64
+
+	// LastTimestamp is the latest timestamp of the metric (microseconds).
+	LastTimestamp int64
+}
+
+// BlobSetStats is a structured statistics and cardinality report of a BlobSet,
+// intended for capacity dashboards.
+type BlobSetStats struct {
+	// NumericBlobCount is the number of numeric blobs.
+	NumericBlobCount int
+
+	// TextBlobCount is the number of text blobs.
+	TextBlobCount int
+
+	// MetricCount is the number of distinct metrics (union across blobs).
+	MetricCount int
+
+	// PointCount is the total number of data points.
+	PointCount int
+
+	// Bytes is the total size of all metrics' decoded payload ranges.
+	Bytes int
+
+	// Metrics holds the per-metric statistics, sorted by MetricID.
+	Metrics []MetricStats
+
+	// Largest holds up to StatsTopN metrics with the most Bytes, largest first.
+	Largest []MetricStats
 }
 
 var (
@@ -160,11 +231,13 @@ func NewBlobSet(numericBlobs []NumericBlob, textBlobs []TextBlob) BlobSet {
 	return BlobSet{
 		numericBlobs: sortedNumeric,
 		textBlobs:    sortedText,
+		ambiguous:    ambiguousNames(sortedNumeric, sortedText, nil),
 	}
 }
 
 // DecodeBlobSet creates a new BlobSet from a list of encoded byte slices.
-// Each byte slice is parsed to determine if it's a numeric or text blob.
+// Each byte slice is parsed to determine if it's a numeric, text, or event blob.
+// Blobs are decoded sequentially; see DecodeBlobSetWithOptions for parallel decoding.
 //
 // Parameters:
 //   - blobs: List of byte slices representing encoded blobs
@@ -173,41 +246,11 @@ func NewBlobSet(numericBlobs []NumericBlob, textBlobs []TextBlob) BlobSet {
 //   - BlobSet: Constructed BlobSet with parsed blobs
 //   - error: Parsing or decoding error
 func DecodeBlobSet(blobs ...[]byte) (BlobSet, error) {
-	numericBlobs := make([]NumericBlob, 0, len(blobs)/2)
-	textBlobs := make([]TextBlob, 0, len(blobs)/2)
-	for _, blob := range blobs {
-		if section.IsNumericBlob(blob) {
-			decoder, err := NewNumericDecoder(blob)
-			if err != nil {
-				return BlobSet{}, err
-			}
-
-			nb, err := decoder.Decode()
-			if err != nil {
-				return BlobSet{}, err
-			}
-
-			numericBlobs = append(numericBlobs, nb)
-		} else if section.IsTextBlob(blob) {
-			decoder, err := NewTextDecoder(blob)
-			if err != nil {
-				return BlobSet{}, err
-			}
-
-			tb, err := decoder.Decode()
-			if err != nil {
-				return BlobSet{}, err
-			}
-
-			textBlobs = append(textBlobs, tb)
-		}
-	}
-
-	return NewBlobSet(numericBlobs, textBlobs), nil
+	return DecodeBlobSetWithOptions(blobs)
 }
 
 func (bs BlobSet) AllNumerics(metricID uint64) iter.Seq2[int, NumericDataPoint] {
-	return func(yield func(int, NumericDataPoint) bool) {
+	return bs.observeNumericScan(metricID, "", func(yield func(int, NumericDataPoint) bool) {
 		index := 0
 		for _, blob := range bs.numericBlobs {
 			if blob.HasMetricID(metricID) {
@@ -219,14 +262,14 @@ func (bs BlobSet) AllNumerics(metricID uint64) iter.Seq2[int, NumericDataPoint]
 				}
 			}
 		}
-	}
+	})
 }
 
 func (bs BlobSet) AllNumericsByName(metricName string) iter.Seq2[int, NumericDataPoint] {
-	return func(yield func(int, NumericDataPoint) bool) {
+	return bs.observeNumericScan(0, metricName, func(yield func(int, NumericDataPoint) bool) {
 		index := 0
 		for _, blob := range bs.numericBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for _, dp := range blob.AllByName(metricName) {
 					if !yield(index, dp) {
 						return
@@ -235,11 +278,98 @@ func (bs BlobSet) AllNumericsByName(metricName string) iter.Seq2[int, NumericDat
 				}
 			}
 		}
-	}
+	})
+}
+
+// AllNumericsWhere iterates through the numeric data points of the given metric
+// ID for which pred returns true, e.g. "points above 95" across a long range.
+//
+// The predicate is pushed down into each blob's decode loop (see
+// NumericBlob.AllWhere), so rejected data points are never built and their tags
+// are not decoded.
+//
+// Parameters:
+//   - metricID: The metric ID to scan
+//   - pred: Predicate on the timestamp and value of each data point
+//
+// Returns:
+//   - iter.Seq2[int, NumericDataPoint]: Iterator yielding (index, data point)
+//     pairs of the matching data points, where index is the global position of
+//     the data point across all numeric blobs, as in AllNumerics
+//
+// Example:
+//
+//	for idx, dp := range blobSet.AllNumericsWhere(metricID, func(_ int64, v float64) bool { return v > 95 }) {
+//	    fmt.Printf("[%d] ts=%d, val=%f\n", idx, dp.Ts, dp.Val)
+//	}
+func (bs BlobSet) AllNumericsWhere(metricID uint64, pred func(ts int64, v float64) bool) iter.Seq2[int, NumericDataPoint] {
+	return bs.observeNumericScan(metricID, "", func(yield func(int, NumericDataPoint) bool) {
+		offset := 0
+		for _, blob := range bs.numericBlobs {
+			for idx, dp := range blob.AllWhere(metricID, pred) {
+				if !yield(offset+idx, dp) {
+					return
+				}
+			}
+			offset += blob.Len(metricID)
+		}
+	})
+}
+
+// AllNumericsPage iterates through one page of numeric data points for the given
+// metric ID, for API backends exposing paginated series data.
+//
+// The first offset data points are skipped and at most limit data points are
+// yielded. Whole blobs are skipped using their per-metric point counts, so only
+// the blob containing the page start is decoded from its beginning.
+//
+// Parameters:
+//   - metricID: The metric ID to query
+//   - offset: Number of leading data points to skip (negative values are treated as 0)
+//   - limit: Maximum number of data points to yield (nothing is yielded if limit <= 0)
+//
+// Returns:
+//   - iter.Seq2[int, NumericDataPoint]: Iterator yielding (global index, data point) pairs,
+//     where the first yielded index is offset
+//
+// Example:
+//
+//	// Third page of 100 points
+//	for idx, dp := range blobSet.AllNumericsPage(metricID, 200, 100) {
+//	    fmt.Printf("[%d] ts=%d, val=%f\n", idx, dp.Ts, dp.Val)
+//	}
+func (bs BlobSet) AllNumericsPage(metricID uint64, offset, limit int) iter.Seq2[int, NumericDataPoint] {
+	return bs.allNumericsPage(offset, limit,
+		func(b *NumericBlob) int { return b.Len(metricID) },
+		func(b *NumericBlob) iter.Seq2[int, NumericDataPoint] { return b.All(metricID) },
+	)
+}
+
+// AllNumericsPageByName iterates through one page of numeric data points for the
+// given metric name. See AllNumericsPage for the paging semantics.
+//
+// Parameters:
+//   - metricName: The metric name to query
+//   - offset: Number of leading data points to skip (negative values are treated as 0)
+//   - limit: Maximum number of data points to yield (nothing is yielded if limit <= 0)
+//
+// Returns:
+//   - iter.Seq2[int, NumericDataPoint]: Iterator yielding (global index, data point) pairs
+func (bs BlobSet) AllNumericsPageByName(metricName string, offset, limit int) iter.Seq2[int, NumericDataPoint] {
+	return bs.allNumericsPage(offset, limit,
+		func(b *NumericBlob) int {
+			if !resolvesName(bs.ambiguous, b, metricName) {
+				return 0
+			}
+
+			return b.LenByName(metricName)
+		},
+		func(b *NumericBlob) iter.Seq2[int, NumericDataPoint] { return b.AllByName(metricName) },
+	)
 }
 
 func (bs BlobSet) AllTexts(metricID uint64) iter.Seq2[int, TextDataPoint] {
-	return func(yield func(int, TextDataPoint) bool) {
+	return bs.observeTextScan(metricID, "", func(yield func(int, TextDataPoint) bool) {
 		index := 0
 		for _, blob := range bs.textBlobs {
 			if blob.HasMetricID(metricID) {
@@ -251,14 +381,14 @@ func (bs BlobSet) AllTexts(metricID uint64) iter.Seq2[int, TextDataPoint] {
 				}
 			}
 		}
-	}
+	})
 }
 
 func (bs BlobSet) AllTextsByName(metricName string) iter.Seq2[int, TextDataPoint] {
-	return func(yield func(int, TextDataPoint) bool) {
+	return bs.observeTextScan(0, metricName, func(yield func(int, TextDataPoint) bool) {
 		index := 0
 		for _, blob := range bs.textBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for _, dp := range blob.AllByName(metricName) {
 					if !yield(index, dp) {
 						return
@@ -267,7 +397,7 @@ func (bs BlobSet) AllTextsByName(metricName string) iter.Seq2[int, TextDataPoint
 				}
 			}
 		}
-	}
+	})
 }
 
 func (bs BlobSet) AllNumericValues(metricID uint64) iter.Seq2[int, float64] {
@@ -290,7 +420,7 @@ func (bs BlobSet) AllNumericValuesByName(metricName string) iter.Seq2[int, float
 	return func(yield func(int, float64) bool) {
 		index := 0
 		for _, blob := range bs.numericBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for val := range blob.AllValuesByName(metricName) {
 					if !yield(index, val) {
 						return
@@ -322,7 +452,7 @@ func (bs BlobSet) AllTextValuesByName(metricName string) iter.Seq2[int, string]
 	return func(yield func(int, string) bool) {
 		index := 0
 		for _, blob := range bs.textBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for val := range blob.AllValuesByName(metricName) {
 					if !yield(index, val) {
 						return
@@ -374,7 +504,7 @@ func (bs BlobSet) AllTimestampsByName(metricName string) iter.Seq2[int, int64] {
 		foundInNumeric := false
 
 		for _, blob := range bs.numericBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				foundInNumeric = true
 				for ts := range blob.AllTimestampsByName(metricName) {
 					if !yield(index, ts) {
@@ -390,7 +520,7 @@ func (bs BlobSet) AllTimestampsByName(metricName string) iter.Seq2[int, int64] {
 		}
 
 		for _, blob := range bs.textBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for ts := range blob.AllTimestampsByName(metricName) {
 					if !yield(index, ts) {
 						return
@@ -402,6 +532,43 @@ func (bs BlobSet) AllTimestampsByName(metricName string) iter.Seq2[int, int64] {
 	}
 }
 
+// AllTimes returns an iterator over the timestamps of the given metric ID across all
+// blobs as UTC time.Time values. Each numeric blob's timestamps are converted using
+// that blob's own timestamp unit, so blobs encoded with different units can be mixed.
+//
+// Like AllTimestamps, numeric blobs take precedence over text blobs.
+//
+// Parameters:
+//   - metricID: The metric ID to iterate over
+//
+// Returns:
+//   - iter.Seq2[int, time.Time]: Iterator yielding (global index, time) pairs
+func (bs BlobSet) AllTimes(metricID uint64) iter.Seq2[int, time.Time] {
+	return bs.allTimes(
+		func(b *NumericBlob) bool { return b.HasMetricID(metricID) },
+		func(b *NumericBlob) iter.Seq2[int, time.Time] { return b.AllTimes(metricID) },
+		func(b *TextBlob) bool { return b.HasMetricID(metricID) },
+		func(b *TextBlob) iter.Seq2[int, time.Time] { return b.AllTimes(metricID) },
+	)
+}
+
+// AllTimesByName returns an iterator over the timestamps of the given metric name
+// across all blobs as UTC time.Time values. See AllTimes for details.
+//
+// Parameters:
+//   - metricName: The metric name to iterate over
+//
+// Returns:
+//   - iter.Seq2[int, time.Time]: Iterator yielding (global index, time) pairs
+func (bs BlobSet) AllTimesByName(metricName string) iter.Seq2[int, time.Time] {
+	return bs.allTimes(
+		func(b *NumericBlob) bool { return resolvesName(bs.ambiguous, b, metricName) },
+		func(b *NumericBlob) iter.Seq2[int, time.Time] { return b.AllTimesByName(metricName) },
+		func(b *TextBlob) bool { return resolvesName(bs.ambiguous, b, metricName) },
+		func(b *TextBlob) iter.Seq2[int, time.Time] { return b.AllTimesByName(metricName) },
+	)
+}
+
 func (bs BlobSet) AllTags(metricID uint64) iter.Seq2[int, string] {
 	return func(yield func(int, string) bool) {
 		index := 0
@@ -442,7 +609,7 @@ func (bs BlobSet) AllTagsByName(metricName string) iter.Seq2[int, string] {
 		foundInNumeric := false
 
 		for _, blob := range bs.numericBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				foundInNumeric = true
 				for tag := range blob.AllTagsByName(metricName) {
 					if !yield(index, tag) {
@@ -458,7 +625,7 @@ func (bs BlobSet) AllTagsByName(metricName string) iter.Seq2[int, string] {
 		}
 
 		for _, blob := range bs.textBlobs {
-			if blob.HasMetricName(metricName) {
+			if resolvesName(bs.ambiguous, blob, metricName) {
 				for tag := range blob.AllTagsByName(metricName) {
 					if !yield(index, tag) {
 						return
@@ -511,7 +678,7 @@ func (bs BlobSet) TimestampAtByName(metricName string, index int) (int64, bool)
 	curIdx := 0
 
 	for _, blob := range bs.numericBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -523,7 +690,7 @@ func (bs BlobSet) TimestampAtByName(metricName string, index int) (int64, bool)
 
 	curIdx = 0 // Reset for text blobs
 	for _, blob := range bs.textBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -579,7 +746,7 @@ func (bs BlobSet) TagAtByName(metricName string, index int) (string, bool) {
 
 	// Try numeric blobs first (95% case)
 	for _, blob := range bs.numericBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -591,7 +758,7 @@ func (bs BlobSet) TagAtByName(metricName string, index int) (string, bool) {
 
 	curIdx = 0 // Reset for text blobs
 	for _, blob := range bs.textBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -631,7 +798,7 @@ func (bs BlobSet) NumericValueAtByName(metricName string, index int) (float64, b
 
 	curIdx := 0
 	for _, blob := range bs.numericBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -671,7 +838,7 @@ func (bs BlobSet) TextValueAtByName(metricName string, index int) (string, bool)
 
 	curIdx := 0
 	for _, blob := range bs.textBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -719,7 +886,7 @@ func (bs BlobSet) NumericAtByName(metricName string, index int) (NumericDataPoin
 
 	curIdx := 0
 	for _, blob := range bs.numericBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -775,7 +942,7 @@ func (bs BlobSet) TextAtByName(metricName string, index int) (TextDataPoint, boo
 
 	curIdx := 0
 	for _, blob := range bs.textBlobs {
-		if !blob.HasMetricName(metricName) {
+		if !resolvesName(bs.ambiguous, blob, metricName) {
 			continue
 		}
 		length := blob.LenByName(metricName)
@@ -817,7 +984,7 @@ func (bs BlobSet) TextAtByName(metricName string, index int) (TextDataPoint, boo
 //	blobSet := NewBlobSet(numericBlobs, textBlobs)
 //	matNumeric := blobSet.MaterializeNumeric()
 //	val, ok := matNumeric.ValueAt(metricID, 150)  // O(1) access
-func (bs BlobSet) MaterializeNumeric() MaterializedNumericBlobSet {
+func (bs BlobSet) MaterializeNumeric(opts ...MaterializeOption) MaterializedNumericBlobSet {
 	if len(bs.numericBlobs) == 0 {
 		return MaterializedNumericBlobSet{
 			data:  make(map[uint64]materializedNumericMetricSet),
@@ -828,7 +995,7 @@ func (bs BlobSet) MaterializeNumeric() MaterializedNumericBlobSet {
 	// Create NumericBlobSet and delegate to its Materialize()
 	numericSet := &NumericBlobSet{blobs: bs.numericBlobs}
 
-	return numericSet.Materialize()
+	return numericSet.Materialize(opts...)
 }
 
 // MaterializeText materializes all text blobs in this BlobSet into a
@@ -852,7 +1019,7 @@ func (bs BlobSet) MaterializeNumeric() MaterializedNumericBlobSet {
 //	blobSet := NewBlobSet(numericBlobs, textBlobs)
 //	matText := blobSet.MaterializeText()
 //	val, ok := matText.ValueAt(metricID, 150)  // O(1) access
-func (bs BlobSet) MaterializeText() MaterializedTextBlobSet {
+func (bs BlobSet) MaterializeText(opts ...MaterializeOption) MaterializedTextBlobSet {
 	if len(bs.textBlobs) == 0 {
 		return MaterializedTextBlobSet{
 			data:  make(map[uint64]materializedTextMetricSet),
@@ -863,7 +1030,7 @@ func (bs BlobSet) MaterializeText() MaterializedTextBlobSet {
 	// Create TextBlobSet and delegate to its Materialize()
 	textSet := &TextBlobSet{blobs: bs.textBlobs}
 
-	return textSet.Materialize()
+	return textSet.Materialize(opts...)
 }
 
 // MaterializeNumericMetric materializes a single numeric metric by ID from all numeric blobs
@@ -1076,7 +1243,7 @@ func (bs BlobSet) MetricLenByName(metricName string) int {
 	totalLen := 0
 
 	for i := range bs.numericBlobs {
-		if bs.numericBlobs[i].HasMetricName(metricName) {
+		if resolvesName(bs.ambiguous, &bs.numericBlobs[i], metricName) {
 			totalLen += bs.numericBlobs[i].LenByName(metricName)
 		}
 	}
@@ -1086,7 +1253,7 @@ func (bs BlobSet) MetricLenByName(metricName string) int {
 	}
 
 	for i := range bs.textBlobs {
-		if bs.textBlobs[i].HasMetricName(metricName) {
+		if resolvesName(bs.ambiguous, &bs.textBlobs[i], metricName) {
 			totalLen += bs.textBlobs[i].LenByName(metricName)
 		}
 	}
@@ -1132,7 +1299,7 @@ func (bs BlobSet) IsNumericMetric(metricID uint64) bool {
 //	}
 func (bs BlobSet) IsNumericMetricByName(metricName string) bool {
 	for i := range bs.numericBlobs {
-		if bs.numericBlobs[i].HasMetricName(metricName) {
+		if resolvesName(bs.ambiguous, &bs.numericBlobs[i], metricName) {
 			return true
 		}
 	}
@@ -1178,7 +1345,7 @@ func (bs BlobSet) IsTextMetric(metricID uint64) bool {
 //	}
 func (bs BlobSet) IsTextMetricByName(metricName string) bool {
 	for i := range bs.textBlobs {
-		if bs.textBlobs[i].HasMetricName(metricName) {
+		if resolvesName(bs.ambiguous, &bs.textBlobs[i], metricName) {
 			return true
 		}
 	}
@@ -1230,12 +1397,246 @@ func (bs BlobSet) MetricDuration(metricID uint64) int64 {
 //	duration := blobSet.MetricDurationByName("cpu.usage")
 //	fmt.Printf("Metric spans %d timestamp units\n", duration)
 func (bs BlobSet) MetricDurationByName(metricName string) int64 {
-	duration := calculateDurationByName(bs.numericBlobs, metricName)
+	duration := calculateDurationByName(bs.numericBlobs, bs.ambiguous, metricName)
 	if duration > 0 {
 		return duration
 	}
 
-	return calculateDurationByName(bs.textBlobs, metricName)
+	return calculateDurationByName(bs.textBlobs, bs.ambiguous, metricName)
+}
+
+// Stats aggregates statistics across all blobs of the set: the union of metrics,
+// per-metric point counts, time coverage and payload bytes, and the StatsTopN
+// largest metrics.
+//
+// Returns:
+//   - BlobSetStats: Aggregated report (Metrics sorted by MetricID)
+//
+// Example:
+//
+//	stats := blobSet.Stats()
+//	for _, m := range stats.Largest {
+//	    fmt.Printf("%016x %s: %d points, %d bytes\n", m.MetricID, m.Name, m.PointCount, m.Bytes)
+//	}
+func (bs BlobSet) Stats() BlobSetStats {
+	stats := BlobSetStats{
+		NumericBlobCount: len(bs.numericBlobs),
+		TextBlobCount:    len(bs.textBlobs),
+	}
+
+	byID := make(map[uint64]*MetricStats)
+	covered := make(map[uint64]bool)
+	add := func(metricID uint64, isText bool, count, size int, first, last int64, ok bool) {
+		m := byID[metricID]
+		if m == nil {
+			m = &MetricStats{MetricID: metricID, IsText: isText}
+			byID[metricID] = m
+		}
+
+		m.BlobCount++
+		m.PointCount += count
+		m.Bytes += size
+
+		switch {
+		case !ok:
+		case !covered[metricID]:
+			m.FirstTimestamp, m.LastTimestamp = first, last
+			covered[metricID] = true
+		default:
+			m.FirstTimestamp = min(m.FirstTimestamp, first)
+			m.LastTimestamp = max(m.LastTimestamp, last)
+		}
+	}
+
+	for i := range bs.numericBlobs {
+		b := &bs.numericBlobs[i]
+		b.index.ForEach(func(e section.NumericIndexEntry) bool {
+			first, okFirst := b.TimestampAt(e.MetricID, 0)
+			last, okLast := b.TimestampAt(e.MetricID, e.Count-1)
+			add(e.MetricID, false, e.Count, e.TimestampLength+e.ValueLength+e.TagLength, first, last, okFirst && okLast)
+
+			return true
+		})
+		for name, e := range b.index.nameMap() {
+			byID[e.MetricID].Name = name
+		}
+	}
+
+	for i := range bs.textBlobs {
+		b := &bs.textBlobs[i]
+		b.index.ForEach(func(e section.TextIndexEntry) bool {
+			first, okFirst := b.TimestampAt(e.MetricID, 0)
+			last, okLast := b.TimestampAt(e.MetricID, int(e.Count)-1)
+			add(e.MetricID, true, int(e.Count), int(e.Size), first, last, okFirst && okLast)
+
+			return true
+		})
+		for name, e := range b.index.nameMap() {
+			byID[e.MetricID].Name = name
+		}
+	}
+
+	stats.Metrics = make([]MetricStats, 0, len(byID))
+	for _, m := range byID {
+		stats.Metrics = append(stats.Metrics, *m)
+		stats.PointCount += m.PointCount
+		stats.Bytes += m.Bytes
+	}
+	stats.MetricCount = len(stats.Metrics)
+
+	slices.SortFunc(stats.Metrics, func(a, b MetricStats) int {
+		return cmp.Compare(a.MetricID, b.MetricID)
+	})
+
+	stats.Largest = slices.Clone(stats.Metrics)
+	slices.SortStableFunc(stats.Largest, func(a, b MetricStats) int {
+		return cmp.Compare(b.Bytes, a.Bytes)
+	})
+	stats.Largest = stats.Largest[:min(StatsTopN, len(stats.Largest))]
+
+	return stats
+}
+
+// ResolveMetricName returns the metric ID used for metricName and checks that every
+// blob of the set can resolve the name unambiguously.
+//
+// Blobs that store metric names always resolve by name. Blobs without names resolve
+// by hashing the name, which is ambiguous when other blobs show that several names
+// share the hashed ID. By-name queries skip such blobs; this method reports them.
+//
+// Parameters:
+//   - metricName: The metric name to resolve
+//
+// Returns:
+//   - uint64: The hashed metric ID of metricName
+//   - error: ErrAmbiguousMetric if a blob without names holds the shared ID
+//
+// Example:
+//
+//	if _, err := blobSet.ResolveMetricName("cpu.usage"); errors.Is(err, errs.ErrAmbiguousMetric) {
+//	    log.Printf("some blobs cannot be queried by name: %v", err)
+//	}
+func (bs BlobSet) ResolveMetricName(metricName string) (uint64, error) {
+	metricID := hash.ID(metricName)
+
+	names, ok := bs.ambiguous[metricID]
+	if !ok {
+		return metricID, nil
+	}
+
+	unnamed := 0
+	for i := range bs.numericBlobs {
+		if !bs.numericBlobs[i].HasMetricNames() && bs.numericBlobs[i].HasMetricID(metricID) {
+			unnamed++
+		}
+	}
+	for i := range bs.textBlobs {
+		if !bs.textBlobs[i].HasMetricNames() && bs.textBlobs[i].HasMetricID(metricID) {
+			unnamed++
+		}
+	}
+
+	if unnamed > 0 {
+		return metricID, fmt.Errorf("%w: %q shares metric ID %#x with %v, %d blob(s) without metric names hold that ID",
+			errs.ErrAmbiguousMetric, metricName, metricID, names, unnamed)
+	}
+
+	return metricID, nil
+}
+
+// allNumericsPage implements AllNumericsPage and AllNumericsPageByName on top of
+// per-blob length and iterator lookups.
+func (bs BlobSet) allNumericsPage(
+	offset, limit int,
+	lenFn func(b *NumericBlob) int,
+	allFn func(b *NumericBlob) iter.Seq2[int, NumericDataPoint],
+) iter.Seq2[int, NumericDataPoint] {
+	return func(yield func(int, NumericDataPoint) bool) {
+		if limit <= 0 {
+			return
+		}
+
+		skip := max(offset, 0)
+		index := skip
+		end := skip + min(limit, math.MaxInt-skip)
+		for i := range bs.numericBlobs {
+			b := &bs.numericBlobs[i]
+			n := lenFn(b)
+			if skip >= n {
+				skip -= n // Skip the whole blob without decoding it
+				continue
+			}
+
+			for j, dp := range allFn(b) {
+				if j < skip {
+					continue
+				}
+
+				if !yield(index, dp) {
+					return
+				}
+
+				index++
+				if index == end {
+					return
+				}
+			}
+			skip = 0
+		}
+	}
+}
+
+// allTimes implements AllTimes and AllTimesByName. Text blobs are only consulted
+// when no numeric blob contains the metric.
+func (bs BlobSet) allTimes(
+	hasNumeric func(b *NumericBlob) bool,
+	numericTimes func(b *NumericBlob) iter.Seq2[int, time.Time],
+	hasText func(b *TextBlob) bool,
+	textTimes func(b *TextBlob) iter.Seq2[int, time.Time],
+) iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		index := 0
+		foundInNumeric := false
+
+		for i := range bs.numericBlobs {
+			b := &bs.numericBlobs[i]
+			if !hasNumeric(b) {
+				continue
+			}
+
+			foundInNumeric = true
+			for _, t := range numericTimes(b) {
+				if !yield(index, t) {
+					return
+				}
+				index++
+			}
+		}
+
+		if foundInNumeric {
+			return
+		}
+
+		for i := range bs.textBlobs {
+			b := &bs.textBlobs[i]
+			if !hasText(b) {
+				continue
+			}
+
+			for _, t := range textTimes(b) {
+				if !yield(index, t) {
+					return
+				}
+				index++
+			}
+		}
+	}
+}
+
+// nameHolder is implemented by blobs that can be queried by metric name.
+type nameHolder interface {
+	HasMetricName(metricName string) bool
+	HasMetricNames() bool
 }
 
 // blobAccessor defines the interface for accessing blob metadata and timestamps.
@@ -1243,6 +1644,7 @@ func (bs BlobSet) MetricDurationByName(metricName string) int64 {
 type blobAccessor[T any] interface {
 	HasMetricID(metricID uint64) bool
 	HasMetricName(metricName string) bool
+	HasMetricNames() bool
 	Len(metricID uint64) int
 	LenByName(metricName string) int
 	TimestampAt(metricID uint64, index int) (int64, bool)
@@ -1310,7 +1712,7 @@ func calculateDuration[T blobAccessor[T]](blobs []T, metricID uint64) int64 {
 //
 // Performance: Optimized bi-directional search - finds first from start, last from end.
 // Average case: O(n/2) when metric is in middle blobs. Best case: O(2) when in first and last.
-func calculateDurationByName[T blobAccessor[T]](blobs []T, metricName string) int64 {
+func calculateDurationByName[T blobAccessor[T]](blobs []T, ambiguous map[uint64][]string, metricName string) int64 {
 	if len(blobs) == 0 {
 		return 0
 	}
@@ -1318,7 +1720,7 @@ func calculateDurationByName[T blobAccessor[T]](blobs []T, metricName string) in
 	// Find first blob containing the metric (forward search)
 	firstIdx := -1
 	for i := range blobs {
-		if blobs[i].HasMetricName(metricName) {
+		if resolvesName(ambiguous, blobs[i], metricName) {
 			firstIdx = i
 			break // Stop as soon as we find the first
 		}
@@ -1331,7 +1733,7 @@ func calculateDurationByName[T blobAccessor[T]](blobs []T, metricName string) in
 	// Find last blob containing the metric (reverse search)
 	lastIdx := firstIdx // Default to first if it's the only one
 	for i := len(blobs) - 1; i > firstIdx; i-- {
-		if blobs[i].HasMetricName(metricName) {
+		if resolvesName(ambiguous, blobs[i], metricName) {
 			lastIdx = i
 			break // Stop as soon as we find the last
 		}
@@ -1360,3 +1762,68 @@ func calculateDurationByName[T blobAccessor[T]](blobs []T, metricName string) in
 
 	return 0
 }
+
+// resolvesName reports whether b holds metricName. Blobs with stored names are
+// checked by name; blobs without names fall back to the hashed ID unless that ID is
+// ambiguous in the set.
+func resolvesName[B nameHolder](ambiguous map[uint64][]string, b B, metricName string) bool {
+	if len(ambiguous) == 0 || b.HasMetricNames() {
+		return b.HasMetricName(metricName)
+	}
+
+	if _, ok := ambiguous[hash.ID(metricName)]; ok {
+		return false
+	}
+
+	return b.HasMetricName(metricName)
+}
+
+// ambiguousNames returns the metric IDs that blobs with stored names assign to more
+// than one name, with the sorted names, or nil if there are none.
+func ambiguousNames(numericBlobs []NumericBlob, textBlobs []TextBlob, eventBlobs []EventBlob) map[uint64][]string {
+	seen := make(map[uint64]string)
+	var ambiguous map[uint64][]string
+
+	record := func(names []string) {
+		for _, name := range names {
+			id := hash.ID(name)
+			first, ok := seen[id]
+			if !ok {
+				seen[id] = name
+				continue
+			}
+			if first == name {
+				continue
+			}
+
+			if ambiguous == nil {
+				ambiguous = make(map[uint64][]string)
+			}
+			if len(ambiguous[id]) == 0 {
+				ambiguous[id] = []string{first}
+			}
+			if !slices.Contains(ambiguous[id], name) {
+				ambiguous[id] = append(ambiguous[id], name)
+				slices.Sort(ambiguous[id])
+			}
+		}
+	}
+
+	for i := range numericBlobs {
+		if numericBlobs[i].HasMetricNames() {
+			record(numericBlobs[i].MetricNames())
+		}
+	}
+	for i := range textBlobs {
+		if textBlobs[i].HasMetricNames() {
+			record(textBlobs[i].MetricNames())
+		}
+	}
+	for i := range eventBlobs {
+		if eventBlobs[i].HasMetricNames() {
+			record(eventBlobs[i].MetricNames())
+		}
+	}
+
+	return ambiguous
+}
@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"iter"
+)
+
+// DataPointKind identifies the value type of a DataPoint.
+type DataPointKind uint8
+
+const (
+	// DataPointNumeric marks a data point of a numeric metric; DataPoint.Val holds the value.
+	DataPointNumeric DataPointKind = iota + 1
+	// DataPointText marks a data point of a text metric; DataPoint.Text holds the value.
+	DataPointText
+)
+
+// String returns the name of the kind.
+func (k DataPointKind) String() string {
+	switch k {
+	case DataPointNumeric:
+		return "Numeric"
+	case DataPointText:
+		return "Text"
+	default:
+		return "Unknown"
+	}
+}
+
+// DataPoint is a data point of a metric whose type is not known in advance, as
+// yielded by BlobSet.All and BlobSet.AllByName.
+type DataPoint struct {
+	// Ts is the timestamp, the unit is defined by the caller when adding data points
+	Ts int64
+	// Kind is the value type of the data point
+	Kind DataPointKind
+	// Val is the float value of a numeric data point, 0 for text data points
+	Val float64
+	// Text is the value of a text data point, empty for numeric data points
+	Text string
+	// Tag is the optional tag associated with this data point
+	Tag string
+}
+
+// IsNumeric reports whether the data point belongs to a numeric metric.
+func (dp DataPoint) IsNumeric() bool {
+	return dp.Kind == DataPointNumeric
+}
+
+// IsText reports whether the data point belongs to a text metric.
+func (dp DataPoint) IsText() bool {
+	return dp.Kind == DataPointText
+}
+
+// All iterates through all data points of the given metric ID, whether the
+// metric is numeric or text, so callers that don't know a metric's type ahead of
+// time avoid a separate IsNumericMetric or IsTextMetric lookup.
+//
+// Like TimestampAt, the numeric blobs take precedence: text blobs are only
+// iterated if no numeric blob holds the metric.
+//
+// Parameters:
+//   - metricID: The metric ID to iterate
+//
+// Returns:
+//   - iter.Seq2[int, DataPoint]: Global index and data point for each iteration
+//
+// Example:
+//
+//	for _, dp := range blobSet.All(metricID) {
+//	    if dp.IsNumeric() {
+//	        fmt.Println(dp.Ts, dp.Val)
+//	    } else {
+//	        fmt.Println(dp.Ts, dp.Text)
+//	    }
+//	}
+func (bs BlobSet) All(metricID uint64) iter.Seq2[int, DataPoint] {
+	return bs.allDataPoints(
+		func(b *NumericBlob) (iter.Seq2[int, NumericDataPoint], bool) {
+			if !b.HasMetricID(metricID) {
+				return nil, false
+			}
+
+			return b.All(metricID), true
+		},
+		func(b *TextBlob) (iter.Seq2[int, TextDataPoint], bool) {
+			if !b.HasMetricID(metricID) {
+				return nil, false
+			}
+
+			return b.All(metricID), true
+		},
+	)
+}
+
+// AllByName iterates through all data points of the given metric name, whether
+// the metric is numeric or text. See All for details.
+//
+// Parameters:
+//   - metricName: The metric name to iterate
+//
+// Returns:
+//   - iter.Seq2[int, DataPoint]: Global index and data point for each iteration
+func (bs BlobSet) AllByName(metricName string) iter.Seq2[int, DataPoint] {
+	return bs.allDataPoints(
+		func(b *NumericBlob) (iter.Seq2[int, NumericDataPoint], bool) {
+			if !resolvesName(bs.ambiguous, b, metricName) {
+				return nil, false
+			}
+
+			return b.AllByName(metricName), true
+		},
+		func(b *TextBlob) (iter.Seq2[int, TextDataPoint], bool) {
+			if !resolvesName(bs.ambiguous, b, metricName) {
+				return nil, false
+			}
+
+			return b.AllByName(metricName), true
+		},
+	)
+}
+
+// allDataPoints implements All and AllByName. The lookup functions return the
+// data points of a blob and whether the blob holds the metric.
+func (bs BlobSet) allDataPoints(
+	numeric func(*NumericBlob) (iter.Seq2[int, NumericDataPoint], bool),
+	text func(*TextBlob) (iter.Seq2[int, TextDataPoint], bool),
+) iter.Seq2[int, DataPoint] {
+	return func(yield func(int, DataPoint) bool) {
+		index, found := 0, false
+		for i := range bs.numericBlobs {
+			seq, ok := numeric(&bs.numericBlobs[i])
+			if !ok {
+				continue
+			}
+
+			found = true
+			for _, dp := range seq {
+				if !yield(index, DataPoint{Ts: dp.Ts, Kind: DataPointNumeric, Val: dp.Val, Tag: dp.Tag}) {
+					return
+				}
+				index++
+			}
+		}
+
+		if found {
+			return
+		}
+
+		for i := range bs.textBlobs {
+			seq, ok := text(&bs.textBlobs[i])
+			if !ok {
+				continue
+			}
+
+			for _, dp := range seq {
+				if !yield(index, DataPoint{Ts: dp.Ts, Kind: DataPointText, Text: dp.Val, Tag: dp.Tag}) {
+					return
+				}
+				index++
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package blob
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func TestBlobSet_All(t *testing.T) {
+	set := createMixedBlobSetForMaterialization(t)
+
+	collect := func(seq iter.Seq2[int, DataPoint]) []DataPoint {
+		var points []DataPoint
+		for i, dp := range seq {
+			require.Equal(t, len(points), i)
+			points = append(points, dp)
+		}
+
+		return points
+	}
+
+	t.Run("Numeric", func(t *testing.T) {
+		points := collect(set.AllByName("cpu.usage"))
+		require.Len(t, points, 6)
+		require.True(t, points[0].IsNumeric())
+		require.Equal(t, DataPoint{Ts: points[4].Ts, Kind: DataPointNumeric, Val: 11, Tag: "cpu"}, points[4])
+		require.Equal(t, points, collect(set.All(hash.ID("cpu.usage"))))
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		points := collect(set.AllByName("status"))
+		require.Len(t, points, 4)
+		require.True(t, points[0].IsText())
+		require.Equal(t, "state_D", points[3].Text)
+		require.Equal(t, "svc", points[3].Tag)
+		require.Equal(t, "Text", points[3].Kind.String())
+	})
+
+	t.Run("NumericPrecedence", func(t *testing.T) {
+		points := collect(set.AllByName("shared"))
+		require.Len(t, points, 2)
+		require.True(t, points[1].IsNumeric())
+	})
+
+	t.Run("EarlyStopAndMissing", func(t *testing.T) {
+		for i := range set.All(hash.ID("status")) {
+			require.Zero(t, i)
+			break
+		}
+		require.Empty(t, collect(set.AllByName("missing")))
+	})
+}
@@ -0,0 +1,266 @@
+package blob
+
+import (
+	"math"
+
+	"github.com/arloliu/mebo/internal/sketch"
+	"github.com/arloliu/mebo/section"
+)
+
+// topKCapacityFactor is the number of metrics the TopK sketch monitors per
+// requested result, trading memory for accuracy on sets with many metrics.
+const topKCapacityFactor = 8
+
+// TopKAggregate selects the per-metric aggregate TopK ranks metrics by.
+type TopKAggregate uint8
+
+const (
+	// TopKCount ranks metrics by their number of data points.
+	TopKCount TopKAggregate = iota
+	// TopKSum ranks metrics by the sum of their values, e.g. bytes sent or
+	// request counts. It is meant for non-negative metrics: negative, infinite
+	// and NaN values are ignored.
+	TopKSum
+)
+
+// MetricAggregate is a metric ranked by TopK.
+type MetricAggregate struct {
+	MetricID uint64
+	// Value is the estimated aggregate, an upper bound of the exact one.
+	Value float64
+	// Error is the maximum overestimation of Value, 0 when Value is exact.
+	Error float64
+}
+
+// String returns the name of the aggregate.
+func (a TopKAggregate) String() string {
+	switch a {
+	case TopKCount:
+		return "Count"
+	case TopKSum:
+		return "Sum"
+	default:
+		return "Unknown"
+	}
+}
+
+// Quantile estimates the q-quantile of the numeric values of a metric within a
+// time range, e.g. the p99 latency of the last hour, without materializing the
+// data points.
+//
+// Values are streamed into a t-digest, so memory stays constant regardless of
+// the number of data points. The estimate is exact for the minimum (q = 0) and
+// maximum (q = 1) and typically within a fraction of a percent of the rank in
+// between, with higher accuracy near the tails. NaN values are ignored. Blobs
+// encoded with WithTimeBounds are skipped without decoding when they do not
+// overlap the range; those also encoded with WithSketches and lying within the
+// range contribute their recorded sketch without decoding any values.
+//
+// Parameters:
+//   - metricID: The metric ID to query
+//   - q: The quantile in [0, 1], e.g. 0.99 for the 99th percentile
+//   - start: Start of the time range (inclusive), in the blobs' timestamp unit
+//   - end: End of the time range (inclusive), in the blobs' timestamp unit
+//
+// Returns:
+//   - float64: The estimated quantile
+//   - bool: false if q is outside [0, 1] or no value falls within the range
+//
+// Example:
+//
+//	p99, ok := blobSet.Quantile(latencyID, 0.99, from.UnixMicro(), to.UnixMicro())
+//	if ok {
+//	    fmt.Printf("p99 latency: %.1fms\n", p99)
+//	}
+func (bs BlobSet) Quantile(metricID uint64, q float64, start, end int64) (float64, bool) {
+	if !(q >= 0 && q <= 1) {
+		return 0, false
+	}
+
+	td := sketch.NewTDigest(sketch.DefaultCompression)
+	for _, blob := range bs.numericBlobs {
+		overlap, within := blob.overlapsRange(start, end)
+		if !overlap {
+			continue
+		}
+
+		if within {
+			if sk, ok := blob.Sketch(metricID); ok {
+				td.Merge(sk.digest)
+
+				continue
+			}
+		}
+
+		blob.forEachValueInRange(metricID, start, end, td.Add)
+	}
+
+	if td.Count() == 0 {
+		return 0, false
+	}
+
+	return td.Quantile(q), true
+}
+
+// Mean returns the mean of the numeric values of a metric within a time range,
+// e.g. the average CPU usage of the last day, without materializing the data
+// points.
+//
+// Values are summed with compensated (Kahan) summation, so the mean stays
+// accurate across many blobs and mixed magnitudes. NaN values are ignored.
+// Blobs encoded with WithTimeBounds are skipped without decoding when they do
+// not overlap the range; those also encoded with WithValueSums and lying within
+// the range contribute their recorded sum and count without decoding any values.
+//
+// Parameters:
+//   - metricID: The metric ID to query
+//   - start: Start of the time range (inclusive), in the blobs' timestamp unit
+//   - end: End of the time range (inclusive), in the blobs' timestamp unit
+//
+// Returns:
+//   - float64: The mean of the values
+//   - bool: false if no value falls within the range
+//
+// Example:
+//
+//	avg, ok := blobSet.Mean(cpuID, from.UnixMicro(), to.UnixMicro())
+//	if ok {
+//	    fmt.Printf("average CPU: %.1f%%\n", avg)
+//	}
+func (bs BlobSet) Mean(metricID uint64, start, end int64) (float64, bool) {
+	var total sketch.KahanSum
+	for _, blob := range bs.numericBlobs {
+		overlap, within := blob.overlapsRange(start, end)
+		if !overlap {
+			continue
+		}
+
+		if within {
+			if sum, ok := blob.valueSum(metricID); ok {
+				total.Merge(sum)
+
+				continue
+			}
+		}
+
+		blob.forEachValueInRange(metricID, start, end, total.Add)
+	}
+
+	if total.Count() == 0 {
+		return 0, false
+	}
+
+	return total.Value() / float64(total.Count()), true
+}
+
+// TopK returns the k numeric metrics with the largest aggregate within a time
+// range, e.g. the busiest hosts by request count, without exporting all points.
+//
+// Each metric is aggregated per blob and the per-blob aggregates are fed into a
+// space-saving sketch monitoring 8*k metrics. Results are exact while the set
+// holds at most that many distinct metrics; beyond that, every metric whose
+// aggregate exceeds 1/(8*k) of the total is guaranteed to be found and Error
+// bounds the overestimation of its Value. Blobs encoded with WithTimeBounds are
+// skipped without decoding when they do not overlap the range, and counted from
+// their index alone when they lie within it.
+//
+// Parameters:
+//   - k: Maximum number of metrics to return
+//   - agg: The aggregate to rank by (TopKCount or TopKSum)
+//   - start: Start of the time range (inclusive), in the blobs' timestamp unit
+//   - end: End of the time range (inclusive), in the blobs' timestamp unit
+//
+// Returns:
+//   - []MetricAggregate: Up to k metrics with a positive aggregate, sorted by
+//     Value descending and then by metric ID ascending. Nil if k <= 0 or agg is
+//     unknown.
+//
+// Example:
+//
+//	for _, m := range blobSet.TopK(10, blob.TopKSum, from.UnixMicro(), to.UnixMicro()) {
+//	    name, _ := blobSet.ResolveMetricName(m.MetricID)
+//	    fmt.Printf("%s: %.0f (±%.0f)\n", name, m.Value, m.Error)
+//	}
+func (bs BlobSet) TopK(k int, agg TopKAggregate, start, end int64) []MetricAggregate {
+	if k <= 0 || agg > TopKSum {
+		return nil
+	}
+
+	ss := sketch.NewSpaceSaving(k * topKCapacityFactor)
+	for _, blob := range bs.numericBlobs {
+		overlap, within := blob.overlapsRange(start, end)
+		if !overlap {
+			continue
+		}
+
+		for _, metricID := range blob.MetricIDs() {
+			if within && agg == TopKCount {
+				ss.Add(metricID, float64(blob.Len(metricID)))
+
+				continue
+			}
+
+			ss.Add(metricID, blob.aggregate(metricID, agg, start, end))
+		}
+	}
+
+	items := ss.TopK(k)
+	result := make([]MetricAggregate, len(items))
+	for i, item := range items {
+		result[i] = MetricAggregate{MetricID: item.Key, Value: item.Count, Error: item.Error}
+	}
+
+	return result
+}
+
+// overlapsRange reports whether the blob may hold data points within [start, end]
+// and whether all of its data points certainly do. Blobs without time bounds
+// are assumed to overlap partially.
+func (b NumericBlob) overlapsRange(start, end int64) (overlap, within bool) {
+	if !b.HasTimeBounds() {
+		return true, false
+	}
+
+	minTs, maxTs, ok := b.timeBounds()
+	if !ok || maxTs < start || minTs > end {
+		return false, false
+	}
+
+	return true, minTs >= start && maxTs <= end
+}
+
+// aggregate computes the aggregate of the metric's data points within [start, end].
+func (b NumericBlob) aggregate(metricID uint64, agg TopKAggregate, start, end int64) float64 {
+	total := 0.0
+	b.forEachValueInRange(metricID, start, end, func(v float64) {
+		switch agg {
+		case TopKCount:
+			total++
+		case TopKSum:
+			if v > 0 && !math.IsInf(v, 1) {
+				total += v
+			}
+		}
+	})
+
+	return total
+}
+
+// forEachValueInRange calls fn for each value of the metric whose timestamp is
+// within [start, end], decoding through the tagless fast paths.
+func (b NumericBlob) forEachValueInRange(metricID uint64, start, end int64, fn func(v float64)) {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return
+	}
+
+	tagless := b
+	tagless.flags &^= section.FlagTagEnabled
+	tagless.forEachFromEntry(entry, func(_ int, dp NumericDataPoint) bool {
+		if dp.Ts >= start && dp.Ts <= end {
+			fn(dp.Val)
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,148 @@
+package blob
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// encodeAnalyticsBlob encodes one blob holding the given metrics, each with one
+// data point per second starting at start.
+func encodeAnalyticsBlob(t *testing.T, start time.Time, metrics map[string][]float64, opts ...NumericEncoderOption) NumericBlob {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start, append([]NumericEncoderOption{WithTimeBounds(true), WithTagsEnabled(true)}, opts...)...)
+	require.NoError(t, err)
+	for name, values := range metrics {
+		require.NoError(t, encoder.StartMetricName(name, len(values)))
+		for i, v := range values {
+			require.NoError(t, encoder.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), v, "tag"))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func TestBlobSet_Quantile(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	first, second := make([]float64, 500), make([]float64, 500)
+	for i := range 500 {
+		first[i] = float64(i + 1)
+		second[i] = float64(i + 501)
+	}
+	bs := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base, map[string][]float64{"latency": first}),
+		encodeAnalyticsBlob(t, base.Add(time.Hour), map[string][]float64{"latency": second}),
+	}, nil)
+	id := hash.ID("latency")
+	all := [2]int64{base.UnixMicro(), base.Add(2 * time.Hour).UnixMicro()}
+
+	p50, ok := bs.Quantile(id, 0.5, all[0], all[1])
+	require.True(t, ok)
+	require.InDelta(t, 500, p50, 5)
+
+	p99, ok := bs.Quantile(id, 0.99, all[0], all[1])
+	require.True(t, ok)
+	require.InDelta(t, 990, p99, 2)
+
+	maxVal, ok := bs.Quantile(id, 1, all[0], all[1])
+	require.True(t, ok)
+	require.Equal(t, 1000.0, maxVal)
+
+	// The range covers the first 100 seconds of the first blob only.
+	p100, ok := bs.Quantile(id, 1, base.UnixMicro(), base.Add(99*time.Second).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 100.0, p100)
+
+	_, ok = bs.Quantile(id, 1.5, all[0], all[1])
+	require.False(t, ok)
+	_, ok = bs.Quantile(id, 0.5, all[1]+1, all[1]+2)
+	require.False(t, ok)
+	_, ok = bs.Quantile(hash.ID("missing"), 0.5, all[0], all[1])
+	require.False(t, ok)
+}
+
+func TestBlobSet_Mean(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	first, second := make([]float64, 1000), make([]float64, 1000)
+	for i := range 1000 {
+		first[i] = 0.1
+		second[i] = 1e12 + 0.3
+	}
+	first[7] = math.NaN()
+	id := hash.ID("usage")
+	all := [2]int64{base.UnixMicro(), base.Add(2 * time.Hour).UnixMicro()}
+	want := (999*0.1 + 1000*(1e12+0.3)) / 1999
+
+	// Recorded sums and decoded values give the same mean.
+	for _, opts := range [][]NumericEncoderOption{nil, {WithValueSums(true)}} {
+		bs := NewBlobSet([]NumericBlob{
+			encodeAnalyticsBlob(t, base, map[string][]float64{"usage": first}, opts...),
+			encodeAnalyticsBlob(t, base.Add(time.Hour), map[string][]float64{"usage": second}, opts...),
+		}, nil)
+
+		mean, ok := bs.Mean(id, all[0], all[1])
+		require.True(t, ok)
+		require.InEpsilon(t, want, mean, 1e-15)
+
+		// The range covers the first 100 seconds of the first blob only.
+		mean, ok = bs.Mean(id, base.UnixMicro(), base.Add(99*time.Second).UnixMicro())
+		require.True(t, ok)
+		require.InDelta(t, 0.1, mean, 1e-15)
+
+		_, ok = bs.Mean(id, all[1]+1, all[1]+2)
+		require.False(t, ok)
+		_, ok = bs.Mean(hash.ID("missing"), all[0], all[1])
+		require.False(t, ok)
+	}
+}
+
+func TestBlobSet_TopK(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	bs := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base, map[string][]float64{
+			"a": {1, 1, 1},
+			"b": {10, 20},
+			"c": {5, -3},
+		}),
+		encodeAnalyticsBlob(t, base.Add(time.Hour), map[string][]float64{
+			"a": {1, 1},
+			"c": {100},
+		}),
+	}, nil)
+	all := [2]int64{base.UnixMicro(), base.Add(2 * time.Hour).UnixMicro()}
+
+	require.Equal(t, []MetricAggregate{
+		{MetricID: hash.ID("a"), Value: 5},
+		{MetricID: hash.ID("c"), Value: 3},
+	}, bs.TopK(2, TopKCount, all[0], all[1]))
+
+	require.Equal(t, []MetricAggregate{
+		{MetricID: hash.ID("c"), Value: 105},
+		{MetricID: hash.ID("b"), Value: 30},
+		{MetricID: hash.ID("a"), Value: 5},
+	}, bs.TopK(5, TopKSum, all[0], all[1]))
+
+	// Only the first second of the first blob.
+	require.Equal(t, []MetricAggregate{
+		{MetricID: hash.ID("b"), Value: 10},
+		{MetricID: hash.ID("c"), Value: 5},
+		{MetricID: hash.ID("a"), Value: 1},
+	}, bs.TopK(3, TopKSum, base.UnixMicro(), base.UnixMicro()))
+
+	require.Nil(t, bs.TopK(0, TopKCount, all[0], all[1]))
+	require.Nil(t, bs.TopK(1, TopKAggregate(9), all[0], all[1]))
+	require.Equal(t, "Sum", TopKSum.String())
+}
@@ -0,0 +1,240 @@
+package blob
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/arloliu/mebo/section"
+)
+
+// ChronologyIssueKind classifies a ChronologyIssue.
+type ChronologyIssueKind uint8
+
+const (
+	// ChronologyOverlap reports a metric whose data in a blob starts before its
+	// data in the preceding blob (by start time) ends.
+	ChronologyOverlap ChronologyIssueKind = iota + 1
+
+	// ChronologyStartOrder reports a blob whose earliest data timestamp precedes
+	// the earliest data timestamp of the preceding blob, although its StartTime
+	// is later.
+	ChronologyStartOrder
+
+	// ChronologyNotMonotone reports a metric whose timestamps decrease within a blob.
+	ChronologyNotMonotone
+)
+
+// String returns the name of the issue kind.
+func (k ChronologyIssueKind) String() string {
+	switch k {
+	case ChronologyOverlap:
+		return "Overlap"
+	case ChronologyStartOrder:
+		return "StartOrder"
+	case ChronologyNotMonotone:
+		return "NotMonotone"
+	default:
+		return "Unknown"
+	}
+}
+
+// ChronologyIssue describes a single chronology violation found by
+// BlobSet.ValidateChronology.
+type ChronologyIssue struct {
+	// Kind classifies the issue.
+	Kind ChronologyIssueKind
+
+	// MetricID is the affected metric, or 0 for ChronologyStartOrder.
+	MetricID uint64
+
+	// Name is the metric name, or empty if no blob carries a metric names payload.
+	Name string
+
+	// IsText reports whether the issue concerns the text blobs of the set.
+	IsText bool
+
+	// BlobIndex is the index of the offending blob in NumericBlobs or TextBlobs.
+	BlobIndex int
+
+	// Index is the data point index within the blob for ChronologyNotMonotone,
+	// and 0 otherwise.
+	Index int
+
+	// Timestamp is the offending timestamp.
+	Timestamp int64
+
+	// Previous is the timestamp that Timestamp should not precede: the metric's
+	// previous timestamp, or the preceding blob's earliest timestamp for
+	// ChronologyStartOrder.
+	Previous int64
+}
+
+// ChronologyReport is the structured result of BlobSet.ValidateChronology.
+type ChronologyReport struct {
+	// MetricCount is the number of distinct metrics checked.
+	MetricCount int
+
+	// Issues holds every violation, ordered by blob kind (numeric first), blob
+	// index, metric ID and data point index.
+	Issues []ChronologyIssue
+}
+
+// OK reports whether no chronology issue was found.
+func (r ChronologyReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// MetricIssues returns the issues of the given metric ID.
+func (r ChronologyReport) MetricIssues(metricID uint64) []ChronologyIssue {
+	var issues []ChronologyIssue
+	for _, issue := range r.Issues {
+		if issue.MetricID == metricID {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// ValidateChronology verifies that the blobs of the set form a consistent
+// timeline: per metric, timestamps must be non-decreasing within each blob and
+// across blob boundaries (blobs must not overlap), and the StartTime order of
+// the blobs must match the order of their earliest data timestamps. Numeric and
+// text blobs are checked separately; event blobs are not checked.
+//
+// Timestamps are compared only with each other, so any caller-defined unit works.
+// Blobs with equal StartTime have no defined order and are not start-order checked.
+//
+// Returns:
+//   - ChronologyReport: Report listing every violation; OK reports a clean set
+//
+// Example:
+//
+//	report := blobSet.ValidateChronology()
+//	for _, issue := range report.Issues {
+//	    log.Printf("%s: metric %016x blob %d ts=%d prev=%d",
+//	        issue.Kind, issue.MetricID, issue.BlobIndex, issue.Timestamp, issue.Previous)
+//	}
+func (bs BlobSet) ValidateChronology() ChronologyReport {
+	var report ChronologyReport
+	names := make(map[uint64]string)
+
+	numeric := chronologyChecker{report: &report, last: make(map[uint64]int64)}
+	for i := range bs.numericBlobs {
+		b := &bs.numericBlobs[i]
+		numeric.checkBlob(i, b.startTimeMicros, func(visit func(uint64, iter.Seq[int64])) {
+			b.index.ForEach(func(e section.NumericIndexEntry) bool {
+				visit(e.MetricID, b.allTimestampsFromEntry(e))
+				return true
+			})
+		})
+		for name, e := range b.index.nameMap() {
+			names[e.MetricID] = name
+		}
+	}
+
+	text := chronologyChecker{report: &report, isText: true, last: make(map[uint64]int64)}
+	for i := range bs.textBlobs {
+		b := &bs.textBlobs[i]
+		text.checkBlob(i, b.startTimeMicros, func(visit func(uint64, iter.Seq[int64])) {
+			b.index.ForEach(func(e section.TextIndexEntry) bool {
+				visit(e.MetricID, b.allTimestampsFromEntry(e))
+				return true
+			})
+		})
+		for name, e := range b.index.nameMap() {
+			names[e.MetricID] = name
+		}
+	}
+
+	metrics := make(map[uint64]struct{}, len(numeric.last)+len(text.last))
+	for id := range numeric.last {
+		metrics[id] = struct{}{}
+	}
+	for id := range text.last {
+		metrics[id] = struct{}{}
+	}
+	report.MetricCount = len(metrics)
+
+	for i := range report.Issues {
+		report.Issues[i].Name = names[report.Issues[i].MetricID]
+	}
+
+	slices.SortFunc(report.Issues, func(a, b ChronologyIssue) int {
+		if a.IsText != b.IsText {
+			if a.IsText {
+				return 1
+			}
+
+			return -1
+		}
+
+		return cmp.Or(
+			cmp.Compare(a.BlobIndex, b.BlobIndex),
+			cmp.Compare(a.MetricID, b.MetricID),
+			cmp.Compare(a.Index, b.Index),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+
+	return report
+}
+
+// chronologyChecker checks the blobs of one kind in start time order.
+type chronologyChecker struct {
+	report *ChronologyReport
+	isText bool
+	last   map[uint64]int64 // Last timestamp seen per metric
+
+	prevStart int64 // StartTime of the preceding blob with data (microseconds)
+	prevFirst int64 // Earliest data timestamp of the preceding blob with data
+	hasPrev   bool
+}
+
+// checkBlob checks the metrics visited by forEach against the preceding blobs.
+func (c *chronologyChecker) checkBlob(blobIndex int, start int64, forEach func(visit func(uint64, iter.Seq[int64]))) {
+	var first int64
+	hasData := false
+
+	forEach(func(metricID uint64, timestamps iter.Seq[int64]) {
+		prev, seen := c.last[metricID]
+		i := 0
+		for ts := range timestamps {
+			switch {
+			case i == 0 && seen && ts < prev:
+				c.add(ChronologyIssue{Kind: ChronologyOverlap, MetricID: metricID, BlobIndex: blobIndex, Timestamp: ts, Previous: prev})
+			case i > 0 && ts < prev:
+				c.add(ChronologyIssue{Kind: ChronologyNotMonotone, MetricID: metricID, BlobIndex: blobIndex, Index: i, Timestamp: ts, Previous: prev})
+			}
+
+			if !hasData || ts < first {
+				first = ts
+				hasData = true
+			}
+
+			prev = ts
+			seen = true
+			i++
+		}
+
+		if seen {
+			c.last[metricID] = prev
+		}
+	})
+
+	if !hasData {
+		return
+	}
+
+	if c.hasPrev && start > c.prevStart && first < c.prevFirst {
+		c.add(ChronologyIssue{Kind: ChronologyStartOrder, BlobIndex: blobIndex, Timestamp: first, Previous: c.prevFirst})
+	}
+
+	c.prevStart, c.prevFirst, c.hasPrev = start, first, true
+}
+
+func (c *chronologyChecker) add(issue ChronologyIssue) {
+	issue.IsText = c.isText
+	c.report.Issues = append(c.report.Issues, issue)
+}
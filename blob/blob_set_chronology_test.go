@@ -0,0 +1,118 @@
+package blob
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func createChronologyNumericBlob(t *testing.T, start time.Time, timestamps map[string][]int64) NumericBlob {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	for _, name := range slices.Sorted(maps.Keys(timestamps)) {
+		require.NoError(t, encoder.StartMetricName(name, len(timestamps[name])))
+		for _, ts := range timestamps[name] {
+			require.NoError(t, encoder.AddDataPoint(ts, 1, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func TestBlobSet_ValidateChronology(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	later := start.Add(time.Hour)
+
+	t.Run("Clean", func(t *testing.T) {
+		set := NewBlobSet([]NumericBlob{
+			createChronologyNumericBlob(t, later, map[string][]int64{"cpu": {40, 50}, "mem": {45}}),
+			createChronologyNumericBlob(t, start, map[string][]int64{"cpu": {10, 20, 30}, "mem": {30}}),
+		}, nil)
+
+		report := set.ValidateChronology()
+		require.True(t, report.OK())
+		require.Equal(t, 2, report.MetricCount)
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		set := NewBlobSet([]NumericBlob{
+			createChronologyNumericBlob(t, start, map[string][]int64{"cpu": {10, 20, 30}, "mem": {15}}),
+			createChronologyNumericBlob(t, later, map[string][]int64{"cpu": {25, 40}, "mem": {50}}),
+		}, nil)
+
+		report := set.ValidateChronology()
+		require.False(t, report.OK())
+		require.Equal(t, []ChronologyIssue{
+			{Kind: ChronologyOverlap, MetricID: hash.ID("cpu"), BlobIndex: 1, Timestamp: 25, Previous: 30},
+		}, report.Issues)
+		require.Len(t, report.MetricIssues(hash.ID("cpu")), 1)
+		require.Empty(t, report.MetricIssues(hash.ID("mem")))
+	})
+
+	t.Run("NotMonotone", func(t *testing.T) {
+		set := NewBlobSet([]NumericBlob{
+			createChronologyNumericBlob(t, start, map[string][]int64{"cpu": {10, 30, 20, 40}}),
+		}, nil)
+
+		report := set.ValidateChronology()
+		require.Equal(t, []ChronologyIssue{
+			{Kind: ChronologyNotMonotone, MetricID: hash.ID("cpu"), Index: 2, Timestamp: 20, Previous: 30},
+		}, report.Issues)
+	})
+
+	t.Run("StartOrder", func(t *testing.T) {
+		set := NewBlobSet([]NumericBlob{
+			createChronologyNumericBlob(t, start, map[string][]int64{"cpu": {100, 110}}),
+			createChronologyNumericBlob(t, later, map[string][]int64{"mem": {50, 60}}),
+		}, nil)
+
+		report := set.ValidateChronology()
+		require.Equal(t, []ChronologyIssue{
+			{Kind: ChronologyStartOrder, BlobIndex: 1, Timestamp: 50, Previous: 100},
+		}, report.Issues)
+		require.Equal(t, "StartOrder", report.Issues[0].Kind.String())
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		encodeText := func(start time.Time, timestamps ...int64) TextBlob {
+			encoder, err := NewTextEncoder(start)
+			require.NoError(t, err)
+			require.NoError(t, encoder.StartMetricID(7, len(timestamps)))
+			for _, ts := range timestamps {
+				require.NoError(t, encoder.AddDataPoint(ts, "v", ""))
+			}
+			require.NoError(t, encoder.EndMetric())
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			decoder, err := NewTextDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			return blob
+		}
+
+		set := NewBlobSet(nil, []TextBlob{encodeText(start, 10, 20), encodeText(later, 15)})
+
+		report := set.ValidateChronology()
+		require.Equal(t, []ChronologyIssue{
+			{Kind: ChronologyOverlap, MetricID: 7, IsText: true, BlobIndex: 1, Timestamp: 15, Previous: 20},
+		}, report.Issues)
+	})
+}
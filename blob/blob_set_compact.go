@@ -0,0 +1,374 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// Compact merges the blobs of the set into one numeric blob and one text blob,
+// e.g. to consolidate a day of hourly blobs at the end of the day.
+//
+// The data points of each metric are concatenated in the order of the set, as
+// yielded by AllNumerics and AllTexts. A type stays split into several blobs
+// only where a single blob cannot hold it: the k-th blob holds up to the
+// encoder's per-metric data point limit of every metric, starting at data point
+// k times that limit, and blobs never exceed MaxMetricCount metrics.
+//
+// Like NumericBlob.Trim, the compacted blobs are re-encoded with the encodings
+// of the set's first blob of each type and store their payloads uncompressed.
+// Tags are kept if any blob has tags, and metric names are kept if every blob
+// of the type stores them; otherwise metrics are identified by ID. Metric
+// metadata, time bounds, sketches, tag summaries and value sums follow the first
+// numeric blob; metrics stored as deltas against a reference metric are stored
+// with their plain values. Event blobs are not compacted.
+//
+// Returns:
+//   - []NumericBlob: Compacted numeric blobs sorted by start time, usually one,
+//     nil if the set has no numeric blobs
+//   - []TextBlob: Compacted text blobs sorted by start time, usually one, nil if
+//     the set has no text blobs
+//   - error: ErrUnsupportedBlobFeature for values with an extension encoding, or
+//     encoding errors
+//
+// Example:
+//
+//	set, _ := blob.DecodeBlobSet(hourlyBlobs...)
+//	numeric, text, err := set.Compact()
+//	if err != nil {
+//	    return err
+//	}
+//	daily := blob.NewBlobSet(numeric, text)
+func (bs BlobSet) Compact() ([]NumericBlob, []TextBlob, error) {
+	numeric, err := compactNumericBlobs(bs.numericBlobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	text, err := compactTextBlobs(bs.textBlobs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return numeric, text, nil
+}
+
+// compactKey identifies a metric across the blobs being compacted. The name is
+// empty unless every blob stores metric names.
+type compactKey struct {
+	metricID uint64
+	name     string
+}
+
+// compactChunk is the data point range [lo, hi) of a metric in a compacted blob.
+type compactChunk struct {
+	metric int
+	lo, hi int
+}
+
+// compactPlan splits metrics of the given data point counts into compacted
+// blobs of at most maxPoints data points per metric and MaxMetricCount metrics.
+func compactPlan(counts []int, maxPoints int) [][]compactChunk {
+	var plan [][]compactChunk
+	for lo := 0; ; lo += maxPoints {
+		var round []compactChunk
+		for i, count := range counts {
+			if lo < count {
+				round = append(round, compactChunk{metric: i, lo: lo, hi: min(lo+maxPoints, count)})
+			}
+		}
+		if len(round) == 0 {
+			return plan
+		}
+
+		for chunk := range slices.Chunk(round, MaxMetricCount) {
+			plan = append(plan, chunk)
+		}
+	}
+}
+
+// compactNumericBlobs merges numeric blobs sorted by start time, see Compact.
+func compactNumericBlobs(blobs []NumericBlob) ([]NumericBlob, error) {
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+
+	// The first blob encodes the compacted blobs, with the tags and metric
+	// metadata of all blobs.
+	proto := blobs[0]
+	proto.metricMeta = make(map[uint64]MetricMeta)
+	named := true
+	for _, b := range blobs {
+		if b.valEncType == format.TypeExtension {
+			return nil, fmt.Errorf("%w: extension value encoding", errs.ErrUnsupportedBlobFeature)
+		}
+		if b.HasTag() {
+			proto.flags |= section.FlagTagEnabled
+		}
+		named = named && b.HasMetricNames()
+		for metricID, meta := range b.metricMeta {
+			if _, ok := proto.metricMeta[metricID]; !ok {
+				proto.metricMeta[metricID] = meta
+			}
+		}
+	}
+	unit := proto.TimestampUnit()
+
+	var metrics []trimmedMetric
+	keys := make(map[compactKey]int)
+	for _, b := range blobs {
+		// Iterate stored values; the decode-time transform is carried over instead.
+		raw := b
+		raw.valTransform = nil
+		from := b.TimestampUnit()
+
+		var names map[section.NumericIndexEntry]string
+		if named {
+			names = make(map[section.NumericIndexEntry]string)
+			for name, entry := range b.index.nameMap() {
+				names[entry] = name
+			}
+		}
+
+		b.index.ForEach(func(entry section.NumericIndexEntry) bool {
+			key := compactKey{metricID: entry.MetricID, name: names[entry]}
+			i, ok := keys[key]
+			if !ok {
+				i = len(metrics)
+				keys[key] = i
+				metrics = append(metrics, trimmedMetric{metricID: key.metricID, name: key.name})
+			}
+
+			m := &metrics[i]
+			for _, dp := range raw.allFromEntry(entry) {
+				m.timestamps = append(m.timestamps, from.Convert(dp.Ts, unit))
+				m.values = append(m.values, dp.Val)
+				m.tags = append(m.tags, dp.Tag)
+			}
+
+			return true
+		})
+	}
+
+	probe, err := NewNumericEncoder(proto.StartTime(), proto.trimEncoderOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int, len(metrics))
+	for i, m := range metrics {
+		counts[i] = len(m.timestamps)
+	}
+
+	plan := compactPlan(counts, probe.MaxDataPoints())
+	compacted := make([]NumericBlob, 0, len(plan))
+	for _, chunks := range plan {
+		part := make([]trimmedMetric, len(chunks))
+		first := int64(math.MaxInt64)
+		for i, c := range chunks {
+			m := metrics[c.metric]
+			part[i] = trimmedMetric{
+				metricID:   m.metricID,
+				name:       m.name,
+				timestamps: m.timestamps[c.lo:c.hi],
+				values:     m.values[c.lo:c.hi],
+				tags:       m.tags[c.lo:c.hi],
+			}
+			first = min(first, slices.Min(part[i].timestamps))
+		}
+
+		data, err := proto.encodeTrimmed(unit.Time(first), part)
+		if err != nil {
+			return nil, err
+		}
+
+		decoder, err := NewNumericDecoder(data)
+		if err != nil {
+			return nil, err
+		}
+
+		blob, err := decoder.Decode()
+		if err != nil {
+			return nil, err
+		}
+		blob.valTransform = proto.valTransform
+		blob.interner = proto.interner
+
+		compacted = append(compacted, blob)
+	}
+
+	return sortedByStart(compacted, func(b NumericBlob) int64 { return b.startTimeMicros }), nil
+}
+
+// compactedText holds the data points of one metric merged by Compact.
+type compactedText struct {
+	key        compactKey
+	timestamps []int64
+	values     []string
+	tags       []string
+}
+
+// compactTextBlobs merges text blobs sorted by start time, see Compact.
+func compactTextBlobs(blobs []TextBlob) ([]TextBlob, error) {
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+
+	named := true
+	for _, b := range blobs {
+		named = named && b.HasMetricNames()
+	}
+
+	var metrics []compactedText
+	keys := make(map[compactKey]int)
+	for _, b := range blobs {
+		var names map[section.TextIndexEntry]string
+		if named {
+			names = make(map[section.TextIndexEntry]string)
+			for name, entry := range b.index.nameMap() {
+				names[entry] = name
+			}
+		}
+
+		b.index.ForEach(func(entry section.TextIndexEntry) bool {
+			key := compactKey{metricID: entry.MetricID, name: names[entry]}
+			i, ok := keys[key]
+			if !ok {
+				i = len(metrics)
+				keys[key] = i
+				metrics = append(metrics, compactedText{key: key})
+			}
+
+			m := &metrics[i]
+			for _, dp := range b.allFromEntry(entry) {
+				m.timestamps = append(m.timestamps, dp.Ts)
+				m.values = append(m.values, dp.Val)
+				m.tags = append(m.tags, dp.Tag)
+			}
+
+			return true
+		})
+	}
+
+	counts := make([]int, len(metrics))
+	for i, m := range metrics {
+		counts[i] = len(m.timestamps)
+	}
+
+	opts := compactTextEncoderOptions(blobs)
+	plan := compactPlan(counts, math.MaxUint16)
+	compacted := make([]TextBlob, 0, len(plan))
+	for _, chunks := range plan {
+		blob, err := encodeCompactedText(metrics, chunks, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		compacted = append(compacted, blob)
+	}
+
+	return sortedByStart(compacted, func(b TextBlob) int64 { return b.startTimeMicros }), nil
+}
+
+// encodeCompactedText encodes the chunks of metrics into a decoded text blob.
+func encodeCompactedText(metrics []compactedText, chunks []compactChunk, opts []TextEncoderOption) (TextBlob, error) {
+	first := int64(math.MaxInt64)
+	for _, c := range chunks {
+		first = min(first, slices.Min(metrics[c.metric].timestamps[c.lo:c.hi]))
+	}
+
+	encoder, err := NewTextEncoder(time.UnixMicro(first), opts...)
+	if err != nil {
+		return TextBlob{}, err
+	}
+
+	for _, c := range chunks {
+		m := metrics[c.metric]
+		if m.key.name != "" {
+			err = encoder.StartMetricName(m.key.name, c.hi-c.lo)
+		} else {
+			err = encoder.StartMetricID(m.key.metricID, c.hi-c.lo)
+		}
+		if err != nil {
+			return TextBlob{}, err
+		}
+
+		for i := c.lo; i < c.hi; i++ {
+			if err := encoder.AddDataPoint(m.timestamps[i], m.values[i], m.tags[i]); err != nil {
+				return TextBlob{}, err
+			}
+		}
+
+		if err := encoder.EndMetric(); err != nil {
+			return TextBlob{}, err
+		}
+	}
+
+	data, err := encoder.Finish()
+	if err != nil {
+		return TextBlob{}, err
+	}
+
+	decoder, err := NewTextDecoder(data)
+	if err != nil {
+		return TextBlob{}, err
+	}
+
+	return decoder.Decode()
+}
+
+// compactTextEncoderOptions returns encoder options that reproduce the
+// encodings of the first blob without compression, with tags, long values and
+// seek indexes if any blob uses them.
+func compactTextEncoderOptions(blobs []TextBlob) []TextEncoderOption {
+	first := blobs[0]
+	opts := []TextEncoderOption{
+		WithTextTimestampEncoding(first.tsEncType),
+		WithTextDataCompression(format.CompressionNone),
+		WithTextValueRLE(first.HasValueRLE()),
+	}
+	if first.IsBigEndian() {
+		opts = append(opts, WithTextBigEndian())
+	}
+
+	var tagged, longValues bool
+	interval := 0
+	for _, b := range blobs {
+		tagged = tagged || b.HasTag()
+		longValues = longValues || b.HasValueCompression()
+		if b.HasSeekIndex() && interval == 0 {
+			b.index.ForEach(func(entry section.TextIndexEntry) bool {
+				interval = int(entry.Reserved1)
+				return interval == 0
+			})
+		}
+	}
+	opts = append(opts, WithTextTagsEnabled(tagged))
+
+	// Values over 255 bytes need the per-value compression layout; shorter
+	// values stay raw.
+	if longValues {
+		opts = append(opts, WithTextValueCompression(math.MaxUint8+1, format.CompressionZstd))
+	}
+	if interval > 0 {
+		opts = append(opts, WithTextSeekIndex(interval))
+	}
+
+	return opts
+}
+
+// sortedByStart sorts blobs by start time, keeping the order of blobs with the
+// same start time.
+func sortedByStart[T any](blobs []T, start func(T) int64) []T {
+	slices.SortStableFunc(blobs, func(a, b T) int {
+		return cmp.Compare(start(a), start(b))
+	})
+
+	return blobs
+}
@@ -0,0 +1,121 @@
+package blob
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func TestBlobSet_Compact(t *testing.T) {
+	set := NewBlobSet(createTestBlobs(t, 3), createTestTextBlobs(t))
+	id := hash.ID("metric1")
+
+	numeric, text, err := set.Compact()
+	require.NoError(t, err)
+	require.Len(t, numeric, 1)
+	require.Len(t, text, 1)
+	require.Equal(t, set.NumericBlobs()[0].StartTime(), numeric[0].StartTime())
+	require.Equal(t, set.TextBlobs()[0].StartTime(), text[0].StartTime())
+
+	compacted := NewBlobSet(numeric, text)
+	require.Equal(t, maps.Collect(set.AllNumerics(id)), maps.Collect(compacted.AllNumerics(id)))
+	require.Equal(t, maps.Collect(set.AllTexts(100)), maps.Collect(compacted.AllTexts(100)))
+	require.Equal(t, 6, numeric[0].Len(id))
+
+	// Sets without blobs of a type compact to no blobs of that type
+	numeric, text, err = NewBlobSet(nil, createTestTextBlobs(t)).Compact()
+	require.NoError(t, err)
+	require.Nil(t, numeric)
+	require.Len(t, text, 1)
+}
+
+func TestBlobSet_Compact_Numeric(t *testing.T) {
+	first := decodeRemapTestBlob(t, encodeTrimTestBlob(t, WithTimestampEncoding(format.TypeRaw)))
+
+	// A later blob with metric names is compacted by ID, since the first has none
+	later := trimTestStart.Add(time.Minute)
+	encoder, err := NewNumericEncoder(later)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("disk", 2))
+	require.NoError(t, encoder.AddDataPoints([]int64{later.UnixMicro(), later.UnixMicro() + 1}, []float64{7, 8}, nil))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.StartMetricName("net", 1))
+	require.NoError(t, encoder.AddDataPoint(later.UnixMicro(), 9, ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	second := decodeRemapTestBlob(t, data)
+
+	numeric, _, err := NewBlobSet([]NumericBlob{second, first}, nil).Compact()
+	require.NoError(t, err)
+	require.Len(t, numeric, 1)
+	blob := numeric[0]
+
+	require.Equal(t, 5, blob.MetricCount())
+	require.False(t, blob.HasMetricNames())
+	require.Equal(t, trimTestStart.UTC(), blob.StartTime())
+	require.Equal(t, []float64{0.5, 1.5, 2.5, 3.5, 4.5}, slices.Collect(blob.AllValues(3)))
+	require.Equal(t, []float64{7, 8}, slices.Collect(blob.AllValues(hash.ID("disk"))))
+	require.Equal(t, []float64{9}, slices.Collect(blob.AllValues(hash.ID("net"))))
+
+	tag, ok := blob.TagAt(1, 59)
+	require.True(t, ok)
+	require.Equal(t, "host=a", tag)
+	meta, ok := blob.MetricMeta(2)
+	require.True(t, ok)
+	require.Equal(t, MetricMeta{Kind: format.MetricKindCounter, Unit: "bytes"}, meta)
+}
+
+func TestBlobSet_Compact_Text(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	long := strings.Repeat("x", 300)
+
+	// Two blobs holding more data points of one metric than a text blob can
+	encode := func(start time.Time, n int, opts ...TextEncoderOption) TextBlob {
+		encoder, err := NewTextEncoder(start, append(opts, WithTextTagsEnabled(true))...)
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricName("status", n))
+		for i := range n {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), fmt.Sprintf("s%d", i%5), "host=a"))
+		}
+		require.NoError(t, encoder.EndMetric())
+		require.NoError(t, encoder.StartMetricName("trace", 1))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), long, ""))
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewTextDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+	set := NewBlobSet(nil, []TextBlob{
+		encode(start, 40000, WithTextSeekIndex(64), WithTextValueRLE(true), WithTextValueCompression(256, format.CompressionZstd)),
+		encode(start.Add(time.Hour), 30000, WithTextValueCompression(256, format.CompressionS2)),
+	})
+
+	_, text, err := set.Compact()
+	require.NoError(t, err)
+	require.Len(t, text, 2)
+	require.Equal(t, 65535, text[0].LenByName("status"))
+	require.Equal(t, 70000-65535, text[1].LenByName("status"))
+	require.Equal(t, 2, text[0].LenByName("trace"))
+	require.False(t, text[1].HasMetricName("trace"))
+	require.True(t, text[0].HasSeekIndex())
+	require.True(t, text[0].HasValueRLE())
+
+	compacted := NewBlobSet(nil, text)
+	require.Equal(t, maps.Collect(set.AllTextsByName("status")), maps.Collect(compacted.AllTextsByName("status")))
+	require.Equal(t, []string{long, long}, slices.Collect(text[0].AllValuesByName("trace")))
+}
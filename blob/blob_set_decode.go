@@ -0,0 +1,279 @@
+package blob
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/arloliu/mebo/internal/options"
+	"github.com/arloliu/mebo/section"
+)
+
+// decodeMemoryFactor is the decoded size reserved per encoded byte of a blob
+// under WithDecodeMemoryLimit. Headers do not record decompressed payload sizes,
+// so the reservation is an estimate covering typical compression ratios.
+const decodeMemoryFactor = 4
+
+// DecodeConfig holds the configuration of DecodeBlobSetWithOptions.
+type DecodeConfig struct {
+	// Workers is the number of blobs decoded concurrently.
+	Workers int
+	// MemoryLimit caps the estimated decoded size of the blobs being decoded
+	// concurrently, in bytes. 0 means unlimited.
+	MemoryLimit int64
+}
+
+// DecodeOption configures DecodeBlobSetWithOptions.
+type DecodeOption = options.Option[*DecodeConfig]
+
+// decodedBlob holds the result of decoding one raw blob. At most one of the
+// blob fields is set; none is for data of an unknown blob type.
+type decodedBlob struct {
+	numeric *NumericBlob
+	text    *TextBlob
+	event   *EventBlob
+	err     error
+}
+
+// memoryGate is a weighted semaphore bounding the bytes reserved by in-flight decodes.
+type memoryGate struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+// WithDecodeWorkers decodes up to n blobs concurrently. A value of 0 or less
+// selects runtime.GOMAXPROCS(0). Default is 1, decoding blobs sequentially.
+//
+// Blobs are decoded independently, so startup of services loading hundreds of
+// blobs scales with the number of cores.
+//
+// Example:
+//
+//	set, err := blob.DecodeBlobSetWithOptions(rawBlobs, blob.WithDecodeWorkers(0))
+func WithDecodeWorkers(n int) DecodeOption {
+	return options.NoError(func(cfg *DecodeConfig) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		cfg.Workers = n
+	})
+}
+
+// WithDecodeMemoryLimit caps the memory held by blobs being decoded
+// concurrently at roughly limit bytes. Default is 0, unlimited.
+//
+// Decompressed sizes are unknown until a blob is decoded, so each decode reserves
+// four times the blob's encoded size before it starts and releases the
+// reservation once done. A blob whose reservation exceeds the limit on its own is
+// decoded alone. Blobs are admitted in input order, so a large blob is never
+// starved by smaller ones. The limit only bounds concurrency: the decoded blobs
+// returned in the set are retained as usual.
+//
+// Example:
+//
+//	set, err := blob.DecodeBlobSetWithOptions(rawBlobs,
+//	    blob.WithDecodeWorkers(8),
+//	    blob.WithDecodeMemoryLimit(256<<20),
+//	)
+func WithDecodeMemoryLimit(limit int64) DecodeOption {
+	return options.New(func(cfg *DecodeConfig) error {
+		if limit < 0 {
+			return fmt.Errorf("invalid decode memory limit: %d, must not be negative", limit)
+		}
+		cfg.MemoryLimit = limit
+
+		return nil
+	})
+}
+
+// DecodeBlobSetWithOptions creates a new BlobSet from a list of encoded byte
+// slices like DecodeBlobSet, optionally decoding blobs in parallel.
+//
+// The resulting set does not depend on the number of workers. If several blobs
+// fail to decode, the error of the first one in input order is returned, as
+// DecodeBlobSet would; no further blobs are started once a decode failed.
+//
+// Parameters:
+//   - blobs: List of byte slices representing encoded blobs
+//   - opts: Optional decode options (WithDecodeWorkers, WithDecodeMemoryLimit)
+//
+// Returns:
+//   - BlobSet: Constructed BlobSet with parsed blobs
+//   - error: Invalid option, parsing or decoding error
+//
+// Example:
+//
+//	set, err := blob.DecodeBlobSetWithOptions(rawBlobs,
+//	    blob.WithDecodeWorkers(0),
+//	    blob.WithDecodeMemoryLimit(512<<20),
+//	)
+//	if err != nil {
+//	    return err
+//	}
+func DecodeBlobSetWithOptions(blobs [][]byte, opts ...DecodeOption) (BlobSet, error) {
+	cfg := &DecodeConfig{Workers: 1}
+	if err := options.Apply(cfg, opts...); err != nil {
+		return BlobSet{}, err
+	}
+
+	results := make([]decodedBlob, len(blobs))
+	if cfg.Workers <= 1 || len(blobs) <= 1 {
+		for i, data := range blobs {
+			results[i] = decodeBlob(data)
+			if results[i].err != nil {
+				return BlobSet{}, results[i].err
+			}
+		}
+	} else {
+		decodeBlobsParallel(blobs, results, cfg)
+	}
+
+	numericBlobs := make([]NumericBlob, 0, len(blobs)/2)
+	textBlobs := make([]TextBlob, 0, len(blobs)/2)
+	var eventBlobs []EventBlob
+	for _, res := range results {
+		switch {
+		case res.err != nil:
+			return BlobSet{}, res.err
+		case res.numeric != nil:
+			numericBlobs = append(numericBlobs, *res.numeric)
+		case res.text != nil:
+			textBlobs = append(textBlobs, *res.text)
+		case res.event != nil:
+			eventBlobs = append(eventBlobs, *res.event)
+		}
+	}
+
+	bs := NewBlobSet(numericBlobs, textBlobs)
+	if len(eventBlobs) > 0 {
+		bs = bs.WithEventBlobs(eventBlobs...)
+	}
+
+	return bs, nil
+}
+
+// decodeBlobsParallel decodes blobs into results with a pool of cfg.Workers
+// goroutines. Blobs are dispatched in input order, each after reserving its
+// decode memory, and dispatching stops at the first failure.
+func decodeBlobsParallel(blobs [][]byte, results []decodedBlob, cfg *DecodeConfig) {
+	var gate *memoryGate
+	if cfg.MemoryLimit > 0 {
+		gate = newMemoryGate(cfg.MemoryLimit)
+	}
+
+	var failed atomic.Bool
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cfg.Workers, len(blobs)) {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i] = decodeBlob(blobs[i])
+				if results[i].err != nil {
+					failed.Store(true)
+				}
+				if gate != nil {
+					gate.release(decodeReservation(blobs[i], gate.limit))
+				}
+			}
+		})
+	}
+
+	for i, data := range blobs {
+		if gate != nil {
+			gate.acquire(decodeReservation(data, gate.limit))
+		}
+		// Blobs before i were all dispatched, so the first error in input
+		// order is always among the decoded results.
+		if failed.Load() {
+			if gate != nil {
+				gate.release(decodeReservation(data, gate.limit))
+			}
+
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// decodeBlob decodes a raw blob according to its type.
+func decodeBlob(data []byte) decodedBlob {
+	switch {
+	case section.IsNumericBlob(data):
+		decoder, err := NewNumericDecoder(data)
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		nb, err := decoder.Decode()
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		return decodedBlob{numeric: &nb}
+	case section.IsTextBlob(data):
+		decoder, err := NewTextDecoder(data)
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		tb, err := decoder.Decode()
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		return decodedBlob{text: &tb}
+	case section.IsEventBlob(data):
+		decoder, err := NewEventDecoder(data)
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		eb, err := decoder.Decode()
+		if err != nil {
+			return decodedBlob{err: err}
+		}
+
+		return decodedBlob{event: &eb}
+	default:
+		return decodedBlob{}
+	}
+}
+
+// decodeReservation returns the memory reserved to decode data, capped at the
+// limit so an oversized blob can still be decoded alone.
+func decodeReservation(data []byte, limit int64) int64 {
+	return min(int64(len(data))*decodeMemoryFactor, limit)
+}
+
+// newMemoryGate creates a gate admitting up to limit reserved bytes.
+func newMemoryGate(limit int64) *memoryGate {
+	g := &memoryGate{limit: limit}
+	g.cond = sync.NewCond(&g.mu)
+
+	return g
+}
+
+// acquire blocks until n bytes fit within the limit, then reserves them.
+func (g *memoryGate) acquire(n int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for g.used+n > g.limit {
+		g.cond.Wait()
+	}
+	g.used += n
+}
+
+// release returns n reserved bytes to the gate.
+func (g *memoryGate) release(n int64) {
+	g.mu.Lock()
+	g.used -= n
+	g.mu.Unlock()
+
+	g.cond.Broadcast()
+}
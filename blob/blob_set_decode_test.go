@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// encodeDecodeTestBlobs encodes n numeric and n text blobs, one hour apart and
+// interleaved, each holding the same metric with three data points.
+func encodeDecodeTestBlobs(t *testing.T, n int) [][]byte {
+	t.Helper()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	blobs := make([][]byte, 0, 2*n)
+	for i := range n {
+		start := base.Add(time.Duration(i) * time.Hour)
+
+		numeric, err := NewNumericEncoder(start)
+		require.NoError(t, err)
+		require.NoError(t, numeric.StartMetricID(100, 3))
+		for j := range 3 {
+			require.NoError(t, numeric.AddDataPoint(start.Add(time.Duration(j)*time.Second).UnixMicro(), float64(i*3+j), ""))
+		}
+		require.NoError(t, numeric.EndMetric())
+		data, err := numeric.Finish()
+		require.NoError(t, err)
+		blobs = append(blobs, data)
+
+		text, err := NewTextEncoder(start)
+		require.NoError(t, err)
+		require.NoError(t, text.StartMetricID(200, 1))
+		require.NoError(t, text.AddDataPoint(start.UnixMicro(), "up", ""))
+		require.NoError(t, text.EndMetric())
+		data, err = text.Finish()
+		require.NoError(t, err)
+		blobs = append(blobs, data)
+	}
+
+	return blobs
+}
+
+func TestDecodeBlobSetWithOptions(t *testing.T) {
+	blobs := encodeDecodeTestBlobs(t, 16)
+	want, err := DecodeBlobSet(blobs...)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts []DecodeOption
+	}{
+		{name: "Sequential"},
+		{name: "Parallel", opts: []DecodeOption{WithDecodeWorkers(4)}},
+		{name: "GOMAXPROCS", opts: []DecodeOption{WithDecodeWorkers(0)}},
+		{name: "MoreWorkersThanBlobs", opts: []DecodeOption{WithDecodeWorkers(100)}},
+		// Every blob exceeds the limit on its own and is decoded alone.
+		{name: "TinyMemoryLimit", opts: []DecodeOption{WithDecodeWorkers(4), WithDecodeMemoryLimit(1)}},
+		{name: "MemoryLimit", opts: []DecodeOption{WithDecodeWorkers(4), WithDecodeMemoryLimit(int64(8 * len(blobs[0])))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeBlobSetWithOptions(blobs, tt.opts...)
+			require.NoError(t, err)
+			require.Len(t, got.numericBlobs, len(want.numericBlobs))
+			require.Len(t, got.textBlobs, len(want.textBlobs))
+
+			wantVals := maps.Collect(want.AllNumericValues(100))
+			require.Len(t, wantVals, 48)
+			require.Equal(t, wantVals, maps.Collect(got.AllNumericValues(100)))
+			require.Equal(t, maps.Collect(want.AllTextValues(200)), maps.Collect(got.AllTextValues(200)))
+		})
+	}
+}
+
+func TestDecodeBlobSetWithOptions_FirstErrorInInputOrder(t *testing.T) {
+	blobs := encodeDecodeTestBlobs(t, 8)
+	// Truncate a numeric and a text blob so that both fail with different errors.
+	blobs[5] = blobs[5][:40]
+	blobs[10] = blobs[10][:40]
+
+	_, wantErr := DecodeBlobSet(blobs...)
+	require.Error(t, wantErr)
+
+	for range 10 {
+		_, err := DecodeBlobSetWithOptions(blobs, WithDecodeWorkers(4))
+		require.EqualError(t, err, wantErr.Error())
+	}
+}
+
+func TestWithDecodeMemoryLimit_Negative(t *testing.T) {
+	_, err := DecodeBlobSetWithOptions(nil, WithDecodeMemoryLimit(-1))
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// WithEventBlobs returns a copy of the BlobSet that also holds the given event
+// blobs, so event streams such as deploy markers can be queried alongside the
+// numeric series they annotate.
+//
+// The event blobs are sorted by start time and replace any event blobs already
+// in the set. Event streams are separate from numeric and text metrics: they are
+// only returned by the event methods (AllEvents, AllEventsByName, AllEventsBetween).
+//
+// Parameters:
+//   - eventBlobs: Event blobs to include in the set
+//
+// Returns:
+//   - BlobSet: A new BlobSet sharing the numeric and text blobs of bs
+func (bs BlobSet) WithEventBlobs(eventBlobs ...EventBlob) BlobSet {
+	sortedEvents := slices.Clone(eventBlobs)
+	slices.SortFunc(sortedEvents, func(a, b EventBlob) int {
+		return cmp.Compare(a.text.startTimeMicros, b.text.startTimeMicros)
+	})
+
+	bs.eventBlobs = sortedEvents
+	bs.ambiguous = ambiguousNames(bs.numericBlobs, bs.textBlobs, sortedEvents)
+
+	return bs
+}
+
+// EventBlobs returns the event blobs in this BlobSet.
+// The blobs are sorted by start time.
+func (bs BlobSet) EventBlobs() []EventBlob {
+	return bs.eventBlobs
+}
+
+// AllEvents iterates through all events of the given event stream ID across the
+// event blobs in chronological order. Returns global index and event for each iteration.
+func (bs BlobSet) AllEvents(metricID uint64) iter.Seq2[int, EventDataPoint] {
+	return func(yield func(int, EventDataPoint) bool) {
+		index := 0
+		for _, blob := range bs.eventBlobs {
+			if blob.HasMetricID(metricID) {
+				for _, ev := range blob.All(metricID) {
+					if !yield(index, ev) {
+						return
+					}
+					index++
+				}
+			}
+		}
+	}
+}
+
+// AllEventsByName iterates through all events of the given event stream name
+// across the event blobs in chronological order. Returns global index and event
+// for each iteration.
+func (bs BlobSet) AllEventsByName(metricName string) iter.Seq2[int, EventDataPoint] {
+	return func(yield func(int, EventDataPoint) bool) {
+		index := 0
+		for _, blob := range bs.eventBlobs {
+			if resolvesName(bs.ambiguous, blob, metricName) {
+				for _, ev := range blob.AllByName(metricName) {
+					if !yield(index, ev) {
+						return
+					}
+					index++
+				}
+			}
+		}
+	}
+}
+
+// AllEventsBetween iterates through the events of the given event stream ID whose
+// timestamps fall within [start, end], such as the annotations of a chart's visible
+// time range.
+//
+// Example:
+//
+//	for ev := range set.AllEventsBetween(deploysID, from, to) {
+//	    chart.Annotate(ev.Ts, ev.Text)
+//	}
+func (bs BlobSet) AllEventsBetween(metricID uint64, start, end int64) iter.Seq[EventDataPoint] {
+	return func(yield func(EventDataPoint) bool) {
+		for _, blob := range bs.eventBlobs {
+			for _, ev := range blob.AllBetween(metricID, start, end) {
+				if !yield(ev) {
+					return
+				}
+			}
+		}
+	}
+}
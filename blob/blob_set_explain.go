@@ -0,0 +1,271 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arloliu/mebo/format"
+)
+
+// AccessPath is the way a blob serves point lookups of one column of a metric,
+// such as TimestampAt and NumericValueAt.
+type AccessPath uint8
+
+const (
+	// AccessNone means the blob does not hold the metric.
+	AccessNone AccessPath = iota
+	// AccessDirect reads a point in (near) constant time from the encoded column,
+	// e.g. raw timestamps and values or ALP values.
+	AccessDirect
+	// AccessScan decodes the column from the start of the metric up to the point,
+	// e.g. delta timestamps, Gorilla and Chimp values, and all text blob columns.
+	AccessScan
+	// AccessMaterialized reads a point from a column the decoder already decoded
+	// into memory, e.g. the values of reference-delta metrics.
+	AccessMaterialized
+)
+
+// String returns the name of the access path.
+func (p AccessPath) String() string {
+	switch p {
+	case AccessNone:
+		return "None"
+	case AccessDirect:
+		return "Direct"
+	case AccessScan:
+		return "Scan"
+	case AccessMaterialized:
+		return "Materialized"
+	default:
+		return "Unknown"
+	}
+}
+
+// cost returns the expected number of points decoded by one lookup of a
+// uniformly random point of a column with count points.
+func (p AccessPath) cost(count int) float64 {
+	switch p {
+	case AccessNone:
+		return 0
+	case AccessScan:
+		return float64(count+1) / 2
+	default:
+		return 1
+	}
+}
+
+// BlobPlan describes how one blob of a BlobSet serves a metric.
+type BlobPlan struct {
+	// BlobIndex is the index of the blob in NumericBlobs or TextBlobs.
+	BlobIndex int
+
+	// IsText reports whether the blob is a text blob.
+	IsText bool
+
+	// StartTime is the start time of the blob.
+	StartTime time.Time
+
+	// Found reports whether the blob holds the metric.
+	Found bool
+
+	// Count is the number of data points of the metric in the blob.
+	Count int
+
+	// TimestampEncoding and ValueEncoding are the column encodings of the blob.
+	TimestampEncoding format.EncodingType
+	ValueEncoding     format.EncodingType
+
+	// TimestampPath and ValuePath are the access paths of point lookups.
+	TimestampPath AccessPath
+	ValuePath     AccessPath
+
+	// LookupCost is the expected number of points decoded, timestamp and value
+	// columns combined, by one lookup of a random data point of the metric.
+	LookupCost float64
+}
+
+// QueryPlan describes how a BlobSet serves point lookups of a metric, as
+// returned by BlobSet.Explain.
+type QueryPlan struct {
+	// MetricID is the explained metric ID.
+	MetricID uint64
+
+	// IsText reports whether lookups are served by text blobs, which BlobSet
+	// only consults when no numeric blob holds the metric.
+	IsText bool
+
+	// Blobs describes every blob of the serving type in start-time order,
+	// including those that do not hold the metric.
+	Blobs []BlobPlan
+
+	// Count is the total number of data points of the metric.
+	Count int
+
+	// LookupCost is the expected number of points decoded by one point lookup
+	// at a random global index, averaged over the blobs by data point count.
+	LookupCost float64
+
+	// MaterializeBreakEven is the number of point lookups after which
+	// materializing the metric, which decodes both columns once, costs less than
+	// looking the points up directly. Zero means materializing never pays off.
+	MaterializeBreakEven int
+}
+
+// Found reports whether any blob holds the metric.
+func (p QueryPlan) Found() bool {
+	return p.Count > 0
+}
+
+// String renders the plan as a human-readable multi-line summary.
+func (p QueryPlan) String() string {
+	var sb strings.Builder
+
+	kind := "numeric"
+	if p.IsText {
+		kind = "text"
+	}
+	fmt.Fprintf(&sb, "metric 0x%016x (%s): %d points, lookup cost %.1f points", p.MetricID, kind, p.Count, p.LookupCost)
+	if p.MaterializeBreakEven > 0 {
+		fmt.Fprintf(&sb, ", materialize after %d lookups", p.MaterializeBreakEven)
+	}
+
+	for _, blob := range p.Blobs {
+		if !blob.Found {
+			fmt.Fprintf(&sb, "\n  blob %d %s: not found", blob.BlobIndex, blob.StartTime.Format(time.RFC3339))
+			continue
+		}
+
+		fmt.Fprintf(&sb, "\n  blob %d %s: %d points, timestamps %s (%s), values %s (%s), lookup cost %.1f",
+			blob.BlobIndex, blob.StartTime.Format(time.RFC3339), blob.Count,
+			blob.TimestampPath, blob.TimestampEncoding, blob.ValuePath, blob.ValueEncoding, blob.LookupCost)
+	}
+
+	return sb.String()
+}
+
+// Explain describes how point lookups of a metric, such as NumericAt and
+// TimestampAt, are served: which blobs hold the metric, the access path of each
+// column, and the expected decoding cost. Use it to debug slow queries and to
+// decide whether to materialize the metric.
+//
+// Like the point lookups, Explain covers the numeric blobs if any holds the
+// metric, and the text blobs otherwise.
+//
+// Parameters:
+//   - metricID: The metric ID to explain
+//
+// Returns:
+//   - QueryPlan: The plan; QueryPlan.Found reports false if no blob holds the metric
+//
+// Example:
+//
+//	plan := blobSet.Explain(metricID)
+//	fmt.Println(plan)
+//	if expectedLookups > plan.MaterializeBreakEven && plan.MaterializeBreakEven > 0 {
+//	    metric, _ := blobSet.MaterializeNumericMetric(metricID)
+//	    // ...
+//	}
+func (bs BlobSet) Explain(metricID uint64) QueryPlan {
+	plan := QueryPlan{MetricID: metricID}
+	for i, blob := range bs.numericBlobs {
+		plan.add(explainNumericBlob(i, blob, metricID))
+	}
+
+	if plan.Count == 0 && bs.IsTextMetric(metricID) {
+		plan = QueryPlan{MetricID: metricID, IsText: true}
+		for i, blob := range bs.textBlobs {
+			plan.add(explainTextBlob(i, blob, metricID))
+		}
+	}
+
+	plan.finish()
+
+	return plan
+}
+
+// explainNumericBlob describes how a numeric blob serves a metric.
+func explainNumericBlob(index int, blob NumericBlob, metricID uint64) BlobPlan {
+	plan := BlobPlan{
+		BlobIndex:         index,
+		StartTime:         blob.StartTime(),
+		TimestampEncoding: blob.TimestampEncodingType(),
+		ValueEncoding:     blob.ValueEncoding(),
+	}
+
+	entry, ok := blob.index.GetByID(metricID)
+	if !ok {
+		return plan
+	}
+
+	plan.Found, plan.Count = true, entry.Count
+	plan.TimestampPath = capabilityPath(blob.TimestampCapabilities().RandomAccess)
+	if _, cached := blob.refValCache[metricID]; cached {
+		plan.ValuePath = AccessMaterialized
+	} else {
+		plan.ValuePath = capabilityPath(blob.ValueCapabilities().RandomAccess)
+	}
+	plan.LookupCost = plan.TimestampPath.cost(plan.Count) + plan.ValuePath.cost(plan.Count)
+
+	return plan
+}
+
+// explainTextBlob describes how a text blob serves a metric. Text rows are
+// variable-length, so every lookup scans the metric's rows.
+func explainTextBlob(index int, blob TextBlob, metricID uint64) BlobPlan {
+	plan := BlobPlan{
+		BlobIndex:         index,
+		IsText:            true,
+		StartTime:         blob.StartTime(),
+		TimestampEncoding: blob.TimestampEncodingType(),
+		ValueEncoding:     blob.ValueEncoding(),
+	}
+
+	count := blob.Len(metricID)
+	if count == 0 {
+		return plan
+	}
+
+	plan.Found, plan.Count = true, count
+	plan.TimestampPath, plan.ValuePath = AccessScan, AccessScan
+	plan.LookupCost = AccessScan.cost(count) * 2
+
+	return plan
+}
+
+// capabilityPath returns the access path of a column with the given random
+// access capability.
+func capabilityPath(randomAccess bool) AccessPath {
+	if randomAccess {
+		return AccessDirect
+	}
+
+	return AccessScan
+}
+
+// add appends a blob plan and accumulates its points.
+func (p *QueryPlan) add(blob BlobPlan) {
+	p.Blobs = append(p.Blobs, blob)
+	p.Count += blob.Count
+}
+
+// finish computes the plan-wide costs from the blob plans.
+func (p *QueryPlan) finish() {
+	if p.Count == 0 {
+		return
+	}
+
+	var total float64
+	for _, blob := range p.Blobs {
+		total += blob.LookupCost * float64(blob.Count)
+	}
+	p.LookupCost = total / float64(p.Count)
+
+	// Materializing decodes both columns once, after which a lookup reads one
+	// point of each column.
+	const materializedCost = 2
+	if saving := p.LookupCost - materializedCost; saving > 0 {
+		p.MaterializeBreakEven = int(math.Ceil(float64(2*p.Count) / saving))
+	}
+}
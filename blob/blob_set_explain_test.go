@@ -0,0 +1,104 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func createExplainNumericBlob(t *testing.T, start time.Time, count int, opts ...NumericEncoderOption) NumericBlob {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start, opts...)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, count))
+	for i := range count {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), float64(i), ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func TestBlobSet_Explain(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	raw := createExplainNumericBlob(t, start, 10,
+		WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw))
+	scan := createExplainNumericBlob(t, start.Add(time.Hour), 99,
+		WithTimestampEncoding(format.TypeDelta), WithValueEncoding(format.TypeGorilla))
+
+	t.Run("Direct", func(t *testing.T) {
+		plan := NewBlobSet([]NumericBlob{raw}, nil).Explain(1)
+		require.True(t, plan.Found())
+		require.Equal(t, 10, plan.Count)
+		require.Equal(t, []BlobPlan{{
+			StartTime:         start,
+			Found:             true,
+			Count:             10,
+			TimestampEncoding: format.TypeRaw,
+			ValueEncoding:     format.TypeRaw,
+			TimestampPath:     AccessDirect,
+			ValuePath:         AccessDirect,
+			LookupCost:        2,
+		}}, plan.Blobs)
+		require.InDelta(t, 2.0, plan.LookupCost, 1e-9)
+		require.Zero(t, plan.MaterializeBreakEven)
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		plan := NewBlobSet([]NumericBlob{scan, raw}, nil).Explain(1)
+		require.Len(t, plan.Blobs, 2)
+		require.Equal(t, AccessDirect, plan.Blobs[0].TimestampPath)
+		require.Equal(t, AccessScan, plan.Blobs[1].TimestampPath)
+		require.Equal(t, AccessScan, plan.Blobs[1].ValuePath)
+		require.InDelta(t, 100.0, plan.Blobs[1].LookupCost, 1e-9)
+
+		// (10×2 + 99×100) / 109 ≈ 91 points decoded per lookup, saving ~89 per
+		// lookup over a materialized lookup that first decodes 2×109 points.
+		require.InDelta(t, 9920.0/109, plan.LookupCost, 1e-9)
+		require.Equal(t, 3, plan.MaterializeBreakEven)
+		require.Contains(t, plan.String(), "timestamps Scan (Delta), values Scan (Gorilla)")
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		encoder, err := NewTextEncoder(start)
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(2, 3))
+		for i := range 3 {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), "ok", ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+		decoder, err := NewTextDecoder(data)
+		require.NoError(t, err)
+		text, err := decoder.Decode()
+		require.NoError(t, err)
+
+		plan := NewBlobSet([]NumericBlob{raw}, []TextBlob{text}).Explain(2)
+		require.True(t, plan.IsText)
+		require.Equal(t, 3, plan.Count)
+		require.Len(t, plan.Blobs, 1)
+		require.Equal(t, AccessScan, plan.Blobs[0].ValuePath)
+		require.InDelta(t, 4.0, plan.LookupCost, 1e-9)
+		require.Equal(t, 3, plan.MaterializeBreakEven)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		plan := NewBlobSet([]NumericBlob{raw}, nil).Explain(42)
+		require.False(t, plan.Found())
+		require.False(t, plan.Blobs[0].Found)
+		require.Equal(t, AccessNone, plan.Blobs[0].ValuePath)
+		require.Contains(t, plan.String(), "not found")
+	})
+}
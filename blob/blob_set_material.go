@@ -0,0 +1,250 @@
+package blob
+
+import (
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// MaterializedBlobSet is a fully decoded view of a mixed BlobSet that supports O(1)
+// random access to both numeric and text metrics.
+//
+// It follows the global indexing semantics of BlobSet: indices span all blobs of one
+// type in start-time order, NumericValueAt and TextValueAt only see their own blob
+// type, and TimestampAt and TagAt try numeric data first and fall back to text data
+// (with the index counted from the start of the text data).
+//
+// Name lookups use the stored metric names and fall back to the hashed metric ID, like
+// BlobSet. Data is keyed by metric ID, so the series of names sharing an ID are merged;
+// by-name lookups of names that BlobSet reports as ambiguous return false.
+//
+// Example:
+//
+//	blobSet := NewBlobSet(numericBlobs, textBlobs)
+//	material := blobSet.Materialize()
+//	val, ok := material.NumericValueAt(cpuID, 1500)    // O(1)
+//	status, ok := material.TextValueAtByName("status", 20)
+//	ts, ok := material.TimestampAt(cpuID, 1500)
+type MaterializedBlobSet struct {
+	numeric   MaterializedNumericBlobSet
+	text      MaterializedTextBlobSet
+	ambiguous map[uint64][]string
+}
+
+// Materialize decodes all metrics from all numeric and text blobs of the set and
+// returns a MaterializedBlobSet that supports O(1) random access.
+//
+// Performance:
+//   - Materialization cost: ~100μs per metric per blob (one-time)
+//   - Random access: ~5ns (O(1), direct array indexing)
+//   - Memory: ~16 bytes per numeric and ~24 bytes per text data point
+//
+// Parameters:
+//   - opts: Materialize options such as WithTimeIndex
+//
+// Example:
+//
+//	blobSet, _ := DecodeBlobSet(blobs...)
+//	material := blobSet.Materialize()
+//	val, ok := material.NumericValueAtByName("cpu.usage", 150)
+//	tag, ok := material.TagAtByName("cpu.usage", 150)
+func (bs BlobSet) Materialize(opts ...MaterializeOption) MaterializedBlobSet {
+	return MaterializedBlobSet{
+		numeric:   bs.MaterializeNumeric(opts...),
+		text:      bs.MaterializeText(opts...),
+		ambiguous: bs.ambiguous,
+	}
+}
+
+// Numeric returns the materialized numeric part of the set.
+func (m MaterializedBlobSet) Numeric() MaterializedNumericBlobSet {
+	return m.numeric
+}
+
+// Text returns the materialized text part of the set.
+func (m MaterializedBlobSet) Text() MaterializedTextBlobSet {
+	return m.text
+}
+
+// NumericValueAt returns the numeric value at the specified global index for the given metric ID.
+// Returns (0, false) if the metric ID is not found in numeric blobs or index is out of bounds.
+func (m MaterializedBlobSet) NumericValueAt(metricID uint64, index int) (float64, bool) {
+	return m.numeric.ValueAt(metricID, index)
+}
+
+// NumericValueAtByName returns the numeric value at the specified global index by metric name.
+// Returns (0, false) if the metric name is not found in numeric blobs or index is out of bounds.
+func (m MaterializedBlobSet) NumericValueAtByName(metricName string, index int) (float64, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return m.numeric.ValueAt(metricID, index)
+}
+
+// TextValueAt returns the text value at the specified global index for the given metric ID.
+// Returns ("", false) if the metric ID is not found in text blobs or index is out of bounds.
+func (m MaterializedBlobSet) TextValueAt(metricID uint64, index int) (string, bool) {
+	return m.text.ValueAt(metricID, index)
+}
+
+// TextValueAtByName returns the text value at the specified global index by metric name.
+// Returns ("", false) if the metric name is not found in text blobs or index is out of bounds.
+func (m MaterializedBlobSet) TextValueAtByName(metricName string, index int) (string, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return "", false
+	}
+
+	return m.text.ValueAt(metricID, index)
+}
+
+// TimestampAt returns the timestamp at the specified global index for the given metric ID.
+// Numeric data is tried first, then text data.
+// Returns (0, false) if neither holds the metric at index.
+func (m MaterializedBlobSet) TimestampAt(metricID uint64, index int) (int64, bool) {
+	if ts, ok := m.numeric.TimestampAt(metricID, index); ok {
+		return ts, true
+	}
+
+	return m.text.TimestampAt(metricID, index)
+}
+
+// TimestampAtByName returns the timestamp at the specified global index by metric name.
+// See TimestampAt for the lookup order.
+func (m MaterializedBlobSet) TimestampAtByName(metricName string, index int) (int64, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return m.TimestampAt(metricID, index)
+}
+
+// TagAt returns the tag at the specified global index for the given metric ID.
+// Numeric data is tried first, then text data.
+// Returns ("", false) if neither holds the metric at index.
+func (m MaterializedBlobSet) TagAt(metricID uint64, index int) (string, bool) {
+	if tag, ok := m.numeric.TagAt(metricID, index); ok {
+		return tag, true
+	}
+
+	return m.text.TagAt(metricID, index)
+}
+
+// TagAtByName returns the tag at the specified global index by metric name.
+// See TagAt for the lookup order.
+func (m MaterializedBlobSet) TagAtByName(metricName string, index int) (string, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return "", false
+	}
+
+	return m.TagAt(metricID, index)
+}
+
+// NumericAt returns the complete numeric data point at the specified global index
+// for the given metric ID.
+func (m MaterializedBlobSet) NumericAt(metricID uint64, index int) (NumericDataPoint, bool) {
+	val, ok := m.numeric.ValueAt(metricID, index)
+	if !ok {
+		return NumericDataPoint{}, false
+	}
+	ts, _ := m.numeric.TimestampAt(metricID, index)
+	tag, _ := m.numeric.TagAt(metricID, index)
+
+	return NumericDataPoint{Ts: ts, Val: val, Tag: tag}, true
+}
+
+// NumericAtByName returns the complete numeric data point at the specified global
+// index by metric name.
+func (m MaterializedBlobSet) NumericAtByName(metricName string, index int) (NumericDataPoint, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return NumericDataPoint{}, false
+	}
+
+	return m.NumericAt(metricID, index)
+}
+
+// TextAt returns the complete text data point at the specified global index for
+// the given metric ID.
+func (m MaterializedBlobSet) TextAt(metricID uint64, index int) (TextDataPoint, bool) {
+	val, ok := m.text.ValueAt(metricID, index)
+	if !ok {
+		return TextDataPoint{}, false
+	}
+	ts, _ := m.text.TimestampAt(metricID, index)
+	tag, _ := m.text.TagAt(metricID, index)
+
+	return TextDataPoint{Ts: ts, Val: val, Tag: tag}, true
+}
+
+// TextAtByName returns the complete text data point at the specified global index
+// by metric name.
+func (m MaterializedBlobSet) TextAtByName(metricName string, index int) (TextDataPoint, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return TextDataPoint{}, false
+	}
+
+	return m.TextAt(metricID, index)
+}
+
+// SearchTime returns the global index of the earliest data point of the given metric
+// ID whose timestamp is at or after ts. Like TimestampAt, numeric data is used when it
+// holds the metric and text data otherwise. See MaterializedNumericBlobSet.SearchTime.
+func (m MaterializedBlobSet) SearchTime(metricID uint64, ts int64) (int, bool) {
+	if m.numeric.HasMetricID(metricID) {
+		return m.numeric.SearchTime(metricID, ts)
+	}
+
+	return m.text.SearchTime(metricID, ts)
+}
+
+// SearchTimeByName returns the global index of the earliest data point of the given
+// metric name whose timestamp is at or after ts. See SearchTime for details.
+func (m MaterializedBlobSet) SearchTimeByName(metricName string, ts int64) (int, bool) {
+	metricID, ok := m.resolveName(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return m.SearchTime(metricID, ts)
+}
+
+// MetricCount returns the number of unique metrics across numeric and text data.
+// A metric ID present in both counts once.
+func (m MaterializedBlobSet) MetricCount() int {
+	n := m.numeric.MetricCount()
+	for _, id := range m.text.MetricIDs() {
+		if !m.numeric.HasMetricID(id) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// HasMetricID returns true if the given metric ID exists in numeric or text data.
+func (m MaterializedBlobSet) HasMetricID(metricID uint64) bool {
+	return m.numeric.HasMetricID(metricID) || m.text.HasMetricID(metricID)
+}
+
+// HasMetricName returns true if the given metric name resolves to a metric in
+// numeric or text data.
+func (m MaterializedBlobSet) HasMetricName(metricName string) bool {
+	metricID, ok := m.resolveName(metricName)
+
+	return ok && m.HasMetricID(metricID)
+}
+
+// resolveName maps a metric name to the metric ID of its materialized data,
+// refusing names whose hashed ID is shared with other names.
+func (m MaterializedBlobSet) resolveName(metricName string) (uint64, bool) {
+	metricID := hash.ID(metricName)
+	if _, ok := m.ambiguous[metricID]; ok {
+		return 0, false
+	}
+
+	return metricID, true
+}
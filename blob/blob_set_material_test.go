@@ -0,0 +1,146 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func createMixedBlobSetForMaterialization(t *testing.T) BlobSet {
+	t.Helper()
+
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	numericBlobs := make([]NumericBlob, 2)
+	textBlobs := make([]TextBlob, 2)
+	for blobIdx := range 2 {
+		blobStart := startTime.Add(time.Duration(blobIdx) * time.Hour)
+
+		numEncoder, err := NewNumericEncoder(blobStart, WithTagsEnabled(true))
+		require.NoError(t, err)
+		require.NoError(t, numEncoder.StartMetricName("cpu.usage", 3))
+		for i := range 3 {
+			ts := blobStart.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, numEncoder.AddDataPoint(ts, float64(blobIdx*10+i), "cpu"))
+		}
+		require.NoError(t, numEncoder.EndMetric())
+		// "shared" exists in both numeric and text blobs
+		require.NoError(t, numEncoder.StartMetricName("shared", 1))
+		require.NoError(t, numEncoder.AddDataPoint(blobStart.UnixMicro(), 1.5, "num"))
+		require.NoError(t, numEncoder.EndMetric())
+		numData, err := numEncoder.Finish()
+		require.NoError(t, err)
+		numDecoder, err := NewNumericDecoder(numData)
+		require.NoError(t, err)
+		numericBlobs[blobIdx], err = numDecoder.Decode()
+		require.NoError(t, err)
+
+		textEncoder, err := NewTextEncoder(blobStart, WithTextTagsEnabled(true))
+		require.NoError(t, err)
+		require.NoError(t, textEncoder.StartMetricName("status", 2))
+		for i := range 2 {
+			ts := blobStart.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, textEncoder.AddDataPoint(ts, "state_"+string(rune('A'+blobIdx*2+i)), "svc"))
+		}
+		require.NoError(t, textEncoder.EndMetric())
+		require.NoError(t, textEncoder.StartMetricName("shared", 3))
+		for i := range 3 {
+			ts := blobStart.Add(time.Duration(i) * time.Minute).UnixMicro()
+			require.NoError(t, textEncoder.AddDataPoint(ts, "text", "txt"))
+		}
+		require.NoError(t, textEncoder.EndMetric())
+		textData, err := textEncoder.Finish()
+		require.NoError(t, err)
+		textDecoder, err := NewTextDecoder(textData)
+		require.NoError(t, err)
+		textBlobs[blobIdx], err = textDecoder.Decode()
+		require.NoError(t, err)
+	}
+
+	return NewBlobSet(numericBlobs, textBlobs)
+}
+
+// TestBlobSet_Materialize verifies the materialized view matches BlobSet for every index
+func TestBlobSet_Materialize(t *testing.T) {
+	blobSet := createMixedBlobSetForMaterialization(t)
+	mat := blobSet.Materialize()
+
+	require.Equal(t, 3, mat.MetricCount())
+	require.True(t, mat.HasMetricName("cpu.usage"))
+	require.True(t, mat.HasMetricID(hash.ID("status")))
+	require.False(t, mat.HasMetricName("nonexistent"))
+
+	for _, name := range []string{"cpu.usage", "status", "shared", "nonexistent"} {
+		id := hash.ID(name)
+		for i := -1; i <= 8; i++ {
+			wantNum, wantOk := blobSet.NumericValueAtByName(name, i)
+			gotNum, gotOk := mat.NumericValueAtByName(name, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] numeric", name, i)
+			require.Equal(t, wantNum, gotNum, "%s[%d] numeric", name, i)
+
+			wantText, wantOk := blobSet.TextValueAt(id, i)
+			gotText, gotOk := mat.TextValueAt(id, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] text", name, i)
+			require.Equal(t, wantText, gotText, "%s[%d] text", name, i)
+
+			wantTs, wantOk := blobSet.TimestampAtByName(name, i)
+			gotTs, gotOk := mat.TimestampAtByName(name, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] timestamp", name, i)
+			require.Equal(t, wantTs, gotTs, "%s[%d] timestamp", name, i)
+
+			wantTag, wantOk := blobSet.TagAt(id, i)
+			gotTag, gotOk := mat.TagAt(id, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] tag", name, i)
+			require.Equal(t, wantTag, gotTag, "%s[%d] tag", name, i)
+
+			wantNumDp, wantOk := blobSet.NumericAt(id, i)
+			gotNumDp, gotOk := mat.NumericAt(id, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] numeric point", name, i)
+			require.Equal(t, wantNumDp, gotNumDp, "%s[%d] numeric point", name, i)
+
+			wantTextDp, wantOk := blobSet.TextAtByName(name, i)
+			gotTextDp, gotOk := mat.TextAtByName(name, i)
+			require.Equal(t, wantOk, gotOk, "%s[%d] text point", name, i)
+			require.Equal(t, wantTextDp, gotTextDp, "%s[%d] text point", name, i)
+		}
+	}
+
+	// Numeric data wins for shared metrics; text indices continue past the numeric ones
+	tag, ok := mat.TagAtByName("shared", 1)
+	require.True(t, ok)
+	require.Equal(t, "num", tag)
+	tag, ok = mat.TagAtByName("shared", 2)
+	require.True(t, ok)
+	require.Equal(t, "txt", tag)
+}
+
+func TestBlobSet_Materialize_Empty(t *testing.T) {
+	mat := NewBlobSet(nil, nil).Materialize()
+
+	require.Zero(t, mat.MetricCount())
+	require.Zero(t, mat.Numeric().MetricCount())
+	require.Zero(t, mat.Text().MetricCount())
+
+	_, ok := mat.TimestampAt(1, 0)
+	require.False(t, ok)
+	_, ok = mat.TextValueAtByName("status", 0)
+	require.False(t, ok)
+}
+
+func TestBlobSet_Materialize_AmbiguousName(t *testing.T) {
+	blobSet := createMixedBlobSetForMaterialization(t)
+	blobSet.ambiguous = map[uint64][]string{hash.ID("cpu.usage"): {"cpu.usage", "other"}}
+	mat := blobSet.Materialize()
+
+	_, ok := mat.NumericValueAtByName("cpu.usage", 0)
+	require.False(t, ok)
+	require.False(t, mat.HasMetricName("cpu.usage"))
+
+	// ID access still returns the merged series
+	val, ok := mat.NumericValueAt(hash.ID("cpu.usage"), 0)
+	require.True(t, ok)
+	require.Equal(t, 0.0, val)
+}
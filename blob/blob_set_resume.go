@@ -0,0 +1,275 @@
+package blob
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"iter"
+	"math"
+	"sort"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// resumeTokenVersion is the version byte of encoded resume tokens.
+const resumeTokenVersion = 1
+
+// ResumeToken is an opaque position in the data points of a metric in a blob
+// set, yielded by NumericBlobSet.AllFrom and TextBlobSet.AllFrom with each data
+// point. Passing it back to AllFrom resumes the iteration after that data point,
+// so paginated streaming APIs over long series continue where the previous page
+// ended instead of decoding the series from the start.
+//
+// The token records the blob holding the next data point by its start time and
+// blob identity, so it stays valid for sets that gain or lose other blobs, e.g.
+// a set rebuilt with newer blobs between two pages. The zero token starts at the
+// first data point.
+//
+// Tokens are comparable, and MarshalText encodes them as URL-safe strings.
+type ResumeToken struct {
+	metricID  uint64 // metric of the iteration, 0 for the zero token
+	blobStart int64  // start time in microseconds of the blob holding the next data point
+	blobID    uint64 // identity of that blob, 0 if unknown
+	point     int    // index of the next data point within the blob
+	index     int    // global index of the next data point
+}
+
+// IsZero reports whether t is the zero token, which starts at the first data point.
+func (t ResumeToken) IsZero() bool {
+	return t == ResumeToken{}
+}
+
+// Index returns the global index of the next data point, i.e., the number of
+// data points of the metric before the resume position.
+func (t ResumeToken) Index() int {
+	return t.index
+}
+
+// String returns the text encoding of the token, see MarshalText.
+func (t ResumeToken) String() string {
+	text, _ := t.MarshalText()
+
+	return string(text)
+}
+
+// MarshalText encodes the token as a URL-safe base64 string. The zero token
+// encodes as an empty string.
+//
+// Returns:
+//   - []byte: Encoded token
+//   - error: Always nil
+func (t ResumeToken) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return []byte{}, nil
+	}
+
+	raw := []byte{resumeTokenVersion}
+	raw = binary.AppendUvarint(raw, t.metricID)
+	raw = binary.AppendVarint(raw, t.blobStart)
+	raw = binary.AppendUvarint(raw, t.blobID)
+	raw = binary.AppendUvarint(raw, uint64(t.point)) //nolint:gosec // non-negative
+	raw = binary.AppendUvarint(raw, uint64(t.index)) //nolint:gosec // non-negative
+
+	text := make([]byte, base64.RawURLEncoding.EncodedLen(len(raw)))
+	base64.RawURLEncoding.Encode(text, raw)
+
+	return text, nil
+}
+
+// UnmarshalText decodes a token encoded by MarshalText.
+//
+// Parameters:
+//   - text: Encoded token, empty for the zero token
+//
+// Returns:
+//   - error: ErrInvalidResumeToken if text is not an encoded token
+func (t *ResumeToken) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = ResumeToken{}
+		return nil
+	}
+
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(text)))
+	n, err := base64.RawURLEncoding.Decode(raw, text)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errs.ErrInvalidResumeToken, err)
+	}
+	raw = raw[:n]
+
+	if len(raw) == 0 || raw[0] != resumeTokenVersion {
+		return fmt.Errorf("%w: unsupported version", errs.ErrInvalidResumeToken)
+	}
+	raw = raw[1:]
+
+	var token ResumeToken
+	var fields [3]uint64
+	token.metricID, raw, err = readTokenUvarint(raw)
+	if err != nil {
+		return err
+	}
+	start, m := binary.Varint(raw)
+	if m <= 0 {
+		return fmt.Errorf("%w: truncated", errs.ErrInvalidResumeToken)
+	}
+	token.blobStart, raw = start, raw[m:]
+	for i := range fields {
+		if fields[i], raw, err = readTokenUvarint(raw); err != nil {
+			return err
+		}
+	}
+	if len(raw) != 0 || token.metricID == 0 || fields[1] > math.MaxInt || fields[2] > math.MaxInt {
+		return fmt.Errorf("%w: malformed", errs.ErrInvalidResumeToken)
+	}
+	token.blobID, token.point, token.index = fields[0], int(fields[1]), int(fields[2]) //nolint:gosec // bounded by math.MaxInt
+
+	*t = token
+
+	return nil
+}
+
+// readTokenUvarint reads a uvarint of an encoded resume token.
+func readTokenUvarint(raw []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(raw)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: truncated", errs.ErrInvalidResumeToken)
+	}
+
+	return v, raw[n:], nil
+}
+
+// resumePosition returns the position in a blob set of count blobs sorted by
+// start time of the blob holding the next data point of token, or 0 for the
+// zero token. start and id return the start time and blob identity of the blob
+// at a position; id is only called for blobs with the token's start time.
+func resumePosition(metricID uint64, token ResumeToken, count int, start func(int) int64, id func(int) uint64) (int, error) {
+	if token.IsZero() {
+		return 0, nil
+	}
+
+	if token.metricID != metricID {
+		return 0, fmt.Errorf("%w: token of metric 0x%016x used for metric 0x%016x", errs.ErrInvalidResumeToken, token.metricID, metricID)
+	}
+
+	first := sort.Search(count, func(i int) bool {
+		return start(i) >= token.blobStart
+	})
+	for i := first; i < count && start(i) == token.blobStart; i++ {
+		if token.blobID == 0 || id(i) == token.blobID {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: blob of the resume position is not in the set", errs.ErrInvalidResumeToken)
+}
+
+// AllFrom returns the data points of the given metric ID across all blobs in
+// the set, in chronological order like All, starting after the position of
+// token. Each data point is yielded with the token that resumes after it.
+//
+// Blobs before the resume position are not decoded; within the blob holding the
+// next data point, decoding starts at the beginning of the metric.
+//
+// Parameters:
+//   - metricID: The metric ID to iterate
+//   - token: A token yielded by a previous AllFrom for the same metric, or the
+//     zero token to start at the first data point
+//
+// Returns:
+//   - iter.Seq2[ResumeToken, NumericDataPoint]: Data points with their resume tokens
+//   - error: ErrInvalidResumeToken if the token belongs to another metric or its
+//     blob is not in the set
+//
+// Example:
+//
+//	var token blob.ResumeToken
+//	_ = token.UnmarshalText([]byte(req.PageToken))
+//	points, err := set.AllFrom(metricID, token)
+//	if err != nil {
+//	    return err
+//	}
+//	for next, dp := range points {
+//	    page = append(page, dp)
+//	    if len(page) == pageSize {
+//	        resp.NextPageToken = next.String()
+//	        break
+//	    }
+//	}
+func (s NumericBlobSet) AllFrom(metricID uint64, token ResumeToken) (iter.Seq2[ResumeToken, NumericDataPoint], error) {
+	pos, err := resumePosition(metricID, token, len(s.blobs),
+		func(i int) int64 { return s.blobs[i].startTimeMicros },
+		func(i int) uint64 { return s.blob(i).blobID.get() },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.IsZero() && token.point > s.blob(pos).Len(metricID) {
+		return nil, fmt.Errorf("%w: position %d exceeds the metric length", errs.ErrInvalidResumeToken, token.point)
+	}
+
+	return func(yield func(ResumeToken, NumericDataPoint) bool) {
+		next := ResumeToken{metricID: metricID, point: token.point, index: token.index}
+		for i := pos; i < len(s.blobs); i++ {
+			blob := s.blob(i)
+			next.blobStart, next.blobID = s.blobs[i].startTimeMicros, blob.blobID.get()
+			for j, dp := range blob.All(metricID) {
+				if i == pos && j < token.point {
+					continue
+				}
+
+				next.point, next.index = j+1, next.index+1
+				if !yield(next, dp) {
+					return
+				}
+			}
+		}
+	}, nil
+}
+
+// AllFrom returns the data points of the given metric ID across all blobs in
+// the set, in chronological order like All, starting after the position of
+// token. Each data point is yielded with the token that resumes after it.
+//
+// Blobs before the resume position are not decoded. See NumericBlobSet.AllFrom
+// for details.
+//
+// Parameters:
+//   - metricID: The metric ID to iterate
+//   - token: A token yielded by a previous AllFrom for the same metric, or the
+//     zero token to start at the first data point
+//
+// Returns:
+//   - iter.Seq2[ResumeToken, TextDataPoint]: Data points with their resume tokens
+//   - error: ErrInvalidResumeToken if the token belongs to another metric or its
+//     blob is not in the set
+func (s TextBlobSet) AllFrom(metricID uint64, token ResumeToken) (iter.Seq2[ResumeToken, TextDataPoint], error) {
+	pos, err := resumePosition(metricID, token, len(s.blobs),
+		func(i int) int64 { return s.blobs[i].startTimeMicros },
+		func(i int) uint64 { return s.blobs[i].blobID.get() },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.IsZero() && token.point > s.blobs[pos].Len(metricID) {
+		return nil, fmt.Errorf("%w: position %d exceeds the metric length", errs.ErrInvalidResumeToken, token.point)
+	}
+
+	return func(yield func(ResumeToken, TextDataPoint) bool) {
+		next := ResumeToken{metricID: metricID, point: token.point, index: token.index}
+		for i := pos; i < len(s.blobs); i++ {
+			blob := &s.blobs[i]
+			next.blobStart, next.blobID = blob.startTimeMicros, blob.blobID.get()
+			for j, dp := range blob.All(metricID) {
+				if i == pos && j < token.point {
+					continue
+				}
+
+				next.point, next.index = j+1, next.index+1
+				if !yield(next, dp) {
+					return
+				}
+			}
+		}
+	}, nil
+}
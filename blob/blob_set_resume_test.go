@@ -0,0 +1,193 @@
+package blob
+
+import (
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// resumePages iterates all data points with allFrom in pages of size points,
+// passing the token between pages through its text encoding.
+func resumePages[T any](t *testing.T, size int, allFrom func(ResumeToken) (iter.Seq2[ResumeToken, T], error)) ([]T, []int) {
+	t.Helper()
+
+	var points []T
+	var indexes []int
+	var text string
+	for {
+		var token ResumeToken
+		require.NoError(t, token.UnmarshalText([]byte(text)))
+		seq, err := allFrom(token)
+		require.NoError(t, err)
+
+		page := 0
+		for next, dp := range seq {
+			points = append(points, dp)
+			indexes = append(indexes, next.Index())
+			text = next.String()
+			if page++; page == size {
+				break
+			}
+		}
+		if page < size {
+			return points, indexes
+		}
+	}
+}
+
+func TestNumericBlobSet_AllFrom(t *testing.T) {
+	blobTS := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var blobs []NumericBlob
+	for i, n := range []int{5, 0, 1, 7} {
+		start := blobTS.Add(time.Duration(i) * time.Hour)
+		name := "cpu"
+		if n == 0 {
+			name, n = "mem", 3
+		}
+		ts := make([]int64, n)
+		vals := make([]float64, n)
+		for j := range n {
+			ts[j] = start.Add(time.Duration(j) * time.Second).UnixMicro()
+			vals[j] = float64(i*100 + j)
+		}
+		blobs = append(blobs, createBlobWithTimestamp(t, start, name, ts, vals))
+	}
+	set, err := NewNumericBlobSet(blobs)
+	require.NoError(t, err)
+	id := hash.ID("cpu")
+
+	var want []NumericDataPoint
+	for _, dp := range set.All(id) {
+		want = append(want, dp)
+	}
+	require.Len(t, want, 13)
+
+	for _, size := range []int{1, 3, 5, 13, 20} {
+		got, indexes := resumePages(t, size, func(token ResumeToken) (iter.Seq2[ResumeToken, NumericDataPoint], error) {
+			return set.AllFrom(id, token)
+		})
+		require.Equal(t, want, got, "page size %d", size)
+		for i, index := range indexes {
+			require.Equal(t, i+1, index)
+		}
+	}
+
+	t.Run("set gains newer blobs", func(t *testing.T) {
+		partial, err := NewNumericBlobSet(blobs[:2])
+		require.NoError(t, err)
+
+		var token ResumeToken
+		seq, err := partial.AllFrom(id, ResumeToken{})
+		require.NoError(t, err)
+		for next := range seq {
+			token = next
+		}
+		require.Equal(t, 5, token.Index())
+
+		var rest []NumericDataPoint
+		seq, err = set.AllFrom(id, token)
+		require.NoError(t, err)
+		for _, dp := range seq {
+			rest = append(rest, dp)
+		}
+		require.Equal(t, want[5:], rest)
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		var token ResumeToken
+		seq, err := set.AllFrom(id, ResumeToken{})
+		require.NoError(t, err)
+		for next := range seq {
+			token = next
+			break
+		}
+
+		_, err = set.AllFrom(hash.ID("mem"), token)
+		require.ErrorIs(t, err, errs.ErrInvalidResumeToken)
+
+		other, err := NewNumericBlobSet(blobs[1:])
+		require.NoError(t, err)
+		_, err = other.AllFrom(id, token)
+		require.ErrorIs(t, err, errs.ErrInvalidResumeToken)
+
+		require.ErrorIs(t, token.UnmarshalText([]byte("not a token!")), errs.ErrInvalidResumeToken)
+		require.ErrorIs(t, token.UnmarshalText([]byte("AQ")), errs.ErrInvalidResumeToken)
+		text, err := token.MarshalText()
+		require.NoError(t, err)
+		require.ErrorIs(t, token.UnmarshalText(text[:len(text)-2]), errs.ErrInvalidResumeToken)
+	})
+}
+
+func TestNumericBlobSet_AllFrom_Cold(t *testing.T) {
+	blobs := createTestBlobs(t, 3)
+	loads := make([]int, len(blobs))
+	cold := make([]ColdNumericBlob, len(blobs))
+	for i := range blobs {
+		encoder, err := NewNumericEncoder(blobs[i].StartTime())
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricName("metric1", 2))
+		require.NoError(t, encoder.AddDataPoints([]int64{blobs[i].startTimeMicros, blobs[i].startTimeMicros + 1}, []float64{float64(i), float64(i)}, nil))
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+		cold[i] = ColdNumericBlob{
+			StartTime: blobs[i].StartTime(),
+			Load: func() ([]byte, error) {
+				loads[i]++
+				return data, nil
+			},
+		}
+	}
+	set, err := NewTieredNumericBlobSet(nil, cold)
+	require.NoError(t, err)
+
+	id := hash.ID("metric1")
+	seq, err := set.AllFrom(id, ResumeToken{})
+	require.NoError(t, err)
+	var token ResumeToken
+	count := 0
+	for next := range seq {
+		token = next
+		if count++; count == 4 {
+			break
+		}
+	}
+
+	// Resuming does not load the blobs before the resume position
+	clear(loads)
+	seq, err = set.AllFrom(id, token)
+	require.NoError(t, err)
+	var rest []float64
+	for _, dp := range seq {
+		rest = append(rest, dp.Val)
+	}
+	require.Equal(t, []float64{2, 2}, rest)
+	require.Zero(t, loads[0])
+	require.NotZero(t, loads[2])
+}
+
+func TestTextBlobSet_AllFrom(t *testing.T) {
+	set, err := NewTextBlobSet(createTestTextBlobs(t))
+	require.NoError(t, err)
+
+	var want []TextDataPoint
+	for _, dp := range set.All(100) {
+		want = append(want, dp)
+	}
+
+	for _, size := range []int{1, 2, 4, 9} {
+		got, indexes := resumePages(t, size, func(token ResumeToken) (iter.Seq2[ResumeToken, TextDataPoint], error) {
+			return set.AllFrom(100, token)
+		})
+		require.Equal(t, want, got, "page size %d", size)
+		require.Equal(t, len(want), indexes[len(indexes)-1])
+	}
+
+	_, err = set.AllFrom(100, ResumeToken{metricID: 100, blobStart: 1})
+	require.ErrorIs(t, err, errs.ErrInvalidResumeToken)
+}
@@ -0,0 +1,157 @@
+package blob
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/arloliu/mebo/section"
+)
+
+// TagUsage describes one distinct non-empty tag across the blobs of a BlobSet,
+// as reported by TagAudit.
+type TagUsage struct {
+	// Tag is the tag string.
+	Tag string
+
+	// NumericCount is the number of numeric data points carrying the tag.
+	NumericCount int
+
+	// TextCount is the number of text data points carrying the tag.
+	TextCount int
+
+	// MetricCount is the number of distinct metrics using the tag.
+	MetricCount int
+
+	// Bytes is the uncompressed size of the tag across all data points
+	// carrying it.
+	Bytes int
+}
+
+// Count returns the number of data points carrying the tag.
+func (u TagUsage) Count() int {
+	return u.NumericCount + u.TextCount
+}
+
+// TagAuditReport is the tag usage report of a BlobSet, as returned by TagAudit.
+type TagAuditReport struct {
+	// TaggedPoints is the number of data points with a non-empty tag.
+	TaggedPoints int
+
+	// UntaggedPoints is the number of data points with an empty tag in blobs
+	// with tags enabled.
+	UntaggedPoints int
+
+	// Bytes is the uncompressed size of all non-empty tags.
+	Bytes int
+
+	// Tags holds every distinct non-empty tag, sorted by Count descending and
+	// then by Tag ascending.
+	Tags []TagUsage
+}
+
+// Distinct returns the number of distinct non-empty tags.
+func (r TagAuditReport) Distinct() int {
+	return len(r.Tags)
+}
+
+// TagAudit reports the tags used across the numeric and text blobs of the set:
+// each distinct tag with its data point frequency per blob kind, the number of
+// metrics using it and its bytes. It helps identify frequent tags worth
+// promoting to structured metadata (see MetricMeta) or a tag dictionary, and
+// rare or bulky ones worth removing.
+//
+// TagAudit decodes every tag of the set, so its cost is proportional to the
+// number of tagged data points. Blobs without tags are skipped.
+//
+// Returns:
+//   - TagAuditReport: The tag usage of the set
+//
+// Example:
+//
+//	report := blobSet.TagAudit()
+//	for _, u := range report.Tags[:min(10, len(report.Tags))] {
+//	    fmt.Printf("%q: %d points, %d metrics, %d bytes\n", u.Tag, u.Count(), u.MetricCount, u.Bytes)
+//	}
+func (bs BlobSet) TagAudit() TagAuditReport {
+	var report TagAuditReport
+
+	type tagMetric struct {
+		tag      string
+		metricID uint64
+	}
+
+	byTag := make(map[string]*TagUsage)
+	used := make(map[tagMetric]struct{})
+	add := func(metricID uint64, tag string, isText bool) {
+		if tag == "" {
+			report.UntaggedPoints++
+			return
+		}
+
+		u := byTag[tag]
+		if u == nil {
+			u = &TagUsage{Tag: tag}
+			byTag[tag] = u
+		}
+
+		if isText {
+			u.TextCount++
+		} else {
+			u.NumericCount++
+		}
+		u.Bytes += len(tag)
+
+		key := tagMetric{tag: tag, metricID: metricID}
+		if _, ok := used[key]; !ok {
+			used[key] = struct{}{}
+			u.MetricCount++
+		}
+	}
+
+	for i := range bs.numericBlobs {
+		b := &bs.numericBlobs[i]
+		if !b.HasTag() {
+			continue
+		}
+
+		b.index.ForEach(func(e section.NumericIndexEntry) bool {
+			for tag := range b.allTagsFromEntry(e) {
+				add(e.MetricID, tag, false)
+			}
+
+			return true
+		})
+	}
+
+	for i := range bs.textBlobs {
+		b := &bs.textBlobs[i]
+		if !b.HasTag() {
+			continue
+		}
+
+		b.index.ForEach(func(e section.TextIndexEntry) bool {
+			for tag := range b.allTagsFromEntry(e) {
+				add(e.MetricID, tag, true)
+			}
+
+			return true
+		})
+	}
+
+	report.Tags = make([]TagUsage, 0, len(byTag))
+	for _, u := range byTag {
+		report.Tags = append(report.Tags, *u)
+		report.TaggedPoints += u.Count()
+		report.Bytes += u.Bytes
+	}
+
+	slices.SortFunc(report.Tags, func(a, b TagUsage) int {
+		if c := cmp.Compare(b.Count(), a.Count()); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+
+	return report
+}
@@ -0,0 +1,61 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobSet_TagAudit(t *testing.T) {
+	set := createMixedBlobSetForMaterialization(t)
+
+	report := set.TagAudit()
+	require.Equal(t, 4, report.Distinct())
+	require.Equal(t, 18, report.TaggedPoints)
+	require.Zero(t, report.UntaggedPoints)
+	require.Equal(t, 18*3, report.Bytes)
+	require.Equal(t, []TagUsage{
+		{Tag: "cpu", NumericCount: 6, MetricCount: 1, Bytes: 18},
+		{Tag: "txt", TextCount: 6, MetricCount: 1, Bytes: 18},
+		{Tag: "svc", TextCount: 4, MetricCount: 1, Bytes: 12},
+		{Tag: "num", NumericCount: 2, MetricCount: 1, Bytes: 6},
+	}, report.Tags)
+
+	require.Empty(t, BlobSet{}.TagAudit().Tags)
+}
+
+func TestBlobSet_TagAudit_SharedTags(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	numEncoder, err := NewNumericEncoder(start, WithTagsEnabled(true))
+	require.NoError(t, err)
+	for _, name := range []string{"cpu", "mem"} {
+		require.NoError(t, numEncoder.StartMetricName(name, 3))
+		for i, tag := range []string{"host=a", "", "host=a"} {
+			require.NoError(t, numEncoder.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), 1, tag))
+		}
+		require.NoError(t, numEncoder.EndMetric())
+	}
+	numData, err := numEncoder.Finish()
+	require.NoError(t, err)
+
+	textEncoder, err := NewTextEncoder(start, WithTextTagsEnabled(true))
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricName("cpu", 1))
+	require.NoError(t, textEncoder.AddDataPoint(start.UnixMicro(), "ok", "host=a"))
+	require.NoError(t, textEncoder.EndMetric())
+	textData, err := textEncoder.Finish()
+	require.NoError(t, err)
+
+	set, err := DecodeBlobSet(numData, textData)
+	require.NoError(t, err)
+
+	report := set.TagAudit()
+	require.Equal(t, 5, report.TaggedPoints)
+	require.Equal(t, 2, report.UntaggedPoints)
+	require.Equal(t, []TagUsage{
+		{Tag: "host=a", NumericCount: 4, TextCount: 1, MetricCount: 2, Bytes: 30},
+	}, report.Tags)
+	require.Equal(t, 5, report.Tags[0].Count())
+}
@@ -2,9 +2,12 @@ package blob
 
 import (
 	"fmt"
+	"iter"
+	"math"
 	"testing"
 	"time"
 
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/hash"
 	"github.com/stretchr/testify/require"
@@ -2245,3 +2248,299 @@ func TestBlobSet_MetricDuration(t *testing.T) {
 		require.Equal(t, int64(0), duration, "Single data point should have 0 duration")
 	})
 }
+
+func TestBlobSet_Stats(t *testing.T) {
+	startTime := time.UnixMicro(1_700_000_000_000_000)
+
+	encodeNumeric := func(t *testing.T, base time.Time, counts map[uint64]int) []byte {
+		t.Helper()
+		enc, err := NewNumericEncoder(base, WithValueEncoding(format.TypeRaw), WithTimestampEncoding(format.TypeRaw))
+		require.NoError(t, err)
+		for _, id := range []uint64{1, 2} {
+			if counts[id] == 0 {
+				continue
+			}
+			require.NoError(t, enc.StartMetricID(id, counts[id]))
+			for i := range counts[id] {
+				require.NoError(t, enc.AddDataPoint(base.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), ""))
+			}
+			require.NoError(t, enc.EndMetric())
+		}
+		data, err := enc.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	textEnc, err := NewTextEncoder(startTime)
+	require.NoError(t, err)
+	require.NoError(t, textEnc.StartMetricID(3, 2))
+	require.NoError(t, textEnc.AddDataPoint(startTime.UnixMicro(), "up", ""))
+	require.NoError(t, textEnc.AddDataPoint(startTime.Add(time.Minute).UnixMicro(), "down", ""))
+	require.NoError(t, textEnc.EndMetric())
+	textData, err := textEnc.Finish()
+	require.NoError(t, err)
+
+	blobSet, err := DecodeBlobSet(
+		encodeNumeric(t, startTime.Add(time.Hour), map[uint64]int{1: 2}),
+		encodeNumeric(t, startTime, map[uint64]int{1: 3, 2: 10}),
+		textData,
+	)
+	require.NoError(t, err)
+
+	stats := blobSet.Stats()
+	require.Equal(t, 2, stats.NumericBlobCount)
+	require.Equal(t, 1, stats.TextBlobCount)
+	require.Equal(t, 3, stats.MetricCount)
+	require.Equal(t, 17, stats.PointCount)
+	require.Len(t, stats.Metrics, 3)
+
+	m1 := stats.Metrics[0]
+	require.Equal(t, uint64(1), m1.MetricID)
+	require.False(t, m1.IsText)
+	require.Equal(t, 2, m1.BlobCount)
+	require.Equal(t, 5, m1.PointCount)
+	require.Equal(t, 5*16, m1.Bytes, "raw timestamps and values take 8 bytes each")
+	require.Equal(t, startTime.UnixMicro(), m1.FirstTimestamp)
+	require.Equal(t, startTime.Add(time.Hour+time.Second).UnixMicro(), m1.LastTimestamp)
+
+	m3 := stats.Metrics[2]
+	require.True(t, m3.IsText)
+	require.Equal(t, 2, m3.PointCount)
+	require.Equal(t, startTime.Add(time.Minute).UnixMicro(), m3.LastTimestamp)
+
+	sum := 0
+	for _, m := range stats.Metrics {
+		sum += m.Bytes
+	}
+	require.Equal(t, sum, stats.Bytes)
+
+	require.Len(t, stats.Largest, 3)
+	require.Equal(t, uint64(2), stats.Largest[0].MetricID)
+	require.GreaterOrEqual(t, stats.Largest[1].Bytes, stats.Largest[2].Bytes)
+
+	t.Run("MetricNames", func(t *testing.T) {
+		named, err := DecodeBlobSet(encodeNamedRemapTestBlob(t, true, "cpu.usage"))
+		require.NoError(t, err)
+
+		stats := named.Stats()
+		require.Len(t, stats.Metrics, 1)
+		require.Equal(t, "cpu.usage", stats.Metrics[0].Name)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		stats := NewBlobSet(nil, nil).Stats()
+		require.Zero(t, stats.MetricCount)
+		require.Empty(t, stats.Largest)
+	})
+}
+
+func TestBlobSet_AllNumericsPage(t *testing.T) {
+	startTime := time.UnixMicro(1_700_000_000_000_000)
+
+	// Three blobs with 4, 0 (metric absent) and 5 points; values are the global index
+	var blobs [][]byte
+	next := 0
+	for b, count := range []int{4, 0, 5} {
+		base := startTime.Add(time.Duration(b) * time.Hour)
+		enc, err := NewNumericEncoder(base, WithValueEncoding(format.TypeGorilla))
+		require.NoError(t, err)
+
+		if count == 0 {
+			require.NoError(t, enc.StartMetricName("other.metric", 1))
+			require.NoError(t, enc.AddDataPoint(base.UnixMicro(), -1, ""))
+		} else {
+			require.NoError(t, enc.StartMetricName("page.metric", count))
+			for i := range count {
+				require.NoError(t, enc.AddDataPoint(base.Add(time.Duration(i)*time.Second).UnixMicro(), float64(next), ""))
+				next++
+			}
+		}
+		require.NoError(t, enc.EndMetric())
+
+		data, err := enc.Finish()
+		require.NoError(t, err)
+		blobs = append(blobs, data)
+	}
+
+	blobSet, err := DecodeBlobSet(blobs...)
+	require.NoError(t, err)
+
+	collect := func(seq func(func(int, NumericDataPoint) bool)) ([]int, []float64) {
+		var indices []int
+		var values []float64
+		for idx, dp := range seq {
+			indices = append(indices, idx)
+			values = append(values, dp.Val)
+		}
+
+		return indices, values
+	}
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		expected      []int
+	}{
+		{name: "FirstPage", offset: 0, limit: 3, expected: []int{0, 1, 2}},
+		{name: "AcrossBlobs", offset: 3, limit: 3, expected: []int{3, 4, 5}},
+		{name: "SkipWholeBlob", offset: 4, limit: 2, expected: []int{4, 5}},
+		{name: "LastPartialPage", offset: 7, limit: 5, expected: []int{7, 8}},
+		{name: "PastEnd", offset: 9, limit: 5, expected: nil},
+		{name: "NegativeOffset", offset: -2, limit: 1, expected: []int{0}},
+		{name: "ZeroLimit", offset: 0, limit: 0, expected: nil},
+		{name: "Unbounded", offset: 6, limit: math.MaxInt, expected: []int{6, 7, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indices, values := collect(blobSet.AllNumericsPage(hash.ID("page.metric"), tt.offset, tt.limit))
+			require.Equal(t, tt.expected, indices)
+			for i, idx := range indices {
+				require.Equal(t, float64(idx), values[i])
+			}
+
+			byName, _ := collect(blobSet.AllNumericsPageByName("page.metric", tt.offset, tt.limit))
+			require.Equal(t, tt.expected, byName)
+		})
+	}
+
+	t.Run("EarlyTermination", func(t *testing.T) {
+		count := 0
+		for range blobSet.AllNumericsPage(hash.ID("page.metric"), 2, 5) {
+			count++
+			if count == 2 {
+				break
+			}
+		}
+		require.Equal(t, 2, count)
+	})
+}
+
+func TestBlobSet_AllTimes(t *testing.T) {
+	startTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	encodeNumeric := func(t *testing.T, base time.Time, unit format.TimeUnit) []byte {
+		t.Helper()
+		enc, err := NewNumericEncoder(base, WithTimestampUnit(unit))
+		require.NoError(t, err)
+		require.NoError(t, enc.StartMetricName("cpu", 2))
+		for i := range 2 {
+			require.NoError(t, enc.AddDataPoint(unit.Timestamp(base.Add(time.Duration(i)*time.Second)), float64(i), ""))
+		}
+		require.NoError(t, enc.EndMetric())
+		data, err := enc.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	textEnc, err := NewTextEncoder(startTime)
+	require.NoError(t, err)
+	require.NoError(t, textEnc.StartMetricName("status", 1))
+	require.NoError(t, textEnc.AddDataPoint(startTime.UnixMicro(), "up", ""))
+	require.NoError(t, textEnc.EndMetric())
+	textData, err := textEnc.Finish()
+	require.NoError(t, err)
+
+	// Blobs with different timestamp units are converted independently.
+	blobSet, err := DecodeBlobSet(
+		encodeNumeric(t, startTime.Add(time.Hour), format.TimeUnitSecond),
+		encodeNumeric(t, startTime, format.TimeUnitNanosecond),
+		textData,
+	)
+	require.NoError(t, err)
+
+	expected := []time.Time{
+		startTime,
+		startTime.Add(time.Second),
+		startTime.Add(time.Hour),
+		startTime.Add(time.Hour + time.Second),
+	}
+
+	collect := func(seq iter.Seq2[int, time.Time]) []time.Time {
+		var out []time.Time
+		for i, ts := range seq {
+			require.Equal(t, len(out), i)
+			out = append(out, ts)
+		}
+
+		return out
+	}
+
+	require.Equal(t, expected, collect(blobSet.AllTimesByName("cpu")))
+	require.Equal(t, expected, collect(blobSet.AllTimes(hash.ID("cpu"))))
+	require.Equal(t, []time.Time{startTime}, collect(blobSet.AllTimesByName("status")))
+	require.Empty(t, collect(blobSet.AllTimes(hash.ID("missing"))))
+
+	numeric := blobSet.NumericBlobs()[0]
+	require.Equal(t, format.TimeUnitNanosecond, numeric.TimestampUnit())
+	require.Equal(t, expected[:2], collect(numeric.AllTimes(hash.ID("cpu"))))
+
+	for i := range blobSet.AllTimes(hash.ID("cpu")) {
+		require.Zero(t, i) // Early termination stops after the first time
+		break
+	}
+}
+
+func TestBlobSet_AmbiguousMetricName(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	numEncoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, numEncoder.StartMetricName("cpu", 1))
+	require.NoError(t, numEncoder.AddDataPoint(start.UnixMicro(), 1, ""))
+	require.NoError(t, numEncoder.EndMetric())
+	numData, err := numEncoder.Finish()
+	require.NoError(t, err)
+
+	textEncoder, err := NewTextEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricName("status", 1))
+	require.NoError(t, textEncoder.AddDataPoint(start.UnixMicro(), "ok", ""))
+	require.NoError(t, textEncoder.EndMetric())
+	textData, err := textEncoder.Finish()
+	require.NoError(t, err)
+
+	set, err := DecodeBlobSet(numData, textData)
+	require.NoError(t, err)
+	require.Nil(t, set.ambiguous, "distinct names never collide")
+
+	_, err = set.ResolveMetricName("cpu")
+	require.NoError(t, err)
+	require.Equal(t, 1, set.MetricLenByName("cpu"))
+
+	// Simulate named blobs elsewhere in the set showing that "cpu" and "status"
+	// share their IDs with other names. Real xxHash64 collisions cannot be crafted here.
+	cpuID, statusID := hash.ID("cpu"), hash.ID("status")
+	set.ambiguous = map[uint64][]string{
+		cpuID:    {"cpu", "cpu-twin"},
+		statusID: {"status", "status-twin"},
+	}
+
+	// The numeric blob has no names, so the hash fallback is refused for "cpu".
+	id, err := set.ResolveMetricName("cpu")
+	require.ErrorIs(t, err, errs.ErrAmbiguousMetric)
+	require.Equal(t, cpuID, id)
+	require.Zero(t, set.MetricLenByName("cpu"))
+	require.False(t, set.IsNumericMetricByName("cpu"))
+	for range set.AllNumericsByName("cpu") {
+		require.Fail(t, "ambiguous unnamed blobs must be skipped")
+	}
+	for range set.AllNumericsPageByName("cpu", 0, 10) {
+		require.Fail(t, "ambiguous unnamed blobs must be skipped")
+	}
+	_, ok := set.NumericAtByName("cpu", 0)
+	require.False(t, ok)
+
+	// ID access is unaffected.
+	require.Equal(t, 1, set.MetricLen(cpuID))
+
+	// Text blobs store names, so they keep resolving by name.
+	_, err = set.ResolveMetricName("status")
+	require.NoError(t, err)
+	require.Equal(t, 1, set.MetricLenByName("status"))
+	v, ok := set.TextValueAtByName("status", 0)
+	require.True(t, ok)
+	require.Equal(t, "ok", v)
+}
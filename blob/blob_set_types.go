@@ -0,0 +1,178 @@
+package blob
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/arloliu/mebo/section"
+)
+
+// MetricType classifies a metric of a BlobSet by the kinds of blobs holding it.
+type MetricType uint8
+
+const (
+	// MetricTypeNotFound means no blob holds the metric.
+	MetricTypeNotFound MetricType = iota
+	// MetricTypeNumeric means only numeric blobs hold the metric.
+	MetricTypeNumeric
+	// MetricTypeText means only text blobs hold the metric.
+	MetricTypeText
+	// MetricTypeBoth means both numeric and text blobs hold the metric. Point
+	// lookups and All then return the numeric data.
+	MetricTypeBoth
+)
+
+// String returns the name of the metric type.
+func (t MetricType) String() string {
+	switch t {
+	case MetricTypeNotFound:
+		return "NotFound"
+	case MetricTypeNumeric:
+		return "Numeric"
+	case MetricTypeText:
+		return "Text"
+	case MetricTypeBoth:
+		return "Both"
+	default:
+		return "Unknown"
+	}
+}
+
+// withNumeric returns t extended by numeric data.
+func (t MetricType) withNumeric() MetricType {
+	if t == MetricTypeText || t == MetricTypeBoth {
+		return MetricTypeBoth
+	}
+
+	return MetricTypeNumeric
+}
+
+// withText returns t extended by text data.
+func (t MetricType) withText() MetricType {
+	if t == MetricTypeNumeric || t == MetricTypeBoth {
+		return MetricTypeBoth
+	}
+
+	return MetricTypeText
+}
+
+// MetricListing describes one metric of a BlobSet, as returned by ListMetrics.
+type MetricListing struct {
+	// MetricID is the metric ID.
+	MetricID uint64
+
+	// Name is the metric name, or empty if no blob carries a metric names payload.
+	Name string
+
+	// Type reports whether numeric blobs, text blobs, or both hold the metric.
+	Type MetricType
+}
+
+// MetricType reports whether numeric blobs, text blobs, or both hold the given
+// metric ID, so callers can choose a decode path without probing each kind.
+//
+// Parameters:
+//   - metricID: The metric ID to classify
+//
+// Returns:
+//   - MetricType: The metric type, MetricTypeNotFound if no blob holds the metric
+//
+// Example:
+//
+//	switch blobSet.MetricType(metricID) {
+//	case blob.MetricTypeNumeric, blob.MetricTypeBoth:
+//	    // ... AllNumerics
+//	case blob.MetricTypeText:
+//	    // ... AllTexts
+//	}
+func (bs BlobSet) MetricType(metricID uint64) MetricType {
+	t := MetricTypeNotFound
+	if bs.IsNumericMetric(metricID) {
+		t = t.withNumeric()
+	}
+	if bs.IsTextMetric(metricID) {
+		t = t.withText()
+	}
+
+	return t
+}
+
+// MetricTypeByName reports whether numeric blobs, text blobs, or both hold the
+// given metric name. See MetricType for details.
+//
+// Parameters:
+//   - metricName: The metric name to classify
+//
+// Returns:
+//   - MetricType: The metric type, MetricTypeNotFound if no blob holds the metric
+func (bs BlobSet) MetricTypeByName(metricName string) MetricType {
+	t := MetricTypeNotFound
+	if bs.IsNumericMetricByName(metricName) {
+		t = t.withNumeric()
+	}
+	if bs.IsTextMetricByName(metricName) {
+		t = t.withText()
+	}
+
+	return t
+}
+
+// ListMetrics returns every metric of the numeric and text blobs of the set with
+// its type.
+//
+// Returns:
+//   - []MetricListing: The metrics sorted by MetricID
+//
+// Example:
+//
+//	for _, m := range blobSet.ListMetrics() {
+//	    fmt.Printf("%016x %s: %s\n", m.MetricID, m.Name, m.Type)
+//	}
+func (bs BlobSet) ListMetrics() []MetricListing {
+	byID := make(map[uint64]*MetricListing)
+	listing := func(metricID uint64) *MetricListing {
+		m := byID[metricID]
+		if m == nil {
+			m = &MetricListing{MetricID: metricID}
+			byID[metricID] = m
+		}
+
+		return m
+	}
+
+	for i := range bs.numericBlobs {
+		b := &bs.numericBlobs[i]
+		b.index.ForEach(func(e section.NumericIndexEntry) bool {
+			m := listing(e.MetricID)
+			m.Type = m.Type.withNumeric()
+
+			return true
+		})
+		for name, e := range b.index.nameMap() {
+			byID[e.MetricID].Name = name
+		}
+	}
+
+	for i := range bs.textBlobs {
+		b := &bs.textBlobs[i]
+		b.index.ForEach(func(e section.TextIndexEntry) bool {
+			m := listing(e.MetricID)
+			m.Type = m.Type.withText()
+
+			return true
+		})
+		for name, e := range b.index.nameMap() {
+			byID[e.MetricID].Name = name
+		}
+	}
+
+	metrics := make([]MetricListing, 0, len(byID))
+	for _, m := range byID {
+		metrics = append(metrics, *m)
+	}
+	slices.SortFunc(metrics, func(a, b MetricListing) int {
+		return cmp.Compare(a.MetricID, b.MetricID)
+	})
+
+	return metrics
+}
@@ -0,0 +1,53 @@
+package blob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func TestBlobSet_MetricType(t *testing.T) {
+	set := createMixedBlobSetForMaterialization(t)
+
+	tests := []struct {
+		name string
+		want MetricType
+	}{
+		{name: "cpu.usage", want: MetricTypeNumeric},
+		{name: "status", want: MetricTypeText},
+		{name: "shared", want: MetricTypeBoth},
+		{name: "missing", want: MetricTypeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, set.MetricTypeByName(tt.name))
+			require.Equal(t, tt.want, set.MetricType(hash.ID(tt.name)))
+		})
+	}
+
+	require.Equal(t, "Both", MetricTypeBoth.String())
+	require.Equal(t, MetricTypeNotFound, BlobSet{}.MetricType(1))
+}
+
+func TestBlobSet_ListMetrics(t *testing.T) {
+	set := createMixedBlobSetForMaterialization(t)
+
+	metrics := set.ListMetrics()
+	require.Len(t, metrics, 3)
+	require.IsIncreasing(t, []uint64{metrics[0].MetricID, metrics[1].MetricID, metrics[2].MetricID})
+
+	types := make(map[uint64]MetricType, len(metrics))
+	for _, m := range metrics {
+		types[m.MetricID] = m.Type
+	}
+	require.Equal(t, map[uint64]MetricType{
+		hash.ID("cpu.usage"): MetricTypeNumeric,
+		hash.ID("status"):    MetricTypeText,
+		hash.ID("shared"):    MetricTypeBoth,
+	}, types)
+
+	require.Empty(t, BlobSet{}.ListMetrics())
+}
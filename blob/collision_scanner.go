@@ -0,0 +1,172 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// MetricCollision is a metric ID shared by more than one metric name.
+type MetricCollision struct {
+	// ID is the colliding metric ID.
+	ID uint64
+	// Names are the distinct metric names hashing to ID, sorted.
+	Names []string
+	// Sources are the sources in which any of the names was seen, sorted.
+	Sources []string
+}
+
+// CollisionScanner finds metric names whose IDs collide across a corpus of blobs
+// and name manifests.
+//
+// An encoder detects collisions within one blob, but two blobs written separately
+// may each hold one of two colliding names. A BlobSet built from both then merges
+// the two series under one metric ID. The scanner collects names from every source
+// and reports IDs claimed by more than one name.
+//
+// Numeric blobs only store names when asked to or on a collision within the blob,
+// so blobs without names contribute nothing; add the names their producers use with
+// AddNames. UnnamedBlobs reports how many such blobs were scanned.
+//
+// A CollisionScanner is not safe for concurrent use.
+type CollisionScanner struct {
+	hash    func(string) uint64
+	names   map[uint64]map[string]struct{}
+	sources map[uint64]map[string]struct{}
+	unnamed int
+}
+
+// NewCollisionScanner creates an empty scanner.
+//
+// Example:
+//
+//	scanner := blob.NewCollisionScanner()
+//	scanner.AddNames("manifest.txt", manifestNames...)
+//	for _, path := range blobPaths {
+//	    data, _ := os.ReadFile(path)
+//	    if err := scanner.AddBlobData(path, data); err != nil {
+//	        return err
+//	    }
+//	}
+//	for _, c := range scanner.Collisions() {
+//	    log.Printf("metric ID %#x shared by %v (seen in %v)", c.ID, c.Names, c.Sources)
+//	}
+func NewCollisionScanner() *CollisionScanner {
+	return &CollisionScanner{
+		hash:    hash.ID,
+		names:   make(map[uint64]map[string]struct{}),
+		sources: make(map[uint64]map[string]struct{}),
+	}
+}
+
+// AddNames records metric names from a source such as a manifest or schema file.
+func (s *CollisionScanner) AddNames(source string, names ...string) {
+	for _, name := range names {
+		id := s.hash(name)
+
+		byName, ok := s.names[id]
+		if !ok {
+			byName = make(map[string]struct{}, 1)
+			s.names[id] = byName
+		}
+		byName[name] = struct{}{}
+
+		bySource, ok := s.sources[id]
+		if !ok {
+			bySource = make(map[string]struct{}, 1)
+			s.sources[id] = bySource
+		}
+		bySource[source] = struct{}{}
+	}
+}
+
+// AddNumericBlob records the metric names stored in a numeric blob.
+func (s *CollisionScanner) AddNumericBlob(source string, b NumericBlob) {
+	s.addBlobNames(source, b.MetricNames())
+}
+
+// AddTextBlob records the metric names stored in a text blob.
+func (s *CollisionScanner) AddTextBlob(source string, b TextBlob) {
+	s.addBlobNames(source, b.MetricNames())
+}
+
+// AddBlobSet records the metric names stored in every blob of a set.
+func (s *CollisionScanner) AddBlobSet(source string, set BlobSet) {
+	for _, b := range set.NumericBlobs() {
+		s.AddNumericBlob(source, b)
+	}
+	for _, b := range set.TextBlobs() {
+		s.AddTextBlob(source, b)
+	}
+}
+
+// AddBlobData decodes numeric or text blobs and records their metric names.
+//
+// Parameters:
+//   - source: Name reported in MetricCollision.Sources, typically a file path
+//   - blobs: Encoded blobs of either type
+//
+// Returns:
+//   - error: ErrInvalidMagicNumber for data that is not a mebo blob, or a decoding error
+func (s *CollisionScanner) AddBlobData(source string, blobs ...[]byte) error {
+	for i, data := range blobs {
+		if !section.IsNumericBlob(data) && !section.IsTextBlob(data) {
+			return fmt.Errorf("%w: %s: blob %d is neither numeric nor text", errs.ErrInvalidMagicNumber, source, i)
+		}
+	}
+
+	set, err := DecodeBlobSet(blobs...)
+	if err != nil {
+		return err
+	}
+	s.AddBlobSet(source, set)
+
+	return nil
+}
+
+// Collisions returns the metric IDs claimed by more than one name, sorted by ID.
+func (s *CollisionScanner) Collisions() []MetricCollision {
+	var collisions []MetricCollision
+	for id, names := range s.names {
+		if len(names) < 2 {
+			continue
+		}
+
+		collisions = append(collisions, MetricCollision{
+			ID:      id,
+			Names:   slices.Sorted(maps.Keys(names)),
+			Sources: slices.Sorted(maps.Keys(s.sources[id])),
+		})
+	}
+	slices.SortFunc(collisions, func(a, b MetricCollision) int { return cmp.Compare(a.ID, b.ID) })
+
+	return collisions
+}
+
+// NameCount returns the number of distinct metric names recorded.
+func (s *CollisionScanner) NameCount() int {
+	n := 0
+	for _, names := range s.names {
+		n += len(names)
+	}
+
+	return n
+}
+
+// UnnamedBlobs returns the number of scanned blobs that stored no metric names.
+func (s *CollisionScanner) UnnamedBlobs() int {
+	return s.unnamed
+}
+
+func (s *CollisionScanner) addBlobNames(source string, names []string) {
+	if len(names) == 0 {
+		s.unnamed++
+		return
+	}
+	s.AddNames(source, names...)
+}
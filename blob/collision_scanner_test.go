@@ -0,0 +1,73 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func encodeTextNames(t *testing.T, names ...string) []byte {
+	t.Helper()
+
+	encoder, err := NewTextEncoder(time.Now())
+	require.NoError(t, err)
+	for _, name := range names {
+		require.NoError(t, encoder.StartMetricName(name, 1))
+		require.NoError(t, encoder.AddDataPoint(1, "v", ""))
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func encodeNumericIDs(t *testing.T, ids ...uint64) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(time.Now())
+	require.NoError(t, err)
+	for _, id := range ids {
+		require.NoError(t, encoder.StartMetricID(id, 1))
+		require.NoError(t, encoder.AddDataPoint(1, 1, ""))
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestCollisionScanner_RealHash(t *testing.T) {
+	s := NewCollisionScanner()
+	require.NoError(t, s.AddBlobData("a.mebo", encodeTextNames(t, "cpu", "mem")))
+	require.NoError(t, s.AddBlobData("b.mebo", encodeTextNames(t, "cpu", "disk"), encodeNumericIDs(t, 1, 2)))
+	s.AddNames("manifest", "net", "cpu")
+
+	require.Empty(t, s.Collisions())
+	require.Equal(t, 4, s.NameCount())
+	require.Equal(t, 1, s.UnnamedBlobs())
+
+	require.ErrorIs(t, s.AddBlobData("bad", []byte("garbage")), errs.ErrInvalidMagicNumber)
+}
+
+func TestCollisionScanner_CrossBlobCollision(t *testing.T) {
+	s := NewCollisionScanner()
+	// Force collisions: every name hashes to its length.
+	s.hash = func(name string) uint64 { return uint64(len(name)) }
+
+	// Each blob on its own is collision-free; only the corpus collides.
+	require.NoError(t, s.AddBlobData("a.mebo", encodeTextNames(t, "cpu", "memory")))
+	require.NoError(t, s.AddBlobData("b.mebo", encodeTextNames(t, "net", "cpu")))
+	s.AddNames("manifest", "disk", "load", "swap")
+
+	require.Equal(t, []MetricCollision{
+		{ID: 3, Names: []string{"cpu", "net"}, Sources: []string{"a.mebo", "b.mebo"}},
+		{ID: 4, Names: []string{"disk", "load", "swap"}, Sources: []string{"manifest"}},
+	}, s.Collisions())
+	require.Equal(t, 6, s.NameCount())
+	require.Zero(t, s.UnnamedBlobs())
+}
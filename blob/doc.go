@@ -9,19 +9,27 @@
 // Encoders - Create blobs from time-series data:
 //   - NumericEncoder: Encodes float64 metrics with configurable compression
 //   - TextEncoder: Encodes string metrics with configurable compression
+//   - Int64Encoder: Encodes exact int64 metrics such as counters and IDs
+//   - EventEncoder: Encodes sparse event streams (deploy markers, alerts) with a text dictionary
 //
 // Decoders - Read data from blobs:
 //   - NumericDecoder: Decodes numeric blobs with sequential and random access
 //   - TextDecoder: Decodes text blobs with sequential access
+//   - Int64Decoder: Decodes int64 blobs with exact values
+//   - EventDecoder: Decodes event blobs
 //
 // Blobs - Immutable binary containers:
 //   - NumericBlob: Contains encoded numeric metrics
 //   - TextBlob: Contains encoded text metrics
+//   - Int64Blob: Contains encoded int64 metrics
+//   - EventBlob: Contains encoded event streams
 //
 // Blob Sets - Multi-blob collections:
 //   - NumericBlobSet: Unified access across multiple numeric blobs
 //   - TextBlobSet: Unified access across multiple text blobs
-//   - BlobSet: Heterogeneous collection of both numeric and text blobs
+//   - Int64BlobSet: Unified access across multiple int64 blobs
+//   - BlobSet: Heterogeneous collection of both numeric and text blobs, plus event
+//     blobs for annotations (see BlobSet.WithEventBlobs)
 //
 // Materialized Views - O(1) random access:
 //   - MaterializedNumericBlobSet: Pre-decoded numeric data for fast random access
@@ -147,6 +155,16 @@
 //   - blob.WithTextDataCompression(format.CompressionNone|Zstd|S2|LZ4) - Data compression
 //   - blob.WithTextTagsEnabled(true|false) - Enable/disable tags
 //
+// # Metadata Section
+//
+// Several numeric encoder options (e.g., WithValuePrecision, WithTimestampUnit,
+// WithTagCompression, WithMetricReferences) record their settings in an optional
+// metadata section flagged in the blob header. Blobs without it keep the original
+// layout.
+//
+// IMPORTANT: Blobs carrying a metadata section can only be decoded by mebo versions
+// that understand it. Upgrade consumers before enabling these options on producers.
+//
 // # Performance Characteristics
 //
 // Encoding:
@@ -174,6 +192,20 @@
 //   - Memory: ~16 bytes/point (numeric), ~24 bytes/point (text)
 //   - Access: O(1), ~5 ns per access
 //
+// BlobSet.Explain reports the access path and expected decoding cost of a
+// metric's point lookups in every blob, and the number of lookups after which
+// materializing the metric pays off.
+//
+// # Instrumentation
+//
+// ReadHooks receives read-path events: WithReadHooks and WithTextReadHooks
+// report the payload sections decompressed by decoders, and BlobSet.WithReadHooks
+// reports the duration, points and bytes of every metric iteration.
+//
+// Iterations over a metric skip the blobs that lack it using their metric index,
+// without decoding any of their data; ScanEvent.Skipped counts them. ScanCounters
+// accumulates these events, e.g. to verify that sparse metrics stay cheap to scan.
+//
 // # Thread Safety
 //
 // Encoders: Not thread-safe. Use one encoder per goroutine.
@@ -182,7 +214,8 @@
 //
 // Blobs: Immutable and thread-safe once created.
 //
-// BlobSets: Safe for concurrent reads.
+// BlobSets: Safe for concurrent reads. Use LiveBlobSet to append and evict blobs
+// while other goroutines read copy-on-write snapshots.
 //
 // MaterializedBlobSets: Safe for concurrent reads.
 //
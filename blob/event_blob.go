@@ -0,0 +1,188 @@
+package blob
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// EventDataPoint represents a single event with timestamp, text, and optional tag.
+type EventDataPoint struct {
+	// Ts is the timestamp, the unit is defined by the caller when adding events in EventEncoder
+	Ts int64
+	// Text is the event text (max 255 UTF-8 bytes), empty for plain markers
+	Text string
+	// Tag is the optional tag associated with this event (max 255 UTF-8 bytes)
+	Tag string
+}
+
+// EventBlob represents a decoded blob of sparse event streams, such as deploy
+// markers and alerts, produced by EventEncoder and decoded by EventDecoder.
+//
+// Each metric is an event stream; event texts are resolved from the blob's text
+// dictionary. Timestamps, tags, and metric lookups behave as in TextBlob.
+type EventBlob struct {
+	text TextBlob
+	dict []string // Dictionary texts in code order
+}
+
+// StartTime returns the start time of the blob.
+func (b EventBlob) StartTime() time.Time {
+	return b.text.StartTime()
+}
+
+// MetricCount returns the number of event streams in the blob.
+func (b EventBlob) MetricCount() int {
+	return b.text.MetricCount()
+}
+
+// HasMetricID checks if the blob contains the given metric ID.
+func (b EventBlob) HasMetricID(metricID uint64) bool {
+	return b.text.HasMetricID(metricID)
+}
+
+// HasMetricName checks if the blob contains the given metric name.
+func (b EventBlob) HasMetricName(metricName string) bool {
+	return b.text.HasMetricName(metricName)
+}
+
+// HasMetricNames returns whether the blob carries a metric names payload.
+func (b EventBlob) HasMetricNames() bool {
+	return b.text.HasMetricNames()
+}
+
+// HasTag returns whether the blob was encoded with tags.
+func (b EventBlob) HasTag() bool {
+	return b.text.HasTag()
+}
+
+// MetricIDs returns a slice of all metric IDs in the blob.
+func (b EventBlob) MetricIDs() []uint64 {
+	return b.text.MetricIDs()
+}
+
+// MetricNames returns a slice of all metric names in the blob, or an empty
+// slice if the blob was encoded without metric names.
+func (b EventBlob) MetricNames() []string {
+	return b.text.MetricNames()
+}
+
+// Texts returns a copy of the blob's distinct event texts in first-seen order.
+func (b EventBlob) Texts() []string {
+	return slices.Clone(b.dict)
+}
+
+// Len returns the number of events for the given metric ID, or 0 if the metric
+// ID does not exist.
+func (b EventBlob) Len(metricID uint64) int {
+	return b.text.Len(metricID)
+}
+
+// LenByName returns the number of events for the given metric name, or 0 if the
+// metric name does not exist.
+func (b EventBlob) LenByName(metricName string) int {
+	return b.text.LenByName(metricName)
+}
+
+// All returns an iterator over all events of the given metric ID in insertion
+// order. It returns an empty iterator if the metric ID is not found.
+//
+// Example:
+//
+//	for i, ev := range blob.All(metricID) {
+//	    fmt.Printf("[%d] ts=%d, text=%s, tag=%s\n", i, ev.Ts, ev.Text, ev.Tag)
+//	}
+func (b EventBlob) All(metricID uint64) iter.Seq2[int, EventDataPoint] {
+	return b.resolve(b.text.All(metricID))
+}
+
+// AllByName returns an iterator over all events of the given metric name in
+// insertion order. It returns an empty iterator if the metric name is not found.
+func (b EventBlob) AllByName(metricName string) iter.Seq2[int, EventDataPoint] {
+	return b.resolve(b.text.AllByName(metricName))
+}
+
+// AllBetween returns an iterator over the events of the given metric ID whose
+// timestamps fall within [start, end], such as the annotations of a chart's
+// visible time range. The index is the event's position in the metric.
+func (b EventBlob) AllBetween(metricID uint64, start, end int64) iter.Seq2[int, EventDataPoint] {
+	return func(yield func(int, EventDataPoint) bool) {
+		for i, ev := range b.All(metricID) {
+			if ev.Ts < start || ev.Ts > end {
+				continue
+			}
+
+			if !yield(i, ev) {
+				return
+			}
+		}
+	}
+}
+
+// AllTimestamps returns an iterator over all timestamps of the given metric ID.
+func (b EventBlob) AllTimestamps(metricID uint64) iter.Seq[int64] {
+	return b.text.AllTimestamps(metricID)
+}
+
+// TextAt returns the event text at the specified index for the given metric ID.
+//
+// Returns ("", false) if the metric doesn't exist or the index is out of bounds.
+func (b EventBlob) TextAt(metricID uint64, index int) (string, bool) {
+	code, ok := b.text.ValueAt(metricID, index)
+	if !ok {
+		return "", false
+	}
+
+	return b.lookup(code)
+}
+
+// TimestampAt returns the timestamp at the specified index for the given metric ID.
+//
+// See TextBlob.TimestampAt for details.
+func (b EventBlob) TimestampAt(metricID uint64, index int) (int64, bool) {
+	return b.text.TimestampAt(metricID, index)
+}
+
+// TagAt returns the tag at the specified index for the given metric ID.
+//
+// See TextBlob.TagAt for details.
+func (b EventBlob) TagAt(metricID uint64, index int) (string, bool) {
+	return b.text.TagAt(metricID, index)
+}
+
+// resolve maps the dictionary codes of rows to event texts, stopping at the
+// first code outside the dictionary.
+func (b EventBlob) resolve(rows iter.Seq2[int, TextDataPoint]) iter.Seq2[int, EventDataPoint] {
+	return func(yield func(int, EventDataPoint) bool) {
+		for i, dp := range rows {
+			text, ok := b.lookup(dp.Val)
+			if !ok {
+				return
+			}
+
+			if !yield(i, EventDataPoint{Ts: dp.Ts, Text: text, Tag: dp.Tag}) {
+				return
+			}
+		}
+	}
+}
+
+// lookup decodes the uvarint dictionary code and returns its text.
+func (b EventBlob) lookup(code string) (string, bool) {
+	var idx uint64
+	var shift uint
+	for i := 0; i < len(code) && shift < 64; i++ {
+		c := code[i]
+		idx |= uint64(c&0x7F) << shift
+		if c < 0x80 {
+			if i != len(code)-1 || idx >= uint64(len(b.dict)) {
+				return "", false
+			}
+
+			return b.dict[idx], true
+		}
+		shift += 7
+	}
+
+	return "", false
+}
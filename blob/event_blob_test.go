@@ -0,0 +1,215 @@
+package blob
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func encodeEventTestBlob(t *testing.T, start time.Time, events map[string][]EventDataPoint, opts ...TextEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewEventEncoder(start, opts...)
+	require.NoError(t, err)
+
+	for _, name := range slices.Sorted(maps.Keys(events)) {
+		require.NoError(t, encoder.StartMetricName(name, len(events[name])))
+		for _, ev := range events[name] {
+			require.NoError(t, encoder.AddDataPoint(ev.Ts, ev.Text, ev.Tag))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestEventBlob_RoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	base := start.UnixMicro()
+	events := map[string][]EventDataPoint{
+		"deploys": {
+			{Ts: base + 10, Text: "deploy v1.4.0", Tag: "api"},
+			{Ts: base + 20, Text: "rollback", Tag: "api"},
+			{Ts: base + 30, Text: "deploy v1.4.0", Tag: "web"},
+		},
+		"alerts": {
+			{Ts: base + 15, Text: "", Tag: "cpu"},
+			{Ts: base + 25, Text: "rollback", Tag: "mem"},
+		},
+	}
+
+	for _, layout := range []struct {
+		name string
+		opts []TextEncoderOption
+	}{
+		{name: "Raw-Zstd", opts: []TextEncoderOption{WithTextTagsEnabled(true)}},
+		{name: "Delta-None-BigEndian", opts: []TextEncoderOption{
+			WithTextTagsEnabled(true),
+			WithTextTimestampEncoding(format.TypeDelta),
+			WithTextDataCompression(format.CompressionNone),
+			WithTextBigEndian(),
+		}},
+	} {
+		t.Run(layout.name, func(t *testing.T) {
+			data := encodeEventTestBlob(t, start, events, layout.opts...)
+
+			decoder, err := NewEventDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			require.Equal(t, start, blob.StartTime())
+			require.Equal(t, 2, blob.MetricCount())
+			require.True(t, blob.HasTag())
+			require.ElementsMatch(t, []string{"", "deploy v1.4.0", "rollback"}, blob.Texts())
+
+			for name, want := range events {
+				require.True(t, blob.HasMetricName(name))
+				require.Equal(t, len(want), blob.LenByName(name))
+
+				var got []EventDataPoint
+				for _, ev := range blob.AllByName(name) {
+					got = append(got, ev)
+				}
+				require.Equal(t, want, got)
+
+				id := hash.ID(name)
+				text, ok := blob.TextAt(id, len(want)-1)
+				require.True(t, ok)
+				require.Equal(t, want[len(want)-1].Text, text)
+
+				_, ok = blob.TextAt(id, len(want))
+				require.False(t, ok)
+			}
+
+			var between []string
+			for i, ev := range blob.AllBetween(hash.ID("deploys"), base+15, base+30) {
+				require.Positive(t, i)
+				between = append(between, ev.Text)
+			}
+			require.Equal(t, []string{"rollback", "deploy v1.4.0"}, between)
+		})
+	}
+}
+
+func TestEventBlob_DictionaryDeduplicates(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	text := strings.Repeat("x", 200)
+
+	encoder, err := NewEventEncoder(start, WithTextDataCompression(format.CompressionNone))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 100))
+	for i := range 100 {
+		require.NoError(t, encoder.AddDataPoint(int64(i), text, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	// The text is stored once, not per event.
+	require.Less(t, len(data), 2*len(text)+100*10)
+
+	decoder, err := NewEventDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []string{text}, blob.Texts())
+	for _, ev := range blob.All(1) {
+		require.Equal(t, text, ev.Text)
+	}
+}
+
+func TestEventDecoder_Errors(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	encoder, err := NewEventEncoder(start)
+	require.NoError(t, err)
+	require.ErrorIs(t, encoder.AddDataPoint(1, strings.Repeat("x", 256), ""), errs.ErrNoMetricStarted)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.Error(t, encoder.AddDataPoint(1, strings.Repeat("x", 256), ""))
+	_, err = encoder.Finish()
+	require.ErrorIs(t, err, errs.ErrMetricNotEnded)
+
+	eventData := encodeEventTestBlob(t, start, map[string][]EventDataPoint{"deploys": {{Ts: 1, Text: "deploy"}}})
+
+	// Event blobs are rejected by the text decoder, and text blobs by the event decoder.
+	_, err = NewTextDecoder(eventData)
+	require.ErrorIs(t, err, errs.ErrInvalidMagicNumber)
+
+	textEncoder, err := NewTextEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricID(1, 1))
+	require.NoError(t, textEncoder.AddDataPoint(1, "deploy", ""))
+	require.NoError(t, textEncoder.EndMetric())
+	textData, err := textEncoder.Finish()
+	require.NoError(t, err)
+
+	_, err = NewEventDecoder(textData)
+	require.ErrorIs(t, err, errs.ErrInvalidMagicNumber)
+}
+
+func TestBlobSet_Events(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	later := start.Add(time.Hour)
+	base := start.UnixMicro()
+
+	numericEncoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, numericEncoder.StartMetricName("cpu", 2))
+	require.NoError(t, numericEncoder.AddDataPoint(base, 1, ""))
+	require.NoError(t, numericEncoder.AddDataPoint(base+10, 2, ""))
+	require.NoError(t, numericEncoder.EndMetric())
+	numericData, err := numericEncoder.Finish()
+	require.NoError(t, err)
+
+	second := encodeEventTestBlob(t, later, map[string][]EventDataPoint{
+		"deploys": {{Ts: later.UnixMicro() + 5, Text: "deploy v2"}},
+	})
+	first := encodeEventTestBlob(t, start, map[string][]EventDataPoint{
+		"deploys": {{Ts: base + 5, Text: "deploy v1"}, {Ts: base + 50, Text: "rollback"}},
+	})
+
+	set, err := DecodeBlobSet(numericData, second, first)
+	require.NoError(t, err)
+	require.Len(t, set.NumericBlobs(), 1)
+	require.Len(t, set.EventBlobs(), 2)
+	require.Equal(t, start, set.EventBlobs()[0].StartTime())
+
+	// Numeric queries are unaffected by event streams.
+	require.Equal(t, 2, set.MetricLenByName("cpu"))
+	require.Zero(t, set.MetricLenByName("deploys"))
+
+	var texts []string
+	for i, ev := range set.AllEventsByName("deploys") {
+		require.Len(t, texts, i)
+		texts = append(texts, ev.Text)
+	}
+	require.Equal(t, []string{"deploy v1", "rollback", "deploy v2"}, texts)
+
+	texts = texts[:0]
+	for _, ev := range set.AllEvents(hash.ID("deploys")) {
+		texts = append(texts, ev.Text)
+	}
+	require.Equal(t, []string{"deploy v1", "rollback", "deploy v2"}, texts)
+
+	texts = texts[:0]
+	for ev := range set.AllEventsBetween(hash.ID("deploys"), base+10, later.UnixMicro()) {
+		texts = append(texts, ev.Text)
+	}
+	require.Equal(t, []string{"rollback"}, texts)
+
+	// WithEventBlobs replaces the event blobs of the set.
+	require.Empty(t, set.WithEventBlobs().EventBlobs())
+	require.Len(t, set.EventBlobs(), 2)
+}
@@ -0,0 +1,80 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// EventDecoder decodes blobs produced by EventEncoder into an EventBlob.
+//
+// Note: The EventDecoder is NOT thread-safe and NOT reusable, like TextDecoder.
+type EventDecoder struct {
+	text *TextDecoder
+}
+
+// NewEventDecoder creates a decoder for a blob produced by EventEncoder.
+//
+// Parameters:
+//   - data: Encoded blob byte slice
+//   - opts: Optional TextDecoder options (e.g., WithTextStringInterning for tags)
+//
+// Returns:
+//   - *EventDecoder: New decoder instance ready for decoding
+//   - error: Header parsing error, or ErrInvalidMagicNumber if the blob is not an event blob
+func NewEventDecoder(data []byte, opts ...TextDecoderOption) (*EventDecoder, error) {
+	text, err := newTextDecoder(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !text.header.Flag.IsEvent() {
+		return nil, fmt.Errorf("%w: not an event blob", errs.ErrInvalidMagicNumber)
+	}
+
+	return &EventDecoder{text: text}, nil
+}
+
+// Decode decodes the encoded data into an EventBlob.
+//
+// Returns:
+//   - EventBlob: Decoded blob
+//   - error: Any error of TextDecoder.Decode, or ErrInvalidEventDictionary if the
+//     text dictionary is malformed
+func (d *EventDecoder) Decode() (EventBlob, error) {
+	text, err := d.text.Decode()
+	if err != nil {
+		return EventBlob{}, err
+	}
+
+	dictOffset := int(d.text.header.EventDictionaryOffset())
+	if dictOffset > len(text.dataPayload) {
+		return EventBlob{}, fmt.Errorf("%w: dictionary offset %d exceeds data size %d",
+			errs.ErrInvalidEventDictionary, dictOffset, len(text.dataPayload))
+	}
+
+	dict, err := parseEventDictionary(text.dataPayload[dictOffset:])
+	if err != nil {
+		return EventBlob{}, err
+	}
+
+	text.dataPayload = text.dataPayload[:dictOffset]
+
+	return EventBlob{text: text, dict: dict}, nil
+}
+
+// parseEventDictionary parses the [uint8 length][bytes] dictionary texts.
+func parseEventDictionary(data []byte) ([]string, error) {
+	var dict []string
+	for offset := 0; offset < len(data); {
+		end := offset + 1 + int(data[offset])
+		if end > len(data) {
+			return nil, fmt.Errorf("%w: text %d exceeds data section", errs.ErrInvalidEventDictionary, len(dict))
+		}
+
+		dict = append(dict, string(data[offset+1:end]))
+		offset = end
+	}
+
+	return dict, nil
+}
@@ -0,0 +1,151 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	ienc "github.com/arloliu/mebo/internal/encoding"
+)
+
+// EventEncoder encodes sparse event streams, such as deploy markers and alerts,
+// where each data point is a timestamp, a small text, and an optional tag.
+//
+// It produces an event blob: the text blob layout with its own magic number,
+// where each row stores a varint code into a text dictionary instead of the
+// text itself. Repeated event texts ("deploy", "rollback") are stored once per
+// blob, and the dictionary is appended to the data section before compression.
+// An empty text is a valid event, so the blob also serves as a plain marker stream.
+//
+// Decode the result with NewEventDecoder, or through DecodeBlobSet to query
+// events alongside numeric blobs.
+//
+// Note: The EventEncoder is NOT thread-safe and NOT reusable, like TextEncoder.
+type EventEncoder struct {
+	text  *TextEncoder
+	codes map[string]string // Event text → encoded dictionary code
+	dict  []string          // Dictionary texts in code order
+}
+
+// NewEventEncoder creates a new EventEncoder with the given start time.
+//
+// It accepts the TextEncoder options for timestamps, tags, endianness, and
+// data compression.
+//
+// Parameters:
+//   - blobTS: Timestamp for the entire blob, used as sorting key for all blobs in the same series
+//   - opts: Optional TextEncoder configuration
+//
+// Returns:
+//   - *EventEncoder: New encoder instance ready for metric encoding
+//   - error: Configuration error if invalid options provided, or ErrUnsupportedBlobFeature
+//     with WithTextValueCompression or WithTextTagCompression
+//
+// Example:
+//
+//	encoder, err := blob.NewEventEncoder(time.Now(), blob.WithTextTagsEnabled(true))
+//	if err != nil {
+//	    return err
+//	}
+//	encoder.StartMetricName("deploys", 2)
+//	encoder.AddDataPoint(ts1, "deploy v1.4.0", "api")
+//	encoder.AddDataPoint(ts2, "rollback", "api")
+//	encoder.EndMetric()
+//	data, err := encoder.Finish()
+func NewEventEncoder(blobTS time.Time, opts ...TextEncoderOption) (*EventEncoder, error) {
+	text, err := NewTextEncoder(blobTS, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if text.header.Flag.HasValueCompression() {
+		return nil, fmt.Errorf("%w: event blobs do not support per-value compression", errs.ErrUnsupportedBlobFeature)
+	}
+	if text.header.Flag.HasTagSection() {
+		return nil, fmt.Errorf("%w: event blobs do not support a separate tag section", errs.ErrUnsupportedBlobFeature)
+	}
+
+	text.header.Flag.SetEvent(true)
+
+	return &EventEncoder{
+		text:  text,
+		codes: make(map[string]string),
+	}, nil
+}
+
+// StartMetricID starts encoding a new event stream identified by metricID.
+//
+// See TextEncoder.StartMetricID for details.
+func (e *EventEncoder) StartMetricID(metricID uint64, numOfDataPoints int) error {
+	return e.text.StartMetricID(metricID, numOfDataPoints)
+}
+
+// StartMetricName starts encoding a new event stream identified by metricName.
+//
+// See TextEncoder.StartMetricName for details.
+func (e *EventEncoder) StartMetricName(metricName string, numOfDataPoints int) error {
+	return e.text.StartMetricName(metricName, numOfDataPoints)
+}
+
+// AddDataPoint adds a single event to the current event stream.
+//
+// Parameters:
+//   - timestamp: Caller-defined timestamp value (e.g. microseconds since Unix epoch)
+//   - text: Event text (max 255 UTF-8 bytes), may be empty for plain markers
+//   - tag: Optional tag string (max 255 UTF-8 bytes, ignored if tag support is disabled)
+//
+// Returns:
+//   - error: ErrNoMetricStarted, text/tag length validation errors, or ErrTooManyDataPoints
+func (e *EventEncoder) AddDataPoint(timestamp int64, text string, tag string) error {
+	if e.text.curMetricID == 0 {
+		return errs.ErrNoMetricStarted
+	}
+
+	if len(text) > ienc.MaxTextLength {
+		return fmt.Errorf("text length %d exceeds maximum %d", len(text), ienc.MaxTextLength)
+	}
+
+	code, ok := e.codes[text]
+	if !ok {
+		code = string(binary.AppendUvarint(nil, uint64(len(e.dict))))
+	}
+
+	if err := e.text.AddDataPoint(timestamp, code, tag); err != nil {
+		return err
+	}
+
+	// Only texts of added events enter the dictionary
+	if !ok {
+		e.codes[text] = code
+		e.dict = append(e.dict, text)
+	}
+
+	return nil
+}
+
+// EndMetric ends the current event stream.
+//
+// See TextEncoder.EndMetric for details.
+func (e *EventEncoder) EndMetric() error {
+	return e.text.EndMetric()
+}
+
+// Finish appends the text dictionary, finalizes the blob, and returns the
+// encoded bytes. After calling Finish, the encoder cannot be reused.
+//
+// See TextEncoder.Finish for details.
+func (e *EventEncoder) Finish() ([]byte, error) {
+	if e.text.curMetricID != 0 {
+		return nil, errs.ErrMetricNotEnded
+	}
+
+	// The dictionary follows the rows, each text as [uint8 length][bytes]
+	e.text.header.SetEventDictionaryOffset(uint32(e.text.dataEncoder.Size())) //nolint:gosec
+	for _, text := range e.dict {
+		e.text.dataEncoder.WriteRaw([]byte{byte(len(text))}) //nolint:gosec // MaxTextLength bounds the text length to one byte.
+		e.text.dataEncoder.WriteRaw([]byte(text))
+	}
+
+	return e.text.Finish()
+}
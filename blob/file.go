@@ -0,0 +1,179 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// SealedMarkerSuffix is appended to a blob file path to name its sealed marker.
+const SealedMarkerSuffix = ".sealed"
+
+// FsyncPolicy controls how WriteFileAtomic makes writes durable.
+type FsyncPolicy uint8
+
+const (
+	// FsyncNone skips fsync. Renames keep readers from seeing partial files, but
+	// a power loss may lose recent writes or leave the marker pointing at stale data,
+	// which ReadSealed detects.
+	FsyncNone FsyncPolicy = iota
+	// FsyncFile fsyncs each file before renaming it into place.
+	FsyncFile
+	// FsyncFileAndDir additionally fsyncs the parent directory after each rename,
+	// so the new names survive a power loss. This is the safest policy.
+	FsyncFileAndDir
+)
+
+const (
+	// sealedMarkerMagic starts every sealed marker.
+	sealedMarkerMagic = "MEBOSEAL"
+	// sealedMarkerSize is the marker size: magic, blob length and xxHash64 checksum.
+	sealedMarkerSize = len(sealedMarkerMagic) + 16
+)
+
+// WriteFileAtomic writes a blob to path and seals it.
+//
+// The blob is written to a temporary file in the same directory and renamed over
+// path, so readers never observe a partially written blob. A sealed marker
+// (path + SealedMarkerSuffix) holding the blob length and checksum is then written
+// the same way. The marker is written last, so its presence means the blob write
+// completed; use ReadSealed to read only sealed blobs.
+//
+// Parameters:
+//   - path: Destination file path
+//   - data: Blob bytes
+//   - policy: Durability policy (FsyncNone, FsyncFile or FsyncFileAndDir)
+//
+// Returns:
+//   - error: File system error; temporary files are removed on failure
+//
+// Example:
+//
+//	data, _ := encoder.Finish()
+//	if err := blob.WriteFileAtomic("/data/cpu-2024-01-01.mebo", data, blob.FsyncFileAndDir); err != nil {
+//	    return err
+//	}
+func WriteFileAtomic(path string, data []byte, policy FsyncPolicy) error {
+	if policy > FsyncFileAndDir {
+		return fmt.Errorf("invalid fsync policy: %d", policy)
+	}
+
+	// Remove a previous marker first so a crash between the two renames cannot
+	// leave an old marker sealing new data.
+	if err := os.Remove(path + SealedMarkerSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := writeFileAtomic(path, data, policy); err != nil {
+		return err
+	}
+
+	marker := make([]byte, 0, sealedMarkerSize)
+	marker = append(marker, sealedMarkerMagic...)
+	marker = binary.LittleEndian.AppendUint64(marker, uint64(len(data)))
+	marker = binary.LittleEndian.AppendUint64(marker, xxhash.Sum64(data))
+
+	return writeFileAtomic(path+SealedMarkerSuffix, marker, policy)
+}
+
+// ReadSealed reads a blob written by WriteFileAtomic.
+//
+// It returns ErrBlobNotSealed when the sealed marker is missing, meaning the write
+// never completed, and ErrBlobSealMismatch when the file does not match the length
+// and checksum recorded in the marker.
+//
+// Parameters:
+//   - path: Blob file path
+//
+// Returns:
+//   - []byte: Blob bytes, ready for NewNumericDecoder or NewTextDecoder
+//   - error: ErrBlobNotSealed, ErrBlobSealMismatch or a file system error
+func ReadSealed(path string) ([]byte, error) {
+	marker, err := os.ReadFile(path + SealedMarkerSuffix)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", errs.ErrBlobNotSealed, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(marker) != sealedMarkerSize || string(marker[:len(sealedMarkerMagic)]) != sealedMarkerMagic {
+		return nil, fmt.Errorf("%w: %s: malformed marker", errs.ErrBlobSealMismatch, path)
+	}
+	size := binary.LittleEndian.Uint64(marker[len(sealedMarkerMagic):])
+	checksum := binary.LittleEndian.Uint64(marker[len(sealedMarkerMagic)+8:])
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(data)) != size || xxhash.Sum64(data) != checksum {
+		return nil, fmt.Errorf("%w: %s", errs.ErrBlobSealMismatch, path)
+	}
+
+	return data, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into place.
+func writeFileAtomic(path string, data []byte, policy FsyncPolicy) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(0o644); err != nil {
+		return err
+	}
+	if policy >= FsyncFile {
+		if err = tmp.Sync(); err != nil {
+			return err
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	if policy == FsyncFileAndDir {
+		return syncDir(dir)
+	}
+
+	return nil
+}
+
+// syncDir fsyncs a directory so renames within it are durable. Windows does not
+// support syncing directories, so it is a no-op there.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
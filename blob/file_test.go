@@ -0,0 +1,86 @@
+package blob
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func TestWriteFileAtomic_ReadSealed(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Now())
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("cpu", 1))
+	require.NoError(t, encoder.AddDataPoint(1, 1.5, ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	for _, policy := range []FsyncPolicy{FsyncNone, FsyncFile, FsyncFileAndDir} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "cpu.mebo")
+
+		require.NoError(t, WriteFileAtomic(path, data, policy))
+		got, err := ReadSealed(path)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2, "no temporary files are left behind")
+
+		// Overwriting replaces both the blob and its marker.
+		require.NoError(t, WriteFileAtomic(path, data[:len(data)-1], policy))
+		got, err = ReadSealed(path)
+		require.NoError(t, err)
+		require.Len(t, got, len(data)-1)
+	}
+
+	require.Error(t, WriteFileAtomic(filepath.Join(t.TempDir(), "x"), data, FsyncFileAndDir+1))
+}
+
+func TestReadSealed_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "blob.mebo")
+	data := []byte("not really a blob")
+
+	// A blob without a marker, as left by a crash before sealing.
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err := ReadSealed(path)
+	require.ErrorIs(t, err, errs.ErrBlobNotSealed)
+
+	require.NoError(t, WriteFileAtomic(path, data, FsyncNone))
+
+	tests := []struct {
+		name   string
+		mutate func(t *testing.T)
+	}{
+		{name: "Truncated", mutate: func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, data[:4], 0o600))
+		}},
+		{name: "Corrupted", mutate: func(t *testing.T) {
+			corrupted := append([]byte(nil), data...)
+			corrupted[0] ^= 0xff
+			require.NoError(t, os.WriteFile(path, corrupted, 0o600))
+		}},
+		{name: "MalformedMarker", mutate: func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path+SealedMarkerSuffix, []byte("bogus"), 0o600))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, WriteFileAtomic(path, data, FsyncNone))
+			tt.mutate(t)
+
+			_, err := ReadSealed(path)
+			require.ErrorIs(t, err, errs.ErrBlobSealMismatch)
+		})
+	}
+
+	_, err = ReadSealed(filepath.Join(dir, "missing.mebo"))
+	require.ErrorIs(t, err, errs.ErrBlobNotSealed)
+}
@@ -0,0 +1,250 @@
+package blob
+
+import (
+	"iter"
+	"slices"
+	"time"
+
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/section"
+)
+
+// Int64DataPoint represents a single data point with timestamp, int64 value, and optional tag.
+type Int64DataPoint struct {
+	// Ts is the timestamp, the unit is defined by the caller when adding data points in Int64Encoder
+	Ts int64
+	// Val is the exact int64 value
+	Val int64
+	// Tag is the optional tag associated with this data point
+	Tag string
+}
+
+// Int64Blob represents a decoded blob of int64 values with associated timestamps
+// and optional tags, produced by Int64Encoder and decoded by Int64Decoder.
+//
+// Values are returned exactly, including magnitudes beyond 2^53 that float64
+// cannot represent. Timestamps, tags, and metric lookups behave as in NumericBlob.
+type Int64Blob struct {
+	numeric NumericBlob
+}
+
+// Numeric returns a float64 view of the blob, whose values are the int64 values
+// converted to the nearest float64.
+func (b Int64Blob) Numeric() NumericBlob {
+	return b.numeric
+}
+
+// StartTime returns the start time of the blob.
+func (b Int64Blob) StartTime() time.Time {
+	return b.numeric.StartTime()
+}
+
+// MetricCount returns the number of metrics in the blob.
+func (b Int64Blob) MetricCount() int {
+	return b.numeric.MetricCount()
+}
+
+// HasMetricID checks if the blob contains the given metric ID.
+func (b Int64Blob) HasMetricID(metricID uint64) bool {
+	return b.numeric.HasMetricID(metricID)
+}
+
+// HasMetricName checks if the blob contains the given metric name.
+func (b Int64Blob) HasMetricName(metricName string) bool {
+	return b.numeric.HasMetricName(metricName)
+}
+
+// HasTag returns whether the blob was encoded with tags.
+func (b Int64Blob) HasTag() bool {
+	return b.numeric.HasTag()
+}
+
+// MetricIDs returns a cloned slice of all metric IDs in the blob.
+func (b Int64Blob) MetricIDs() []uint64 {
+	return b.numeric.MetricIDs()
+}
+
+// MetricNames returns a cloned slice of all metric names in the blob, or an
+// empty slice if the blob was encoded without metric names.
+func (b Int64Blob) MetricNames() []string {
+	return b.numeric.MetricNames()
+}
+
+// Len returns the number of data points for the given metric ID, or 0 if the
+// metric ID does not exist.
+func (b Int64Blob) Len(metricID uint64) int {
+	return b.numeric.Len(metricID)
+}
+
+// LenByName returns the number of data points for the given metric name, or 0
+// if the metric name does not exist.
+func (b Int64Blob) LenByName(metricName string) int {
+	return b.numeric.LenByName(metricName)
+}
+
+// All returns an iterator over all data points of the given metric ID in
+// insertion order. It returns an empty iterator if the metric ID is not found.
+//
+// Example:
+//
+//	for i, dp := range blob.All(metricID) {
+//	    fmt.Printf("[%d] ts=%d, val=%d\n", i, dp.Ts, dp.Val)
+//	}
+func (b Int64Blob) All(metricID uint64) iter.Seq2[int, Int64DataPoint] {
+	entry, ok := b.numeric.index.GetByID(metricID)
+	if !ok {
+		return func(yield func(int, Int64DataPoint) bool) {}
+	}
+
+	return b.allFromEntry(entry)
+}
+
+// AllByName returns an iterator over all data points of the given metric name
+// in insertion order. It returns an empty iterator if the metric name is not found.
+func (b Int64Blob) AllByName(metricName string) iter.Seq2[int, Int64DataPoint] {
+	entry, ok := b.numeric.lookupMetricEntry(metricName)
+	if !ok {
+		return func(yield func(int, Int64DataPoint) bool) {}
+	}
+
+	return b.allFromEntry(entry)
+}
+
+// AllTimestamps returns an iterator over all timestamps of the given metric ID.
+func (b Int64Blob) AllTimestamps(metricID uint64) iter.Seq[int64] {
+	return b.numeric.AllTimestamps(metricID)
+}
+
+// AllValues returns an iterator over all values of the given metric ID. It
+// returns an empty iterator if the metric ID is not found.
+func (b Int64Blob) AllValues(metricID uint64) iter.Seq[int64] {
+	entry, ok := b.numeric.index.GetByID(metricID)
+	if !ok {
+		return func(yield func(int64) bool) {}
+	}
+
+	return b.allValuesFromEntry(entry)
+}
+
+// AllValuesByName returns an iterator over all values of the given metric name.
+// It returns an empty iterator if the metric name is not found.
+func (b Int64Blob) AllValuesByName(metricName string) iter.Seq[int64] {
+	entry, ok := b.numeric.lookupMetricEntry(metricName)
+	if !ok {
+		return func(yield func(int64) bool) {}
+	}
+
+	return b.allValuesFromEntry(entry)
+}
+
+// AllTags returns an iterator over all tags of the given metric ID, or an empty
+// iterator if tags are not enabled or the metric ID is not found.
+func (b Int64Blob) AllTags(metricID uint64) iter.Seq[string] {
+	return b.numeric.AllTags(metricID)
+}
+
+// ValueAt returns the value at the specified index for the given metric ID.
+//
+// Values are delta-of-delta encoded, so this decodes every preceding value of
+// the metric (O(index)); iterate with All or AllValues to read many values.
+//
+// Returns (0, false) if the metric doesn't exist or the index is out of bounds.
+func (b Int64Blob) ValueAt(metricID uint64, index int) (int64, bool) {
+	entry, ok := b.numeric.index.GetByID(metricID)
+	if !ok {
+		return 0, false
+	}
+
+	return b.valueAtFromEntry(entry, index)
+}
+
+// ValueAtByName returns the value at the specified index for the given metric name.
+//
+// See ValueAt for details.
+func (b Int64Blob) ValueAtByName(metricName string, index int) (int64, bool) {
+	entry, ok := b.numeric.lookupMetricEntry(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return b.valueAtFromEntry(entry, index)
+}
+
+// TimestampAt returns the timestamp at the specified index for the given metric ID.
+//
+// See NumericBlob.TimestampAt for details.
+func (b Int64Blob) TimestampAt(metricID uint64, index int) (int64, bool) {
+	return b.numeric.TimestampAt(metricID, index)
+}
+
+// TagAt returns the tag at the specified index for the given metric ID.
+//
+// See NumericBlob.TagAt for details.
+func (b Int64Blob) TagAt(metricID uint64, index int) (string, bool) {
+	return b.numeric.TagAt(metricID, index)
+}
+
+// allFromEntry pairs the entry's decoded values with its timestamps and tags.
+func (b Int64Blob) allFromEntry(entry section.NumericIndexEntry) iter.Seq2[int, Int64DataPoint] {
+	return func(yield func(int, Int64DataPoint) bool) {
+		values := b.valuesFromEntry(entry)
+
+		var tags []string
+		if b.numeric.HasTag() {
+			tags = slices.Collect(b.numeric.allTagsFromEntry(entry))
+		}
+
+		i := 0
+		for ts := range b.numeric.allTimestampsFromEntry(entry) {
+			if i >= len(values) {
+				return
+			}
+
+			dp := Int64DataPoint{Ts: ts, Val: values[i]}
+			if i < len(tags) {
+				dp.Tag = tags[i]
+			}
+
+			if !yield(i, dp) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+// allValuesFromEntry returns an iterator over the entry's values.
+func (b Int64Blob) allValuesFromEntry(entry section.NumericIndexEntry) iter.Seq[int64] {
+	valBytes, ok := safeSlice(b.numeric.valPayload, entry.ValueOffset, entry.ValueLength)
+	if !ok {
+		return func(yield func(int64) bool) {}
+	}
+
+	return ienc.NewTimestampDeltaDecoder().All(valBytes, entry.Count)
+}
+
+// valuesFromEntry decodes all of the entry's values.
+func (b Int64Blob) valuesFromEntry(entry section.NumericIndexEntry) []int64 {
+	valBytes, ok := safeSlice(b.numeric.valPayload, entry.ValueOffset, entry.ValueLength)
+	if !ok {
+		return nil
+	}
+
+	values := make([]int64, entry.Count)
+
+	return values[:ienc.NewTimestampDeltaDecoder().DecodeAll(valBytes, entry.Count, values)]
+}
+
+// valueAtFromEntry returns the entry's value at index.
+func (b Int64Blob) valueAtFromEntry(entry section.NumericIndexEntry, index int) (int64, bool) {
+	if index < 0 || index >= entry.Count {
+		return 0, false
+	}
+
+	valBytes, ok := safeSlice(b.numeric.valPayload, entry.ValueOffset, entry.ValueLength)
+	if !ok {
+		return 0, false
+	}
+
+	return ienc.NewTimestampDeltaDecoder().At(valBytes, index, entry.Count)
+}
@@ -0,0 +1,135 @@
+package blob
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// Int64BlobSet represents an immutable collection of Int64Blob instances that
+// together contain time-series data for metrics across multiple time windows.
+//
+// The blobs are sorted by their start time, and iteration and global indexes
+// span all blobs in chronological order, as in NumericBlobSet. It is safe for
+// concurrent reads.
+type Int64BlobSet struct {
+	blobs []Int64Blob
+}
+
+// NewInt64BlobSet creates a new Int64BlobSet from the provided blobs.
+//
+// The blobs are sorted by their start time in ascending order. The provided
+// slice is copied, so later modifications do not affect the set.
+//
+// Parameters:
+//   - blobs: Slice of Int64Blob instances to include in the set
+//
+// Returns:
+//   - Int64BlobSet: An immutable blob set with blobs sorted by start time
+//   - error: ErrEmptyBlobSet if the blobs slice is empty
+func NewInt64BlobSet(blobs []Int64Blob) (Int64BlobSet, error) {
+	if len(blobs) == 0 {
+		return Int64BlobSet{}, errs.ErrEmptyBlobSet
+	}
+
+	sortedBlobs := slices.Clone(blobs)
+	slices.SortFunc(sortedBlobs, func(a, b Int64Blob) int {
+		return cmp.Compare(a.numeric.startTimeMicros, b.numeric.startTimeMicros)
+	})
+
+	return Int64BlobSet{blobs: sortedBlobs}, nil
+}
+
+// Len returns the number of blobs in the set.
+func (s Int64BlobSet) Len() int {
+	return len(s.blobs)
+}
+
+// Blobs returns a copy of the blobs in the set, sorted by start time.
+func (s Int64BlobSet) Blobs() []Int64Blob {
+	return slices.Clone(s.blobs)
+}
+
+// All returns a sequence of (index, Int64DataPoint) tuples for the given metric
+// ID across all blobs in the set, in chronological order.
+//
+// The index is 0-based and continuous across all blobs. Blobs that do not
+// contain the metric are skipped.
+func (s Int64BlobSet) All(metricID uint64) iter.Seq2[int, Int64DataPoint] {
+	return func(yield func(int, Int64DataPoint) bool) {
+		globalIndex := 0
+		for i := range s.blobs {
+			for _, dp := range s.blobs[i].All(metricID) {
+				if !yield(globalIndex, dp) {
+					return
+				}
+				globalIndex++
+			}
+		}
+	}
+}
+
+// AllByName returns a sequence of (index, Int64DataPoint) tuples for the given
+// metric name across all blobs in the set, in chronological order.
+func (s Int64BlobSet) AllByName(metricName string) iter.Seq2[int, Int64DataPoint] {
+	return func(yield func(int, Int64DataPoint) bool) {
+		globalIndex := 0
+		for i := range s.blobs {
+			for _, dp := range s.blobs[i].AllByName(metricName) {
+				if !yield(globalIndex, dp) {
+					return
+				}
+				globalIndex++
+			}
+		}
+	}
+}
+
+// AllValues returns a sequence of values for the given metric ID across all
+// blobs in the set, in chronological order.
+func (s Int64BlobSet) AllValues(metricID uint64) iter.Seq[int64] {
+	return func(yield func(int64) bool) {
+		for i := range s.blobs {
+			for val := range s.blobs[i].AllValues(metricID) {
+				if !yield(val) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ValueAt returns the value at the specified global index across all blobs for
+// the given metric. The index is 0-based and spans all blobs in chronological order.
+//
+// Returns (0, false) if the metric doesn't exist or the index is out of bounds.
+func (s Int64BlobSet) ValueAt(metricID uint64, index int) (int64, bool) {
+	if index < 0 {
+		return 0, false
+	}
+
+	currentOffset := 0
+	for i := range s.blobs {
+		blobLen := s.blobs[i].Len(metricID)
+		if index < currentOffset+blobLen {
+			return s.blobs[i].ValueAt(metricID, index-currentOffset)
+		}
+
+		currentOffset += blobLen
+	}
+
+	return 0, false
+}
+
+// MetricLen returns the total number of data points for the given metric ID
+// across all blobs, or 0 if the metric doesn't exist in any blob.
+func (s Int64BlobSet) MetricLen(metricID uint64) int {
+	totalLen := 0
+	for i := range s.blobs {
+		totalLen += s.blobs[i].Len(metricID)
+	}
+
+	return totalLen
+}
@@ -0,0 +1,194 @@
+package blob
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func encodeInt64TestBlob(t *testing.T, start time.Time, values map[string][]int64, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewInt64Encoder(start, opts...)
+	require.NoError(t, err)
+
+	base := start.UnixMicro()
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		vals := values[name]
+		require.NoError(t, encoder.StartMetricName(name, len(vals)))
+		for i, v := range vals {
+			require.NoError(t, encoder.AddDataPoint(base+int64(i)*1000000, v, name))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestInt64Blob_RoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	values := map[string][]int64{
+		"extremes": {math.MinInt64, math.MaxInt64, 0, -1, math.MinInt64, math.MaxInt64},
+		"beyond53": {1<<53 + 1, 1<<53 + 3, 1<<53 + 5, 1<<62 + 7},
+		"counter":  {100, 200, 300, 400, 500},
+	}
+
+	for _, layout := range []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "V2-BigEndian", opts: []NumericEncoderOption{WithTagsEnabled(true), WithBlobLayoutV2(), WithBigEndian()}},
+	} {
+		t.Run(layout.name, func(t *testing.T) {
+			data := encodeInt64TestBlob(t, start, values, layout.opts...)
+
+			decoder, err := NewInt64Decoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+			require.Equal(t, 3, blob.MetricCount())
+			require.True(t, blob.HasTag())
+
+			for name, want := range values {
+				require.True(t, blob.HasMetricName(name))
+				require.Equal(t, len(want), blob.LenByName(name))
+				require.Equal(t, want, slices.Collect(blob.AllValuesByName(name)))
+
+				for i, dp := range blob.AllByName(name) {
+					require.Equal(t, start.UnixMicro()+int64(i)*1000000, dp.Ts)
+					require.Equal(t, want[i], dp.Val)
+					require.Equal(t, name, dp.Tag)
+				}
+
+				last, ok := blob.ValueAtByName(name, len(want)-1)
+				require.True(t, ok)
+				require.Equal(t, want[len(want)-1], last)
+
+				_, ok = blob.ValueAtByName(name, len(want))
+				require.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestInt64Encoder_AddDataPoints(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewInt64Encoder(start)
+	require.NoError(t, err)
+
+	ts := []int64{1, 2, 3}
+	vals := []int64{math.MaxInt64 - 2, math.MaxInt64 - 1, math.MaxInt64}
+	require.NoError(t, encoder.StartMetricID(42, 3))
+	require.NoError(t, encoder.AddDataPoints(ts, vals, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewInt64Decoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	require.Equal(t, vals, slices.Collect(blob.AllValues(42)))
+	require.Equal(t, ts, slices.Collect(blob.AllTimestamps(42)))
+	require.Empty(t, slices.Collect(blob.AllValues(7)))
+
+	// The float64 view rounds to the nearest representable value.
+	numeric := blob.Numeric()
+	require.Equal(t, format.TypeDelta, numeric.ValueEncoding())
+	v, ok := numeric.ValueAt(42, 0)
+	require.True(t, ok)
+	require.Equal(t, float64(vals[0]), v)
+
+	// A plain numeric decoder reads the same blob as float64 values.
+	numericDecoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	floats, err := numericDecoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []float64{float64(vals[0]), float64(vals[1]), float64(vals[2])}, slices.Collect(floats.AllValues(42)))
+}
+
+func TestInt64Encoder_Errors(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	_, err := NewInt64Encoder(start, WithValuePrecision(2))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+
+	_, err = NewInt64Encoder(start, WithMetricReferences())
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+
+	// Value encoding options are overridden.
+	encoder, err := NewInt64Encoder(start, WithValueEncoding(format.TypeChimp))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.NoError(t, encoder.AddDataPoint(1, 1, ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	_, err = NewInt64Decoder(data)
+	require.NoError(t, err)
+
+	// Float blobs are rejected by the int64 decoder.
+	floatEncoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, floatEncoder.StartMetricID(1, 1))
+	require.NoError(t, floatEncoder.AddDataPoint(1, 1.5, ""))
+	require.NoError(t, floatEncoder.EndMetric())
+	data, err = floatEncoder.Finish()
+	require.NoError(t, err)
+	_, err = NewInt64Decoder(data)
+	require.ErrorIs(t, err, errs.ErrUnsupportedEncoding)
+}
+
+func TestInt64BlobSet(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	later := start.Add(time.Hour)
+
+	decode := func(data []byte) Int64Blob {
+		decoder, err := NewInt64Decoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	second := decode(encodeInt64TestBlob(t, later, map[string][]int64{"ids": {1 << 60, 1<<60 + 1}}))
+	first := decode(encodeInt64TestBlob(t, start, map[string][]int64{"ids": {-5, 1<<53 + 1}}))
+
+	set, err := NewInt64BlobSet([]Int64Blob{second, first})
+	require.NoError(t, err)
+	require.Equal(t, 2, set.Len())
+	require.Equal(t, start, set.Blobs()[0].StartTime())
+
+	want := []int64{-5, 1<<53 + 1, 1 << 60, 1<<60 + 1}
+	id := first.MetricIDs()[0]
+	require.Equal(t, want, slices.Collect(set.AllValues(id)))
+	require.Equal(t, 4, set.MetricLen(id))
+
+	for i, dp := range set.AllByName("ids") {
+		require.Equal(t, want[i], dp.Val)
+	}
+
+	v, ok := set.ValueAt(id, 2)
+	require.True(t, ok)
+	require.Equal(t, int64(1<<60), v)
+
+	_, ok = set.ValueAt(id, 4)
+	require.False(t, ok)
+
+	_, err = NewInt64BlobSet(nil)
+	require.ErrorIs(t, err, errs.ErrEmptyBlobSet)
+}
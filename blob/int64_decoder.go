@@ -0,0 +1,55 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// Int64Decoder decodes blobs produced by Int64Encoder into an Int64Blob.
+//
+// Note: The Int64Decoder is NOT thread-safe and NOT reusable, like NumericDecoder.
+type Int64Decoder struct {
+	numeric *NumericDecoder
+}
+
+// NewInt64Decoder creates a decoder for a blob produced by Int64Encoder.
+//
+// It accepts the NumericDecoder options; WithValueTransform only applies to
+// the float64 view returned by Int64Blob.Numeric.
+//
+// Parameters:
+//   - data: Encoded blob byte slice
+//   - opts: Optional NumericDecoder options
+//
+// Returns:
+//   - *Int64Decoder: New decoder instance ready for decoding
+//   - error: Header parsing error, or ErrUnsupportedEncoding if the blob does
+//     not hold int64 values
+func NewInt64Decoder(data []byte, opts ...NumericDecoderOption) (*Int64Decoder, error) {
+	numeric, err := NewNumericDecoder(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if enc := numeric.header.Flag.ValueEncoding(); enc != format.TypeDelta {
+		return nil, fmt.Errorf("%w: blob holds %s float values, not int64 values", errs.ErrUnsupportedEncoding, enc)
+	}
+
+	return &Int64Decoder{numeric: numeric}, nil
+}
+
+// Decode decodes the encoded data into an Int64Blob.
+//
+// Returns:
+//   - Int64Blob: Decoded blob
+//   - error: Any error of NumericDecoder.Decode
+func (d *Int64Decoder) Decode() (Int64Blob, error) {
+	numeric, err := d.numeric.Decode()
+	if err != nil {
+		return Int64Blob{}, err
+	}
+
+	return Int64Blob{numeric: numeric}, nil
+}
@@ -0,0 +1,181 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// Int64Encoder encodes int64 metric values, such as counters and IDs, without
+// the precision loss of float64 beyond 2^53.
+//
+// It produces a numeric blob whose value column uses zigzag delta-of-delta
+// varint encoding (format.TypeDelta in the header value encoding bits), so it
+// shares the layout, index, metric names, tags, and compression options of
+// NumericEncoder. Decode the result with NewInt64Decoder for exact values; a
+// NumericDecoder reads the same blob as float64 approximations.
+//
+// Note: The Int64Encoder is NOT thread-safe and NOT reusable, like NumericEncoder.
+type Int64Encoder struct {
+	numeric *NumericEncoder
+	scratch []float64 // Reused bit-cast buffer for AddDataPoints
+}
+
+// NewInt64Encoder creates a new Int64Encoder with the given start time.
+//
+// It accepts the NumericEncoder options for layout, endianness, timestamps,
+// tags, and compression. Value encoding options are overridden, and options
+// that rewrite float values (WithValuePrecision, WithQuantization,
+// WithMetricReferences, WithValueEncoder) are rejected.
+//
+// Parameters:
+//   - blobTS: Timestamp for the entire blob
+//   - opts: Optional NumericEncoder configuration
+//
+// Returns:
+//   - *Int64Encoder: A new encoder ready for use
+//   - error: ErrUnsupportedBlobFeature for float-only options, or any option error
+//
+// Example:
+//
+//	encoder, err := blob.NewInt64Encoder(time.Now(), blob.WithTagsEnabled(true))
+//	if err != nil {
+//	    return err
+//	}
+//	encoder.StartMetricName("requests.total", 2)
+//	encoder.AddDataPoint(ts1, 9007199254740993, "")
+//	encoder.AddDataPoint(ts2, 9007199254741001, "")
+//	encoder.EndMetric()
+//	data, err := encoder.Finish()
+func NewInt64Encoder(blobTS time.Time, opts ...NumericEncoderOption) (*Int64Encoder, error) {
+	opts = append(slices.Clone(opts), withInt64Values())
+
+	numeric, err := NewNumericEncoder(blobTS, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Int64Encoder{numeric: numeric}, nil
+}
+
+// withInt64Values selects the int64 value column. It must be applied last so
+// it overrides value encoding options and sees every float-only option.
+func withInt64Values() NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		if c.quantizing() || c.metricRefs || c.extEncoder != nil || c.sketches || c.valueSums {
+			return fmt.Errorf("%w: int64 values cannot be quantized, reference-encoded, extension-encoded, sketched or summed",
+				errs.ErrUnsupportedBlobFeature)
+		}
+
+		c.header.Flag.SetValueEncoding(format.TypeDelta)
+
+		return nil
+	})
+}
+
+// StartMetricID starts encoding a new metric identified by metricID.
+//
+// See NumericEncoder.StartMetricID for details.
+func (e *Int64Encoder) StartMetricID(metricID uint64, numOfDataPoints int) error {
+	return e.numeric.StartMetricID(metricID, numOfDataPoints)
+}
+
+// StartMetricName starts encoding a new metric identified by metricName.
+//
+// See NumericEncoder.StartMetricName for details.
+func (e *Int64Encoder) StartMetricName(metricName string, numOfDataPoints int) error {
+	return e.numeric.StartMetricName(metricName, numOfDataPoints)
+}
+
+// AddDataPoint adds a single data point to the current metric.
+//
+// Parameters:
+//   - timestamp: Caller-defined timestamp value (e.g. microseconds since Unix epoch)
+//   - value: Int64 metric value, stored exactly
+//   - tag: Optional tag string (ignored if tag support is not enabled)
+//
+// Returns:
+//   - error: ErrTooManyDataPoints if adding would exceed the claimed data point count
+func (e *Int64Encoder) AddDataPoint(timestamp int64, value int64, tag string) error {
+	return e.numeric.AddDataPoint(timestamp, math.Float64frombits(uint64(value)), tag) //nolint: gosec
+}
+
+// AddDataPoints adds multiple data points to the current metric.
+//
+// See NumericEncoder.AddDataPoints for the length rules of the slices.
+//
+// Parameters:
+//   - timestamps: Slice of caller-defined timestamp values
+//   - values: Slice of int64 metric values (must have the same length as timestamps)
+//   - tags: Optional slice of tag strings
+//
+// Returns:
+//   - error: Length mismatch error or ErrTooManyDataPoints
+func (e *Int64Encoder) AddDataPoints(timestamps []int64, values []int64, tags []string) error {
+	e.scratch = e.scratch[:0]
+	for _, v := range values {
+		e.scratch = append(e.scratch, math.Float64frombits(uint64(v))) //nolint: gosec
+	}
+
+	return e.numeric.AddDataPoints(timestamps, e.scratch, tags)
+}
+
+// EndMetric ends the current metric.
+//
+// See NumericEncoder.EndMetric for details.
+func (e *Int64Encoder) EndMetric() error {
+	return e.numeric.EndMetric()
+}
+
+// AbortMetric discards the current metric and its partially written data points.
+//
+// See NumericEncoder.AbortMetric for details.
+func (e *Int64Encoder) AbortMetric() error {
+	return e.numeric.AbortMetric()
+}
+
+// RemoveMetric removes an already ended metric from the blob.
+//
+// See NumericEncoder.RemoveMetric for details.
+func (e *Int64Encoder) RemoveMetric(metricID uint64) error {
+	return e.numeric.RemoveMetric(metricID)
+}
+
+// Stats returns the progress and remaining capacity of the encoder.
+//
+// See NumericEncoder.Stats for details.
+func (e *Int64Encoder) Stats() NumericEncoderStats {
+	return e.numeric.Stats()
+}
+
+// Finish finalizes the blob and returns the encoded bytes.
+//
+// See NumericEncoder.Finish for details.
+func (e *Int64Encoder) Finish() ([]byte, error) {
+	return e.numeric.Finish()
+}
+
+// int64ColumnEncoder adapts the delta-of-delta integer codec to the float64
+// value column of NumericEncoder. Int64Encoder passes each value as its int64
+// bit pattern carried in a float64, which is converted back losslessly here.
+type int64ColumnEncoder struct {
+	*ienc.TimestampDeltaEncoder
+}
+
+// Write encodes the int64 bit pattern carried by v.
+func (e int64ColumnEncoder) Write(v float64) {
+	e.TimestampDeltaEncoder.Write(int64(math.Float64bits(v))) //nolint: gosec
+}
+
+// WriteSlice encodes the int64 bit patterns carried by values.
+func (e int64ColumnEncoder) WriteSlice(values []float64) {
+	for _, v := range values {
+		e.TimestampDeltaEncoder.Write(int64(math.Float64bits(v))) //nolint: gosec
+	}
+}
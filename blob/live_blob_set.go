@@ -0,0 +1,150 @@
+package blob
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LiveBlobSet is a BlobSet that can be mutated while other goroutines read it.
+//
+// Mutations are copy-on-write: Append, Evict and Update build a new BlobSet and
+// publish it atomically, never modifying the blob slices of a published set.
+// Readers call Snapshot to obtain the current set and may iterate it for as long
+// as they like; a snapshot is unaffected by mutations published after it was
+// taken. Mutations are serialized, so concurrent writers never lose each other's
+// changes.
+//
+// Snapshots share their blob slices with later snapshots. Callers must not
+// modify the slices returned by NumericBlobs, TextBlobs or EventBlobs.
+//
+// Example:
+//
+//	live := blob.NewLiveBlobSet(blobSet)
+//
+//	// Ingestion goroutine
+//	live.Append([]blob.NumericBlob{newBlob}, nil)
+//	live.Evict(time.Now().Add(-24 * time.Hour))
+//
+//	// Query goroutines
+//	snapshot := live.Snapshot()
+//	for _, dp := range snapshot.AllNumerics(metricID) {
+//	    // ...
+//	}
+type LiveBlobSet struct {
+	mu  sync.Mutex // Serializes mutations
+	cur atomic.Pointer[BlobSet]
+}
+
+// NewLiveBlobSet creates a LiveBlobSet holding set.
+//
+// Parameters:
+//   - set: Initial blob set
+//
+// Returns:
+//   - *LiveBlobSet: Live set whose first snapshot is set
+func NewLiveBlobSet(set BlobSet) *LiveBlobSet {
+	live := &LiveBlobSet{}
+	live.cur.Store(&set)
+
+	return live
+}
+
+// Snapshot returns the current blob set. The snapshot is immutable: mutations
+// of the live set published afterwards do not affect it.
+//
+// Returns:
+//   - BlobSet: The current blob set
+func (l *LiveBlobSet) Snapshot() BlobSet {
+	return *l.cur.Load()
+}
+
+// Append publishes a new set that also holds the given numeric and text blobs.
+//
+// Parameters:
+//   - numericBlobs: Numeric blobs to add
+//   - textBlobs: Text blobs to add
+//
+// Returns:
+//   - BlobSet: The published set
+func (l *LiveBlobSet) Append(numericBlobs []NumericBlob, textBlobs []TextBlob) BlobSet {
+	return l.Update(func(set BlobSet) BlobSet {
+		next := NewBlobSet(
+			slices.Concat(set.numericBlobs, numericBlobs),
+			slices.Concat(set.textBlobs, textBlobs),
+		)
+
+		return next.WithEventBlobs(set.eventBlobs...).WithReadHooks(set.hooks)
+	})
+}
+
+// AppendEvents publishes a new set that also holds the given event blobs.
+//
+// Parameters:
+//   - eventBlobs: Event blobs to add
+//
+// Returns:
+//   - BlobSet: The published set
+func (l *LiveBlobSet) AppendEvents(eventBlobs ...EventBlob) BlobSet {
+	return l.Update(func(set BlobSet) BlobSet {
+		return set.WithEventBlobs(slices.Concat(set.eventBlobs, eventBlobs)...)
+	})
+}
+
+// Evict publishes a new set without the blobs of any kind that start before
+// cutoff.
+//
+// Parameters:
+//   - cutoff: Blobs with a start time before cutoff are removed
+//
+// Returns:
+//   - int: Number of evicted blobs
+func (l *LiveBlobSet) Evict(cutoff time.Time) int {
+	evicted := 0
+	l.Update(func(set BlobSet) BlobSet {
+		before := func(start time.Time) bool {
+			if start.Before(cutoff) {
+				evicted++
+				return true
+			}
+
+			return false
+		}
+
+		numericBlobs := slices.DeleteFunc(slices.Clone(set.numericBlobs), func(b NumericBlob) bool { return before(b.StartTime()) })
+		textBlobs := slices.DeleteFunc(slices.Clone(set.textBlobs), func(b TextBlob) bool { return before(b.StartTime()) })
+		eventBlobs := slices.DeleteFunc(slices.Clone(set.eventBlobs), func(b EventBlob) bool { return before(b.StartTime()) })
+
+		return NewBlobSet(numericBlobs, textBlobs).WithEventBlobs(eventBlobs...).WithReadHooks(set.hooks)
+	})
+
+	return evicted
+}
+
+// Update publishes the set returned by fn, which receives the current set.
+//
+// fn runs while other mutations are blocked, so it must not call mutating
+// methods of l. It must build its result with the BlobSet constructors rather
+// than modifying the slices of the set it receives.
+//
+// Parameters:
+//   - fn: Function deriving the next set from the current one
+//
+// Returns:
+//   - BlobSet: The published set
+//
+// Example:
+//
+//	live.Update(func(set blob.BlobSet) blob.BlobSet {
+//	    return blob.NewBlobSet(compacted, set.TextBlobs())
+//	})
+func (l *LiveBlobSet) Update(fn func(BlobSet) BlobSet) BlobSet {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := fn(*l.cur.Load())
+	l.cur.Store(&next)
+
+	return next
+}
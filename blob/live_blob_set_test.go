@@ -0,0 +1,86 @@
+package blob
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveBlobSet(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blobAt := func(hour int) NumericBlob {
+		return createExplainNumericBlob(t, start.Add(time.Duration(hour)*time.Hour), 4)
+	}
+
+	live := NewLiveBlobSet(NewBlobSet([]NumericBlob{blobAt(1)}, nil))
+	before := live.Snapshot()
+
+	set := live.Append([]NumericBlob{blobAt(2), blobAt(0)}, nil)
+	require.Len(t, set.NumericBlobs(), 3)
+	require.Equal(t, start, set.NumericBlobs()[0].StartTime())
+	require.Equal(t, 12, live.Snapshot().MetricLen(1))
+
+	// Earlier snapshots are unaffected.
+	require.Len(t, before.NumericBlobs(), 1)
+	require.Equal(t, 4, before.MetricLen(1))
+
+	appended := live.Snapshot()
+	require.Equal(t, 2, live.Evict(start.Add(90*time.Minute)))
+	require.Len(t, live.Snapshot().NumericBlobs(), 1)
+	require.Equal(t, start.Add(2*time.Hour), live.Snapshot().NumericBlobs()[0].StartTime())
+	require.Len(t, appended.NumericBlobs(), 3)
+	require.Zero(t, live.Evict(start))
+
+	live.Update(func(BlobSet) BlobSet { return BlobSet{} })
+	require.Empty(t, live.Snapshot().NumericBlobs())
+}
+
+func TestLiveBlobSet_ConcurrentSnapshots(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blobs := make([]NumericBlob, 32)
+	for i := range blobs {
+		blobs[i] = createExplainNumericBlob(t, start.Add(time.Duration(i)*time.Hour), 4)
+	}
+
+	live := NewLiveBlobSet(NewBlobSet(blobs[:1], nil))
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				snapshot := live.Snapshot()
+				want := snapshot.MetricLen(1)
+
+				// Iterating a snapshot sees the same points however the live
+				// set changes meanwhile.
+				got := 0
+				for range snapshot.AllNumerics(1) {
+					got++
+				}
+				if got != want {
+					t.Errorf("snapshot yielded %d points, want %d", got, want)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i < len(blobs); i++ {
+			live.Append(blobs[i:i+1], nil)
+			if i%4 == 0 {
+				live.Evict(start.Add(time.Duration(i-2) * time.Hour))
+			}
+		}
+	}()
+
+	wg.Wait()
+	// The last eviction at hour 28 kept the blobs of hours 26 through 31.
+	require.Len(t, live.Snapshot().NumericBlobs(), 6)
+}
@@ -0,0 +1,222 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// LiveWriterConfig holds LiveWriter decoder and retention settings.
+type LiveWriterConfig struct {
+	numericOpts []NumericDecoderOption
+	textOpts    []TextDecoderOption
+	retention   time.Duration
+}
+
+// LiveWriterOption represents a functional option for configuring a LiveWriter.
+type LiveWriterOption = options.Option[*LiveWriterConfig]
+
+// WithLiveNumericDecoderOptions sets the options used to decode numeric blobs
+// before they are published.
+func WithLiveNumericDecoderOptions(opts ...NumericDecoderOption) LiveWriterOption {
+	return options.NoError(func(cfg *LiveWriterConfig) {
+		cfg.numericOpts = append(cfg.numericOpts, opts...)
+	})
+}
+
+// WithLiveTextDecoderOptions sets the options used to decode text blobs before
+// they are published.
+func WithLiveTextDecoderOptions(opts ...TextDecoderOption) LiveWriterOption {
+	return options.NoError(func(cfg *LiveWriterConfig) {
+		cfg.textOpts = append(cfg.textOpts, opts...)
+	})
+}
+
+// WithLiveRetention keeps a rolling window of blobs: each publish also evicts,
+// in the same atomic update, the blobs of any kind that start more than d
+// before the start of the newest blob in the set. Default is no eviction.
+func WithLiveRetention(d time.Duration) LiveWriterOption {
+	return options.New(func(cfg *LiveWriterConfig) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid live retention: %v, must be positive", d)
+		}
+		cfg.retention = d
+
+		return nil
+	})
+}
+
+// LiveWriter publishes freshly encoded blobs to a LiveBlobSet, giving
+// read-your-writes consistency: once FinishNumeric, FinishText or a Publish
+// method returns, every later Snapshot of the live set holds the new blob.
+//
+// Blobs are decoded before the set is touched, so a blob that fails to decode
+// is never published and readers never observe a partial update.
+//
+// A LiveWriter is safe for concurrent use; publishes are serialized by the
+// live set.
+//
+// Example:
+//
+//	live := blob.NewLiveBlobSet(blob.BlobSet{})
+//	writer, _ := blob.NewLiveWriter(live, blob.WithLiveRetention(24*time.Hour))
+//
+//	// Ingestion: buffered points become queryable as each blob is cut.
+//	batcher, _ := blob.NewBatcher(writer.PublishNumeric)
+//
+//	// Serving
+//	snapshot := live.Snapshot()
+type LiveWriter struct {
+	live *LiveBlobSet
+	cfg  LiveWriterConfig
+}
+
+// NewLiveWriter creates a LiveWriter publishing to live.
+//
+// Parameters:
+//   - live: Live set receiving the published blobs
+//   - opts: Decoder and retention options (WithLiveNumericDecoderOptions,
+//     WithLiveTextDecoderOptions, WithLiveRetention)
+//
+// Returns:
+//   - *LiveWriter: New writer
+//   - error: Invalid option error
+func NewLiveWriter(live *LiveBlobSet, opts ...LiveWriterOption) (*LiveWriter, error) {
+	if live == nil {
+		return nil, errors.New("live blob set must not be nil")
+	}
+
+	w := &LiveWriter{live: live}
+	if err := options.Apply(&w.cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// FinishNumeric finishes encoder and publishes the produced blob.
+//
+// Parameters:
+//   - encoder: Encoder holding the blob's metrics
+//
+// Returns:
+//   - []byte: The encoded blob, e.g. for persisting
+//   - error: Encoding or decoding error; nothing is published on error
+func (w *LiveWriter) FinishNumeric(encoder *NumericEncoder) ([]byte, error) {
+	data, err := encoder.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	return data, w.PublishNumeric(data)
+}
+
+// FinishText finishes encoder and publishes the produced blob.
+//
+// Parameters:
+//   - encoder: Encoder holding the blob's metrics
+//
+// Returns:
+//   - []byte: The encoded blob, e.g. for persisting
+//   - error: Encoding or decoding error; nothing is published on error
+func (w *LiveWriter) FinishText(encoder *TextEncoder) ([]byte, error) {
+	data, err := encoder.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	return data, w.PublishText(data)
+}
+
+// PublishNumeric decodes an encoded numeric blob and publishes it. Its
+// signature matches the NewBatcher callback.
+//
+// The decoded blob references data, which must not be modified afterwards.
+//
+// Parameters:
+//   - data: Encoded numeric blob
+//
+// Returns:
+//   - error: Decoding error; nothing is published on error
+func (w *LiveWriter) PublishNumeric(data []byte) error {
+	decoder, err := NewNumericDecoder(data, w.cfg.numericOpts...)
+	if err != nil {
+		return err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return err
+	}
+
+	w.publish([]NumericBlob{b}, nil)
+
+	return nil
+}
+
+// PublishText decodes an encoded text blob and publishes it.
+//
+// The decoded blob references data, which must not be modified afterwards.
+//
+// Parameters:
+//   - data: Encoded text blob
+//
+// Returns:
+//   - error: Decoding error; nothing is published on error
+func (w *LiveWriter) PublishText(data []byte) error {
+	decoder, err := NewTextDecoder(data, w.cfg.textOpts...)
+	if err != nil {
+		return err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return err
+	}
+
+	w.publish(nil, []TextBlob{b})
+
+	return nil
+}
+
+// publish appends the blobs to the live set and applies the retention window,
+// in one atomic update.
+func (w *LiveWriter) publish(numericBlobs []NumericBlob, textBlobs []TextBlob) {
+	w.live.Update(func(set BlobSet) BlobSet {
+		numerics := slices.Concat(set.numericBlobs, numericBlobs)
+		texts := slices.Concat(set.textBlobs, textBlobs)
+		events := set.eventBlobs
+
+		if w.cfg.retention > 0 {
+			newest := time.Time{}
+			for _, b := range numerics {
+				newest = latest(newest, b.StartTime())
+			}
+			for _, b := range texts {
+				newest = latest(newest, b.StartTime())
+			}
+			for _, b := range events {
+				newest = latest(newest, b.StartTime())
+			}
+
+			cutoff := newest.Add(-w.cfg.retention)
+			numerics = slices.DeleteFunc(numerics, func(b NumericBlob) bool { return b.StartTime().Before(cutoff) })
+			texts = slices.DeleteFunc(texts, func(b TextBlob) bool { return b.StartTime().Before(cutoff) })
+			events = slices.DeleteFunc(slices.Clone(events), func(b EventBlob) bool { return b.StartTime().Before(cutoff) })
+		}
+
+		return NewBlobSet(numerics, texts).WithEventBlobs(events...).WithReadHooks(set.hooks)
+	})
+}
+
+// latest returns the later of a and b.
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+
+	return a
+}
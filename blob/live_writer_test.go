@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newLiveWriterEncoder returns a numeric encoder holding one point of metric 1 at start.
+func newLiveWriterEncoder(t *testing.T, start time.Time, value float64) *NumericEncoder {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), value, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	return encoder
+}
+
+func TestLiveWriter_ReadYourWrites(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	live := NewLiveBlobSet(BlobSet{})
+	writer, err := NewLiveWriter(live)
+	require.NoError(t, err)
+
+	data, err := writer.FinishNumeric(newLiveWriterEncoder(t, start, 1.5))
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+
+	v, ok := live.Snapshot().NumericValueAt(1, 0)
+	require.True(t, ok)
+	require.Equal(t, 1.5, v)
+
+	textEncoder, err := NewTextEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricID(2, 1))
+	require.NoError(t, textEncoder.AddDataPoint(start.UnixMicro(), "up", ""))
+	require.NoError(t, textEncoder.EndMetric())
+	_, err = writer.FinishText(textEncoder)
+	require.NoError(t, err)
+
+	s, ok := live.Snapshot().TextValueAt(2, 0)
+	require.True(t, ok)
+	require.Equal(t, "up", s)
+}
+
+func TestLiveWriter_Retention(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	live := NewLiveBlobSet(BlobSet{})
+	writer, err := NewLiveWriter(live, WithLiveRetention(90*time.Minute))
+	require.NoError(t, err)
+
+	for hour := range 4 {
+		_, err := writer.FinishNumeric(newLiveWriterEncoder(t, start.Add(time.Duration(hour)*time.Hour), float64(hour)))
+		require.NoError(t, err)
+	}
+
+	// Only the blobs within 90 minutes of the newest one remain.
+	blobs := live.Snapshot().NumericBlobs()
+	require.Len(t, blobs, 2)
+	require.Equal(t, start.Add(2*time.Hour), blobs[0].StartTime())
+	require.Equal(t, start.Add(3*time.Hour), blobs[1].StartTime())
+}
+
+func TestLiveWriter_BatcherCallback(t *testing.T) {
+	live := NewLiveBlobSet(BlobSet{})
+	writer, err := NewLiveWriter(live)
+	require.NoError(t, err)
+
+	batcher, err := NewBatcher(writer.PublishNumeric)
+	require.NoError(t, err)
+	require.NoError(t, batcher.AddID(7, time.Unix(1700000000, 0).UnixMicro(), 3, ""))
+	require.Zero(t, live.Snapshot().MetricLen(7))
+
+	require.NoError(t, batcher.Flush())
+	require.Equal(t, 1, live.Snapshot().MetricLen(7))
+}
+
+func TestLiveWriter_InvalidBlob(t *testing.T) {
+	live := NewLiveBlobSet(BlobSet{})
+	writer, err := NewLiveWriter(live)
+	require.NoError(t, err)
+
+	require.Error(t, writer.PublishNumeric([]byte{1, 2, 3}))
+	require.Error(t, writer.PublishText([]byte{1, 2, 3}))
+	require.Empty(t, live.Snapshot().NumericBlobs())
+	require.Empty(t, live.Snapshot().TextBlobs())
+
+	_, err = NewLiveWriter(nil)
+	require.Error(t, err)
+	_, err = NewLiveWriter(live, WithLiveRetention(0))
+	require.Error(t, err)
+}
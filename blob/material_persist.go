@@ -0,0 +1,357 @@
+package blob
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+const (
+	// materialHeaderSize is the size of the header of a saved materialized set.
+	materialHeaderSize = 16
+
+	// materialMagic identifies a saved materialized numeric blob set. It lies
+	// outside the 0xEAxx-0xECxx range of blob magic numbers, so sniffing a saved
+	// set never mistakes it for a blob.
+	materialMagic uint16 = 0x4D60
+
+	// materialVersion is the version of the saved layout.
+	materialVersion uint8 = 1
+)
+
+const (
+	// materialFlagTags marks a saved set carrying a tag column per metric.
+	materialFlagTags uint8 = 1 << iota
+	// materialFlagTimeIndex marks a set materialized with WithTimeIndex.
+	materialFlagTimeIndex
+)
+
+// Save writes the materialized set to w in a compact columnar layout, so a warmed
+// cache can be reloaded with LoadMaterialized after a restart without decoding
+// and materializing the blobs again.
+//
+// The layout is a 16-byte header, the metrics sorted by ID, the metric names and
+// an xxHash64 checksum of everything before it. All fields are little-endian:
+//
+//	Header:  Magic(2B) + Version(1B) + Flags(1B) + MetricCount(4B) + NameCount(4B) + Reserved(4B)
+//	Metric:  MetricID(8B) + TimestampCount(uvarint) + PointCount(uvarint)
+//	         + Timestamps (first timestamp, then deltas, as zigzag varints)
+//	         + Values (PointCount × 8B float64)
+//	         + Tags (PointCount × (Length(uvarint) + Bytes), if the tags flag is set)
+//	Name:    Length(uvarint) + Bytes + MetricID(8B)
+//	Trailer: Checksum(8B)
+//
+// Saving the same set always produces the same bytes. The time index of a set
+// materialized with WithTimeIndex is not stored but rebuilt on load.
+//
+// Parameters:
+//   - w: Destination of the serialized set
+//
+// Returns:
+//   - error: Write error
+//
+// Example:
+//
+//	material := blobSet.Materialize(blob.WithTimeIndex())
+//	f, _ := os.Create("material.cache")
+//	defer f.Close()
+//	if err := material.Save(f); err != nil {
+//	    return err
+//	}
+func (m MaterializedNumericBlobSet) Save(w io.Writer) error {
+	metricIDs := slices.Sorted(maps.Keys(m.data))
+	names := slices.Sorted(maps.Keys(m.names))
+
+	var flags uint8
+	for _, metricSet := range m.data {
+		if metricSet.tags != nil {
+			flags |= materialFlagTags
+		}
+		if metricSet.timeIndex {
+			flags |= materialFlagTimeIndex
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	digest := xxhash.New()
+	out := io.MultiWriter(bw, digest)
+
+	buf := make([]byte, 0, materialHeaderSize)
+	buf = binary.LittleEndian.AppendUint16(buf, materialMagic)
+	buf = append(buf, materialVersion, flags)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(metricIDs))) //nolint: gosec
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(names)))     //nolint: gosec
+	buf = binary.LittleEndian.AppendUint32(buf, 0)
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+
+	for _, metricID := range metricIDs {
+		buf = appendMaterializedMetric(buf[:0], metricID, m.data[metricID], flags&materialFlagTags != 0)
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+
+	buf = buf[:0]
+	for _, name := range names {
+		buf = binary.AppendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+		buf = binary.LittleEndian.AppendUint64(buf, m.names[name])
+	}
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+
+	if _, err := bw.Write(binary.LittleEndian.AppendUint64(buf[:0], digest.Sum64())); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// LoadMaterialized reads a materialized set written by
+// MaterializedNumericBlobSet.Save.
+//
+// The whole input is read and its checksum verified before any data is parsed,
+// so a truncated or corrupted cache file is rejected rather than partially
+// loaded.
+//
+// Parameters:
+//   - r: Source of the serialized set
+//
+// Returns:
+//   - MaterializedNumericBlobSet: The restored set, equivalent to the saved one
+//   - error: Read error, or ErrInvalidMaterializedData if the data is malformed
+//
+// Example:
+//
+//	f, err := os.Open("material.cache")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	material, err := blob.LoadMaterialized(f)
+func LoadMaterialized(r io.Reader) (MaterializedNumericBlobSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return MaterializedNumericBlobSet{}, err
+	}
+
+	if len(data) < materialHeaderSize+8 {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: data truncated", errs.ErrInvalidMaterializedData)
+	}
+
+	body := data[:len(data)-8]
+	if xxhash.Sum64(body) != binary.LittleEndian.Uint64(data[len(body):]) {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: checksum mismatch", errs.ErrInvalidMaterializedData)
+	}
+
+	if binary.LittleEndian.Uint16(body) != materialMagic {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: invalid magic number", errs.ErrInvalidMaterializedData)
+	}
+	if body[2] != materialVersion {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: unsupported version %d", errs.ErrInvalidMaterializedData, body[2])
+	}
+
+	flags := body[3]
+	metricCount := int(binary.LittleEndian.Uint32(body[4:]))
+	nameCount := int(binary.LittleEndian.Uint32(body[8:]))
+	p := materialParser{data: body, off: materialHeaderSize}
+
+	// Every metric takes at least 10 bytes and every name at least 9 bytes.
+	if metricCount > len(body)/10 || nameCount > len(body)/9 {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: counts exceed data size", errs.ErrInvalidMaterializedData)
+	}
+
+	material := MaterializedNumericBlobSet{
+		data:  make(map[uint64]materializedNumericMetricSet, metricCount),
+		names: make(map[string]uint64, nameCount),
+	}
+
+	for range metricCount {
+		metricID, metricSet, err := p.metric(flags&materialFlagTags != 0)
+		if err != nil {
+			return MaterializedNumericBlobSet{}, err
+		}
+		if _, dup := material.data[metricID]; dup {
+			return MaterializedNumericBlobSet{}, fmt.Errorf("%w: duplicate metric ID 0x%016x", errs.ErrInvalidMaterializedData, metricID)
+		}
+
+		if flags&materialFlagTimeIndex != 0 {
+			metricSet.timeOrder = buildTimeOrder(metricSet.timestamps)
+			metricSet.timeIndex = true
+		}
+		material.data[metricID] = metricSet
+	}
+
+	for range nameCount {
+		name, err := p.bytes()
+		if err != nil {
+			return MaterializedNumericBlobSet{}, err
+		}
+		metricID, err := p.uint64()
+		if err != nil {
+			return MaterializedNumericBlobSet{}, err
+		}
+		material.names[string(name)] = metricID
+	}
+
+	if p.off != len(body) {
+		return MaterializedNumericBlobSet{}, fmt.Errorf("%w: %d trailing bytes", errs.ErrInvalidMaterializedData, len(body)-p.off)
+	}
+
+	return material, nil
+}
+
+// appendMaterializedMetric appends the serialized columns of a metric to dst.
+func appendMaterializedMetric(dst []byte, metricID uint64, metricSet materializedNumericMetricSet, withTags bool) []byte {
+	dst = binary.LittleEndian.AppendUint64(dst, metricID)
+	dst = binary.AppendUvarint(dst, uint64(len(metricSet.timestamps)))
+	dst = binary.AppendUvarint(dst, uint64(len(metricSet.values)))
+
+	prev := int64(0)
+	for _, ts := range metricSet.timestamps {
+		dst = binary.AppendVarint(dst, ts-prev)
+		prev = ts
+	}
+
+	for _, v := range metricSet.values {
+		dst = binary.LittleEndian.AppendUint64(dst, math.Float64bits(v))
+	}
+
+	if withTags {
+		for i := range metricSet.values {
+			tag := ""
+			if i < len(metricSet.tags) {
+				tag = metricSet.tags[i]
+			}
+			dst = binary.AppendUvarint(dst, uint64(len(tag)))
+			dst = append(dst, tag...)
+		}
+	}
+
+	return dst
+}
+
+// materialParser reads the fields of a saved materialized set.
+type materialParser struct {
+	data []byte
+	off  int
+}
+
+// metric parses the columns of one metric.
+func (p *materialParser) metric(withTags bool) (uint64, materializedNumericMetricSet, error) {
+	metricID, err := p.uint64()
+	if err != nil {
+		return 0, materializedNumericMetricSet{}, err
+	}
+
+	tsCount, err := p.uvarint()
+	if err != nil {
+		return 0, materializedNumericMetricSet{}, err
+	}
+	count, err := p.uvarint()
+	if err != nil {
+		return 0, materializedNumericMetricSet{}, err
+	}
+	// Each timestamp takes at least one byte and each value eight bytes.
+	remaining := uint64(len(p.data) - p.off)
+	if tsCount > remaining || count > remaining/8 {
+		return 0, materializedNumericMetricSet{}, fmt.Errorf("%w: point count of metric ID 0x%016x exceeds data size", errs.ErrInvalidMaterializedData, metricID)
+	}
+
+	n := int(count) //nolint: gosec // bounded by the data size above
+	metricSet := materializedNumericMetricSet{
+		timestamps: make([]int64, tsCount),
+		values:     make([]float64, n),
+	}
+
+	prev := int64(0)
+	for i := range metricSet.timestamps {
+		delta, err := p.varint()
+		if err != nil {
+			return 0, materializedNumericMetricSet{}, err
+		}
+		prev += delta
+		metricSet.timestamps[i] = prev
+	}
+
+	if len(p.data)-p.off < 8*n {
+		return 0, materializedNumericMetricSet{}, fmt.Errorf("%w: values of metric ID 0x%016x truncated", errs.ErrInvalidMaterializedData, metricID)
+	}
+	for i := range metricSet.values {
+		metricSet.values[i] = math.Float64frombits(binary.LittleEndian.Uint64(p.data[p.off:]))
+		p.off += 8
+	}
+
+	if withTags {
+		metricSet.tags = make([]string, n)
+		for i := range metricSet.tags {
+			tag, err := p.bytes()
+			if err != nil {
+				return 0, materializedNumericMetricSet{}, err
+			}
+			metricSet.tags[i] = string(tag)
+		}
+	}
+
+	return metricID, metricSet, nil
+}
+
+// uint64 reads a fixed 8-byte field.
+func (p *materialParser) uint64() (uint64, error) {
+	if len(p.data)-p.off < 8 {
+		return 0, fmt.Errorf("%w: data truncated", errs.ErrInvalidMaterializedData)
+	}
+	v := binary.LittleEndian.Uint64(p.data[p.off:])
+	p.off += 8
+
+	return v, nil
+}
+
+// uvarint reads an unsigned varint.
+func (p *materialParser) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(p.data[p.off:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: invalid varint at offset %d", errs.ErrInvalidMaterializedData, p.off)
+	}
+	p.off += n
+
+	return v, nil
+}
+
+// varint reads a zigzag-encoded signed varint.
+func (p *materialParser) varint() (int64, error) {
+	v, n := binary.Varint(p.data[p.off:])
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: invalid varint at offset %d", errs.ErrInvalidMaterializedData, p.off)
+	}
+	p.off += n
+
+	return v, nil
+}
+
+// bytes reads a length-prefixed byte string, referencing the parsed data.
+func (p *materialParser) bytes() ([]byte, error) {
+	length, err := p.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(p.data)-p.off) {
+		return nil, fmt.Errorf("%w: string at offset %d truncated", errs.ErrInvalidMaterializedData, p.off)
+	}
+
+	b := p.data[p.off : p.off+int(length)] //nolint: gosec // bounded by the data size above
+	p.off += int(length)                   //nolint: gosec // bounded by the data size above
+
+	return b, nil
+}
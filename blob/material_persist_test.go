@@ -0,0 +1,140 @@
+package blob
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// saveAndLoadMaterialized round-trips a materialized set through Save and LoadMaterialized.
+func saveAndLoadMaterialized(t *testing.T, material MaterializedNumericBlobSet) (MaterializedNumericBlobSet, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, material.Save(&buf))
+	data := bytes.Clone(buf.Bytes())
+
+	loaded, err := LoadMaterialized(&buf)
+	require.NoError(t, err)
+
+	return loaded, data
+}
+
+func TestMaterializedNumericBlobSet_SaveLoad(t *testing.T) {
+	metrics := map[uint64]int{1001: 10, 1002: 25, 1003: 1}
+
+	tests := []struct {
+		name     string
+		blobSet  NumericBlobSet
+		material func(NumericBlobSet) MaterializedNumericBlobSet
+	}{
+		{
+			name:     "Empty",
+			blobSet:  NumericBlobSet{},
+			material: func(s NumericBlobSet) MaterializedNumericBlobSet { return s.Materialize() },
+		},
+		{
+			name:     "NoTags",
+			blobSet:  createTestBlobSetForMaterialization(t, 3, format.TypeDelta, format.TypeGorilla, false, metrics),
+			material: func(s NumericBlobSet) MaterializedNumericBlobSet { return s.Materialize() },
+		},
+		{
+			name:     "WithTags",
+			blobSet:  createTestBlobSetForMaterialization(t, 3, format.TypeRaw, format.TypeRaw, true, metrics),
+			material: func(s NumericBlobSet) MaterializedNumericBlobSet { return s.Materialize() },
+		},
+		{
+			name:     "WithTimeIndex",
+			blobSet:  createOverlappingNumericBlobSet(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+			material: func(s NumericBlobSet) MaterializedNumericBlobSet { return s.Materialize(WithTimeIndex()) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.material(tt.blobSet)
+			got, data := saveAndLoadMaterialized(t, want)
+			require.Equal(t, want, got)
+
+			// Saving is deterministic, so the reloaded set saves to the same bytes.
+			_, again := saveAndLoadMaterialized(t, got)
+			require.Equal(t, data, again)
+		})
+	}
+}
+
+func TestLoadMaterialized_RebuildsTimeIndex(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	blobSet := createOverlappingNumericBlobSet(t, base)
+	material := blobSet.Materialize(WithTimeIndex())
+
+	loaded, _ := saveAndLoadMaterialized(t, material)
+	require.NotNil(t, loaded.data[hash.ID("overlap")].timeOrder)
+
+	for sec := range 35 {
+		ts := base.Add(time.Duration(sec) * time.Second).UnixMicro()
+		wantIdx, wantOK := material.SearchTimeByName("overlap", ts)
+		gotIdx, gotOK := loaded.SearchTimeByName("overlap", ts)
+		require.Equal(t, wantOK, gotOK)
+		require.Equal(t, wantIdx, gotIdx)
+	}
+}
+
+func TestLoadMaterialized_InvalidData(t *testing.T) {
+	blobSet := createTestBlobSetForMaterialization(t, 2, format.TypeDelta, format.TypeGorilla, true, map[uint64]int{1: 5, 2: 7})
+	var buf bytes.Buffer
+	require.NoError(t, blobSet.Materialize().Save(&buf))
+	data := buf.Bytes()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty", data: nil},
+		{name: "Truncated", data: data[:len(data)-1]},
+		{name: "Corrupted", data: func() []byte {
+			corrupted := bytes.Clone(data)
+			corrupted[materialHeaderSize+3] ^= 0xFF
+
+			return corrupted
+		}()},
+		{name: "NotMaterialized", data: make([]byte, 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMaterialized(bytes.NewReader(tt.data))
+			require.ErrorIs(t, err, errs.ErrInvalidMaterializedData)
+		})
+	}
+}
+
+func TestMaterialMagic_NoBlobCollision(t *testing.T) {
+	blobMagics := []uint16{
+		section.MagicNumericV1Opt,
+		section.MagicNumericV2Opt,
+		section.MagicNumericV2ExtOpt,
+		section.MagicNumericV2NoTagOpt,
+		section.MagicNumericSmallOpt,
+		section.MagicTextV1Opt,
+		section.MagicEventV1Opt,
+		numericPartMagic,
+	}
+	for _, magic := range blobMagics {
+		require.NotEqual(t, magic>>8, materialMagic>>8, "magic 0x%04X", magic)
+	}
+
+	blobSet := createTestBlobSetForMaterialization(t, 1, format.TypeDelta, format.TypeGorilla, true, map[uint64]int{1: 5})
+	_, data := saveAndLoadMaterialized(t, blobSet.Materialize())
+	require.GreaterOrEqual(t, len(data), section.HeaderSize)
+	require.False(t, section.IsNumericBlob(data))
+	require.False(t, section.IsTextBlob(data))
+	require.False(t, section.IsEventBlob(data))
+}
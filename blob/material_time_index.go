@@ -0,0 +1,99 @@
+package blob
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// MaterializeConfig holds the configuration of a materialized blob set.
+type MaterializeConfig struct {
+	// TimeIndex builds a per-metric timestamp index for SearchTime.
+	TimeIndex bool
+}
+
+// MaterializeOption configures Materialize on blob sets.
+type MaterializeOption = options.Option[*MaterializeConfig]
+
+// WithTimeIndex builds a sorted timestamp index for every metric during materialization,
+// making SearchTime an O(log n) binary search.
+//
+// Materialized data concatenates blobs in start-time order, so timestamps are only
+// globally sorted when blobs do not overlap. For such metrics the index costs nothing;
+// for metrics from overlapping blobs it stores a time-ordered permutation of the
+// global indices (8 bytes per data point). Without the index, SearchTime falls back
+// to a linear scan.
+//
+// Example:
+//
+//	material := blobSet.Materialize(blob.WithTimeIndex())
+//	idx, ok := material.SearchTime(metricID, startTs)
+//	if ok {
+//	    val, _ := material.ValueAt(metricID, idx)
+//	}
+func WithTimeIndex() MaterializeOption {
+	return options.NoError(func(cfg *MaterializeConfig) {
+		cfg.TimeIndex = true
+	})
+}
+
+// newMaterializeConfig applies materialize options to a default configuration.
+func newMaterializeConfig(opts []MaterializeOption) *MaterializeConfig {
+	cfg := &MaterializeConfig{}
+	// Materialize options never fail.
+	_ = options.Apply(cfg, opts...)
+
+	return cfg
+}
+
+// buildTimeOrder returns the global indices of timestamps ordered by timestamp, with
+// ties kept in global index order, or nil if timestamps are already sorted.
+func buildTimeOrder(timestamps []int64) []int {
+	if slices.IsSorted(timestamps) {
+		return nil
+	}
+
+	order := make([]int, len(timestamps))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(timestamps[a], timestamps[b])
+	})
+
+	return order
+}
+
+// searchTime returns the global index of the earliest data point at or after ts.
+// Among equal timestamps the lowest global index wins. When indexed is false the
+// timestamps are scanned linearly.
+func searchTime(timestamps []int64, order []int, indexed bool, ts int64) (int, bool) {
+	if !indexed {
+		best, found := 0, false
+		for i, t := range timestamps {
+			if t >= ts && (!found || t < timestamps[best]) {
+				best, found = i, true
+			}
+		}
+
+		return best, found
+	}
+
+	if order == nil {
+		i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= ts })
+		if i == len(timestamps) {
+			return 0, false
+		}
+
+		return i, true
+	}
+
+	k := sort.Search(len(order), func(k int) bool { return timestamps[order[k]] >= ts })
+	if k == len(order) {
+		return 0, false
+	}
+
+	return order[k], true
+}
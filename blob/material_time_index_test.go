@@ -0,0 +1,131 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// createOverlappingNumericBlobSet creates two blobs whose "overlap" metric interleaves
+// in time, while "sorted" does not overlap.
+func createOverlappingNumericBlobSet(t *testing.T, base time.Time) NumericBlobSet {
+	t.Helper()
+
+	offsets := [][]int{{0, 10, 20, 30, 30}, {5, 15, 25, 30}}
+	blobs := make([]NumericBlob, len(offsets))
+	for blobIdx, secs := range offsets {
+		blobStart := base.Add(time.Duration(blobIdx*5) * time.Second)
+		encoder, err := NewNumericEncoder(blobStart)
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricName("overlap", len(secs)))
+		for i, sec := range secs {
+			ts := base.Add(time.Duration(sec) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(blobIdx*100+i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		require.NoError(t, encoder.StartMetricName("sorted", 2))
+		for i := range 2 {
+			ts := base.Add(time.Duration(blobIdx*60+i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blobs[blobIdx], err = decoder.Decode()
+		require.NoError(t, err)
+	}
+
+	blobSet, err := NewNumericBlobSet(blobs)
+	require.NoError(t, err)
+
+	return blobSet
+}
+
+func TestMaterializedNumericBlobSet_SearchTime(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	blobSet := createOverlappingNumericBlobSet(t, base)
+
+	indexed := blobSet.Materialize(WithTimeIndex())
+	linear := blobSet.Materialize()
+
+	// Only overlapping metrics need a permutation
+	require.NotNil(t, indexed.data[hash.ID("overlap")].timeOrder)
+	require.Nil(t, indexed.data[hash.ID("sorted")].timeOrder)
+
+	for _, name := range []string{"overlap", "sorted"} {
+		id := hash.ID(name)
+		timestamps := indexed.data[id].timestamps
+
+		for sec := -1; sec <= 70; sec++ {
+			target := base.Add(time.Duration(sec) * time.Second).UnixMicro()
+
+			// Brute force: earliest timestamp >= target, lowest global index on ties
+			want, wantOk := 0, false
+			for i, ts := range timestamps {
+				if ts >= target && (!wantOk || ts < timestamps[want]) {
+					want, wantOk = i, true
+				}
+			}
+
+			got, ok := indexed.SearchTime(id, target)
+			require.Equal(t, wantOk, ok, "%s@%ds", name, sec)
+			require.Equal(t, want, got, "%s@%ds", name, sec)
+
+			got, ok = linear.SearchTime(id, target)
+			require.Equal(t, wantOk, ok, "%s@%ds linear", name, sec)
+			require.Equal(t, want, got, "%s@%ds linear", name, sec)
+		}
+	}
+
+	// 5s lives in the second blob: global index 5 (after the first blob's 5 points)
+	idx, ok := indexed.SearchTime(hash.ID("overlap"), base.Add(3*time.Second).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 5, idx)
+	val, ok := indexed.ValueAt(hash.ID("overlap"), idx)
+	require.True(t, ok)
+	require.Equal(t, 100.0, val)
+
+	// Ties resolve to the lowest global index
+	idx, ok = indexed.SearchTime(hash.ID("overlap"), base.Add(26*time.Second).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 3, idx)
+
+	_, ok = indexed.SearchTime(12345, 0)
+	require.False(t, ok)
+}
+
+func TestMaterializedBlobSet_SearchTime(t *testing.T) {
+	blobSet := createMixedBlobSetForMaterialization(t)
+	mat := blobSet.Materialize(WithTimeIndex())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Numeric metric
+	idx, ok := mat.SearchTimeByName("cpu.usage", start.Add(time.Hour+time.Second).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 4, idx)
+
+	// Text-only metric
+	idx, ok = mat.SearchTimeByName("status", start.Add(30*time.Minute).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 2, idx)
+	val, ok := mat.TextValueAtByName("status", idx)
+	require.True(t, ok)
+	require.Equal(t, "state_C", val)
+
+	// Metrics in both numeric and text blobs are searched in numeric data
+	idx, ok = mat.SearchTimeByName("shared", start.Add(time.Minute).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, 1, idx)
+
+	_, ok = mat.SearchTimeByName("status", start.Add(2*time.Hour).UnixMicro())
+	require.False(t, ok)
+}
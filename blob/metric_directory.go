@@ -0,0 +1,157 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/section"
+)
+
+// metricDirEntrySize is the size of a metric directory entry: MetricID(8) + Offset(4).
+const metricDirEntrySize = 12
+
+// metricDirEntry locates the body of a metric in a metric directory record.
+//
+// Metadata records holding one body per metric (sketches, tag summaries) share
+// the layout: a Count uint32, Count (MetricID uint64, Offset uint32) entries
+// sorted by MetricID, then the bodies each Offset points into.
+type metricDirEntry struct {
+	metricID uint64
+	offset   int // offset of the body in the bodies
+}
+
+// encodeMetricDirectory serializes the directory, sorted by MetricID, and the
+// bodies it points into.
+func encodeMetricDirectory(entries []metricDirEntry, bodies []byte, engine endian.EndianEngine) []byte {
+	slices.SortFunc(entries, func(a, b metricDirEntry) int {
+		return cmp.Compare(a.metricID, b.metricID)
+	})
+
+	b := make([]byte, 0, 4+metricDirEntrySize*len(entries)+len(bodies))
+	b = engine.AppendUint32(b, uint32(len(entries))) //nolint: gosec
+	for _, e := range entries {
+		b = engine.AppendUint64(b, e.metricID)
+		b = engine.AppendUint32(b, uint32(e.offset)) //nolint: gosec
+	}
+
+	return append(b, bodies...)
+}
+
+// removeMetricBody removes the i-th entry and its body from the directory of an
+// encoder, whose bodies are appended in entry order.
+func removeMetricBody(entries []metricDirEntry, bodies []byte, i int) ([]metricDirEntry, []byte) {
+	end := len(bodies)
+	if i+1 < len(entries) {
+		end = entries[i+1].offset
+	}
+
+	size := end - entries[i].offset
+	bodies = slices.Delete(bodies, entries[i].offset, end)
+	entries = slices.Delete(entries, i, i+1)
+	for j := i; j < len(entries); j++ {
+		entries[j].offset -= size
+	}
+
+	return entries, bodies
+}
+
+// parseMetricDirectory splits a directory record into its entries, in record
+// order, and bodies.
+func parseMetricDirectory(record []byte, engine endian.EndianEngine) ([]metricDirEntry, []byte, error) {
+	if len(record) < 4 {
+		return nil, nil, fmt.Errorf("%w: metric directory record truncated", errs.ErrInvalidMetadata)
+	}
+
+	count := int(engine.Uint32(record))
+	if count > (len(record)-4)/metricDirEntrySize {
+		return nil, nil, fmt.Errorf("%w: metric directory truncated", errs.ErrInvalidMetadata)
+	}
+
+	bodies := record[4+metricDirEntrySize*count:]
+	entries := make([]metricDirEntry, count)
+	for i := range entries {
+		off := 4 + metricDirEntrySize*i
+		entries[i] = metricDirEntry{
+			metricID: engine.Uint64(record[off:]),
+			offset:   int(engine.Uint32(record[off+8:])),
+		}
+		if entries[i].offset > len(bodies) {
+			return nil, nil, fmt.Errorf("%w: body of metric ID 0x%016x out of range", errs.ErrInvalidMetadata, entries[i].metricID)
+		}
+	}
+
+	return entries, bodies, nil
+}
+
+// findMetricBody binary searches the directory record for the metric and
+// returns its body, extending to the end of the record.
+func findMetricBody(record []byte, metricID uint64, engine endian.EndianEngine) ([]byte, bool) {
+	if len(record) < 4 {
+		return nil, false
+	}
+
+	count := int(engine.Uint32(record))
+	if count > (len(record)-4)/metricDirEntrySize {
+		return nil, false
+	}
+
+	idAt := func(i int) uint64 {
+		return engine.Uint64(record[4+metricDirEntrySize*i:])
+	}
+
+	lo, hi := 0, count
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1) //nolint: gosec
+		if idAt(mid) < metricID {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo == count || idAt(lo) != metricID {
+		return nil, false
+	}
+
+	bodies := record[4+metricDirEntrySize*count:]
+	offset := int(engine.Uint32(record[4+metricDirEntrySize*lo+8:]))
+	if offset > len(bodies) {
+		return nil, false
+	}
+
+	return bodies[offset:], true
+}
+
+// remapMetricDirectory returns metadata whose directory record under key uses
+// the remapped IDs, re-sorted by MetricID. Other records are shared with md.
+func remapMetricDirectory(md section.Metadata, key section.MetadataKey, oldIDs, newIDs []uint64, engine endian.EndianEngine) (section.Metadata, error) {
+	record, ok := md.Get(key)
+	if !ok {
+		return md, nil
+	}
+
+	entries, bodies, err := parseMetricDirectory(record, engine)
+	if err != nil {
+		return md, err
+	}
+
+	remap := make(map[uint64]uint64, len(oldIDs))
+	for i, id := range oldIDs {
+		remap[id] = newIDs[i]
+	}
+
+	for i := range entries {
+		newID, ok := remap[entries[i].metricID]
+		if !ok {
+			return md, fmt.Errorf("%w: body of unknown metric ID 0x%016x", errs.ErrInvalidMetadata, entries[i].metricID)
+		}
+		entries[i].metricID = newID
+	}
+
+	remapped := section.Metadata{Records: slices.Clone(md.Records)}
+	remapped.Set(key, encodeMetricDirectory(entries, bodies, engine))
+
+	return remapped, nil
+}
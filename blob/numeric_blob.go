@@ -2,6 +2,8 @@ package blob
 
 import (
 	"iter"
+	"math"
+	"slices"
 	"time"
 
 	"github.com/arloliu/mebo/encoding"
@@ -28,7 +30,14 @@ type NumericBlob struct {
 	tsPayload     []byte
 	valPayload    []byte
 	tagPayload    []byte
-	sharedTsCache map[int][]int64 // Pre-decoded shared timestamps keyed by TimestampOffset (nil if no shared TS)
+	sharedTsCache map[int][]int64                   // Pre-decoded shared timestamps keyed by TimestampOffset (nil if no shared TS)
+	valTransform  ValueTransform                    // Optional decode-time value transform (nil if none)
+	metadata      section.Metadata                  // Optional metadata section records (empty if absent)
+	refValCache   map[uint64][]float64              // Reconstructed values of reference-delta metrics keyed by MetricID (nil if none)
+	metricMeta    map[uint64]MetricMeta             // Declared kind and unit keyed by MetricID (nil if none)
+	tagBitmap     bool                              // Tag sections use the presence bitmap layout (recorded in metadata)
+	extValues     encoding.ColumnarDecoder[float64] // Registered decoder of an extension value encoding (nil if built-in)
+	valuesErr     error                             // Why the value payload is unavailable (nil if present)
 }
 
 var _ BlobReader = NumericBlob{}
@@ -91,6 +100,27 @@ func (b NumericBlob) MetricNames() []string {
 	return b.index.MetricNames()
 }
 
+// MetricNamesErr reports why the blob's compressed metric names payload could not
+// be used, or nil.
+//
+// Compressed metric names (see WithCompressedMetricNames) are decoded and verified
+// on the first name lookup rather than by Decode. If that fails, the blob behaves
+// as if it had no metric names: name lookups fall back to hashing and MetricNames
+// returns an empty slice. Calling MetricNamesErr parses the names if needed.
+func (b NumericBlob) MetricNamesErr() error {
+	return b.index.namesErr()
+}
+
+// ValuesErr reports why the blob's values cannot be read, or nil.
+//
+// A blob decoded from parts without the Values part (see
+// NewNumericDecoderFromParts) serves timestamps, tags and index queries, but
+// has no values: ValueAt and similar lookups return false, value iterators
+// yield nothing, and ValuesErr returns an error wrapping ErrInvalidBlobPart.
+func (b NumericBlob) ValuesErr() error {
+	return b.valuesErr
+}
+
 // Len returns the number of data points for the given metric ID.
 // If the metric ID does not exist, it returns 0.
 //
@@ -112,6 +142,49 @@ func (b NumericBlob) LenByName(metricName string) int {
 	return b.index.LenByName(metricName)
 }
 
+// ValuePrecision returns the number of decimal digits values were rounded to
+// by the encoder (see WithValuePrecision).
+//
+// Returns (decimals, true) if the blob records a value precision, or (0, false)
+// if values were stored at full float64 precision.
+func (b NumericBlob) ValuePrecision() (int, bool) {
+	v, ok := b.metadata.Get(section.MetadataKeyValuePrecision)
+	if !ok || len(v) != 1 {
+		return 0, false
+	}
+
+	return int(v[0]), true
+}
+
+// QuantizationStep returns the step values were snapped to by the encoder
+// (see WithQuantization).
+//
+// Returns (step, true) if the blob records a quantization step, or (0, false)
+// if values were not quantized with an explicit step.
+func (b NumericBlob) QuantizationStep() (float64, bool) {
+	v, ok := b.metadata.Get(section.MetadataKeyQuantizationStep)
+	if !ok || len(v) != 8 {
+		return 0, false
+	}
+
+	return math.Float64frombits(b.Engine().Uint64(v)), true
+}
+
+// TimestampUnit returns the unit of the stored timestamps (see WithTimestampUnit).
+//
+// All timestamp accessors and iterators return timestamps in this unit. Use
+// format.TimeUnit.Time to convert them to time.Time, or Convert to rescale them.
+//
+// Returns format.TimeUnitMicrosecond for blobs that record no unit.
+func (b NumericBlob) TimestampUnit() format.TimeUnit {
+	v, ok := b.metadata.Get(section.MetadataKeyTimestampUnit)
+	if !ok || len(v) != 1 {
+		return format.TimeUnitMicrosecond
+	}
+
+	return format.TimeUnit(v[0])
+}
+
 // All returns an iterator over (index, NumericDataPoint) for the given metric ID.
 // The index starts from 0 and increments for each data point.
 // NumericDataPoint contains timestamp, value, and optional tag.
@@ -194,6 +267,33 @@ func (b NumericBlob) AllTimestampsByName(metricName string) iter.Seq[int64] {
 	return b.allTimestampsFromEntry(entry)
 }
 
+// AllTimes returns an iterator over all timestamps for the given metric ID as time.Time,
+// converted according to the blob's timestamp unit (see TimestampUnit).
+//
+// Parameters:
+//   - metricID: The metric ID to iterate over.
+//
+// Returns:
+//   - iter.Seq2[int, time.Time]: Iterator yielding (0-based index, UTC time) pairs.
+//     Returns an empty iterator if the metric ID is not found.
+//
+// Example:
+//
+//	for i, t := range blob.AllTimes(metricID) {
+//	    fmt.Printf("[%d] %s\n", i, t.In(loc).Format(time.RFC3339))
+//	}
+func (b NumericBlob) AllTimes(metricID uint64) iter.Seq2[int, time.Time] {
+	return allTimes(b.AllTimestamps(metricID), b.TimestampUnit())
+}
+
+// AllTimesByName returns an iterator over all timestamps for the given metric name as
+// time.Time, converted according to the blob's timestamp unit (see TimestampUnit).
+//
+// Returns an empty iterator if the metric name is not found.
+func (b NumericBlob) AllTimesByName(metricName string) iter.Seq2[int, time.Time] {
+	return allTimes(b.AllTimestampsByName(metricName), b.TimestampUnit())
+}
+
 // AllValues returns an iterator over all float64 values for the given metric ID.
 //
 // Parameters:
@@ -351,6 +451,50 @@ func (b NumericBlob) ValueAtByName(metricName string, index int) (float64, bool)
 	return b.valueAtFromEntry(entry, index)
 }
 
+// ValuesAt returns the values at the specified indexes for the given metric, in the
+// order the indexes were requested. Indexes may be unsorted and may repeat.
+//
+// Unlike calling ValueAt once per index, sequential encodings (Gorilla, Chimp)
+// decode the column at most once up to the largest requested index, so scattered
+// access costs O(n + k log k) instead of O(n × k). Raw and ALP values are read
+// per index since they already support O(1) random access.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//   - indexes: 0-based data point indexes within the blob
+//
+// Returns:
+//   - []float64: Values aligned with indexes
+//   - bool: false if the metric doesn't exist, any index is out of bounds, or the
+//     value encoding isn't Raw, Gorilla, Chimp, or ALP
+//
+// Example:
+//
+//	values, ok := blob.ValuesAt(metricID, []int{90, 3, 42})
+//	if ok {
+//	    fmt.Println(values[0]) // value at index 90
+//	}
+func (b NumericBlob) ValuesAt(metricID uint64, indexes []int) ([]float64, bool) {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return nil, false
+	}
+
+	return b.valuesAtFromEntry(entry, indexes)
+}
+
+// ValuesAtByName returns the values at the specified indexes for the given metric name.
+//
+// Behavior and performance: see ValuesAt.
+func (b NumericBlob) ValuesAtByName(metricName string, indexes []int) ([]float64, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return nil, false
+	}
+
+	return b.valuesAtFromEntry(entry, indexes)
+}
+
 // TagAt returns the tag at the specified index for the given metric.
 // The index is 0-based within this blob.
 //
@@ -465,13 +609,37 @@ func (b NumericBlob) allFromEntry(entry section.NumericIndexEntry) iter.Seq2[int
 	if b.HasTag() && len(b.tagPayload) > 0 {
 		var tagOk bool
 		tagBytes, tagOk = safeSlice(b.tagPayload, entry.TagOffset, entry.TagLength)
+		if tagOk {
+			tagBytes, tagOk = b.plainTags(tagBytes, entry.Count)
+		}
 		if !tagOk {
 			return func(yield func(int, NumericDataPoint) bool) {}
 		}
 	}
 
-	// Return optimized iterator based on encoding types
-	return b.allDataPoints(tsBytes, valBytes, tagBytes, entry.Count)
+	// Return optimized iterator based on encoding types; reference-delta metrics
+	// pair their reconstructed values with the decoded timestamps and tags.
+	var seq iter.Seq2[int, NumericDataPoint]
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		seq = b.allFromValues(entry, values)
+	} else {
+		seq = b.allDataPoints(tsBytes, valBytes, tagBytes, entry.Count)
+	}
+
+	if b.valTransform == nil {
+		return seq
+	}
+
+	transform, metricID := b.valTransform, entry.MetricID
+
+	return func(yield func(int, NumericDataPoint) bool) {
+		for i, dp := range seq {
+			dp.Val = transform(metricID, dp.Val)
+			if !yield(i, dp) {
+				return
+			}
+		}
+	}
 }
 
 // allTimestampsFromEntry returns an iterator over all timestamps for the given entry.
@@ -510,7 +678,25 @@ func (b NumericBlob) allValuesFromEntry(entry section.NumericIndexEntry) iter.Se
 		return func(yield func(float64) bool) {}
 	}
 
-	return b.decodeValues(valBytes, entry.Count)
+	var seq iter.Seq[float64]
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		seq = slices.Values(values)
+	} else {
+		seq = b.decodeValues(valBytes, entry.Count)
+	}
+	if b.valTransform == nil {
+		return seq
+	}
+
+	transform, metricID := b.valTransform, entry.MetricID
+
+	return func(yield func(float64) bool) {
+		for v := range seq {
+			if !yield(transform(metricID, v)) {
+				return
+			}
+		}
+	}
 }
 
 // allTagsFromEntry returns an iterator over all tags for the given entry.
@@ -536,7 +722,7 @@ func (b NumericBlob) allTagsFromEntry(entry section.NumericIndexEntry) iter.Seq[
 		return func(yield func(string) bool) {}
 	}
 
-	return b.decodeTags(tagBytes, count)
+	return b.tagDecoder().All(tagBytes, count)
 }
 
 // timestampAtFromEntry returns the timestamp at the specified index for the given entry.
@@ -574,16 +760,65 @@ func (b NumericBlob) timestampAtFromEntry(entry section.NumericIndexEntry, index
 	}
 }
 
-// valueAtFromEntry returns the value at the specified index for the given entry.
+// valueAtFromEntry returns the value at the specified index for the given entry,
+// with the decode-time value transform (if any) applied.
 func (b NumericBlob) valueAtFromEntry(entry section.NumericIndexEntry, index int) (float64, bool) {
+	v, ok := b.rawValueAtFromEntry(entry, index)
+	if !ok || b.valTransform == nil {
+		return v, ok
+	}
+
+	return b.valTransform(entry.MetricID, v), true
+}
+
+// ValueCapabilities returns the access capabilities of the decoder for the
+// blob's value encoding, so callers can choose between direct ValueAt calls and
+// materializing the column. Extension encodings report the capabilities of
+// their registered decoder (see encoding.CapabilitiesOf).
+//
+// Returns:
+//   - encoding.Capabilities: The capabilities, or the zero value for an unknown encoding
+//
+// Example:
+//
+//	if blob.ValueCapabilities().RandomAccess {
+//	    v, _ := blob.ValueAt(metricID, index)
+//	} else {
+//	    points, _ := blob.DataPoints(metricID)
+//	}
+func (b NumericBlob) ValueCapabilities() encoding.Capabilities {
+	switch b.ValueEncoding() { //nolint: exhaustive
+	case format.TypeRaw:
+		return encoding.CapabilitiesOf(ienc.NewNumericRawDecoder(b.Engine()))
+	case format.TypeGorilla:
+		return encoding.CapabilitiesOf(ienc.NewNumericGorillaDecoder())
+	case format.TypeChimp:
+		return encoding.CapabilitiesOf(ienc.NewNumericChimpDecoder())
+	case format.TypeALP:
+		return encoding.CapabilitiesOf(ienc.NewNumericALPDecoder(b.Engine()))
+	case format.TypeDelta:
+		return encoding.CapabilitiesOf(ienc.NewTimestampDeltaDecoder())
+	case format.TypeExtension:
+		return encoding.CapabilitiesOf(b.extValues)
+	default:
+		return encoding.Capabilities{}
+	}
+}
+
+// rawValueAtFromEntry returns the stored value at the specified index for the given entry.
+func (b NumericBlob) rawValueAtFromEntry(entry section.NumericIndexEntry, index int) (float64, bool) {
 	count := entry.Count
 	if index < 0 || index >= count {
 		return 0, false
 	}
 
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		return values[index], true
+	}
+
 	// Get byte slice for values
 	valStart := entry.ValueOffset
-	if valStart > len(b.valPayload) {
+	if valStart > len(b.valPayload) || b.valuesErr != nil {
 		return 0, false
 	}
 
@@ -631,12 +866,95 @@ func (b NumericBlob) valueAtFromEntry(entry section.NumericIndexEntry, index int
 		valBytes = b.valPayload[valStart:]
 
 		return decoder.At(valBytes, index, count)
+	case format.TypeDelta:
+		intBytes, ok := safeSlice(b.valPayload, valStart, entry.ValueLength)
+		if !ok {
+			return 0, false
+		}
+
+		v, ok := ienc.NewTimestampDeltaDecoder().At(intBytes, index, count)
+
+		return float64(v), ok
+	case format.TypeExtension:
+		if b.extValues == nil {
+			return 0, false
+		}
+
+		extBytes, ok := safeSlice(b.valPayload, valStart, entry.ValueLength)
+		if !ok {
+			return 0, false
+		}
+
+		return b.extValues.At(extBytes, index, count)
 	default:
 		// Other encodings don't support random access
 		return 0, false
 	}
 }
 
+// valuesAtFromEntry returns the values at the specified indexes for the given entry,
+// decoding sequential encodings once in ascending index order.
+func (b NumericBlob) valuesAtFromEntry(entry section.NumericIndexEntry, indexes []int) ([]float64, bool) {
+	for _, index := range indexes {
+		if index < 0 || index >= entry.Count {
+			return nil, false
+		}
+	}
+
+	values := make([]float64, len(indexes))
+
+	if _, isRef := b.refValCache[entry.MetricID]; isRef || b.ValueCapabilities().RandomAccess {
+		for i, index := range indexes {
+			v, ok := b.valueAtFromEntry(entry, index)
+			if !ok {
+				return nil, false
+			}
+			values[i] = v
+		}
+
+		return values, true
+	}
+
+	if len(indexes) == 0 {
+		return values, true
+	}
+
+	valBytes, ok := safeSlice(b.valPayload, entry.ValueOffset, entry.ValueLength)
+	if !ok {
+		return nil, false
+	}
+
+	// Visit the requested positions in ascending index order while walking the stream.
+	order := make([]int, len(indexes))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(x, y int) int { return indexes[x] - indexes[y] })
+
+	next, i := 0, 0
+	for v := range b.decodeValues(valBytes, entry.Count) {
+		for next < len(order) && indexes[order[next]] == i {
+			values[order[next]] = v
+			next++
+		}
+		if next == len(order) {
+			break
+		}
+		i++
+	}
+	if next < len(order) {
+		return nil, false
+	}
+
+	if b.valTransform != nil {
+		for i, v := range values {
+			values[i] = b.valTransform(entry.MetricID, v)
+		}
+	}
+
+	return values, true
+}
+
 // tagAtFromEntry returns the tag at the specified index for the given entry.
 func (b NumericBlob) tagAtFromEntry(entry section.NumericIndexEntry, index int) (string, bool) {
 	count := entry.Count
@@ -650,10 +968,8 @@ func (b NumericBlob) tagAtFromEntry(entry section.NumericIndexEntry, index int)
 		return "", false
 	}
 
-	// Tags always support random access
-	decoder := ienc.NewTagDecoder(b.Engine())
-
-	return decoder.At(tagBytes, index, count)
+	// Tags always support random access; the presence bitmap answers empty tags directly
+	return b.tagDecoder().At(tagBytes, index, count)
 }
 
 // allDataPoints creates an optimized iterator for (index, NumericDataPoint).
@@ -762,7 +1078,7 @@ func (b NumericBlob) allDataPointsRaw(tsBytes, valBytes, tagBytes []byte, count
 
 	// Tags enabled: Use tag iterator to avoid O(N²) cost of repeated At() calls
 	// Tag At() must scan from start each time due to varint encoding
-	tagDecoder := ienc.NewTagDecoder(engine)
+	tagDecoder := ienc.NewTagDecoderWithInterner(engine, b.interner)
 
 	return func(yield func(int, NumericDataPoint) bool) {
 		tagIter := tagDecoder.All(tagBytes, count)
@@ -815,7 +1131,7 @@ func (b NumericBlob) allDataPointsDeltaRaw(tsBytes, valBytes, tagBytes []byte, c
 
 	// Tags enabled: Use fused delta+tag decoder with At() for raw values
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaTagAll(tsBytes, tagBytes, count, func(i int, ts int64, tag string) bool {
+		ienc.FusedDeltaTagAll(tsBytes, tagBytes, count, b.interner, func(i int, ts int64, tag string) bool {
 			val, _ := valDecoder.At(valBytes, i, count)
 
 			dp := NumericDataPoint{
@@ -861,7 +1177,7 @@ func (b NumericBlob) allDataPointsDeltaGorilla(tsBytes, valBytes, tagBytes []byt
 
 	// Tags enabled: Use fused delta+gorilla+tag decoder
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaGorillaTagAll(tsBytes, valBytes, tagBytes, count, func(i int, ts int64, val float64, tag string) bool {
+		ienc.FusedDeltaGorillaTagAll(tsBytes, valBytes, tagBytes, count, b.interner, func(i int, ts int64, val float64, tag string) bool {
 			dp := NumericDataPoint{
 				Ts:  ts,
 				Val: val,
@@ -900,7 +1216,7 @@ func (b NumericBlob) allDataPointsDeltaChimp(tsBytes, valBytes, tagBytes []byte,
 
 	// Tags enabled: Use fused delta+chimp+tag decoder
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaChimpTagAll(tsBytes, valBytes, tagBytes, count, func(i int, ts int64, val float64, tag string) bool {
+		ienc.FusedDeltaChimpTagAll(tsBytes, valBytes, tagBytes, count, b.interner, func(i int, ts int64, val float64, tag string) bool {
 			dp := NumericDataPoint{
 				Ts:  ts,
 				Val: val,
@@ -944,7 +1260,7 @@ func (b NumericBlob) allDataPointsDeltaPackedRaw(tsBytes, valBytes, tagBytes []b
 
 	// Tags enabled: Use fused deltaPacked+tag decoder with raw value At()
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaPackedTagAll(tsBytes, tagBytes, count, func(i int, ts int64, tag string) bool {
+		ienc.FusedDeltaPackedTagAll(tsBytes, tagBytes, count, b.interner, func(i int, ts int64, tag string) bool {
 			val, _ := valDecoder.At(valBytes, i, count)
 			return yield(i, NumericDataPoint{Ts: ts, Val: val, Tag: tag})
 		})
@@ -976,7 +1292,7 @@ func (b NumericBlob) allDataPointsDeltaPackedGorilla(tsBytes, valBytes, tagBytes
 	}
 
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaPackedGorillaTagAll(tsBytes, valBytes, tagBytes, count, func(i int, ts int64, val float64, tag string) bool {
+		ienc.FusedDeltaPackedGorillaTagAll(tsBytes, valBytes, tagBytes, count, b.interner, func(i int, ts int64, val float64, tag string) bool {
 			return yield(i, NumericDataPoint{Ts: ts, Val: val, Tag: tag})
 		})
 	}
@@ -1007,7 +1323,7 @@ func (b NumericBlob) allDataPointsDeltaPackedChimp(tsBytes, valBytes, tagBytes [
 	}
 
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedDeltaPackedChimpTagAll(tsBytes, valBytes, tagBytes, count, func(i int, ts int64, val float64, tag string) bool {
+		ienc.FusedDeltaPackedChimpTagAll(tsBytes, valBytes, tagBytes, count, b.interner, func(i int, ts int64, val float64, tag string) bool {
 			return yield(i, NumericDataPoint{Ts: ts, Val: val, Tag: tag})
 		})
 	}
@@ -1054,7 +1370,7 @@ func (b NumericBlob) allDataPointsRawGorilla(tsBytes, valBytes, tagBytes []byte,
 
 	// Tags enabled: Use fused gorilla+tag decoder with At() for raw timestamps
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedGorillaTagAll(valBytes, tagBytes, count, func(i int, val float64, tag string) bool {
+		ienc.FusedGorillaTagAll(valBytes, tagBytes, count, b.interner, func(i int, val float64, tag string) bool {
 			ts, _ := tsDecoder.At(tsBytes, i, count)
 
 			dp := NumericDataPoint{
@@ -1107,7 +1423,7 @@ func (b NumericBlob) allDataPointsRawChimp(tsBytes, valBytes, tagBytes []byte, c
 
 	// Tags enabled: Use fused chimp+tag decoder with At() for raw timestamps
 	return func(yield func(int, NumericDataPoint) bool) {
-		ienc.FusedChimpTagAll(valBytes, tagBytes, count, func(i int, val float64, tag string) bool {
+		ienc.FusedChimpTagAll(valBytes, tagBytes, count, b.interner, func(i int, val float64, tag string) bool {
 			ts, _ := tsDecoder.At(tsBytes, i, count)
 
 			dp := NumericDataPoint{
@@ -1269,6 +1585,20 @@ func (b NumericBlob) decodeValues(valBytes []byte, count int) iter.Seq[float64]
 		decoder := ienc.NewNumericALPDecoder(engine)
 
 		return decoder.All(valBytes, count)
+	case format.TypeDelta:
+		return func(yield func(float64) bool) {
+			for v := range ienc.NewTimestampDeltaDecoder().All(valBytes, count) {
+				if !yield(float64(v)) {
+					return
+				}
+			}
+		}
+	case format.TypeExtension:
+		if b.extValues == nil {
+			return func(yield func(float64) bool) {}
+		}
+
+		return b.extValues.All(valBytes, count)
 	default:
 		return func(yield func(float64) bool) {}
 	}
@@ -1305,6 +1635,57 @@ func (b NumericBlob) decodeTimestampsSlice(tsBytes []byte, count int, dst []int6
 	}
 }
 
+// decodeEntryValues decodes the values of entry into dst, resolving reference-delta
+// metrics and applying the decode-time value transform (if any).
+// Returns the number of values written.
+func (b NumericBlob) decodeEntryValues(entry section.NumericIndexEntry, dst []float64) int {
+	var n int
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		n = copy(dst[:min(entry.Count, len(dst))], values)
+	} else {
+		if valBytes, ok := safeSlice(b.valPayload, entry.ValueOffset, entry.ValueLength); ok {
+			n = b.decodeValuesSlice(valBytes, entry.Count, dst)
+		}
+	}
+
+	if b.valTransform != nil {
+		for i, v := range dst[:n] {
+			dst[i] = b.valTransform(entry.MetricID, v)
+		}
+	}
+
+	return n
+}
+
+// allFromValues returns an iterator pairing pre-decoded values with the entry's
+// timestamps and tags. Used for reference-delta metrics whose values were
+// reconstructed at decode time.
+func (b NumericBlob) allFromValues(entry section.NumericIndexEntry, values []float64) iter.Seq2[int, NumericDataPoint] {
+	return func(yield func(int, NumericDataPoint) bool) {
+		var tags []string
+		if b.HasTag() {
+			tags = slices.Collect(b.allTagsFromEntry(entry))
+		}
+
+		i := 0
+		for ts := range b.allTimestampsFromEntry(entry) {
+			if i >= len(values) {
+				return
+			}
+
+			dp := NumericDataPoint{Ts: ts, Val: values[i]}
+			if i < len(tags) {
+				dp.Tag = tags[i]
+			}
+
+			if !yield(i, dp) {
+				return
+			}
+			i++
+		}
+	}
+}
+
 // decodeValuesSlice decodes all values directly into the destination slice.
 // dst must have len >= count.
 //
@@ -1336,14 +1717,57 @@ func (b NumericBlob) decodeValuesSlice(valBytes []byte, count int, dst []float64
 		decoder := ienc.NewNumericALPDecoder(engine)
 
 		return decoder.DecodeAll(valBytes, count, dst)
+	case format.TypeDelta:
+		ints := make([]int64, count)
+		n := ienc.NewTimestampDeltaDecoder().DecodeAll(valBytes, count, ints)
+		for i, v := range ints[:n] {
+			dst[i] = float64(v)
+		}
+
+		return n
+	case format.TypeExtension:
+		if b.extValues == nil {
+			return 0
+		}
+
+		n := 0
+		for v := range b.extValues.All(valBytes, count) {
+			if n == count {
+				break
+			}
+			dst[n] = v
+			n++
+		}
+
+		return n
 	default:
 		return 0
 	}
 }
 
+// tagDecoder returns a decoder for the tag sections of the blob's tag payload.
+func (b NumericBlob) tagDecoder() ienc.TagDecoder {
+	if b.tagBitmap {
+		return ienc.NewBitmapTagDecoder(b.Engine(), b.interner)
+	}
+
+	return ienc.NewTagDecoderWithInterner(b.Engine(), b.interner)
+}
+
+// plainTags returns a metric's tag section in the length-prefixed layout the
+// sequential decode paths expect, expanding presence bitmap sections.
+// Returns false if a bitmap section is malformed.
+func (b NumericBlob) plainTags(tagBytes []byte, count int) ([]byte, bool) {
+	if !b.tagBitmap {
+		return tagBytes, true
+	}
+
+	return ienc.AppendPlainTags(make([]byte, 0, len(tagBytes)+count), tagBytes, count)
+}
+
 // decodeTags returns an iterator for tag strings.
 // Tags are always encoded the same way regardless of timestamp/value encoding.
 func (b NumericBlob) decodeTags(tagBytes []byte, count int) iter.Seq[string] {
-	var decoder ienc.TagDecoder
+	decoder := ienc.NewTagDecoderWithInterner(b.Engine(), b.interner)
 	return decoder.All(tagBytes, count)
 }
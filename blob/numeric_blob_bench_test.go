@@ -1018,3 +1018,77 @@ func encodeLayoutRawBlobs(tb testing.TB, numBlobs, numMetrics, pointsPerMetric i
 
 	return result
 }
+
+func BenchmarkNumericReader_ValueAt(b *testing.B) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	metricID := hash.ID("test.metric.reader")
+
+	encoder, err := NewNumericEncoder(startTime, WithValueEncoding(format.TypeGorilla))
+	if err != nil {
+		b.Fatalf("Failed to create encoder: %v", err)
+	}
+	if err = encoder.StartMetricID(metricID, 100); err != nil {
+		b.Fatalf("Failed to start metric: %v", err)
+	}
+	for i := range 100 {
+		if err = encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Minute).UnixMicro(), rand.Float64()*100, ""); err != nil {
+			b.Fatalf("Failed to write data: %v", err)
+		}
+	}
+	if err = encoder.EndMetric(); err != nil {
+		b.Fatalf("Failed to end metric: %v", err)
+	}
+	data, err := encoder.Finish()
+	if err != nil {
+		b.Fatalf("Failed to finish: %v", err)
+	}
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		b.Fatalf("Failed to create decoder: %v", err)
+	}
+	blob, err := decoder.Decode()
+	if err != nil {
+		b.Fatalf("Failed to decode: %v", err)
+	}
+
+	b.Run("Blob", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				_, _ = blob.ValueAt(metricID, i%100)
+				i++
+			}
+		})
+	})
+
+	b.Run("Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			reader := blob.NewReader()
+			i := 0
+			for pb.Next() {
+				_, _ = reader.ValueAt(metricID, i%100)
+				i++
+			}
+		})
+	})
+
+	indexes := []int{90, 3, 71, 42, 15, 99, 60, 28}
+
+	b.Run("ValueAtLoop", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			for _, index := range indexes {
+				_, _ = blob.ValueAt(metricID, index)
+			}
+		}
+	})
+
+	b.Run("ValuesAt", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			_, _ = blob.ValuesAt(metricID, indexes)
+		}
+	})
+}
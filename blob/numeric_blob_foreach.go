@@ -69,6 +69,97 @@ func (b NumericBlob) ForEachByName(metricName string, yield func(idx int, dp Num
 	return true
 }
 
+// NumericDataPoints holds the data points of a single metric as parallel
+// slices: Ts[i], Val[i] and Tag[i] describe the i-th data point.
+//
+// It sits between per-point iteration (ForEach, All) and full set
+// materialization (Materialize), suited to callers that want one metric's
+// columns without paying for the whole blob.
+type NumericDataPoints struct {
+	// Ts holds the timestamps in insertion order.
+	Ts []int64
+	// Val holds the values in insertion order.
+	Val []float64
+	// Tag holds the tags in insertion order, or nil if tags are not enabled.
+	Tag []string
+}
+
+// Len returns the number of data points.
+func (p NumericDataPoints) Len() int {
+	return len(p.Ts)
+}
+
+// DataPoints decodes all data points of the given metric ID into parallel
+// slices in a single pass over the payloads.
+//
+// The slices are freshly allocated and owned by the caller. Tag is nil when
+// the blob was encoded without tags.
+//
+// Parameters:
+//   - metricID: Metric ID to decode
+//
+// Returns:
+//   - NumericDataPoints: The decoded timestamps, values and tags
+//   - bool: false if the metric ID does not exist in the blob
+//
+// Example:
+//
+//	points, ok := blob.DataPoints(metricID)
+//	if ok {
+//	    for i := range points.Len() {
+//	        fmt.Printf("ts=%d, val=%f\n", points.Ts[i], points.Val[i])
+//	    }
+//	}
+func (b NumericBlob) DataPoints(metricID uint64) (NumericDataPoints, bool) {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return NumericDataPoints{}, false
+	}
+
+	return b.dataPointsFromEntry(entry), true
+}
+
+// DataPointsByName decodes all data points of the given metric name into
+// parallel slices in a single pass over the payloads.
+//
+// See DataPoints for details.
+//
+// Returns:
+//   - NumericDataPoints: The decoded timestamps, values and tags
+//   - bool: false if the metric name does not exist in the blob
+func (b NumericBlob) DataPointsByName(metricName string) (NumericDataPoints, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return NumericDataPoints{}, false
+	}
+
+	return b.dataPointsFromEntry(entry), true
+}
+
+// dataPointsFromEntry fills pre-sized column slices through the fused
+// ForEach iteration path.
+func (b NumericBlob) dataPointsFromEntry(entry section.NumericIndexEntry) NumericDataPoints {
+	points := NumericDataPoints{
+		Ts:  make([]int64, 0, entry.Count),
+		Val: make([]float64, 0, entry.Count),
+	}
+	if b.HasTag() {
+		points.Tag = make([]string, 0, entry.Count)
+	}
+
+	b.forEachFromEntry(entry, func(_ int, dp NumericDataPoint) bool {
+		points.Ts = append(points.Ts, dp.Ts)
+		points.Val = append(points.Val, dp.Val)
+		if points.Tag != nil {
+			points.Tag = append(points.Tag, dp.Tag)
+		}
+
+		return true
+	})
+
+	return points
+}
+
 // forEachFromEntry slices the payloads for the entry and dispatches to the
 // encoding-specific iteration body.
 func (b NumericBlob) forEachFromEntry(entry section.NumericIndexEntry, yield func(int, NumericDataPoint) bool) {
@@ -89,11 +180,27 @@ func (b NumericBlob) forEachFromEntry(entry section.NumericIndexEntry, yield fun
 	if b.HasTag() && len(b.tagPayload) > 0 {
 		var tagOk bool
 		tagBytes, tagOk = safeSlice(b.tagPayload, entry.TagOffset, entry.TagLength)
+		if tagOk {
+			tagBytes, tagOk = b.plainTags(tagBytes, entry.Count)
+		}
 		if !tagOk {
 			return
 		}
 	}
 
+	if b.valTransform != nil {
+		transform, metricID, inner := b.valTransform, entry.MetricID, yield
+		yield = func(i int, dp NumericDataPoint) bool {
+			dp.Val = transform(metricID, dp.Val)
+			return inner(i, dp)
+		}
+	}
+
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		b.allFromValues(entry, values)(yield)
+		return
+	}
+
 	b.forEachDataPoint(tsBytes, valBytes, tagBytes, entry.Count, yield)
 }
 
@@ -362,6 +469,23 @@ func (b NumericBlob) forEachValuesFromEntry(entry section.NumericIndexEntry, yie
 		return
 	}
 
+	if b.valTransform != nil {
+		transform, metricID, inner := b.valTransform, entry.MetricID, yield
+		yield = func(i int, v float64) bool {
+			return inner(i, transform(metricID, v))
+		}
+	}
+
+	if values, ok := b.refValCache[entry.MetricID]; ok {
+		for i, v := range values {
+			if !yield(i, v) {
+				return
+			}
+		}
+
+		return
+	}
+
 	switch b.ValueEncoding() { //nolint:exhaustive // default branch drains the remaining codecs
 	case format.TypeGorilla:
 		ienc.FusedGorillaEach(valBytes, entry.Count, yield)
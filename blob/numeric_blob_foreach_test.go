@@ -320,3 +320,64 @@ func truncateForEachBlobPayload(blob NumericBlob, metricID uint64, timestamp boo
 
 	return blob
 }
+
+func TestNumericBlob_DataPoints(t *testing.T) {
+	for _, withTags := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tags=%v", withTags), func(t *testing.T) {
+			blob, metricIDs := buildForEachTestBlob(t, format.TypeDelta, format.TypeGorilla, withTags)
+
+			for _, id := range metricIDs {
+				points, ok := blob.DataPoints(id)
+				require.True(t, ok)
+				require.Equal(t, 50, points.Len())
+				require.Len(t, points.Val, 50)
+				if withTags {
+					require.Len(t, points.Tag, 50)
+				} else {
+					require.Nil(t, points.Tag)
+				}
+
+				for i, dp := range blob.All(id) {
+					require.Equal(t, dp.Ts, points.Ts[i])
+					require.Equal(t, dp.Val, points.Val[i])
+					if withTags {
+						require.Equal(t, dp.Tag, points.Tag[i])
+					}
+				}
+			}
+
+			points, ok := blob.DataPoints(99999)
+			require.False(t, ok)
+			require.Zero(t, points.Len())
+		})
+	}
+}
+
+func TestNumericBlob_DataPointsByName(t *testing.T) {
+	startTime := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(startTime, WithTagsEnabled(true))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricName("cpu.usage", 3))
+	base := startTime.UnixMicro()
+	for i := range 3 {
+		require.NoError(t, encoder.AddDataPoint(base+int64(i)*1000000, float64(i)+0.5, fmt.Sprintf("t%d", i)))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	points, ok := blob.DataPointsByName("cpu.usage")
+	require.True(t, ok)
+	require.Equal(t, []int64{base, base + 1000000, base + 2000000}, points.Ts)
+	require.Equal(t, []float64{0.5, 1.5, 2.5}, points.Val)
+	require.Equal(t, []string{"t0", "t1", "t2"}, points.Tag)
+
+	_, ok = blob.DataPointsByName("no.such.metric")
+	require.False(t, ok)
+}
@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/arloliu/mebo/section"
+)
+
+// IndexEntryInfo describes where the encoded data of one metric lives in a
+// numeric blob.
+//
+// Offsets are absolute byte offsets into the decompressed timestamp, value and
+// tag payloads, and sizes are the encoded byte sizes of the metric in each
+// payload, so per-metric storage costs can be computed without re-deriving the
+// delta-encoded index. For compressed payloads, sizes are measured before
+// compression.
+type IndexEntryInfo struct {
+	MetricID        uint64 // metric ID
+	Name            string // metric name, empty if the blob has no metric names
+	Count           int    // number of data points
+	TimestampOffset int    // offset of the metric's timestamps in the timestamp payload
+	TimestampSize   int    // encoded size of the metric's timestamps in bytes
+	ValueOffset     int    // offset of the metric's values in the value payload
+	ValueSize       int    // encoded size of the metric's values in bytes
+	TagOffset       int    // offset of the metric's tags in the tag payload, 0 without tags
+	TagSize         int    // encoded size of the metric's tags in bytes, 0 without tags
+}
+
+// Size returns the total encoded size of the metric's data points in bytes.
+//
+// Metrics with shared timestamps (see WithSharedTimestamps) report the size of
+// the shared timestamp sequence, which is stored only once.
+func (i IndexEntryInfo) Size() int {
+	return i.TimestampSize + i.ValueSize + i.TagSize
+}
+
+// IndexEntries returns an iterator over the index entries of the blob, in the
+// order the metrics are stored in the value payload.
+//
+// Metrics sharing a timestamp sequence report the same TimestampOffset and
+// TimestampSize.
+//
+// Returns:
+//   - iter.Seq[IndexEntryInfo]: Iterator yielding one entry per metric
+//
+// Example:
+//
+//	for info := range blob.IndexEntries() {
+//	    fmt.Printf("%016x: %d points, %d bytes\n", info.MetricID, info.Count, info.Size())
+//	}
+func (b NumericBlob) IndexEntries() iter.Seq[IndexEntryInfo] {
+	return func(yield func(IndexEntryInfo) bool) {
+		entries := make([]section.NumericIndexEntry, 0, b.MetricCount())
+		b.index.ForEach(func(entry section.NumericIndexEntry) bool {
+			entries = append(entries, entry)
+			return true
+		})
+		slices.SortFunc(entries, func(a, c section.NumericIndexEntry) int {
+			return cmp.Compare(a.ValueOffset, c.ValueOffset)
+		})
+
+		var names map[section.NumericIndexEntry]string
+		if b.HasMetricNames() {
+			names = make(map[section.NumericIndexEntry]string, len(entries))
+			for name, entry := range b.index.nameMap() {
+				names[entry] = name
+			}
+		}
+
+		hasTag := b.HasTag()
+		for _, entry := range entries {
+			info := IndexEntryInfo{
+				MetricID:        entry.MetricID,
+				Name:            names[entry],
+				Count:           entry.Count,
+				TimestampOffset: entry.TimestampOffset,
+				TimestampSize:   entry.TimestampLength,
+				ValueOffset:     entry.ValueOffset,
+				ValueSize:       entry.ValueLength,
+			}
+			if hasTag {
+				info.TagOffset = entry.TagOffset
+				info.TagSize = entry.TagLength
+			}
+
+			if !yield(info) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericBlob_IndexEntries(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		opts := []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithTimestampEncoding(format.TypeRaw),
+			WithValueEncoding(format.TypeRaw),
+		}
+		if v2 {
+			opts = append(opts, WithBlobLayoutV2())
+		}
+		blob := decodeRemapTestBlob(t, encodeTrimTestBlob(t, opts...))
+
+		infos := slices.Collect(blob.IndexEntries())
+		require.Len(t, infos, 3)
+
+		total := 0
+		for i, info := range infos {
+			require.Equal(t, blob.Len(info.MetricID), info.Count)
+			require.Equal(t, info.Count*8, info.TimestampSize)
+			require.Equal(t, info.Count*8, info.ValueSize)
+			require.Empty(t, info.Name)
+			if i > 0 {
+				prev := infos[i-1]
+				require.Equal(t, prev.ValueOffset+prev.ValueSize, info.ValueOffset)
+				require.Equal(t, prev.TimestampOffset+prev.TimestampSize, info.TimestampOffset)
+				require.Equal(t, prev.TagOffset+prev.TagSize, info.TagOffset)
+			}
+			total += info.Size()
+		}
+		require.Equal(t, len(blob.tsPayload)+len(blob.valPayload)+len(blob.tagPayload), total)
+
+		// Stopping early is honored.
+		for range blob.IndexEntries() {
+			break
+		}
+	}
+}
+
+func TestNumericBlob_IndexEntries_SharedTimestamps(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0),
+		WithSharedTimestamps(),
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+	)
+	require.NoError(t, err)
+	for id := uint64(1); id <= 3; id++ {
+		require.NoError(t, encoder.StartMetricID(id, 4))
+		require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3, 4}, []float64{1, 2, 3, float64(id)}, nil))
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	infos := slices.Collect(decodeRemapTestBlob(t, data).IndexEntries())
+	require.Len(t, infos, 3)
+	for i, info := range infos {
+		require.Equal(t, uint64(i+1), info.MetricID)
+		require.Zero(t, info.TimestampOffset)
+		require.Equal(t, 32, info.TimestampSize)
+		require.Equal(t, i*32, info.ValueOffset)
+		require.Zero(t, info.TagSize)
+		require.Equal(t, 64, info.Size())
+	}
+}
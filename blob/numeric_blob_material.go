@@ -75,8 +75,7 @@ func (b NumericBlob) Materialize() MaterializedNumericBlob {
 			timestamps = timestamps[:tsProduced]
 		}
 
-		valBytes := b.valPayload[entry.ValueOffset : entry.ValueOffset+entry.ValueLength]
-		valProduced := b.decodeValuesSlice(valBytes, count, values)
+		valProduced := b.decodeEntryValues(entry, values)
 		values = values[:valProduced]
 
 		var tags []string
@@ -101,8 +100,8 @@ func (b NumericBlob) Materialize() MaterializedNumericBlob {
 	})
 
 	// Copy metric name mappings (if available)
-	if b.index.byName != nil {
-		for name, entry := range b.index.byName {
+	if byName := b.index.nameMap(); byName != nil {
+		for name, entry := range byName {
 			material.names[name] = entry.MetricID
 		}
 	}
@@ -334,8 +333,7 @@ func (b NumericBlob) MaterializeMetric(metricID uint64) (MaterializedNumericMetr
 		timestamps = timestamps[:tsProduced]
 	}
 
-	valBytes := b.valPayload[entry.ValueOffset : entry.ValueOffset+entry.ValueLength]
-	valProduced := b.decodeValuesSlice(valBytes, count, values)
+	valProduced := b.decodeEntryValues(entry, values)
 	values = values[:valProduced]
 
 	var tags []string
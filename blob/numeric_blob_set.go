@@ -21,10 +21,14 @@ import (
 // created, the set cannot be modified. Use value semantics when passing BlobSets
 // to functions.
 //
+// Sets created by NewTieredNumericBlobSet may also hold cold blobs, which are
+// loaded and decoded on access.
+//
 // Example use case: A BlobSet containing hourly blobs for a 24-hour period,
 // where each blob contains metrics with data points for that hour.
 type NumericBlobSet struct {
 	blobs []NumericBlob
+	cold  []*coldBlob // parallel to blobs, non-nil for cold blobs (see NewTieredNumericBlobSet); nil if none
 }
 
 // NewNumericBlobSet creates a new NumericBlobSet from the provided blobs.
@@ -83,7 +87,7 @@ func (s NumericBlobSet) All(metricID uint64) iter.Seq2[int, NumericDataPoint] {
 	return func(yield func(int, NumericDataPoint) bool) {
 		globalIndex := 0
 		for i := range s.blobs {
-			blob := &s.blobs[i]
+			blob := s.blob(i)
 			// Iterate through all data points in this blob for the metric
 			for _, dp := range blob.All(metricID) {
 				if !yield(globalIndex, dp) {
@@ -104,7 +108,7 @@ func (s NumericBlobSet) All(metricID uint64) iter.Seq2[int, NumericDataPoint] {
 func (s NumericBlobSet) AllTimestamps(metricID uint64) iter.Seq[int64] {
 	return func(yield func(int64) bool) {
 		for i := range s.blobs {
-			blob := &s.blobs[i]
+			blob := s.blob(i)
 			for ts := range blob.AllTimestamps(metricID) {
 				if !yield(ts) {
 					return
@@ -123,7 +127,7 @@ func (s NumericBlobSet) AllTimestamps(metricID uint64) iter.Seq[int64] {
 func (s NumericBlobSet) AllValues(metricID uint64) iter.Seq[float64] {
 	return func(yield func(float64) bool) {
 		for i := range s.blobs {
-			blob := &s.blobs[i]
+			blob := s.blob(i)
 			for val := range blob.AllValues(metricID) {
 				if !yield(val) {
 					return
@@ -142,7 +146,7 @@ func (s NumericBlobSet) AllValues(metricID uint64) iter.Seq[float64] {
 func (s NumericBlobSet) AllTags(metricID uint64) iter.Seq[string] {
 	return func(yield func(string) bool) {
 		for i := range s.blobs {
-			blob := &s.blobs[i]
+			blob := s.blob(i)
 			for tag := range blob.AllTags(metricID) {
 				if !yield(tag) {
 					return
@@ -152,6 +156,27 @@ func (s NumericBlobSet) AllTags(metricID uint64) iter.Seq[string] {
 	}
 }
 
+// AllWhere returns an iterator over the data points of the given metric ID
+// across all blobs, in chronological order, for which pred returns true.
+//
+// The index is the global position of the data point across all blobs, as in
+// All. See NumericBlob.AllWhere for how the predicate is pushed down into the
+// decode loop.
+func (s NumericBlobSet) AllWhere(metricID uint64, pred func(ts int64, v float64) bool) iter.Seq2[int, NumericDataPoint] {
+	return func(yield func(int, NumericDataPoint) bool) {
+		offset := 0
+		for i := range s.blobs {
+			blob := s.blob(i)
+			for idx, dp := range blob.AllWhere(metricID, pred) {
+				if !yield(offset+idx, dp) {
+					return
+				}
+			}
+			offset += blob.Len(metricID)
+		}
+	}
+}
+
 // Len returns the number of blobs in the set.
 func (s NumericBlobSet) Len() int {
 	return len(s.blobs)
@@ -161,8 +186,8 @@ func (s NumericBlobSet) Len() int {
 // Returns the start time of the first blob and the start time of the last blob.
 //
 // Note: The actual time range extends beyond the last blob's start time
-// to include its data points. To get the exact end time, you would need
-// to inspect the last timestamp in the last blob.
+// to include its data points. Use NumericBlob.TimeRange on the last blob to
+// get the time of its latest data point.
 func (s NumericBlobSet) TimeRange() (start, end time.Time) {
 	if len(s.blobs) == 0 {
 		return time.Time{}, time.Time{}
@@ -178,12 +203,13 @@ func (s NumericBlobSet) TimeRange() (start, end time.Time) {
 //
 // Returns:
 //   - *NumericBlob: Pointer to the blob, or nil if the index is out of bounds.
+//     A cold blob is loaded; see NewTieredNumericBlobSet.
 func (s NumericBlobSet) BlobAt(index int) *NumericBlob {
 	if index < 0 || index >= len(s.blobs) {
 		return nil
 	}
 
-	return &s.blobs[index]
+	return s.blob(index)
 }
 
 // Blobs returns all blobs in chronological order.
@@ -194,7 +220,7 @@ func (s NumericBlobSet) BlobAt(index int) *NumericBlob {
 //   - []NumericBlob: A copy of the internal blob slice sorted by start time.
 func (s NumericBlobSet) Blobs() []NumericBlob {
 	result := make([]NumericBlob, len(s.blobs))
-	copy(result, s.blobs)
+	copy(result, s.loaded().blobs)
 
 	return result
 }
@@ -220,7 +246,7 @@ func (s NumericBlobSet) ValueAt(metricID uint64, index int) (float64, bool) {
 	// Find which blob contains this index by accumulating counts
 	currentOffset := 0
 	for i := range s.blobs {
-		blob := &s.blobs[i]
+		blob := s.blob(i)
 		blobLen := blob.Len(metricID)
 
 		// Check if index falls within this blob
@@ -260,7 +286,7 @@ func (s NumericBlobSet) TimestampAt(metricID uint64, index int) (int64, bool) {
 	// Find which blob contains this index by accumulating counts
 	currentOffset := 0
 	for i := range s.blobs {
-		blob := &s.blobs[i]
+		blob := s.blob(i)
 		blobLen := blob.Len(metricID)
 
 		// Check if index falls within this blob
@@ -300,7 +326,7 @@ func (s NumericBlobSet) TagAt(metricID uint64, index int) (string, bool) {
 	// Find which blob contains this index by accumulating counts
 	currentOffset := 0
 	for i := range s.blobs {
-		blob := &s.blobs[i]
+		blob := s.blob(i)
 		blobLen := blob.Len(metricID)
 
 		// Check if index falls within this blob
@@ -337,8 +363,8 @@ func (s NumericBlobSet) TagAt(metricID uint64, index int) (string, bool) {
 func (s NumericBlobSet) MetricLen(metricID uint64) int {
 	totalLen := 0
 	for i := range s.blobs {
-		if s.blobs[i].HasMetricID(metricID) {
-			totalLen += s.blobs[i].Len(metricID)
+		if blob := s.blob(i); blob.HasMetricID(metricID) {
+			totalLen += blob.Len(metricID)
 		}
 	}
 
@@ -363,8 +389,8 @@ func (s NumericBlobSet) MetricLen(metricID uint64) int {
 func (s NumericBlobSet) MetricLenByName(metricName string) int {
 	totalLen := 0
 	for i := range s.blobs {
-		if s.blobs[i].HasMetricName(metricName) {
-			totalLen += s.blobs[i].LenByName(metricName)
+		if blob := s.blob(i); blob.HasMetricName(metricName) {
+			totalLen += blob.LenByName(metricName)
 		}
 	}
 
@@ -389,7 +415,7 @@ func (s NumericBlobSet) MetricLenByName(metricName string) int {
 //	duration := blobSet.MetricDuration(metricID)
 //	fmt.Printf("Metric spans %d timestamp units\n", duration)
 func (s NumericBlobSet) MetricDuration(metricID uint64) int64 {
-	return calculateDuration(s.blobs, metricID)
+	return calculateDuration(s.loaded().blobs, metricID)
 }
 
 // MetricDurationByName calculates the time span for the given metric name across all blobs.
@@ -410,5 +436,5 @@ func (s NumericBlobSet) MetricDuration(metricID uint64) int64 {
 //	duration := blobSet.MetricDurationByName("cpu.usage")
 //	fmt.Printf("Metric spans %d timestamp units\n", duration)
 func (s NumericBlobSet) MetricDurationByName(metricName string) int64 {
-	return calculateDurationByName(s.blobs, metricName)
+	return calculateDurationByName(s.loaded().blobs, nil, metricName)
 }
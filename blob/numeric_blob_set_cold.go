@@ -0,0 +1,206 @@
+package blob
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// ColdNumericBlob references an encoded numeric blob that a NumericBlobSet loads
+// and decodes on first access, e.g. a historical blob kept in an object store.
+type ColdNumericBlob struct {
+	// StartTime is the start time of the blob. The set orders blobs by it without
+	// loading them, so it must match the start time of the encoded blob.
+	StartTime time.Time
+
+	// Load returns the encoded blob. It may be called concurrently, and again
+	// after a failure or for every access if Cache is false.
+	Load func() ([]byte, error)
+
+	// Cache keeps the decoded blob after the first successful load. Without it,
+	// every access loads and decodes the blob again, and the decoded blob is
+	// released once the access completes.
+	Cache bool
+}
+
+// coldBlob is the load state of a ColdNumericBlob shared by all copies of a set.
+type coldBlob struct {
+	ref ColdNumericBlob
+
+	mu     sync.Mutex
+	cached *NumericBlob // decoded blob, nil until loaded with ref.Cache
+	err    error        // error of the latest failed load, nil after a successful one
+}
+
+// NewTieredNumericBlobSet creates a NumericBlobSet from decoded (warm) blobs and
+// cold blob references, so huge historical sets do not need to be decoded, or
+// even held in memory, upfront.
+//
+// Cold blobs are loaded and decoded on first access. A blob that fails to load
+// or decode reads as a blob without metrics; LoadErr reports the failures.
+// Blobs, Materialize and the MetricDuration methods need all blobs at once and
+// load every cold blob, the other methods load one blob at a time.
+//
+// Parameters:
+//   - warm: Decoded blobs
+//   - cold: References to encoded blobs, each with a non-nil Load function
+//
+// Returns:
+//   - NumericBlobSet: A blob set with all blobs sorted by start time
+//   - error: ErrEmptyBlobSet if both slices are empty, or an error for a cold
+//     blob without Load function
+//
+// Example:
+//
+//	cold := make([]blob.ColdNumericBlob, 0, len(keys))
+//	for _, key := range keys {
+//	    cold = append(cold, blob.ColdNumericBlob{
+//	        StartTime: startTimeOf(key),
+//	        Load:      func() ([]byte, error) { return store.Get(ctx, key) },
+//	    })
+//	}
+//	set, err := blob.NewTieredNumericBlobSet(recentBlobs, cold)
+func NewTieredNumericBlobSet(warm []NumericBlob, cold []ColdNumericBlob) (NumericBlobSet, error) {
+	if len(warm) == 0 && len(cold) == 0 {
+		return NumericBlobSet{}, errs.ErrEmptyBlobSet
+	}
+
+	type slot struct {
+		blob NumericBlob
+		cold *coldBlob
+	}
+
+	slots := make([]slot, 0, len(warm)+len(cold))
+	for _, blob := range warm {
+		slots = append(slots, slot{blob: blob})
+	}
+	for i, ref := range cold {
+		if ref.Load == nil {
+			return NumericBlobSet{}, fmt.Errorf("cold blob %d has no Load function", i)
+		}
+
+		// The placeholder only carries the start time, for ordering and TimeRange
+		var placeholder NumericBlob
+		placeholder.startTimeMicros = ref.StartTime.UnixMicro()
+		slots = append(slots, slot{blob: placeholder, cold: &coldBlob{ref: ref}})
+	}
+
+	slices.SortFunc(slots, func(a, b slot) int {
+		return cmp.Compare(a.blob.startTimeMicros, b.blob.startTimeMicros)
+	})
+
+	set := NumericBlobSet{
+		blobs: make([]NumericBlob, len(slots)),
+	}
+	if len(cold) > 0 {
+		set.cold = make([]*coldBlob, len(slots))
+	}
+	for i, s := range slots {
+		set.blobs[i] = s.blob
+		if set.cold != nil {
+			set.cold[i] = s.cold
+		}
+	}
+
+	return set, nil
+}
+
+// LoadErr returns the errors of cold blobs whose latest load or decode failed,
+// joined, or nil if every access so far succeeded.
+func (s NumericBlobSet) LoadErr() error {
+	var loadErrs []error
+	for _, c := range s.cold {
+		if c == nil {
+			continue
+		}
+
+		c.mu.Lock()
+		if c.err != nil {
+			loadErrs = append(loadErrs, c.err)
+		}
+		c.mu.Unlock()
+	}
+
+	return errors.Join(loadErrs...)
+}
+
+// blob returns the blob at index i, loading a cold blob. A cold blob that fails
+// to load is returned as an empty blob.
+func (s NumericBlobSet) blob(i int) *NumericBlob {
+	if s.cold == nil || s.cold[i] == nil {
+		return &s.blobs[i]
+	}
+
+	blob, err := s.cold[i].load()
+	if err != nil {
+		return &NumericBlob{}
+	}
+
+	return blob
+}
+
+// loaded returns the set with every cold blob loaded, or s itself if it has none.
+func (s *NumericBlobSet) loaded() *NumericBlobSet {
+	if s.cold == nil {
+		return s
+	}
+
+	blobs := make([]NumericBlob, len(s.blobs))
+	for i := range s.blobs {
+		blobs[i] = *s.blob(i)
+	}
+
+	return &NumericBlobSet{blobs: blobs}
+}
+
+// load returns the decoded blob, from the cache if enabled.
+func (c *coldBlob) load() (*NumericBlob, error) {
+	if !c.ref.Cache {
+		blob, err := c.decode()
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+
+		return blob, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached != nil {
+		return c.cached, nil
+	}
+
+	blob, err := c.decode()
+	c.err = err
+	if err == nil {
+		c.cached = blob
+	}
+
+	return blob, err
+}
+
+// decode loads and decodes the referenced blob.
+func (c *coldBlob) decode() (*NumericBlob, error) {
+	data, err := c.ref.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cold blob starting at %s: %w", c.ref.StartTime, err)
+	}
+
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cold blob starting at %s: %w", c.ref.StartTime, err)
+	}
+
+	blob, err := decoder.Decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cold blob starting at %s: %w", c.ref.StartTime, err)
+	}
+
+	return &blob, nil
+}
@@ -0,0 +1,97 @@
+package blob
+
+import (
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func encodeColdTestBlob(t *testing.T, start time.Time, values ...float64) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, len(values)))
+	for i, v := range values {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), v, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNewTieredNumericBlobSet(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	hour1, hour2, hour3 := base.Add(time.Hour), base.Add(2*time.Hour), base.Add(3*time.Hour)
+
+	warm, err := NewNumericDecoder(encodeColdTestBlob(t, hour2, 3, 4))
+	require.NoError(t, err)
+	warmBlob, err := warm.Decode()
+	require.NoError(t, err)
+
+	var cachedLoads, uncachedLoads atomic.Int32
+	cachedData := encodeColdTestBlob(t, hour1, 1, 2)
+	uncachedData := encodeColdTestBlob(t, hour3, 5)
+
+	set, err := NewTieredNumericBlobSet([]NumericBlob{warmBlob}, []ColdNumericBlob{
+		{StartTime: hour3, Load: func() ([]byte, error) { uncachedLoads.Add(1); return uncachedData, nil }},
+		{StartTime: hour1, Load: func() ([]byte, error) { cachedLoads.Add(1); return cachedData, nil }, Cache: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, set.Len())
+
+	// Ordering and TimeRange need no loads.
+	start, end := set.TimeRange()
+	require.Equal(t, hour1, start)
+	require.Equal(t, hour3, end)
+	require.Zero(t, cachedLoads.Load())
+	require.Zero(t, uncachedLoads.Load())
+
+	require.Equal(t, []float64{1, 2, 3, 4, 5}, slices.Collect(set.AllValues(1)))
+	val, ok := set.ValueAt(1, 4)
+	require.True(t, ok)
+	require.Equal(t, 5.0, val)
+	require.Equal(t, 5, set.MetricLen(1))
+
+	var sum float64
+	require.True(t, set.ForEachValues(1, func(_ int, v float64) bool { sum += v; return true }))
+	require.Equal(t, 15.0, sum)
+
+	material := set.Materialize()
+	require.Equal(t, 5, material.DataPointCount(1))
+
+	// The cached blob was loaded once, the uncached one on every access.
+	require.Equal(t, int32(1), cachedLoads.Load())
+	require.Greater(t, uncachedLoads.Load(), int32(1))
+	require.NoError(t, set.LoadErr())
+}
+
+func TestNewTieredNumericBlobSet_LoadError(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	errUnavailable := errors.New("store unavailable")
+
+	set, err := NewTieredNumericBlobSet(nil, []ColdNumericBlob{
+		{StartTime: base, Load: func() ([]byte, error) { return encodeColdTestBlob(t, base, 1), nil }},
+		{StartTime: base.Add(time.Hour), Load: func() ([]byte, error) { return nil, errUnavailable }, Cache: true},
+	})
+	require.NoError(t, err)
+
+	// A failed blob reads as a blob without metrics.
+	require.Equal(t, []float64{1}, slices.Collect(set.AllValues(1)))
+	require.Zero(t, set.BlobAt(1).MetricCount())
+	require.ErrorIs(t, set.LoadErr(), errUnavailable)
+
+	_, err = NewTieredNumericBlobSet(nil, nil)
+	require.ErrorIs(t, err, errs.ErrEmptyBlobSet)
+	_, err = NewTieredNumericBlobSet(nil, []ColdNumericBlob{{StartTime: base}})
+	require.Error(t, err)
+}
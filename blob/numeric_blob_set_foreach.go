@@ -29,7 +29,7 @@ package blob
 // per-call adapter closure allocates — the generic form keeps the same
 // allocation count and wall time as a hand-written method.
 func forEachAcrossBlobs[K, T any](
-	blobs []NumericBlob,
+	s NumericBlobSet,
 	key K,
 	yield func(int, T) bool,
 	perBlob func(b NumericBlob, key K, adapter func(int, T) bool) bool,
@@ -54,8 +54,8 @@ func forEachAcrossBlobs[K, T any](
 		return true
 	}
 
-	for i := range blobs {
-		if perBlob(blobs[i], key, adapter) {
+	for i := range s.blobs {
+		if perBlob(*s.blob(i), key, adapter) {
 			found = true
 		}
 		if stopped {
@@ -72,7 +72,7 @@ func forEachAcrossBlobs[K, T any](
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEach(metricID uint64, yield func(idx int, dp NumericDataPoint) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricID, yield, NumericBlob.ForEach)
+	return forEachAcrossBlobs(s, metricID, yield, NumericBlob.ForEach)
 }
 
 // ForEachByName calls yield for each data point of the given metric name across
@@ -82,7 +82,7 @@ func (s NumericBlobSet) ForEach(metricID uint64, yield func(idx int, dp NumericD
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEachByName(metricName string, yield func(idx int, dp NumericDataPoint) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricName, yield, NumericBlob.ForEachByName)
+	return forEachAcrossBlobs(s, metricName, yield, NumericBlob.ForEachByName)
 }
 
 // ForEachValues calls yield for each value of the given metric ID across all
@@ -91,7 +91,7 @@ func (s NumericBlobSet) ForEachByName(metricName string, yield func(idx int, dp
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEachValues(metricID uint64, yield func(idx int, val float64) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricID, yield, NumericBlob.ForEachValues)
+	return forEachAcrossBlobs(s, metricID, yield, NumericBlob.ForEachValues)
 }
 
 // ForEachValuesByName calls yield for each value of the given metric name across
@@ -101,7 +101,7 @@ func (s NumericBlobSet) ForEachValues(metricID uint64, yield func(idx int, val f
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEachValuesByName(metricName string, yield func(idx int, val float64) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricName, yield, NumericBlob.ForEachValuesByName)
+	return forEachAcrossBlobs(s, metricName, yield, NumericBlob.ForEachValuesByName)
 }
 
 // ForEachTimestamps calls yield for each timestamp of the given metric ID across
@@ -110,7 +110,7 @@ func (s NumericBlobSet) ForEachValuesByName(metricName string, yield func(idx in
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEachTimestamps(metricID uint64, yield func(idx int, ts int64) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricID, yield, NumericBlob.ForEachTimestamps)
+	return forEachAcrossBlobs(s, metricID, yield, NumericBlob.ForEachTimestamps)
 }
 
 // ForEachTimestampsByName calls yield for each timestamp of the given metric
@@ -121,5 +121,5 @@ func (s NumericBlobSet) ForEachTimestamps(metricID uint64, yield func(idx int, t
 //
 // Returns false if the metric is absent from every blob, or if yield is nil.
 func (s NumericBlobSet) ForEachTimestampsByName(metricName string, yield func(idx int, ts int64) bool) bool {
-	return forEachAcrossBlobs(s.blobs, metricName, yield, NumericBlob.ForEachTimestampsByName)
+	return forEachAcrossBlobs(s, metricName, yield, NumericBlob.ForEachTimestampsByName)
 }
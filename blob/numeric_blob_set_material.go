@@ -30,6 +30,8 @@ type materializedNumericMetricSet struct {
 	timestamps []int64   // All timestamps from all blobs, concatenated
 	values     []float64 // All values from all blobs, concatenated
 	tags       []string  // All tags from all blobs, concatenated (empty if tags disabled)
+	timeOrder  []int     // Global indices sorted by timestamp (nil if timestamps are sorted)
+	timeIndex  bool      // Whether timeOrder was built (WithTimeIndex)
 }
 
 // Materialize decodes all metrics from all blobs in the set and returns a
@@ -52,7 +54,11 @@ type materializedNumericMetricSet struct {
 //	// Access any data point across all blobs in O(1) time
 //	val, ok := material.ValueAt(metricID, 1500)  // Could be in blob 2
 //	ts, ok := material.TimestampAt(metricID, 2500) // Could be in blob 3
-func (s *NumericBlobSet) Materialize() MaterializedNumericBlobSet {
+func (s *NumericBlobSet) Materialize(opts ...MaterializeOption) MaterializedNumericBlobSet {
+	s = s.loaded()
+
+	cfg := newMaterializeConfig(opts)
+
 	if len(s.blobs) == 0 {
 		return MaterializedNumericBlobSet{
 			data:  make(map[uint64]materializedNumericMetricSet),
@@ -114,10 +120,9 @@ func (s *NumericBlobSet) Materialize() MaterializedNumericBlobSet {
 			}
 
 			// Decode values: extend slice and decode directly into tail
-			valBytes := blob.valPayload[entry.ValueOffset : entry.ValueOffset+entry.ValueLength]
 			valOff := len(metricSet.values)
 			metricSet.values = metricSet.values[:valOff+count]
-			valProduced := blob.decodeValuesSlice(valBytes, count, metricSet.values[valOff:])
+			valProduced := blob.decodeEntryValues(entry, metricSet.values[valOff:])
 			metricSet.values = metricSet.values[:valOff+valProduced]
 
 			// Align timestamps to actual values produced (defensive against short-decode)
@@ -147,13 +152,21 @@ func (s *NumericBlobSet) Materialize() MaterializedNumericBlobSet {
 	// Step 5: Build metric name mapping if available
 	for i := range s.blobs {
 		blob := &s.blobs[i]
-		if blob.index.byName != nil {
-			for name, entry := range blob.index.byName {
+		if byName := blob.index.nameMap(); byName != nil {
+			for name, entry := range byName {
 				material.names[name] = entry.MetricID
 			}
 		}
 	}
 
+	if cfg.TimeIndex {
+		for metricID, metricSet := range material.data {
+			metricSet.timeOrder = buildTimeOrder(metricSet.timestamps)
+			metricSet.timeIndex = true
+			material.data[metricID] = metricSet
+		}
+	}
+
 	return material
 }
 
@@ -184,6 +197,8 @@ func (s *NumericBlobSet) Materialize() MaterializedNumericBlobSet {
 //	    ts, _ := metric.TimestampAt(250)   // O(1) access
 //	}
 func (s *NumericBlobSet) MaterializeMetric(metricID uint64) (MaterializedNumericMetric, bool) {
+	s = s.loaded()
+
 	// Step 1: Check if metric exists in any blob and calculate total capacity
 	capacity := 0
 	for i := range s.blobs {
@@ -237,10 +252,9 @@ func (s *NumericBlobSet) MaterializeMetric(metricID uint64) (MaterializedNumeric
 		}
 
 		// Decode values: extend slice and decode directly into tail
-		valBytes := blob.valPayload[entry.ValueOffset : entry.ValueOffset+entry.ValueLength]
 		valOff := len(values)
 		values = values[:valOff+count]
-		valProduced := blob.decodeValuesSlice(valBytes, count, values[valOff:])
+		valProduced := blob.decodeEntryValues(entry, values[valOff:])
 		values = values[:valOff+valProduced]
 
 		// Align timestamps to actual values produced (defensive against short-decode)
@@ -297,6 +311,8 @@ func (s *NumericBlobSet) MaterializeMetric(metricID uint64) (MaterializedNumeric
 //	    ts, _ := metric.TimestampAt(250)   // O(1) access
 //	}
 func (s *NumericBlobSet) MaterializeMetricByName(metricName string) (MaterializedNumericMetric, bool) {
+	s = s.loaded()
+
 	// Step 1: Find the metric ID from the first blob that has this name
 	var metricID uint64
 	found := false
@@ -435,6 +451,40 @@ func (m MaterializedNumericBlobSet) DataPointCountByName(metricName string) int
 	return m.DataPointCount(metricID)
 }
 
+// SearchTime returns the global index of the earliest data point of the given metric
+// ID whose timestamp is at or after ts. Among equal timestamps the lowest global index
+// is returned. Returns (0, false) if the metric ID is not found or no data point is at
+// or after ts.
+//
+// With WithTimeIndex this is an O(log n) binary search that handles overlapping blobs;
+// otherwise the metric's timestamps are scanned linearly.
+//
+// Example:
+//
+//	material := blobSet.Materialize(WithTimeIndex())
+//	if idx, ok := material.SearchTime(metricID, from); ok {
+//	    val, _ := material.ValueAt(metricID, idx)
+//	}
+func (m MaterializedNumericBlobSet) SearchTime(metricID uint64, ts int64) (int, bool) {
+	metric, ok := m.data[metricID]
+	if !ok {
+		return 0, false
+	}
+
+	return searchTime(metric.timestamps, metric.timeOrder, metric.timeIndex, ts)
+}
+
+// SearchTimeByName returns the global index of the earliest data point of the given
+// metric name whose timestamp is at or after ts. See SearchTime for details.
+func (m MaterializedNumericBlobSet) SearchTimeByName(metricName string, ts int64) (int, bool) {
+	metricID, ok := m.names[metricName]
+	if !ok {
+		return 0, false
+	}
+
+	return m.SearchTime(metricID, ts)
+}
+
 // MetricCount returns the number of unique metrics in the materialized blob set.
 func (m MaterializedNumericBlobSet) MetricCount() int {
 	return len(m.data)
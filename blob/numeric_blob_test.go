@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/arloliu/mebo/encoding"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/hash"
 	"github.com/arloliu/mebo/section"
@@ -3139,3 +3140,108 @@ func testOffsetBoundary(t *testing.T, numMetrics, _ int, encoding format.Encodin
 		require.True(t, ok, "Expected random access to succeed")
 	}
 }
+
+func TestNumericBlob_ValuesAt(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Raw", opts: []NumericEncoderOption{WithValueEncoding(format.TypeRaw)}},
+		{name: "Gorilla", opts: []NumericEncoderOption{WithValueEncoding(format.TypeGorilla)}},
+		{name: "Chimp", opts: []NumericEncoderOption{WithValueEncoding(format.TypeChimp)}},
+		{name: "ALP", opts: []NumericEncoderOption{WithValueEncoding(format.TypeALP)}},
+	}
+
+	indexes := []int{9, 0, 5, 5, 3}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, tt.opts...)
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			for id := uint64(1); id <= 3; id++ {
+				expected := make([]float64, len(indexes))
+				for i, index := range indexes {
+					v, ok := blob.ValueAt(id, index)
+					require.True(t, ok)
+					expected[i] = v
+				}
+
+				values, ok := blob.ValuesAt(id, indexes)
+				require.True(t, ok)
+				require.Equal(t, expected, values)
+			}
+
+			values, ok := blob.ValuesAt(1, nil)
+			require.True(t, ok)
+			require.Empty(t, values)
+
+			_, ok = blob.ValuesAt(1, []int{0, 10})
+			require.False(t, ok, "out-of-bounds index should fail the whole batch")
+			_, ok = blob.ValuesAt(1, []int{-1})
+			require.False(t, ok)
+			_, ok = blob.ValuesAt(99, []int{0})
+			require.False(t, ok)
+		})
+	}
+
+	t.Run("ByNameAndTransform", func(t *testing.T) {
+		startTime := time.Now()
+		encoder, err := NewNumericEncoder(startTime, WithValueEncoding(format.TypeGorilla))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricName("cpu", 4))
+		for i := range 4 {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data, WithValueTransform(func(_ uint64, v float64) float64 { return v * 10 }))
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		values, ok := blob.ValuesAtByName("cpu", []int{3, 1})
+		require.True(t, ok)
+		require.Equal(t, []float64{30, 10}, values)
+
+		_, ok = blob.ValuesAtByName("missing", []int{0})
+		require.False(t, ok)
+	})
+}
+
+func TestNumericBlob_Capabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		tsEnc    format.EncodingType
+		valEnc   format.EncodingType
+		tsRandom bool
+		vRandom  bool
+	}{
+		{name: "Raw-Raw", tsEnc: format.TypeRaw, valEnc: format.TypeRaw, tsRandom: true, vRandom: true},
+		{name: "Delta-Gorilla", tsEnc: format.TypeDelta, valEnc: format.TypeGorilla},
+		{name: "DeltaPacked-Chimp", tsEnc: format.TypeDeltaPacked, valEnc: format.TypeChimp},
+		{name: "Delta-ALP", tsEnc: format.TypeDelta, valEnc: format.TypeALP, vRandom: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob, metricIDs := buildForEachTestBlob(t, tt.tsEnc, tt.valEnc, false)
+
+			require.Equal(t, encoding.Capabilities{RandomAccess: tt.tsRandom, Reverse: tt.tsRandom}, blob.TimestampCapabilities())
+			require.Equal(t, encoding.Capabilities{RandomAccess: tt.vRandom, Reverse: tt.vRandom}, blob.ValueCapabilities())
+
+			values, ok := blob.ValuesAt(metricIDs[0], []int{10, 2, 49})
+			require.True(t, ok)
+			for i, index := range []int{10, 2, 49} {
+				v, ok := blob.ValueAt(metricIDs[0], index)
+				require.True(t, ok)
+				require.Equal(t, v, values[i])
+			}
+		})
+	}
+}
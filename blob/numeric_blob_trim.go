@@ -0,0 +1,267 @@
+package blob
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// Clone returns a deep copy of the blob that shares no memory with it or with
+// the encoded bytes it was decoded from.
+//
+// Decoded blobs reference the input bytes of uncompressed payloads, so a cache
+// holding a blob keeps the whole input alive. Cloning lets the caller release
+// or reuse the input buffer.
+//
+// Returns:
+//   - NumericBlob: Independent copy of the blob
+//
+// Example:
+//
+//	blob, _ := decoder.Decode()
+//	cache.Put(key, blob.Clone())
+//	bufferPool.Put(data) // data may now be reused
+func (b NumericBlob) Clone() NumericBlob {
+	c := b
+	c.index = b.index.clone()
+	c.tsPayload = slices.Clone(b.tsPayload)
+	c.valPayload = slices.Clone(b.valPayload)
+	c.tagPayload = slices.Clone(b.tagPayload)
+	c.metadata = cloneMetadata(b.metadata)
+	c.metricMeta = maps.Clone(b.metricMeta)
+
+	if b.sharedTsCache != nil {
+		c.sharedTsCache = make(map[int][]int64, len(b.sharedTsCache))
+		for offset, timestamps := range b.sharedTsCache {
+			c.sharedTsCache[offset] = slices.Clone(timestamps)
+		}
+	}
+
+	if b.refValCache != nil {
+		c.refValCache = make(map[uint64][]float64, len(b.refValCache))
+		for metricID, values := range b.refValCache {
+			c.refValCache[metricID] = slices.Clone(values)
+		}
+	}
+
+	return c
+}
+
+// Trim returns a new blob holding only the data points with timestamps in
+// [start, end), re-encoded with the blob's encodings. Metrics without data
+// points in the window are dropped.
+//
+// The result is independent of the blob, like Clone, and usually much smaller,
+// so caches can hold hot windows instead of full blobs. Payloads are stored
+// uncompressed, since the trimmed blob is only held in memory. Metric names,
+// metric metadata, tags, byte order, layout, timestamp unit, time bounds,
+// sketches, tag summaries, value sums and value precision or quantization are
+// preserved; metrics stored as deltas against a reference metric are stored
+// with their plain values.
+//
+// Parameters:
+//   - start: Inclusive start of the window
+//   - end: Exclusive end of the window
+//
+// Returns:
+//   - NumericBlob: Blob with the data points of the window
+//   - error: ErrInvalidTimeRange if end is not after start, ErrNoMetricsAdded if
+//     no data point falls in the window, ErrUnsupportedBlobFeature for values
+//     with an extension encoding, ValuesErr if the blob has no values, or
+//     encoding errors
+//
+// Example:
+//
+//	// Keep the last 5 minutes of an hour blob in the cache
+//	hot, err := blob.Trim(now.Add(-5*time.Minute), now)
+func (b NumericBlob) Trim(start, end time.Time) (NumericBlob, error) {
+	if !end.After(start) {
+		return NumericBlob{}, fmt.Errorf("%w: %s to %s", errs.ErrInvalidTimeRange, start, end)
+	}
+
+	if b.valEncType == format.TypeExtension {
+		return NumericBlob{}, fmt.Errorf("%w: extension value encoding", errs.ErrUnsupportedBlobFeature)
+	}
+	if b.valuesErr != nil {
+		return NumericBlob{}, b.valuesErr
+	}
+
+	unit := b.TimestampUnit()
+	lo, hi := unit.Timestamp(start), unit.Timestamp(end)
+
+	// Iterate stored values; the decode-time transform is carried over instead.
+	raw := b
+	raw.valTransform = nil
+
+	var names map[section.NumericIndexEntry]string
+	if b.HasMetricNames() {
+		names = make(map[section.NumericIndexEntry]string)
+		for name, entry := range b.index.nameMap() {
+			names[entry] = name
+		}
+	}
+
+	metrics := make([]trimmedMetric, 0, b.MetricCount())
+	first := hi
+	b.index.ForEach(func(entry section.NumericIndexEntry) bool {
+		m := trimmedMetric{metricID: entry.MetricID, name: names[entry]}
+		for _, dp := range raw.allFromEntry(entry) {
+			if dp.Ts < lo || dp.Ts >= hi {
+				continue
+			}
+
+			m.timestamps = append(m.timestamps, dp.Ts)
+			m.values = append(m.values, dp.Val)
+			m.tags = append(m.tags, dp.Tag)
+			first = min(first, dp.Ts)
+		}
+
+		if len(m.timestamps) > 0 {
+			metrics = append(metrics, m)
+		}
+
+		return true
+	})
+
+	data, err := b.encodeTrimmed(unit.Time(first), metrics)
+	if err != nil {
+		return NumericBlob{}, err
+	}
+
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		return NumericBlob{}, err
+	}
+
+	trimmed, err := decoder.Decode()
+	if err != nil {
+		return NumericBlob{}, err
+	}
+	trimmed.valTransform = b.valTransform
+	trimmed.interner = b.interner
+
+	return trimmed, nil
+}
+
+// trimmedMetric holds the data points of one metric kept by Trim.
+type trimmedMetric struct {
+	metricID   uint64
+	name       string // empty unless the blob stores metric names
+	timestamps []int64
+	values     []float64
+	tags       []string
+}
+
+// encodeTrimmed encodes the metrics with the blob's encodings and settings.
+func (b NumericBlob) encodeTrimmed(startTime time.Time, metrics []trimmedMetric) ([]byte, error) {
+	encoder, err := NewNumericEncoder(startTime, b.trimEncoderOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		meta := b.metricMeta[m.metricID]
+		if m.name != "" {
+			err = encoder.StartMetricNameWithMeta(m.name, len(m.timestamps), meta)
+		} else {
+			err = encoder.StartMetricIDWithMeta(m.metricID, len(m.timestamps), meta)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		tags := m.tags
+		if !b.HasTag() {
+			tags = nil
+		}
+		if err := encoder.AddDataPoints(m.timestamps, m.values, tags); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// trimEncoderOptions returns encoder options that reproduce the blob's
+// encodings and settings without compression.
+func (b NumericBlob) trimEncoderOptions() []NumericEncoderOption {
+	opts := []NumericEncoderOption{
+		WithTimestampEncoding(b.tsEncType),
+		WithValueEncoding(b.valEncType),
+		WithTimestampCompression(format.CompressionNone),
+		WithValueCompression(format.CompressionNone),
+		WithTagCompression(format.CompressionNone),
+		WithTagsEnabled(b.HasTag()),
+	}
+
+	if b.IsBigEndian() {
+		opts = append(opts, WithBigEndian())
+	}
+	switch {
+	case b.sharedTsCache != nil:
+		opts = append(opts, WithSharedTimestamps())
+	case b.IsV2Layout():
+		opts = append(opts, WithBlobLayoutV2())
+	case b.index.resolve().sorted != nil:
+		opts = append(opts, WithSortedIndex(true))
+	}
+	if unit := b.TimestampUnit(); unit != format.TimeUnitMicrosecond {
+		opts = append(opts, WithTimestampUnit(unit))
+	}
+	if b.HasTimeBounds() {
+		opts = append(opts, WithTimeBounds(true))
+	}
+	if b.HasSketches() {
+		opts = append(opts, WithSketches(true))
+	}
+	if b.HasTagSummaries() {
+		opts = append(opts, WithTagSummaries(true))
+	}
+	if b.HasValueSums() {
+		opts = append(opts, WithValueSums(true))
+	}
+	if decimals, ok := b.ValuePrecision(); ok {
+		opts = append(opts, WithValuePrecision(decimals))
+	} else if step, ok := b.QuantizationStep(); ok {
+		opts = append(opts, WithQuantization(step))
+	}
+
+	return opts
+}
+
+// cloneMetadata returns a copy of md whose record values share no memory with it.
+func cloneMetadata(md section.Metadata) section.Metadata {
+	if md.Records == nil {
+		return md
+	}
+
+	records := make([]section.MetadataRecord, len(md.Records))
+	for i, r := range md.Records {
+		records[i] = section.MetadataRecord{Key: r.Key, Value: slices.Clone(r.Value)}
+	}
+
+	return section.Metadata{Records: records}
+}
+
+// clone returns a built copy of the index that shares no memory with m. Deferred
+// indexes and compressed metric names are resolved first.
+func (m indexMaps[T]) clone() indexMaps[T] {
+	byName := m.nameMap()
+	m = m.resolve()
+
+	return indexMaps[T]{
+		byID:      maps.Clone(m.byID),
+		byName:    maps.Clone(byName),
+		sorted:    slices.Clone(m.sorted),
+		sortedIDs: slices.Clone(m.sortedIDs),
+	}
+}
@@ -0,0 +1,139 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+var trimTestStart = time.Unix(1700000000, 0)
+
+// encodeTrimTestBlob encodes three metrics with one point per second: metric 1
+// for 60s with tags, metric 2 for 60s with metadata, and metric 3 for the first 5s.
+func encodeTrimTestBlob(t *testing.T, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(trimTestStart, append([]NumericEncoderOption{WithTagsEnabled(true)}, opts...)...)
+	require.NoError(t, err)
+
+	addMetric := func(start func() error, points int, tag string) {
+		require.NoError(t, start())
+		for i := range points {
+			ts := trimTestStart.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(i)+0.5, tag))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	addMetric(func() error { return encoder.StartMetricID(1, 60) }, 60, "host=a")
+	addMetric(func() error {
+		return encoder.StartMetricIDWithMeta(2, 60, MetricMeta{Kind: format.MetricKindCounter, Unit: "bytes"})
+	}, 60, "")
+	addMetric(func() error { return encoder.StartMetricID(3, 5) }, 5, "")
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericBlob_Clone(t *testing.T) {
+	data := encodeTrimTestBlob(t,
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+		WithTagCompression(format.CompressionNone),
+	)
+
+	for _, lazy := range []bool{false, true} {
+		input := slices.Clone(data)
+		decoder, err := NewNumericDecoder(input, WithLazyIndex(lazy))
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		clone := blob.Clone()
+
+		// Overwriting the input corrupts the original blob but not the clone.
+		clear(input)
+
+		require.Equal(t, 3, clone.MetricCount())
+		require.Equal(t, []float64{0.5, 1.5, 2.5, 3.5, 4.5}, slices.Collect(clone.AllValues(3)))
+		tag, ok := clone.TagAt(1, 59)
+		require.True(t, ok)
+		require.Equal(t, "host=a", tag)
+		meta, ok := clone.MetricMeta(2)
+		require.True(t, ok)
+		require.Equal(t, "bytes", meta.Unit)
+		require.Equal(t, blob.StartTime(), clone.StartTime())
+	}
+}
+
+func TestNumericBlob_Trim(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		opts := []NumericEncoderOption{WithTimestampCompression(format.CompressionZstd)}
+		if v2 {
+			opts = append(opts, WithBlobLayoutV2())
+		}
+		blob := decodeRemapTestBlob(t, encodeTrimTestBlob(t, opts...))
+
+		trimmed, err := blob.Trim(trimTestStart.Add(10*time.Second), trimTestStart.Add(20*time.Second))
+		require.NoError(t, err)
+
+		// Metric 3 has no points in the window.
+		require.Equal(t, 2, trimmed.MetricCount())
+		require.False(t, trimmed.HasMetricID(3))
+		require.Equal(t, trimTestStart.Add(10*time.Second).UTC(), trimmed.StartTime())
+		require.Equal(t, v2, trimmed.IsV2Layout())
+
+		var want []float64
+		for i := 10; i < 20; i++ {
+			want = append(want, float64(i)+0.5)
+		}
+		require.Equal(t, want, slices.Collect(trimmed.AllValues(1)))
+		require.Equal(t, want, slices.Collect(trimmed.AllValues(2)))
+
+		ts, ok := trimmed.TimestampAt(2, 9)
+		require.True(t, ok)
+		require.Equal(t, trimTestStart.Add(19*time.Second).UnixMicro(), ts)
+
+		tag, ok := trimmed.TagAt(1, 0)
+		require.True(t, ok)
+		require.Equal(t, "host=a", tag)
+
+		meta, ok := trimmed.MetricMeta(2)
+		require.True(t, ok)
+		require.Equal(t, MetricMeta{Kind: format.MetricKindCounter, Unit: "bytes"}, meta)
+	}
+}
+
+func TestNumericBlob_Trim_TimestampUnit(t *testing.T) {
+	encoder, err := NewNumericEncoder(trimTestStart, WithTimestampUnit(format.TimeUnitMillisecond))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 4))
+	for i := range 4 {
+		require.NoError(t, encoder.AddDataPoint(trimTestStart.Add(time.Duration(i)*time.Second).UnixMilli(), float64(i), ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	trimmed, err := decodeRemapTestBlob(t, data).Trim(trimTestStart.Add(time.Second), trimTestStart.Add(3*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, format.TimeUnitMillisecond, trimmed.TimestampUnit())
+	require.Equal(t, []float64{1, 2}, slices.Collect(trimmed.AllValues(1)))
+}
+
+func TestNumericBlob_Trim_Errors(t *testing.T) {
+	blob := decodeRemapTestBlob(t, encodeTrimTestBlob(t))
+
+	_, err := blob.Trim(trimTestStart.Add(time.Minute), trimTestStart)
+	require.ErrorIs(t, err, errs.ErrInvalidTimeRange)
+
+	_, err = blob.Trim(trimTestStart.Add(time.Hour), trimTestStart.Add(2*time.Hour))
+	require.ErrorIs(t, err, errs.ErrNoMetricsAdded)
+}
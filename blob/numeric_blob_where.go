@@ -0,0 +1,102 @@
+package blob
+
+import (
+	"iter"
+
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/section"
+)
+
+// AllWhere returns an iterator over the data points of the given metric ID for
+// which pred returns true, e.g. for threshold scans such as "values above 95".
+//
+// The predicate is evaluated inside the decode loop on the timestamp and value
+// alone: data points it rejects are never built and their tags are skipped
+// without being decoded.
+//
+// Parameters:
+//   - metricID: The metric ID to scan
+//   - pred: Predicate on the timestamp and value of each data point
+//
+// Returns:
+//   - iter.Seq2[int, NumericDataPoint]: Iterator yielding (index, data point)
+//     pairs of the matching data points, where index is the position of the
+//     data point within the metric (so gaps mark skipped points). Empty if the
+//     metric does not exist.
+//
+// Example:
+//
+//	for idx, dp := range blob.AllWhere(metricID, func(_ int64, v float64) bool { return v > 95 }) {
+//	    fmt.Printf("[%d] ts=%d, val=%f, tag=%s\n", idx, dp.Ts, dp.Val, dp.Tag)
+//	}
+func (b NumericBlob) AllWhere(metricID uint64, pred func(ts int64, v float64) bool) iter.Seq2[int, NumericDataPoint] {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok || pred == nil {
+		return func(yield func(int, NumericDataPoint) bool) {}
+	}
+
+	return func(yield func(int, NumericDataPoint) bool) {
+		b.forEachWhereFromEntry(entry, pred, yield)
+	}
+}
+
+// AllWhereByName returns an iterator over the data points of the given metric
+// name for which pred returns true.
+//
+// See AllWhere for details.
+func (b NumericBlob) AllWhereByName(metricName string, pred func(ts int64, v float64) bool) iter.Seq2[int, NumericDataPoint] {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok || pred == nil {
+		return func(yield func(int, NumericDataPoint) bool) {}
+	}
+
+	return func(yield func(int, NumericDataPoint) bool) {
+		b.forEachWhereFromEntry(entry, pred, yield)
+	}
+}
+
+// forEachWhereFromEntry runs the tagless ForEach decode loop for the entry and
+// yields the data points matching pred, decoding only the tags of matches.
+func (b NumericBlob) forEachWhereFromEntry(entry section.NumericIndexEntry, pred func(int64, float64) bool, yield func(int, NumericDataPoint) bool) {
+	var tags ienc.TagCursor
+	hasTags := false
+	if b.HasTag() && len(b.tagPayload) > 0 {
+		tagBytes, ok := safeSlice(b.tagPayload, entry.TagOffset, entry.TagLength)
+		if ok {
+			tagBytes, ok = b.plainTags(tagBytes, entry.Count)
+		}
+		if !ok {
+			return
+		}
+		tags, hasTags = ienc.NewTagCursor(tagBytes, b.interner), true
+	}
+
+	// Decode timestamps and values through the tagless fast paths; the tag
+	// cursor trails the decode loop and only materializes matching tags.
+	tagless := b
+	tagless.flags &^= section.FlagTagEnabled
+
+	tagIdx := 0
+	tagless.forEachFromEntry(entry, func(i int, dp NumericDataPoint) bool {
+		if !pred(dp.Ts, dp.Val) {
+			return true
+		}
+
+		if hasTags {
+			for ; tagIdx < i; tagIdx++ {
+				if !tags.Skip() {
+					return false
+				}
+			}
+
+			tag, ok := tags.Next()
+			if !ok {
+				return false
+			}
+			tagIdx++
+			dp.Tag = tag
+		}
+
+		return yield(i, dp)
+	})
+}
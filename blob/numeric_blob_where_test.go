@@ -0,0 +1,100 @@
+package blob
+
+import (
+	"fmt"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericBlob_AllWhere(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	above := func(_ int64, v float64) bool { return v > 95 }
+
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "delta gorilla", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "raw raw", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)}},
+		{name: "packed chimp", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTimestampEncoding(format.TypeDeltaPacked), WithValueEncoding(format.TypeChimp)}},
+		{name: "no tags", opts: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewNumericEncoder(start, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, encoder.StartMetricName("cpu", 100))
+			for i := range 100 {
+				tag := ""
+				if i%3 == 0 {
+					tag = fmt.Sprintf("host-%d", i)
+				}
+				require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), float64((i*37)%100), tag))
+			}
+			require.NoError(t, encoder.EndMetric())
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			want := map[int]NumericDataPoint{}
+			for i, dp := range blob.AllByName("cpu") {
+				if above(dp.Ts, dp.Val) {
+					want[i] = dp
+				}
+			}
+			require.NotEmpty(t, want)
+
+			got := map[int]NumericDataPoint{}
+			for i, dp := range blob.AllWhereByName("cpu", above) {
+				got[i] = dp
+			}
+			require.Equal(t, want, got)
+
+			// Early termination stops the scan.
+			n := 0
+			for range blob.AllWhereByName("cpu", above) {
+				n++
+				if n == 2 {
+					break
+				}
+			}
+			require.Equal(t, 2, n)
+
+			require.Empty(t, collectWhere(blob.AllWhere(12345, above)))
+		})
+	}
+}
+
+func TestBlobSet_AllNumericsWhere(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	blob1 := createBlobWithTimestamp(t, base, "cpu", []int64{base.UnixMicro(), base.UnixMicro() + 1}, []float64{99, 10})
+	blob2 := createBlobWithTimestamp(t, base.Add(time.Hour), "cpu", []int64{base.Add(time.Hour).UnixMicro()}, []float64{97})
+	above := func(_ int64, v float64) bool { return v > 95 }
+
+	bs := NewBlobSet([]NumericBlob{blob1, blob2}, nil)
+	got := collectWhere(bs.AllNumericsWhere(blob1.MetricIDs()[0], above))
+	require.Equal(t, map[int]float64{0: 99, 2: 97}, got)
+
+	set, err := NewNumericBlobSet([]NumericBlob{blob2, blob1})
+	require.NoError(t, err)
+	require.Equal(t, got, collectWhere(set.AllWhere(blob1.MetricIDs()[0], above)))
+}
+
+func collectWhere(seq iter.Seq2[int, NumericDataPoint]) map[int]float64 {
+	got := map[int]float64{}
+	for i, dp := range seq {
+		got[i] = dp.Val
+	}
+
+	return got
+}
@@ -1,14 +1,19 @@
 package blob
 
 import (
+	"cmp"
 	"fmt"
+	"math"
+	"slices"
 
 	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/encoding"
 	"github.com/arloliu/mebo/endian"
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	ienc "github.com/arloliu/mebo/internal/encoding"
 	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/internal/options"
 	"github.com/arloliu/mebo/section"
 )
 
@@ -22,6 +27,120 @@ type NumericDecoder struct {
 	metricCount int
 	engine      endian.EndianEngine
 	header      *section.NumericHeader
+
+	valTransform ValueTransform
+	interner     *ienc.Interner // Shared by all strings of the decoded blob, nil if disabled
+	lazyIndex    bool           // Defer building the metric index until the first lookup
+	hooks        ReadHooks      // Section decode instrumentation, nil if disabled
+
+	// convertToNative byte-swaps raw payloads of foreign byte order blobs at decode time.
+	convertToNative bool
+
+	// offsetUnit is the unit of index offset deltas in bytes recorded in the
+	// metadata section; 0 means bytes.
+	offsetUnit int
+
+	// rawPayloads holds the still-compressed payload sections when they are
+	// supplied as separate parts (see NewNumericDecoderFromParts). When nil,
+	// the sections are sliced from data using the header offsets.
+	rawPayloads *decodedPayloads
+
+	// valuesMissing is set when the parts omit the value payload, which is then
+	// neither decompressed nor indexed.
+	valuesMissing bool
+}
+
+// ValueTransform is a decode-time hook that maps a stored value of the given
+// metric to the value returned to the caller (e.g., unit conversion, scaling).
+//
+// The function must be pure and safe for concurrent use: it is invoked for every
+// value yielded by iteration, random access, and materialization of the blob.
+type ValueTransform func(metricID uint64, v float64) float64
+
+// NumericDecoderOption is a functional option for configuring NumericDecoder.
+type NumericDecoderOption = options.Option[*NumericDecoder]
+
+// WithValueTransform installs a decode-time value transform on the decoded blob.
+//
+// The transform is applied lazily while values are produced by All, AllValues,
+// ValueAt, ForEach, ForEachValues, Materialize and MaterializeMetric (and the
+// equivalent blob-set methods), so callers avoid a second pass over the data.
+// The encoded payload is never modified. A nil transform disables the hook.
+//
+// Example:
+//
+//	// Convert stored bytes to kibibytes for every metric
+//	decoder, _ := blob.NewNumericDecoder(data, blob.WithValueTransform(
+//	    func(_ uint64, v float64) float64 { return v / 1024 },
+//	))
+func WithValueTransform(fn ValueTransform) NumericDecoderOption {
+	return options.NoError(func(d *NumericDecoder) {
+		d.valTransform = fn
+	})
+}
+
+// WithStringInterning deduplicates the tag strings produced by the decoded blob.
+//
+// Without interning, every tag access allocates a new string, so a tag-heavy blob
+// read in full holds one copy of "host=a" per data point. With interning, identical
+// tags across points and metrics share one allocation, and lookups of already-seen
+// tags do not allocate. This cuts retained heap by large factors when callers keep
+// tags (e.g., Materialize or collecting AllTags) at the cost of a map lookup per tag.
+//
+// The interner lives as long as the decoded blob and is safe for concurrent use.
+//
+// Example:
+//
+//	decoder, _ := blob.NewNumericDecoder(data, blob.WithStringInterning(true))
+func WithStringInterning(enabled bool) NumericDecoderOption {
+	return options.NoError(func(d *NumericDecoder) {
+		d.interner = nil
+		if enabled {
+			d.interner = ienc.NewInterner()
+		}
+	})
+}
+
+// WithLazyIndex defers building the decoded blob's metric index until the first
+// metric lookup.
+//
+// Decoding normally builds the MetricID lookup structure (a map for V1 indexes,
+// a sorted slice for sorted ones) and the metric name map up front. Workloads that
+// decode blobs only to verify or re-upload them never look metrics up, so the
+// lazy mode skips that work; the index is built once, on the first call to a
+// lookup or iteration method, and is safe under concurrent first use.
+//
+// Blobs that store metrics as deltas against reference metrics still build the
+// index during Decode, since reconstructing them requires lookups.
+//
+// Example:
+//
+//	decoder, _ := blob.NewNumericDecoder(data, blob.WithLazyIndex(true))
+//	b, _ := decoder.Decode() // no index built yet
+//	_ = b.Len(metricID)      // index built here
+func WithLazyIndex(enabled bool) NumericDecoderOption {
+	return options.NoError(func(d *NumericDecoder) {
+		d.lazyIndex = enabled
+	})
+}
+
+// NumericDecodeReport describes the outcome of a best-effort DecodePartial.
+type NumericDecodeReport struct {
+	// Recovered lists the IDs of metrics whose payload ranges were fully present,
+	// in index order.
+	Recovered []uint64
+
+	// Lost lists the IDs of metrics that could not be recovered, in index order.
+	Lost []uint64
+
+	// TagsLost reports whether the tag payload could not be recovered. Recovered
+	// metrics are then returned without tags.
+	TagsLost bool
+}
+
+// IsComplete reports whether every metric, including its tags, was recovered.
+func (r NumericDecodeReport) IsComplete() bool {
+	return len(r.Lost) == 0 && !r.TagsLost
 }
 
 // NewNumericDecoder creates a new NumericDecoder for the given encoded data.
@@ -31,15 +150,25 @@ type NumericDecoder struct {
 //
 // Parameters:
 //   - data: Encoded blob byte slice (must contain valid header)
+//   - opts: Optional decoder options (e.g., WithValueTransform)
 //
 // Returns:
 //   - *NumericDecoder: New decoder instance ready for decoding
 //   - error: Header parsing error or invalid data format
-func NewNumericDecoder(data []byte) (*NumericDecoder, error) {
+func NewNumericDecoder(data []byte, opts ...NumericDecoderOption) (*NumericDecoder, error) {
+	data, err := standardNumericBlob(data)
+	if err != nil {
+		return nil, err
+	}
+
 	decoder := &NumericDecoder{
 		data: data,
 	}
 
+	if err := options.Apply(decoder, opts...); err != nil {
+		return nil, err
+	}
+
 	if err := decoder.parseHeader(); err != nil {
 		return nil, err
 	}
@@ -61,69 +190,43 @@ func NewNumericDecoder(data []byte) (*NumericDecoder, error) {
 //   - error: Payload offset validation errors, decompression errors, index parsing errors,
 //     or metric name verification failures
 func (d *NumericDecoder) Decode() (NumericBlob, error) {
-	// Pack flags into single uint16 for size optimization
-	var flags uint16
-	if d.header.Flag.IsBigEndian() {
-		flags |= section.FlagEndianLittleEndian // 1=big endian
-	}
-	if d.header.Flag.TimestampEncoding() == format.TypeRaw {
-		flags |= section.FlagTsEncRaw
-	}
-	if d.header.Flag.HasTag() {
-		flags |= section.FlagTagEnabled
-	}
-	if d.header.Flag.HasMetricNames() {
-		flags |= section.FlagMetricNames
-	}
-
-	blob := NumericBlob{
-		blobBase: blobBase{
-			tsEncType:  d.header.Flag.TimestampEncoding(),
-			valEncType: d.header.Flag.ValueEncoding(),
-			flags:      flags, // Packed flags (optimized)
-			formatVersion: func() uint8 {
-				if d.header.Flag.IsV2() {
-					return blobFormatV2
-				}
+	blob := d.newBlob()
 
-				return blobFormatV1
-			}(),
-			sameByteOrder: endian.CompareNativeEndian(d.engine),
-			endianType: func() uint8 {
-				if d.header.Flag.IsBigEndian() {
-					return 1
-				}
-
-				return 0
-			}(), // 0=little, 1=big
-			startTimeMicros: d.header.StartTime, // Direct int64 assignment (optimized)
-		},
-	}
-
-	// Validate payload offsets
-	tsOffset := int(d.header.TimestampPayloadOffset)
-	if len(d.data) < tsOffset {
-		return blob, errs.ErrInvalidTimestampPayloadOffset
-	}
-
-	valOffset := int(d.header.ValuePayloadOffset)
-	if len(d.data) < valOffset {
-		return blob, errs.ErrInvalidValuePayloadOffset
+	// Locate the compressed payload sections
+	rawPayloads, err := d.payloadSections()
+	if err != nil {
+		return blob, err
 	}
 
-	tagOffset := int(d.header.TagPayloadOffset)
-	if len(d.data) < tagOffset {
-		return blob, errs.ErrInvalidTagPayloadOffset
+	// Step 1: Parse metric names (if present); compressed names are parsed on first name lookup
+	var metricNames []string
+	var namesFrame []byte
+	indexOffset := d.header.Size()
+	if d.header.Flag.HasCompressedMetricNames() {
+		var namesSize int
+		namesFrame, namesSize, err = compressedMetricNames(d.data[indexOffset:], d.engine)
+		if err != nil {
+			return blob, fmt.Errorf("failed to decode metric names: %w", err)
+		}
+		indexOffset += namesSize
+	} else {
+		metricNames, indexOffset, err = d.parseMetricNames()
+		if err != nil {
+			return blob, err
+		}
 	}
 
-	// Step 1: Parse metric names (if present)
-	metricNames, indexOffset, err := d.parseMetricNames()
+	// Step 1.5: Parse metadata section (if present)
+	metadata, indexOffset, err := d.parseMetadata(indexOffset)
 	if err != nil {
 		return blob, err
 	}
+	if err := d.setMetadata(&blob, metadata); err != nil {
+		return blob, err
+	}
 
 	// Step 2: Decompress payloads (do this before parsing index entries)
-	payloads, err := d.decompressPayloads(tsOffset, valOffset, tagOffset)
+	payloads, err := d.decompressPayloads(rawPayloads, tagCompression(blob.metadata))
 	if err != nil {
 		return blob, err
 	}
@@ -131,12 +234,23 @@ func (d *NumericDecoder) Decode() (NumericBlob, error) {
 	blob.tsPayload = payloads.tsPayload
 	blob.valPayload = payloads.valPayload
 	blob.tagPayload = payloads.tagPayload
+	d.convertPayloadsToNative(&blob)
+
+	// Without the value payload, value offsets are unbounded and the blob
+	// identity unknown
+	valSize := len(blob.valPayload)
+	if d.valuesMissing {
+		valSize = math.MaxInt
+		blob.valuesErr = fmt.Errorf("%w: value part not supplied", errs.ErrInvalidBlobPart)
+	} else {
+		blob.blobID = newLazyBlobID(d.data[:d.header.TimestampPayloadOffset], rawPayloads.tsPayload, rawPayloads.valPayload, rawPayloads.tagPayload)
+	}
 
 	// Step 3: Parse index entries (now we know decompressed payload sizes)
 	// For V2 without metric names, skip metricIDs allocation (it would be unused).
 	// For V2 with metric names, metricIDs is reused directly as sortedIDs.
-	needMetricIDs := len(metricNames) > 0
-	indexEntries, metricIDs, err := d.parseIndexEntries(indexOffset, len(blob.tsPayload), len(blob.valPayload), len(blob.tagPayload), needMetricIDs)
+	needMetricIDs := len(metricNames) > 0 || namesFrame != nil
+	indexEntries, metricIDs, err := d.parseIndexEntries(indexOffset, len(blob.tsPayload), valSize, len(blob.tagPayload), needMetricIDs)
 	if err != nil {
 		return blob, err
 	}
@@ -152,7 +266,7 @@ func (d *NumericDecoder) Decode() (NumericBlob, error) {
 	// fields are out of range) would otherwise panic deep in the decode paths
 	// on out-of-range slicing/indexing — validated here too, so both classes
 	// of corruption are caught at blob open instead of on the decode hot path.
-	if blob.valEncType == format.TypeALP {
+	if blob.valEncType == format.TypeALP && !d.valuesMissing {
 		if err := validateALPColumns(blob.valPayload, indexEntries, d.engine); err != nil {
 			return blob, err
 		}
@@ -177,53 +291,331 @@ func (d *NumericDecoder) Decode() (NumericBlob, error) {
 		d.buildSharedTsCache(&blob, indexEntries)
 	}
 
-	// Step 4: Build index — V2 uses sorted slice, V1 uses map
-	d.buildIndex(&blob, indexEntries, metricIDs)
+	// Step 4: Reject a sorted index flag whose V1 entries are out of order
+	if d.header.Flag.IsIndexSorted() && !d.header.Flag.IsV2() {
+		if !slices.IsSortedFunc(indexEntries, compareIndexEntryIDs) {
+			return blob, fmt.Errorf("%w: sorted index flag set but entries are not sorted by MetricID", errs.ErrInvalidIndexOffsets)
+		}
+	}
 
-	// Step 5: Verify and populate metric name map (if metric names present)
+	// Step 4.1: Verify metric names (if present) against their hashed IDs
 	if len(metricNames) > 0 {
 		if err := ienc.VerifyMetricNamesHashes(metricNames, metricIDs, hash.ID); err != nil {
 			return blob, fmt.Errorf("metric name verification failed: %w", err)
 		}
+	}
+
+	// Step 4.2: Build index and metric name map (metricNames[i] corresponds to indexEntries[i]).
+	// With lazy indexing the build is deferred to the first lookup; the closure
+	// captures only the parsed slices, not the decoder.
+	sorted := d.header.Flag.IsIndexSorted()
+	engine := d.engine
+	build := func() indexMaps[section.NumericIndexEntry] {
+		index := newNumericIndex(sorted, indexEntries, metricIDs)
+		index.byName = nameIndex(metricNames, indexEntries)
+		if namesFrame != nil {
+			index.names = &lazyNames[section.NumericIndexEntry]{
+				count: len(indexEntries),
+				parse: func() (map[string]section.NumericIndexEntry, error) {
+					return parseCompressedMetricNames(namesFrame, engine, indexEntries, metricIDs)
+				},
+			}
+		}
 
-		// Populate metric name map for ByName lookups
-		// metricNames[i] corresponds to indexEntries[i] (consistent ordering)
-		blob.index.byName = make(map[string]section.NumericIndexEntry, d.metricCount)
-		for i, name := range metricNames {
-			blob.index.byName[name] = indexEntries[i]
+		return index
+	}
+	if d.lazyIndex {
+		blob.index = newLazyIndexMaps(build)
+	} else {
+		blob.index = build()
+	}
+
+	// Step 4.5: Reconstruct metrics stored as deltas against a reference metric
+	// (the first lookup here builds a lazy index)
+	if !d.valuesMissing {
+		if err := d.resolveMetricReferences(&blob); err != nil {
+			return blob, err
 		}
 	}
 
 	return blob, nil
 }
 
-// buildIndex populates the blob's index from parsed index entries.
-// V2 uses sorted slice with parallel sortedIDs; V1 uses map.
-func (d *NumericDecoder) buildIndex(blob *NumericBlob, indexEntries []section.NumericIndexEntry, metricIDs []uint64) {
-	if d.header.Flag.IsV2() {
-		// V2: entries are already sorted by MetricID from the encoder.
+// DecodePartial decodes a possibly truncated blob (e.g., an interrupted upload)
+// on a best-effort basis, salvaging every metric whose payload ranges are fully
+// present instead of failing the whole decode.
+//
+// The header, metric names, metadata and index sections must be intact, since
+// they are needed to enumerate the metrics. A compressed payload section can only
+// be used when it is complete; uncompressed (CompressionNone) sections are used up
+// to the truncation point. Metrics stored as deltas against a lost reference metric
+// are lost as well. For an intact blob the result is equivalent to Decode.
+//
+// Returns:
+//   - NumericBlob: Blob containing only the recovered metrics
+//   - NumericDecodeReport: Recovered and lost metric IDs
+//   - error: Header, metric names, metadata or index errors that prevent enumerating metrics
+//
+// Example:
+//
+//	blob, report, err := decoder.DecodePartial()
+//	if err == nil && !report.IsComplete() {
+//	    log.Printf("recovered %d metrics, lost %d", len(report.Recovered), len(report.Lost))
+//	}
+func (d *NumericDecoder) DecodePartial() (NumericBlob, NumericDecodeReport, error) {
+	var report NumericDecodeReport
+
+	blob := d.newBlob()
+
+	tsOffset := int(d.header.TimestampPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	valOffset := int(d.header.ValuePayloadOffset)    //nolint: gosec // Parse bounds offsets to the int range
+	tagOffset := int(d.header.TagPayloadOffset)      //nolint: gosec // Parse bounds offsets to the int range
+	if tsOffset > valOffset {
+		return blob, report, errs.ErrInvalidValuePayloadOffset
+	}
+	if valOffset > tagOffset {
+		return blob, report, errs.ErrInvalidTagPayloadOffset
+	}
+
+	metricNames, indexOffset, err := d.parseMetricNames()
+	if err != nil {
+		return blob, report, err
+	}
+
+	metadata, indexOffset, err := d.parseMetadata(indexOffset)
+	if err != nil {
+		return blob, report, err
+	}
+	if err := d.setMetadata(&blob, metadata); err != nil {
+		return blob, report, err
+	}
+
+	refs, _, err := d.metricReferences(blob.metadata)
+	if err != nil {
+		return blob, report, err
+	}
+
+	// Salvage the payload sections. Tags extend to the end of the data, so
+	// compressed tags are either fully present or lost.
+	tsPayload, tsSize, tsOK := d.partialSection(tsOffset, valOffset, d.header.Flag.TimestampCompression())
+	valPayload, valSize, valOK := d.partialSection(valOffset, tagOffset, d.header.Flag.ValueCompression())
+
+	var tagPayload []byte
+	tagSize, tagOK := 0, true
+	if d.header.Flag.HasTag() {
+		tagPayload, tagSize, tagOK = d.partialSection(tagOffset, len(d.data), tagCompression(blob.metadata))
+	}
+
+	needMetricIDs := len(metricNames) > 0
+	indexEntries, metricIDs, err := d.parseIndexEntries(indexOffset, tsSize, valSize, tagSize, needMetricIDs)
+	if err != nil {
+		return blob, report, err
+	}
+
+	if len(metricNames) > 0 {
+		if err := ienc.VerifyMetricNamesHashes(metricNames, metricIDs, hash.ID); err != nil {
+			return blob, report, fmt.Errorf("metric name verification failed: %w", err)
+		}
+	}
+
+	if d.header.Flag.HasSharedTimestamps() && tsOK {
+		indexEnd := indexOffset + d.metricCount*d.header.Flag.IndexEntrySize()
+		if tsOffset <= indexEnd || tsOffset > len(d.data) ||
+			section.ApplySharedTimestampTable(d.data[indexEnd:tsOffset], d.engine, d.metricCount, indexEntries) != nil {
+			tsOK = false
+		}
+	}
+
+	// Keep the metrics whose ranges are fully present and whose values decode safely.
+	keep := make([]bool, len(indexEntries))
+	kept := make(map[uint64]bool, len(indexEntries))
+	for i := range indexEntries {
+		e := &indexEntries[i]
+		keep[i] = tsOK && valOK &&
+			e.TimestampOffset+e.TimestampLength <= len(tsPayload) &&
+			e.ValueOffset+e.ValueLength <= len(valPayload)
+
+		if keep[i] && blob.valEncType == format.TypeALP {
+			keep[i] = validateALPColumns(valPayload, indexEntries[i:i+1], d.engine) == nil
+		}
+
+		if keep[i] {
+			kept[e.MetricID] = true
+		}
+	}
+
+	// Drop metrics whose reference chain reaches a lost metric.
+	for changed := true; changed; {
+		changed = false
+		for i := range indexEntries {
+			id := indexEntries[i].MetricID
+			if refID, ok := refs[id]; ok && kept[id] && !kept[refID] {
+				keep[i], kept[id], changed = false, false, true
+			}
+		}
+	}
+
+	entries := make([]section.NumericIndexEntry, 0, len(kept))
+	var names []string
+	for i := range indexEntries {
+		if !keep[i] {
+			report.Lost = append(report.Lost, indexEntries[i].MetricID)
+			delete(refs, indexEntries[i].MetricID)
+			delete(blob.metricMeta, indexEntries[i].MetricID)
+
+			continue
+		}
+
+		report.Recovered = append(report.Recovered, indexEntries[i].MetricID)
+		entries = append(entries, indexEntries[i])
+		if len(metricNames) > 0 {
+			names = append(names, metricNames[i])
+		}
+	}
+
+	// The recorded time bounds may include lost metrics; TimeRange scans the rest.
+	if len(report.Lost) > 0 {
+		blob.metadata.Delete(section.MetadataKeyTimeBounds)
+	}
+
+	blob.tsPayload = tsPayload
+	blob.valPayload = valPayload
+	d.convertPayloadsToNative(&blob)
+	if tagOK {
+		blob.tagPayload = tagPayload
+	} else {
+		report.TagsLost = true
+		blob.flags &^= section.FlagTagEnabled
+	}
+
+	if d.header.Flag.HasSharedTimestamps() {
+		d.buildSharedTsCache(&blob, entries)
+	}
+
+	blob.index = newNumericIndex(d.header.Flag.IsIndexSorted(), entries, nil)
+
+	if len(refs) > 0 {
+		if err := d.applyMetricReferences(&blob, refs); err != nil {
+			return blob, report, err
+		}
+	}
+
+	blob.index.byName = nameIndex(names, entries)
+
+	return blob, report, nil
+}
+
+// partialSection returns the decompressed bytes of the payload section
+// data[start:end], tolerating truncation of the blob, along with the section's
+// declared decompressed size. Uncompressed sections are returned up to the
+// truncation point; compressed sections are only usable when complete. For an
+// unusable section the bool result is false and the size is unbounded, so index
+// offsets can still be parsed.
+func (d *NumericDecoder) partialSection(start, end int, compression format.CompressionType) ([]byte, int, bool) {
+	if compression == format.CompressionNone {
+		available := d.data[min(start, len(d.data)):min(end, len(d.data))]
+
+		return available, end - start, true
+	}
+
+	if start > end || end > len(d.data) {
+		return nil, math.MaxInt, false
+	}
+
+	codec, err := compress.GetCodec(compression)
+	if err != nil {
+		return nil, math.MaxInt, false
+	}
+
+	payload, err := codec.Decompress(d.data[start:end])
+	if err != nil {
+		return nil, math.MaxInt, false
+	}
+
+	return payload, len(payload), true
+}
+
+// newBlob returns a NumericBlob carrying the header-derived settings and the
+// decoder options, with no payloads or index attached yet.
+func (d *NumericDecoder) newBlob() NumericBlob {
+	// Pack flags into single uint16 for size optimization
+	var flags uint16
+	if d.header.Flag.IsBigEndian() {
+		flags |= section.FlagEndianLittleEndian // 1=big endian
+	}
+	if d.header.Flag.TimestampEncoding() == format.TypeRaw {
+		flags |= section.FlagTsEncRaw
+	}
+	if d.header.Flag.HasTag() {
+		flags |= section.FlagTagEnabled
+	}
+	if d.header.Flag.HasMetricNames() {
+		flags |= section.FlagMetricNames
+	}
+
+	return NumericBlob{
+		blobBase: blobBase{
+			tsEncType:  d.header.Flag.TimestampEncoding(),
+			valEncType: d.header.Flag.ValueEncoding(),
+			flags:      flags, // Packed flags (optimized)
+			formatVersion: func() uint8 {
+				if d.header.Flag.IsV2() {
+					return blobFormatV2
+				}
+
+				return blobFormatV1
+			}(),
+			sameByteOrder: endian.CompareNativeEndian(d.engine),
+			endianType: func() uint8 {
+				if d.header.Flag.IsBigEndian() {
+					return 1
+				}
+
+				return 0
+			}(), // 0=little, 1=big
+			startTimeMicros: d.header.StartTime, // Direct int64 assignment (optimized)
+			interner:        d.interner,
+		},
+		valTransform: d.valTransform,
+	}
+}
+
+// compareIndexEntryIDs orders index entries by MetricID.
+func compareIndexEntryIDs(a, b section.NumericIndexEntry) int {
+	return cmp.Compare(a.MetricID, b.MetricID)
+}
+
+// newNumericIndex builds an index from parsed index entries.
+// Sorted indexes (V2, or V1 with the sorted index flag) use a sorted slice with
+// parallel sortedIDs; unsorted V1 uses a map.
+func newNumericIndex(sorted bool, indexEntries []section.NumericIndexEntry, metricIDs []uint64) indexMaps[section.NumericIndexEntry] {
+	var index indexMaps[section.NumericIndexEntry]
+	if sorted {
+		// Entries are already sorted by MetricID from the encoder.
 		// Assign directly — no copy needed since parseIndexEntries returns a dedicated slice.
-		blob.index.sorted = indexEntries
+		index.sorted = indexEntries
 
 		if metricIDs != nil {
 			// Reuse metricIDs from parseIndexEntries as sortedIDs (same data, same order)
-			blob.index.sortedIDs = metricIDs
+			index.sortedIDs = metricIDs
 		} else {
 			// Build sortedIDs when metricIDs wasn't allocated (V2 without metric names)
-			blob.index.sortedIDs = make([]uint64, len(indexEntries))
+			index.sortedIDs = make([]uint64, len(indexEntries))
 			for i := range indexEntries {
-				blob.index.sortedIDs[i] = indexEntries[i].MetricID
+				index.sortedIDs[i] = indexEntries[i].MetricID
 			}
 		}
 
-		return
+		return index
 	}
 
 	// V1: map-based index for O(1) amortized lookups
-	blob.index.byID = make(map[uint64]section.NumericIndexEntry, d.metricCount)
+	index.byID = make(map[uint64]section.NumericIndexEntry, len(indexEntries))
 	for _, entry := range indexEntries {
-		blob.index.byID[entry.MetricID] = entry
+		index.byID[entry.MetricID] = entry
 	}
+
+	return index
 }
 
 // parseHeader parses the header section of the encoded data.
@@ -254,10 +646,10 @@ func (d *NumericDecoder) parsePayloads() error {
 // Returns the metric names slice and the byte offset where the index section starts.
 func (d *NumericDecoder) parseMetricNames() ([]string, int, error) {
 	if !d.header.Flag.HasMetricNames() {
-		return nil, section.HeaderSize, nil
+		return nil, d.header.Size(), nil
 	}
 
-	metricNames, bytesRead, err := ienc.DecodeMetricNames(d.data[section.HeaderSize:], d.engine)
+	metricNames, bytesRead, err := decodeNumericMetricNames(d.data[d.header.Size():], d.engine, d.header.Flag.HasCompressedMetricNames())
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to decode metric names: %w", err)
 	}
@@ -268,11 +660,201 @@ func (d *NumericDecoder) parseMetricNames() ([]string, int, error) {
 			errs.ErrInvalidMetricNamesCount, d.metricCount, len(metricNames))
 	}
 
-	indexOffset := section.HeaderSize + bytesRead
+	indexOffset := d.header.Size() + bytesRead
 
 	return metricNames, indexOffset, nil
 }
 
+// metricReferences parses the metric references metadata record into a map of
+// MetricID to reference MetricID. The bool result reports whether the record
+// is present.
+func (d *NumericDecoder) metricReferences(metadata section.Metadata) (map[uint64]uint64, bool, error) {
+	data, ok := metadata.Get(section.MetadataKeyMetricReferences)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if len(data)%16 != 0 {
+		return nil, false, fmt.Errorf("%w: metric references record size %d is not a multiple of 16", errs.ErrInvalidMetricReference, len(data))
+	}
+
+	refs := make(map[uint64]uint64, len(data)/16)
+	for i := 0; i < len(data); i += 16 {
+		refs[d.engine.Uint64(data[i:])] = d.engine.Uint64(data[i+8:])
+	}
+
+	return refs, true, nil
+}
+
+// resolveMetricReferences reconstructs the values of metrics stored as deltas
+// against a reference metric (see NumericEncoder.StartMetricIDWithReference) and
+// caches them on the blob, so every value access path sees the original values.
+func (d *NumericDecoder) resolveMetricReferences(blob *NumericBlob) error {
+	refs, ok, err := d.metricReferences(blob.metadata)
+	if err != nil || !ok {
+		return err
+	}
+
+	return d.applyMetricReferences(blob, refs)
+}
+
+// applyMetricReferences reconstructs and caches the values of every metric in
+// refs (MetricID to reference MetricID).
+func (d *NumericDecoder) applyMetricReferences(blob *NumericBlob, refs map[uint64]uint64) error {
+	blob.refValCache = make(map[uint64][]float64, len(refs))
+
+	// resolve returns the original values of metricID, reconstructing (and
+	// caching) referencing metrics first. visiting guards against cycles in
+	// corrupt or crafted blobs.
+	visiting := make(map[uint64]bool, len(refs))
+	var resolve func(metricID uint64) ([]float64, error)
+	resolve = func(metricID uint64) ([]float64, error) {
+		if cached, ok := blob.refValCache[metricID]; ok {
+			return cached, nil
+		}
+
+		entry, ok := blob.index.GetByID(metricID)
+		if !ok {
+			return nil, fmt.Errorf("%w: metric ID 0x%016x not found", errs.ErrInvalidMetricReference, metricID)
+		}
+
+		valBytes, ok := safeSlice(blob.valPayload, entry.ValueOffset, entry.ValueLength)
+		if !ok {
+			return nil, fmt.Errorf("%w: metric ID 0x%016x value payload out of range", errs.ErrInvalidMetricReference, metricID)
+		}
+
+		values := make([]float64, entry.Count)
+		values = values[:blob.decodeValuesSlice(valBytes, entry.Count, values)]
+
+		refID, isRef := refs[metricID]
+		if !isRef {
+			return values, nil
+		}
+
+		if visiting[metricID] {
+			return nil, fmt.Errorf("%w: reference cycle at metric ID 0x%016x", errs.ErrInvalidMetricReference, metricID)
+		}
+		visiting[metricID] = true
+
+		refValues, err := resolve(refID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(refValues) != len(values) {
+			return nil, fmt.Errorf("%w: metric ID 0x%016x has %d values, reference has %d",
+				errs.ErrInvalidMetricReference, metricID, len(values), len(refValues))
+		}
+
+		for i, v := range values {
+			values[i] = math.Float64frombits(math.Float64bits(v) + math.Float64bits(refValues[i]))
+		}
+		blob.refValCache[metricID] = values
+
+		return values, nil
+	}
+
+	for metricID := range refs {
+		if _, err := resolve(metricID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseMetadata parses the optional metadata section starting at offset.
+// Returns the parsed metadata and the offset where the index section begins.
+func (d *NumericDecoder) parseMetadata(offset int) (section.Metadata, int, error) {
+	if !d.header.Flag.HasMetadata() {
+		return section.Metadata{}, offset, nil
+	}
+
+	if offset > len(d.data) {
+		return section.Metadata{}, 0, fmt.Errorf("%w: metadata section out of range", errs.ErrInvalidMetadata)
+	}
+
+	metadata, bytesRead, err := section.ParseMetadata(d.data[offset:], d.engine)
+	if err != nil {
+		return section.Metadata{}, 0, err
+	}
+
+	if unit, ok := metadata.Get(section.MetadataKeyTimestampUnit); ok {
+		if len(unit) != 1 || !format.TimeUnit(unit[0]).IsValid() {
+			return section.Metadata{}, 0, fmt.Errorf("%w: invalid timestamp unit record", errs.ErrInvalidMetadata)
+		}
+	}
+
+	if comp, ok := metadata.Get(section.MetadataKeyTagCompression); ok {
+		switch {
+		case len(comp) != 1:
+			return section.Metadata{}, 0, fmt.Errorf("%w: invalid tag compression record", errs.ErrInvalidMetadata)
+		case format.CompressionType(comp[0]).String() == "Unknown":
+			return section.Metadata{}, 0, fmt.Errorf("%w: unknown tag compression %d", errs.ErrInvalidMetadata, comp[0])
+		}
+	}
+
+	if value, ok := metadata.Get(section.MetadataKeyTagPresenceBitmap); ok && len(value) != 0 {
+		return section.Metadata{}, 0, fmt.Errorf("%w: invalid tag presence bitmap record", errs.ErrInvalidMetadata)
+	}
+
+	if unit, ok := metadata.Get(section.MetadataKeyOffsetUnit); ok {
+		if len(unit) != 1 || unit[0] == 0 || unit[0]&(unit[0]-1) != 0 {
+			return section.Metadata{}, 0, fmt.Errorf("%w: invalid offset unit record", errs.ErrInvalidMetadata)
+		}
+		d.offsetUnit = int(unit[0])
+	}
+
+	return metadata, offset + bytesRead, nil
+}
+
+// setMetadata attaches a parsed metadata section to blob, along with the decode
+// state it records: the tag layout, the extension value decoder and the metric
+// metadata.
+func (d *NumericDecoder) setMetadata(blob *NumericBlob, metadata section.Metadata) error {
+	blob.metadata = metadata
+	_, blob.tagBitmap = metadata.Get(section.MetadataKeyTagPresenceBitmap)
+
+	var err error
+	if blob.valEncType == format.TypeExtension {
+		if blob.extValues, err = d.extensionValueDecoder(metadata); err != nil {
+			return err
+		}
+	}
+
+	blob.metricMeta, err = parseMetricMeta(metadata, d.engine)
+
+	return err
+}
+
+// extensionValueDecoder returns a decoder for the value payload of a blob whose
+// values use an extension encoding, looked up by the ID recorded in metadata.
+func (d *NumericDecoder) extensionValueDecoder(metadata section.Metadata) (encoding.ColumnarDecoder[float64], error) {
+	value, ok := metadata.Get(section.MetadataKeyValueExtension)
+	if !ok || len(value) != 2 {
+		return nil, fmt.Errorf("%w: missing or invalid value extension record", errs.ErrInvalidMetadata)
+	}
+
+	id := d.engine.Uint16(value)
+	ext, ok := format.LookupEncoding(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: extension encoding ID %d is not registered", errs.ErrUnsupportedEncoding, id)
+	}
+
+	return ext.NewDecoder(d.engine), nil
+}
+
+// tagCompression returns the tag payload compression recorded in metadata,
+// defaulting to Zstd for blobs that record none.
+func tagCompression(metadata section.Metadata) format.CompressionType {
+	comp, ok := metadata.Get(section.MetadataKeyTagCompression)
+	if !ok || len(comp) != 1 {
+		return format.CompressionZstd
+	}
+
+	return format.CompressionType(comp[0])
+}
+
 // parseIndexEntries parses the index section and populates the index entry map.
 // Returns the parsed index entries in order and the metric IDs for verification.
 // Uses the provided decompressed payload sizes to calculate entry lengths correctly.
@@ -302,10 +884,13 @@ func (d *NumericDecoder) parseIndexEntries(
 		metricIDs = make([]uint64, d.metricCount)
 	}
 
-	// Select parser based on entry size (compact 16B vs extended 32B)
+	// Select parser based on the index format (compact 16B, tagless 16B or extended 32B)
 	parseEntry := section.ParseNumericIndexEntry
-	if entrySize == section.NumericExtIndexEntrySize {
+	switch {
+	case d.header.Flag.IsV2Ext():
 		parseEntry = section.ParseNumericIndexEntryExt
+	case d.header.Flag.IsV2NoTag():
+		parseEntry = section.ParseNumericIndexEntryNoTag
 	}
 
 	var err error
@@ -322,6 +907,11 @@ func (d *NumericDecoder) parseIndexEntries(
 
 		// Convert delta offsets to absolute offsets
 		// Accumulate in int to prevent uint16 overflow, entry now has int fields
+		if d.offsetUnit > 1 {
+			curEntry.TimestampOffset *= d.offsetUnit
+			curEntry.ValueOffset *= d.offsetUnit
+			curEntry.TagOffset *= d.offsetUnit
+		}
 		lastTsOffset += curEntry.TimestampOffset
 		lastValOffset += curEntry.ValueOffset
 		lastTagOffset += curEntry.TagOffset
@@ -491,15 +1081,47 @@ func validateALPColumns(valPayload []byte, indexEntries []section.NumericIndexEn
 	return nil
 }
 
-// decodedPayloads holds the decompressed payload data.
+// decodedPayloads holds the timestamp, value, and tag payload sections.
+// Depending on the stage it carries either compressed or decompressed bytes.
 type decodedPayloads struct {
 	tsPayload  []byte
 	valPayload []byte
 	tagPayload []byte
 }
 
+// payloadSections returns the compressed timestamp, value, and tag payload
+// sections, either from separately supplied parts or by slicing the blob data
+// at the header offsets.
+func (d *NumericDecoder) payloadSections() (decodedPayloads, error) {
+	if d.rawPayloads != nil {
+		return *d.rawPayloads, nil
+	}
+
+	tsOffset := int(d.header.TimestampPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	if len(d.data) < tsOffset {
+		return decodedPayloads{}, errs.ErrInvalidTimestampPayloadOffset
+	}
+
+	valOffset := int(d.header.ValuePayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	if len(d.data) < valOffset {
+		return decodedPayloads{}, errs.ErrInvalidValuePayloadOffset
+	}
+
+	tagOffset := int(d.header.TagPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	if len(d.data) < tagOffset {
+		return decodedPayloads{}, errs.ErrInvalidTagPayloadOffset
+	}
+
+	return decodedPayloads{
+		tsPayload:  d.data[tsOffset:valOffset],
+		valPayload: d.data[valOffset:tagOffset],
+		tagPayload: d.data[tagOffset:],
+	}, nil
+}
+
 // decompressPayloads decompresses timestamp, value, and tag payloads.
-func (d *NumericDecoder) decompressPayloads(tsOffset, valOffset, tagOffset int) (decodedPayloads, error) {
+// tagComp is the tag payload compression recorded in the blob (see tagCompression).
+func (d *NumericDecoder) decompressPayloads(raw decodedPayloads, tagComp format.CompressionType) (decodedPayloads, error) {
 	// Get built-in codecs based on header settings
 	tsCodec, err := compress.GetCodec(d.header.Flag.TimestampCompression())
 	if err != nil {
@@ -512,25 +1134,28 @@ func (d *NumericDecoder) decompressPayloads(tsOffset, valOffset, tagOffset int)
 	}
 
 	// Decompress timestamp and value payloads
-	tsPayload, err := tsCodec.Decompress(d.data[tsOffset:valOffset])
+	tsPayload, err := decompressSection(d.hooks, SectionTimestamps, d.header.Flag.TimestampCompression(), raw.tsPayload, tsCodec.Decompress)
 	if err != nil {
 		return decodedPayloads{}, fmt.Errorf("failed to decompress timestamp payload: %w", err)
 	}
 
-	valPayload, err := valCodec.Decompress(d.data[valOffset:tagOffset])
-	if err != nil {
-		return decodedPayloads{}, fmt.Errorf("failed to decompress value payload: %w", err)
+	var valPayload []byte
+	if !d.valuesMissing {
+		valPayload, err = decompressSection(d.hooks, SectionValues, d.header.Flag.ValueCompression(), raw.valPayload, valCodec.Decompress)
+		if err != nil {
+			return decodedPayloads{}, fmt.Errorf("failed to decompress value payload: %w", err)
+		}
 	}
 
 	// Decompress tag payload only if tag support is enabled
 	var tagPayload []byte
 	if d.header.Flag.HasTag() {
-		tagCodec, err := compress.GetCodec(format.CompressionZstd)
+		tagCodec, err := compress.GetCodec(tagComp)
 		if err != nil {
 			return decodedPayloads{}, fmt.Errorf("unsupported tag compression: %w", err)
 		}
 
-		tagPayload, err = tagCodec.Decompress(d.data[tagOffset:])
+		tagPayload, err = decompressSection(d.hooks, SectionTags, tagComp, raw.tagPayload, tagCodec.Decompress)
 		if err != nil {
 			return decodedPayloads{}, fmt.Errorf("failed to decompress tag payload: %w", err)
 		}
@@ -548,14 +1173,14 @@ func (d *NumericDecoder) decompressPayloads(tsOffset, valOffset, tagOffset int)
 // that share the same underlying timestamp data.
 func (d *NumericDecoder) buildSharedTsCache(blob *NumericBlob, indexEntries []section.NumericIndexEntry) {
 	// Count how many metrics reference each TimestampOffset
-	refCount := make(map[int]int, d.metricCount)
-	for i := range indexEntries[:d.metricCount] {
+	refCount := make(map[int]int, len(indexEntries))
+	for i := range indexEntries {
 		refCount[indexEntries[i].TimestampOffset]++
 	}
 
 	// Pre-decode only offsets used by more than one metric
 	cache := make(map[int][]int64)
-	for i := range indexEntries[:d.metricCount] {
+	for i := range indexEntries {
 		entry := &indexEntries[i]
 		if refCount[entry.TimestampOffset] <= 1 {
 			continue
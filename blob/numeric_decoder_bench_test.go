@@ -209,10 +209,8 @@ func BenchmarkNumericDecoder_ParseIndexEntries(b *testing.B) {
 			indexOffset := section.HeaderSize
 
 			// Decompress payloads to get sizes (required for parseIndexEntries)
-			tsOffset := int(decoder.header.TimestampPayloadOffset)
-			valOffset := int(decoder.header.ValuePayloadOffset)
-			tagOffset := int(decoder.header.TagPayloadOffset)
-			payloads, _ := decoder.decompressPayloads(tsOffset, valOffset, tagOffset)
+			rawPayloads, _ := decoder.payloadSections()
+			payloads, _ := decoder.decompressPayloads(rawPayloads, format.CompressionZstd)
 
 			b.ReportAllocs()
 			b.ResetTimer()
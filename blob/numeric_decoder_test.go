@@ -1,8 +1,12 @@
 package blob
 
 import (
+	"bytes"
+	"slices"
+	"sync"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/arloliu/mebo/endian"
 	"github.com/arloliu/mebo/errs"
@@ -2498,3 +2502,400 @@ func TestNumericDecoder_MaliciousMagicExtendedTruncatedData(t *testing.T) {
 	require.Error(t, err, "decoder must not panic on truncated extended index data")
 	require.ErrorIs(t, err, errs.ErrInvalidIndexEntrySize)
 }
+
+func TestNumericDecoder_WithValueTransform(t *testing.T) {
+	startTime := time.Now()
+	const (
+		scaledID = uint64(1)
+		plainID  = uint64(2)
+		count    = 5
+	)
+
+	transform := func(metricID uint64, v float64) float64 {
+		if metricID == scaledID {
+			return v * 1000
+		}
+
+		return v
+	}
+
+	encodings := []format.EncodingType{format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP}
+	for _, valEnc := range encodings {
+		t.Run(valEnc.String(), func(t *testing.T) {
+			encoder, err := NewNumericEncoder(startTime, WithValueEncoding(valEnc), WithTagsEnabled(true))
+			require.NoError(t, err)
+
+			for _, id := range []uint64{scaledID, plainID} {
+				require.NoError(t, encoder.StartMetricID(id, count))
+				for i := range count {
+					ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+					require.NoError(t, encoder.AddDataPoint(ts, float64(i)+0.5, "t"))
+				}
+				require.NoError(t, encoder.EndMetric())
+			}
+
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			decoder, err := NewNumericDecoder(data, WithValueTransform(transform))
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			expected := func(id uint64, i int) float64 {
+				return transform(id, float64(i)+0.5)
+			}
+
+			for _, id := range []uint64{scaledID, plainID} {
+				for i, dp := range blob.All(id) {
+					require.Equal(t, expected(id, i), dp.Val)
+				}
+
+				i := 0
+				for v := range blob.AllValues(id) {
+					require.Equal(t, expected(id, i), v)
+					i++
+				}
+				require.Equal(t, count, i)
+
+				for i := range count {
+					v, ok := blob.ValueAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, expected(id, i), v)
+				}
+
+				blob.ForEach(id, func(i int, dp NumericDataPoint) bool {
+					require.Equal(t, expected(id, i), dp.Val)
+					return true
+				})
+				blob.ForEachValues(id, func(i int, v float64) bool {
+					require.Equal(t, expected(id, i), v)
+					return true
+				})
+
+				metric, ok := blob.MaterializeMetric(id)
+				require.True(t, ok)
+				for i, v := range metric.Values {
+					require.Equal(t, expected(id, i), v)
+				}
+
+				material := blob.Materialize()
+				for i := range count {
+					v, ok := material.ValueAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, expected(id, i), v)
+				}
+			}
+		})
+	}
+
+	t.Run("NilTransform", func(t *testing.T) {
+		encoder, err := NewNumericEncoder(startTime)
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(scaledID, 1))
+		require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1.5, ""))
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data, WithValueTransform(nil))
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		v, ok := blob.ValueAt(scaledID, 0)
+		require.True(t, ok)
+		require.Equal(t, 1.5, v)
+	})
+}
+
+func TestNumericDecoder_InvalidMetricReference(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, WithMetricReferences())
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricID(100, 1))
+	require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1.5, ""))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.StartMetricIDWithReference(50, 100, 1))
+	require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1.6, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	// Locate the (50, 100) reference pair and point it at unknown / cyclic metrics
+	engine := endian.GetLittleEndianEngine()
+	pair := make([]byte, 16)
+	engine.PutUint64(pair, 50)
+	engine.PutUint64(pair[8:], 100)
+	pos := bytes.Index(data, pair)
+	require.Positive(t, pos)
+
+	for _, refID := range []uint64{999, 50} {
+		corrupt := bytes.Clone(data)
+		engine.PutUint64(corrupt[pos+8:], refID)
+
+		decoder, err := NewNumericDecoder(corrupt)
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.ErrorIs(t, err, errs.ErrInvalidMetricReference)
+	}
+}
+
+func TestNumericDecoder_DecodePartial(t *testing.T) {
+	startTime := time.Now()
+	encode := func(t *testing.T, valComp format.CompressionType) []byte {
+		t.Helper()
+
+		encoder, err := NewNumericEncoder(startTime,
+			WithBlobLayoutV2(),
+			WithMetricReferences(),
+			WithTagsEnabled(true),
+			WithTimestampCompression(format.CompressionNone),
+			WithValueCompression(valComp),
+		)
+		require.NoError(t, err)
+
+		// Metric 10 references metric 30; the V2 payload is ordered 10, 20, 30.
+		for _, id := range []uint64{30, 20, 10} {
+			if id == 10 {
+				require.NoError(t, encoder.StartMetricIDWithReference(id, 30, 4))
+			} else {
+				require.NoError(t, encoder.StartMetricID(id, 4))
+			}
+			for i := range 4 {
+				ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+				require.NoError(t, encoder.AddDataPoint(ts, float64(id)+float64(i)*0.5, "tag"))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	decodePartial := func(t *testing.T, data []byte) (NumericBlob, NumericDecodeReport, error) {
+		t.Helper()
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+
+		return decoder.DecodePartial()
+	}
+
+	data := encode(t, format.CompressionNone)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	expected, err := decoder.Decode()
+	require.NoError(t, err)
+
+	t.Run("Intact", func(t *testing.T) {
+		blob, report, err := decodePartial(t, data)
+		require.NoError(t, err)
+		require.True(t, report.IsComplete())
+		require.Equal(t, []uint64{10, 20, 30}, report.Recovered)
+		require.True(t, blob.HasTag())
+
+		for _, id := range report.Recovered {
+			require.Equal(t, slices.Collect(expected.AllValues(id)), slices.Collect(blob.AllValues(id)))
+			require.Equal(t, slices.Collect(expected.AllTags(id)), slices.Collect(blob.AllTags(id)))
+		}
+	})
+
+	t.Run("TruncatedValues", func(t *testing.T) {
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		entry, ok := expected.index.GetByID(30)
+		require.True(t, ok)
+
+		blob, report, err := decodePartial(t, data[:int(header.ValuePayloadOffset)+entry.ValueOffset+1])
+		require.NoError(t, err)
+		require.False(t, report.IsComplete())
+		require.True(t, report.TagsLost)
+		require.Equal(t, []uint64{20}, report.Recovered)
+		require.Equal(t, []uint64{10, 30}, report.Lost, "metric 10 references the lost metric 30")
+
+		require.Equal(t, 1, blob.MetricCount())
+		require.False(t, blob.HasTag())
+		require.Equal(t, slices.Collect(expected.AllValues(20)), slices.Collect(blob.AllValues(20)))
+		require.Equal(t, slices.Collect(expected.AllTimestamps(20)), slices.Collect(blob.AllTimestamps(20)))
+	})
+
+	t.Run("TruncatedTags", func(t *testing.T) {
+		blob, report, err := decodePartial(t, data[:len(data)-1])
+		require.NoError(t, err)
+		require.True(t, report.TagsLost)
+		require.Empty(t, report.Lost)
+		require.Equal(t, slices.Collect(expected.AllValues(10)), slices.Collect(blob.AllValues(10)))
+	})
+
+	t.Run("TruncatedCompressedValues", func(t *testing.T) {
+		compressed := encode(t, format.CompressionZstd)
+		header, err := section.ParseNumericHeader(compressed)
+		require.NoError(t, err)
+
+		blob, report, err := decodePartial(t, compressed[:header.ValuePayloadOffset+2])
+		require.NoError(t, err)
+		require.Empty(t, report.Recovered)
+		require.Equal(t, []uint64{10, 20, 30}, report.Lost)
+		require.Zero(t, blob.MetricCount())
+	})
+
+	t.Run("TruncatedIndex", func(t *testing.T) {
+		_, _, err := decodePartial(t, data[:section.HeaderSize+4])
+		require.Error(t, err)
+	})
+}
+
+func TestNumericDecoder_WithStringInterning(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "DeltaGorilla", opts: nil},
+		{name: "RawRaw", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)}},
+		{name: "DeltaPackedChimp", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeDeltaPacked), WithValueEncoding(format.TypeChimp)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, append([]NumericEncoderOption{WithTagsEnabled(true)}, tt.opts...)...)
+
+			decoder, err := NewNumericDecoder(data, WithStringInterning(true))
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			first, ok := blob.TagAt(1, 0)
+			require.True(t, ok)
+			require.Equal(t, "tag", first)
+
+			same := func(tag string) {
+				require.Equal(t, first, tag)
+				require.Same(t, unsafe.StringData(first), unsafe.StringData(tag))
+			}
+
+			for id := uint64(1); id <= 3; id++ {
+				for tag := range blob.AllTags(id) {
+					same(tag)
+				}
+				for _, dp := range blob.All(id) {
+					same(dp.Tag)
+				}
+				tag, ok := blob.TagAt(id, 9)
+				require.True(t, ok)
+				same(tag)
+			}
+
+			material := blob.Materialize()
+			tag, ok := material.TagAt(3, 5)
+			require.True(t, ok)
+			same(tag)
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		data := encodePartsTestBlob(t, WithTagsEnabled(true))
+		decoder, err := NewNumericDecoder(data, WithStringInterning(false))
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		a, _ := blob.TagAt(1, 0)
+		b, _ := blob.TagAt(1, 1)
+		require.Equal(t, a, b)
+		require.NotSame(t, unsafe.StringData(a), unsafe.StringData(b))
+	})
+}
+
+func TestNumericDecoder_WithLazyIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1", opts: nil},
+		{name: "V1Sorted", opts: []NumericEncoderOption{WithSortedIndex(true)}},
+		{name: "V2", opts: []NumericEncoderOption{WithBlobLayoutV2(), WithSharedTimestamps()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, append([]NumericEncoderOption{WithTagsEnabled(true)}, tt.opts...)...)
+
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			eager, err := decoder.Decode()
+			require.NoError(t, err)
+
+			decoder, err = NewNumericDecoder(data, WithLazyIndex(true))
+			require.NoError(t, err)
+			lazy, err := decoder.Decode()
+			require.NoError(t, err)
+
+			require.NotNil(t, lazy.index.lazy)
+			require.Nil(t, lazy.index.lazy.index.byID)
+			require.Nil(t, lazy.index.lazy.index.sorted)
+
+			require.ElementsMatch(t, eager.MetricIDs(), lazy.MetricIDs())
+			require.True(t, lazy.index.lazy.index.byID != nil || lazy.index.lazy.index.sorted != nil)
+			require.Nil(t, lazy.index.lazy.build)
+
+			for id := uint64(1); id <= 3; id++ {
+				require.Equal(t, eager.Len(id), lazy.Len(id))
+				require.Equal(t, slices.Collect(eager.AllValues(id)), slices.Collect(lazy.AllValues(id)))
+			}
+			require.Equal(t, eager.Materialize(), lazy.Materialize())
+		})
+	}
+}
+
+func TestNumericDecoder_WithLazyIndex_MetricNames(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime)
+	require.NoError(t, err)
+
+	for _, name := range []string{"cpu.usage", "mem.used"} {
+		require.NoError(t, encoder.StartMetricName(name, 2))
+		for i := range 2 {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data, WithLazyIndex(true))
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Nil(t, blob.index.lazy.index.byID)
+
+	require.Equal(t, 2, blob.LenByName("mem.used"))
+	require.True(t, blob.HasMetricName("cpu.usage"))
+	require.False(t, blob.HasMetricName("disk.io"))
+}
+
+func TestNumericDecoder_WithLazyIndex_ConcurrentFirstLookup(t *testing.T) {
+	data := encodePartsTestBlob(t)
+
+	decoder, err := NewNumericDecoder(data, WithLazyIndex(true))
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for id := uint64(1); id <= 3; id++ {
+				if blob.Len(id) != 10 {
+					t.Errorf("metric %d: unexpected length %d", id, blob.Len(id))
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
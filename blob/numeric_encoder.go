@@ -10,7 +10,9 @@ import (
 
 	"github.com/cespare/xxhash/v2"
 
+	"github.com/arloliu/mebo/compress"
 	"github.com/arloliu/mebo/encoding"
+	"github.com/arloliu/mebo/endian"
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/collision"
@@ -18,6 +20,7 @@ import (
 	"github.com/arloliu/mebo/internal/hash"
 	"github.com/arloliu/mebo/internal/options"
 	"github.com/arloliu/mebo/internal/pool"
+	"github.com/arloliu/mebo/internal/sketch"
 	"github.com/arloliu/mebo/section"
 )
 
@@ -45,9 +48,9 @@ const (
 	// - Full collision tracker allocated
 	modeNameManaged
 
-	// maxBlobBytes is the largest blob size that can be represented by uint32 header offsets.
+	// maxBlobBytes is the largest blob size that can be represented by the uint32
+	// payload offsets of a v1 header; larger blobs use the wide header.
 	// On 64-bit: math.MaxUint32 (~4 GB); on 32-bit: math.MaxInt32 (~2 GB).
-	// This mirrors section.maxSafeUint32 and is optimized away by the compiler on 64-bit.
 	maxBlobBytes = math.MaxUint32 & math.MaxInt
 
 	// maxCachedSliceSize is the maximum size of cached slices for AddFromRows operations.
@@ -77,7 +80,14 @@ type NumericEncoder struct {
 
 	curMetricID uint64 // current metric ID being encoded
 	claimed     int    // number of data points claimed for the current metric
+	hinted      bool   // claimed is an upper bound set by StartMetricIDHint or StartMetricNameHint
 	curPoints   int    // number of data points added to the current metric
+	lastValidTs int64  // last timestamp accepted by AddDataPointsValidated for the current metric
+	hasValidTs  bool   // whether lastValidTs is set
+	curMinTs    int64  // earliest timestamp of the current metric (WithTimeBounds only)
+	curMaxTs    int64  // latest timestamp of the current metric (WithTimeBounds only)
+	winLo       int64  // inclusive start of the time window in the timestamp unit (WithTimeWindow only)
+	winHi       int64  // exclusive end of the time window in the timestamp unit (WithTimeWindow only)
 	hasTag      bool   // cached header.Flag.HasTag() for the per-point hot path
 
 	// Encoder state tracking: groups related fields for better cache locality.
@@ -103,6 +113,7 @@ type NumericEncoder struct {
 	// This keeps the original header immutable for future stateless encoder pattern
 	hasCollision    bool // Set when hash collision detected, applied to cloned header in Finish()
 	hasNonEmptyTags bool // Set when any non-empty tag is written, used to optimize empty-tag-only blobs
+	tagsAtStart     bool // hasNonEmptyTags when the current metric started, restored by AbortMetric
 
 	// Reusable slices for AddFromRows - cached across multiple metrics to reduce pool overhead
 	// These slices are:
@@ -112,12 +123,49 @@ type NumericEncoder struct {
 	cachedTimestamps []int64
 	cachedValues     []float64
 	cachedTags       []string
+	// Scratch buffer for prepared values in AddDataPoints (caller slices are never mutated)
+	valBuf []float64
+
+	// Delta-against-reference state (WithMetricReferences only)
+	retained  map[uint64][]float64 // values of completed metrics, keyed by metric ID
+	curValues []float64            // values of the current metric, retained at EndMetric
+	curRef    []float64            // reference values of the current metric (nil if none)
+	curRefID  uint64               // reference metric ID of the current metric
+	refs      []metricReference    // completed metrics stored as deltas against a reference
+
+	minTs        int64      // earliest timestamp of completed metrics (WithTimeBounds only)
+	maxTs        int64      // latest timestamp of completed metrics (WithTimeBounds only)
+	hasBounds    bool       // whether minTs and maxTs are set
+	metricBounds [][2]int64 // earliest and latest timestamp of each completed metric (WithTimeBounds only)
+
+	curMeta MetricMeta        // kind and unit of the current metric (zero if none)
+	metas   []metricMetaEntry // kind and unit of completed metrics, see StartMetricIDWithMeta
+
+	curSketch     *sketch.TDigest  // digest of the current metric's values (WithSketches only)
+	sketchEntries []metricDirEntry // sketch directory of completed metrics
+	sketchBodies  []byte           // serialized sketches of completed metrics
+
+	curTagCounts      map[string]int   // non-empty tag counts of the current metric (WithTagSummaries only)
+	tagSummaryEntries []metricDirEntry // tag summary directory of completed metrics
+	tagSummaryBodies  []byte           // serialized tag summaries of completed metrics
+
+	curSum     sketch.KahanSum  // compensated sum of the current metric's values (WithValueSums only)
+	sumEntries []metricDirEntry // value sum directory of completed metrics
+	sumBodies  []byte           // serialized value sums of completed metrics
+
+	spill *columnSpill // spilled column bytes of completed metrics (WithMaxEncoderMemory only)
 	// Cleanup functions for returning slices to pool
 	cleanupTS  func()
 	cleanupVal func()
 	cleanupTag func()
 }
 
+// metricReference records a metric stored as deltas against a reference metric.
+type metricReference struct {
+	metricID uint64
+	refID    uint64
+}
+
 // tsGroup represents a group of metrics sharing identical timestamp sequences.
 type tsGroup struct {
 	canonicalIdx int   // index in indexEntries of the canonical metric
@@ -194,7 +242,7 @@ func (e *NumericEncoder) MaxDataPoints() int {
 
 	maxBytes := max(tsBytes, valBytes)
 
-	return section.NumericMaxOffset / maxBytes
+	return section.NumericMaxOffset * max(e.offsetUnit, 1) / maxBytes
 }
 
 // NewNumericEncoder creates a new NumericEncoder with the given start time.
@@ -224,42 +272,80 @@ func NewNumericEncoder(blobTS time.Time, opts ...NumericEncoderOption) (*Numeric
 		return nil, err
 	}
 
-	enc := encoder.header.Flag.ValueEncoding()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.window {
+		encoder.winLo = config.tsUnit.Timestamp(config.windowStart)
+		encoder.winHi = config.tsUnit.Timestamp(config.windowEnd)
+	}
+
+	if err := encoder.newColumnEncoders(); err != nil {
+		return nil, err
+	}
+	encoder.hasTag = encoder.header.Flag.HasTag()
+
+	if encoder.edgePoints > 0 {
+		encoder.preallocateEdge()
+	}
+
+	if encoder.metricRefs {
+		encoder.retained = make(map[uint64][]float64)
+	}
+
+	if encoder.sketches {
+		encoder.curSketch = sketch.NewTDigest(sketch.DefaultCompression)
+	}
+
+	if encoder.tagSummaries && encoder.hasTag {
+		encoder.curTagCounts = make(map[string]int)
+	}
+
+	if err := encoder.setCodecs(*encoder.header); err != nil {
+		return nil, err
+	}
+
+	return encoder, nil
+}
+
+// newColumnEncoders creates empty timestamp, value and tag column encoders for
+// the configured encodings.
+func (e *NumericEncoder) newColumnEncoders() error {
+	enc := e.header.Flag.ValueEncoding()
 	switch enc { //nolint:exhaustive // TypeDeltaPacked is timestamp-only encoding
 	case format.TypeRaw:
-		encoder.valEncoder = ienc.NewNumericRawEncoder(encoder.engine)
+		e.valEncoder = ienc.NewNumericRawEncoder(e.engine)
 	case format.TypeGorilla:
-		encoder.valEncoder = ienc.NewNumericGorillaEncoder()
+		e.valEncoder = ienc.NewNumericGorillaEncoderWithTuning(e.gorillaTuning)
 	case format.TypeChimp:
-		encoder.valEncoder = ienc.NewNumericChimpEncoder()
+		e.valEncoder = ienc.NewNumericChimpEncoder()
 	case format.TypeALP:
-		encoder.valEncoder = ienc.NewNumericALPEncoder(encoder.engine)
+		e.valEncoder = ienc.NewNumericALPEncoder(e.engine)
+	case format.TypeExtension:
+		e.valEncoder = e.extEncoder
 	case format.TypeDelta:
-		return nil, fmt.Errorf("%w: value encoding %s not supported yet", errs.ErrUnsupportedEncoding, enc.String())
+		// Delta-of-delta values carry int64 bit patterns and are only selected by Int64Encoder.
+		e.valEncoder = int64ColumnEncoder{ienc.NewTimestampDeltaEncoder()}
 	default:
-		return nil, fmt.Errorf("%w: invalid value encoding %s", errs.ErrUnsupportedEncoding, enc.String())
+		return fmt.Errorf("%w: invalid value encoding %s", errs.ErrUnsupportedEncoding, enc.String())
 	}
 
-	enc = encoder.header.Flag.TimestampEncoding()
+	enc = e.header.Flag.TimestampEncoding()
 	switch enc { //nolint: exhaustive
 	case format.TypeRaw:
-		encoder.tsEncoder = ienc.NewTimestampRawEncoder(encoder.engine)
+		e.tsEncoder = ienc.NewTimestampRawEncoder(e.engine)
 	case format.TypeDelta:
-		encoder.tsEncoder = ienc.NewTimestampDeltaEncoder()
+		e.tsEncoder = ienc.NewTimestampDeltaEncoder()
 	case format.TypeDeltaPacked:
-		encoder.tsEncoder = ienc.NewTimestampDeltaPackedEncoder()
+		e.tsEncoder = ienc.NewTimestampDeltaPackedEncoder()
 	default:
-		return nil, fmt.Errorf("%w: invalid timestamp encoding %s", errs.ErrUnsupportedEncoding, enc.String())
+		return fmt.Errorf("%w: invalid timestamp encoding %s", errs.ErrUnsupportedEncoding, enc.String())
 	}
 
-	encoder.tagEncoder = ienc.NewTagEncoder(encoder.engine)
-	encoder.hasTag = encoder.header.Flag.HasTag()
-
-	if err := encoder.setCodecs(*encoder.header); err != nil {
-		return nil, err
-	}
+	e.tagEncoder = ienc.NewTagEncoder(e.engine)
 
-	return encoder, nil
+	return nil
 }
 
 // StartMetricID begins encoding a new metric with the specified unique identifier and number of data points.
@@ -307,6 +393,15 @@ func (e *NumericEncoder) StartMetricID(metricID uint64, numOfDataPoints int) err
 		return fmt.Errorf("%w: metric count exceeded: max %d", errs.ErrMetricCountExceeded, MaxMetricCount)
 	}
 
+	// Edge profile encoders scan their preallocated index instead of a map
+	if e.edgePoints > 0 {
+		if err := e.claimEdgeMetric(metricID, numOfDataPoints); err != nil {
+			return err
+		}
+
+		return e.startMetric(metricID, numOfDataPoints)
+	}
+
 	// In ID mode, use simple map for duplicate detection, is much lighter than collision tracker
 	if e.usedIDs == nil {
 		e.usedIDs = make(map[uint64]struct{})
@@ -320,6 +415,130 @@ func (e *NumericEncoder) StartMetricID(metricID uint64, numOfDataPoints int) err
 	return e.startMetric(metricID, numOfDataPoints)
 }
 
+// StartMetricIDWithMeta begins encoding a new metric like StartMetricID and declares
+// its kind and unit.
+//
+// The metadata is stored in the optional metadata section of the blob and returned
+// by NumericBlob.MetricMeta, so exporters can map counters, gauges and histogram
+// series without an external schema registry. A zero MetricMeta records nothing.
+//
+// Parameters:
+//   - metricID: Unique 64-bit metric identifier (must be non-zero)
+//   - numOfDataPoints: Expected number of data points (1 to MaxDataPoints())
+//   - meta: Kind and unit of the metric
+//
+// Returns:
+//   - error: ErrInvalidMetricMeta for an unknown kind or a unit longer than
+//     MaxMetricUnitLength; otherwise the same errors as StartMetricID
+//
+// Example:
+//
+//	err := encoder.StartMetricIDWithMeta(metricID, len(timestamps), blob.MetricMeta{
+//	    Kind: format.MetricKindCounter,
+//	    Unit: "bytes",
+//	})
+func (e *NumericEncoder) StartMetricIDWithMeta(metricID uint64, numOfDataPoints int, meta MetricMeta) error {
+	if err := meta.validate(); err != nil {
+		return err
+	}
+
+	if err := e.StartMetricID(metricID, numOfDataPoints); err != nil {
+		return err
+	}
+	e.curMeta = meta
+
+	return nil
+}
+
+// StartMetricIDHint begins encoding a new metric like StartMetricID, but without
+// committing to an exact number of data points.
+//
+// The capacity hint only sizes internal buffers. Any number of data points up to
+// MaxDataPoints() may be added, and EndMetric accepts whatever count was added
+// (at least one), so streaming producers need not pre-count their data points.
+//
+// Parameters:
+//   - metricID: Unique 64-bit metric identifier (must be non-zero)
+//   - capacityHint: Expected number of data points (0 to MaxDataPoints(), 0 if unknown)
+//
+// Returns:
+//   - error: The same errors as StartMetricID
+//
+// Example:
+//
+//	encoder.StartMetricIDHint(metricID, 128)
+//	for sample := range samples {
+//	    encoder.AddDataPoint(sample.Ts, sample.Value, "")
+//	}
+//	encoder.EndMetric()
+func (e *NumericEncoder) StartMetricIDHint(metricID uint64, capacityHint int) error {
+	if capacityHint < 0 {
+		return fmt.Errorf("%w: negative capacity hint %d", errs.ErrInvalidNumOfDataPoints, capacityHint)
+	}
+
+	if err := e.StartMetricID(metricID, max(capacityHint, 1)); err != nil {
+		return err
+	}
+	e.relaxClaim()
+
+	return nil
+}
+
+// relaxClaim turns the claimed count of the current metric into an upper bound.
+func (e *NumericEncoder) relaxClaim() {
+	e.claimed = e.MaxDataPoints()
+	if e.edgePoints > 0 {
+		e.claimed = min(e.claimed, e.edgeRemaining())
+	}
+	e.hinted = true
+}
+
+// StartMetricIDWithReference begins encoding a new metric whose values are stored as
+// deltas against a previously encoded reference metric.
+//
+// The difference is computed on the IEEE 754 bit patterns, so reconstruction is
+// exact for every value, including NaN and ±Inf. Closely tracking series yield
+// small differences that compress well with Gorilla and Chimp value encodings.
+// Decoders reconstruct the original values transparently; the reference metric
+// is unaffected.
+//
+// Requires the WithMetricReferences option. The reference metric must have been
+// ended already, and must have exactly numOfDataPoints data points. A reference
+// metric may itself reference another metric.
+//
+// Parameters:
+//   - metricID: Unique 64-bit metric identifier (must be non-zero)
+//   - refMetricID: ID of the already-encoded reference metric
+//   - numOfDataPoints: Expected number of data points (must equal the reference's count)
+//
+// Returns:
+//   - error: ErrInvalidMetricReference if references are not enabled, the reference
+//     metric is unknown, or the counts differ; otherwise the same errors as StartMetricID
+func (e *NumericEncoder) StartMetricIDWithReference(metricID, refMetricID uint64, numOfDataPoints int) error {
+	if e.retained == nil {
+		return fmt.Errorf("%w: metric references are not enabled, use WithMetricReferences", errs.ErrInvalidMetricReference)
+	}
+
+	refValues, ok := e.retained[refMetricID]
+	if !ok {
+		return fmt.Errorf("%w: reference metric ID 0x%016x has not been encoded", errs.ErrInvalidMetricReference, refMetricID)
+	}
+
+	if len(refValues) != numOfDataPoints {
+		return fmt.Errorf("%w: reference metric has %d data points, got %d",
+			errs.ErrInvalidMetricReference, len(refValues), numOfDataPoints)
+	}
+
+	if err := e.StartMetricID(metricID, numOfDataPoints); err != nil {
+		return err
+	}
+
+	e.curRef = refValues
+	e.curRefID = refMetricID
+
+	return nil
+}
+
 // startMetric is the internal method that actually starts a metric.
 // It does NOT do collision checking - caller is responsible for that.
 func (e *NumericEncoder) startMetric(metricID uint64, numOfDataPoints int) error {
@@ -331,7 +550,20 @@ func (e *NumericEncoder) startMetric(metricID uint64, numOfDataPoints int) error
 	// Set current metric state
 	e.curMetricID = metricID
 	e.claimed = numOfDataPoints
+	e.hinted = false
 	e.curPoints = 0
+	e.hasValidTs = false
+	e.tagsAtStart = e.hasNonEmptyTags
+
+	if e.retained != nil {
+		e.curValues = make([]float64, 0, numOfDataPoints)
+	}
+
+	if e.curSketch != nil {
+		e.curSketch.Reset()
+	}
+	clear(e.curTagCounts)
+	e.curSum = sketch.KahanSum{}
 
 	return nil
 }
@@ -357,12 +589,17 @@ func (e *NumericEncoder) startMetric(metricID uint64, numOfDataPoints int) error
 //
 // Returns:
 //   - error: ErrMetricAlreadyStarted, ErrMixedIdentifierMode, ErrInvalidMetricName,
-//     ErrInvalidNumOfDataPoints, or ErrMetricCountExceeded
+//     ErrInvalidNumOfDataPoints, ErrMetricCountExceeded, or ErrUnsupportedBlobFeature
+//     for WithEdgeProfile encoders
 func (e *NumericEncoder) StartMetricName(metricName string, numOfDataPoints int) error {
 	if e.curMetricID != 0 {
 		return fmt.Errorf("%w: metric ID %d is already started", errs.ErrMetricAlreadyStarted, e.curMetricID)
 	}
 
+	if e.edgePoints > 0 {
+		return fmt.Errorf("%w: edge profile encoders identify metrics by ID, use StartMetricID", errs.ErrUnsupportedBlobFeature)
+	}
+
 	// Check mode exclusivity - cannot mix Name mode with ID mode
 	if e.identifierMode == modeUserID {
 		return fmt.Errorf("%w: cannot use StartMetricName after StartMetricID", errs.ErrMixedIdentifierMode)
@@ -401,6 +638,53 @@ func (e *NumericEncoder) StartMetricName(metricName string, numOfDataPoints int)
 	return e.startMetric(metricID, numOfDataPoints)
 }
 
+// StartMetricNameHint begins encoding a new metric like StartMetricName, but
+// without committing to an exact number of data points. See StartMetricIDHint
+// for details.
+//
+// Parameters:
+//   - metricName: Metric name string (must be non-empty)
+//   - capacityHint: Expected number of data points (0 to MaxDataPoints(), 0 if unknown)
+//
+// Returns:
+//   - error: The same errors as StartMetricName
+func (e *NumericEncoder) StartMetricNameHint(metricName string, capacityHint int) error {
+	if capacityHint < 0 {
+		return fmt.Errorf("%w: negative capacity hint %d", errs.ErrInvalidNumOfDataPoints, capacityHint)
+	}
+
+	if err := e.StartMetricName(metricName, max(capacityHint, 1)); err != nil {
+		return err
+	}
+	e.relaxClaim()
+
+	return nil
+}
+
+// StartMetricNameWithMeta begins encoding a new metric like StartMetricName and
+// declares its kind and unit. See StartMetricIDWithMeta for details.
+//
+// Parameters:
+//   - metricName: Metric name string (must be non-empty)
+//   - numOfDataPoints: Expected number of data points (1 to MaxDataPoints())
+//   - meta: Kind and unit of the metric
+//
+// Returns:
+//   - error: ErrInvalidMetricMeta for an unknown kind or a unit longer than
+//     MaxMetricUnitLength; otherwise the same errors as StartMetricName
+func (e *NumericEncoder) StartMetricNameWithMeta(metricName string, numOfDataPoints int, meta MetricMeta) error {
+	if err := meta.validate(); err != nil {
+		return err
+	}
+
+	if err := e.StartMetricName(metricName, numOfDataPoints); err != nil {
+		return err
+	}
+	e.curMeta = meta
+
+	return nil
+}
+
 // EndMetric completes the encoding of the current metric and prepares the encoder for the next metric.
 //
 // This method should be called after all data points have been added via AddDataPoint or AddDataPoints.
@@ -408,7 +692,9 @@ func (e *NumericEncoder) StartMetricName(metricName string, numOfDataPoints int)
 //
 // Returns:
 //   - error: ErrNoMetricStarted, ErrNoDataPointsAdded, ErrDataPointCountMismatch,
-//     or ErrOffsetOutOfRange if offset deltas exceed uint16 range
+//     ErrInvalidMetricReference if data points of a metric started with
+//     StartMetricIDWithReference were dropped, or ErrOffsetOutOfRange if offset
+//     deltas exceed uint16 range
 func (e *NumericEncoder) EndMetric() error {
 	if e.curMetricID == 0 {
 		return errs.ErrNoMetricStarted
@@ -417,8 +703,9 @@ func (e *NumericEncoder) EndMetric() error {
 	// For bit-packed encodings (Gorilla, Chimp), we need to flush any pending bits
 	// BEFORE calculating lengths. This ensures the length includes all flushed data.
 	// For other encodings, this is a no-op as Bytes() just returns the buffer.
+	// Extension encoders are flushed too, since their buffering is unknown.
 	valEnc := e.header.Flag.ValueEncoding()
-	if valEnc == format.TypeGorilla || valEnc == format.TypeChimp || valEnc == format.TypeALP {
+	if valEnc == format.TypeGorilla || valEnc == format.TypeChimp || valEnc == format.TypeALP || valEnc == format.TypeExtension {
 		_ = e.valEncoder.Bytes() // Flush pending bits
 	}
 
@@ -489,13 +776,131 @@ func (e *NumericEncoder) EndMetric() error {
 	e.valEncoder.Reset()
 	e.tagEncoder.Reset()
 
+	// Retain values for later references and record this metric's reference
+	if e.retained != nil {
+		e.retained[e.curMetricID] = e.curValues
+		if e.curRef != nil {
+			e.refs = append(e.refs, metricReference{metricID: e.curMetricID, refID: e.curRefID})
+		}
+		e.curValues = nil
+		e.curRef = nil
+		e.curRefID = 0
+	}
+
+	if !e.curMeta.IsZero() {
+		e.metas = append(e.metas, metricMetaEntry{metricID: e.curMetricID, meta: e.curMeta})
+		e.curMeta = MetricMeta{}
+	}
+
+	if e.curSketch != nil {
+		e.sketchEntries = append(e.sketchEntries, metricDirEntry{metricID: e.curMetricID, offset: len(e.sketchBodies)})
+		e.sketchBodies = appendSketchBody(e.sketchBodies, e.curSketch, e.engine)
+	}
+
+	if e.curTagCounts != nil {
+		e.tagSummaryEntries = append(e.tagSummaryEntries, metricDirEntry{metricID: e.curMetricID, offset: len(e.tagSummaryBodies)})
+		e.tagSummaryBodies = appendTagSummaryBody(e.tagSummaryBodies, summarizeTags(e.curTagCounts))
+	}
+
+	if e.valueSums {
+		e.sumEntries = append(e.sumEntries, metricDirEntry{metricID: e.curMetricID, offset: len(e.sumBodies)})
+		e.sumBodies = appendValueSumBody(e.sumBodies, e.curSum, e.engine)
+	}
+
+	if e.timeBounds {
+		if !e.hasBounds {
+			e.minTs, e.maxTs, e.hasBounds = e.curMinTs, e.curMaxTs, true
+		}
+		e.minTs, e.maxTs = min(e.minTs, e.curMinTs), max(e.maxTs, e.curMaxTs)
+		e.metricBounds = append(e.metricBounds, [2]int64{e.curMinTs, e.curMaxTs})
+	}
+
 	// Reset current metric state
 	e.curMetricID = 0
 	e.claimed = 0
+	e.hinted = false
+
+	if e.maxMemory > 0 && e.tsEncoder.Size()+e.valEncoder.Size()+e.tagEncoder.Size() > e.maxMemory {
+		return e.spillColumns()
+	}
+
+	return nil
+}
+
+// spillColumns appends the encoded columns of all completed metrics to the spill
+// files and replaces the column encoders with empty ones.
+//
+// It must be called between metrics. Offset state is rebased onto the new, empty
+// encoders: the next metric starts at offset 0, and the last metric start moves
+// back by the spilled size so the next offset delta still spans the last metric.
+func (e *NumericEncoder) spillColumns() error {
+	if e.spill == nil {
+		e.spill = &columnSpill{dir: e.spillDir}
+	}
+
+	return e.moveColumnsToSpill(e.tsEncoder.Bytes(), e.valEncoder.Bytes(), e.tagEncoder.Bytes())
+}
+
+// moveColumnsToSpill appends the given column bytes to the spill, replaces the
+// column encoders with empty ones and rebases the offset state like spillColumns.
+// Bytes the encoders hold beyond the given ones are discarded.
+func (e *NumericEncoder) moveColumnsToSpill(tsBytes, valBytes, tagBytes []byte) error {
+	if err := e.spill.write(spillTs, tsBytes); err != nil {
+		return err
+	}
+	if err := e.spill.write(spillVal, valBytes); err != nil {
+		return err
+	}
+	if err := e.spill.write(spillTag, tagBytes); err != nil {
+		return err
+	}
+
+	e.ts = encoderState{lastOffset: e.ts.lastOffset - len(tsBytes)}
+	e.val = encoderState{lastOffset: e.val.lastOffset - len(valBytes)}
+	e.tag = encoderState{lastOffset: e.tag.lastOffset - len(tagBytes)}
+
+	e.tsEncoder.Finish()
+	e.valEncoder.Finish()
+	e.tagEncoder.Finish()
+
+	if err := e.newColumnEncoders(); err != nil {
+		return err
+	}
+	if e.edgePoints > 0 {
+		e.growEdgeColumns()
+	}
 
 	return nil
 }
 
+// rawColumns returns the complete encoded timestamp, value and tag columns,
+// reading spilled metrics back ahead of the bytes still held in memory.
+func (e *NumericEncoder) rawColumns(hasTag bool) (rawTs, rawVal, rawTag []byte, err error) {
+	rawTs = e.tsEncoder.Bytes()
+	rawVal = e.valEncoder.Bytes()
+	if hasTag {
+		rawTag = e.tagEncoder.Bytes()
+	}
+
+	if e.spill == nil {
+		return rawTs, rawVal, rawTag, nil
+	}
+
+	if rawTs, err = e.spill.column(spillTs, rawTs); err != nil {
+		return nil, nil, nil, err
+	}
+	if rawVal, err = e.spill.column(spillVal, rawVal); err != nil {
+		return nil, nil, nil, err
+	}
+	if hasTag {
+		if rawTag, err = e.spill.column(spillTag, rawTag); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return rawTs, rawVal, rawTag, nil
+}
+
 func (e *NumericEncoder) validateMetricData(curTsLen int, curValLen int, curTagLen int) error {
 	// Ensure at least one data point was added
 	if curTsLen == 0 || curValLen == 0 {
@@ -507,26 +912,37 @@ func (e *NumericEncoder) validateMetricData(curTsLen int, curValLen int, curTagL
 		return fmt.Errorf("%w: %d timestamps, %d values", errs.ErrDataPointCountMismatch, curTsLen, curValLen)
 	}
 
-	// Validate that exactly the claimed number of data points were added
-	if curTsLen != e.claimed {
+	// Validate that exactly the claimed number of data points were added,
+	// unless the claim is only a capacity hint
+	if !e.hinted && curTsLen != e.claimed {
 		return fmt.Errorf("%w: claimed %d, got %d", errs.ErrDataPointCountMismatch, e.claimed, curTsLen)
 	}
 
+	// Deltas are reconstructed against every reference value, so a metric whose
+	// data points were dropped or rejected cannot be stored against its reference
+	if e.curRef != nil && curTsLen != len(e.curRef) {
+		return fmt.Errorf("%w: metric ID 0x%016x has %d data points, reference has %d",
+			errs.ErrInvalidMetricReference, e.curMetricID, curTsLen, len(e.curRef))
+	}
+
 	// Tag count must match data point count (tags can be empty strings) - only check if tags are enabled
-	if e.header.Flag.HasTag() && curTagLen != e.claimed {
-		return fmt.Errorf("%w: claimed %d, got %d tags", errs.ErrDataPointCountMismatch, e.claimed, curTagLen)
+	if e.header.Flag.HasTag() && curTagLen != curTsLen {
+		return fmt.Errorf("%w: %d data points, got %d tags", errs.ErrDataPointCountMismatch, curTsLen, curTagLen)
 	}
 
 	return nil
 }
 
 // validateV1OffsetDeltas checks that offset deltas fit in uint16 range for V1 layout.
+// Deltas are given in bytes and checked in stored offset units.
 // For V2+, this is a no-op since the encoder auto-upgrades to extended format in Finish().
 func (e *NumericEncoder) validateV1OffsetDeltas(tsDelta, valDelta, tagDelta int) error {
 	if e.layoutVersion >= 2 {
 		return nil
 	}
 
+	tsDelta, valDelta, tagDelta = e.offsetUnits(tsDelta), e.offsetUnits(valDelta), e.offsetUnits(tagDelta)
+
 	if tsDelta > section.NumericMaxOffset ||
 		valDelta > section.NumericMaxOffset ||
 		tagDelta > section.NumericMaxOffset {
@@ -539,50 +955,121 @@ func (e *NumericEncoder) validateV1OffsetDeltas(tsDelta, valDelta, tagDelta int)
 
 // selectIndexFormat determines the index entry format based on layout version and data ranges.
 //
+// Parameters:
+//   - hasTag: Whether the finished blob has a tag payload
+//
 // Returns:
-//   - magic: Magic number for the header (0xEA10 for V1, 0xEA20/0xEA30 for V2)
+//   - magic: Magic number for the header (0xEA10 for V1, 0xEA20/0xEA30/0xEA40 for V2)
 //   - entrySize: Byte size of each index entry (16 or 32)
-func (e *NumericEncoder) selectIndexFormat() (magic uint16, entrySize int) {
-	if e.layoutVersion < 2 {
+func (e *NumericEncoder) selectIndexFormat(hasTag bool) (magic uint16, entrySize int) {
+	return selectNumericIndexFormat(e.layoutVersion, hasTag, e.indexEntries)
+}
+
+// writeIndexEntries writes all index entries to the blob buffer using the appropriate
+// serialization method for the selected format.
+func (e *NumericEncoder) writeIndexEntries(blob []byte, offset int, magic uint16) {
+	writeNumericIndexEntries(blob, offset, magic, e.indexEntries, e.engine)
+}
+
+// selectNumericIndexFormat implements selectIndexFormat for the given layout version
+// and index entries holding offset deltas.
+//
+// V2 prefers compact 16-byte entries. When they overflow, tagless blobs move to the
+// 16-byte tagless entries, whose uint24 offsets reuse the unused tag offset bytes,
+// before falling back to 32-byte extended entries.
+func selectNumericIndexFormat(layoutVersion uint8, hasTag bool, entries []section.NumericIndexEntry) (magic uint16, entrySize int) {
+	if layoutVersion < 2 {
 		return section.MagicNumericV1Opt, section.NumericIndexEntrySize
 	}
 
-	// V2: scan entries to decide compact (16B) vs extended (32B).
-	// Extended is needed when any offset delta exceeds uint16 or any count exceeds uint16.
-	for i := range e.indexEntries {
-		entry := &e.indexEntries[i]
-		if entry.TimestampOffset > section.NumericMaxOffset ||
-			entry.ValueOffset > section.NumericMaxOffset ||
+	// V2: scan entries to decide compact (16B) vs tagless (16B) vs extended (32B).
+	// Compact needs every offset delta and count in uint16; tagless allows uint24
+	// timestamp and value deltas.
+	compact, noTag := true, !hasTag
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Count > math.MaxUint16 ||
 			entry.TagOffset > section.NumericMaxOffset ||
-			entry.Count > math.MaxUint16 {
+			entry.TimestampOffset > section.NumericNoTagMaxOffset ||
+			entry.ValueOffset > section.NumericNoTagMaxOffset {
 			return section.MagicNumericV2ExtOpt, section.NumericExtIndexEntrySize
 		}
+		if entry.TimestampOffset > section.NumericMaxOffset || entry.ValueOffset > section.NumericMaxOffset {
+			compact = false
+		}
 	}
 
-	return section.MagicNumericV2Opt, section.NumericIndexEntrySize
+	switch {
+	case compact:
+		return section.MagicNumericV2Opt, section.NumericIndexEntrySize
+	case noTag:
+		return section.MagicNumericV2NoTagOpt, section.NumericIndexEntrySize
+	default:
+		return section.MagicNumericV2ExtOpt, section.NumericExtIndexEntrySize
+	}
 }
 
-// writeIndexEntries writes all index entries to the blob buffer using the appropriate
-// serialization method for the selected entry size (16B compact or 32B extended).
-func (e *NumericEncoder) writeIndexEntries(blob []byte, offset, entrySize int) {
-	if entrySize == section.NumericExtIndexEntrySize {
-		for i, entry := range e.indexEntries {
-			entry.WriteToSlice32(blob, offset+i*entrySize, e.engine)
+// writeNumericIndexEntries implements writeIndexEntries for the given entries and
+// the magic number returned by selectNumericIndexFormat.
+func writeNumericIndexEntries(blob []byte, offset int, magic uint16, entries []section.NumericIndexEntry, engine endian.EndianEngine) {
+	switch magic {
+	case section.MagicNumericV2ExtOpt:
+		for _, entry := range entries {
+			offset = entry.WriteToSlice32(blob, offset, engine)
 		}
-	} else {
-		for i, entry := range e.indexEntries {
-			entry.WriteToSlice(blob, offset+i*entrySize, e.engine)
+	case section.MagicNumericV2NoTagOpt:
+		for _, entry := range entries {
+			offset = entry.WriteToSliceNoTag(blob, offset, engine)
+		}
+	default:
+		for _, entry := range entries {
+			offset = entry.WriteToSlice(blob, offset, engine)
 		}
 	}
 }
 
-// validateBlobSize ensures the assembled blob fits in uint32 header offsets.
-func validateBlobSize(blobSize int) error {
-	if blobSize > maxBlobBytes {
-		return fmt.Errorf("%w: computed %d bytes", errs.ErrBlobSizeExceedsLimit, blobSize)
+// compressOrRaw compresses payload with codec, falling back to the raw bytes
+// when compression does not make the payload smaller. Tiny payloads often grow
+// under frame overhead, so the fallback keeps them at their encoded size.
+//
+// Returns the bytes to store and whether they are compressed.
+func compressOrRaw(codec compress.Codec, payload []byte) ([]byte, bool, error) {
+	compressed, err := codec.Compress(payload)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return nil
+	if len(compressed) < len(payload) {
+		return compressed, true, nil
+	}
+
+	return payload, false, nil
+}
+
+// needsWideHeader reports whether a blob of blobSize bytes, measured with a v1
+// header, has payload offsets too large for the v1 header.
+func needsWideHeader(blobSize int) bool {
+	return blobSize > maxBlobBytes
+}
+
+// layoutNumericHeader sets the section offsets of h for a blob whose header is
+// followed by prefixSize bytes of metric names and metadata, indexSize bytes of
+// index entries and shared timestamp table, and the three payloads. Blobs too
+// large for uint32 payload offsets switch h to the wide header, which shifts
+// every section by its extra bytes.
+//
+// Returns the blob size.
+func layoutNumericHeader(h *section.NumericHeader, prefixSize, indexSize, tsSize, valSize, tagSize int) int {
+	bodySize := prefixSize + indexSize + tsSize + valSize + tagSize
+	h.Wide = h.Wide || needsWideHeader(section.HeaderSize+bodySize)
+	headerSize := h.Size()
+
+	h.IndexOffset = uint32(headerSize + prefixSize)                        //nolint: gosec
+	h.TimestampPayloadOffset = uint64(headerSize + prefixSize + indexSize) //nolint: gosec
+	h.ValuePayloadOffset = h.TimestampPayloadOffset + uint64(tsSize)       //nolint: gosec
+	h.TagPayloadOffset = h.ValuePayloadOffset + uint64(valSize)            //nolint: gosec
+
+	return headerSize + bodySize
 }
 
 // Finish finalizes the encoding process and returns the complete byte slice representing all encoded metrics.
@@ -617,6 +1104,98 @@ func (e *NumericEncoder) FinishInto(dst []byte) ([]byte, error) {
 	return e.finishAppend(dst)
 }
 
+// FinishTo finalizes the encoding process like Finish, but writes the encoded
+// blob to the start of dst's backing array instead of allocating a fresh slice.
+// It is equivalent to FinishInto(dst[:0]): if cap(dst) is at least the blob
+// size, no allocation occurs, so callers managing their own arenas or pools can
+// size dst with MaxFinishedSize and have the blob written in place.
+//
+// Returns:
+//   - []byte: The complete encoded blob, backed by dst when it fits
+//   - error: ErrMetricNotEnded, ErrNoMetricsAdded, or compression errors
+//     (same conditions as Finish)
+//
+// Example:
+//
+//	buf := arena.Alloc(encoder.MaxFinishedSize())
+//	data, err := encoder.FinishTo(buf)
+func (e *NumericEncoder) FinishTo(dst []byte) ([]byte, error) {
+	data, err := e.finishAppend(dst[:0])
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MaxFinishedSize returns an upper bound of the size in bytes of the blob that
+// Finish would produce from the data added so far.
+//
+// The bound assumes uncompressed payloads, extended index entries, and the
+// worst-case alignment padding and shared timestamp table, so a buffer of this
+// capacity always holds the finished blob. It does not modify the encoder.
+//
+// Returns:
+//   - int: Upper bound of the finished blob size in bytes
+func (e *NumericEncoder) MaxFinishedSize() int {
+	n := len(e.indexEntries)
+	size := section.HeaderSize + n*section.NumericExtIndexEntrySize
+
+	// Metric names, stored raw when compression does not shrink them
+	if e.collisionTracker != nil {
+		size += 2
+		for _, name := range e.collisionTracker.GetMetricNames() {
+			size += 2 + len(name)
+		}
+	}
+
+	// Metadata, plus the records Finish may add
+	metadata := e.finalMetadata()
+	size += metadata.Size() + 7 + 6
+	if len(e.refs) > 0 {
+		size += 6 + 16*len(e.refs)
+	}
+	if e.hasBounds {
+		size += 6 + 16
+	}
+	if len(e.sketchEntries) > 0 {
+		size += 6 + 4 + metricDirEntrySize*len(e.sketchEntries) + len(e.sketchBodies)
+	}
+	if len(e.tagSummaryEntries) > 0 {
+		size += 6 + 4 + metricDirEntrySize*len(e.tagSummaryEntries) + len(e.tagSummaryBodies)
+	}
+	if len(e.sumEntries) > 0 {
+		size += 6 + 4 + metricDirEntrySize*len(e.sumEntries) + len(e.sumBodies)
+	}
+	if len(e.metas) > 0 {
+		size += 6
+		for _, m := range e.metas {
+			size += metricMetaEntrySize(m.meta)
+		}
+	}
+
+	// Shared timestamp groups have at least two members, each adding at most
+	// 4 bytes to the table while the deduplicated payloads only shrink.
+	if e.sharedTimestamps {
+		size += 2 + 4*n
+	}
+
+	// Payloads never exceed their raw size, plus padding of each metric section
+	size += e.tsEncoder.Size() + e.valEncoder.Size() + e.tagEncoder.Size()
+	if e.spill != nil {
+		size += e.spill.sizes[spillTs] + e.spill.sizes[spillVal] + e.spill.sizes[spillTag]
+	}
+	if e.offsetUnit > 1 {
+		size += 3 * n * (e.offsetUnit - 1)
+	}
+
+	if e.header.Wide || needsWideHeader(size) {
+		size += section.WideHeaderSize - section.HeaderSize
+	}
+
+	return size
+}
+
 // appendBlobRegion extends dst by blobSize bytes, reallocating only when the
 // capacity is insufficient. Returns the extended slice and the writable
 // region for the blob.
@@ -641,6 +1220,10 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 	defer e.valEncoder.Finish()
 	defer e.tagEncoder.Finish()
 
+	if e.spill != nil {
+		defer e.spill.close()
+	}
+
 	if e.curMetricID != 0 {
 		return dst, errs.ErrMetricNotEnded
 	}
@@ -678,17 +1261,44 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 	// This enables cache-friendly iteration and binary search lookups in the decoder.
 	// Index entries store delta offsets referencing payload data in insertion order,
 	// so we must also reorder all payload data to match sorted order.
-	rawTsBytes := e.tsEncoder.Bytes()
-	rawValBytes := e.valEncoder.Bytes()
-	var rawTagBytes []byte
-	if finalHeader.Flag.HasTag() {
-		rawTagBytes = e.tagEncoder.Bytes()
+	rawTsBytes, rawValBytes, rawTagBytes, err := e.rawColumns(finalHeader.Flag.HasTag())
+	if err != nil {
+		return dst, err
 	}
 
-	if e.layoutVersion >= 2 && !e.sortedByMetricID {
+	// V1 sorts on request (WithSortedIndex) and advertises it with the sorted index flag.
+	v1Sorted := e.layoutVersion < 2 && e.sortedIndex
+	if v1Sorted {
+		finalHeader.Flag.SetIndexSorted(true)
+	}
+
+	if (e.layoutVersion >= 2 || v1Sorted) && !e.sortedByMetricID {
 		rawTsBytes, rawValBytes, rawTagBytes = e.sortEntriesByMetricID(rawTsBytes, rawValBytes, rawTagBytes)
 	}
 
+	// Store sparse tags as presence bitmaps when the bitmaps are smaller than the
+	// zero-length varints of the empty tags they replace.
+	var tagBitmap bool
+	if finalHeader.Flag.HasTag() {
+		rawTagBytes, tagBitmap = bitmapTagColumn(e.indexEntries, rawTagBytes)
+	}
+
+	if e.smallLayout(finalHeader) {
+		return e.finishSmall(dst, finalHeader, rawTsBytes, rawValBytes, rawTagBytes, tagBitmap)
+	}
+
+	// Pad metric sections to whole words and store offset deltas in words.
+	if e.offsetUnit > 1 {
+		rawTsBytes, rawValBytes, rawTagBytes = e.alignMetricSections(rawTsBytes, rawValBytes, rawTagBytes)
+	}
+
+	// Sorting rewrites V1 deltas, which must still fit compact index entries.
+	if v1Sorted && !e.sortedByMetricID {
+		if err := validateV1Entries(e.indexEntries); err != nil {
+			return dst, err
+		}
+	}
+
 	// Detect shared timestamp groups and build dedup payload if opt-in enabled.
 	var sharedTable section.SharedTimestampTable
 	var sharedTableSize int
@@ -705,26 +1315,34 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 	// non-last position, making its payload size appear as an explicit delta that
 	// could overflow uint16 in compact format. Dedup further rewrites timestamp deltas.
 	// Selecting format here ensures we see the final delta values.
-	magic, entrySize := e.selectIndexFormat()
+	magic, entrySize := e.selectIndexFormat(finalHeader.Flag.HasTag())
 	if e.layoutVersion >= 2 {
 		finalHeader.Flag.Options = (finalHeader.Flag.Options &^ section.MagicNumberMask) | magic
 	}
 
-	// Compress timestamp and value payloads
-	tsPayload, err := e.tsCodec.Compress(rawTsBytes)
+	// Compress timestamp and value payloads. Sections that compression does not
+	// shrink are stored raw and recorded as CompressionNone.
+	tsPayload, tsCompressed, err := compressOrRaw(e.tsCodec, rawTsBytes)
 	if err != nil {
 		return dst, fmt.Errorf("failed to compress timestamp payload: %w", err)
 	}
+	if !tsCompressed {
+		finalHeader.Flag.SetTimestampCompression(format.CompressionNone)
+	}
 
-	valPayload, err := e.valCodec.Compress(rawValBytes)
+	valPayload, valCompressed, err := compressOrRaw(e.valCodec, rawValBytes)
 	if err != nil {
 		return dst, fmt.Errorf("failed to compress value payload: %w", err)
 	}
+	if !valCompressed {
+		finalHeader.Flag.SetValueCompression(format.CompressionNone)
+	}
 
 	// Only compress tag payload if tag support is enabled
 	var tagPayload []byte
+	tagCompressed := true
 	if finalHeader.Flag.HasTag() {
-		tagPayload, err = e.tagCodec.Compress(rawTagBytes)
+		tagPayload, tagCompressed, err = compressOrRaw(e.tagCodec, rawTagBytes)
 		if err != nil {
 			return dst, fmt.Errorf("failed to compress tag payload: %w", err)
 		}
@@ -734,26 +1352,32 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 	// In ID mode, collisionTracker is nil, so we skip this entirely
 	var metricNamesPayload []byte
 	if e.collisionTracker != nil && finalHeader.Flag.HasMetricNames() {
-		metricNamesPayload, err = ienc.EncodeMetricNames(e.collisionTracker.GetMetricNames(), e.engine)
+		var namesCodec compress.Codec
+		if e.compressNames {
+			if namesCodec, err = newCodec(format.CompressionZstd, "metric names", e.deterministic); err != nil {
+				return dst, err
+			}
+		}
+
+		var namesCompressed bool
+		metricNamesPayload, namesCompressed, err = encodeNumericMetricNames(e.collisionTracker.GetMetricNames(), e.engine, namesCodec)
 		if err != nil {
 			return dst, fmt.Errorf("failed to encode metric names: %w", err)
 		}
-		// Update IndexOffset to account for metric names payload (positioned after header)
-		finalHeader.IndexOffset = uint32(section.HeaderSize + len(metricNamesPayload)) //nolint: gosec
+		finalHeader.Flag.SetCompressedMetricNames(namesCompressed)
 	}
 
-	// Calculate exact blob size and validate it fits in uint32 header offsets.
-	// If blobSize <= MaxUint32, all sub-offsets (which are portions of blobSize) also fit in uint32.
-	indexEntriesSize := entrySize * len(e.indexEntries)
-	blobSize := section.HeaderSize + len(metricNamesPayload) + indexEntriesSize + sharedTableSize + len(tsPayload) + len(valPayload) + len(tagPayload)
-	if err := validateBlobSize(blobSize); err != nil {
-		return dst, err
+	// Metadata section (if any) is positioned after the metric names payload
+	metadata := e.blobMetadata(finalHeader.Flag.HasTag(), tagCompressed, tagBitmap)
+	metadataSize := 0
+	if !metadata.IsEmpty() {
+		finalHeader.Flag.SetHasMetadata(true)
+		metadataSize = metadata.Size()
 	}
 
-	// Set header payload offsets — safe because blobSize fits in uint32.
-	finalHeader.TimestampPayloadOffset = finalHeader.IndexOffset + uint32(indexEntriesSize) + uint32(sharedTableSize) //nolint: gosec
-	finalHeader.ValuePayloadOffset = finalHeader.TimestampPayloadOffset + uint32(len(tsPayload))                      //nolint: gosec
-	finalHeader.TagPayloadOffset = finalHeader.ValuePayloadOffset + uint32(len(valPayload))                           //nolint: gosec
+	indexEntriesSize := entrySize * len(e.indexEntries)
+	blobSize := layoutNumericHeader(finalHeader, len(metricNamesPayload)+metadataSize, indexEntriesSize+sharedTableSize,
+		len(tsPayload), len(valPayload), len(tagPayload))
 
 	// Extend dst by exactly blobSize bytes (allocating only when capacity is
 	// insufficient) and assemble the blob in the appended region.
@@ -768,8 +1392,13 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 		offset += copy(blob[offset:], metricNamesPayload)
 	}
 
+	// Write metadata section (if present)
+	if metadataSize > 0 {
+		offset = metadata.WriteToSlice(blob, offset, e.engine)
+	}
+
 	// Write index entries using entry size determined by format selection
-	e.writeIndexEntries(blob, offset, entrySize)
+	e.writeIndexEntries(blob, offset, magic)
 	offset += indexEntriesSize
 
 	// Write shared timestamp table (if V2 with sharing)
@@ -789,6 +1418,53 @@ func (e *NumericEncoder) finishAppend(dst []byte) ([]byte, error) {
 	return full, nil
 }
 
+// blobMetadata returns the metadata records of the finished blob: the configured
+// records plus those describing the tag payload and the per-metric summaries.
+func (e *NumericEncoder) blobMetadata(hasTag, tagCompressed, tagBitmap bool) section.Metadata {
+	metadata := e.finalMetadata()
+	if !tagCompressed {
+		metadata.Set(section.MetadataKeyTagCompression, []byte{byte(format.CompressionNone)})
+	}
+	if tagBitmap {
+		metadata.Set(section.MetadataKeyTagPresenceBitmap, []byte{})
+	}
+	if len(e.refs) > 0 {
+		metadata.Set(section.MetadataKeyMetricReferences, e.encodeMetricReferences())
+	}
+	if len(e.metas) > 0 {
+		metadata.Set(section.MetadataKeyMetricMeta, encodeMetricMeta(e.metas, e.engine))
+	}
+	if e.hasBounds {
+		metadata.Set(section.MetadataKeyTimeBounds, encodeTimeBounds(e.minTs, e.maxTs, e.engine))
+	}
+	if len(e.sketchEntries) > 0 {
+		metadata.Set(section.MetadataKeyMetricSketches, encodeMetricDirectory(e.sketchEntries, e.sketchBodies, e.engine))
+	}
+	if len(e.tagSummaryEntries) > 0 && hasTag {
+		metadata.Set(section.MetadataKeyTagSummaries, encodeMetricDirectory(e.tagSummaryEntries, e.tagSummaryBodies, e.engine))
+	}
+	if len(e.sumEntries) > 0 {
+		metadata.Set(section.MetadataKeyValueSums, encodeMetricDirectory(e.sumEntries, e.sumBodies, e.engine))
+	}
+
+	return metadata
+}
+
+// encodeMetricReferences serializes the recorded metric references sorted by MetricID.
+func (e *NumericEncoder) encodeMetricReferences() []byte {
+	slices.SortFunc(e.refs, func(a, b metricReference) int {
+		return cmp.Compare(a.metricID, b.metricID)
+	})
+
+	b := make([]byte, 16*len(e.refs))
+	for i, ref := range e.refs {
+		e.engine.PutUint64(b[i*16:], ref.metricID)
+		e.engine.PutUint64(b[i*16+8:], ref.refID)
+	}
+
+	return b
+}
+
 // releasePooledSlices returns cached slices to their respective pools.
 func (e *NumericEncoder) releasePooledSlices() {
 	if e.cleanupTS != nil {
@@ -930,6 +1606,113 @@ func (e *NumericEncoder) sortEntriesByMetricID(rawTs, rawVal, rawTag []byte) (so
 	return newTs, newVal, newTag
 }
 
+// alignMetricSections pads every metric section of the payloads with zero bytes to
+// a multiple of the offset unit and converts the index entry deltas to units.
+func (e *NumericEncoder) alignMetricSections(rawTs, rawVal, rawTag []byte) (alignedTs, alignedVal, alignedTag []byte) {
+	alignedTs = alignColumn(e.indexEntries, rawTs, e.offsetUnit, func(entry *section.NumericIndexEntry) *int {
+		return &entry.TimestampOffset
+	})
+	alignedVal = alignColumn(e.indexEntries, rawVal, e.offsetUnit, func(entry *section.NumericIndexEntry) *int {
+		return &entry.ValueOffset
+	})
+	alignedTag = alignColumn(e.indexEntries, rawTag, e.offsetUnit, func(entry *section.NumericIndexEntry) *int {
+		return &entry.TagOffset
+	})
+
+	return alignedTs, alignedVal, alignedTag
+}
+
+// alignColumn pads the metric sections of one payload column to multiples of unit
+// and rewrites the column's byte deltas, selected by field, as unit deltas.
+func alignColumn(entries []section.NumericIndexEntry, payload []byte, unit int, field func(*section.NumericIndexEntry) *int) []byte {
+	if len(payload) == 0 {
+		return payload
+	}
+
+	sizes := columnSectionSizes(entries, len(payload), field)
+	aligned := make([]byte, 0, len(payload)+len(entries)*(unit-1))
+	start := 0
+	for i, size := range sizes {
+		aligned = appendPadding(append(aligned, payload[start:start+size]...), unit)
+		start += size
+
+		if i+1 < len(entries) {
+			*field(&entries[i+1]) = (size + unit - 1) / unit
+		}
+	}
+
+	return aligned
+}
+
+// columnSectionSizes returns the byte sizes of the metric sections of one payload
+// column: entry i+1 stores the size of section i as its delta, selected by field,
+// and the last section takes the rest of the column.
+func columnSectionSizes(entries []section.NumericIndexEntry, payloadLen int, field func(*section.NumericIndexEntry) *int) []int {
+	sizes := make([]int, len(entries))
+	remaining := payloadLen
+	for i := range entries {
+		if i+1 < len(entries) {
+			sizes[i] = *field(&entries[i+1])
+		} else {
+			sizes[i] = remaining
+		}
+		remaining -= sizes[i]
+	}
+
+	return sizes
+}
+
+// bitmapTagColumn converts the tag sections of a tag payload column to the presence
+// bitmap layout and rewrites the tag deltas, if that shrinks the column.
+//
+// Returns the (possibly unchanged) column and whether it was converted.
+func bitmapTagColumn(entries []section.NumericIndexEntry, payload []byte) ([]byte, bool) {
+	if len(payload) == 0 {
+		return payload, false
+	}
+
+	tagOffset := func(entry *section.NumericIndexEntry) *int { return &entry.TagOffset }
+	sizes := columnSectionSizes(entries, len(payload), tagOffset)
+
+	bitmapBytes, emptyTags, start := 0, 0, 0
+	for i, size := range sizes {
+		empty, ok := ienc.CountEmptyTags(payload[start:start+size], entries[i].Count)
+		if !ok {
+			return payload, false
+		}
+		bitmapBytes += ienc.TagBitmapSize(entries[i].Count)
+		emptyTags += empty
+		start += size
+	}
+
+	if bitmapBytes >= emptyTags {
+		return payload, false
+	}
+
+	converted := make([]byte, 0, len(payload)-emptyTags+bitmapBytes)
+	start = 0
+	for i, size := range sizes {
+		sectionStart := len(converted)
+		converted, _ = ienc.AppendBitmapTags(converted, payload[start:start+size], entries[i].Count)
+		start += size
+
+		if i+1 < len(entries) {
+			*tagOffset(&entries[i+1]) = len(converted) - sectionStart
+		}
+	}
+
+	return converted, true
+}
+
+// appendPadding appends zero bytes to buf up to a multiple of unit.
+func appendPadding(buf []byte, unit int) []byte {
+	if rem := len(buf) % unit; rem != 0 {
+		buf = append(buf, make([]byte, unit-rem)...)
+	}
+
+	return buf
+}
+
 // detectSharedTimestamps scans all metrics' encoded timestamp byte ranges and groups
 // metrics with identical timestamp sequences. Returns nil if no sharing is detected.
 //
@@ -1112,12 +1895,45 @@ func (e *NumericEncoder) buildDedupTsPayload(allTsBytes []byte, groups []tsGroup
 //   - tag: Optional tag string (ignored if tag support is not enabled).
 //
 // Returns:
-//   - error: ErrTooManyDataPoints if adding would exceed claimed data point count.
+//   - error: ErrTooManyDataPoints if adding would exceed claimed data point count,
+//     ErrNaNValue if value is NaN and the NaN policy is NaNError, or
+//     ErrTimestampOutOfWindow if timestamp is outside the window set by
+//     WithTimeWindow and its policy is TimeWindowReject.
 func (e *NumericEncoder) AddDataPoint(timestamp int64, value float64, tag string) error {
 	if e.curPoints >= e.claimed {
 		return errs.ErrTooManyDataPoints
 	}
 
+	if e.nanPolicy != NaNKeep && math.IsNaN(value) {
+		if e.nanPolicy == NaNError {
+			return fmt.Errorf("%w: data point %d of metric ID 0x%016x", errs.ErrNaNValue, e.curPoints, e.curMetricID)
+		}
+		e.claimed-- // NaNDrop
+
+		return nil
+	}
+
+	if e.window && e.outOfWindow(timestamp) {
+		switch e.windowPolicy {
+		case TimeWindowReject:
+			return e.windowError(e.curPoints, timestamp)
+		case TimeWindowDrop:
+			e.claimed--
+
+			return nil
+		case TimeWindowClamp:
+			timestamp = e.clampToWindow(timestamp)
+		}
+	}
+
+	if e.quantizing() || e.retained != nil || e.curSketch != nil || e.valueSums {
+		value = e.prepareValue(value, e.curPoints)
+	}
+
+	if e.timeBounds {
+		e.trackTimeBounds(timestamp, timestamp)
+	}
+
 	e.tsEncoder.Write(timestamp)
 	e.valEncoder.Write(value)
 	// Only encode tags if tag support is enabled
@@ -1126,6 +1942,9 @@ func (e *NumericEncoder) AddDataPoint(timestamp int64, value float64, tag string
 		// Track if any non-empty tag is written (branchless check for hot path)
 		if tag != "" {
 			e.hasNonEmptyTags = true
+			if e.curTagCounts != nil {
+				e.curTagCounts[tag]++
+			}
 		}
 	}
 
@@ -1151,7 +1970,10 @@ func (e *NumericEncoder) AddDataPoint(timestamp int64, value float64, tag string
 //
 // Returns:
 //   - error: Length mismatch error if timestamps/values/tags lengths don't match,
-//     or ErrTooManyDataPoints if adding would exceed the claimed data point count.
+//     ErrTooManyDataPoints if adding would exceed the claimed data point count,
+//     ErrNaNValue if a value is NaN and the NaN policy is NaNError, or
+//     ErrTimestampOutOfWindow if a timestamp is outside the window set by
+//     WithTimeWindow and its policy is TimeWindowReject.
 func (e *NumericEncoder) AddDataPoints(timestamps []int64, values []float64, tags []string) error {
 	tsLen := len(timestamps)
 	valLen := len(values)
@@ -1171,6 +1993,43 @@ func (e *NumericEncoder) AddDataPoints(timestamps []int64, values []float64, tag
 		return errs.ErrTooManyDataPoints
 	}
 
+	// Dropped data points release their claim only once the whole batch is
+	// accepted, so a rejected call leaves the encoder unchanged.
+	if e.nanPolicy != NaNKeep || e.window {
+		input := values
+		var dropped int
+		var err error
+		if e.nanPolicy != NaNKeep {
+			timestamps, values, tags, dropped, err = e.applyNaNPolicy(timestamps, values, tags)
+			if err != nil {
+				return err
+			}
+		}
+
+		if e.window && len(timestamps) > 0 {
+			var windowDropped int
+			timestamps, values, tags, windowDropped, err = e.applyTimeWindow(timestamps, values, tags, input)
+			if err != nil {
+				return err
+			}
+			dropped += windowDropped
+		}
+
+		e.claimed -= dropped
+		tsLen, tagLen = len(timestamps), len(tags)
+		if tsLen == 0 {
+			return nil
+		}
+	}
+
+	if e.quantizing() || e.retained != nil || e.curSketch != nil || e.valueSums {
+		values = e.prepareValues(values)
+	}
+
+	if e.timeBounds {
+		e.trackTimeBounds(slices.Min(timestamps), slices.Max(timestamps))
+	}
+
 	e.tsEncoder.WriteSlice(timestamps)
 	e.valEncoder.WriteSlice(values)
 
@@ -1185,6 +2044,13 @@ func (e *NumericEncoder) AddDataPoints(timestamps []int64, values []float64, tag
 					break // Early exit once we find one non-empty tag
 				}
 			}
+			if e.curTagCounts != nil {
+				for _, tag := range tags {
+					if tag != "" {
+						e.curTagCounts[tag]++
+					}
+				}
+			}
 		} else {
 			// If no tags provided, write empty strings for each data point
 			emptyTags := make([]string, tsLen)
@@ -1202,6 +2068,60 @@ func (e *NumericEncoder) AddDataPoints(timestamps []int64, values []float64, tag
 	return nil
 }
 
+// trackTimeBounds widens the time bounds of the current metric to [lo, hi]. It
+// must be called before curPoints is advanced for the data points.
+func (e *NumericEncoder) trackTimeBounds(lo, hi int64) {
+	if e.curPoints == 0 {
+		e.curMinTs, e.curMaxTs = lo, hi
+
+		return
+	}
+
+	e.curMinTs, e.curMaxTs = min(e.curMinTs, lo), max(e.curMaxTs, hi)
+}
+
+// prepareValue applies quantization and reference deltas to the value of the
+// data point at position idx of the current metric, retaining the logical value
+// for metrics that may later be referenced and adding it to the metric's sketch
+// and sum.
+func (e *NumericEncoder) prepareValue(value float64, idx int) float64 {
+	if e.quantizing() {
+		value = e.quantize(value)
+	}
+
+	if e.retained != nil {
+		e.curValues = append(e.curValues, value)
+	}
+
+	if e.curSketch != nil {
+		e.curSketch.Add(value)
+	}
+
+	if e.valueSums {
+		e.curSum.Add(value)
+	}
+
+	if e.curRef != nil {
+		value = math.Float64frombits(math.Float64bits(value) - math.Float64bits(e.curRef[idx]))
+	}
+
+	return value
+}
+
+// prepareValues returns the prepared copy of values in the encoder's scratch buffer.
+func (e *NumericEncoder) prepareValues(values []float64) []float64 {
+	if cap(e.valBuf) < len(values) {
+		e.valBuf = make([]float64, len(values))
+	}
+
+	buf := e.valBuf[:len(values)]
+	for i, v := range values {
+		buf[i] = e.prepareValue(v, e.curPoints+i)
+	}
+
+	return buf
+}
+
 // getTimestamps returns a slice for timestamps with at least the requested capacity.
 // It lazily allocates from pool on first call, then reuses and grows the same slice
 // across multiple AddFromRows calls within the same encoder lifecycle.
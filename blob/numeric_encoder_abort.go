@@ -0,0 +1,104 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/sketch"
+)
+
+// AbortMetric discards the current metric: its partially written timestamps,
+// values and tags, its claimed data point count and its identifier. The encoder
+// returns to the state it had before the metric was started, so ingestion can
+// recover from a mid-metric upstream error and continue with the next metric,
+// or start the same metric again.
+//
+// Completed metrics are unaffected. Discarding written data points rebuilds the
+// column encoders, keeping the bytes of completed metrics aside until Finish.
+//
+// Returns:
+//   - error: ErrNoMetricStarted if no metric is started, or
+//     ErrUnsupportedBlobFeature if data points were written with an extension
+//     value encoder (see WithValueEncoder), whose bytes cannot be truncated;
+//     the metric stays started in that case
+//
+// Example:
+//
+//	encoder.StartMetricName(name, len(batch))
+//	for _, sample := range batch {
+//	    if err := encoder.AddDataPoint(sample.Ts, sample.Value, ""); err != nil {
+//	        encoder.AbortMetric() // skip this metric, keep the blob
+//	        break
+//	    }
+//	}
+func (e *NumericEncoder) AbortMetric() error {
+	if e.curMetricID == 0 {
+		return errs.ErrNoMetricStarted
+	}
+
+	if e.curPoints > 0 {
+		if e.extEncoder != nil {
+			return fmt.Errorf("%w: cannot abort a metric written with an extension value encoder", errs.ErrUnsupportedBlobFeature)
+		}
+
+		if err := e.truncateColumns(); err != nil {
+			return err
+		}
+	}
+
+	e.untrackCurrentMetric()
+
+	e.curMetricID = 0
+	e.claimed = 0
+	e.hinted = false
+	e.curPoints = 0
+	e.hasValidTs = false
+	e.hasNonEmptyTags = e.tagsAtStart
+
+	e.curValues = nil
+	e.curRef = nil
+	e.curRefID = 0
+	e.curMeta = MetricMeta{}
+	if e.curSketch != nil {
+		e.curSketch.Reset()
+	}
+	clear(e.curTagCounts)
+	e.curSum = sketch.KahanSum{}
+
+	return nil
+}
+
+// truncateColumns drops the bytes written for the current metric from the
+// column encoders.
+//
+// Column encoders cannot truncate their output, so the bytes of completed
+// metrics move to the spill (kept in memory unless WithMaxEncoderMemory is set)
+// and the encoders are replaced with empty ones.
+func (e *NumericEncoder) truncateColumns() error {
+	if e.spill == nil {
+		e.spill = &columnSpill{dir: e.spillDir, inMemory: e.maxMemory <= 0}
+	}
+
+	tsBytes, valBytes, tagBytes := e.tsEncoder.Bytes(), e.valEncoder.Bytes(), e.tagEncoder.Bytes()
+
+	return e.moveColumnsToSpill(tsBytes[:e.ts.offset], valBytes[:e.val.offset], tagBytes[:e.tag.offset])
+}
+
+// untrackCurrentMetric releases the identifier of the current metric so it can
+// be started again. If no metric is completed, the identifier mode is reset too.
+func (e *NumericEncoder) untrackCurrentMetric() {
+	if len(e.indexEntries) == 0 {
+		e.identifierMode = modeUndefined
+		e.collisionTracker = nil
+		e.usedIDs = nil
+		e.hasCollision = false
+
+		return
+	}
+
+	if e.collisionTracker != nil {
+		e.collisionTracker.Remove(e.collisionTracker.Count() - 1)
+		e.hasCollision = e.collisionTracker.HasCollision()
+	}
+	delete(e.usedIDs, e.curMetricID)
+}
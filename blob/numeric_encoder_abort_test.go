@@ -0,0 +1,100 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// addAbortTestMetric adds n data points of the named metric to encoder.
+func addAbortTestMetric(t *testing.T, encoder *NumericEncoder, name string, n int, ended bool) {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0)
+	require.NoError(t, encoder.StartMetricName(name, n))
+	for i := range n {
+		ts := start.Add(time.Duration(i) * time.Second).UnixMicro()
+		require.NoError(t, encoder.AddDataPoint(ts, float64(i)*1.25+float64(len(name)), "tag-"+name))
+	}
+	if ended {
+		require.NoError(t, encoder.EndMetric())
+	}
+}
+
+func TestNumericEncoder_AbortMetric(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	configs := map[string][]NumericEncoderOption{
+		"raw":         {WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)},
+		"delta":       {WithTimestampEncoding(format.TypeDelta), WithValueEncoding(format.TypeGorilla)},
+		"deltapacked": {WithTimestampEncoding(format.TypeDeltaPacked), WithValueEncoding(format.TypeChimp)},
+		"alp":         {WithValueEncoding(format.TypeALP)},
+		"tags":        {WithTagsEnabled(true), WithValueSums(true), WithSketches(true), WithTimeBounds(true)},
+		"layout v2":   {WithBlobLayoutV2(), WithTagsEnabled(true), WithTagSummaries(true)},
+		"spill":       {WithMaxEncoderMemory(1, t.TempDir())},
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			want, err := NewNumericEncoder(start, opts...)
+			require.NoError(t, err)
+			addAbortTestMetric(t, want, "cpu", 10, true)
+			addAbortTestMetric(t, want, "mem", 7, true)
+			wantData, err := want.Finish()
+			require.NoError(t, err)
+
+			got, err := NewNumericEncoder(start, opts...)
+			require.NoError(t, err)
+			addAbortTestMetric(t, got, "cpu", 10, true)
+			addAbortTestMetric(t, got, "bogus", 5, false)
+			require.NoError(t, got.AbortMetric())
+			addAbortTestMetric(t, got, "mem", 7, true)
+			gotData, err := got.Finish()
+			require.NoError(t, err)
+
+			require.Equal(t, wantData, gotData)
+		})
+	}
+}
+
+func TestNumericEncoder_AbortMetric_Restart(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, encoder.AbortMetric(), errs.ErrNoMetricStarted)
+
+	// Aborting the first metric also releases the identifier mode.
+	require.NoError(t, encoder.StartMetricName("cpu", 3))
+	require.NoError(t, encoder.AbortMetric())
+	require.NoError(t, encoder.StartMetricID(1, 2))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 1, ""))
+	require.NoError(t, encoder.AbortMetric())
+
+	// The same metric can be started again after an abort.
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 1, ""))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.StartMetricID(2, 2))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 2, ""))
+	require.NoError(t, encoder.AbortMetric())
+	require.NoError(t, encoder.StartMetricID(2, 1))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 3, ""))
+	require.NoError(t, encoder.EndMetric())
+	require.ErrorIs(t, encoder.StartMetricID(1, 1), errs.ErrHashCollision)
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	require.Equal(t, 2, blob.MetricCount())
+	v, ok := blob.ValueAt(2, 0)
+	require.True(t, ok)
+	require.Equal(t, 3.0, v)
+}
@@ -2,11 +2,17 @@ package blob
 
 import (
 	"fmt"
+	"math"
+	"os"
+	"slices"
 	"time"
 
 	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/encoding"
 	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
 	"github.com/arloliu/mebo/internal/options"
 	"github.com/arloliu/mebo/section"
 )
@@ -14,6 +20,14 @@ import (
 // MaxMetricCount is the maximum number of metrics allowed in a single numeric blob.
 const MaxMetricCount = 65536
 
+// MaxGorillaWindowWaste is the largest value accepted by WithGorillaWindowReuse.
+// A Gorilla XOR window never exceeds 64 bits, so 64 means unrestricted reuse.
+const MaxGorillaWindowWaste = 64
+
+// MaxValuePrecision is the maximum number of decimal digits accepted by WithValuePrecision.
+// Float64 carries at most ~15-17 significant decimal digits, so finer precision is meaningless.
+const MaxValuePrecision = 15
+
 // Index entry capacity growth strategy constants for performance optimization.
 const (
 	// initialIndexCapacity is the initial capacity for index entries slice.
@@ -25,6 +39,18 @@ const (
 	indexGrowthThreshold = 256
 )
 
+// explicitFlags records the header flags set by an option, so that
+// WithEdgeProfile only replaces the defaults, whatever the option order.
+type explicitFlags uint8
+
+const (
+	explicitTimestampEncoding explicitFlags = 1 << iota
+	explicitValueEncoding
+	explicitTimestampCompression
+	explicitValueCompression
+	explicitTags
+)
+
 // NumericEncoderConfig handles common numeric encoder configuration and state management.
 //
 // This struct follows the composition over inheritance principle, allowing
@@ -37,10 +63,41 @@ type NumericEncoderConfig struct {
 	valCodec         compress.Codec
 	tagCodec         compress.Codec
 	engine           endian.EndianEngine
-	layoutVersion    uint8  // 0=default(v1), 2=v2
-	sharedTimestamps bool   // opt-in for shared timestamp detection (implies v2)
-	sortedByMetricID bool   // tracks whether metrics were inserted in ascending MetricID order
-	lastMetricID     uint64 // last MetricID added (for sorted tracking)
+	layoutVersion    uint8            // 0=default(v1), 2=v2
+	sharedTimestamps bool             // opt-in for shared timestamp detection (implies v2)
+	sortedByMetricID bool             // tracks whether metrics were inserted in ascending MetricID order
+	lastMetricID     uint64           // last MetricID added (for sorted tracking)
+	metadata         section.Metadata // optional metadata section records (empty = section omitted)
+	quantScale       float64          // 10^decimals set by WithValuePrecision, 0 if disabled
+	quantStep        float64          // step set by WithQuantization, 0 if disabled
+	quantDecimals    int              // decimals set by WithValuePrecision
+	metricRefs       bool             // opt-in for delta-against-reference metric encoding
+	gorillaTuning    ienc.GorillaTuning
+	tsUnit           format.TimeUnit                   // unit of encoded timestamps, recorded in metadata unless microseconds
+	tagCompression   format.CompressionType            // tag payload compression, recorded in metadata unless Zstd
+	offsetUnit       int                               // index offset delta unit in bytes, recorded in metadata when > 1
+	valueAlignment   int                               // value section alignment set by WithValueAlignment, 0 if unset
+	maxMemory        int                               // in-memory column bytes that trigger a spill, 0 if disabled
+	spillDir         string                            // directory for spill files, "" for the OS temp directory
+	deterministic    bool                              // pin compressors to fixed configurations for reproducible output
+	sortedIndex      bool                              // sort V1 index entries by MetricID and flag the header
+	smallHeader      bool                              // write single-metric blobs with the 8-byte small header
+	edgeMetrics      int                               // metric capacity set by WithEdgeProfile, 0 if disabled
+	edgePoints       int                               // data point capacity set by WithEdgeProfile, 0 if disabled
+	explicit         explicitFlags                     // header flags set by an option rather than defaulted
+	compressNames    bool                              // Zstd compress the metric names payload when it shrinks
+	extEncoder       encoding.ColumnarEncoder[float64] // extension value encoder set by WithValueEncoder, nil if unused
+	extID            uint16                            // registered ID of extEncoder, recorded in metadata
+	validation       ValidationPolicy                  // rules applied by AddDataPointsValidated
+	nanPolicy        NaNPolicy                         // handling of NaN values, NaNKeep by default
+	timeBounds       bool                              // record the earliest and latest timestamps in metadata
+	sketches         bool                              // record a t-digest of each metric's values in metadata
+	tagSummaries     bool                              // record the tag cardinality of each metric in metadata
+	valueSums        bool                              // record the compensated sum and count of each metric's values in metadata
+	window           bool                              // enforce [windowStart, windowEnd) set by WithTimeWindow
+	windowStart      time.Time                         // inclusive start of the time window
+	windowEnd        time.Time                         // exclusive end of the time window
+	windowPolicy     TimeWindowPolicy                  // handling of data points outside the time window
 }
 
 // NewNumericEncoderConfig creates a new NumericEncoderConfig with the given start time.
@@ -62,6 +119,8 @@ func NewNumericEncoderConfig(startTime time.Time) *NumericEncoderConfig {
 		indexEntries:     make([]section.NumericIndexEntry, 0, initialIndexCapacity),
 		engine:           header.Flag.GetEndianEngine(),
 		sortedByMetricID: true, // optimistic: assume ascending insertion order
+		gorillaTuning:    ienc.DefaultGorillaTuning(),
+		tagCompression:   format.CompressionZstd,
 	}
 
 	return config
@@ -74,6 +133,8 @@ func (c *NumericEncoderConfig) setTimestampEncoding(enc format.EncodingType) err
 	switch enc {
 	case format.TypeRaw, format.TypeDelta, format.TypeDeltaPacked:
 		c.header.Flag.SetTimestampEncoding(enc)
+		c.explicit |= explicitTimestampEncoding
+
 		return nil
 	case format.TypeGorilla, format.TypeChimp, format.TypeALP:
 		return fmt.Errorf("%v encoding is not supported for timestamps", enc)
@@ -87,6 +148,9 @@ func (c *NumericEncoderConfig) setValueEncoding(enc format.EncodingType) error {
 	switch enc { //nolint: exhaustive
 	case format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP:
 		c.header.Flag.SetValueEncoding(enc)
+		c.extEncoder = nil
+		c.explicit |= explicitValueEncoding
+
 		return nil
 	default:
 		return fmt.Errorf("invalid value encoding: %v", enc)
@@ -98,6 +162,8 @@ func (c *NumericEncoderConfig) setTimestampCompression(comp format.CompressionTy
 	switch comp {
 	case format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4:
 		c.header.Flag.SetTimestampCompression(comp)
+		c.explicit |= explicitTimestampCompression
+
 		return nil
 	default:
 		return fmt.Errorf("invalid timestamp compression: %v", comp)
@@ -109,12 +175,25 @@ func (c *NumericEncoderConfig) setValueCompression(comp format.CompressionType)
 	switch comp {
 	case format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4:
 		c.header.Flag.SetValueCompression(comp)
+		c.explicit |= explicitValueCompression
+
 		return nil
 	default:
 		return fmt.Errorf("invalid value compression: %v", comp)
 	}
 }
 
+// setTagCompression sets the tag payload compression type.
+func (c *NumericEncoderConfig) setTagCompression(comp format.CompressionType) error {
+	switch comp {
+	case format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4:
+		c.tagCompression = comp
+		return nil
+	default:
+		return fmt.Errorf("invalid tag compression: %v", comp)
+	}
+}
+
 // setEndianess sets the endianness option.
 func (c *NumericEncoderConfig) setEndianess(endiness endianness) {
 	if endiness == bigEndianOpt {
@@ -135,6 +214,160 @@ func (c *NumericEncoderConfig) setTagsEnabled(enabled bool) {
 	} else {
 		c.header.Flag.WithoutTag()
 	}
+	c.explicit |= explicitTags
+}
+
+// setValuePrecision enables rounding values to the given number of decimal digits.
+func (c *NumericEncoderConfig) setValuePrecision(decimals int) error {
+	if decimals < 0 || decimals > MaxValuePrecision {
+		return fmt.Errorf("invalid value precision: %d, must be between 0 and %d", decimals, MaxValuePrecision)
+	}
+
+	c.quantScale = math.Pow10(decimals)
+	c.quantStep = 0
+	c.quantDecimals = decimals
+
+	return nil
+}
+
+// setGorillaWindowReuse limits the bits a reused Gorilla window may waste.
+func (c *NumericEncoderConfig) setGorillaWindowReuse(maxWastedBits int) error {
+	if maxWastedBits < 0 || maxWastedBits > MaxGorillaWindowWaste {
+		return fmt.Errorf("invalid gorilla window waste: %d, must be between 0 and %d", maxWastedBits, MaxGorillaWindowWaste)
+	}
+
+	c.gorillaTuning.MaxWindowWaste = maxWastedBits
+
+	return nil
+}
+
+// setGorillaRebaseline sets how often the Gorilla encoder opens a fresh window.
+func (c *NumericEncoderConfig) setGorillaRebaseline(every int) error {
+	if every < 0 {
+		return fmt.Errorf("invalid gorilla rebaseline interval: %d, must be non-negative", every)
+	}
+
+	c.gorillaTuning.RebaseInterval = every
+
+	return nil
+}
+
+// setValidationPolicy sets the rules applied by AddDataPointsValidated.
+func (c *NumericEncoderConfig) setValidationPolicy(policy ValidationPolicy) error {
+	if policy.Window < 0 {
+		return fmt.Errorf("invalid validation window: %s, must be non-negative", policy.Window)
+	}
+
+	c.validation = policy
+
+	return nil
+}
+
+// setNaNPolicy sets how NaN values are handled.
+func (c *NumericEncoderConfig) setNaNPolicy(policy NaNPolicy) error {
+	if !policy.IsValid() {
+		return fmt.Errorf("invalid NaN policy: %d", policy)
+	}
+
+	c.nanPolicy = policy
+
+	return nil
+}
+
+// setTimeWindow sets the window of accepted timestamps and how points outside it are handled.
+func (c *NumericEncoderConfig) setTimeWindow(start, end time.Time, policy TimeWindowPolicy) error {
+	if !end.After(start) {
+		return fmt.Errorf("%w: time window %s to %s", errs.ErrInvalidTimeRange, start, end)
+	}
+	if !policy.IsValid() {
+		return fmt.Errorf("invalid time window policy: %d", policy)
+	}
+
+	c.window = true
+	c.windowStart, c.windowEnd = start, end
+	c.windowPolicy = policy
+
+	return nil
+}
+
+// setTimestampUnit sets the unit of encoded timestamps.
+func (c *NumericEncoderConfig) setTimestampUnit(unit format.TimeUnit) error {
+	if !unit.IsValid() {
+		return fmt.Errorf("invalid timestamp unit: %v", unit)
+	}
+
+	c.tsUnit = unit
+
+	return nil
+}
+
+// setQuantization enables snapping values to the nearest multiple of step.
+func (c *NumericEncoderConfig) setQuantization(step float64) error {
+	if !(step > 0) || math.IsInf(step, 0) {
+		return fmt.Errorf("invalid quantization step: %v, must be a positive finite number", step)
+	}
+
+	c.quantStep = step
+	c.quantScale = 0
+
+	return nil
+}
+
+// quantizing returns whether values are rounded before encoding.
+func (c *NumericEncoderConfig) quantizing() bool {
+	return c.quantScale != 0 || c.quantStep != 0
+}
+
+// finalMetadata returns the metadata records to write into the blob, including
+// the quantization parameters encoded with the configured byte order.
+func (c *NumericEncoderConfig) finalMetadata() section.Metadata {
+	md := section.Metadata{Records: slices.Clone(c.metadata.Records)}
+
+	switch {
+	case c.quantScale != 0:
+		md.Set(section.MetadataKeyValuePrecision, []byte{byte(c.quantDecimals)}) //nolint: gosec
+	case c.quantStep != 0:
+		b := make([]byte, 8)
+		c.engine.PutUint64(b, math.Float64bits(c.quantStep))
+		md.Set(section.MetadataKeyQuantizationStep, b)
+	}
+
+	if c.tsUnit != format.TimeUnitMicrosecond {
+		md.Set(section.MetadataKeyTimestampUnit, []byte{byte(c.tsUnit)})
+	}
+
+	if c.header.Flag.HasTag() && c.tagCompression != format.CompressionZstd {
+		md.Set(section.MetadataKeyTagCompression, []byte{byte(c.tagCompression)})
+	}
+
+	if c.offsetUnit > 1 {
+		md.Set(section.MetadataKeyOffsetUnit, []byte{byte(c.offsetUnit)}) //nolint: gosec
+	}
+
+	if c.extEncoder != nil {
+		b := make([]byte, 2)
+		c.engine.PutUint16(b, c.extID)
+		md.Set(section.MetadataKeyValueExtension, b)
+	}
+
+	return md
+}
+
+// quantize rounds v according to the configured precision or quantization step.
+// NaN, ±Inf, and values whose scaled form overflows are returned unchanged.
+func (c *NumericEncoderConfig) quantize(v float64) float64 {
+	var q float64
+	if c.quantScale != 0 {
+		q = math.Round(v*c.quantScale) / c.quantScale
+	} else {
+		q = math.Round(v/c.quantStep) * c.quantStep
+	}
+
+	if math.IsNaN(q) || math.IsInf(q, 0) {
+		return v
+	}
+
+	return q
 }
 
 // Common helper methods that can be used by concrete encoders
@@ -200,18 +433,17 @@ func (c *NumericEncoderConfig) addEntryIndex(entry section.NumericIndexEntry) {
 // setCodecs sets the compression codecs.
 func (c *NumericEncoderConfig) setCodecs(header section.NumericHeader) error {
 	// Create compressors based on header settings
-	tsCodec, err := compress.CreateCodec(header.Flag.TimestampCompression(), "timestamps")
+	tsCodec, err := newCodec(header.Flag.TimestampCompression(), "timestamps", c.deterministic)
 	if err != nil {
 		return err
 	}
 
-	valCodec, err := compress.CreateCodec(header.Flag.ValueCompression(), "values")
+	valCodec, err := newCodec(header.Flag.ValueCompression(), "values", c.deterministic)
 	if err != nil {
 		return err
 	}
 
-	// tag payload is calways compressed with zstd
-	tagCodec, err := compress.CreateCodec(format.CompressionZstd, "tags")
+	tagCodec, err := newCodec(c.tagCompression, "tags", c.deterministic)
 	if err != nil {
 		return err
 	}
@@ -223,6 +455,17 @@ func (c *NumericEncoderConfig) setCodecs(header section.NumericHeader) error {
 	return nil
 }
 
+// newCodec creates the compression codec for a payload section. Deterministic
+// output pins Zstd to its fixed encoder configuration; the other codecs are
+// deterministic already.
+func newCodec(comp format.CompressionType, target string, deterministic bool) (compress.Codec, error) {
+	if deterministic && comp == format.CompressionZstd {
+		return compress.NewDeterministicZstdCompressor(), nil
+	}
+
+	return compress.CreateCodec(comp, target)
+}
+
 // endianness represents the byte order configuration option.
 type endianness uint8
 
@@ -301,6 +544,52 @@ func WithValueEncoding(enc format.EncodingType) NumericEncoderOption {
 	})
 }
 
+// WithValueEncoder sets a custom extension encoder for metric values.
+//
+// The id must be registered with format.RegisterEncoding, whose decoder factory
+// is used to read the value payload back: the blob header records
+// format.TypeExtension and the metadata section records id, so the blob
+// round-trips through the standard decoders of any process that registered the
+// same ID. It overrides WithValueEncoding, and vice versa; the last one wins.
+//
+// The encoder must be a fresh instance owned by the NumericEncoder. It follows
+// the encoding.ColumnarEncoder contract: Reset is called after every metric,
+// and the bytes of each metric are the growth of Bytes() since the previous
+// metric ended. Extension encoders cannot be combined with
+// WithMaxEncoderMemory.
+//
+// Parameters:
+//   - id: Registered ID of the extension encoding
+//   - encoder: Encoder instance that produces the value payload
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the value encoder, or an error
+//     if encoder is nil or id is not registered.
+//
+// Example:
+//
+//	encoder, err := blob.NewNumericEncoder(startTime,
+//	    blob.WithValueEncoder(0x100, myencoder.NewMyCustomEncoder()),
+//	)
+func WithValueEncoder(id uint16, encoder encoding.ColumnarEncoder[float64]) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		if encoder == nil {
+			return fmt.Errorf("%w: nil extension value encoder", errs.ErrUnsupportedEncoding)
+		}
+
+		if _, ok := format.LookupEncoding(id); !ok {
+			return fmt.Errorf("%w: extension encoding ID %d is not registered", errs.ErrUnsupportedEncoding, id)
+		}
+
+		c.header.Flag.SetValueEncoding(format.TypeExtension)
+		c.extEncoder = encoder
+		c.extID = id
+		c.explicit |= explicitValueEncoding
+
+		return nil
+	})
+}
+
 // WithTimestampCompression sets the timestamp compression type for the encoder.
 //
 // Valid compression types:
@@ -345,6 +634,36 @@ func WithValueCompression(comp format.CompressionType) NumericEncoderOption {
 	})
 }
 
+// WithTagCompression sets the compression type of the tag payload, independently
+// of the timestamp and value compression.
+//
+// Valid compression types:
+//   - format.CompressionNone: No compression; fastest tag access after decode.
+//   - format.CompressionZstd: Zstandard compression; best ratio, higher CPU cost.
+//   - format.CompressionS2: S2 compression; good ratio with lower CPU cost than Zstd.
+//   - format.CompressionLZ4: LZ4 compression; fastest decompression, moderate ratio.
+//
+// The default is format.CompressionZstd. Latency-sensitive readers can choose
+// LZ4 or None to cut blob open time for tag-heavy blobs. The option has no effect
+// unless tags are enabled (see WithTagsEnabled).
+//
+// A non-default tag compression is recorded in the blob metadata section, as
+// every bit of the numeric header flags is already assigned. Text blobs record
+// their tag compression in the header flags, see WithTextTagCompression.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Parameters:
+//   - comp: The compression type to apply to the encoded tag stream.
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the tag compression, or an error if the compression type is unsupported.
+func WithTagCompression(comp format.CompressionType) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setTagCompression(comp)
+	})
+}
+
 // WithTagsEnabled enables or disables per-point tag storage.
 //
 // When enabled, each data point may carry an associated text tag of up to
@@ -395,3 +714,682 @@ func WithSharedTimestamps() NumericEncoderOption {
 		cfg.layoutVersion = 2
 	})
 }
+
+// WithWordAlignedOffsets stores index entry offset deltas in units of 8-byte words
+// instead of bytes.
+//
+// The encoder pads every metric's timestamp, value and tag sections with zero bytes
+// to a multiple of 8, so the uint16 offset deltas of compact index entries address
+// up to 512 KiB per metric instead of 64 KiB (and the wider index formats scale the
+// same way). This keeps large metrics in 16-byte index entries, at the cost of up to
+// 7 padding bytes per metric section before compression.
+//
+// The option cannot be combined with WithSharedTimestamps, because shared timestamp
+// tables address timestamp sections in bytes.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables word-aligned offsets.
+//
+// Example:
+//
+//	// 60,000 raw points (480 KB) per metric still fit V1 compact index entries
+//	encoder, _ := blob.NewNumericEncoder(startTime,
+//	    blob.WithTimestampEncoding(format.TypeRaw),
+//	    blob.WithValueEncoding(format.TypeRaw),
+//	    blob.WithWordAlignedOffsets(),
+//	)
+func WithWordAlignedOffsets() NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.offsetUnit = max(c.offsetUnit, section.NumericOffsetWordSize)
+	})
+}
+
+// WithValueAlignment pads every metric's raw value section to a multiple of n
+// bytes, so each section starts at an n-byte boundary of the value payload and
+// vectorized readers can run aligned loads directly over it (see
+// NumericBlob.RawValueBytes).
+//
+// The alignment is recorded in the metadata section as the offset unit of the
+// index entries, like WithWordAlignedOffsets, and decoders skip the padding
+// transparently. The timestamp and tag sections are padded the same way. Note that
+// sections are aligned relative to the start of the value payload; readers needing
+// aligned addresses must place the payload on an n-byte boundary in memory.
+//
+// The option requires raw value encoding (format.TypeRaw) and cannot be combined
+// with WithSharedTimestamps. It supersedes WithWordAlignedOffsets.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Parameters:
+//   - n: Alignment in bytes, 32 (AVX2) or 64 (AVX-512, cache line)
+//
+// Returns:
+//   - NumericEncoderOption: An option that aligns value sections, or an error
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime,
+//	    blob.WithValueEncoding(format.TypeRaw),
+//	    blob.WithValueCompression(format.CompressionNone),
+//	    blob.WithValueAlignment(64),
+//	)
+func WithValueAlignment(n int) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		if n != 32 && n != 64 {
+			return fmt.Errorf("invalid value alignment: %d, must be 32 or 64", n)
+		}
+		c.valueAlignment = n
+		c.offsetUnit = max(c.offsetUnit, n)
+
+		return nil
+	})
+}
+
+// WithMaxEncoderMemory caps the encoded column bytes the encoder holds in memory,
+// spilling completed metrics to temporary files once the cap is exceeded.
+//
+// The cap is checked after every EndMetric: when the timestamp, value and tag
+// bytes buffered in memory exceed maxBytes, they are appended to one temporary
+// file per column in spillDir and the in-memory buffers are released. Finish
+// streams the spilled sections back and removes the files, so the finished blob
+// is byte-identical to one encoded without the cap. Use this in memory-constrained
+// collectors that may receive unexpectedly large batches.
+//
+// The cap bounds memory between metrics: a single metric larger than maxBytes
+// stays in memory until it ends, and Finish still needs each complete column in
+// memory to compress it. Spill files are only removed by Finish, so always call
+// Finish on an encoder that used this option.
+//
+// Parameters:
+//   - maxBytes: In-memory encoded bytes that trigger a spill (must be positive)
+//   - spillDir: Directory for spill files; "" uses the OS temporary directory
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables spilling, or an error if maxBytes
+//     is not positive or spillDir is not a directory.
+//
+// Example:
+//
+//	// Keep at most 64 MiB of encoded columns in memory
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithMaxEncoderMemory(64<<20, "/var/tmp"))
+func WithMaxEncoderMemory(maxBytes int, spillDir string) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		if maxBytes <= 0 {
+			return fmt.Errorf("invalid max encoder memory: %d, must be positive", maxBytes)
+		}
+
+		if spillDir != "" {
+			info, err := os.Stat(spillDir)
+			if err != nil {
+				return fmt.Errorf("invalid spill directory: %w", err)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("invalid spill directory: %s is not a directory", spillDir)
+			}
+		}
+
+		c.maxMemory = maxBytes
+		c.spillDir = spillDir
+
+		return nil
+	})
+}
+
+// WithSortedIndex writes V1 index entries sorted by MetricID and sets the sorted
+// index flag in the header.
+//
+// V1 blobs store metrics in insertion order, so decoders build a hash map over the
+// index. With a sorted index, decoders binary search the index instead, saving the
+// map allocation for blobs with many metrics; minimal decoders can search the raw
+// index entries without decoding them all. Payloads are reordered to match, as in
+// the V2 layout, which always sorts its index and ignores this option.
+//
+// Sorting can move the last metric, whose size is otherwise implicit, into the
+// middle of the index, so Finish returns ErrOffsetOutOfRange if its sections no
+// longer fit the uint16 offset deltas.
+//
+// IMPORTANT: Decoders that predate the sorted index flag reject these blobs.
+// Upgrade consumers before enabling this option on producers.
+//
+// Parameters:
+//   - enabled: Whether to sort V1 index entries
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the sorted index.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithSortedIndex(true))
+func WithSortedIndex(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.sortedIndex = enabled
+	})
+}
+
+// WithSmallHeader writes blobs of a single metric in the small layout: an 8-byte
+// header and a merged index of about 18 bytes instead of the 32-byte header and
+// 16-byte index entry, with uncompressed payloads. For sub-kilobyte blobs, such
+// as edge devices sending a few points of one metric per batch, the standard
+// layout overhead often exceeds the data itself, and compressing a few bytes
+// does not pay off.
+//
+// Blobs of several metrics, the V2 layout, word-aligned value sections and
+// blobs with a metric names payload use the standard layout regardless. The
+// metadata section is kept, so time bounds, metric metadata, sketches, value
+// sums, timestamp units and value precision work as usual.
+//
+// Decoders, BlobSet, QuickVerify, NumericBlobID and SplitNumericBlob read small
+// blobs transparently; metric remapping requires the standard layout.
+//
+// IMPORTANT: Decoders that predate the small layout reject these blobs.
+// Upgrade consumers before enabling this option on producers.
+//
+// Parameters:
+//   - enabled: Whether to write single-metric blobs in the small layout
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the small layout.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithSmallHeader(true))
+func WithSmallHeader(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.smallHeader = enabled
+	})
+}
+
+// WithEdgeProfile configures a minimal encoder for microcontroller-class or
+// embedded collectors: raw timestamp and value encodings, no compression, no
+// tags, and column buffers and index preallocated by NewNumericEncoder for a
+// fixed capacity of metrics and data points.
+//
+// After setup, AddDataPoint performs zero heap allocations, as does
+// AddDataPoints unless a NaN or time window policy drops or clamps data points.
+// Duplicate metric IDs are detected by scanning the preallocated index instead
+// of a map, so metrics must be started with StartMetricID and friends;
+// StartMetricName returns ErrUnsupportedBlobFeature, hash metric names once with
+// hash.ID during setup instead. Starting a metric beyond the capacity returns
+// ErrMetricCountExceeded or ErrInvalidNumOfDataPoints rather than growing the
+// buffers. Finish still allocates the blob; use FinishInto with a buffer of
+// MaxFinishedSize bytes to reuse one.
+//
+// Options that allocate per data point or use maps are rejected by
+// NewNumericEncoder and ValidateOptions with ErrUnsupportedBlobFeature, whether
+// they come before or after WithEdgeProfile: non-raw encodings, compression,
+// tags, shared timestamps, sketches, tag summaries, metric references and
+// WithMaxEncoderMemory. Options that select the edge defaults, such as
+// WithTagsEnabled(false) or WithValueCompression(format.CompressionNone), are
+// allowed. Time bounds, value sums, value
+// precision, NaN and time window policies and timestamp units are allowed.
+// Combine with WithSmallHeader for the smallest single-metric blobs.
+//
+// Parameters:
+//   - maxMetrics: Maximum number of metrics in the blob (1 to MaxMetricCount)
+//   - maxDataPoints: Maximum total number of data points across all metrics
+//     (must be positive)
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables the edge profile, or an error
+//     if a capacity is out of range.
+//
+// Example:
+//
+//	cpuID := hash.ID("cpu.usage")
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithEdgeProfile(4, 256))
+//	encoder.StartMetricID(cpuID, 60)
+//	for _, s := range samples {
+//	    encoder.AddDataPoint(s.Ts, s.Value, "") // never allocates
+//	}
+//	encoder.EndMetric()
+func WithEdgeProfile(maxMetrics, maxDataPoints int) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		if maxMetrics <= 0 || maxMetrics > MaxMetricCount {
+			return fmt.Errorf("invalid edge profile metric capacity: %d, must be between 1 and %d", maxMetrics, MaxMetricCount)
+		}
+		if maxDataPoints <= 0 {
+			return fmt.Errorf("invalid edge profile data point capacity: %d, must be positive", maxDataPoints)
+		}
+
+		// Replace only the defaults, explicit conflicting options are rejected
+		// by validateEdgeProfile whether they come before or after
+		if c.explicit&explicitTimestampEncoding == 0 {
+			c.header.Flag.SetTimestampEncoding(format.TypeRaw)
+		}
+		if c.explicit&explicitValueEncoding == 0 {
+			c.header.Flag.SetValueEncoding(format.TypeRaw)
+		}
+		if c.explicit&explicitTimestampCompression == 0 {
+			c.header.Flag.SetTimestampCompression(format.CompressionNone)
+		}
+		if c.explicit&explicitValueCompression == 0 {
+			c.header.Flag.SetValueCompression(format.CompressionNone)
+		}
+		if c.explicit&explicitTags == 0 {
+			c.header.Flag.WithoutTag()
+		}
+		c.edgeMetrics = maxMetrics
+		c.edgePoints = maxDataPoints
+
+		return nil
+	})
+}
+
+// WithCompressedMetricNames Zstd compresses the metric names payload.
+//
+// The payload stores every metric name and is written when metric names collide on
+// their hashed IDs; for blobs with thousands of names it can rival the data itself.
+// Compressed names are flagged in the header and decoders parse them lazily, on
+// the first name lookup (ByName methods, HasMetricName, MetricNames), so Decode
+// stays fast for consumers that only look metrics up by ID. The payload is kept
+// uncompressed if compression does not shrink it.
+//
+// IMPORTANT: Decoders that predate the compressed metric names flag reject these
+// blobs. Upgrade consumers before enabling this option on producers.
+//
+// Parameters:
+//   - enabled: Whether to compress the metric names payload
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables names compression.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithCompressedMetricNames(true))
+func WithCompressedMetricNames(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.compressNames = enabled
+	})
+}
+
+// WithDeterministicOutput guarantees that identical input yields byte-identical blobs.
+//
+// Identical input means the same options, start time, and metrics with the same data
+// points added in the same order. The encoder itself has no hidden state: metric
+// names are stored in insertion order, and sorting and deduplication are stable. The
+// remaining source of variation is compression, whose default Zstd encoder is tuned
+// per build; this option pins it to a fixed single-threaded configuration (see
+// compress.NewDeterministicZstdCompressor), so output is also identical across the
+// default and lite builds. Use it for content-addressed storage and golden-file tests.
+//
+// Blobs remain readable by every decoder; Zstd sections may be slightly larger for
+// payloads above 1 MiB.
+//
+// Parameters:
+//   - enabled: Whether to pin compressors to fixed configurations
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables deterministic output.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithDeterministicOutput(true))
+func WithDeterministicOutput(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.deterministic = enabled
+	})
+}
+
+// offsetUnits converts a metric section size in bytes to the stored offset delta,
+// rounding up to whole words when word-aligned offsets are enabled.
+func (c *NumericEncoderConfig) offsetUnits(size int) int {
+	if c.offsetUnit <= 1 {
+		return size
+	}
+
+	return (size + c.offsetUnit - 1) / c.offsetUnit
+}
+
+// WithValuePrecision rounds every value to the given number of decimal digits
+// before encoding.
+//
+// Sensor data rarely needs full float64 precision. Rounding produces values with
+// long runs of identical mantissa bits, which increases the trailing zeros seen
+// by XOR-based encodings (Gorilla, Chimp) and lets ALP pick small exponents, often
+// halving the value payload. The precision is recorded in the blob metadata
+// section, so readers can query it via NumericBlob.ValuePrecision.
+//
+// This option is lossy. NaN and ±Inf are stored unchanged. It replaces any
+// previously configured WithQuantization.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Parameters:
+//   - decimals: Number of decimal digits to keep, between 0 and MaxValuePrecision.
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables precision reduction, or an error if decimals is out of range.
+//
+// Example:
+//
+//	// Temperature readings with 0.01 resolution
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithValuePrecision(2))
+func WithValuePrecision(decimals int) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setValuePrecision(decimals)
+	})
+}
+
+// WithQuantization snaps every value to the nearest multiple of step before encoding.
+//
+// Use it when the source resolution is not a power of ten (e.g., an ADC with a
+// 0.25 unit step). Like WithValuePrecision, it trades exactness for a smaller
+// value payload, and the step is recorded in the blob metadata section so readers
+// can query it via NumericBlob.QuantizationStep.
+//
+// This option is lossy. NaN and ±Inf are stored unchanged. It replaces any
+// previously configured WithValuePrecision.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Parameters:
+//   - step: Quantization step, must be positive and finite.
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables quantization, or an error if step is invalid.
+func WithQuantization(step float64) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setQuantization(step)
+	})
+}
+
+// WithMetricReferences enables delta-against-reference metric encoding.
+//
+// When enabled, a metric started with NumericEncoder.StartMetricIDWithReference is
+// stored as the per-point difference between its values and the values of a
+// previously encoded reference metric in the same blob. Multi-channel sensors whose
+// series track each other closely produce near-zero differences that Gorilla and
+// Chimp compress far better than the original series. The decoder reconstructs
+// the original values transparently and losslessly.
+//
+// The encoder retains the values of every completed metric until Finish, so this
+// option increases encoder memory usage by 8 bytes per data point.
+//
+// The option writes a metadata section, see "Metadata Section" in the package documentation.
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables metric references.
+func WithMetricReferences() NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.metricRefs = true
+	})
+}
+
+// WithTimestampUnit declares the unit of the timestamps passed to the encoder.
+//
+// By default timestamps are Unix microseconds. Nanosecond-precision tracing data or
+// second-resolution billing data can instead be stored natively, without scaling
+// every timestamp. Coarser units also shrink delta-encoded timestamp payloads. The
+// unit is recorded in the blob metadata section, and readers can query it via
+// NumericBlob.TimestampUnit and convert with format.TimeUnit.Time or Convert.
+//
+// The blob start time passed to NewNumericEncoder is a time.Time and is unaffected.
+//
+// Unless the unit is format.TimeUnitMicrosecond, the option writes a metadata
+// section, see "Metadata Section" in the package documentation.
+//
+// Parameters:
+//   - unit: Timestamp unit, one of the format.TimeUnit constants.
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the timestamp unit, or an error if unit is unknown.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithTimestampUnit(format.TimeUnitNanosecond))
+//	encoder.StartMetricName("span.duration", 1)
+//	encoder.AddDataPoint(spanStart.UnixNano(), 42.5, "")
+func WithTimestampUnit(unit format.TimeUnit) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setTimestampUnit(unit)
+	})
+}
+
+// WithGorillaWindowReuse limits how many bits the Gorilla value encoder may waste
+// when reusing the previous leading/trailing zero window.
+//
+// Gorilla reuses the previous XOR window whenever the new XOR fits inside it. After a
+// single large change, that window stays wide and every later small change pays for
+// the full width. Limiting the waste makes the encoder open a new, tighter window
+// instead, at the cost of a 13-bit window header. Values around 8-16 work well for
+// series with occasional spikes.
+//
+// The option only changes encoder decisions; blobs remain readable by any mebo
+// version. It has no effect unless the value encoding is format.TypeGorilla.
+//
+// Parameters:
+//   - maxWastedBits: Maximum wasted bits per reused window, between 0 and
+//     MaxGorillaWindowWaste (default MaxGorillaWindowWaste, i.e. always reuse).
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the window reuse policy, or an error if out of range.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime,
+//	    blob.WithValueEncoding(format.TypeGorilla),
+//	    blob.WithGorillaWindowReuse(12),
+//	)
+func WithGorillaWindowReuse(maxWastedBits int) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setGorillaWindowReuse(maxWastedBits)
+	})
+}
+
+// WithValidationPolicy sets the rules NumericEncoder.AddDataPointsValidated uses
+// to reject individual data points.
+//
+// Without this option the zero ValidationPolicy applies: NaN values and
+// timestamps that do not increase are rejected, and no time window is enforced.
+// The policy does not affect AddDataPoint or AddDataPoints.
+//
+// Parameters:
+//   - policy: Rules for rejecting data points
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the policy, or an error if policy.Window is negative.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(start, blob.WithValidationPolicy(blob.ValidationPolicy{
+//	    Window: time.Hour, // reject points outside [start, start+1h)
+//	}))
+func WithValidationPolicy(policy ValidationPolicy) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setValidationPolicy(policy)
+	})
+}
+
+// WithNaNPolicy sets how the encoder handles NaN values, which many collectors
+// produce for failed scrapes.
+//
+// NaNKeep (the default) encodes them like any other value. NaNError makes
+// AddDataPoint and AddDataPoints return ErrNaNValue without adding any data point
+// of the call. NaNDrop silently skips them: each dropped point reduces the number
+// of data points claimed for the metric by one, so callers can still claim the
+// full batch size. If all points of a metric are dropped, EndMetric returns
+// ErrNoDataPointsAdded; if points of a metric started with
+// StartMetricIDWithReference are dropped, it returns ErrInvalidMetricReference.
+//
+// Parameters:
+//   - policy: NaNKeep, NaNDrop or NaNError
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the policy, or an error if it is unknown.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(start, blob.WithNaNPolicy(blob.NaNDrop))
+//	encoder.StartMetricID(id, len(values))
+//	encoder.AddDataPoints(timestamps, values, nil) // NaN values are skipped
+//	encoder.EndMetric()
+func WithNaNPolicy(policy NaNPolicy) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setNaNPolicy(policy)
+	})
+}
+
+// WithTimeWindow restricts the timestamps of encoded data points to [start, end),
+// typically the interval the blob is meant to cover.
+//
+// Agents with skewed clocks can report timestamps far from the blob's StartTime,
+// producing blobs whose data contradicts their order inside a NumericBlobSet.
+// The policy decides what happens to such data points in AddDataPoint and
+// AddDataPoints:
+//   - TimeWindowReject returns ErrTimestampOutOfWindow without adding any data
+//     point of the call.
+//   - TimeWindowDrop silently skips them; each dropped point reduces the number
+//     of data points claimed for the metric by one, like NaNDrop. Metrics started
+//     with StartMetricIDWithReference cannot lose points: EndMetric returns
+//     ErrInvalidMetricReference.
+//   - TimeWindowClamp moves them to the nearest edge of the window.
+//
+// AddDataPointsValidated reports points outside the window as RejectOutOfWindow
+// under TimeWindowReject and TimeWindowDrop. The window is converted to the
+// encoder's timestamp unit, see WithTimestampUnit.
+//
+// Parameters:
+//   - start: Inclusive start of the window
+//   - end: Exclusive end of the window
+//   - policy: TimeWindowReject, TimeWindowDrop or TimeWindowClamp
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the window, or an error if end is
+//     not after start (ErrInvalidTimeRange) or the policy is unknown.
+//
+// Example:
+//
+//	// Accept an hour of data, dropping points from agents with skewed clocks
+//	encoder, _ := blob.NewNumericEncoder(start,
+//	    blob.WithTimeWindow(start, start.Add(time.Hour), blob.TimeWindowDrop))
+func WithTimeWindow(start, end time.Time, policy TimeWindowPolicy) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setTimeWindow(start, end, policy)
+	})
+}
+
+// WithTimeBounds records the earliest and latest data point timestamps of the
+// blob in its metadata section.
+//
+// NumericBlob.TimeRange and NumericBlob.EndTime then answer from the header and
+// metadata alone, so readers can prune blobs by time without decoding any
+// timestamps. Without the record they scan the timestamp payload instead. The
+// record adds 22 bytes to the blob; blobs remain readable by any mebo version
+// that supports the metadata section.
+//
+// Parameters:
+//   - enabled: Whether to record the time bounds
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the time bounds record.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithTimeBounds(true))
+func WithTimeBounds(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.timeBounds = enabled
+	})
+}
+
+// WithSketches records a t-digest of the values of each metric in the blob's
+// metadata section.
+//
+// NumericBlob.Sketch then answers percentile queries from the metadata alone,
+// and BlobSet.Quantile uses the sketches of blobs lying within the queried
+// range instead of decoding their values. Sketches summarize the values as
+// stored, i.e. after quantization; NaN values are not counted. A sketch takes
+// about 550 bytes for a metric with thousands of distinct values and less for
+// short or repetitive series. Blobs remain readable by any mebo version that
+// supports the metadata section.
+//
+// Parameters:
+//   - enabled: Whether to record the sketches
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the sketch record.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithSketches(true))
+func WithSketches(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.sketches = enabled
+	})
+}
+
+// WithTagSummaries records the tag cardinality of each metric in the blob's
+// metadata section: the number of tagged data points, the number of distinct
+// tags and the most frequent tags with their counts.
+//
+// NumericBlob.TagSummary then answers label-cardinality queries from the
+// metadata alone instead of decoding every tag. The record is only written when
+// the blob stores tags (see WithTagsEnabled); empty tags are not counted. A
+// summary takes a few bytes plus the length of its top tags per metric. Blobs
+// remain readable by any mebo version that supports the metadata section.
+//
+// Parameters:
+//   - enabled: Whether to record the tag summaries
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the tag summary record.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime,
+//	    blob.WithTagsEnabled(true),
+//	    blob.WithTagSummaries(true),
+//	)
+func WithTagSummaries(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.tagSummaries = enabled
+	})
+}
+
+// WithValueSums records the sum and count of the values of each metric in the
+// blob's metadata section, summed with compensated (Kahan) summation.
+//
+// NumericBlob.ValueSum then answers sums and means from the metadata alone, and
+// BlobSet.Mean merges the sums of blobs lying within the queried range instead
+// of decoding their values. Storing the compensation term next to the sum keeps
+// means across many blobs numerically stable. Sums cover the values as stored,
+// i.e. after quantization; NaN values are not counted. A sum takes about 30
+// bytes per metric. Blobs remain readable by any mebo version that supports the
+// metadata section.
+//
+// Parameters:
+//   - enabled: Whether to record the value sums
+//
+// Returns:
+//   - NumericEncoderOption: An option that enables or disables the value sum record.
+//
+// Example:
+//
+//	encoder, _ := blob.NewNumericEncoder(startTime, blob.WithValueSums(true))
+func WithValueSums(enabled bool) NumericEncoderOption {
+	return options.NoError(func(c *NumericEncoderConfig) {
+		c.valueSums = enabled
+	})
+}
+
+// WithGorillaRebaseline makes the Gorilla value encoder discard its window state
+// every N values of a metric, so the next change opens a fresh window.
+//
+// Long series can accumulate window state that no longer fits the data; periodic
+// re-baselining bounds how long such a state can persist.
+//
+// The option only changes encoder decisions; blobs remain readable by any mebo
+// version. It has no effect unless the value encoding is format.TypeGorilla.
+//
+// Parameters:
+//   - every: Re-baseline interval in values, 0 disables it (default).
+//
+// Returns:
+//   - NumericEncoderOption: An option that sets the re-baseline interval, or an error if negative.
+func WithGorillaRebaseline(every int) NumericEncoderOption {
+	return options.New(func(c *NumericEncoderConfig) error {
+		return c.setGorillaRebaseline(every)
+	})
+}
@@ -0,0 +1,70 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/section"
+)
+
+// edgeColumnEncoder is a column encoder that preallocates its buffer, as the
+// raw encoders selected by WithEdgeProfile do.
+type edgeColumnEncoder interface {
+	Grow(n int)
+}
+
+// preallocateEdge sizes the index, column buffers and value scratch buffer for
+// the capacity set by WithEdgeProfile, so that adding data points never grows
+// them.
+func (e *NumericEncoder) preallocateEdge() {
+	e.indexEntries = make([]section.NumericIndexEntry, 0, e.edgeMetrics)
+	e.growEdgeColumns()
+
+	if e.quantizing() || e.valueSums {
+		e.valBuf = make([]float64, e.edgePoints)
+	}
+}
+
+// growEdgeColumns preallocates the column encoders for the remaining capacity,
+// also after RemoveMetric replaces them.
+func (e *NumericEncoder) growEdgeColumns() {
+	remaining := e.edgeRemaining()
+	if ts, ok := e.tsEncoder.(edgeColumnEncoder); ok {
+		ts.Grow(remaining)
+	}
+	if val, ok := e.valEncoder.(edgeColumnEncoder); ok {
+		val.Grow(remaining)
+	}
+}
+
+// claimEdgeMetric checks that a metric of numOfDataPoints data points fits the
+// remaining capacity of an edge profile encoder and that its ID is unused,
+// scanning the index instead of tracking IDs in a map.
+func (e *NumericEncoder) claimEdgeMetric(metricID uint64, numOfDataPoints int) error {
+	if len(e.indexEntries) >= e.edgeMetrics {
+		return fmt.Errorf("%w: edge profile capacity of %d metrics exceeded", errs.ErrMetricCountExceeded, e.edgeMetrics)
+	}
+
+	if remaining := e.edgeRemaining(); numOfDataPoints > remaining {
+		return fmt.Errorf("%w: edge profile capacity leaves %d data points", errs.ErrInvalidNumOfDataPoints, remaining)
+	}
+
+	for i := range e.indexEntries {
+		if e.indexEntries[i].MetricID == metricID {
+			return fmt.Errorf("%w: metric ID 0x%016x already used", errs.ErrHashCollision, metricID)
+		}
+	}
+
+	return nil
+}
+
+// edgeRemaining returns the number of data points an edge profile encoder can
+// still accept after its completed metrics.
+func (e *NumericEncoder) edgeRemaining() int {
+	remaining := e.edgePoints
+	for i := range e.indexEntries {
+		remaining -= e.indexEntries[i].Count
+	}
+
+	return remaining
+}
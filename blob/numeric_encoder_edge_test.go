@@ -0,0 +1,126 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericEncoder_EdgeProfile(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start, WithEdgeProfile(2, 300), WithTimeBounds(true), WithValueSums(true))
+	require.NoError(t, err)
+
+	flag := encoder.NumericHeader().Flag
+	require.Equal(t, format.TypeRaw, flag.TimestampEncoding())
+	require.Equal(t, format.TypeRaw, flag.ValueEncoding())
+	require.Equal(t, format.CompressionNone, flag.TimestampCompression())
+	require.Equal(t, format.CompressionNone, flag.ValueCompression())
+	require.False(t, flag.HasTag())
+
+	require.ErrorIs(t, encoder.StartMetricName("cpu", 1), errs.ErrUnsupportedBlobFeature)
+	require.ErrorIs(t, encoder.StartMetricID(1, 301), errs.ErrInvalidNumOfDataPoints)
+
+	require.NoError(t, encoder.StartMetricID(1, 200))
+	// AllocsPerRun calls the function once more to warm up: 50 + 2×75 data points
+	ts := start.UnixMicro()
+	allocs := testing.AllocsPerRun(49, func() {
+		_ = encoder.AddDataPoint(ts, 1.5, "")
+		ts++
+	})
+	require.Zero(t, allocs)
+	timestamps := make([]int64, 75)
+	values := make([]float64, 75)
+	for i := range timestamps {
+		timestamps[i], values[i] = ts+int64(i), 2.5
+	}
+	allocs = testing.AllocsPerRun(1, func() {
+		require.NoError(t, encoder.AddDataPoints(timestamps, values, nil))
+	})
+	require.Zero(t, allocs)
+	require.NoError(t, encoder.EndMetric())
+
+	// The hint of a streaming metric is capped by the remaining capacity
+	require.ErrorIs(t, encoder.StartMetricID(1, 1), errs.ErrHashCollision)
+	require.NoError(t, encoder.StartMetricIDHint(2, 0))
+	for i := range 100 {
+		require.NoError(t, encoder.AddDataPoint(ts+int64(i), 3.5, ""))
+	}
+	require.ErrorIs(t, encoder.AddDataPoint(ts, 3.5, ""), errs.ErrTooManyDataPoints)
+	require.NoError(t, encoder.EndMetric())
+	require.ErrorIs(t, encoder.StartMetricID(3, 1), errs.ErrMetricCountExceeded)
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, 200, blob.Len(1))
+	require.Equal(t, slices.Repeat([]float64{3.5}, 100), slices.Collect(blob.AllValues(2)))
+}
+
+func TestNumericEncoder_EdgeProfile_RemoveMetric(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithEdgeProfile(2, 4))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{1, 2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.RemoveMetric(1))
+
+	// The removed metric's capacity is available again, without allocations
+	require.NoError(t, encoder.StartMetricID(2, 4))
+	ts := int64(0)
+	allocs := testing.AllocsPerRun(3, func() {
+		ts++
+		require.NoError(t, encoder.AddDataPoint(ts, float64(ts), ""))
+	})
+	require.Zero(t, allocs)
+}
+
+func TestNumericEncoder_EdgeProfile_Options(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewNumericEncoder(start, WithEdgeProfile(0, 1))
+	require.Error(t, err)
+	_, err = NewNumericEncoder(start, WithEdgeProfile(1, 0))
+	require.Error(t, err)
+
+	conflicts := []NumericEncoderOption{
+		WithValueEncoding(format.TypeGorilla),
+		WithTimestampCompression(format.CompressionZstd),
+		WithTagsEnabled(true),
+		WithSharedTimestamps(),
+		WithSketches(true),
+		WithMetricReferences(),
+		WithMaxEncoderMemory(1<<20, ""),
+	}
+	// Conflicts are rejected whether they come before or after the profile
+	for _, opt := range conflicts {
+		_, err := NewNumericEncoder(start, WithEdgeProfile(1, 1), opt)
+		require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+		_, err = NewNumericEncoder(start, opt, WithEdgeProfile(1, 1))
+		require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+		require.ErrorIs(t, ValidateOptions(WithEdgeProfile(1, 1), opt), errs.ErrUnsupportedBlobFeature)
+		require.ErrorIs(t, ValidateOptions(opt, WithEdgeProfile(1, 1)), errs.ErrUnsupportedBlobFeature)
+	}
+
+	require.NoError(t, ValidateOptions(WithEdgeProfile(1, 1), WithValuePrecision(2), WithSmallHeader(true)))
+
+	// Options selecting the edge defaults are allowed in any order
+	defaults := []NumericEncoderOption{
+		WithTagsEnabled(false),
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+		WithTimestampCompression(format.CompressionNone),
+		WithValueCompression(format.CompressionNone),
+	}
+	require.NoError(t, ValidateOptions(append(defaults, WithEdgeProfile(1, 1))...))
+	require.NoError(t, ValidateOptions(append([]NumericEncoderOption{WithEdgeProfile(1, 1)}, defaults...)...))
+}
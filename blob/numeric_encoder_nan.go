@@ -0,0 +1,108 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// NaNPolicy selects how NumericEncoder handles NaN values (see WithNaNPolicy).
+type NaNPolicy uint8
+
+const (
+	// NaNKeep encodes NaN values like any other value (the default).
+	NaNKeep NaNPolicy = iota
+	// NaNDrop skips data points with NaN values. Each dropped point reduces the
+	// number of data points claimed for the metric by one.
+	NaNDrop
+	// NaNError rejects data points with NaN values with ErrNaNValue.
+	NaNError
+)
+
+// String returns the name of the policy.
+func (p NaNPolicy) String() string {
+	switch p {
+	case NaNKeep:
+		return "Keep"
+	case NaNDrop:
+		return "Drop"
+	case NaNError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsValid reports whether p is a known policy.
+func (p NaNPolicy) IsValid() bool {
+	return p <= NaNError
+}
+
+// applyNaNPolicy applies the NaN policy to a batch of data points.
+//
+// It returns the input slices unchanged if no value is NaN. Otherwise it returns
+// ErrNaNValue under NaNError, or copies of the slices without the NaN data points
+// and the number of dropped points under NaNDrop.
+func (e *NumericEncoder) applyNaNPolicy(timestamps []int64, values []float64, tags []string) ([]int64, []float64, []string, int, error) {
+	first := -1
+	for i, v := range values {
+		if math.IsNaN(v) {
+			first = i
+			break
+		}
+	}
+
+	if first < 0 {
+		return timestamps, values, tags, 0, nil
+	}
+
+	if e.nanPolicy == NaNError {
+		return nil, nil, nil, 0, fmt.Errorf("%w: data point %d of metric ID 0x%016x", errs.ErrNaNValue, first, e.curMetricID)
+	}
+
+	keptTs := make([]int64, first, len(timestamps))
+	keptVals := make([]float64, first, len(values))
+	copy(keptTs, timestamps)
+	copy(keptVals, values)
+
+	var keptTags []string
+	if len(tags) > 0 {
+		keptTags = make([]string, first, len(tags))
+		copy(keptTags, tags)
+	}
+
+	for i := first + 1; i < len(values); i++ {
+		if math.IsNaN(values[i]) {
+			continue
+		}
+
+		keptTs = append(keptTs, timestamps[i])
+		keptVals = append(keptVals, values[i])
+		if keptTags != nil {
+			keptTags = append(keptTags, tags[i])
+		}
+	}
+
+	return keptTs, keptVals, keptTags, len(values) - len(keptVals), nil
+}
+
+// inputIndex returns the position in input of the data point at position idx of
+// the n values left after applyNaNPolicy dropped the NaN data points of input.
+func inputIndex(input []float64, n, idx int) int {
+	if len(input) == n {
+		return idx
+	}
+
+	for i, v := range input {
+		if math.IsNaN(v) {
+			continue
+		}
+		if idx == 0 {
+			return i
+		}
+		idx--
+	}
+
+	return len(input)
+}
@@ -0,0 +1,127 @@
+package blob
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func TestNumericEncoder_NaNPolicy(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		name   string
+		policy NaNPolicy
+		want   []float64 // values of metric 1, nil if encoding fails
+	}{
+		{name: "keep", policy: NaNKeep, want: []float64{1, nan, 3, nan, 5, nan}},
+		{name: "drop", policy: NaNDrop, want: []float64{1, 3, 5}},
+		{name: "error", policy: NaNError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithTagsEnabled(true), WithNaNPolicy(tt.policy))
+			require.NoError(t, err)
+			require.NoError(t, encoder.StartMetricID(1, 6))
+
+			err = encoder.AddDataPoints([]int64{1, 2, 3, 4}, []float64{1, nan, 3, nan}, []string{"a", "b", "c", "d"})
+			if tt.want == nil {
+				require.ErrorIs(t, err, errs.ErrNaNValue)
+				require.ErrorIs(t, encoder.AddDataPoint(5, nan, ""), errs.ErrNaNValue)
+
+				// Rejected calls add nothing, so the full claim is still available.
+				require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3, 4, 5}, []float64{1, 2, 3, 4, 5}, nil))
+				require.NoError(t, encoder.AddDataPoint(6, 6, ""))
+				require.NoError(t, encoder.EndMetric())
+
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, encoder.AddDataPoint(5, 5, "e"))
+			require.NoError(t, encoder.AddDataPoint(6, nan, "f"))
+			require.NoError(t, encoder.EndMetric())
+
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			blob := decodeRemapTestBlob(t, data)
+			got := slices.Collect(blob.AllValues(1))
+			require.Len(t, got, len(tt.want))
+			for i, v := range tt.want {
+				if math.IsNaN(v) {
+					require.True(t, math.IsNaN(got[i]))
+				} else {
+					require.Equal(t, v, got[i])
+				}
+			}
+
+			if tt.policy == NaNDrop {
+				tag, ok := blob.TagAt(1, 1)
+				require.True(t, ok)
+				require.Equal(t, "c", tag)
+			}
+		})
+	}
+}
+
+func TestNumericEncoder_NaNPolicy_DropAll(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithNaNPolicy(NaNDrop))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 2))
+
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{math.NaN(), math.NaN()}, nil))
+	require.ErrorIs(t, encoder.EndMetric(), errs.ErrNoDataPointsAdded)
+
+	_, err = NewNumericEncoder(time.Unix(1700000000, 0), WithNaNPolicy(NaNPolicy(9)))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_NaNPolicy_Validated(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0),
+		WithNaNPolicy(NaNDrop),
+		WithValidationPolicy(ValidationPolicy{AllowNaN: true}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+
+	// Dropped NaN values still show up in the report.
+	report, err := encoder.AddDataPointsValidated([]int64{1, 2, 3}, []float64{1, math.NaN(), 3}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Accepted)
+	require.Len(t, report.Rejected, 1)
+	require.Equal(t, RejectNaN, report.Rejected[0].Reason)
+	require.NoError(t, encoder.EndMetric())
+}
+
+func TestNumericEncoder_NaNPolicy_Reference(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithMetricReferences(), WithNaNPolicy(NaNDrop))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{1, 2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	// A dropped NaN leaves the metric shorter than its reference
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{1.5, math.NaN()}, nil))
+	require.NoError(t, encoder.AddDataPoint(3, 3.5, ""))
+	require.ErrorIs(t, encoder.EndMetric(), errs.ErrInvalidMetricReference)
+	require.NoError(t, encoder.AbortMetric())
+
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{1.5, 2.5, 3.5}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1.5, 2.5, 3.5}, slices.Collect(blob.AllValues(2)))
+}
@@ -0,0 +1,172 @@
+package blob
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/section"
+)
+
+// RemoveMetric removes an already ended metric from the blob being encoded:
+// its encoded timestamps, values and tags, its index entry and its metadata
+// (metric meta, sketch, tag summary, value sum and time bounds). Use it when
+// validation after EndMetric finds the metric's data bogus. The metric's
+// identifier is released, so it may be encoded again.
+//
+// RemoveMetric rebuilds the column encoders, keeping the bytes of the remaining
+// metrics aside until Finish (in memory unless WithMaxEncoderMemory is set);
+// the cost is proportional to the bytes encoded after the removed metric.
+//
+// Parameters:
+//   - metricID: ID of the ended metric to remove
+//
+// Returns:
+//   - error: ErrMetricAlreadyStarted if a metric is started (end or abort it
+//     first), ErrMetricNotFound if no ended metric has the ID, ErrAmbiguousMetric
+//     if colliding metric names share the ID, ErrInvalidMetricReference if
+//     another metric is stored as deltas against it, or ErrUnsupportedBlobFeature
+//     with an extension value encoder (see WithValueEncoder)
+//
+// Example:
+//
+//	encoder.EndMetric()
+//	if !validate(series) {
+//	    encoder.RemoveMetric(series.ID)
+//	}
+func (e *NumericEncoder) RemoveMetric(metricID uint64) error {
+	if e.curMetricID != 0 {
+		return fmt.Errorf("%w: end or abort metric ID 0x%016x before removing a metric", errs.ErrMetricAlreadyStarted, e.curMetricID)
+	}
+
+	idx := -1
+	for i := range e.indexEntries {
+		if e.indexEntries[i].MetricID != metricID {
+			continue
+		}
+		if idx >= 0 {
+			return fmt.Errorf("%w: metric ID 0x%016x", errs.ErrAmbiguousMetric, metricID)
+		}
+		idx = i
+	}
+
+	if idx < 0 {
+		return fmt.Errorf("%w: metric ID 0x%016x", errs.ErrMetricNotFound, metricID)
+	}
+
+	for _, ref := range e.refs {
+		if ref.refID == metricID {
+			return fmt.Errorf("%w: metric ID 0x%016x is the reference of metric ID 0x%016x",
+				errs.ErrInvalidMetricReference, metricID, ref.metricID)
+		}
+	}
+
+	if e.extEncoder != nil {
+		return fmt.Errorf("%w: cannot remove a metric written with an extension value encoder", errs.ErrUnsupportedBlobFeature)
+	}
+
+	if err := e.removeColumns(idx); err != nil {
+		return err
+	}
+
+	e.removeMetricState(idx, metricID)
+
+	return nil
+}
+
+// removeColumns cuts the column bytes of the idx-th ended metric and removes
+// its index entry.
+//
+// The bytes of all ended metrics move to the spill first. The entry after the
+// removed one takes over its offset delta, which then spans the metric before
+// the removed one; if the removed metric was the last one, the last metric
+// start moves back to the metric before it.
+func (e *NumericEncoder) removeColumns(idx int) error {
+	if e.spill == nil {
+		e.spill = &columnSpill{dir: e.spillDir, inMemory: e.maxMemory <= 0}
+	}
+
+	if err := e.moveColumnsToSpill(e.tsEncoder.Bytes(), e.valEncoder.Bytes(), e.tagEncoder.Bytes()); err != nil {
+		return err
+	}
+
+	states := [spillColumns]*encoderState{&e.ts, &e.val, &e.tag}
+	var start [spillColumns]int
+	for i := 0; i <= idx; i++ {
+		for c, d := range entryOffsetDeltas(e.indexEntries[i]) {
+			start[c] += d
+		}
+	}
+
+	removed := entryOffsetDeltas(e.indexEntries[idx])
+	for c := range spillColumns {
+		end := e.spill.sizes[c]
+		if idx+1 < len(e.indexEntries) {
+			end = start[c] + entryOffsetDeltas(e.indexEntries[idx+1])[c]
+		} else {
+			states[c].lastOffset = -removed[c]
+		}
+
+		if err := e.spill.remove(c, start[c], end); err != nil {
+			return err
+		}
+	}
+
+	if idx+1 < len(e.indexEntries) {
+		next := &e.indexEntries[idx+1]
+		next.TimestampOffset, next.ValueOffset, next.TagOffset = removed[spillTs], removed[spillVal], removed[spillTag]
+	}
+	e.indexEntries = slices.Delete(e.indexEntries, idx, idx+1)
+
+	return nil
+}
+
+// entryOffsetDeltas returns the column offset deltas of an index entry, indexed
+// by spill column.
+func entryOffsetDeltas(entry section.NumericIndexEntry) [spillColumns]int {
+	return [spillColumns]int{entry.TimestampOffset, entry.ValueOffset, entry.TagOffset}
+}
+
+// removeMetricState drops the identifier and per-metric metadata of the
+// idx-th ended metric, whose index entry is already removed.
+func (e *NumericEncoder) removeMetricState(idx int, metricID uint64) {
+	if e.collisionTracker != nil {
+		e.collisionTracker.Remove(idx)
+		e.hasCollision = e.collisionTracker.HasCollision()
+	}
+	delete(e.usedIDs, metricID)
+
+	if e.retained != nil {
+		delete(e.retained, metricID)
+		e.refs = slices.DeleteFunc(e.refs, func(ref metricReference) bool { return ref.metricID == metricID })
+	}
+
+	e.metas = slices.DeleteFunc(e.metas, func(m metricMetaEntry) bool { return m.metricID == metricID })
+
+	if len(e.sketchEntries) > idx {
+		e.sketchEntries, e.sketchBodies = removeMetricBody(e.sketchEntries, e.sketchBodies, idx)
+	}
+	if len(e.tagSummaryEntries) > idx {
+		e.tagSummaryEntries, e.tagSummaryBodies = removeMetricBody(e.tagSummaryEntries, e.tagSummaryBodies, idx)
+	}
+	if len(e.sumEntries) > idx {
+		e.sumEntries, e.sumBodies = removeMetricBody(e.sumEntries, e.sumBodies, idx)
+	}
+
+	if len(e.metricBounds) > idx {
+		e.metricBounds = slices.Delete(e.metricBounds, idx, idx+1)
+		e.hasBounds = len(e.metricBounds) > 0
+		for i, b := range e.metricBounds {
+			if i == 0 {
+				e.minTs, e.maxTs = b[0], b[1]
+			}
+			e.minTs, e.maxTs = min(e.minTs, b[0]), max(e.maxTs, b[1])
+		}
+	}
+
+	if len(e.indexEntries) == 0 {
+		e.identifierMode = modeUndefined
+		e.collisionTracker = nil
+		e.usedIDs = nil
+	}
+}
@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func TestNumericEncoder_RemoveMetric(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	configs := map[string][]NumericEncoderOption{
+		"raw":         {WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)},
+		"delta":       {WithTimestampEncoding(format.TypeDelta), WithValueEncoding(format.TypeGorilla)},
+		"deltapacked": {WithTimestampEncoding(format.TypeDeltaPacked), WithValueEncoding(format.TypeChimp)},
+		"alp":         {WithValueEncoding(format.TypeALP)},
+		"tags":        {WithTagsEnabled(true), WithValueSums(true), WithSketches(true), WithTimeBounds(true)},
+		"layout v2":   {WithBlobLayoutV2(), WithTagsEnabled(true), WithTagSummaries(true)},
+		"spill":       {WithMaxEncoderMemory(1, t.TempDir())},
+	}
+	names := []string{"cpu", "mem", "disk"}
+
+	for name, opts := range configs {
+		for removed := range names {
+			t.Run(name+"/"+names[removed], func(t *testing.T) {
+				want, err := NewNumericEncoder(start, opts...)
+				require.NoError(t, err)
+				for i, metric := range names {
+					if i != removed {
+						addAbortTestMetric(t, want, metric, 5+i, true)
+					}
+				}
+				addAbortTestMetric(t, want, "net", 4, true)
+				wantData, err := want.Finish()
+				require.NoError(t, err)
+
+				got, err := NewNumericEncoder(start, opts...)
+				require.NoError(t, err)
+				for i, metric := range names {
+					addAbortTestMetric(t, got, metric, 5+i, true)
+				}
+				require.NoError(t, got.RemoveMetric(hash.ID(names[removed])))
+				addAbortTestMetric(t, got, "net", 4, true)
+				gotData, err := got.Finish()
+				require.NoError(t, err)
+
+				require.Equal(t, wantData, gotData)
+			})
+		}
+	}
+}
+
+func TestNumericEncoder_RemoveMetric_Errors(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start, WithMetricReferences())
+	require.NoError(t, err)
+
+	require.ErrorIs(t, encoder.RemoveMetric(1), errs.ErrMetricNotFound)
+
+	require.NoError(t, encoder.StartMetricID(1, 2))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{1, 2}, nil))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 2))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{1.5, 2.5}, nil))
+
+	require.ErrorIs(t, encoder.RemoveMetric(1), errs.ErrMetricAlreadyStarted)
+	require.NoError(t, encoder.EndMetric())
+	require.ErrorIs(t, encoder.RemoveMetric(1), errs.ErrInvalidMetricReference)
+
+	// Removing the referencing metric first releases the reference.
+	require.NoError(t, encoder.RemoveMetric(2))
+	require.NoError(t, encoder.RemoveMetric(1))
+
+	// With every metric removed, the identifier mode is free again.
+	require.NoError(t, encoder.StartMetricName("cpu", 1))
+	require.NoError(t, encoder.AddDataPoint(1, 3, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, 1, blob.MetricCount())
+	v, ok := blob.ValueAtByName("cpu", 0)
+	require.True(t, ok)
+	require.Equal(t, 3.0, v)
+}
@@ -0,0 +1,118 @@
+package blob
+
+import (
+	"github.com/arloliu/mebo/section"
+)
+
+// NumericEncoderStats is a snapshot of a NumericEncoder's progress and of its
+// remaining capacity, returned by NumericEncoder.Stats.
+//
+// Byte counts are uncompressed encoded sizes. Bit-packed value encodings
+// (Gorilla, Chimp) may hold a few pending bits of the started metric that are
+// not counted yet.
+type NumericEncoderStats struct {
+	Metrics    int // number of ended metrics
+	DataPoints int // number of data points of ended metrics
+
+	// Started metric, zero if no metric is started
+	CurrentMetricID   uint64 // ID of the started metric
+	CurrentDataPoints int    // data points added to the started metric
+
+	// Encoded bytes per section, including the started metric and spilled bytes
+	TimestampBytes int // timestamp payload
+	ValueBytes     int // value payload
+	TagBytes       int // tag payload, zero if tags are disabled
+	IndexBytes     int // index entries of ended metrics
+	MaxBlobBytes   int // upper bound of the finished blob size (see MaxFinishedSize)
+
+	// Remaining capacity before the blob or the started metric hits a limit
+	RemainingMetrics    int // metrics that can still be started before MaxMetricCount
+	RemainingDataPoints int // data points the started (or next) metric can still take (see MaxDataPoints)
+	RemainingBytes      int // bytes any column of the started (or next) metric can still take before its index offset delta overflows
+}
+
+// Stats returns the progress and remaining capacity of the encoder, so
+// long-running batch jobs can log progress and cut a blob before reaching a
+// limit. It may be called at any point before Finish and does not modify the
+// encoder.
+//
+// The per-metric limits come from the index entry format: with the default V1
+// layout, offset deltas and counts are stored as uint16, so a metric whose
+// column grows past RemainingBytes is rejected by the EndMetric of the metric
+// after it. The V2 layout widens the limits to uint32.
+//
+// Returns:
+//   - NumericEncoderStats: Snapshot of the encoder's progress and capacity
+//
+// Example:
+//
+//	stats := encoder.Stats()
+//	if stats.RemainingMetrics == 0 || stats.MaxBlobBytes > targetBlobSize {
+//	    data, err := encoder.Finish()
+//	    // ... store data and continue with a new encoder
+//	}
+func (e *NumericEncoder) Stats() NumericEncoderStats {
+	stats := NumericEncoderStats{
+		Metrics:         len(e.indexEntries),
+		CurrentMetricID: e.curMetricID,
+		TimestampBytes:  e.tsEncoder.Size(),
+		ValueBytes:      e.valEncoder.Size(),
+		MaxBlobBytes:    e.MaxFinishedSize(),
+	}
+
+	hasTag := e.header.Flag.HasTag()
+	if hasTag {
+		stats.TagBytes = e.tagEncoder.Size()
+	}
+
+	if e.spill != nil {
+		stats.TimestampBytes += e.spill.sizes[spillTs]
+		stats.ValueBytes += e.spill.sizes[spillVal]
+		stats.TagBytes += e.spill.sizes[spillTag]
+	}
+
+	for i := range e.indexEntries {
+		stats.DataPoints += e.indexEntries[i].Count
+	}
+
+	_, entrySize := e.selectIndexFormat(hasTag)
+	stats.IndexBytes = stats.Metrics * entrySize
+
+	stats.RemainingMetrics = MaxMetricCount - stats.Metrics
+
+	maxBytes := section.NumericMaxOffset * max(e.offsetUnit, 1)
+	if e.layoutVersion >= 2 {
+		maxBytes = section.NumericExtMaxOffset
+	}
+
+	curBytes := 0
+	if e.curMetricID != 0 {
+		stats.CurrentDataPoints = e.curPoints
+		stats.RemainingMetrics--
+
+		curBytes = max(e.tsEncoder.Size()-e.ts.offset, e.valEncoder.Size()-e.val.offset)
+		if hasTag {
+			curBytes = max(curBytes, e.tagEncoder.Size()-e.tag.offset)
+		}
+	}
+
+	stats.RemainingDataPoints = max(e.MaxDataPoints()-stats.CurrentDataPoints, 0)
+	stats.RemainingBytes = max(maxBytes-curBytes, 0)
+
+	return stats
+}
+
+// encodedSize returns the uncompressed size of the encoded columns and index
+// entries, a cheap running estimate of the blob size.
+func (e *NumericEncoder) encodedSize() int {
+	size := e.tsEncoder.Size() + e.valEncoder.Size() + len(e.indexEntries)*section.NumericIndexEntrySize
+	if e.header.Flag.HasTag() {
+		size += e.tagEncoder.Size()
+	}
+
+	if e.spill != nil {
+		size += e.spill.sizes[spillTs] + e.spill.sizes[spillVal] + e.spill.sizes[spillTag]
+	}
+
+	return size
+}
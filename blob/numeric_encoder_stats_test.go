@@ -0,0 +1,62 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+func TestNumericEncoder_Stats(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start,
+		WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw), WithTagsEnabled(true))
+	require.NoError(t, err)
+
+	stats := encoder.Stats()
+	require.Zero(t, stats.Metrics)
+	require.Zero(t, stats.TimestampBytes)
+	require.Equal(t, MaxMetricCount, stats.RemainingMetrics)
+	require.Equal(t, encoder.MaxDataPoints(), stats.RemainingDataPoints)
+	require.Equal(t, section.NumericMaxOffset, stats.RemainingBytes)
+
+	addAbortTestMetric(t, encoder, "cpu", 10, true)
+	addAbortTestMetric(t, encoder, "mem", 4, false)
+
+	stats = encoder.Stats()
+	require.Equal(t, 1, stats.Metrics)
+	require.Equal(t, 10, stats.DataPoints)
+	require.NotZero(t, stats.CurrentMetricID)
+	require.Equal(t, 4, stats.CurrentDataPoints)
+	require.Equal(t, 14*8, stats.TimestampBytes)
+	require.Equal(t, 14*8, stats.ValueBytes)
+	require.NotZero(t, stats.TagBytes)
+	require.Equal(t, section.NumericIndexEntrySize, stats.IndexBytes)
+	require.Equal(t, MaxMetricCount-2, stats.RemainingMetrics)
+	require.Equal(t, encoder.MaxDataPoints()-4, stats.RemainingDataPoints)
+	require.Equal(t, section.NumericMaxOffset-4*8, stats.RemainingBytes)
+
+	require.NoError(t, encoder.EndMetric())
+	stats = encoder.Stats()
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), stats.MaxBlobBytes)
+}
+
+func TestNumericEncoder_Stats_Spill(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start, WithTimestampEncoding(format.TypeRaw),
+		WithMaxEncoderMemory(1, t.TempDir()))
+	require.NoError(t, err)
+
+	addAbortTestMetric(t, encoder, "cpu", 10, true)
+	addAbortTestMetric(t, encoder, "mem", 5, true)
+
+	stats := encoder.Stats()
+	require.Equal(t, 2, stats.Metrics)
+	require.Equal(t, 15, stats.DataPoints)
+	require.Equal(t, 15*8, stats.TimestampBytes)
+}
@@ -1,13 +1,19 @@
 package blob
 
 import (
+	"bytes"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/arloliu/mebo/compress"
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/hash"
@@ -529,6 +535,58 @@ func TestNumericEncoder_FinishInto(t *testing.T) {
 	})
 }
 
+func TestNumericEncoder_FinishTo(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Default"},
+		{name: "Tags", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "SharedTimestamps", opts: []NumericEncoderOption{WithSharedTimestamps()}},
+		{name: "WordAligned", opts: []NumericEncoderOption{WithWordAlignedOffsets(), WithTagsEnabled(true)}},
+		{name: "ValueAligned", opts: []NumericEncoderOption{WithValueAlignment(64), WithTagsEnabled(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encode := func() *NumericEncoder {
+				encoder, err := NewNumericEncoder(start, tt.opts...)
+				require.NoError(t, err)
+				for _, name := range []string{"cpu", "mem", "disk"} {
+					require.NoError(t, encoder.StartMetricName(name, 10))
+					for i := range 10 {
+						require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i)*1000000, float64(i)*1.25, "host=a"))
+					}
+					require.NoError(t, encoder.EndMetric())
+				}
+
+				return encoder
+			}
+
+			want, err := encode().Finish()
+			require.NoError(t, err)
+
+			encoder := encode()
+			bound := encoder.MaxFinishedSize()
+			require.GreaterOrEqual(t, bound, len(want))
+
+			dst := make([]byte, 7, bound)
+			got, err := encoder.FinishTo(dst)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+			// Written at the start of dst's backing array without reallocation.
+			require.Same(t, &dst[0], &got[0])
+		})
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		got, err := createTestEncoder(t).FinishTo(make([]byte, 0, 64))
+		require.ErrorIs(t, err, errs.ErrNoMetricsAdded)
+		require.Nil(t, got)
+	})
+}
+
 func TestNumericEncoder_ValidationMethods(t *testing.T) {
 	encoder := createTestEncoder(t)
 
@@ -1444,9 +1502,9 @@ func TestNumericEncoder_HeaderImmutability(t *testing.T) {
 		originalHeader := &struct {
 			MetricCount            uint32
 			IndexOffset            uint32
-			TimestampPayloadOffset uint32
-			ValuePayloadOffset     uint32
-			TagPayloadOffset       uint32
+			TimestampPayloadOffset uint64
+			ValuePayloadOffset     uint64
+			TagPayloadOffset       uint64
 			HasMetricNames         bool
 		}{
 			MetricCount:            encoder.header.MetricCount,
@@ -2966,28 +3024,20 @@ func TestNumericEncoder_V1_DeltaGuardPreserved(t *testing.T) {
 	require.ErrorIs(t, err, errs.ErrInvalidNumOfDataPoints)
 }
 
-func TestValidateBlobSize(t *testing.T) {
+func TestNeedsWideHeader(t *testing.T) {
 	tests := []struct {
 		name     string
 		blobSize int
-		wantErr  bool
+		want     bool
 	}{
-		{name: "below limit", blobSize: maxBlobBytes - 1, wantErr: false},
-		{name: "at limit", blobSize: maxBlobBytes, wantErr: false},
-		{name: "above limit", blobSize: maxBlobBytes + 1, wantErr: true},
+		{name: "below limit", blobSize: maxBlobBytes - 1, want: false},
+		{name: "at limit", blobSize: maxBlobBytes, want: false},
+		{name: "above limit", blobSize: maxBlobBytes + 1, want: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateBlobSize(tt.blobSize)
-			if tt.wantErr {
-				require.Error(t, err)
-				require.ErrorIs(t, err, errs.ErrBlobSizeExceedsLimit)
-
-				return
-			}
-
-			require.NoError(t, err)
+			require.Equal(t, tt.want, needsWideHeader(tt.blobSize))
 		})
 	}
 }
@@ -3018,6 +3068,7 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 		name      string
 		tsEnc     format.EncodingType
 		valEnc    format.EncodingType
+		tag       string // non-empty enables tags
 		metrics   []metricSpec
 		wantMagic uint16
 	}{
@@ -3052,10 +3103,32 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 			},
 			wantMagic: section.MagicNumericV2Opt,
 		},
-		// --- Extended mode (0xEA30): offset delta exceeds uint16 ---
+		// --- Tagless mode (0xEA40): offset delta exceeds uint16, no tags ---
+		{
+			name:  "tagless/raw/offset trigger/uniform large metrics",
+			tsEnc: format.TypeRaw, valEnc: format.TypeRaw,
+			// 8192 × 8 bytes = 65536 > 65535, fits uint24
+			metrics: []metricSpec{
+				{id: 1001, points: 8192, value: 1.5},
+				{id: 1002, points: 8192, value: 2.5},
+			},
+			wantMagic: section.MagicNumericV2NoTagOpt,
+		},
+		{
+			name:  "tagless/raw/offset trigger/one large among small",
+			tsEnc: format.TypeRaw, valEnc: format.TypeRaw,
+			metrics: []metricSpec{
+				{id: 1001, points: 5, value: 1.0},
+				{id: 1002, points: 9000, value: 3.14},
+				{id: 1003, points: 3, value: 0.5},
+			},
+			wantMagic: section.MagicNumericV2NoTagOpt,
+		},
+		// --- Extended mode (0xEA30): offset delta exceeds uint16 with tags ---
 		{
 			name:  "extended/raw/offset trigger/uniform large metrics",
 			tsEnc: format.TypeRaw, valEnc: format.TypeRaw,
+			tag: "t",
 			// 8192 × 8 bytes = 65536 > 65535
 			metrics: []metricSpec{
 				{id: 1001, points: 8192, value: 1.5},
@@ -3066,6 +3139,7 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 		{
 			name:  "extended/raw/offset trigger/one large among small",
 			tsEnc: format.TypeRaw, valEnc: format.TypeRaw,
+			tag: "t",
 			// Metric 1002: 9000 × 8 = 72000 > 65535
 			metrics: []metricSpec{
 				{id: 1001, points: 5, value: 1.0},
@@ -3104,6 +3178,7 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 				WithValueEncoding(tt.valEnc),
 				WithTimestampCompression(format.CompressionNone),
 				WithValueCompression(format.CompressionNone),
+				WithTagsEnabled(tt.tag != ""),
 				WithBlobLayoutV2(),
 			)
 			require.NoError(t, err)
@@ -3115,7 +3190,7 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 				require.NoError(t, err)
 
 				for i := range m.points {
-					err = encoder.AddDataPoint(baseTS+int64(i)*1_000_000, m.value, "")
+					err = encoder.AddDataPoint(baseTS+int64(i)*1_000_000, m.value, tt.tag)
 					require.NoError(t, err)
 				}
 				err = encoder.EndMetric()
@@ -3167,6 +3242,102 @@ func TestNumericEncoder_V2AdaptiveIndexFormat(t *testing.T) {
 	}
 }
 
+func TestSelectNumericIndexFormat(t *testing.T) {
+	entry := func(count, tsDelta, valDelta, tagDelta int) section.NumericIndexEntry {
+		return section.NumericIndexEntry{Count: count, TimestampOffset: tsDelta, ValueOffset: valDelta, TagOffset: tagDelta}
+	}
+
+	tests := []struct {
+		name          string
+		layoutVersion uint8
+		hasTag        bool
+		entries       []section.NumericIndexEntry
+		wantMagic     uint16
+		wantSize      int
+	}{
+		{
+			name: "V1 always compact", layoutVersion: 1,
+			entries:   []section.NumericIndexEntry{entry(1, 70_000, 0, 0)},
+			wantMagic: section.MagicNumericV1Opt, wantSize: section.NumericIndexEntrySize,
+		},
+		{
+			name: "compact fits", layoutVersion: 2,
+			entries:   []section.NumericIndexEntry{entry(65535, 65535, 65535, 0)},
+			wantMagic: section.MagicNumericV2Opt, wantSize: section.NumericIndexEntrySize,
+		},
+		{
+			name: "tagless offsets", layoutVersion: 2,
+			entries:   []section.NumericIndexEntry{entry(1, 0, 0, 0), entry(10, section.NumericNoTagMaxOffset, 65536, 0)},
+			wantMagic: section.MagicNumericV2NoTagOpt, wantSize: section.NumericIndexEntrySize,
+		},
+		{
+			name: "tagged offsets", layoutVersion: 2, hasTag: true,
+			entries:   []section.NumericIndexEntry{entry(10, 65536, 0, 0)},
+			wantMagic: section.MagicNumericV2ExtOpt, wantSize: section.NumericExtIndexEntrySize,
+		},
+		{
+			name: "tagless offset beyond uint24", layoutVersion: 2,
+			entries:   []section.NumericIndexEntry{entry(10, 0, section.NumericNoTagMaxOffset+1, 0)},
+			wantMagic: section.MagicNumericV2ExtOpt, wantSize: section.NumericExtIndexEntrySize,
+		},
+		{
+			name: "tagless count beyond uint16", layoutVersion: 2,
+			entries:   []section.NumericIndexEntry{entry(65536, 100, 100, 0)},
+			wantMagic: section.MagicNumericV2ExtOpt, wantSize: section.NumericExtIndexEntrySize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			magic, size := selectNumericIndexFormat(tt.layoutVersion, tt.hasTag, tt.entries)
+			require.Equal(t, tt.wantMagic, magic)
+			require.Equal(t, tt.wantSize, size)
+		})
+	}
+}
+
+func TestNumericEncoder_V2TaglessBigEndian(t *testing.T) {
+	startTime := time.Now()
+	baseTS := startTime.UnixMicro()
+
+	encoder, err := NewNumericEncoder(startTime,
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+		WithBigEndian(),
+		WithBlobLayoutV2(),
+	)
+	require.NoError(t, err)
+
+	// 9000 × 8 = 72000 bytes: the second metric's delta exceeds uint16
+	for _, id := range []uint64{1, 2} {
+		require.NoError(t, encoder.StartMetricID(id, 9000))
+		for i := range 9000 {
+			require.NoError(t, encoder.AddDataPoint(baseTS+int64(i), float64(id)*float64(i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	require.True(t, header.Flag.IsV2NoTag())
+	require.Equal(t, section.NumericIndexEntrySize*2, int(header.TimestampPayloadOffset-uint64(header.IndexOffset)))
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	val, ok := blob.ValueAt(2, 8999)
+	require.True(t, ok)
+	require.Equal(t, 2*8999.0, val)
+	ts, ok := blob.TimestampAt(2, 0)
+	require.True(t, ok)
+	require.Equal(t, baseTS, ts)
+}
+
 // TestNumericEncoder_V2_LastMetricLargePayloadSortPromotesExtended verifies that when the
 // last metric (in insertion order) has a payload exceeding uint16 range, sorting by MetricID
 // correctly promotes to extended format. Before sorting, the last metric's payload size is
@@ -3219,12 +3390,13 @@ func TestNumericEncoder_V2_LastMetricLargePayloadSortPromotesExtended(t *testing
 	data, err := encoder.Finish()
 	require.NoError(t, err)
 
-	// Must select extended (0xEA30) because after sorting, metric 1000's 72000-byte
-	// payload becomes an explicit delta exceeding uint16.
+	// Must leave compact format because after sorting, metric 1000's 72000-byte
+	// payload becomes an explicit delta exceeding uint16. The blob has no tags, so
+	// the tagless format (uint24 offsets) is selected.
 	options := uint16(data[0]) | (uint16(data[1]) << 8)
 	magic := options & section.MagicNumberMask
-	require.Equal(t, uint16(section.MagicNumericV2ExtOpt), magic,
-		"expected extended format 0xEA30: last-inserted large metric must trigger upgrade after sort")
+	require.Equal(t, uint16(section.MagicNumericV2NoTagOpt), magic,
+		"expected tagless format 0xEA40: last-inserted large metric must trigger upgrade after sort")
 
 	// Verify round-trip decode
 	decoder, err := NewNumericDecoder(data)
@@ -3380,7 +3552,8 @@ func TestNumericEncoder_V2MaxDeltaChainStaysCompact(t *testing.T) {
 }
 
 // TestNumericEncoder_V2SingleTriggerExtended verifies that when 100 metrics are encoded
-// and only the 50th has a delta exceeding uint16, the entire blob switches to Extended mode.
+// and only the 50th has a delta exceeding uint16, the entire blob leaves compact mode
+// (for the tagless format, since the blob has no tags).
 func TestNumericEncoder_V2SingleTriggerExtended(t *testing.T) {
 	startTime := time.Now()
 	baseTS := startTime.UnixMicro()
@@ -3418,10 +3591,11 @@ func TestNumericEncoder_V2SingleTriggerExtended(t *testing.T) {
 	data, err := encoder.Finish()
 	require.NoError(t, err)
 
-	// Verify magic is extended (0xEA30) because the 50th metric triggers it
+	// Verify magic leaves compact because the 50th metric triggers it; without tags
+	// the tagless format (0xEA40) holds the delta
 	options := uint16(data[0]) | (uint16(data[1]) << 8)
 	magic := options & section.MagicNumberMask
-	require.Equal(t, uint16(section.MagicNumericV2ExtOpt), magic, "expected extended format 0xEA30")
+	require.Equal(t, uint16(section.MagicNumericV2NoTagOpt), magic, "expected tagless format 0xEA40")
 
 	// Verify round-trip decode
 	decoder, err := NewNumericDecoder(data)
@@ -3563,3 +3737,1058 @@ func encodePointAPIFixture(
 
 	return data
 }
+
+func TestNumericEncoder_ValuePrecision(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+	values := []float64{1.23456, -7.891011, 100.005, math.NaN(), math.Inf(1)}
+	timestamps := make([]int64, len(values))
+	for i := range timestamps {
+		timestamps[i] = startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+	}
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) NumericBlob {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+
+		// Mix per-point and batch appends; the batch input must not be mutated.
+		input := append([]float64(nil), values...)
+		require.NoError(t, encoder.StartMetricID(1, len(values)))
+		require.NoError(t, encoder.AddDataPoint(timestamps[0], input[0], ""))
+		require.NoError(t, encoder.AddDataPoints(timestamps[1:], input[1:], nil))
+		require.NoError(t, encoder.EndMetric())
+		require.Equal(t, values[1], input[1])
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	collect := func(blob NumericBlob) []float64 {
+		out := make([]float64, 0, len(values))
+		for v := range blob.AllValues(1) {
+			out = append(out, v)
+		}
+
+		return out
+	}
+
+	t.Run("Precision", func(t *testing.T) {
+		for _, opt := range []NumericEncoderOption{WithLittleEndian(), WithBigEndian()} {
+			blob := encode(t, WithValuePrecision(2), opt)
+
+			decimals, ok := blob.ValuePrecision()
+			require.True(t, ok)
+			require.Equal(t, 2, decimals)
+			_, ok = blob.QuantizationStep()
+			require.False(t, ok)
+
+			got := collect(blob)
+			require.Equal(t, []float64{1.23, -7.89, 100.01}, got[:3])
+			require.True(t, math.IsNaN(got[3]))
+			require.True(t, math.IsInf(got[4], 1))
+		}
+	})
+
+	t.Run("QuantizationStep", func(t *testing.T) {
+		for _, opt := range []NumericEncoderOption{WithLittleEndian(), WithBigEndian()} {
+			blob := encode(t, WithQuantization(0.25), opt, WithValueEncoding(format.TypeChimp))
+
+			step, ok := blob.QuantizationStep()
+			require.True(t, ok)
+			require.Equal(t, 0.25, step)
+			_, ok = blob.ValuePrecision()
+			require.False(t, ok)
+
+			got := collect(blob)
+			require.Equal(t, []float64{1.25, -8, 100}, got[:3])
+		}
+	})
+
+	t.Run("LastOptionWins", func(t *testing.T) {
+		blob := encode(t, WithQuantization(0.5), WithValuePrecision(1))
+
+		decimals, ok := blob.ValuePrecision()
+		require.True(t, ok)
+		require.Equal(t, 1, decimals)
+		_, ok = blob.QuantizationStep()
+		require.False(t, ok)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		blob := encode(t)
+
+		_, ok := blob.ValuePrecision()
+		require.False(t, ok)
+		_, ok = blob.QuantizationStep()
+		require.False(t, ok)
+		require.Equal(t, values[:3], collect(blob)[:3])
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		for _, opt := range []NumericEncoderOption{
+			WithValuePrecision(-1),
+			WithValuePrecision(MaxValuePrecision + 1),
+			WithQuantization(0),
+			WithQuantization(-0.5),
+			WithQuantization(math.NaN()),
+			WithQuantization(math.Inf(1)),
+		} {
+			_, err := NewNumericEncoder(startTime, opt)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("WithMetricNames", func(t *testing.T) {
+		encoder, err := NewNumericEncoder(startTime, WithValuePrecision(1))
+		require.NoError(t, err)
+
+		// Force the metric names payload so it precedes the metadata section
+		encoder.header.Flag.SetHasMetricNames(true)
+		for _, name := range []string{"cpu.usage", "mem.usage"} {
+			require.NoError(t, encoder.StartMetricName(name, 1))
+			require.NoError(t, encoder.AddDataPoint(timestamps[0], 3.14159, ""))
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"cpu.usage", "mem.usage"}, blob.MetricNames())
+
+		v, ok := blob.ValueAtByName("mem.usage", 0)
+		require.True(t, ok)
+		require.Equal(t, 3.1, v)
+	})
+}
+
+func TestNumericEncoder_MetricReferences(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+	const count = 64
+
+	timestamps := make([]int64, count)
+	base := make([]float64, count)
+	channel := make([]float64, count)
+	for i := range count {
+		timestamps[i] = startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+		base[i] = 20 + math.Sin(float64(i)/8)
+		channel[i] = base[i] + 0.001*float64(i%3)
+	}
+	channel[5] = math.NaN()
+	channel[6] = math.Inf(-1)
+
+	encode := func(t *testing.T, useRefs bool, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, append([]NumericEncoderOption{WithMetricReferences(), WithTagsEnabled(true)}, opts...)...)
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricID(100, count))
+		require.NoError(t, encoder.AddDataPoints(timestamps, base, nil))
+		require.NoError(t, encoder.EndMetric())
+
+		if useRefs {
+			require.NoError(t, encoder.StartMetricIDWithReference(50, 100, count))
+		} else {
+			require.NoError(t, encoder.StartMetricID(50, count))
+		}
+		require.NoError(t, encoder.AddDataPoint(timestamps[0], channel[0], "first"))
+		require.NoError(t, encoder.AddDataPoints(timestamps[1:], channel[1:], nil))
+		require.NoError(t, encoder.EndMetric())
+
+		// A reference chain: 200 -> 50 -> 100
+		if useRefs {
+			require.NoError(t, encoder.StartMetricIDWithReference(200, 50, count))
+		} else {
+			require.NoError(t, encoder.StartMetricID(200, count))
+		}
+		require.NoError(t, encoder.AddDataPoints(timestamps, channel, nil))
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	requireValues := func(t *testing.T, expected, got []float64) {
+		t.Helper()
+		require.Len(t, got, len(expected))
+		for i := range expected {
+			require.Equal(t, math.Float64bits(expected[i]), math.Float64bits(got[i]), "index %d", i)
+		}
+	}
+
+	for _, opts := range [][]NumericEncoderOption{
+		{WithValueEncoding(format.TypeGorilla)},
+		{WithValueEncoding(format.TypeChimp), WithBlobLayoutV2()},
+		{WithValueEncoding(format.TypeRaw), WithBigEndian()},
+	} {
+		data := encode(t, true, opts...)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		requireValues(t, base, slices.Collect(blob.AllValues(100)))
+		for _, id := range []uint64{50, 200} {
+			requireValues(t, channel, slices.Collect(blob.AllValues(id)))
+
+			points := make([]float64, 0, count)
+			for i, dp := range blob.All(id) {
+				require.Equal(t, timestamps[i], dp.Ts)
+				points = append(points, dp.Val)
+			}
+			requireValues(t, channel, points)
+
+			points = points[:0]
+			blob.ForEachValues(id, func(_ int, v float64) bool {
+				points = append(points, v)
+				return true
+			})
+			requireValues(t, channel, points)
+
+			v, ok := blob.ValueAt(id, 7)
+			require.True(t, ok)
+			require.Equal(t, channel[7], v)
+
+			metric, ok := blob.MaterializeMetric(id)
+			require.True(t, ok)
+			requireValues(t, channel, metric.Values)
+		}
+
+		tag, ok := blob.TagAt(50, 0)
+		require.True(t, ok)
+		require.Equal(t, "first", tag)
+	}
+
+	t.Run("SmallerPayload", func(t *testing.T) {
+		require.Less(t, len(encode(t, true)), len(encode(t, false)))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		encoder, err := NewNumericEncoder(startTime)
+		require.NoError(t, err)
+		require.ErrorIs(t, encoder.StartMetricIDWithReference(2, 1, 1), errs.ErrInvalidMetricReference)
+
+		encoder, err = NewNumericEncoder(startTime, WithMetricReferences())
+		require.NoError(t, err)
+		require.ErrorIs(t, encoder.StartMetricIDWithReference(2, 1, 1), errs.ErrInvalidMetricReference)
+
+		require.NoError(t, encoder.StartMetricID(1, 2))
+		require.NoError(t, encoder.AddDataPoints(timestamps[:2], base[:2], nil))
+		require.NoError(t, encoder.EndMetric())
+		require.ErrorIs(t, encoder.StartMetricIDWithReference(2, 1, 3), errs.ErrInvalidMetricReference)
+		require.ErrorIs(t, encoder.StartMetricIDWithReference(1, 1, 2), errs.ErrHashCollision)
+	})
+}
+
+func TestNumericEncoder_GorillaTuning(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	// A single spike followed by small fluctuations keeps the default encoder on a wide window.
+	values := make([]float64, 2000)
+	for i := range values {
+		values[i] = 20 + float64(i%7)*0.5
+	}
+	values[1] = 1e9
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		opts = append([]NumericEncoderOption{WithValueEncoding(format.TypeGorilla), WithValueCompression(format.CompressionNone)}, opts...)
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+
+		for id := uint64(1); id <= 2; id++ {
+			require.NoError(t, encoder.StartMetricID(id, len(values)))
+			for i, v := range values {
+				require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), v, ""))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		for id := uint64(1); id <= 2; id++ {
+			require.Equal(t, values, slices.Collect(blob.AllValues(id)))
+		}
+
+		return data
+	}
+
+	defaultData := encode(t)
+	wasteData := encode(t, WithGorillaWindowReuse(12))
+	rebaseData := encode(t, WithGorillaRebaseline(256))
+	require.Less(t, len(wasteData), len(defaultData))
+	require.Less(t, len(rebaseData), len(defaultData))
+	require.Equal(t, defaultData, encode(t, WithGorillaWindowReuse(MaxGorillaWindowWaste), WithGorillaRebaseline(0)))
+
+	_, err := NewNumericEncoder(startTime, WithGorillaWindowReuse(-1))
+	require.Error(t, err)
+	_, err = NewNumericEncoder(startTime, WithGorillaWindowReuse(MaxGorillaWindowWaste+1))
+	require.Error(t, err)
+	_, err = NewNumericEncoder(startTime, WithGorillaRebaseline(-1))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_TimestampUnit(t *testing.T) {
+	startTime := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	encode := func(t *testing.T, unit format.TimeUnit, timestamps []int64) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, WithTimestampUnit(unit))
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricID(1, len(timestamps)))
+		for i, ts := range timestamps {
+			require.NoError(t, encoder.AddDataPoint(ts, float64(i), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	decode := func(t *testing.T, data []byte) NumericBlob {
+		t.Helper()
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	t.Run("Nanosecond", func(t *testing.T) {
+		timestamps := []int64{startTime.UnixNano() + 1, startTime.UnixNano() + 1_500, startTime.UnixNano() + 2_000_003}
+		blob := decode(t, encode(t, format.TimeUnitNanosecond, timestamps))
+
+		require.Equal(t, format.TimeUnitNanosecond, blob.TimestampUnit())
+		require.Equal(t, timestamps, slices.Collect(blob.AllTimestamps(1)))
+
+		ts, ok := blob.TimestampAt(1, 1)
+		require.True(t, ok)
+		require.Equal(t, startTime.Add(1500*time.Nanosecond), blob.TimestampUnit().Time(ts))
+	})
+
+	t.Run("Second", func(t *testing.T) {
+		timestamps := []int64{startTime.Unix(), startTime.Unix() + 3600}
+		data := encode(t, format.TimeUnitSecond, timestamps)
+		blob := decode(t, data)
+
+		require.Equal(t, format.TimeUnitSecond, blob.TimestampUnit())
+		require.Equal(t, timestamps, slices.Collect(blob.AllTimestamps(1)))
+		require.Equal(t, startTime, blob.StartTime())
+
+		// Metadata follows the header: count(2) + key(2) + length(4) + unit(1).
+		corrupt := slices.Clone(data)
+		require.Equal(t, byte(format.TimeUnitSecond), corrupt[section.HeaderSize+8])
+		corrupt[section.HeaderSize+8] = 0x7F
+		decoder, err := NewNumericDecoder(corrupt)
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+	})
+
+	t.Run("DefaultMicrosecond", func(t *testing.T) {
+		data := encode(t, format.TimeUnitMicrosecond, []int64{startTime.UnixMicro()})
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		require.False(t, header.Flag.HasMetadata())
+		require.Equal(t, format.TimeUnitMicrosecond, decode(t, data).TimestampUnit())
+	})
+
+	_, err := NewNumericEncoder(startTime, WithTimestampUnit(format.TimeUnit(9)))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_TagCompression(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricID(1, 50))
+		for i := range 50 {
+			ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(i), fmt.Sprintf("host=%d", i%3)))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		comp format.CompressionType
+	}{
+		{name: "None", comp: format.CompressionNone},
+		{name: "Zstd", comp: format.CompressionZstd},
+		{name: "S2", comp: format.CompressionS2},
+		{name: "LZ4", comp: format.CompressionLZ4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encode(t, WithTagsEnabled(true), WithTagCompression(tt.comp), WithValueCompression(format.CompressionNone))
+
+			header, err := section.ParseNumericHeader(data)
+			require.NoError(t, err)
+			// Only a non-default tag compression adds a metadata record.
+			require.Equal(t, tt.comp != format.CompressionZstd, header.Flag.HasMetadata())
+			if tt.comp == format.CompressionNone {
+				require.Contains(t, string(data[header.TagPayloadOffset:]), "host=2")
+			}
+
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			tags := slices.Collect(blob.AllTags(1))
+			require.Len(t, tags, 50)
+			require.Equal(t, "host=2", tags[47])
+
+			partialDecoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			_, report, err := partialDecoder.DecodePartial()
+			require.NoError(t, err)
+			require.True(t, report.IsComplete())
+		})
+	}
+
+	t.Run("TagsDisabled", func(t *testing.T) {
+		data := encode(t, WithTagCompression(format.CompressionLZ4))
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		require.False(t, header.Flag.HasMetadata())
+	})
+
+	_, err := NewNumericEncoder(startTime, WithTagCompression(format.CompressionType(9)))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_WordAlignedOffsets(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+
+		// Odd point counts keep per-metric sections off the 8-byte boundary.
+		for m, count := range []int{3, 7, 1, 12} {
+			require.NoError(t, encoder.StartMetricID(uint64(m+1), count))
+			for i := range count {
+				ts := startTime.Add(time.Duration(i*(m+1)) * time.Second).UnixMicro()
+				require.NoError(t, encoder.AddDataPoint(ts, float64(m)*10+float64(i)*0.25, fmt.Sprintf("t%d", i%3)))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	layouts := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1", opts: nil},
+		{name: "V2", opts: []NumericEncoderOption{WithBlobLayoutV2()}},
+		{name: "BigEndian", opts: []NumericEncoderOption{WithBigEndian()}},
+	}
+	tsEncodings := []format.EncodingType{format.TypeRaw, format.TypeDelta, format.TypeDeltaPacked}
+	valEncodings := []format.EncodingType{format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP}
+
+	for _, layout := range layouts {
+		for _, tsEnc := range tsEncodings {
+			for _, valEnc := range valEncodings {
+				for _, tags := range []bool{false, true} {
+					name := fmt.Sprintf("%s/%s/%s/tags=%v", layout.name, tsEnc, valEnc, tags)
+					t.Run(name, func(t *testing.T) {
+						opts := append(slices.Clone(layout.opts),
+							WithTimestampEncoding(tsEnc),
+							WithValueEncoding(valEnc),
+							WithTagsEnabled(tags),
+						)
+						plain, err := NewNumericDecoder(encode(t, opts...))
+						require.NoError(t, err)
+						want, err := plain.Decode()
+						require.NoError(t, err)
+
+						aligned, err := NewNumericDecoder(encode(t, append(opts, WithWordAlignedOffsets())...))
+						require.NoError(t, err)
+						got, err := aligned.Decode()
+						require.NoError(t, err)
+						require.Equal(t, section.NumericOffsetWordSize, aligned.offsetUnit)
+
+						for id := uint64(1); id <= 4; id++ {
+							require.Equal(t, collectDataPoints(want, id), collectDataPoints(got, id))
+							count := want.Len(id)
+							for _, idx := range []int{0, count - 1} {
+								wantTs, _ := want.TimestampAt(id, idx)
+								gotTs, ok := got.TimestampAt(id, idx)
+								require.True(t, ok)
+								require.Equal(t, wantTs, gotTs)
+
+								wantVal, _ := want.ValueAt(id, idx)
+								gotVal, ok := got.ValueAt(id, idx)
+								require.True(t, ok)
+								require.Equal(t, wantVal, gotVal)
+
+								wantTag, _ := want.TagAt(id, idx)
+								gotTag, _ := got.TagAt(id, idx)
+								require.Equal(t, wantTag, gotTag)
+							}
+						}
+					})
+				}
+			}
+		}
+	}
+
+	t.Run("ExtendsV1Range", func(t *testing.T) {
+		// 10000 raw points need 80000 bytes per column, beyond the uint16 byte range.
+		const count = 10000
+		rawOpts := []NumericEncoderOption{WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)}
+
+		encoder, err := NewNumericEncoder(startTime, rawOpts...)
+		require.NoError(t, err)
+		require.ErrorIs(t, encoder.StartMetricID(1, count), errs.ErrInvalidNumOfDataPoints)
+
+		encoder, err = NewNumericEncoder(startTime, append(rawOpts, WithWordAlignedOffsets())...)
+		require.NoError(t, err)
+		require.Equal(t, section.NumericMaxOffset*section.NumericOffsetWordSize/8, encoder.MaxDataPoints())
+		for m := range 2 {
+			require.NoError(t, encoder.StartMetricID(uint64(m+1), count))
+			for i := range count {
+				require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), ""))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		require.Equal(t, count, blob.Len(2))
+		val, ok := blob.ValueAt(2, count-1)
+		require.True(t, ok)
+		require.Equal(t, float64(count-1), val)
+	})
+
+	t.Run("InvalidUnitRecord", func(t *testing.T) {
+		data := encode(t, WithWordAlignedOffsets())
+
+		// Metadata follows the header: count(2) + key(2) + length(4) + unit(1).
+		corrupt := slices.Clone(data)
+		require.Equal(t, byte(section.NumericOffsetWordSize), corrupt[section.HeaderSize+8])
+		corrupt[section.HeaderSize+8] = 3
+		decoder, err := NewNumericDecoder(corrupt)
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+	})
+
+	_, err := NewNumericEncoder(startTime, WithWordAlignedOffsets(), WithSharedTimestamps())
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestNumericEncoder_SkipsIneffectiveCompression(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+	encode := func(t *testing.T, count int) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime,
+			WithTagsEnabled(true),
+			WithTimestampCompression(format.CompressionZstd),
+			WithValueCompression(format.CompressionS2),
+			WithTagCompression(format.CompressionLZ4),
+		)
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(1, count))
+		for i := range count {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), 42, "host=a"))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	decode := func(t *testing.T, data []byte) NumericBlob {
+		t.Helper()
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	t.Run("TinyPayloadsStoredRaw", func(t *testing.T) {
+		data := encode(t, 1)
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		require.Equal(t, format.CompressionNone, header.Flag.TimestampCompression())
+		require.Equal(t, format.CompressionNone, header.Flag.ValueCompression())
+
+		blob := decode(t, data)
+		require.Equal(t, format.CompressionNone, tagCompression(blob.metadata))
+		require.Equal(t, []NumericDataPoint{{Ts: startTime.UnixMicro(), Val: 42, Tag: "host=a"}}, collectDataPoints(blob, 1))
+	})
+
+	t.Run("CompressiblePayloadsKeepCodec", func(t *testing.T) {
+		data := encode(t, 1000)
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		require.Equal(t, format.CompressionZstd, header.Flag.TimestampCompression())
+		require.Equal(t, format.CompressionS2, header.Flag.ValueCompression())
+
+		blob := decode(t, data)
+		require.Equal(t, format.CompressionLZ4, tagCompression(blob.metadata))
+		tag, ok := blob.TagAt(1, 999)
+		require.True(t, ok)
+		require.Equal(t, "host=a", tag)
+	})
+}
+
+func TestNumericEncoder_MaxEncoderMemory(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+
+		// Descending IDs make V2 reorder metrics across spilled sections.
+		for m := range 20 {
+			count := 5 + m%7
+			require.NoError(t, encoder.StartMetricID(uint64(100-m), count))
+			for i := range count {
+				ts := startTime.Add(time.Duration(i*(m+1)) * time.Second).UnixMicro()
+				tag := ""
+				if m%3 == 0 {
+					tag = fmt.Sprintf("t%d", i)
+				}
+				require.NoError(t, encoder.AddDataPoint(ts, float64(m)*1.5+float64(i)*0.1, tag))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "V2", opts: []NumericEncoderOption{WithTagsEnabled(true), WithBlobLayoutV2()}},
+		{name: "SharedTimestamps", opts: []NumericEncoderOption{WithSharedTimestamps()}},
+		{name: "WordAligned", opts: []NumericEncoderOption{WithTagsEnabled(true), WithWordAlignedOffsets()}},
+		{name: "Encodings", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithTimestampEncoding(format.TypeDeltaPacked),
+			WithValueEncoding(format.TypeALP),
+		}},
+		{name: "Raw", opts: []NumericEncoderOption{
+			WithTimestampEncoding(format.TypeRaw),
+			WithValueEncoding(format.TypeChimp),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := encode(t, tt.opts...)
+
+			for _, maxBytes := range []int{1, 200} {
+				dir := t.TempDir()
+				got := encode(t, append(slices.Clone(tt.opts), WithMaxEncoderMemory(maxBytes, dir))...)
+				require.Equal(t, want, got, "maxBytes=%d", maxBytes)
+
+				files, err := os.ReadDir(dir)
+				require.NoError(t, err)
+				require.Empty(t, files)
+			}
+		})
+	}
+
+	t.Run("SpillsBetweenMetrics", func(t *testing.T) {
+		dir := t.TempDir()
+		encoder, err := NewNumericEncoder(startTime, WithMaxEncoderMemory(1, dir))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(1, 1))
+		require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1, ""))
+		require.NoError(t, encoder.EndMetric())
+		require.NotNil(t, encoder.spill)
+		require.Zero(t, encoder.tsEncoder.Size())
+
+		files, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		// Finish removes spill files on error paths too
+		require.NoError(t, encoder.StartMetricID(2, 1))
+		_, err = encoder.Finish()
+		require.ErrorIs(t, err, errs.ErrMetricNotEnded)
+		files, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	_, err := NewNumericEncoder(startTime, WithMaxEncoderMemory(0, ""))
+	require.Error(t, err)
+	_, err = NewNumericEncoder(startTime, WithMaxEncoderMemory(1024, filepath.Join(t.TempDir(), "missing")))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_DeterministicOutput(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+
+	encode := func(t *testing.T, dst []byte, seed int, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, append(opts, WithDeterministicOutput(true))...)
+		require.NoError(t, err)
+
+		for m := range 50 {
+			name := fmt.Sprintf("metric.%d", (m*37+seed)%101)
+			require.NoError(t, encoder.StartMetricName(name, 20))
+			for i := range 20 {
+				ts := startTime.Add(time.Duration(i*(m%4+1)) * time.Second).UnixMicro()
+				require.NoError(t, encoder.AddDataPoint(ts, math.Sin(float64(m*20+i+seed)), fmt.Sprintf("host-%d", i%5)))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+		// Store metric names, as after a hash collision.
+		encoder.hasCollision = true
+
+		data, err := encoder.FinishInto(dst)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "V1Zstd", opts: []NumericEncoderOption{WithTagsEnabled(true), WithValueCompression(format.CompressionZstd)}},
+		{name: "V2S2", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithBlobLayoutV2(),
+			WithTimestampCompression(format.CompressionS2),
+			WithTagCompression(format.CompressionLZ4),
+		}},
+		{name: "SharedTimestamps", opts: []NumericEncoderOption{WithSharedTimestamps(), WithValueEncoding(format.TypeALP)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := encode(t, nil, 0, tt.opts...)
+
+			// Unrelated encodes dirty the pooled buffers and compressors in between,
+			// and a dirty destination buffer must not leak into the blob.
+			encode(t, nil, 1, tt.opts...)
+			dirty := bytes.Repeat([]byte{0xFF}, 2*len(want))
+			got := encode(t, dirty[:0], 0, tt.opts...)
+			require.Equal(t, want, got)
+		})
+	}
+
+	// Zstd sections use the fixed configuration
+	encoder, err := NewNumericEncoder(startTime, WithDeterministicOutput(true))
+	require.NoError(t, err)
+	require.Equal(t, compress.NewDeterministicZstdCompressor(), encoder.tagCodec)
+}
+
+func TestNumericEncoder_SortedIndex(t *testing.T) {
+	startTime := time.Unix(1700000000, 0)
+	ids := []uint64{42, 7, 1000, 3, 99}
+
+	encode := func(t *testing.T, opts ...NumericEncoderOption) []byte {
+		t.Helper()
+		encoder, err := NewNumericEncoder(startTime, append(opts, WithTagsEnabled(true))...)
+		require.NoError(t, err)
+		for m, id := range ids {
+			count := 3 + m
+			require.NoError(t, encoder.StartMetricID(id, count))
+			for i := range count {
+				ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+				require.NoError(t, encoder.AddDataPoint(ts, float64(id)+float64(i)/10, fmt.Sprintf("id=%d", id)))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	decode := func(t *testing.T, data []byte) NumericBlob {
+		t.Helper()
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	t.Run("V1", func(t *testing.T) {
+		unsorted := encode(t)
+		sorted := encode(t, WithSortedIndex(true))
+
+		header, err := section.ParseNumericHeader(sorted)
+		require.NoError(t, err)
+		require.Equal(t, uint16(section.MagicNumericV1Opt), header.Flag.GetMagicNumber())
+		require.True(t, header.Flag.IsIndexSorted())
+
+		// Raw index entries are in MetricID order
+		var rawIDs []uint64
+		for i := range len(ids) {
+			entry, err := section.ParseNumericIndexEntry(sorted[section.HeaderSize+i*section.NumericIndexEntrySize:], header.Flag.GetEndianEngine())
+			require.NoError(t, err)
+			rawIDs = append(rawIDs, entry.MetricID)
+		}
+		require.Equal(t, []uint64{3, 7, 42, 99, 1000}, rawIDs)
+
+		want, got := decode(t, unsorted), decode(t, sorted)
+		require.Nil(t, got.index.byID)
+		require.Equal(t, rawIDs, got.MetricIDs())
+		for _, id := range ids {
+			require.Equal(t, collectDataPoints(want, id), collectDataPoints(got, id))
+		}
+		require.False(t, got.HasMetricID(8))
+	})
+
+	t.Run("V2Unchanged", func(t *testing.T) {
+		plain := encode(t, WithBlobLayoutV2())
+		require.Equal(t, plain, encode(t, WithBlobLayoutV2(), WithSortedIndex(true)))
+	})
+
+	t.Run("UnsortedEntriesRejected", func(t *testing.T) {
+		data := encode(t)
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+		header.Flag.SetIndexSorted(true)
+		copy(data, header.Bytes())
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.ErrorIs(t, err, errs.ErrInvalidIndexOffsets)
+	})
+
+	t.Run("MovedLastMetricOverflows", func(t *testing.T) {
+		// The last metric's tag section exceeds uint16 but needs no delta until sorting moves it first.
+		encoder, err := NewNumericEncoder(startTime, WithTagsEnabled(true), WithSortedIndex(true))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(2, 1))
+		require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1, "small"))
+		require.NoError(t, encoder.EndMetric())
+
+		longTag := strings.Repeat("x", 250)
+		require.NoError(t, encoder.StartMetricID(1, 300))
+		for i := range 300 {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), 1, longTag))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		_, err = encoder.Finish()
+		require.ErrorIs(t, err, errs.ErrOffsetOutOfRange)
+	})
+}
+
+func TestNumericEncoder_TagPresenceBitmap(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "DeltaGorilla", opts: nil},
+		{name: "RawRaw", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw)}},
+		{name: "DeltaPackedChimp", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeDeltaPacked), WithValueEncoding(format.TypeChimp)}},
+		{name: "ALPSortedAligned", opts: []NumericEncoderOption{WithValueEncoding(format.TypeALP), WithSortedIndex(true), WithWordAlignedOffsets()}},
+	}
+
+	const numPoints = 50
+	tagOf := func(id uint64, i int) string {
+		if i%10 != 3 {
+			return ""
+		}
+
+		return fmt.Sprintf("err-%d-%d", id, i)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startTime := time.Now()
+			encoder, err := NewNumericEncoder(startTime, append([]NumericEncoderOption{WithTagsEnabled(true)}, tt.opts...)...)
+			require.NoError(t, err)
+
+			for id := uint64(3); id >= 1; id-- {
+				require.NoError(t, encoder.StartMetricID(id, numPoints))
+				for i := range numPoints {
+					ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+					require.NoError(t, encoder.AddDataPoint(ts, float64(i), tagOf(id, i)))
+				}
+				require.NoError(t, encoder.EndMetric())
+			}
+
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+			require.True(t, blob.tagBitmap)
+
+			material := blob.Materialize()
+			for id := uint64(1); id <= 3; id++ {
+				want := make([]string, numPoints)
+				for i := range want {
+					want[i] = tagOf(id, i)
+					tag, ok := blob.TagAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, want[i], tag)
+					tag, ok = material.TagAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, want[i], tag)
+				}
+				require.Equal(t, want, slices.Collect(blob.AllTags(id)))
+
+				var allTags, eachTags []string
+				for _, dp := range blob.All(id) {
+					allTags = append(allTags, dp.Tag)
+				}
+				blob.ForEach(id, func(_ int, dp NumericDataPoint) bool {
+					eachTags = append(eachTags, dp.Tag)
+					return true
+				})
+				require.Equal(t, want, allTags)
+				require.Equal(t, want, eachTags)
+			}
+		})
+	}
+}
+
+func TestNumericEncoder_TagPresenceBitmap_Dense(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, WithTagsEnabled(true))
+	require.NoError(t, err)
+
+	// Only one in eight tags is empty: the bitmap would not pay for itself
+	require.NoError(t, encoder.StartMetricID(1, 16))
+	for i := range 16 {
+		tag := "host=a"
+		if i%8 == 0 {
+			tag = ""
+		}
+		require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), 1, tag))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.False(t, blob.tagBitmap)
+
+	tag, ok := blob.TagAt(1, 1)
+	require.True(t, ok)
+	require.Equal(t, "host=a", tag)
+}
+
+func TestNumericEncoder_StartMetricIDHint(t *testing.T) {
+	for _, v2 := range []bool{false, true} {
+		opts := []NumericEncoderOption{WithTagsEnabled(true)}
+		if v2 {
+			opts = append(opts, WithBlobLayoutV2())
+		}
+		encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), opts...)
+		require.NoError(t, err)
+
+		// The hint neither caps nor fixes the number of data points.
+		require.NoError(t, encoder.StartMetricIDHint(1, 2))
+		for i := range 5 {
+			require.NoError(t, encoder.AddDataPoint(int64(i), float64(i), "t"))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		require.NoError(t, encoder.StartMetricIDHint(2, 0))
+		require.NoError(t, encoder.AddDataPoints([]int64{1}, []float64{7}, nil))
+		require.NoError(t, encoder.EndMetric())
+
+		// Exact claims are still enforced for other metrics.
+		require.NoError(t, encoder.StartMetricID(3, 2))
+		require.NoError(t, encoder.AddDataPoint(1, 1, ""))
+		require.ErrorIs(t, encoder.EndMetric(), errs.ErrDataPointCountMismatch)
+		require.NoError(t, encoder.AddDataPoint(2, 2, ""))
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		require.Equal(t, 5, blob.Len(1))
+		require.Equal(t, []float64{7}, slices.Collect(blob.AllValues(2)))
+		tag, ok := blob.TagAt(1, 4)
+		require.True(t, ok)
+		require.Equal(t, "t", tag)
+	}
+}
+
+func TestNumericEncoder_StartMetricHint_Errors(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, encoder.StartMetricIDHint(1, -1), errs.ErrInvalidNumOfDataPoints)
+	require.ErrorIs(t, encoder.StartMetricIDHint(1, encoder.MaxDataPoints()+1), errs.ErrInvalidNumOfDataPoints)
+
+	require.NoError(t, encoder.StartMetricIDHint(1, 4))
+	require.ErrorIs(t, encoder.EndMetric(), errs.ErrNoDataPointsAdded)
+
+	names, err := NewNumericEncoder(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	require.NoError(t, names.StartMetricNameHint("cpu.usage", 0))
+	require.NoError(t, names.AddDataPoints([]int64{1, 2, 3}, []float64{1, 2, 3}, nil))
+	require.NoError(t, names.EndMetric())
+	require.ErrorIs(t, names.StartMetricNameHint("mem.usage", -1), errs.ErrInvalidNumOfDataPoints)
+}
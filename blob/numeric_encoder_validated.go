@@ -0,0 +1,229 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// ValidationPolicy configures which data points NumericEncoder.AddDataPointsValidated
+// rejects (see WithValidationPolicy).
+//
+// The zero value rejects NaN values and timestamps that do not increase, and
+// accepts any timestamp relative to the blob start time.
+type ValidationPolicy struct {
+	// AllowNaN accepts NaN values instead of rejecting them.
+	AllowNaN bool
+
+	// AllowUnordered accepts timestamps that are equal to or older than the
+	// last accepted timestamp of the metric instead of rejecting them.
+	AllowUnordered bool
+
+	// Window, if positive, rejects timestamps outside [start, start+Window),
+	// where start is the blob start time.
+	Window time.Duration
+}
+
+// RejectReason describes why AddDataPointsValidated rejected a data point.
+type RejectReason uint8
+
+const (
+	// RejectNaN marks a NaN value.
+	RejectNaN RejectReason = iota + 1
+	// RejectNonMonotonic marks a timestamp not after the last accepted timestamp of the metric.
+	RejectNonMonotonic
+	// RejectOutOfWindow marks a timestamp outside the window of the validation policy
+	// or the window set by WithTimeWindow.
+	RejectOutOfWindow
+)
+
+// String returns the name of the reason.
+func (r RejectReason) String() string {
+	switch r {
+	case RejectNaN:
+		return "NaN"
+	case RejectNonMonotonic:
+		return "NonMonotonic"
+	case RejectOutOfWindow:
+		return "OutOfWindow"
+	default:
+		return "Unknown"
+	}
+}
+
+// RejectedPoint describes a data point rejected by AddDataPointsValidated.
+type RejectedPoint struct {
+	Index     int          // position of the data point in the input slices
+	Timestamp int64        // timestamp of the data point
+	Value     float64      // value of the data point
+	Reason    RejectReason // why the data point was rejected
+}
+
+// ValidationReport summarizes one AddDataPointsValidated call.
+type ValidationReport struct {
+	Accepted int             // number of data points added to the metric
+	Rejected []RejectedPoint // rejected data points in input order, nil if none
+}
+
+// AllAccepted reports whether no data point was rejected.
+func (r ValidationReport) AllAccepted() bool {
+	return len(r.Rejected) == 0
+}
+
+// AddDataPointsValidated adds the data points of the current metric that pass the
+// encoder's validation policy and reports the rejected ones, instead of failing
+// the whole batch because of a few bad samples.
+//
+// Rejected data points count against the number of data points claimed by
+// StartMetricID or StartMetricName: the claim shrinks by the number of rejected
+// points, so EndMetric succeeds once every claimed point was either added or
+// rejected. If all points of a metric are rejected, EndMetric returns
+// ErrNoDataPointsAdded. Metrics started with StartMetricIDWithReference need
+// every data point of their reference, so the call fails with
+// ErrInvalidMetricReference instead if it would reject any.
+//
+// With AllowNaN set, NaN values are still rejected under the NaNDrop policy and
+// fail the call under NaNError (see WithNaNPolicy).
+//
+// Timestamps are checked for monotonicity against the last timestamp accepted by
+// this method for the current metric; data points added by AddDataPoint or
+// AddDataPoints are not taken into account.
+//
+// Parameters:
+//   - timestamps: Slice of timestamps in the encoder's timestamp unit.
+//   - values: Slice of float64 metric values (must have the same length as timestamps).
+//   - tags: Optional slice of tag strings (if non-empty, must have the same length as timestamps).
+//
+// Returns:
+//   - ValidationReport: Number of accepted data points and the rejected ones
+//   - error: Length mismatch error, ErrNoMetricStarted, ErrTooManyDataPoints if the
+//     input exceeds the claimed data point count, or ErrInvalidMetricReference if
+//     data points of a reference-delta metric are rejected; no data point is
+//     added on error
+//
+// Example:
+//
+//	report, err := encoder.AddDataPointsValidated(timestamps, values, nil)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, p := range report.Rejected {
+//	    log.Printf("dropped point %d: %s", p.Index, p.Reason)
+//	}
+func (e *NumericEncoder) AddDataPointsValidated(timestamps []int64, values []float64, tags []string) (ValidationReport, error) {
+	tsLen := len(timestamps)
+	if tsLen != len(values) {
+		return ValidationReport{}, fmt.Errorf("mismatched lengths: %d timestamps, %d values", tsLen, len(values))
+	}
+	if len(tags) > 0 && len(tags) != tsLen {
+		return ValidationReport{}, fmt.Errorf("mismatched lengths: %d timestamps, %d tags", tsLen, len(tags))
+	}
+
+	if e.curMetricID == 0 {
+		return ValidationReport{}, errs.ErrNoMetricStarted
+	}
+
+	if e.curPoints+tsLen > e.claimed {
+		return ValidationReport{}, errs.ErrTooManyDataPoints
+	}
+
+	lo, hi := e.validationWindow()
+
+	var report ValidationReport
+	last, hasLast := e.lastValidTs, e.hasValidTs
+	for i, ts := range timestamps {
+		if reason := e.rejectReason(ts, values[i], last, hasLast, lo, hi); reason != 0 {
+			report.Rejected = append(report.Rejected, RejectedPoint{Index: i, Timestamp: ts, Value: values[i], Reason: reason})
+			continue
+		}
+
+		last, hasLast = ts, true
+	}
+
+	if len(report.Rejected) > 0 {
+		// Deltas are reconstructed against every reference value
+		if e.curRef != nil {
+			return ValidationReport{}, fmt.Errorf("%w: %d data points of metric ID 0x%016x rejected, %s",
+				errs.ErrInvalidMetricReference, len(report.Rejected), e.curMetricID, report.Rejected[0].Reason)
+		}
+		timestamps, values, tags = withoutRejected(timestamps, values, tags, report.Rejected)
+	}
+
+	if err := e.AddDataPoints(timestamps, values, tags); err != nil {
+		return ValidationReport{}, err
+	}
+	e.claimed -= len(report.Rejected)
+	e.lastValidTs, e.hasValidTs = last, hasLast
+	report.Accepted = len(timestamps)
+
+	return report, nil
+}
+
+// validationWindow returns the bounds [lo, hi) of accepted timestamps in the
+// encoder's timestamp unit, spanning all timestamps if no window is set. A
+// window set by WithTimeWindow narrows the bounds unless its points are clamped.
+func (e *NumericEncoder) validationWindow() (lo, hi int64) {
+	lo, hi = math.MinInt64, math.MaxInt64
+	if e.validation.Window > 0 {
+		start := e.header.StartTimeAsTime()
+		lo, hi = e.tsUnit.Timestamp(start), e.tsUnit.Timestamp(start.Add(e.validation.Window))
+	}
+
+	if e.window && e.windowPolicy != TimeWindowClamp {
+		lo, hi = max(lo, e.winLo), min(hi, e.winHi)
+	}
+
+	return lo, hi
+}
+
+// rejectReason returns why the data point violates the validation policy, or 0
+// if it is accepted. last is the previously accepted timestamp if hasLast is set,
+// and [lo, hi) the window returned by validationWindow.
+func (e *NumericEncoder) rejectReason(ts int64, value float64, last int64, hasLast bool, lo, hi int64) RejectReason {
+	policy := e.validation
+
+	// Under NaNDrop, NaN values are reported rather than dropped silently.
+	if (!policy.AllowNaN || e.nanPolicy == NaNDrop) && math.IsNaN(value) {
+		return RejectNaN
+	}
+
+	if ts < lo || ts >= hi {
+		return RejectOutOfWindow
+	}
+
+	if !policy.AllowUnordered && hasLast && ts <= last {
+		return RejectNonMonotonic
+	}
+
+	return 0
+}
+
+// withoutRejected returns copies of the input slices without the rejected data
+// points. Tags stay nil if none were given.
+func withoutRejected(timestamps []int64, values []float64, tags []string, rejected []RejectedPoint) ([]int64, []float64, []string) {
+	n := len(timestamps) - len(rejected)
+	keptTs := make([]int64, 0, n)
+	keptVals := make([]float64, 0, n)
+	var keptTags []string
+	if len(tags) > 0 {
+		keptTags = make([]string, 0, n)
+	}
+
+	next := 0
+	for i := range timestamps {
+		if next < len(rejected) && rejected[next].Index == i {
+			next++
+			continue
+		}
+
+		keptTs = append(keptTs, timestamps[i])
+		keptVals = append(keptVals, values[i])
+		if keptTags != nil {
+			keptTags = append(keptTags, tags[i])
+		}
+	}
+
+	return keptTs, keptVals, keptTags
+}
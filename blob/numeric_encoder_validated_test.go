@@ -0,0 +1,124 @@
+package blob
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func TestNumericEncoder_AddDataPointsValidated(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	base := start.UnixMicro()
+
+	encoder, err := NewNumericEncoder(start,
+		WithTagsEnabled(true),
+		WithValidationPolicy(ValidationPolicy{Window: time.Minute}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 8))
+
+	report, err := encoder.AddDataPointsValidated(
+		[]int64{base, base + 1, base + 1, base + 2, base - 1},
+		[]float64{1, 2, 3, math.NaN(), 5},
+		[]string{"a", "b", "c", "d", "e"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Accepted)
+	require.False(t, report.AllAccepted())
+	require.Equal(t, []RejectedPoint{
+		{Index: 2, Timestamp: base + 1, Value: 3, Reason: RejectNonMonotonic},
+		{Index: 4, Timestamp: base - 1, Value: 5, Reason: RejectOutOfWindow},
+	}, []RejectedPoint{report.Rejected[0], report.Rejected[2]})
+	require.Equal(t, RejectNaN, report.Rejected[1].Reason)
+	require.Equal(t, 3, report.Rejected[1].Index)
+
+	// Monotonicity carries over to the next call of the same metric.
+	report, err = encoder.AddDataPointsValidated(
+		[]int64{base + 1, base + 3, base + time.Minute.Microseconds()},
+		[]float64{6, 7, 8}, nil,
+	)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Accepted)
+	require.Equal(t, RejectNonMonotonic, report.Rejected[0].Reason)
+	require.Equal(t, RejectOutOfWindow, report.Rejected[1].Reason)
+
+	// Rejected points shrink the claim, so the metric ends with 3 of 8 points.
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, data)
+	require.Equal(t, []float64{1, 2, 7}, slices.Collect(blob.AllValues(1)))
+	tag, ok := blob.TagAt(1, 1)
+	require.True(t, ok)
+	require.Equal(t, "b", tag)
+}
+
+func TestNumericEncoder_AddDataPointsValidated_Reference(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithMetricReferences())
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{1, 2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	// Rejecting a point would leave the metric shorter than its reference
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+	_, err = encoder.AddDataPointsValidated([]int64{1, 2, 3}, []float64{1.5, math.NaN(), 3.5}, nil)
+	require.ErrorIs(t, err, errs.ErrInvalidMetricReference)
+
+	report, err := encoder.AddDataPointsValidated([]int64{1, 2, 3}, []float64{1.5, 2.5, 3.5}, nil)
+	require.NoError(t, err)
+	require.True(t, report.AllAccepted())
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	blob := decodeRemapTestBlob(t, data)
+	require.Equal(t, []float64{1.5, 2.5, 3.5}, slices.Collect(blob.AllValues(2)))
+}
+
+func TestNumericEncoder_AddDataPointsValidated_Policy(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0),
+		WithValidationPolicy(ValidationPolicy{AllowNaN: true, AllowUnordered: true}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+
+	report, err := encoder.AddDataPointsValidated([]int64{3, 2, 2}, []float64{1, math.NaN(), 3}, nil)
+	require.NoError(t, err)
+	require.True(t, report.AllAccepted())
+	require.Equal(t, 3, report.Accepted)
+	require.NoError(t, encoder.EndMetric())
+
+	_, err = NewNumericEncoder(time.Unix(1700000000, 0), WithValidationPolicy(ValidationPolicy{Window: -time.Second}))
+	require.Error(t, err)
+}
+
+func TestNumericEncoder_AddDataPointsValidated_Errors(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	_, err = encoder.AddDataPointsValidated([]int64{1}, []float64{1}, nil)
+	require.ErrorIs(t, err, errs.ErrNoMetricStarted)
+
+	require.NoError(t, encoder.StartMetricID(1, 2))
+
+	_, err = encoder.AddDataPointsValidated([]int64{1, 2}, []float64{1}, nil)
+	require.Error(t, err)
+
+	// The claim is checked against the whole input, including rejected points.
+	_, err = encoder.AddDataPointsValidated([]int64{1, 2, 3}, []float64{1, math.NaN(), 3}, nil)
+	require.ErrorIs(t, err, errs.ErrTooManyDataPoints)
+
+	// A metric whose points are all rejected cannot be ended.
+	report, err := encoder.AddDataPointsValidated([]int64{1, 2}, []float64{math.NaN(), math.NaN()}, nil)
+	require.NoError(t, err)
+	require.Zero(t, report.Accepted)
+	require.ErrorIs(t, encoder.EndMetric(), errs.ErrNoDataPointsAdded)
+}
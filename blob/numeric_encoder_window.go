@@ -0,0 +1,120 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// TimeWindowPolicy selects how NumericEncoder handles data points whose
+// timestamps fall outside the window set by WithTimeWindow.
+type TimeWindowPolicy uint8
+
+const (
+	// TimeWindowReject rejects data points outside the window with
+	// ErrTimestampOutOfWindow.
+	TimeWindowReject TimeWindowPolicy = iota
+	// TimeWindowDrop skips data points outside the window. Each dropped point
+	// reduces the number of data points claimed for the metric by one.
+	TimeWindowDrop
+	// TimeWindowClamp moves timestamps outside the window to its nearest edge:
+	// earlier timestamps to the window start, later ones to the last timestamp
+	// before the window end.
+	TimeWindowClamp
+)
+
+// String returns the name of the policy.
+func (p TimeWindowPolicy) String() string {
+	switch p {
+	case TimeWindowReject:
+		return "Reject"
+	case TimeWindowDrop:
+		return "Drop"
+	case TimeWindowClamp:
+		return "Clamp"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsValid reports whether p is a known policy.
+func (p TimeWindowPolicy) IsValid() bool {
+	return p <= TimeWindowClamp
+}
+
+// outOfWindow reports whether ts is outside the encoder's time window.
+func (e *NumericEncoder) outOfWindow(ts int64) bool {
+	return ts < e.winLo || ts >= e.winHi
+}
+
+// clampToWindow moves ts into the encoder's time window.
+func (e *NumericEncoder) clampToWindow(ts int64) int64 {
+	return min(max(ts, e.winLo), e.winHi-1)
+}
+
+// applyTimeWindow applies the time window policy to a batch of data points.
+//
+// It returns the input slices unchanged if every timestamp is inside the window.
+// Otherwise it returns ErrTimestampOutOfWindow under TimeWindowReject, copies of
+// the slices without the points outside the window and the number of dropped
+// points under TimeWindowDrop, or a copy of the timestamps moved into the window
+// under TimeWindowClamp.
+//
+// The rejected data point is reported at its position in input, the values of
+// the call before applyNaNPolicy dropped NaN data points.
+func (e *NumericEncoder) applyTimeWindow(timestamps []int64, values []float64, tags []string, input []float64) ([]int64, []float64, []string, int, error) {
+	first := -1
+	for i, ts := range timestamps {
+		if e.outOfWindow(ts) {
+			first = i
+			break
+		}
+	}
+
+	if first < 0 {
+		return timestamps, values, tags, 0, nil
+	}
+
+	switch e.windowPolicy {
+	case TimeWindowReject:
+		return nil, nil, nil, 0, e.windowError(inputIndex(input, len(values), first), timestamps[first])
+	case TimeWindowClamp:
+		clamped := make([]int64, len(timestamps))
+		for i, ts := range timestamps {
+			clamped[i] = e.clampToWindow(ts)
+		}
+
+		return clamped, values, tags, 0, nil
+	}
+
+	keptTs := make([]int64, first, len(timestamps))
+	keptVals := make([]float64, first, len(values))
+	copy(keptTs, timestamps)
+	copy(keptVals, values)
+
+	var keptTags []string
+	if len(tags) > 0 {
+		keptTags = make([]string, first, len(tags))
+		copy(keptTags, tags)
+	}
+
+	for i := first + 1; i < len(timestamps); i++ {
+		if e.outOfWindow(timestamps[i]) {
+			continue
+		}
+
+		keptTs = append(keptTs, timestamps[i])
+		keptVals = append(keptVals, values[i])
+		if keptTags != nil {
+			keptTags = append(keptTags, tags[i])
+		}
+	}
+
+	return keptTs, keptVals, keptTags, len(timestamps) - len(keptTs), nil
+}
+
+// windowError returns the error for the data point at position idx of a call.
+func (e *NumericEncoder) windowError(idx int, ts int64) error {
+	return fmt.Errorf("%w: data point %d of metric ID 0x%016x has timestamp %d, window is [%d, %d)",
+		errs.ErrTimestampOutOfWindow, idx, e.curMetricID, ts, e.winLo, e.winHi)
+}
@@ -0,0 +1,155 @@
+package blob
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericEncoder_TimeWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(time.Hour)
+	lo, hi := start.UnixMicro(), end.UnixMicro()
+
+	tests := []struct {
+		name   string
+		policy TimeWindowPolicy
+		want   []int64 // timestamps of metric 1, nil if encoding fails
+	}{
+		{name: "drop", policy: TimeWindowDrop, want: []int64{lo, lo + 1, hi - 1}},
+		{name: "clamp", policy: TimeWindowClamp, want: []int64{lo, lo, lo + 1, hi - 1, hi - 1, hi - 1}},
+		{name: "reject", policy: TimeWindowReject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewNumericEncoder(start, WithTagsEnabled(true), WithTimeWindow(start, end, tt.policy))
+			require.NoError(t, err)
+			require.NoError(t, encoder.StartMetricID(1, 6))
+
+			err = encoder.AddDataPoints([]int64{lo - 5, lo, lo + 1, hi}, []float64{1, 2, 3, 4}, []string{"a", "b", "c", "d"})
+			if tt.want == nil {
+				require.ErrorIs(t, err, errs.ErrTimestampOutOfWindow)
+				require.ErrorIs(t, encoder.AddDataPoint(hi+1e9, 5, ""), errs.ErrTimestampOutOfWindow)
+
+				// Rejected calls add nothing, so the full claim is still available.
+				require.NoError(t, encoder.AddDataPoints([]int64{lo, lo + 1, lo + 2, lo + 3, lo + 4}, []float64{1, 2, 3, 4, 5}, nil))
+				require.NoError(t, encoder.AddDataPoint(hi-1, 6, ""))
+				require.NoError(t, encoder.EndMetric())
+
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, encoder.AddDataPoint(hi-1, 5, "e"))
+			require.NoError(t, encoder.AddDataPoint(hi+1e9, 6, "f"))
+			require.NoError(t, encoder.EndMetric())
+
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+
+			blob := decodeRemapTestBlob(t, data)
+			require.Equal(t, tt.want, slices.Collect(blob.AllTimestamps(1)))
+
+			if tt.policy == TimeWindowDrop {
+				tag, ok := blob.TagAt(1, 0)
+				require.True(t, ok)
+				require.Equal(t, "b", tag)
+			}
+		})
+	}
+}
+
+func TestNumericEncoder_TimeWindow_NaNDrop(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	end := start.Add(time.Hour)
+	lo, hi := start.UnixMicro(), end.UnixMicro()
+
+	encoder, err := NewNumericEncoder(start, WithNaNPolicy(NaNDrop), WithTimeWindow(start, end, TimeWindowReject))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+
+	// The rejected point is reported at its position in the call, and the
+	// dropped NaN does not release its claim
+	err = encoder.AddDataPoints([]int64{lo, lo + 1, hi}, []float64{1, math.NaN(), 3}, nil)
+	require.ErrorIs(t, err, errs.ErrTimestampOutOfWindow)
+	require.ErrorContains(t, err, "data point 2 ")
+
+	require.NoError(t, encoder.AddDataPoints([]int64{lo, lo + 1, lo + 2}, []float64{1, 2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	// An accepted batch releases the claim of its dropped NaN
+	require.NoError(t, encoder.StartMetricID(2, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{lo, lo + 1, lo + 2}, []float64{1, math.NaN(), 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, data)
+	require.Equal(t, []float64{1, 2, 3}, slices.Collect(blob.AllValues(1)))
+	require.Equal(t, []int64{lo, lo + 2}, slices.Collect(blob.AllTimestamps(2)))
+}
+
+func TestNumericEncoder_TimeWindow_Reference(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	lo := start.UnixMicro()
+	encoder, err := NewNumericEncoder(start, WithMetricReferences(), WithTimeWindow(start, start.Add(time.Hour), TimeWindowDrop))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{lo, lo + 1, lo + 2}, []float64{1, 2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	// A dropped point leaves the metric shorter than its reference
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{lo - 1, lo + 1}, []float64{1.5, 2.5}, nil))
+	require.NoError(t, encoder.AddDataPoint(lo+2, 3.5, ""))
+	require.ErrorIs(t, encoder.EndMetric(), errs.ErrInvalidMetricReference)
+	require.NoError(t, encoder.AbortMetric())
+
+	require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+	require.NoError(t, encoder.AddDataPoints([]int64{lo, lo + 1, lo + 2}, []float64{1.5, 2.5, 3.5}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	blob := decodeRemapTestBlob(t, data)
+	require.Equal(t, []float64{1.5, 2.5, 3.5}, slices.Collect(blob.AllValues(2)))
+}
+
+func TestNumericEncoder_TimeWindow_Invalid(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewNumericEncoder(start, WithTimeWindow(start, start, TimeWindowDrop))
+	require.ErrorIs(t, err, errs.ErrInvalidTimeRange)
+
+	_, err = NewNumericEncoder(start, WithTimeWindow(start, start.Add(time.Hour), TimeWindowPolicy(9)))
+	require.Error(t, err)
+
+	// A sub-second window is empty in seconds.
+	_, err = NewNumericEncoder(start,
+		WithTimestampUnit(format.TimeUnitSecond),
+		WithTimeWindow(start, start.Add(time.Millisecond), TimeWindowDrop),
+	)
+	require.ErrorIs(t, err, errs.ErrInvalidTimeRange)
+}
+
+func TestNumericEncoder_TimeWindow_Validated(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start, WithTimeWindow(start, start.Add(time.Minute), TimeWindowReject))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 3))
+
+	lo := start.UnixMicro()
+	report, err := encoder.AddDataPointsValidated([]int64{lo, lo + 1, lo + time.Hour.Microseconds()}, []float64{1, 2, 3}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Accepted)
+	require.Len(t, report.Rejected, 1)
+	require.Equal(t, RejectOutOfWindow, report.Rejected[0].Reason)
+	require.NoError(t, encoder.EndMetric())
+}
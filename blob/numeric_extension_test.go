@@ -0,0 +1,208 @@
+package blob
+
+import (
+	"bytes"
+	"encoding/binary"
+	"iter"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/encoding"
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// testExtensionID is the extension encoding ID registered by this file's tests.
+const testExtensionID = 0x7E01
+
+// invertedEncoder is a minimal extension value encoder that stores the
+// inverted IEEE 754 bits of each value as big-endian uint64.
+type invertedEncoder struct {
+	buf []byte
+	n   int
+}
+
+var _ encoding.ColumnarEncoder[float64] = (*invertedEncoder)(nil)
+
+func (e *invertedEncoder) Bytes() []byte { return e.buf }
+func (e *invertedEncoder) Len() int      { return e.n }
+func (e *invertedEncoder) Size() int     { return len(e.buf) }
+func (e *invertedEncoder) Reset()        {}
+func (e *invertedEncoder) Finish()       {}
+
+func (e *invertedEncoder) Write(v float64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, ^math.Float64bits(v))
+	e.n++
+}
+
+func (e *invertedEncoder) WriteSlice(values []float64) {
+	for _, v := range values {
+		e.Write(v)
+	}
+}
+
+type invertedDecoder struct{}
+
+var _ encoding.ColumnarDecoder[float64] = invertedDecoder{}
+
+func (invertedDecoder) All(data []byte, count int) iter.Seq[float64] {
+	return func(yield func(float64) bool) {
+		for i := 0; i < count && len(data) >= (i+1)*8; i++ {
+			if !yield(math.Float64frombits(^binary.BigEndian.Uint64(data[i*8:]))) {
+				return
+			}
+		}
+	}
+}
+
+func (invertedDecoder) At(data []byte, index int, count int) (float64, bool) {
+	if index < 0 || index >= count || len(data) < (index+1)*8 {
+		return 0, false
+	}
+
+	return math.Float64frombits(^binary.BigEndian.Uint64(data[index*8:])), true
+}
+
+func init() {
+	err := format.RegisterEncoding(testExtensionID, "inverted", func(endian.EndianEngine) encoding.ColumnarDecoder[float64] {
+		return invertedDecoder{}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestNumericEncoder_ExtensionValueEncoder(t *testing.T) {
+	startTime := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(startTime,
+		WithValueEncoder(testExtensionID, &invertedEncoder{}),
+		WithTagsEnabled(true),
+	)
+	require.NoError(t, err)
+
+	base := startTime.UnixMicro()
+	want := map[uint64][]float64{
+		1: {1.5, -2.25, math.Inf(1)},
+		2: {100, 200, 300, 400},
+	}
+	for _, id := range []uint64{1, 2} {
+		require.NoError(t, encoder.StartMetricID(id, len(want[id])))
+		for i, v := range want[id] {
+			require.NoError(t, encoder.AddDataPoint(base+int64(i)*1000000, v, "t"))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	require.Equal(t, format.TypeExtension, header.Flag.ValueEncoding())
+	require.True(t, header.Flag.HasMetadata())
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	for id, values := range want {
+		var got []float64
+		for v := range blob.AllValues(id) {
+			got = append(got, v)
+		}
+		require.Equal(t, values, got)
+
+		points, ok := blob.DataPoints(id)
+		require.True(t, ok)
+		require.Equal(t, values, points.Val)
+
+		last, ok := blob.ValueAt(id, len(values)-1)
+		require.True(t, ok)
+		require.Equal(t, values[len(values)-1], last)
+	}
+
+	material := blob.Materialize()
+	got, ok := material.ValueAt(2, 3)
+	require.True(t, ok)
+	require.Equal(t, 400.0, got)
+}
+
+func TestNumericEncoder_ExtensionValueEncoderErrors(t *testing.T) {
+	startTime := time.Unix(1700000000, 0).UTC()
+
+	_, err := NewNumericEncoder(startTime, WithValueEncoder(0x7EFF, &invertedEncoder{}))
+	require.ErrorIs(t, err, errs.ErrUnsupportedEncoding)
+
+	_, err = NewNumericEncoder(startTime, WithValueEncoder(testExtensionID, nil))
+	require.ErrorIs(t, err, errs.ErrUnsupportedEncoding)
+
+	_, err = NewNumericEncoder(startTime,
+		WithValueEncoder(testExtensionID, &invertedEncoder{}),
+		WithMaxEncoderMemory(1<<20, ""),
+	)
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestNumericDecoder_ExtensionNotRegistered(t *testing.T) {
+	startTime := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(startTime, WithValueEncoder(testExtensionID, &invertedEncoder{}))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	// Rewrite the recorded extension ID to one that is not registered.
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	engine := header.Flag.GetEndianEngine()
+	metadata, _, err := section.ParseMetadata(data[section.HeaderSize:], engine)
+	require.NoError(t, err)
+	require.Len(t, metadata.Records, 1)
+	require.Equal(t, section.MetadataKeyValueExtension, metadata.Records[0].Key)
+
+	id := make([]byte, 2)
+	engine.PutUint16(id, testExtensionID)
+	idx := section.HeaderSize + bytes.Index(data[section.HeaderSize:], id)
+	engine.PutUint16(data[idx:], 0x7EFF)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	_, err = decoder.Decode()
+	require.ErrorIs(t, err, errs.ErrUnsupportedEncoding)
+}
+
+func TestNumericBlob_ExtensionCapabilities(t *testing.T) {
+	startTime := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(startTime, WithValueEncoder(testExtensionID, &invertedEncoder{}))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	for i := range 3 {
+		require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro()+int64(i), float64(i), ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	// invertedDecoder does not report capabilities, so it is treated as sequential.
+	require.Equal(t, encoding.Capabilities{}, blob.ValueCapabilities())
+
+	values, ok := blob.ValuesAt(1, []int{2, 0})
+	require.True(t, ok)
+	require.Equal(t, []float64{2, 0}, values)
+}
@@ -0,0 +1,242 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// MaxMetricUnitLength is the maximum length of MetricMeta.Unit in bytes.
+const MaxMetricUnitLength = 255
+
+// MetricMeta describes the semantics of a numeric metric, so exporters such as
+// Prometheus or OpenTelemetry can map it without an external schema registry.
+type MetricMeta struct {
+	// Kind declares whether the metric is a gauge, counter or histogram series.
+	Kind format.MetricKind
+
+	// Unit is the unit of the values, e.g. "bytes" or "seconds" (at most
+	// MaxMetricUnitLength bytes, empty if unspecified).
+	Unit string
+}
+
+// IsZero reports whether the metadata declares neither kind nor unit.
+func (m MetricMeta) IsZero() bool {
+	return m.Kind == format.MetricKindUnknown && m.Unit == ""
+}
+
+// validate checks that the metadata can be encoded.
+func (m MetricMeta) validate() error {
+	if !m.Kind.IsValid() {
+		return fmt.Errorf("%w: unknown metric kind %d", errs.ErrInvalidMetricMeta, m.Kind)
+	}
+
+	if len(m.Unit) > MaxMetricUnitLength {
+		return fmt.Errorf("%w: unit length %d exceeds %d bytes", errs.ErrInvalidMetricMeta, len(m.Unit), MaxMetricUnitLength)
+	}
+
+	return nil
+}
+
+// metricMetaEntry pairs a metric ID with its metadata.
+type metricMetaEntry struct {
+	metricID uint64
+	meta     MetricMeta
+}
+
+// metricMetaEntrySize returns the serialized size of an entry.
+func metricMetaEntrySize(meta MetricMeta) int {
+	return 10 + len(meta.Unit) // MetricID(8) + Kind(1) + UnitLength(1) + Unit
+}
+
+// encodeMetricMeta serializes the entries sorted by MetricID.
+func encodeMetricMeta(entries []metricMetaEntry, engine endian.EndianEngine) []byte {
+	slices.SortFunc(entries, func(a, b metricMetaEntry) int {
+		return cmp.Compare(a.metricID, b.metricID)
+	})
+
+	size := 0
+	for _, e := range entries {
+		size += metricMetaEntrySize(e.meta)
+	}
+
+	b := make([]byte, 0, size)
+	for _, e := range entries {
+		b = engine.AppendUint64(b, e.metricID)
+		b = append(b, byte(e.meta.Kind), byte(len(e.meta.Unit)))
+		b = append(b, e.meta.Unit...)
+	}
+
+	return b
+}
+
+// parseMetricMetaEntries parses the metric metadata record into entries in
+// record order.
+func parseMetricMetaEntries(data []byte, engine endian.EndianEngine) ([]metricMetaEntry, error) {
+	var entries []metricMetaEntry
+	for offset := 0; offset < len(data); {
+		if len(data)-offset < 10 {
+			return nil, fmt.Errorf("%w: record truncated at entry %d", errs.ErrInvalidMetricMeta, len(entries))
+		}
+
+		metricID := engine.Uint64(data[offset:])
+		kind := format.MetricKind(data[offset+8])
+		unitLen := int(data[offset+9])
+		offset += 10
+
+		if len(data)-offset < unitLen {
+			return nil, fmt.Errorf("%w: unit of metric ID 0x%016x truncated", errs.ErrInvalidMetricMeta, metricID)
+		}
+
+		if !kind.IsValid() {
+			return nil, fmt.Errorf("%w: unknown kind %d of metric ID 0x%016x", errs.ErrInvalidMetricMeta, kind, metricID)
+		}
+
+		if len(entries) > 0 && metricID <= entries[len(entries)-1].metricID {
+			return nil, fmt.Errorf("%w: entries must be unique and sorted by MetricID", errs.ErrInvalidMetricMeta)
+		}
+
+		entries = append(entries, metricMetaEntry{
+			metricID: metricID,
+			meta:     MetricMeta{Kind: kind, Unit: string(data[offset : offset+unitLen])},
+		})
+		offset += unitLen
+	}
+
+	return entries, nil
+}
+
+// parseMetricMeta parses the metric metadata record of md into a map keyed by
+// MetricID, or nil if the record is absent.
+func parseMetricMeta(md section.Metadata, engine endian.EndianEngine) (map[uint64]MetricMeta, error) {
+	data, ok := md.Get(section.MetadataKeyMetricMeta)
+	if !ok {
+		return nil, nil
+	}
+
+	entries, err := parseMetricMetaEntries(data, engine)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make(map[uint64]MetricMeta, len(entries))
+	for _, e := range entries {
+		metas[e.metricID] = e.meta
+	}
+
+	return metas, nil
+}
+
+// remapMetricMeta returns metadata whose metric metadata entries use the
+// remapped IDs, re-sorted by MetricID. Other records are shared with md.
+func remapMetricMeta(md section.Metadata, oldIDs, newIDs []uint64, engine endian.EndianEngine) (section.Metadata, error) {
+	data, ok := md.Get(section.MetadataKeyMetricMeta)
+	if !ok {
+		return md, nil
+	}
+
+	entries, err := parseMetricMetaEntries(data, engine)
+	if err != nil {
+		return md, err
+	}
+
+	remap := make(map[uint64]uint64, len(oldIDs))
+	for i, id := range oldIDs {
+		remap[id] = newIDs[i]
+	}
+
+	for i := range entries {
+		newID, ok := remap[entries[i].metricID]
+		if !ok {
+			return md, fmt.Errorf("%w: metadata of unknown metric ID 0x%016x", errs.ErrInvalidMetricMeta, entries[i].metricID)
+		}
+		entries[i].metricID = newID
+	}
+
+	remapped := section.Metadata{Records: slices.Clone(md.Records)}
+	remapped.Set(section.MetadataKeyMetricMeta, encodeMetricMeta(entries, engine))
+
+	return remapped, nil
+}
+
+// MetricMeta returns the kind and unit declared for the given metric ID (see
+// NumericEncoder.StartMetricIDWithMeta).
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - MetricMeta: The declared metadata
+//   - bool: true if metadata was declared for the metric, false otherwise
+//
+// Example:
+//
+//	if meta, ok := blob.MetricMeta(metricID); ok && meta.Kind == format.MetricKindCounter {
+//	    // ... export as a Prometheus counter with unit meta.Unit
+//	}
+func (b NumericBlob) MetricMeta(metricID uint64) (MetricMeta, bool) {
+	meta, ok := b.metricMeta[metricID]
+
+	return meta, ok
+}
+
+// MetricMetaByName returns the kind and unit declared for the given metric name.
+// See MetricMeta for details.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//
+// Returns:
+//   - MetricMeta: The declared metadata
+//   - bool: true if metadata was declared for the metric, false otherwise
+func (b NumericBlob) MetricMetaByName(metricName string) (MetricMeta, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return MetricMeta{}, false
+	}
+
+	return b.MetricMeta(entry.MetricID)
+}
+
+// MetricMeta returns the kind and unit declared for the given metric ID by the
+// first blob that declares it.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - MetricMeta: The declared metadata
+//   - bool: true if any blob declared metadata for the metric, false otherwise
+func (s NumericBlobSet) MetricMeta(metricID uint64) (MetricMeta, bool) {
+	for i := range s.blobs {
+		if meta, ok := s.blob(i).MetricMeta(metricID); ok {
+			return meta, true
+		}
+	}
+
+	return MetricMeta{}, false
+}
+
+// MetricMetaByName returns the kind and unit declared for the given metric name
+// by the first blob that declares it.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//
+// Returns:
+//   - MetricMeta: The declared metadata
+//   - bool: true if any blob declared metadata for the metric, false otherwise
+func (s NumericBlobSet) MetricMetaByName(metricName string) (MetricMeta, bool) {
+	for i := range s.blobs {
+		if meta, ok := s.blob(i).MetricMetaByName(metricName); ok {
+			return meta, true
+		}
+	}
+
+	return MetricMeta{}, false
+}
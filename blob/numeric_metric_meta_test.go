@@ -0,0 +1,162 @@
+package blob
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func encodeMetricMetaTestBlob(t *testing.T, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricIDWithMeta(30, 2, MetricMeta{Kind: format.MetricKindCounter, Unit: "bytes"}))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{10, 20}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	require.NoError(t, encoder.StartMetricID(10, 2))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{0.5, 0.7}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	require.NoError(t, encoder.StartMetricIDWithMeta(20, 2, MetricMeta{Kind: format.MetricKindGauge}))
+	require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{3, 4}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	maxSize := encoder.MaxFinishedSize()
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), maxSize)
+
+	return data
+}
+
+func TestNumericEncoder_StartMetricIDWithMeta(t *testing.T) {
+	for _, bigEndian := range []bool{false, true} {
+		opts := []NumericEncoderOption{WithLittleEndian()}
+		if bigEndian {
+			opts = []NumericEncoderOption{WithBigEndian()}
+		}
+
+		blob := decodeRemapTestBlob(t, encodeMetricMetaTestBlob(t, opts...))
+
+		meta, ok := blob.MetricMeta(30)
+		require.True(t, ok)
+		require.Equal(t, MetricMeta{Kind: format.MetricKindCounter, Unit: "bytes"}, meta)
+
+		meta, ok = blob.MetricMeta(20)
+		require.True(t, ok)
+		require.Equal(t, MetricMeta{Kind: format.MetricKindGauge}, meta)
+
+		_, ok = blob.MetricMeta(10)
+		require.False(t, ok)
+
+		// Metadata does not affect the data points.
+		require.Equal(t, []float64{10, 20}, slices.Collect(blob.AllValues(30)))
+	}
+}
+
+func TestNumericEncoder_StartMetricNameWithMeta(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricNameWithMeta("http.requests", 1, MetricMeta{Kind: format.MetricKindCounter}))
+	require.NoError(t, encoder.AddDataPoint(1, 100, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, data)
+	meta, ok := blob.MetricMetaByName("http.requests")
+	require.True(t, ok)
+	require.Equal(t, format.MetricKindCounter, meta.Kind)
+
+	_, ok = blob.MetricMetaByName("missing")
+	require.False(t, ok)
+
+	set, err := NewNumericBlobSet([]NumericBlob{blob})
+	require.NoError(t, err)
+	meta, ok = set.MetricMetaByName("http.requests")
+	require.True(t, ok)
+	require.Equal(t, format.MetricKindCounter, meta.Kind)
+	meta, ok = set.MetricMeta(hash.ID("http.requests"))
+	require.True(t, ok)
+	require.Equal(t, format.MetricKindCounter, meta.Kind)
+}
+
+func TestNumericEncoder_StartMetricIDWithMeta_Invalid(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	err = encoder.StartMetricIDWithMeta(1, 1, MetricMeta{Kind: format.MetricKind(9)})
+	require.ErrorIs(t, err, errs.ErrInvalidMetricMeta)
+
+	err = encoder.StartMetricIDWithMeta(1, 1, MetricMeta{Unit: strings.Repeat("x", MaxMetricUnitLength+1)})
+	require.ErrorIs(t, err, errs.ErrInvalidMetricMeta)
+
+	// A rejected call leaves no metric started.
+	require.NoError(t, encoder.StartMetricIDWithMeta(1, 1, MetricMeta{Unit: strings.Repeat("x", MaxMetricUnitLength)}))
+}
+
+func TestRemapMetricIDs_MetricMeta(t *testing.T) {
+	data := encodeMetricMetaTestBlob(t)
+
+	remapped, err := RemapMetricIDs(data, map[uint64]uint64{30: 5, 20: 40})
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, remapped)
+	meta, ok := blob.MetricMeta(5)
+	require.True(t, ok)
+	require.Equal(t, "bytes", meta.Unit)
+
+	meta, ok = blob.MetricMeta(40)
+	require.True(t, ok)
+	require.Equal(t, format.MetricKindGauge, meta.Kind)
+
+	_, ok = blob.MetricMeta(30)
+	require.False(t, ok)
+}
+
+func TestParseMetricMetaEntries_Invalid(t *testing.T) {
+	engine := endian.GetLittleEndianEngine()
+	valid := encodeMetricMeta([]metricMetaEntry{
+		{metricID: 2, meta: MetricMeta{Kind: format.MetricKindGauge, Unit: "s"}},
+		{metricID: 1, meta: MetricMeta{Kind: format.MetricKindCounter}},
+	}, engine)
+
+	entries, err := parseMetricMetaEntries(valid, engine)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2}, []uint64{entries[0].metricID, entries[1].metricID})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated entry", data: valid[:5]},
+		{name: "truncated unit", data: valid[:len(valid)-1]},
+		{name: "unknown kind", data: func() []byte {
+			b := append([]byte(nil), valid...)
+			b[8] = 7
+
+			return b
+		}()},
+		{name: "unsorted", data: append(append([]byte(nil), valid[10:]...), valid[:10]...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseMetricMetaEntries(tt.data, engine)
+			require.ErrorIs(t, err, errs.ErrInvalidMetricMeta)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// compressedNamesPrefixSize is the size of the uint32 compressed size that
+// prefixes a compressed metric names payload.
+const compressedNamesPrefixSize = 4
+
+// encodeNumericMetricNames encodes the metric names payload of a numeric blob.
+//
+// When codec is non-nil the payload is compressed and stored as
+// [Size: uint32][compressed names payload], unless compression does not shrink it.
+// The bool result reports whether the returned payload is compressed.
+func encodeNumericMetricNames(names []string, engine endian.EndianEngine, codec compress.Codec) ([]byte, bool, error) {
+	payload, err := ienc.EncodeMetricNames(names, engine)
+	if err != nil || codec == nil {
+		return payload, false, err
+	}
+
+	compressed, err := codec.Compress(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to compress metric names: %w", err)
+	}
+
+	if compressedNamesPrefixSize+len(compressed) >= len(payload) {
+		return payload, false, nil
+	}
+
+	buf := make([]byte, compressedNamesPrefixSize+len(compressed))
+	engine.PutUint32(buf, uint32(len(compressed))) //nolint: gosec
+	copy(buf[compressedNamesPrefixSize:], compressed)
+
+	return buf, true, nil
+}
+
+// reencodeNumericMetricNames encodes rewritten metric names of a numeric blob,
+// compressing them if the source blob's were, and updates the compressed metric
+// names flag to match.
+func reencodeNumericMetricNames(names []string, flag *section.NumericFlag, engine endian.EndianEngine) ([]byte, error) {
+	var codec compress.Codec
+	if flag.HasCompressedMetricNames() {
+		var err error
+		if codec, err = compress.GetCodec(format.CompressionZstd); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, compressed, err := encodeNumericMetricNames(names, engine, codec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metric names: %w", err)
+	}
+	flag.SetCompressedMetricNames(compressed)
+
+	return payload, nil
+}
+
+// compressedMetricNames returns the compressed frame of the compressed metric
+// names payload at the start of data, and the payload size including its prefix.
+func compressedMetricNames(data []byte, engine endian.EndianEngine) ([]byte, int, error) {
+	if len(data) < compressedNamesPrefixSize {
+		return nil, 0, fmt.Errorf("%w: cannot read compressed metric names size", errs.ErrInvalidMetricNamesPayload)
+	}
+
+	size := int(engine.Uint32(data))
+	end := compressedNamesPrefixSize + size
+	if size > len(data)-compressedNamesPrefixSize {
+		return nil, 0, fmt.Errorf("%w: compressed metric names size %d exceeds data length %d",
+			errs.ErrInvalidMetricNamesPayload, size, len(data)-compressedNamesPrefixSize)
+	}
+
+	return data[compressedNamesPrefixSize:end], end, nil
+}
+
+// decompressMetricNames decompresses and decodes a compressed metric names frame.
+func decompressMetricNames(frame []byte, engine endian.EndianEngine) ([]string, error) {
+	codec, err := compress.GetCodec(format.CompressionZstd)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := codec.Decompress(frame)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decompress metric names: %w", errs.ErrInvalidMetricNamesPayload, err)
+	}
+
+	names, _, err := ienc.DecodeMetricNames(payload, engine)
+
+	return names, err
+}
+
+// decodeNumericMetricNames decodes the metric names payload at the start of data,
+// decompressing it first when compressed is true.
+//
+// Returns the names and the payload size in bytes.
+func decodeNumericMetricNames(data []byte, engine endian.EndianEngine, compressed bool) ([]string, int, error) {
+	if !compressed {
+		return ienc.DecodeMetricNames(data, engine)
+	}
+
+	frame, size, err := compressedMetricNames(data, engine)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	names, err := decompressMetricNames(frame, engine)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return names, size, nil
+}
+
+// parseCompressedMetricNames decodes a compressed metric names frame, verifies the
+// names against the metric IDs of the index entries (in index order) and maps them
+// to their entries.
+func parseCompressedMetricNames(frame []byte, engine endian.EndianEngine, entries []section.NumericIndexEntry, metricIDs []uint64) (map[string]section.NumericIndexEntry, error) {
+	names, err := decompressMetricNames(frame, engine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metric names: %w", err)
+	}
+
+	if len(names) != len(entries) {
+		return nil, fmt.Errorf("%w: expected %d names, got %d", errs.ErrInvalidMetricNamesCount, len(entries), len(names))
+	}
+
+	if err := ienc.VerifyMetricNamesHashes(names, metricIDs, hash.ID); err != nil {
+		return nil, fmt.Errorf("metric name verification failed: %w", err)
+	}
+
+	return nameIndex(names, entries), nil
+}
@@ -0,0 +1,132 @@
+package blob
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/section"
+)
+
+// encodeNamedTestBlob encodes count named metrics with a forced collision so the
+// blob stores its metric names payload.
+func encodeNamedTestBlob(t *testing.T, count int, opts ...NumericEncoderOption) ([]byte, []string) {
+	t.Helper()
+
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, opts...)
+	require.NoError(t, err)
+
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("service.requests.latency.p99.host-%04d", i)
+		require.NoError(t, encoder.StartMetricName(names[i], 2))
+		for j := range 2 {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(j)*time.Second).UnixMicro(), float64(i+j), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	encoder.hasCollision = true
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data, names
+}
+
+func TestNumericMetricNames_Compressed(t *testing.T) {
+	plain, names := encodeNamedTestBlob(t, 200)
+	data, _ := encodeNamedTestBlob(t, 200, WithCompressedMetricNames(true))
+	require.Less(t, len(data), len(plain))
+
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	require.True(t, header.Flag.HasMetricNames())
+	require.True(t, header.Flag.HasCompressedMetricNames())
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	// Names are not parsed by Decode or by ID lookups
+	require.Equal(t, 200, blob.MetricCount())
+	require.Equal(t, 2, blob.Len(blob.MetricIDs()[0]))
+	require.Nil(t, blob.index.names.byName)
+
+	require.ElementsMatch(t, names, blob.MetricNames())
+	require.NoError(t, blob.MetricNamesErr())
+	for i, name := range names {
+		require.True(t, blob.HasMetricName(name))
+		v, ok := blob.ValueAtByName(name, 1)
+		require.True(t, ok)
+		require.InDelta(t, float64(i+1), v, 1e-9)
+	}
+	require.False(t, blob.HasMetricName("missing"))
+}
+
+func TestNumericMetricNames_CompressedTooSmall(t *testing.T) {
+	// A single short name does not shrink, so it is stored uncompressed
+	encoder, err := NewNumericEncoder(time.Now(), WithCompressedMetricNames(true))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("a", 1))
+	require.NoError(t, encoder.AddDataPoint(time.Now().UnixMicro(), 1, ""))
+	require.NoError(t, encoder.EndMetric())
+	encoder.hasCollision = true
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	require.True(t, header.Flag.HasMetricNames())
+	require.False(t, header.Flag.HasCompressedMetricNames())
+}
+
+func TestNumericMetricNames_CompressedCorrupt(t *testing.T) {
+	data, names := encodeNamedTestBlob(t, 50, WithCompressedMetricNames(true))
+
+	// Corrupt the compressed frame, located right after the header and its size prefix
+	data[section.HeaderSize+compressedNamesPrefixSize+8] ^= 0xFF
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	require.Error(t, blob.MetricNamesErr())
+	require.Empty(t, blob.MetricNames())
+	require.Equal(t, 50, blob.MetricCount())
+
+	// Name lookups fall back to hashed IDs
+	require.True(t, blob.HasMetricName(names[0]))
+}
+
+func TestNumericMetricNames_CompressedRewrite(t *testing.T) {
+	data, names := encodeNamedTestBlob(t, 100, WithCompressedMetricNames(true))
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.DropMetricByName(names[0]))
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	renamed, err := RenameMetrics(edited, map[string]string{names[1]: "renamed"})
+	require.NoError(t, err)
+
+	for _, out := range [][]byte{edited, renamed} {
+		header, err := section.ParseNumericHeader(out)
+		require.NoError(t, err)
+		require.True(t, header.Flag.HasCompressedMetricNames())
+	}
+
+	decoder, err := NewNumericDecoder(renamed)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	want := append([]string{"renamed"}, names[2:]...)
+	require.ElementsMatch(t, want, blob.MetricNames())
+	require.NoError(t, blob.MetricNamesErr())
+}
@@ -0,0 +1,99 @@
+package blob
+
+import (
+	"slices"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// WithConvertToNative converts the fixed-width payloads of a blob encoded in the
+// other byte order to the byte order of the running system at decode time.
+//
+// Raw timestamps and raw values of a foreign byte order blob (e.g., a big-endian
+// blob read on an amd64 or arm64 host) are normally decoded word by word through
+// the endian engine. With the conversion, Decode byte-swaps the raw timestamp and
+// value payloads once, so every later read takes the same zero-copy fast path as
+// a native blob. Decoded results are identical either way; the conversion trades
+// one pass and, for uncompressed payloads, a copy of the payload for faster reads,
+// which pays off when the blob is read more than once.
+//
+// The option has no effect on native byte order blobs and on payloads that are
+// not raw encoded. The encoded data is never modified. After the conversion,
+// RawValueBytes and RawTimestampPayload return native byte order bytes (see
+// NumericBlob.PayloadEngine).
+//
+// Example:
+//
+//	decoder, _ := blob.NewNumericDecoder(bigEndianData, blob.WithConvertToNative(true))
+//	b, _ := decoder.Decode()
+//	_ = b.SameByteOrder() // true for raw encoded payloads
+func WithConvertToNative(enabled bool) NumericDecoderOption {
+	return options.NoError(func(d *NumericDecoder) {
+		d.convertToNative = enabled
+	})
+}
+
+// PayloadEngine returns the byte order of the blob's raw timestamp and value
+// payloads, as returned by RawValueBytes and RawTimestampPayload.
+//
+// It equals Engine unless the blob was decoded with WithConvertToNative from a
+// foreign byte order blob, in which case it is the native byte order.
+func (b NumericBlob) PayloadEngine() endian.EndianEngine {
+	if b.sameByteOrder {
+		return nativeEngine()
+	}
+
+	return b.Engine()
+}
+
+// nativeEngine returns the endian engine of the running system.
+func nativeEngine() endian.EndianEngine {
+	if endian.IsNativeLittleEndian() {
+		return endian.GetLittleEndianEngine()
+	}
+
+	return endian.GetBigEndianEngine()
+}
+
+// convertPayloadsToNative byte-swaps the raw timestamp and value payloads of a
+// foreign byte order blob when the decoder converts to native byte order.
+//
+// Every raw payload is converted, since a single flag selects the raw decoders
+// of both columns. Payloads are swapped in place unless they are uncompressed,
+// in which case they alias the encoded data and are copied first. Truncated
+// payloads (DecodePartial) convert every complete word.
+func (d *NumericDecoder) convertPayloadsToNative(blob *NumericBlob) {
+	if !d.convertToNative || blob.sameByteOrder {
+		return
+	}
+
+	if blob.tsEncType != format.TypeRaw && blob.valEncType != format.TypeRaw {
+		return
+	}
+
+	if blob.tsEncType == format.TypeRaw {
+		blob.tsPayload = d.nativeWords(blob.tsPayload, d.header.Flag.TimestampCompression())
+	}
+	if blob.valEncType == format.TypeRaw {
+		blob.valPayload = d.nativeWords(blob.valPayload, d.header.Flag.ValueCompression())
+	}
+
+	blob.sameByteOrder = true
+}
+
+// nativeWords returns payload with every complete 64-bit word converted from the
+// blob's byte order to the native byte order.
+func (d *NumericDecoder) nativeWords(payload []byte, compression format.CompressionType) []byte {
+	if compression == format.CompressionNone {
+		payload = slices.Clone(payload)
+	}
+
+	native := nativeEngine()
+	for i := 0; i+8 <= len(payload); i += 8 {
+		native.PutUint64(payload[i:], d.engine.Uint64(payload[i:]))
+	}
+
+	return payload
+}
@@ -0,0 +1,322 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/format"
+)
+
+// endianTestMetric is the source data of one metric of a cross-endian test blob.
+type endianTestMetric struct {
+	name       string
+	timestamps []int64
+	values     []float64
+	tags       []string
+}
+
+// randomEndianTestMetrics returns random metrics, including special float values
+// and irregular timestamps, generated from seed.
+func randomEndianTestMetrics(seed uint64) []endianTestMetric {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	start := time.Unix(1700000000, 0).UnixMicro()
+	specials := []float64{0, math.Copysign(0, -1), math.Inf(1), math.Inf(-1), math.MaxFloat64, math.SmallestNonzeroFloat64}
+
+	metrics := make([]endianTestMetric, 1+rng.IntN(8))
+	for i := range metrics {
+		n := 1 + rng.IntN(200)
+		m := endianTestMetric{
+			name:       fmt.Sprintf("metric.%d.%d", seed, i),
+			timestamps: make([]int64, n),
+			values:     make([]float64, n),
+			tags:       make([]string, n),
+		}
+
+		ts := start
+		for j := range n {
+			ts += int64(1 + rng.IntN(5_000_000))
+			m.timestamps[j] = ts
+
+			switch rng.IntN(4) {
+			case 0:
+				m.values[j] = specials[rng.IntN(len(specials))]
+			case 1:
+				m.values[j] = float64(rng.IntN(1000))
+			default:
+				m.values[j] = rng.NormFloat64() * 1e6
+			}
+
+			if rng.IntN(3) > 0 {
+				m.tags[j] = fmt.Sprintf("host=%d", rng.IntN(10))
+			}
+		}
+		metrics[i] = m
+	}
+
+	return metrics
+}
+
+// encodeEndianTestBlob encodes metrics with opts.
+func encodeEndianTestBlob(t *testing.T, metrics []endianTestMetric, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), opts...)
+	require.NoError(t, err)
+	for _, m := range metrics {
+		require.NoError(t, encoder.StartMetricName(m.name, len(m.timestamps)))
+		require.NoError(t, encoder.AddDataPoints(m.timestamps, m.values, m.tags))
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+// endianSnapshot holds everything a blob returns for one metric through its
+// iterators, random access and materialization methods.
+type endianSnapshot struct {
+	points       []NumericDataPoint
+	timestamps   []int64
+	values       []float64
+	tags         []string
+	at           []NumericDataPoint
+	forEach      []NumericDataPoint
+	forEachVals  []float64
+	forEachTimes []int64
+	material     MaterializedNumericMetric
+}
+
+// takeEndianSnapshot reads every metric of blob through all access paths.
+func takeEndianSnapshot(t *testing.T, blob NumericBlob, metrics []endianTestMetric) []endianSnapshot {
+	t.Helper()
+
+	snapshots := make([]endianSnapshot, len(metrics))
+	for i, m := range metrics {
+		s := &snapshots[i]
+		var ok bool
+		s.material, ok = blob.MaterializeMetricByName(m.name)
+		require.True(t, ok)
+		id := s.material.MetricID
+
+		for _, dp := range blob.AllByName(m.name) {
+			s.points = append(s.points, dp)
+		}
+		s.timestamps = slices.Collect(blob.AllTimestampsByName(m.name))
+		s.values = slices.Collect(blob.AllValuesByName(m.name))
+		s.tags = slices.Collect(blob.AllTagsByName(m.name))
+
+		for j := range blob.LenByName(m.name) {
+			ts, ok := blob.TimestampAtByName(m.name, j)
+			require.True(t, ok)
+			val, ok := blob.ValueAtByName(m.name, j)
+			require.True(t, ok)
+			tag, ok := blob.TagAtByName(m.name, j)
+			require.True(t, ok)
+			s.at = append(s.at, NumericDataPoint{Ts: ts, Val: val, Tag: tag})
+		}
+
+		blob.ForEachByName(m.name, func(_ int, dp NumericDataPoint) bool {
+			s.forEach = append(s.forEach, dp)
+			return true
+		})
+		blob.ForEachValues(id, func(_ int, val float64) bool {
+			s.forEachVals = append(s.forEachVals, val)
+			return true
+		})
+		blob.ForEachTimestamps(id, func(_ int, ts int64) bool {
+			s.forEachTimes = append(s.forEachTimes, ts)
+			return true
+		})
+	}
+
+	return snapshots
+}
+
+// requireSnapshotMatches checks a snapshot against the source metrics, comparing
+// float values bit by bit.
+func requireSnapshotMatches(t *testing.T, metrics []endianTestMetric, snapshots []endianSnapshot) {
+	t.Helper()
+
+	bits := func(values []float64) []uint64 {
+		out := make([]uint64, len(values))
+		for i, v := range values {
+			out[i] = math.Float64bits(v)
+		}
+
+		return out
+	}
+
+	for i, m := range metrics {
+		s := snapshots[i]
+		want := make([]NumericDataPoint, len(m.timestamps))
+		for j := range want {
+			want[j] = NumericDataPoint{Ts: m.timestamps[j], Val: m.values[j], Tag: m.tags[j]}
+		}
+
+		require.Equal(t, m.timestamps, s.timestamps, m.name)
+		require.Equal(t, bits(m.values), bits(s.values), m.name)
+		require.Equal(t, m.tags, s.tags, m.name)
+		require.Equal(t, m.timestamps, s.forEachTimes, m.name)
+		require.Equal(t, bits(m.values), bits(s.forEachVals), m.name)
+		require.Equal(t, m.timestamps, s.material.Timestamps, m.name)
+		require.Equal(t, bits(m.values), bits(s.material.Values), m.name)
+		require.Equal(t, m.tags, s.material.Tags, m.name)
+		for _, points := range [][]NumericDataPoint{s.points, s.at, s.forEach} {
+			require.Len(t, points, len(want), m.name)
+			for j, dp := range points {
+				require.Equal(t, want[j].Ts, dp.Ts, m.name)
+				require.Equal(t, math.Float64bits(want[j].Val), math.Float64bits(dp.Val), m.name)
+				require.Equal(t, want[j].Tag, dp.Tag, m.name)
+			}
+		}
+	}
+}
+
+func TestNumericBlob_CrossEndianMatrix(t *testing.T) {
+	tsEncodings := []format.EncodingType{format.TypeRaw, format.TypeDelta, format.TypeDeltaPacked}
+	valEncodings := []format.EncodingType{format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP}
+	compressions := []format.CompressionType{format.CompressionNone, format.CompressionZstd}
+	layouts := map[string][]NumericEncoderOption{
+		"v1": nil,
+		"v2": {WithBlobLayoutV2()},
+	}
+
+	for _, tsEnc := range tsEncodings {
+		for _, valEnc := range valEncodings {
+			for _, comp := range compressions {
+				for layout, layoutOpts := range layouts {
+					name := fmt.Sprintf("%s-%s-%s-%s", tsEnc, valEnc, comp, layout)
+					t.Run(name, func(t *testing.T) {
+						opts := append([]NumericEncoderOption{
+							WithTimestampEncoding(tsEnc),
+							WithValueEncoding(valEnc),
+							WithTimestampCompression(comp),
+							WithValueCompression(comp),
+							WithTagsEnabled(true),
+						}, layoutOpts...)
+
+						for seed := range uint64(3) {
+							metrics := randomEndianTestMetrics(seed + 1)
+							requireCrossEndianIdentical(t, metrics, opts...)
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestNumericBlob_CrossEndianLayouts(t *testing.T) {
+	raw := []NumericEncoderOption{WithTimestampEncoding(format.TypeRaw), WithValueEncoding(format.TypeRaw), WithTagsEnabled(true)}
+	configs := map[string][]NumericEncoderOption{
+		"shared timestamps":    append(slices.Clone(raw), WithSharedTimestamps()),
+		"word aligned offsets": append(slices.Clone(raw), WithWordAlignedOffsets()),
+		"value alignment":      append(slices.Clone(raw), WithValueAlignment(64), WithValueCompression(format.CompressionNone)),
+		"lz4":                  append(slices.Clone(raw), WithTimestampCompression(format.CompressionLZ4), WithValueCompression(format.CompressionS2)),
+	}
+
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			metrics := randomEndianTestMetrics(42)
+			requireCrossEndianIdentical(t, metrics, opts...)
+		})
+	}
+}
+
+// requireCrossEndianIdentical encodes metrics in both byte orders and checks
+// that every decode path, with and without the conversion to native byte order,
+// returns the source data.
+func requireCrossEndianIdentical(t *testing.T, metrics []endianTestMetric, opts ...NumericEncoderOption) {
+	t.Helper()
+
+	for _, order := range []NumericEncoderOption{WithLittleEndian(), WithBigEndian()} {
+		data := encodeEndianTestBlob(t, metrics, append(slices.Clone(opts), order)...)
+		original := slices.Clone(data)
+
+		for _, convert := range []bool{false, true} {
+			decoder, err := NewNumericDecoder(data, WithConvertToNative(convert))
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			requireSnapshotMatches(t, metrics, takeEndianSnapshot(t, blob, metrics))
+
+			hasRaw := blob.TimestampEncodingType() == format.TypeRaw || blob.ValueEncoding() == format.TypeRaw
+			native := endian.CompareNativeEndian(blob.Engine())
+			require.Equal(t, native || (convert && hasRaw), blob.SameByteOrder())
+		}
+
+		// Converting an uncompressed blob must not modify the encoded data.
+		require.Equal(t, original, data)
+	}
+}
+
+func TestNumericBlob_ConvertToNative_RawPayloads(t *testing.T) {
+	metrics := randomEndianTestMetrics(7)
+	data := encodeEndianTestBlob(t, metrics,
+		WithBigEndian(),
+		WithTagsEnabled(true),
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+		WithValueCompression(format.CompressionNone),
+	)
+
+	for _, convert := range []bool{false, true} {
+		decoder, err := NewNumericDecoder(data, WithConvertToNative(convert))
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		engine := blob.PayloadEngine()
+		if convert {
+			require.True(t, endian.CompareNativeEndian(engine))
+		} else {
+			require.Equal(t, blob.Engine(), engine)
+		}
+
+		for _, m := range metrics {
+			material, ok := blob.MaterializeMetricByName(m.name)
+			require.True(t, ok)
+			id := material.MetricID
+			raw, ok := blob.RawValueBytes(id)
+			require.True(t, ok)
+			payload, _, err := blob.RawTimestampPayload(id)
+			require.NoError(t, err)
+
+			for j := range m.values {
+				require.Equal(t, math.Float64bits(m.values[j]), engine.Uint64(raw[j*8:]))
+				require.Equal(t, uint64(m.timestamps[j]), engine.Uint64(payload[j*8:])) //nolint:gosec
+			}
+		}
+	}
+}
+
+func TestNumericBlob_ConvertToNative_DecodePartial(t *testing.T) {
+	metrics := randomEndianTestMetrics(3)
+	data := encodeEndianTestBlob(t, metrics,
+		WithBigEndian(),
+		WithTagsEnabled(true),
+		WithTimestampEncoding(format.TypeRaw),
+		WithValueEncoding(format.TypeRaw),
+		WithTimestampCompression(format.CompressionNone),
+		WithValueCompression(format.CompressionNone),
+	)
+
+	decoder, err := NewNumericDecoder(data, WithConvertToNative(true))
+	require.NoError(t, err)
+	blob, report, err := decoder.DecodePartial()
+	require.NoError(t, err)
+	require.True(t, report.IsComplete())
+	require.True(t, blob.SameByteOrder())
+
+	requireSnapshotMatches(t, metrics, takeEndianSnapshot(t, blob, metrics))
+}
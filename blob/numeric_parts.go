@@ -0,0 +1,481 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+	"github.com/arloliu/mebo/section"
+)
+
+// NumericPartKind identifies the payload column carried by a blob part.
+type NumericPartKind uint8
+
+const (
+	// NumericPartTimestamps marks a part carrying the compressed timestamp payload.
+	NumericPartTimestamps NumericPartKind = 1
+	// NumericPartValues marks a part carrying the compressed value payload.
+	NumericPartValues NumericPartKind = 2
+	// NumericPartTags marks a part carrying the compressed tag payload.
+	NumericPartTags NumericPartKind = 3
+)
+
+const (
+	// NumericPartHeaderSize is the size of the link header prepended to each column part.
+	NumericPartHeaderSize = 16
+
+	// numericPartMagic identifies a column part link header.
+	numericPartMagic uint16 = 0xEC50
+)
+
+// NumericBlobParts is the columnar external layout of a numeric blob.
+//
+// The blob is split into a head part and one part per payload column, so storage
+// systems can cache the (usually hot) timestamp column separately from the value
+// column and fetch the latter lazily.
+//
+// Head holds everything before the timestamp payload: the header, metric names,
+// metadata, index entries and the shared timestamp table. Each column part starts
+// with a 16-byte link header followed by the compressed payload, exactly as stored
+// in the contiguous blob:
+//
+//	Magic(2B) + Kind(1B) + Reserved(1B) + PayloadLength(4B) + HeadChecksum(8B)
+//
+// Link header fields are always little-endian. HeadChecksum is the xxHash64 of
+// Head and ties each column part to the head it was split from, so mixing parts
+// of different blobs is detected at decode time.
+type NumericBlobParts struct {
+	// Head holds the header, metric names, metadata, index and shared timestamp table.
+	Head []byte
+
+	// Timestamps holds the link header and the compressed timestamp payload.
+	Timestamps []byte
+
+	// Values holds the link header and the compressed value payload.
+	Values []byte
+
+	// Tags holds the link header and the compressed tag payload (nil if the blob has no tags).
+	Tags []byte
+}
+
+// FinishParts finalizes the encoding process like Finish, but returns the blob
+// in the columnar external layout (see NumericBlobParts).
+//
+// Returns:
+//   - NumericBlobParts: The head and column parts of the encoded blob
+//   - error: Same conditions as Finish
+func (e *NumericEncoder) FinishParts() (NumericBlobParts, error) {
+	data, err := e.Finish()
+	if err != nil {
+		return NumericBlobParts{}, err
+	}
+
+	return SplitNumericBlob(data)
+}
+
+// SplitNumericBlob splits an encoded numeric blob into the columnar external layout.
+//
+// The column parts are newly allocated; Head references the input slice.
+//
+// Parameters:
+//   - data: Encoded numeric blob
+//
+// Returns:
+//   - NumericBlobParts: The head and column parts of the blob
+//   - error: Header parsing error or invalid payload offsets
+//
+// Example:
+//
+//	parts, err := blob.SplitNumericBlob(data)
+//	// store parts.Head and parts.Timestamps in the hot tier, parts.Values in the cold tier
+func SplitNumericBlob(data []byte) (NumericBlobParts, error) {
+	data, err := standardNumericBlob(data)
+	if err != nil {
+		return NumericBlobParts{}, err
+	}
+
+	header, err := section.ParseNumericHeader(data)
+	if err != nil {
+		return NumericBlobParts{}, err
+	}
+
+	tsOffset := int(header.TimestampPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	valOffset := int(header.ValuePayloadOffset)    //nolint: gosec // Parse bounds offsets to the int range
+	tagOffset := int(header.TagPayloadOffset)      //nolint: gosec // Parse bounds offsets to the int range
+
+	if tsOffset < header.Size() || tsOffset > len(data) {
+		return NumericBlobParts{}, errs.ErrInvalidTimestampPayloadOffset
+	}
+
+	if valOffset < tsOffset || valOffset > len(data) {
+		return NumericBlobParts{}, errs.ErrInvalidValuePayloadOffset
+	}
+
+	if tagOffset < valOffset || tagOffset > len(data) {
+		return NumericBlobParts{}, errs.ErrInvalidTagPayloadOffset
+	}
+
+	head := data[:tsOffset:tsOffset]
+	checksum := xxhash.Sum64(head)
+
+	parts := NumericBlobParts{
+		Head:       head,
+		Timestamps: newNumericPart(NumericPartTimestamps, checksum, data[tsOffset:valOffset]),
+		Values:     newNumericPart(NumericPartValues, checksum, data[valOffset:tagOffset]),
+	}
+
+	if header.Flag.HasTag() {
+		parts.Tags = newNumericPart(NumericPartTags, checksum, data[tagOffset:])
+	}
+
+	return parts, nil
+}
+
+// Join reassembles the parts into a contiguous encoded blob.
+//
+// Returns:
+//   - []byte: Newly allocated blob, byte-identical to the blob the parts were split from
+//   - error: ErrInvalidBlobPart if the parts are malformed or do not belong together
+func (p NumericBlobParts) Join() ([]byte, error) {
+	_, payloads, err := p.payloads(true)
+	if err != nil {
+		return nil, err
+	}
+
+	size := len(p.Head) + len(payloads.tsPayload) + len(payloads.valPayload) + len(payloads.tagPayload)
+	data := make([]byte, 0, size)
+	data = append(data, p.Head...)
+	data = append(data, payloads.tsPayload...)
+	data = append(data, payloads.valPayload...)
+	data = append(data, payloads.tagPayload...)
+
+	return data, nil
+}
+
+// BlobID computes the identity of the blob the parts were split from, without
+// joining them (see NumericBlobID).
+//
+// Returns:
+//   - uint64: Deterministic blob identity, equal to NumericBlobID of the joined blob
+//   - error: ErrInvalidBlobPart if the parts are malformed or do not belong together
+func (p NumericBlobParts) BlobID() (uint64, error) {
+	_, payloads, err := p.payloads(true)
+	if err != nil {
+		return 0, err
+	}
+
+	return computeBlobID(p.Head, payloads.tsPayload, payloads.valPayload, payloads.tagPayload), nil
+}
+
+// NewNumericDecoderFromParts creates a NumericDecoder for a blob in the columnar
+// external layout (see NumericBlobParts).
+//
+// The link headers are validated against Head, and the payload sections are
+// referenced from the parts without being copied.
+//
+// The Values part may be omitted, so storage systems can serve timestamp, tag
+// and index queries from the hot parts before fetching the value column. The
+// decoded blob then has no values: value reads find none, and its ValuesErr
+// reports the missing part.
+//
+// Parameters:
+//   - parts: Head and column parts produced by SplitNumericBlob or FinishParts,
+//     optionally without Values
+//   - opts: Optional decoder options (e.g., WithValueTransform)
+//
+// Returns:
+//   - *NumericDecoder: New decoder instance ready for decoding
+//   - error: Header parsing error, or ErrInvalidBlobPart if the parts are
+//     malformed or do not belong together
+//
+// Example:
+//
+//	hot := blob.NumericBlobParts{Head: head, Timestamps: timestamps, Tags: tags}
+//	decoder, err := blob.NewNumericDecoderFromParts(hot)
+//	// decoded blob serves TimestampAt, Len, TagAt; fetch parts.Values for values
+func NewNumericDecoderFromParts(parts NumericBlobParts, opts ...NumericDecoderOption) (*NumericDecoder, error) {
+	header, payloads, err := parts.payloads(false)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := &NumericDecoder{
+		data:          parts.Head,
+		rawPayloads:   &payloads,
+		valuesMissing: parts.Values == nil,
+	}
+
+	if err := options.Apply(decoder, opts...); err != nil {
+		return nil, err
+	}
+
+	decoder.engine = header.Flag.GetEndianEngine()
+	decoder.metricCount = int(header.MetricCount)
+	decoder.header = &header
+
+	return decoder, nil
+}
+
+// payloads parses the head header, validates every column part against it and
+// returns the compressed payload sections. Without requireValues, a nil Values
+// part is accepted and its payload left nil.
+func (p NumericBlobParts) payloads(requireValues bool) (section.NumericHeader, decodedPayloads, error) {
+	header, err := section.ParseNumericHeader(p.Head)
+	if err != nil {
+		return section.NumericHeader{}, decodedPayloads{}, err
+	}
+
+	if int(header.TimestampPayloadOffset) != len(p.Head) {
+		return section.NumericHeader{}, decodedPayloads{}, fmt.Errorf("%w: head size %d does not match timestamp payload offset %d",
+			errs.ErrInvalidBlobPart, len(p.Head), header.TimestampPayloadOffset)
+	}
+
+	checksum := xxhash.Sum64(p.Head)
+
+	var payloads decodedPayloads
+
+	payloads.tsPayload, err = parseNumericPart(p.Timestamps, NumericPartTimestamps, checksum)
+	if err != nil {
+		return section.NumericHeader{}, decodedPayloads{}, err
+	}
+
+	if p.Values != nil || requireValues {
+		payloads.valPayload, err = parseNumericPart(p.Values, NumericPartValues, checksum)
+		if err != nil {
+			return section.NumericHeader{}, decodedPayloads{}, err
+		}
+	}
+
+	if header.Flag.HasTag() {
+		payloads.tagPayload, err = parseNumericPart(p.Tags, NumericPartTags, checksum)
+		if err != nil {
+			return section.NumericHeader{}, decodedPayloads{}, err
+		}
+	} else if p.Tags != nil {
+		return section.NumericHeader{}, decodedPayloads{}, fmt.Errorf("%w: tags part present but blob has no tags", errs.ErrInvalidBlobPart)
+	}
+
+	// The header offsets must describe the same layout as the parts, so that
+	// Join reproduces the original blob.
+	valOffset := int(header.TimestampPayloadOffset) + len(payloads.tsPayload)
+	if int(header.ValuePayloadOffset) != valOffset {
+		return section.NumericHeader{}, decodedPayloads{}, fmt.Errorf("%w: timestamps part size does not match header offsets", errs.ErrInvalidBlobPart)
+	}
+
+	if p.Values != nil && int(header.TagPayloadOffset) != valOffset+len(payloads.valPayload) {
+		return section.NumericHeader{}, decodedPayloads{}, fmt.Errorf("%w: values part size does not match header offsets", errs.ErrInvalidBlobPart)
+	}
+
+	return header, payloads, nil
+}
+
+// newNumericPart builds a column part: link header followed by a copy of payload.
+func newNumericPart(kind NumericPartKind, checksum uint64, payload []byte) []byte {
+	part := make([]byte, NumericPartHeaderSize+len(payload))
+	binary.LittleEndian.PutUint16(part[0:2], numericPartMagic)
+	part[2] = byte(kind)
+	binary.LittleEndian.PutUint32(part[4:8], uint32(len(payload))) //nolint: gosec
+	binary.LittleEndian.PutUint64(part[8:16], checksum)
+	copy(part[NumericPartHeaderSize:], payload)
+
+	return part
+}
+
+// parseNumericPart validates the link header of a column part and returns its payload.
+func parseNumericPart(part []byte, kind NumericPartKind, checksum uint64) ([]byte, error) {
+	if len(part) < NumericPartHeaderSize {
+		return nil, fmt.Errorf("%w: part kind %d is missing or too short", errs.ErrInvalidBlobPart, kind)
+	}
+
+	if binary.LittleEndian.Uint16(part[0:2]) != numericPartMagic {
+		return nil, fmt.Errorf("%w: part kind %d has bad magic", errs.ErrInvalidBlobPart, kind)
+	}
+
+	if NumericPartKind(part[2]) != kind || part[3] != 0 {
+		return nil, fmt.Errorf("%w: expected part kind %d, got %d", errs.ErrInvalidBlobPart, kind, part[2])
+	}
+
+	if uint64(binary.LittleEndian.Uint32(part[4:8])) != uint64(len(part)-NumericPartHeaderSize) {
+		return nil, fmt.Errorf("%w: part kind %d payload length mismatch", errs.ErrInvalidBlobPart, kind)
+	}
+
+	if binary.LittleEndian.Uint64(part[8:16]) != checksum {
+		return nil, fmt.Errorf("%w: part kind %d does not belong to this head", errs.ErrInvalidBlobPart, kind)
+	}
+
+	return part[NumericPartHeaderSize:], nil
+}
+
+// NewNumericBlobFromParts assembles a read-only NumericBlob from separately stored
+// sections, without concatenating them into a contiguous blob and re-parsing it.
+//
+// The payloads are the sections exactly as stored in an encoded blob, i.e. still
+// compressed with the codecs recorded in the header; codec None payloads are used
+// in place. The index entries carry absolute offsets and lengths within the
+// decompressed payloads, as produced by decoding (shared timestamp references are
+// expected to be resolved already). The entries are copied.
+//
+// Blobs with a metadata section (see NumericFlag.HasMetadata) need it to
+// reconstruct their tags and values; assemble them with
+// NewNumericBlobFromPartsWithMetadata.
+//
+// Parameters:
+//   - header: Parsed blob header
+//   - index: Index entries, one per metric (sorted by MetricID for V2 layout)
+//   - tsPayload: Timestamp payload section
+//   - valPayload: Value payload section
+//   - tagPayload: Tag payload section (ignored if the header has no tags)
+//
+// Returns:
+//   - NumericBlob: Queryable blob referencing the given sections
+//   - error: ErrInvalidBlobPart for inconsistent sections, ErrInvalidMetadata if
+//     the header declares a metadata section, ErrInvalidIndexOffsets for entries
+//     outside their payloads, or decompression errors
+func NewNumericBlobFromParts(
+	header section.NumericHeader,
+	index []section.NumericIndexEntry,
+	tsPayload, valPayload, tagPayload []byte,
+) (NumericBlob, error) {
+	return NewNumericBlobFromPartsWithMetadata(header, nil, index, tsPayload, valPayload, tagPayload)
+}
+
+// NewNumericBlobFromPartsWithMetadata is NewNumericBlobFromParts for blobs that
+// may carry a metadata section, supplied as stored in the encoded blob.
+//
+// The metadata determines the tag payload compression, the tag layout, the
+// timestamp unit, value precision and extension value decoding, and the values
+// of metrics stored as deltas against a reference metric, which are
+// reconstructed here as by Decode.
+//
+// Parameters:
+//   - header: Parsed blob header
+//   - metadata: Metadata section bytes, nil if the header has no metadata section
+//   - index: Index entries, one per metric (sorted by MetricID for V2 layout)
+//   - tsPayload: Timestamp payload section
+//   - valPayload: Value payload section
+//   - tagPayload: Tag payload section (ignored if the header has no tags)
+//
+// Returns:
+//   - NumericBlob: Queryable blob referencing the given sections
+//   - error: ErrInvalidBlobPart for inconsistent sections, ErrInvalidMetadata for
+//     missing, unexpected or malformed metadata, ErrInvalidIndexOffsets for
+//     entries outside their payloads, ErrInvalidMetricReference for unresolvable
+//     reference metrics, or decompression errors
+//
+// Example:
+//
+//	// metadata holds the metadata section of the encoded blob, stored next to its index
+//	b, err := blob.NewNumericBlobFromPartsWithMetadata(header, metadata, index, ts, val, tags)
+func NewNumericBlobFromPartsWithMetadata(
+	header section.NumericHeader,
+	metadata []byte,
+	index []section.NumericIndexEntry,
+	tsPayload, valPayload, tagPayload []byte,
+) (NumericBlob, error) {
+	if int(header.MetricCount) != len(index) {
+		return NumericBlob{}, fmt.Errorf("%w: header declares %d metrics, index has %d entries",
+			errs.ErrInvalidBlobPart, header.MetricCount, len(index))
+	}
+
+	switch {
+	case header.Flag.HasMetadata() && metadata == nil:
+		return NumericBlob{}, fmt.Errorf("%w: header declares a metadata section, none supplied", errs.ErrInvalidMetadata)
+	case !header.Flag.HasMetadata() && metadata != nil:
+		return NumericBlob{}, fmt.Errorf("%w: metadata supplied, header declares no metadata section", errs.ErrInvalidMetadata)
+	}
+
+	if !header.Flag.HasTag() {
+		tagPayload = nil
+	}
+
+	d := &NumericDecoder{
+		data:        metadata,
+		metricCount: len(index),
+		engine:      header.Flag.GetEndianEngine(),
+		header:      &header,
+	}
+
+	parsed, size, err := d.parseMetadata(0)
+	if err != nil {
+		return NumericBlob{}, err
+	}
+	if size != len(metadata) {
+		return NumericBlob{}, fmt.Errorf("%w: %d trailing bytes after the metadata section", errs.ErrInvalidMetadata, len(metadata)-size)
+	}
+
+	blob := d.newBlob()
+	if err := d.setMetadata(&blob, parsed); err != nil {
+		return NumericBlob{}, err
+	}
+
+	payloads, err := d.decompressPayloads(decodedPayloads{
+		tsPayload:  tsPayload,
+		valPayload: valPayload,
+		tagPayload: tagPayload,
+	}, tagCompression(blob.metadata))
+	if err != nil {
+		return NumericBlob{}, err
+	}
+
+	entries := slices.Clone(index)
+	if err := validatePartIndex(entries, header.Flag.IsIndexSorted(), payloads); err != nil {
+		return NumericBlob{}, err
+	}
+
+	blob.tsPayload = payloads.tsPayload
+	blob.valPayload = payloads.valPayload
+	blob.tagPayload = payloads.tagPayload
+
+	if blob.valEncType == format.TypeALP {
+		if err := validateALPColumns(blob.valPayload, entries, d.engine); err != nil {
+			return NumericBlob{}, err
+		}
+	}
+
+	if header.Flag.HasSharedTimestamps() {
+		d.buildSharedTsCache(&blob, entries)
+	}
+
+	blob.index = newNumericIndex(header.Flag.IsIndexSorted(), entries, nil)
+
+	if err := d.resolveMetricReferences(&blob); err != nil {
+		return NumericBlob{}, err
+	}
+
+	return blob, nil
+}
+
+// validatePartIndex checks that every entry lies within the decompressed payloads,
+// that metric IDs are unique, and that V2 entries are sorted by MetricID.
+func validatePartIndex(entries []section.NumericIndexEntry, sorted bool, payloads decodedPayloads) error {
+	inRange := func(offset, length, size int) bool {
+		return offset >= 0 && length >= 0 && offset <= size && length <= size-offset
+	}
+
+	seen := make(map[uint64]struct{}, len(entries))
+	for i := range entries {
+		e := &entries[i]
+		if e.Count <= 0 ||
+			!inRange(e.TimestampOffset, e.TimestampLength, len(payloads.tsPayload)) ||
+			!inRange(e.ValueOffset, e.ValueLength, len(payloads.valPayload)) ||
+			!inRange(e.TagOffset, e.TagLength, len(payloads.tagPayload)) {
+			return fmt.Errorf("%w: metric ID 0x%016x", errs.ErrInvalidIndexOffsets, e.MetricID)
+		}
+
+		if sorted && i > 0 && e.MetricID <= entries[i-1].MetricID {
+			return fmt.Errorf("%w: V2 index entries must be sorted by unique MetricID", errs.ErrInvalidBlobPart)
+		}
+
+		if _, dup := seen[e.MetricID]; dup {
+			return fmt.Errorf("%w: duplicate metric ID 0x%016x", errs.ErrInvalidBlobPart, e.MetricID)
+		}
+		seen[e.MetricID] = struct{}{}
+	}
+
+	return nil
+}
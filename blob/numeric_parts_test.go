@@ -0,0 +1,365 @@
+package blob
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+func encodePartsTestBlob(t *testing.T, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, opts...)
+	require.NoError(t, err)
+
+	for id := uint64(1); id <= 3; id++ {
+		require.NoError(t, encoder.StartMetricID(id, 10))
+		for i := range 10 {
+			ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(id)*100+float64(i)*0.25, "tag"))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericBlobParts_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Default", opts: nil},
+		{name: "Tags", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "GorillaZstd", opts: []NumericEncoderOption{WithValueEncoding(format.TypeGorilla), WithValueCompression(format.CompressionZstd)}},
+		{name: "BigEndianV2", opts: []NumericEncoderOption{WithBigEndian(), WithBlobLayoutV2(), WithSharedTimestamps()}},
+		{name: "Precision", opts: []NumericEncoderOption{WithValuePrecision(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, tt.opts...)
+
+			parts, err := SplitNumericBlob(data)
+			require.NoError(t, err)
+			require.Equal(t, NumericPartTimestamps, NumericPartKind(parts.Timestamps[2]))
+			require.Equal(t, NumericPartValues, NumericPartKind(parts.Values[2]))
+
+			joined, err := parts.Join()
+			require.NoError(t, err)
+			require.Equal(t, data, joined)
+
+			expectedDecoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			expected, err := expectedDecoder.Decode()
+			require.NoError(t, err)
+
+			decoder, err := NewNumericDecoderFromParts(parts)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			require.Equal(t, expected.HasTag(), blob.HasTag())
+			for id := uint64(1); id <= 3; id++ {
+				require.Equal(t, expected.Len(id), blob.Len(id))
+				for i, dp := range expected.All(id) {
+					got, ok := blob.ValueAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, dp.Val, got)
+
+					ts, ok := blob.TimestampAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, dp.Ts, ts)
+
+					tag, _ := blob.TagAt(id, i)
+					require.Equal(t, dp.Tag, tag)
+				}
+			}
+		})
+	}
+}
+
+func TestNumericEncoder_FinishParts(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, WithTagsEnabled(true))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricName("cpu.usage", 2))
+	require.NoError(t, encoder.AddDataPoint(startTime.UnixMicro(), 1.5, "a"))
+	require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Second).UnixMicro(), 2.5, "b"))
+	require.NoError(t, encoder.EndMetric())
+
+	parts, err := encoder.FinishParts()
+	require.NoError(t, err)
+	require.NotNil(t, parts.Tags)
+
+	decoder, err := NewNumericDecoderFromParts(parts, WithValueTransform(func(_ uint64, v float64) float64 { return v * 2 }))
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	values := make([]float64, 0, 2)
+	for v := range blob.AllValuesByName("cpu.usage") {
+		values = append(values, v)
+	}
+	require.Equal(t, []float64{3, 5}, values)
+}
+
+func TestNumericBlobParts_Invalid(t *testing.T) {
+	data := encodePartsTestBlob(t, WithTagsEnabled(true))
+	other := encodePartsTestBlob(t, WithTagsEnabled(true), WithValueEncoding(format.TypeGorilla))
+
+	split := func(t *testing.T, data []byte) NumericBlobParts {
+		t.Helper()
+		parts, err := SplitNumericBlob(data)
+		require.NoError(t, err)
+
+		return parts
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(p *NumericBlobParts)
+	}{
+		{name: "MissingTags", mutate: func(p *NumericBlobParts) { p.Tags = nil }},
+		{name: "SwappedKinds", mutate: func(p *NumericBlobParts) { p.Timestamps, p.Values = p.Values, p.Timestamps }},
+		{name: "BadMagic", mutate: func(p *NumericBlobParts) { p.Values[0] ^= 0xFF }},
+		{name: "TruncatedPayload", mutate: func(p *NumericBlobParts) { p.Values = p.Values[:len(p.Values)-1] }},
+		{name: "ForeignPart", mutate: func(p *NumericBlobParts) { p.Values = split(t, other).Values }},
+		{name: "HeadTooLong", mutate: func(p *NumericBlobParts) { p.Head = append(p.Head, 0) }},
+		{name: "LengthMismatch", mutate: func(p *NumericBlobParts) {
+			binary.LittleEndian.PutUint32(p.Timestamps[4:8], 1)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := split(t, data)
+			parts.Head = append([]byte(nil), parts.Head...)
+			tt.mutate(&parts)
+
+			_, err := NewNumericDecoderFromParts(parts)
+			require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+
+			_, err = parts.Join()
+			require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+		})
+	}
+
+	t.Run("InvalidBlob", func(t *testing.T) {
+		_, err := SplitNumericBlob(data[:8])
+		require.Error(t, err)
+	})
+
+	t.Run("JoinMissingValues", func(t *testing.T) {
+		parts := split(t, data)
+		parts.Values = nil
+
+		_, err := parts.Join()
+		require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+		_, err = parts.BlobID()
+		require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+	})
+}
+
+func TestNumericBlobParts_WithoutValues(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Raw", opts: []NumericEncoderOption{WithValueEncoding(format.TypeRaw)}},
+		{name: "GorillaZstd", opts: []NumericEncoderOption{WithValueEncoding(format.TypeGorilla), WithValueCompression(format.CompressionZstd)}},
+		{name: "ALPV2", opts: []NumericEncoderOption{WithValueEncoding(format.TypeALP), WithBlobLayoutV2()}},
+		{name: "Precision", opts: []NumericEncoderOption{WithValuePrecision(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, append(tt.opts, WithTagsEnabled(true))...)
+			parts, err := SplitNumericBlob(data)
+			require.NoError(t, err)
+			parts.Values = nil
+
+			expectedDecoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			expected, err := expectedDecoder.Decode()
+			require.NoError(t, err)
+			require.NoError(t, expected.ValuesErr())
+
+			decoder, err := NewNumericDecoderFromParts(parts)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+			require.ErrorIs(t, blob.ValuesErr(), errs.ErrInvalidBlobPart)
+
+			require.ElementsMatch(t, expected.MetricIDs(), blob.MetricIDs())
+			for id := uint64(1); id <= 3; id++ {
+				require.Equal(t, expected.Len(id), blob.Len(id))
+				require.Equal(t, slices.Collect(expected.AllTimestamps(id)), slices.Collect(blob.AllTimestamps(id)))
+				require.Equal(t, slices.Collect(expected.AllTags(id)), slices.Collect(blob.AllTags(id)))
+
+				_, ok := blob.ValueAt(id, 0)
+				require.False(t, ok)
+				require.Empty(t, slices.Collect(blob.AllValues(id)))
+				_, ok = blob.RawValueBytes(id)
+				require.False(t, ok)
+				_, ok = blob.ValuesAt(id, []int{0, 1})
+				require.False(t, ok)
+				for range blob.All(id) {
+					require.Fail(t, "data point without values")
+				}
+				blob.ForEachValues(id, func(int, float64) bool {
+					require.Fail(t, "value read without values")
+					return false
+				})
+				_, _ = blob.MaterializeMetric(id)
+			}
+			_ = blob.Materialize()
+
+			_, ok := blob.BlobID()
+			require.False(t, ok)
+			_, err = blob.Trim(time.Unix(0, 0), time.Now().Add(time.Hour))
+			require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+		})
+	}
+}
+
+func TestNewNumericBlobFromParts(t *testing.T) {
+	sections := func(t *testing.T, data []byte) (section.NumericHeader, []byte, []section.NumericIndexEntry, []byte, []byte, []byte) {
+		t.Helper()
+
+		header, err := section.ParseNumericHeader(data)
+		require.NoError(t, err)
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		decoded, err := decoder.Decode()
+		require.NoError(t, err)
+
+		_, namesEnd, err := decoder.parseMetricNames()
+		require.NoError(t, err)
+		_, metadataEnd, err := decoder.parseMetadata(namesEnd)
+		require.NoError(t, err)
+		var metadata []byte
+		if header.Flag.HasMetadata() {
+			metadata = data[namesEnd:metadataEnd]
+		}
+
+		var index []section.NumericIndexEntry
+		decoded.index.ForEach(func(e section.NumericIndexEntry) bool {
+			index = append(index, e)
+			return true
+		})
+
+		ts := data[header.TimestampPayloadOffset:header.ValuePayloadOffset]
+		val := data[header.ValuePayloadOffset:header.TagPayloadOffset]
+		tag := data[header.TagPayloadOffset:]
+
+		return header, metadata, index, ts, val, tag
+	}
+
+	requireSameBlob := func(t *testing.T, data []byte, blob NumericBlob) {
+		t.Helper()
+
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		expected, err := decoder.Decode()
+		require.NoError(t, err)
+
+		require.Equal(t, expected.StartTime(), blob.StartTime())
+		require.Equal(t, expected.TimestampUnit(), blob.TimestampUnit())
+		require.ElementsMatch(t, expected.MetricIDs(), blob.MetricIDs())
+		for _, id := range expected.MetricIDs() {
+			require.Equal(t, slices.Collect(expected.AllTimestamps(id)), slices.Collect(blob.AllTimestamps(id)))
+			require.Equal(t, slices.Collect(expected.AllValues(id)), slices.Collect(blob.AllValues(id)))
+			require.Equal(t, slices.Collect(expected.AllTags(id)), slices.Collect(blob.AllTags(id)))
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Default", opts: nil},
+		{name: "TagsZstd", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTimestampCompression(format.CompressionZstd)}},
+		{name: "V2SharedTimestamps", opts: []NumericEncoderOption{WithBlobLayoutV2(), WithSharedTimestamps(), WithValueEncoding(format.TypeChimp)}},
+		{name: "Precision", opts: []NumericEncoderOption{WithValuePrecision(2), WithTimestampUnit(format.TimeUnitMillisecond)}},
+		{name: "TagCompressionLZ4", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTagCompression(format.CompressionLZ4)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, tt.opts...)
+			header, metadata, index, ts, val, tag := sections(t, data)
+
+			if metadata == nil {
+				blob, err := NewNumericBlobFromParts(header, index, ts, val, tag)
+				require.NoError(t, err)
+				requireSameBlob(t, data, blob)
+			}
+
+			blob, err := NewNumericBlobFromPartsWithMetadata(header, metadata, index, ts, val, tag)
+			require.NoError(t, err)
+			requireSameBlob(t, data, blob)
+		})
+	}
+
+	t.Run("MetricReferences", func(t *testing.T) {
+		start := time.Now()
+		encoder, err := NewNumericEncoder(start, WithMetricReferences())
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(1, 3))
+		require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{10, 20, 30}, nil))
+		require.NoError(t, encoder.EndMetric())
+		require.NoError(t, encoder.StartMetricIDWithReference(2, 1, 3))
+		require.NoError(t, encoder.AddDataPoints([]int64{1, 2, 3}, []float64{11, 21, 32}, nil))
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		header, metadata, index, ts, val, tag := sections(t, data)
+		require.NotNil(t, metadata)
+		blob, err := NewNumericBlobFromPartsWithMetadata(header, metadata, index, ts, val, tag)
+		require.NoError(t, err)
+		requireSameBlob(t, data, blob)
+		require.Equal(t, []float64{11, 21, 32}, slices.Collect(blob.AllValues(2)))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		data := encodePartsTestBlob(t, WithBlobLayoutV2(), WithValueCompression(format.CompressionNone))
+		header, _, index, ts, val, tag := sections(t, data)
+
+		_, err := NewNumericBlobFromParts(header, index[:2], ts, val, tag)
+		require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+
+		unsorted := slices.Clone(index)
+		unsorted[0], unsorted[1] = unsorted[1], unsorted[0]
+		_, err = NewNumericBlobFromParts(header, unsorted, ts, val, tag)
+		require.ErrorIs(t, err, errs.ErrInvalidBlobPart)
+
+		_, err = NewNumericBlobFromParts(header, index, ts, val[:len(val)-1], tag)
+		require.ErrorIs(t, err, errs.ErrInvalidIndexOffsets)
+
+		_, err = NewNumericBlobFromPartsWithMetadata(header, []byte{0}, index, ts, val, tag)
+		require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+
+		withMetadata := encodePartsTestBlob(t, WithValuePrecision(2))
+		header, metadata, index, ts, val, tag := sections(t, withMetadata)
+		_, err = NewNumericBlobFromParts(header, index, ts, val, tag)
+		require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+
+		_, err = NewNumericBlobFromPartsWithMetadata(header, append(slices.Clone(metadata), 0), index, ts, val, tag)
+		require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+	})
+}
@@ -0,0 +1,108 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// rawValueSize is the size of a raw-encoded value in bytes.
+const rawValueSize = 8
+
+// ValueAlignment returns the byte boundary every metric's value section starts
+// at within the value payload: the alignment set by WithValueAlignment, 8 for
+// blobs encoded with WithWordAlignedOffsets, and 1 for unpadded blobs.
+func (b NumericBlob) ValueAlignment() int {
+	unit, ok := b.metadata.Get(section.MetadataKeyOffsetUnit)
+	if !ok || len(unit) != 1 {
+		return 1
+	}
+
+	return int(unit[0])
+}
+
+// RawValueBytes returns the raw-encoded value section of the given metric ID:
+// one IEEE 754 float64 per data point in the payload byte order (see PayloadEngine),
+// without padding. Combined with WithValueAlignment, the section starts at a
+// ValueAlignment boundary of the value payload, so vectorized analytics can
+// load values directly without decoding them.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - []byte: The value section, shared with the blob (must not be modified)
+//   - bool: false if the metric is not found, the blob does not use raw value
+//     encoding, or the metric's values are transformed at decode time
+//     (reference-delta metrics or a value transform)
+//
+// Example:
+//
+//	if raw, ok := blob.RawValueBytes(metricID); ok && blob.ValueAlignment() >= 32 {
+//	    sum := simdSumFloat64(raw) // aligned loads over raw
+//	}
+func (b NumericBlob) RawValueBytes(metricID uint64) ([]byte, bool) {
+	if b.valEncType != format.TypeRaw || b.valTransform != nil {
+		return nil, false
+	}
+
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return nil, false
+	}
+
+	if _, ok := b.refValCache[entry.MetricID]; ok {
+		return nil, false
+	}
+
+	size := entry.Count * rawValueSize
+	if size > entry.ValueLength {
+		return nil, false
+	}
+
+	valBytes, ok := safeSlice(b.valPayload, entry.ValueOffset, size)
+	if !ok {
+		return nil, false
+	}
+
+	return valBytes[:size:size], true
+}
+
+// RawTimestampPayload returns the encoded timestamp section of the given metric
+// ID exactly as stored, after decompression, together with its encoding, so
+// specialist consumers can run custom decoders or forward the bytes elsewhere
+// without re-encoding.
+//
+// The section holds Len(metricID) timestamps in the blob's timestamp unit (see
+// TimestampUnit) and payload byte order (see PayloadEngine). Blobs encoded with
+// WithWordAlignedOffsets or WithValueAlignment pad the section with trailing
+// zero bytes, so decoders must stop after Len(metricID) timestamps. Metrics
+// sharing a timestamp sequence (WithSharedTimestamps) return the same bytes.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - []byte: The encoded section, shared with the blob (must not be modified)
+//   - format.EncodingType: The timestamp encoding of the section
+//   - error: ErrMetricNotFound if the blob has no such metric
+//
+// Example:
+//
+//	payload, enc, err := blob.RawTimestampPayload(metricID)
+//	if err != nil {
+//	    return err
+//	}
+//	forward(metricID, enc, blob.Len(metricID), payload)
+func (b NumericBlob) RawTimestampPayload(metricID uint64) ([]byte, format.EncodingType, error) {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return nil, b.tsEncType, fmt.Errorf("%w: metric ID 0x%016x", errs.ErrMetricNotFound, metricID)
+	}
+
+	end := entry.TimestampOffset + entry.TimestampLength
+
+	return b.tsPayload[entry.TimestampOffset:end:end], b.tsEncType, nil
+}
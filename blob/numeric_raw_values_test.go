@@ -0,0 +1,156 @@
+package blob
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// encodeRawValuesBlob encodes three metrics of different lengths with raw values.
+func encodeRawValuesBlob(t *testing.T, opts ...NumericEncoderOption) NumericBlob {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0).UTC()
+	opts = append([]NumericEncoderOption{
+		WithValueEncoding(format.TypeRaw),
+		WithValueCompression(format.CompressionNone),
+	}, opts...)
+	encoder, err := NewNumericEncoder(start, opts...)
+	require.NoError(t, err)
+	for m, count := range []int{3, 10, 7} {
+		require.NoError(t, encoder.StartMetricID(uint64(m+1), count))
+		for i := range count {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), float64(m*100+i)+0.25, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func TestNumericBlob_ValueAlignment(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+		want int
+	}{
+		{name: "Unpadded", want: 1},
+		{name: "WordAligned", opts: []NumericEncoderOption{WithWordAlignedOffsets()}, want: 8},
+		{name: "Align32", opts: []NumericEncoderOption{WithValueAlignment(32)}, want: 32},
+		{name: "Align64", opts: []NumericEncoderOption{WithValueAlignment(64)}, want: 64},
+		// The coarser alignment wins regardless of option order.
+		{name: "Align64ThenWord", opts: []NumericEncoderOption{WithValueAlignment(64), WithWordAlignedOffsets()}, want: 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := encodeRawValuesBlob(t, tt.opts...)
+			require.Equal(t, tt.want, blob.ValueAlignment())
+
+			for m, count := range []int{3, 10, 7} {
+				metricID := uint64(m + 1)
+				entry, ok := blob.index.GetByID(metricID)
+				require.True(t, ok)
+				require.Zero(t, entry.ValueOffset%tt.want)
+
+				raw, ok := blob.RawValueBytes(metricID)
+				require.True(t, ok)
+				require.Len(t, raw, count*8)
+
+				values := make([]float64, count)
+				for i := range values {
+					values[i] = math.Float64frombits(blob.Engine().Uint64(raw[i*8:]))
+				}
+				require.Equal(t, slices.Collect(blob.AllValues(metricID)), values)
+			}
+		})
+	}
+}
+
+func TestNumericBlob_RawValueBytes_Unavailable(t *testing.T) {
+	blob := encodeRawValuesBlob(t)
+	_, ok := blob.RawValueBytes(42)
+	require.False(t, ok)
+
+	gorilla := encodeRawValuesBlob(t, WithValueEncoding(format.TypeGorilla))
+	_, ok = gorilla.RawValueBytes(1)
+	require.False(t, ok)
+	require.Equal(t, 1, gorilla.ValueAlignment())
+}
+
+func TestWithValueAlignment_Invalid(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	_, err := NewNumericEncoder(start, WithValueAlignment(16))
+	require.Error(t, err)
+
+	_, err = NewNumericEncoder(start, WithValueEncoding(format.TypeGorilla), WithValueAlignment(32))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+
+	_, err = NewNumericEncoder(start, WithValueAlignment(32), WithSharedTimestamps())
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+
+	_, err = NewInt64Encoder(start, WithValueAlignment(64))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestNumericBlob_RawTimestampPayload(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Raw", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeRaw)}},
+		{name: "Delta", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeDelta)}},
+		{name: "DeltaPacked", opts: []NumericEncoderOption{
+			WithTimestampEncoding(format.TypeDeltaPacked),
+			WithTimestampCompression(format.CompressionZstd),
+		}},
+		{name: "Padded", opts: []NumericEncoderOption{WithTimestampEncoding(format.TypeDelta), WithValueAlignment(64)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blob := encodeRawValuesBlob(t, tt.opts...)
+
+			for _, metricID := range blob.MetricIDs() {
+				payload, enc, err := blob.RawTimestampPayload(metricID)
+				require.NoError(t, err)
+				require.Equal(t, blob.tsEncType, enc)
+
+				// The payload decodes to the blob's timestamps on its own.
+				count := blob.Len(metricID)
+				got := make([]int64, count)
+				require.Equal(t, count, blob.decodeTimestampsSlice(payload, count, got))
+				require.Equal(t, slices.Collect(blob.AllTimestamps(metricID)), got)
+			}
+		})
+	}
+
+	t.Run("RawLayout", func(t *testing.T) {
+		blob := encodeRawValuesBlob(t, WithTimestampEncoding(format.TypeRaw))
+		payload, enc, err := blob.RawTimestampPayload(2)
+		require.NoError(t, err)
+		require.Equal(t, format.TypeRaw, enc)
+		require.Len(t, payload, 10*8)
+		require.Equal(t, int64(1700000000000000+9), int64(blob.Engine().Uint64(payload[9*8:]))) //nolint: gosec
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		blob := encodeRawValuesBlob(t)
+		_, _, err := blob.RawTimestampPayload(42)
+		require.ErrorIs(t, err, errs.ErrMetricNotFound)
+	})
+}
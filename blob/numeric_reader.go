@@ -0,0 +1,202 @@
+package blob
+
+import (
+	"github.com/arloliu/mebo/section"
+)
+
+// NumericReader is a per-goroutine random-access handle on a NumericBlob.
+//
+// NumericBlob itself is immutable and safe for concurrent use, but its ValueAt and
+// TimestampAt methods keep no state between calls, so sequential codecs (Gorilla,
+// Chimp values; Delta, DeltaPacked timestamps) re-decode the column prefix on every
+// call. A NumericReader keeps its own scratch buffers holding the most recently
+// accessed value and timestamp columns, so repeated random access to the same
+// metric is O(1) after the first call. Random-access-friendly codecs (Raw, ALP)
+// are delegated to the blob directly.
+//
+// Readers are cheap to create and share no mutable state, so concurrent access
+// scales across cores without synchronization as long as every goroutine uses its
+// own reader.
+//
+// Note: A NumericReader is NOT thread-safe. Create one reader per goroutine.
+type NumericReader struct {
+	blob NumericBlob
+
+	values   []float64 // Decoded value column of valEntry
+	valEntry columnKey // Column currently held in values
+	valValid bool
+
+	timestamps []int64   // Decoded timestamp column of tsEntry
+	tsScratch  []int64   // Reader-owned buffer; timestamps may alias the blob's shared cache instead
+	tsEntry    columnKey // Column currently held in timestamps
+	tsValid    bool
+}
+
+// columnKey identifies a decoded payload column in a NumericReader.
+type columnKey struct {
+	metricID uint64
+	offset   int
+}
+
+// NewReader returns a new per-goroutine reader for random access to the blob.
+//
+// Returns:
+//   - *NumericReader: Reader with its own scratch state (not thread-safe)
+//
+// Example:
+//
+//	for w := range workers {
+//	    go func() {
+//	        reader := blob.NewReader() // one reader per goroutine
+//	        for i := range blob.Len(metricID) {
+//	            v, _ := reader.ValueAt(metricID, i)
+//	            _ = v
+//	        }
+//	    }()
+//	}
+func (b NumericBlob) NewReader() *NumericReader {
+	return &NumericReader{blob: b}
+}
+
+// ValueAt returns the value at the specified index for the given metric.
+// It returns the same results as NumericBlob.ValueAt.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//   - index: 0-based data point index within the blob
+//
+// Returns:
+//   - float64: The value at index
+//   - bool: false if the metric doesn't exist or index is out of bounds
+func (r *NumericReader) ValueAt(metricID uint64, index int) (float64, bool) {
+	entry, ok := r.blob.index.GetByID(metricID)
+	if !ok {
+		return 0, false
+	}
+
+	return r.valueAtFromEntry(entry, index)
+}
+
+// ValueAtByName returns the value at the specified index for the given metric name.
+// It returns the same results as NumericBlob.ValueAtByName.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//   - index: 0-based data point index within the blob
+//
+// Returns:
+//   - float64: The value at index
+//   - bool: false if the metric doesn't exist or index is out of bounds
+func (r *NumericReader) ValueAtByName(metricName string, index int) (float64, bool) {
+	entry, ok := r.blob.lookupMetricEntry(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return r.valueAtFromEntry(entry, index)
+}
+
+// TimestampAt returns the timestamp at the specified index for the given metric.
+// It returns the same results as NumericBlob.TimestampAt.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//   - index: 0-based data point index within the blob
+//
+// Returns:
+//   - int64: The timestamp at index
+//   - bool: false if the metric doesn't exist or index is out of bounds
+func (r *NumericReader) TimestampAt(metricID uint64, index int) (int64, bool) {
+	entry, ok := r.blob.index.GetByID(metricID)
+	if !ok {
+		return 0, false
+	}
+
+	return r.timestampAtFromEntry(entry, index)
+}
+
+// TimestampAtByName returns the timestamp at the specified index for the given metric name.
+// It returns the same results as NumericBlob.TimestampAtByName.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//   - index: 0-based data point index within the blob
+//
+// Returns:
+//   - int64: The timestamp at index
+//   - bool: false if the metric doesn't exist or index is out of bounds
+func (r *NumericReader) TimestampAtByName(metricName string, index int) (int64, bool) {
+	entry, ok := r.blob.lookupMetricEntry(metricName)
+	if !ok {
+		return 0, false
+	}
+
+	return r.timestampAtFromEntry(entry, index)
+}
+
+// valueAtFromEntry serves sequential value codecs from the cached column and
+// delegates random-access codecs to the blob.
+func (r *NumericReader) valueAtFromEntry(entry section.NumericIndexEntry, index int) (float64, bool) {
+	if r.blob.ValueCapabilities().RandomAccess {
+		return r.blob.valueAtFromEntry(entry, index)
+	}
+
+	if index < 0 || index >= entry.Count {
+		return 0, false
+	}
+
+	key := columnKey{metricID: entry.MetricID, offset: entry.ValueOffset}
+	if !r.valValid || r.valEntry != key {
+		if _, ok := safeSlice(r.blob.valPayload, entry.ValueOffset, entry.ValueLength); !ok {
+			return 0, false
+		}
+
+		if cap(r.values) < entry.Count {
+			r.values = make([]float64, entry.Count)
+		}
+		r.values = r.values[:r.blob.decodeEntryValues(entry, r.values[:entry.Count])]
+		r.valEntry, r.valValid = key, true
+	}
+
+	if index >= len(r.values) {
+		return 0, false
+	}
+
+	return r.values[index], true
+}
+
+// timestampAtFromEntry serves sequential timestamp codecs from the cached column
+// and delegates random-access codecs to the blob.
+func (r *NumericReader) timestampAtFromEntry(entry section.NumericIndexEntry, index int) (int64, bool) {
+	if r.blob.TimestampCapabilities().RandomAccess {
+		return r.blob.timestampAtFromEntry(entry, index)
+	}
+
+	if index < 0 || index >= entry.Count {
+		return 0, false
+	}
+
+	key := columnKey{offset: entry.TimestampOffset}
+	if !r.tsValid || r.tsEntry != key {
+		tsBytes, ok := safeSlice(r.blob.tsPayload, entry.TimestampOffset, entry.TimestampLength)
+		if !ok {
+			return 0, false
+		}
+
+		if cached, ok := r.blob.sharedTsCache[entry.TimestampOffset]; ok {
+			r.timestamps = cached
+		} else {
+			if cap(r.tsScratch) < entry.Count {
+				r.tsScratch = make([]int64, entry.Count)
+			}
+			r.timestamps = r.tsScratch[:r.blob.decodeTimestampsSlice(tsBytes, entry.Count, r.tsScratch[:entry.Count])]
+		}
+		r.tsEntry, r.tsValid = key, true
+	}
+
+	if index >= len(r.timestamps) {
+		return 0, false
+	}
+
+	return r.timestamps[index], true
+}
@@ -0,0 +1,127 @@
+package blob
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericReader_MatchesBlob(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Default", opts: nil},
+		{name: "RawValuesRawTimestamps", opts: []NumericEncoderOption{WithValueEncoding(format.TypeRaw), WithTimestampEncoding(format.TypeRaw)}},
+		{name: "GorillaDeltaPacked", opts: []NumericEncoderOption{WithValueEncoding(format.TypeGorilla), WithTimestampEncoding(format.TypeDeltaPacked)}},
+		{name: "ChimpV2Shared", opts: []NumericEncoderOption{WithValueEncoding(format.TypeChimp), WithBlobLayoutV2(), WithSharedTimestamps()}},
+		{name: "ALP", opts: []NumericEncoderOption{WithValueEncoding(format.TypeALP)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encodePartsTestBlob(t, tt.opts...)
+			decoder, err := NewNumericDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			reader := blob.NewReader()
+			// Interleave metrics to exercise column switching.
+			for _, i := range []int{9, 0, 5, 3, 9} {
+				for id := uint64(1); id <= 3; id++ {
+					expectedVal, ok := blob.ValueAt(id, i)
+					require.True(t, ok)
+					gotVal, ok := reader.ValueAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, expectedVal, gotVal)
+
+					expectedTs, ok := blob.TimestampAt(id, i)
+					require.True(t, ok)
+					gotTs, ok := reader.TimestampAt(id, i)
+					require.True(t, ok)
+					require.Equal(t, expectedTs, gotTs)
+				}
+			}
+
+			_, ok := reader.ValueAt(1, 10)
+			require.False(t, ok)
+			_, ok = reader.ValueAt(1, -1)
+			require.False(t, ok)
+			_, ok = reader.TimestampAt(1, 10)
+			require.False(t, ok)
+			_, ok = reader.ValueAt(99, 0)
+			require.False(t, ok)
+			_, ok = reader.TimestampAt(99, 0)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestNumericReader_ByNameAndTransform(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, WithValueEncoding(format.TypeGorilla))
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricName("cpu.usage", 3))
+	for i := range 3 {
+		require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i)+0.5, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data, WithValueTransform(func(_ uint64, v float64) float64 { return v * 10 }))
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	reader := blob.NewReader()
+	v, ok := reader.ValueAtByName("cpu.usage", 2)
+	require.True(t, ok)
+	require.Equal(t, 25.0, v)
+
+	ts, ok := reader.TimestampAtByName("cpu.usage", 1)
+	require.True(t, ok)
+	require.Equal(t, startTime.Add(time.Second).UnixMicro(), ts)
+
+	_, ok = reader.ValueAtByName("missing", 0)
+	require.False(t, ok)
+	_, ok = reader.TimestampAtByName("missing", 0)
+	require.False(t, ok)
+}
+
+func TestNumericReader_Concurrent(t *testing.T) {
+	data := encodePartsTestBlob(t, WithValueEncoding(format.TypeGorilla), WithBlobLayoutV2(), WithSharedTimestamps())
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mismatches atomic.Int64
+	for w := range 8 {
+		wg.Go(func() {
+			reader := blob.NewReader()
+			for round := range 100 {
+				id := uint64((w+round)%3 + 1)
+				for i := range blob.Len(id) {
+					expected, _ := blob.ValueAt(id, i)
+					got, ok := reader.ValueAt(id, i)
+					if !ok || got != expected {
+						mismatches.Add(1)
+					}
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	require.Zero(t, mismatches.Load())
+}
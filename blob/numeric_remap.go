@@ -0,0 +1,335 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// numericRemapLayout describes the sections of an encoded numeric blob that a
+// metric remap rewrites.
+type numericRemapLayout struct {
+	header      section.NumericHeader
+	names       []string         // Metric names in index order (nil if absent)
+	namesSize   int              // Byte size of the metric names payload
+	metadata    section.Metadata // Parsed metadata section (empty if absent)
+	indexOffset int              // Start of the index entries
+	indexEnd    int              // End of the index entries
+}
+
+// RemapMetricIDs rewrites the metric IDs of an encoded numeric blob according to
+// mapping, without touching the encoded payloads.
+//
+// Only the index entries (and the metric reference and metric metadata records)
+// are rewritten; IDs absent from mapping are kept. The mapping is applied
+// simultaneously, so IDs can be swapped. Blobs with a metric names payload are
+// rejected, because their IDs are derived from the names; use RenameMetrics for
+// those. V2 layout blobs store payloads in MetricID order, so the remapped IDs
+// must preserve that order.
+// Blobs written with WithSmallHeader are returned in the standard layout.
+//
+// Parameters:
+//   - data: Encoded numeric blob (not modified)
+//   - mapping: Old metric ID to new metric ID
+//
+// Returns:
+//   - []byte: Newly allocated blob with remapped metric IDs
+//   - error: ErrInvalidMetricID for zero target IDs, ErrInvalidMetricRemap for
+//     duplicate or reordered IDs, or blob parsing errors
+//
+// Example:
+//
+//	migrated, err := blob.RemapMetricIDs(data, map[uint64]uint64{oldCPU: newCPU})
+func RemapMetricIDs(data []byte, mapping map[uint64]uint64) ([]byte, error) {
+	data, err := standardNumericBlob(data)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := parseNumericRemapLayout(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if layout.names != nil {
+		return nil, fmt.Errorf("%w: blob has metric names, use RenameMetrics", errs.ErrInvalidMetricRemap)
+	}
+
+	for oldID, newID := range mapping {
+		if newID == 0 {
+			return nil, fmt.Errorf("%w: metric ID 0x%016x mapped to zero", errs.ErrInvalidMetricID, oldID)
+		}
+	}
+
+	return rewriteNumericIndex(data, layout, mapping, nil)
+}
+
+// RenameMetrics renames the metrics of an encoded numeric blob according to
+// mapping, without touching the encoded payloads.
+//
+// Metric IDs are the hashes of metric names, so renaming rewrites the IDs in the
+// index entries (and the metric reference and metric metadata records), plus the
+// metric names payload if the blob has one. Names absent from mapping are kept,
+// and the mapping is applied simultaneously. A blob without a names payload
+// cannot represent a hash collision, so renames that produce one are rejected.
+// V2 layout blobs store payloads in MetricID order, so the renamed IDs must
+// preserve that order.
+// Blobs written with WithSmallHeader are returned in the standard layout.
+//
+// Parameters:
+//   - data: Encoded numeric blob (not modified)
+//   - mapping: Old metric name to new metric name
+//
+// Returns:
+//   - []byte: Newly allocated blob with renamed metrics
+//   - error: ErrInvalidMetricName for empty names, ErrInvalidMetricRemap for
+//     duplicate or reordered metrics, or blob parsing errors
+//
+// Example:
+//
+//	migrated, err := blob.RenameMetrics(data, map[string]string{"cpu_usage": "cpu.usage"})
+func RenameMetrics(data []byte, mapping map[string]string) ([]byte, error) {
+	data, err := standardNumericBlob(data)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := parseNumericRemapLayout(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for oldName, newName := range mapping {
+		if newName == "" {
+			return nil, fmt.Errorf("%w: metric %q renamed to empty name", errs.ErrInvalidMetricName, oldName)
+		}
+	}
+
+	if layout.names == nil {
+		idMapping := make(map[uint64]uint64, len(mapping))
+		for oldName, newName := range mapping {
+			idMapping[hash.ID(oldName)] = hash.ID(newName)
+		}
+
+		return rewriteNumericIndex(data, layout, idMapping, nil)
+	}
+
+	names := make([]string, len(layout.names))
+	seen := make(map[string]struct{}, len(names))
+	for i, name := range layout.names {
+		if newName, ok := mapping[name]; ok {
+			name = newName
+		}
+
+		if _, dup := seen[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate metric name %q", errs.ErrInvalidMetricRemap, name)
+		}
+		seen[name] = struct{}{}
+		names[i] = name
+	}
+
+	return rewriteNumericIndex(data, layout, nil, names)
+}
+
+// parseNumericRemapLayout parses the header, metric names and metadata sections
+// and locates the index entries of an encoded numeric blob.
+func parseNumericRemapLayout(data []byte) (numericRemapLayout, error) {
+	var layout numericRemapLayout
+
+	header, err := section.ParseNumericHeader(data)
+	if err != nil {
+		return layout, err
+	}
+
+	if header.ValuePayloadOffset < header.TimestampPayloadOffset {
+		return layout, errs.ErrInvalidValuePayloadOffset
+	}
+	if header.TagPayloadOffset < header.ValuePayloadOffset || header.TagPayloadOffset > uint64(len(data)) {
+		return layout, errs.ErrInvalidTagPayloadOffset
+	}
+
+	layout.header = header
+	engine := header.Flag.GetEndianEngine()
+	offset := header.Size()
+
+	if header.Flag.HasMetricNames() {
+		names, bytesRead, err := decodeNumericMetricNames(data[offset:], engine, header.Flag.HasCompressedMetricNames())
+		if err != nil {
+			return layout, fmt.Errorf("failed to decode metric names: %w", err)
+		}
+
+		if len(names) != int(header.MetricCount) {
+			return layout, fmt.Errorf("%w: expected %d names, got %d",
+				errs.ErrInvalidMetricNamesCount, header.MetricCount, len(names))
+		}
+
+		layout.names = names
+		layout.namesSize = bytesRead
+		offset += bytesRead
+	}
+
+	if header.Flag.HasMetadata() {
+		metadata, bytesRead, err := section.ParseMetadata(data[offset:], engine)
+		if err != nil {
+			return layout, err
+		}
+
+		layout.metadata = metadata
+		offset += bytesRead
+	}
+
+	layout.indexOffset = offset
+	layout.indexEnd = offset + int(header.MetricCount)*header.Flag.IndexEntrySize()
+	if layout.indexEnd > len(data) || layout.indexEnd > int(header.TimestampPayloadOffset) {
+		return layout, errs.ErrInvalidIndexEntrySize
+	}
+
+	return layout, nil
+}
+
+// rewriteNumericIndex assembles a copy of data with rewritten metric IDs. IDs are
+// taken from idMapping when names is nil, otherwise they are the hashes of names,
+// which also replace the metric names payload.
+func rewriteNumericIndex(data []byte, layout numericRemapLayout, idMapping map[uint64]uint64, names []string) ([]byte, error) {
+	header := layout.header
+	engine := header.Flag.GetEndianEngine()
+	entrySize := header.Flag.IndexEntrySize()
+	count := int(header.MetricCount)
+
+	oldIDs := make([]uint64, count)
+	newIDs := make([]uint64, count)
+	for i := range count {
+		oldIDs[i] = engine.Uint64(data[layout.indexOffset+i*entrySize:])
+		switch {
+		case names != nil:
+			newIDs[i] = hash.ID(names[i])
+		case idMapping[oldIDs[i]] != 0:
+			newIDs[i] = idMapping[oldIDs[i]]
+		default:
+			newIDs[i] = oldIDs[i]
+		}
+	}
+
+	// Duplicate IDs are only representable with a names payload (hash collisions).
+	if names == nil {
+		seen := make(map[uint64]struct{}, count)
+		for _, id := range newIDs {
+			if _, dup := seen[id]; dup {
+				return nil, fmt.Errorf("%w: duplicate metric ID 0x%016x", errs.ErrInvalidMetricRemap, id)
+			}
+			seen[id] = struct{}{}
+		}
+	}
+
+	if header.Flag.IsIndexSorted() && !slices.IsSorted(newIDs) {
+		return nil, fmt.Errorf("%w: sorted index requires the remapped IDs to keep MetricID order", errs.ErrInvalidMetricRemap)
+	}
+
+	metadata, err := remapMetricReferences(layout.metadata, oldIDs, newIDs, engine)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err = remapMetricMeta(metadata, oldIDs, newIDs, engine)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err = remapMetricDirectory(metadata, section.MetadataKeyMetricSketches, oldIDs, newIDs, engine)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err = remapMetricDirectory(metadata, section.MetadataKeyTagSummaries, oldIDs, newIDs, engine)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err = remapMetricDirectory(metadata, section.MetadataKeyValueSums, oldIDs, newIDs, engine)
+	if err != nil {
+		return nil, err
+	}
+
+	namesPayload := data[header.Size() : header.Size()+layout.namesSize]
+	if names != nil {
+		namesPayload, err = reencodeNumericMetricNames(names, &header.Flag, engine)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metadataSize := 0
+	if header.Flag.HasMetadata() {
+		metadataSize = metadata.Size()
+	}
+
+	// The index, shared timestamp table and payloads are copied unchanged; only
+	// the sections before the index may change size.
+	tail := data[layout.indexEnd:]
+	header.Wide = false
+	blobSize := layoutNumericHeader(&header, len(namesPayload)+metadataSize,
+		int(header.TimestampPayloadOffset)-layout.indexOffset,
+		int(header.ValuePayloadOffset-header.TimestampPayloadOffset),
+		int(header.TagPayloadOffset-header.ValuePayloadOffset),
+		len(data)-int(header.TagPayloadOffset))
+
+	out := make([]byte, blobSize)
+	offset := copy(out, header.Bytes())
+	offset += copy(out[offset:], namesPayload)
+	if metadataSize > 0 {
+		offset = metadata.WriteToSlice(out, offset, engine)
+	}
+
+	indexStart := offset
+	offset += copy(out[offset:], data[layout.indexOffset:layout.indexEnd])
+	copy(out[offset:], tail)
+
+	for i, id := range newIDs {
+		engine.PutUint64(out[indexStart+i*entrySize:], id)
+	}
+
+	return out, nil
+}
+
+// remapMetricReferences returns metadata whose metric reference pairs use the
+// remapped IDs, re-sorted by MetricID. Other records are shared with md.
+func remapMetricReferences(md section.Metadata, oldIDs, newIDs []uint64, engine endian.EndianEngine) (section.Metadata, error) {
+	data, ok := md.Get(section.MetadataKeyMetricReferences)
+	if !ok {
+		return md, nil
+	}
+
+	if len(data)%16 != 0 {
+		return md, fmt.Errorf("%w: metric references record size %d is not a multiple of 16", errs.ErrInvalidMetricReference, len(data))
+	}
+
+	remap := make(map[uint64]uint64, len(oldIDs))
+	for i, id := range oldIDs {
+		remap[id] = newIDs[i]
+	}
+
+	pairs := make([][2]uint64, 0, len(data)/16)
+	for i := 0; i < len(data); i += 16 {
+		metricID, refID := engine.Uint64(data[i:]), engine.Uint64(data[i+8:])
+		newMetricID, ok1 := remap[metricID]
+		newRefID, ok2 := remap[refID]
+		if !ok1 || !ok2 {
+			return md, fmt.Errorf("%w: reference 0x%016x -> 0x%016x names an unknown metric", errs.ErrInvalidMetricReference, metricID, refID)
+		}
+		pairs = append(pairs, [2]uint64{newMetricID, newRefID})
+	}
+
+	slices.SortFunc(pairs, func(a, b [2]uint64) int { return cmp.Compare(a[0], b[0]) })
+
+	record := make([]byte, len(data))
+	for i, p := range pairs {
+		engine.PutUint64(record[i*16:], p[0])
+		engine.PutUint64(record[i*16+8:], p[1])
+	}
+
+	remapped := section.Metadata{Records: slices.Clone(md.Records)}
+	remapped.Set(section.MetadataKeyMetricReferences, record)
+
+	return remapped, nil
+}
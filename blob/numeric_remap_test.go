@@ -0,0 +1,159 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func decodeRemapTestBlob(t *testing.T, data []byte) NumericBlob {
+	t.Helper()
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func encodeNamedRemapTestBlob(t *testing.T, withNames bool, names ...string) []byte {
+	t.Helper()
+
+	startTime := time.Now()
+	encoder, err := NewNumericEncoder(startTime, WithTagsEnabled(true))
+	require.NoError(t, err)
+	if withNames {
+		// Force the names payload, as written when hash collisions occur
+		encoder.header.Flag.SetHasMetricNames(true)
+	}
+
+	for n, name := range names {
+		require.NoError(t, encoder.StartMetricName(name, 3))
+		for i := range 3 {
+			ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, float64(n*10+i), name))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestRemapMetricIDs(t *testing.T) {
+	t.Run("V1Swap", func(t *testing.T) {
+		data := encodePartsTestBlob(t, WithTagsEnabled(true))
+		original := decodeRemapTestBlob(t, data)
+
+		remapped, err := RemapMetricIDs(data, map[uint64]uint64{1: 2, 2: 1, 3: 30, 99: 100})
+		require.NoError(t, err)
+		require.Len(t, remapped, len(data))
+
+		blob := decodeRemapTestBlob(t, remapped)
+		require.ElementsMatch(t, []uint64{1, 2, 30}, blob.MetricIDs())
+		for oldID, newID := range map[uint64]uint64{1: 2, 2: 1, 3: 30} {
+			require.Equal(t, slices.Collect(original.AllValues(oldID)), slices.Collect(blob.AllValues(newID)))
+			require.Equal(t, slices.Collect(original.AllTags(oldID)), slices.Collect(blob.AllTags(newID)))
+		}
+	})
+
+	t.Run("V2OrderPreserving", func(t *testing.T) {
+		data := encodePartsTestBlob(t, WithBlobLayoutV2(), WithSharedTimestamps())
+		original := decodeRemapTestBlob(t, data)
+
+		remapped, err := RemapMetricIDs(data, map[uint64]uint64{2: 5, 3: 7})
+		require.NoError(t, err)
+
+		blob := decodeRemapTestBlob(t, remapped)
+		require.Equal(t, []uint64{1, 5, 7}, blob.MetricIDs())
+		require.Equal(t, slices.Collect(original.AllTimestamps(3)), slices.Collect(blob.AllTimestamps(7)))
+		require.Equal(t, slices.Collect(original.AllValues(2)), slices.Collect(blob.AllValues(5)))
+
+		_, err = RemapMetricIDs(data, map[uint64]uint64{1: 9})
+		require.ErrorIs(t, err, errs.ErrInvalidMetricRemap)
+	})
+
+	t.Run("MetricReferences", func(t *testing.T) {
+		startTime := time.Now()
+		encoder, err := NewNumericEncoder(startTime, WithMetricReferences(), WithValueEncoding(format.TypeGorilla))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(100, 2))
+		require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{1.5, 2.5}, nil))
+		require.NoError(t, encoder.EndMetric())
+		require.NoError(t, encoder.StartMetricIDWithReference(50, 100, 2))
+		require.NoError(t, encoder.AddDataPoints([]int64{1, 2}, []float64{1.75, 2.25}, nil))
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		remapped, err := RemapMetricIDs(data, map[uint64]uint64{100: 1, 50: 200})
+		require.NoError(t, err)
+
+		blob := decodeRemapTestBlob(t, remapped)
+		require.Equal(t, []float64{1.5, 2.5}, slices.Collect(blob.AllValues(1)))
+		require.Equal(t, []float64{1.75, 2.25}, slices.Collect(blob.AllValues(200)))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		data := encodePartsTestBlob(t)
+
+		_, err := RemapMetricIDs(data, map[uint64]uint64{1: 2})
+		require.ErrorIs(t, err, errs.ErrInvalidMetricRemap)
+
+		_, err = RemapMetricIDs(data, map[uint64]uint64{1: 0})
+		require.ErrorIs(t, err, errs.ErrInvalidMetricID)
+
+		_, err = RemapMetricIDs(encodeNamedRemapTestBlob(t, true, "a", "b"), map[uint64]uint64{hash.ID("a"): 1})
+		require.ErrorIs(t, err, errs.ErrInvalidMetricRemap)
+
+		_, err = RemapMetricIDs(data[:8], nil)
+		require.ErrorIs(t, err, errs.ErrInvalidHeaderSize)
+	})
+}
+
+func TestRenameMetrics(t *testing.T) {
+	for _, withNames := range []bool{false, true} {
+		name := "HashOnly"
+		if withNames {
+			name = "NamesPayload"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			data := encodeNamedRemapTestBlob(t, withNames, "cpu_usage", "mem_usage", "disk_io")
+			original := decodeRemapTestBlob(t, data)
+
+			renamed, err := RenameMetrics(data, map[string]string{
+				"cpu_usage": "system.cpu.usage",
+				"mem_usage": "disk_io",
+				"disk_io":   "mem_usage",
+			})
+			require.NoError(t, err)
+
+			blob := decodeRemapTestBlob(t, renamed)
+			require.Equal(t, withNames, blob.HasMetricNames())
+			if withNames {
+				require.ElementsMatch(t, []string{"system.cpu.usage", "mem_usage", "disk_io"}, blob.MetricNames())
+			}
+
+			require.False(t, blob.HasMetricName("cpu_usage"))
+			require.Equal(t, slices.Collect(original.AllValuesByName("cpu_usage")), slices.Collect(blob.AllValuesByName("system.cpu.usage")))
+			require.Equal(t, slices.Collect(original.AllTagsByName("mem_usage")), slices.Collect(blob.AllTagsByName("disk_io")))
+			require.Equal(t, slices.Collect(original.AllValuesByName("disk_io")), slices.Collect(blob.AllValuesByName("mem_usage")))
+
+			_, err = RenameMetrics(data, map[string]string{"cpu_usage": "mem_usage"})
+			require.ErrorIs(t, err, errs.ErrInvalidMetricRemap)
+
+			_, err = RenameMetrics(data, map[string]string{"cpu_usage": ""})
+			require.ErrorIs(t, err, errs.ErrInvalidMetricName)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MetricSize attributes part of an encoded numeric blob to one metric.
+//
+// The *Bytes fields are the metric's share of each stored payload section,
+// after compression. Compressed sections cannot be split exactly, so each
+// section's stored size is distributed in proportion to the metrics' encoded
+// (uncompressed) sizes in it. A timestamp sequence shared by several metrics
+// is split evenly between them.
+type MetricSize struct {
+	IndexEntryInfo
+
+	TimestampBytes float64 // attributed share of the stored timestamp payload
+	ValueBytes     float64 // attributed share of the stored value payload
+	TagBytes       float64 // attributed share of the stored tag payload
+}
+
+// Bytes returns the total number of stored bytes attributed to the metric.
+func (m MetricSize) Bytes() float64 {
+	return m.TimestampBytes + m.ValueBytes + m.TagBytes
+}
+
+// NumericSizeReport attributes the size of an encoded numeric blob to its metrics.
+type NumericSizeReport struct {
+	TotalBytes     int // size of the encoded blob
+	OverheadBytes  int // header, metric names, metadata and index
+	TimestampBytes int // stored timestamp payload
+	ValueBytes     int // stored value payload
+	TagBytes       int // stored tag payload
+
+	// Metrics holds one entry per metric, largest first.
+	Metrics []MetricSize
+}
+
+// Top returns the n metrics with the most attributed bytes, or all metrics if
+// there are fewer.
+func (r NumericSizeReport) Top(n int) []MetricSize {
+	return r.Metrics[:min(max(n, 0), len(r.Metrics))]
+}
+
+// String returns the report as a table, largest metric first.
+func (r NumericSizeReport) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "total %d bytes: overhead %d, timestamps %d, values %d, tags %d\n",
+		r.TotalBytes, r.OverheadBytes, r.TimestampBytes, r.ValueBytes, r.TagBytes)
+	for _, m := range r.Metrics {
+		name := m.Name
+		if name == "" {
+			name = fmt.Sprintf("%016x", m.MetricID)
+		}
+		share := 0.0
+		if r.TotalBytes > 0 {
+			share = m.Bytes() / float64(r.TotalBytes) * 100
+		}
+		fmt.Fprintf(&sb, "%-32s %8d points %12.1f bytes %6.2f%%\n", name, m.Count, m.Bytes(), share)
+	}
+
+	return sb.String()
+}
+
+// SizeReport attributes the stored bytes of an encoded numeric blob to its
+// metrics, identifying which metrics dominate the blob size, e.g. for
+// cardinality cleanup. See MetricSize for how compressed bytes are attributed.
+//
+// Parameters:
+//   - data: Encoded numeric blob
+//
+// Returns:
+//   - NumericSizeReport: Section sizes and per-metric attribution, largest metric first
+//   - error: Decoding errors
+//
+// Example:
+//
+//	report, err := blob.SizeReport(data)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, m := range report.Top(10) {
+//	    fmt.Printf("%s: %.0f bytes\n", m.Name, m.Bytes())
+//	}
+func SizeReport(data []byte) (NumericSizeReport, error) {
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		return NumericSizeReport{}, err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return NumericSizeReport{}, err
+	}
+
+	raw, err := decoder.payloadSections()
+	if err != nil {
+		return NumericSizeReport{}, err
+	}
+
+	report := NumericSizeReport{
+		TotalBytes:     len(data),
+		TimestampBytes: len(raw.tsPayload),
+		ValueBytes:     len(raw.valPayload),
+		TagBytes:       len(raw.tagPayload),
+	}
+	report.OverheadBytes = report.TotalBytes - report.TimestampBytes - report.ValueBytes - report.TagBytes
+
+	infos := make([]IndexEntryInfo, 0, b.MetricCount())
+	sharing := make(map[int]int) // metrics per timestamp offset
+	var tsTotal, valTotal, tagTotal int
+	for info := range b.IndexEntries() {
+		if sharing[info.TimestampOffset] == 0 {
+			tsTotal += info.TimestampSize
+		}
+		sharing[info.TimestampOffset]++
+		valTotal += info.ValueSize
+		tagTotal += info.TagSize
+		infos = append(infos, info)
+	}
+
+	report.Metrics = make([]MetricSize, len(infos))
+	for i, info := range infos {
+		m := MetricSize{IndexEntryInfo: info}
+		if tsTotal > 0 {
+			m.TimestampBytes = float64(info.TimestampSize) / float64(sharing[info.TimestampOffset]) /
+				float64(tsTotal) * float64(report.TimestampBytes)
+		}
+		if valTotal > 0 {
+			m.ValueBytes = float64(info.ValueSize) / float64(valTotal) * float64(report.ValueBytes)
+		}
+		if tagTotal > 0 {
+			m.TagBytes = float64(info.TagSize) / float64(tagTotal) * float64(report.TagBytes)
+		}
+		report.Metrics[i] = m
+	}
+
+	slices.SortStableFunc(report.Metrics, func(a, c MetricSize) int {
+		return cmp.Compare(c.Bytes(), a.Bytes())
+	})
+
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package blob
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func TestSizeReport(t *testing.T) {
+	for _, compression := range []format.CompressionType{format.CompressionNone, format.CompressionZstd} {
+		data := encodeTrimTestBlob(t,
+			WithTimestampCompression(compression),
+			WithValueCompression(compression),
+			WithTagCompression(compression),
+		)
+
+		report, err := SizeReport(data)
+		require.NoError(t, err)
+		require.Equal(t, len(data), report.TotalBytes)
+		require.Equal(t, report.TotalBytes,
+			report.OverheadBytes+report.TimestampBytes+report.ValueBytes+report.TagBytes)
+		require.Positive(t, report.OverheadBytes)
+
+		require.Len(t, report.Metrics, 3)
+		var ts, val, tag float64
+		for i, m := range report.Metrics {
+			if i > 0 {
+				require.GreaterOrEqual(t, report.Metrics[i-1].Bytes(), m.Bytes())
+			}
+			ts += m.TimestampBytes
+			val += m.ValueBytes
+			tag += m.TagBytes
+		}
+		require.InDelta(t, float64(report.TimestampBytes), ts, 1e-6)
+		require.InDelta(t, float64(report.ValueBytes), val, 1e-6)
+		require.InDelta(t, float64(report.TagBytes), tag, 1e-6)
+
+		// Metric 3 has 5 of 125 data points.
+		require.Equal(t, uint64(3), report.Metrics[2].MetricID)
+		require.Len(t, report.Top(1), 1)
+		require.Len(t, report.Top(10), 3)
+		require.Equal(t, 4, strings.Count(report.String(), "\n"))
+	}
+}
+
+func TestSizeReport_SharedTimestamps(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Unix(1700000000, 0), WithSharedTimestamps())
+	require.NoError(t, err)
+	for id := uint64(1); id <= 4; id++ {
+		require.NoError(t, encoder.StartMetricID(id, 8))
+		for i := range 8 {
+			require.NoError(t, encoder.AddDataPoint(int64(i), float64(i)*float64(id), ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	report, err := SizeReport(data)
+	require.NoError(t, err)
+
+	var ts float64
+	for _, m := range report.Metrics {
+		ts += m.TimestampBytes
+	}
+	require.InDelta(t, float64(report.TimestampBytes), ts, 1e-6)
+
+	_, err = SizeReport(data[:8])
+	require.Error(t, err)
+}
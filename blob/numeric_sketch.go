@@ -0,0 +1,188 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/sketch"
+	"github.com/arloliu/mebo/section"
+)
+
+// ValueSketch is a t-digest summarizing the values of a metric (see
+// WithSketches), answering percentile queries without decoding the values.
+//
+// The zero value is an empty sketch. A ValueSketch is not safe for concurrent
+// use; NumericBlob.Sketch returns an independent sketch on every call.
+type ValueSketch struct {
+	digest *sketch.TDigest
+}
+
+// Count returns the number of values summarized by the sketch, NaN excluded.
+func (s ValueSketch) Count() int {
+	if s.digest == nil {
+		return 0
+	}
+
+	return int(s.digest.Count())
+}
+
+// Min returns the smallest summarized value, or NaN if the sketch is empty.
+func (s ValueSketch) Min() float64 {
+	if s.Count() == 0 {
+		return math.NaN()
+	}
+
+	return s.digest.Min()
+}
+
+// Max returns the largest summarized value, or NaN if the sketch is empty.
+func (s ValueSketch) Max() float64 {
+	if s.Count() == 0 {
+		return math.NaN()
+	}
+
+	return s.digest.Max()
+}
+
+// Quantile returns the estimated value at quantile q in [0, 1], e.g. 0.99 for
+// the 99th percentile. The minimum (q = 0) and maximum (q = 1) are exact.
+//
+// Returns:
+//   - float64: The estimated quantile, or NaN if the sketch is empty or q is
+//     outside [0, 1]
+func (s ValueSketch) Quantile(q float64) float64 {
+	if s.digest == nil {
+		return math.NaN()
+	}
+
+	return s.digest.Quantile(q)
+}
+
+// Merge returns a sketch summarizing the values of both s and other, e.g. to
+// combine the sketches of a metric across blobs. Neither input is modified.
+func (s ValueSketch) Merge(other ValueSketch) ValueSketch {
+	merged := sketch.NewTDigest(sketch.DefaultCompression)
+	merged.Merge(s.digest)
+	merged.Merge(other.digest)
+
+	return ValueSketch{digest: merged}
+}
+
+// HasSketches reports whether the blob records value sketches (see WithSketches).
+func (b NumericBlob) HasSketches() bool {
+	_, ok := b.metadata.Get(section.MetadataKeyMetricSketches)
+
+	return ok
+}
+
+// Sketch returns the t-digest of the values of the given metric ID recorded at
+// encode time (see WithSketches), so percentile queries over archived blobs do
+// not touch the value payload at all.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - ValueSketch: A sketch of the metric's values, independent of the blob
+//   - bool: false if the blob has no sketch for the metric or it is corrupted
+//
+// Example:
+//
+//	if sk, ok := blob.Sketch(latencyID); ok {
+//	    fmt.Printf("p99=%.2f over %d values\n", sk.Quantile(0.99), sk.Count())
+//	}
+func (b NumericBlob) Sketch(metricID uint64) (ValueSketch, bool) {
+	if _, ok := b.index.GetByID(metricID); !ok {
+		return ValueSketch{}, false
+	}
+
+	record, ok := b.metadata.Get(section.MetadataKeyMetricSketches)
+	if !ok {
+		return ValueSketch{}, false
+	}
+
+	body, ok := findMetricBody(record, metricID, b.Engine())
+	if !ok {
+		return ValueSketch{}, false
+	}
+
+	digest, err := parseSketchBody(body, b.Engine())
+	if err != nil {
+		return ValueSketch{}, false
+	}
+
+	return ValueSketch{digest: digest}, true
+}
+
+// SketchByName returns the t-digest of the values of the given metric name.
+// See Sketch for details.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//
+// Returns:
+//   - ValueSketch: A sketch of the metric's values, independent of the blob
+//   - bool: false if the blob has no sketch for the metric or it is corrupted
+func (b NumericBlob) SketchByName(metricName string) (ValueSketch, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return ValueSketch{}, false
+	}
+
+	return b.Sketch(entry.MetricID)
+}
+
+// appendSketchBody appends the serialized body of the digest to dst.
+func appendSketchBody(dst []byte, digest *sketch.TDigest, engine endian.EndianEngine) []byte {
+	centroids := digest.Centroids()
+	minVal, maxVal := digest.Min(), digest.Max()
+	if len(centroids) == 0 {
+		minVal, maxVal = 0, 0
+	}
+
+	dst = engine.AppendUint64(dst, math.Float64bits(minVal))
+	dst = engine.AppendUint64(dst, math.Float64bits(maxVal))
+	dst = binary.AppendUvarint(dst, uint64(len(centroids)))
+	for _, c := range centroids {
+		dst = engine.AppendUint64(dst, math.Float64bits(c.Mean))
+		dst = binary.AppendUvarint(dst, uint64(c.Weight))
+	}
+
+	return dst
+}
+
+// parseSketchBody parses a serialized sketch body into a digest.
+func parseSketchBody(data []byte, engine endian.EndianEngine) (*sketch.TDigest, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("%w: sketch body truncated", errs.ErrInvalidMetadata)
+	}
+
+	minVal := math.Float64frombits(engine.Uint64(data))
+	maxVal := math.Float64frombits(engine.Uint64(data[8:]))
+	count, n := binary.Uvarint(data[16:])
+	if n <= 0 || count > uint64(len(data)) {
+		return nil, fmt.Errorf("%w: invalid sketch centroid count", errs.ErrInvalidMetadata)
+	}
+
+	offset := 16 + n
+	centroids := make([]sketch.Centroid, count)
+	for i := range centroids {
+		if len(data)-offset < 8 {
+			return nil, fmt.Errorf("%w: sketch centroid %d truncated", errs.ErrInvalidMetadata, i)
+		}
+
+		mean := math.Float64frombits(engine.Uint64(data[offset:]))
+		weight, n := binary.Uvarint(data[offset+8:])
+		if n <= 0 || weight == 0 || mean < minVal || mean > maxVal || (i > 0 && mean < centroids[i-1].Mean) {
+			return nil, fmt.Errorf("%w: invalid sketch centroid %d", errs.ErrInvalidMetadata, i)
+		}
+
+		centroids[i] = sketch.Centroid{Mean: mean, Weight: float64(weight)}
+		offset += 8 + n
+	}
+
+	return sketch.NewTDigestFromCentroids(sketch.DefaultCompression, centroids, minVal, maxVal), nil
+}
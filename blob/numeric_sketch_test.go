@@ -0,0 +1,147 @@
+package blob
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// encodeSketchTestBlob encodes metrics "a" and "b" with count data points each,
+// "a" holding 0..count-1 in shuffled order and "b" holding their negation.
+func encodeSketchTestBlob(t *testing.T, start time.Time, count int, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start, append([]NumericEncoderOption{WithSketches(true)}, opts...)...)
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b"} {
+		sign := 1.0
+		if name == "b" {
+			sign = -1
+		}
+
+		require.NoError(t, encoder.StartMetricName(name, count))
+		for i := range count {
+			v := float64((i * 7919) % count)
+			require.NoError(t, encoder.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), sign*v, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericBlob_Sketch(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blob := decodeRemapTestBlob(t, encodeSketchTestBlob(t, start, 5000))
+	require.True(t, blob.HasSketches())
+
+	sk, ok := blob.SketchByName("a")
+	require.True(t, ok)
+	require.Equal(t, 5000, sk.Count())
+	require.Equal(t, 0.0, sk.Min())
+	require.Equal(t, 4999.0, sk.Max())
+	require.InDelta(t, 2500, sk.Quantile(0.5), 25)
+	require.InDelta(t, 4950, sk.Quantile(0.99), 5)
+
+	neg, ok := blob.Sketch(hash.ID("b"))
+	require.True(t, ok)
+	require.InDelta(t, -4950, neg.Quantile(0.01), 5)
+
+	merged := sk.Merge(neg)
+	require.Equal(t, 10000, merged.Count())
+	require.Equal(t, -4999.0, merged.Min())
+	require.InDelta(t, 0, merged.Quantile(0.5), 50)
+
+	_, ok = blob.Sketch(hash.ID("missing"))
+	require.False(t, ok)
+	require.True(t, math.IsNaN(ValueSketch{}.Quantile(0.5)))
+
+	plain := decodeRemapTestBlob(t, encodeColdTestBlob(t, start, 1))
+	require.False(t, plain.HasSketches())
+}
+
+func TestNumericBlob_SketchQuantized(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(start, WithSketches(true), WithValuePrecision(0))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 4))
+	for i, v := range []float64{1.4, 2.6, math.NaN(), 7.2} {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), v, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	sk, ok := decodeRemapTestBlob(t, data).Sketch(1)
+	require.True(t, ok)
+	require.Equal(t, 3, sk.Count())
+	require.Equal(t, 1.0, sk.Min())
+	require.Equal(t, 7.0, sk.Max())
+
+	_, err = NewInt64Encoder(start, WithSketches(true))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestNumericBlob_SketchFollowsRewrites(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	data := encodeSketchTestBlob(t, start, 100)
+
+	// Remapped IDs keep their sketches.
+	remapped, err := RemapMetricIDs(data, map[uint64]uint64{hash.ID("a"): 42})
+	require.NoError(t, err)
+	sk, ok := decodeRemapTestBlob(t, remapped).Sketch(42)
+	require.True(t, ok)
+	require.Equal(t, 99.0, sk.Max())
+
+	// Edits rebuild the sketches of appended metrics and drop those of dropped ones.
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.AppendDataPoints(hash.ID("a"), []int64{start.Add(time.Hour).UnixMicro()}, []float64{500}, nil))
+	require.NoError(t, editor.DropMetric(hash.ID("b")))
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, edited)
+	sk, ok = blob.SketchByName("a")
+	require.True(t, ok)
+	require.Equal(t, 101, sk.Count())
+	require.Equal(t, 500.0, sk.Max())
+	_, ok = blob.SketchByName("b")
+	require.False(t, ok)
+
+	// Trimmed blobs recompute the sketches of the kept data points.
+	trimmed, err := decodeRemapTestBlob(t, data).Trim(start, start.Add(10*time.Second))
+	require.NoError(t, err)
+	sk, ok = trimmed.SketchByName("b")
+	require.True(t, ok)
+	require.Equal(t, 10, sk.Count())
+}
+
+func TestBlobSet_QuantileFromSketches(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blob := decodeRemapTestBlob(t, encodeSketchTestBlob(t, start, 1000, WithTimeBounds(true)))
+	bs := NewBlobSet([]NumericBlob{blob}, nil)
+	id := hash.ID("a")
+	from, to := start.UnixMicro(), start.Add(time.Hour).UnixMicro()
+
+	// The blob lies within the range, so its sketch answers; a partial range
+	// decodes the values.
+	p50, ok := bs.Quantile(id, 0.5, from, to)
+	require.True(t, ok)
+	sk, _ := blob.Sketch(id)
+	require.Equal(t, sk.Quantile(0.5), p50)
+
+	firstTen := slices.Collect(blob.AllValues(id))[:10]
+	maxVal, ok := bs.Quantile(id, 1, from, start.Add(9*time.Second).UnixMicro())
+	require.True(t, ok)
+	require.Equal(t, slices.Max(firstTen), maxVal)
+}
@@ -0,0 +1,114 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// smallLayout reports whether the finished blob is written in the small layout,
+// see WithSmallHeader.
+func (e *NumericEncoder) smallLayout(header *section.NumericHeader) bool {
+	return e.smallHeader &&
+		len(e.indexEntries) == 1 &&
+		e.layoutVersion < 2 &&
+		e.offsetUnit <= 1 &&
+		!header.Flag.HasMetricNames()
+}
+
+// finishSmall appends the blob in the small layout to dst, with the raw payloads
+// of its single metric.
+func (e *NumericEncoder) finishSmall(dst []byte, header *section.NumericHeader, rawTs, rawVal, rawTag []byte, tagBitmap bool) ([]byte, error) {
+	// Small blobs store raw tags, which standardNumericBlob records again.
+	metadata := e.blobMetadata(header.Flag.HasTag(), true, tagBitmap)
+	metadata.Delete(section.MetadataKeyTagCompression)
+
+	small := section.NumericSmallHeader{Flag: header.Flag, Count: uint32(e.indexEntries[0].Count)} //nolint:gosec // V1 counts fit uint16
+	small.Flag.SetHasMetadata(!metadata.IsEmpty())
+	index := section.NumericSmallIndex{
+		MetricID:      e.indexEntries[0].MetricID,
+		StartTime:     header.StartTime,
+		TimestampSize: len(rawTs),
+		ValueSize:     len(rawVal),
+	}
+
+	size := section.SmallHeaderSize + index.Size() + len(rawTs) + len(rawVal) + len(rawTag)
+	if small.Flag.HasMetadata() {
+		size += metadata.Size()
+	}
+
+	full, blob := appendBlobRegion(dst, size)
+	offset := copy(blob, small.Bytes())
+	offset += len(index.AppendTo(blob[offset:offset], e.engine))
+	if small.Flag.HasMetadata() {
+		offset = metadata.WriteToSlice(blob, offset, e.engine)
+	}
+	offset += copy(blob[offset:], rawTs)
+	offset += copy(blob[offset:], rawVal)
+	copy(blob[offset:], rawTag)
+
+	return full, nil
+}
+
+// standardNumericBlob returns data in the standard layout: small blobs are
+// expanded into an equivalent V1 blob with uncompressed payloads, other blobs
+// are returned as is. The expanded blob is a new allocation of about 40 bytes
+// more than data.
+func standardNumericBlob(data []byte) ([]byte, error) {
+	if !section.IsNumericSmallBlob(data) {
+		return data, nil
+	}
+
+	var small section.NumericSmallHeader
+	if err := small.Parse(data[:section.SmallHeaderSize]); err != nil {
+		return nil, err
+	}
+	engine := small.Flag.GetEndianEngine()
+
+	index, n, err := section.ParseNumericSmallIndex(data[section.SmallHeaderSize:], engine)
+	if err != nil {
+		return nil, err
+	}
+	offset := section.SmallHeaderSize + n
+
+	var metadata section.Metadata
+	if small.Flag.HasMetadata() {
+		metadata, n, err = section.ParseMetadata(data[offset:], engine)
+		if err != nil {
+			return nil, err
+		}
+		offset += n
+	}
+
+	payloads := data[offset:]
+	if index.TimestampSize > len(payloads) {
+		return nil, fmt.Errorf("%w: timestamp payload of %d bytes exceeds blob", errs.ErrInvalidTimestampPayloadOffset, index.TimestampSize)
+	}
+	if index.ValueSize > len(payloads)-index.TimestampSize {
+		return nil, fmt.Errorf("%w: value payload of %d bytes exceeds blob", errs.ErrInvalidValuePayloadOffset, index.ValueSize)
+	}
+
+	header := section.NumericHeader{StartTime: index.StartTime, MetricCount: 1, Flag: small.Flag}
+	if header.Flag.HasTag() {
+		metadata.Set(section.MetadataKeyTagCompression, []byte{byte(format.CompressionNone)})
+	}
+	metadataSize := 0
+	if !metadata.IsEmpty() {
+		header.Flag.SetHasMetadata(true)
+		metadataSize = metadata.Size()
+	}
+
+	tagSize := len(payloads) - index.TimestampSize - index.ValueSize
+	blob := make([]byte, layoutNumericHeader(&header, metadataSize, section.NumericIndexEntrySize, index.TimestampSize, index.ValueSize, tagSize))
+	offset = copy(blob, header.Bytes())
+	if metadataSize > 0 {
+		offset = metadata.WriteToSlice(blob, offset, engine)
+	}
+	entry := section.NewNumericIndexEntry(index.MetricID, int(small.Count))
+	offset = entry.WriteToSlice(blob, offset, engine)
+	copy(blob[offset:], payloads)
+
+	return blob, nil
+}
@@ -0,0 +1,191 @@
+package blob
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// encodeSmallTestBlob encodes the given metrics of three data points each.
+func encodeSmallTestBlob(t *testing.T, metrics []string, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0)
+	encoder, err := NewNumericEncoder(start, opts...)
+	require.NoError(t, err)
+	for _, name := range metrics {
+		require.NoError(t, encoder.StartMetricName(name, 3))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 1.5, "host=a"))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+1000, 2.5, ""))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+2000, 3.5, "host=b"))
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericSmallHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "default", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "raw big endian", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithBigEndian(),
+			WithTimestampEncoding(format.TypeRaw),
+			WithValueEncoding(format.TypeRaw),
+		}},
+		{name: "metadata", opts: []NumericEncoderOption{
+			WithTagsEnabled(true),
+			WithTimeBounds(true),
+			WithValueSums(true),
+			WithTimestampUnit(format.TimeUnitMillisecond),
+		}},
+		{name: "no tags", opts: []NumericEncoderOption{WithTagCompression(format.CompressionS2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Small blobs store uncompressed payloads, as does the compared blob
+			standard := encodeSmallTestBlob(t, []string{"cpu"}, append(tt.opts,
+				WithTimestampCompression(format.CompressionNone),
+				WithValueCompression(format.CompressionNone),
+				WithTagCompression(format.CompressionNone))...)
+			data := encodeSmallTestBlob(t, []string{"cpu"}, append(tt.opts, WithSmallHeader(true))...)
+			require.True(t, section.IsNumericSmallBlob(data))
+			require.True(t, section.IsNumericBlob(data))
+			require.LessOrEqual(t, len(data), len(standard)-20)
+			require.NoError(t, QuickVerify(data))
+
+			want, err := DecodeBlobSet(standard)
+			require.NoError(t, err)
+			got, err := DecodeBlobSet(data)
+			require.NoError(t, err)
+			require.Len(t, got.NumericBlobs(), 1)
+
+			wantBlob, gotBlob := want.NumericBlobs()[0], got.NumericBlobs()[0]
+			require.Equal(t, wantBlob.StartTime(), gotBlob.StartTime())
+			require.Equal(t, wantBlob.HasTag(), gotBlob.HasTag())
+			require.Equal(t, wantBlob.TimestampUnit(), gotBlob.TimestampUnit())
+			require.Equal(t, wantBlob.HasTimeBounds(), gotBlob.HasTimeBounds())
+			require.Equal(t, slices.Collect(wantBlob.AllTimestampsByName("cpu")), slices.Collect(gotBlob.AllTimestampsByName("cpu")))
+			require.Equal(t, slices.Collect(wantBlob.AllValuesByName("cpu")), slices.Collect(gotBlob.AllValuesByName("cpu")))
+			require.Equal(t, slices.Collect(wantBlob.AllTagsByName("cpu")), slices.Collect(gotBlob.AllTagsByName("cpu")))
+
+			id, err := NumericBlobID(data)
+			require.NoError(t, err)
+			blobID, ok := gotBlob.BlobID()
+			require.True(t, ok)
+			require.Equal(t, blobID, id)
+
+			parts, err := SplitNumericBlob(data)
+			require.NoError(t, err)
+			joined, err := parts.Join()
+			require.NoError(t, err)
+			require.NoError(t, QuickVerify(joined))
+		})
+	}
+}
+
+func TestNumericSmallHeader_StandardFallback(t *testing.T) {
+	// Blobs of several metrics and V2 blobs keep the standard layout
+	data := encodeSmallTestBlob(t, []string{"cpu", "mem"}, WithSmallHeader(true))
+	require.False(t, section.IsNumericSmallBlob(data))
+
+	data = encodeSmallTestBlob(t, []string{"cpu"}, WithSmallHeader(true), WithBlobLayoutV2())
+	require.False(t, section.IsNumericSmallBlob(data))
+
+	data = encodeSmallTestBlob(t, []string{"cpu"}, WithSmallHeader(true), WithSmallHeader(false))
+	require.False(t, section.IsNumericSmallBlob(data))
+}
+
+func TestNumericSmallHeader_Malformed(t *testing.T) {
+	// Without tags, the value payload ends the blob
+	data := encodeSmallTestBlob(t, []string{"cpu"}, WithSmallHeader(true))
+	for n := range len(data) {
+		_, err := NewNumericDecoder(data[:n])
+		require.Error(t, err, "truncated to %d bytes", n)
+	}
+
+	reserved := slices.Clone(data)
+	reserved[3] |= 0x80
+	_, err := NewNumericDecoder(reserved)
+	require.ErrorIs(t, err, errs.ErrInvalidHeaderFlags)
+
+	names := slices.Clone(data)
+	names[0] |= section.MetricNamesMask
+	_, err = NewNumericDecoder(names)
+	require.ErrorIs(t, err, errs.ErrInvalidHeaderFlags)
+}
+
+func TestNumericSmallHeader_Edit(t *testing.T) {
+	opts := []NumericEncoderOption{WithTagsEnabled(true), WithValuePrecision(1), WithSmallHeader(true)}
+	data := encodeSmallTestBlob(t, []string{"cpu"}, opts...)
+	require.True(t, section.IsNumericSmallBlob(data))
+	start := time.Unix(1700000000, 0).UnixMicro()
+
+	decode := func(t *testing.T, data []byte) NumericBlob {
+		t.Helper()
+		require.NoError(t, QuickVerify(data))
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	t.Run("editor", func(t *testing.T) {
+		editor, err := NewBlobEditor(data)
+		require.NoError(t, err)
+		same, err := editor.Finish()
+		require.NoError(t, err)
+		require.Equal(t, data, same)
+
+		require.NoError(t, editor.SetTagByName("cpu", 1, "host=c"))
+		require.NoError(t, editor.AppendDataPointsByName("cpu", []int64{start + 3000}, []float64{4.54}, []string{"host=d"}))
+		edited, err := editor.Finish()
+		require.NoError(t, err)
+
+		blob := decode(t, edited)
+		require.Equal(t, []int64{start, start + 1000, start + 2000, start + 3000}, slices.Collect(blob.AllTimestampsByName("cpu")))
+		require.Equal(t, []float64{1.5, 2.5, 3.5, 4.5}, slices.Collect(blob.AllValuesByName("cpu")))
+		require.Equal(t, []string{"host=a", "host=c", "host=b", "host=d"}, slices.Collect(blob.AllTagsByName("cpu")))
+	})
+
+	t.Run("update tags", func(t *testing.T) {
+		updated, err := UpdateTags(data, hash.ID("cpu"), func(_ int, oldTag string) string { return oldTag + ",dc=1" })
+		require.NoError(t, err)
+
+		blob := decode(t, updated)
+		require.Equal(t, []string{"host=a,dc=1", ",dc=1", "host=b,dc=1"}, slices.Collect(blob.AllTagsByName("cpu")))
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		remapped, err := RemapMetricIDs(data, map[uint64]uint64{hash.ID("cpu"): 42})
+		require.NoError(t, err)
+
+		blob := decode(t, remapped)
+		require.Equal(t, []float64{1.5, 2.5, 3.5}, slices.Collect(blob.AllValues(42)))
+		require.Equal(t, []string{"host=a", "", "host=b"}, slices.Collect(blob.AllTags(42)))
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		renamed, err := RenameMetrics(data, map[string]string{"cpu": "cpu.usage"})
+		require.NoError(t, err)
+
+		blob := decode(t, renamed)
+		require.Equal(t, []int64{start, start + 1000, start + 2000}, slices.Collect(blob.AllTimestampsByName("cpu.usage")))
+		require.Equal(t, []float64{1.5, 2.5, 3.5}, slices.Collect(blob.AllValuesByName("cpu.usage")))
+	})
+}
@@ -0,0 +1,127 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// Spill file column indices.
+const (
+	spillTs = iota
+	spillVal
+	spillTag
+	spillColumns
+)
+
+// columnSpill holds encoded column bytes of completed metrics that an encoder
+// moved out of its column encoders, one temporary file per column.
+//
+// Files are created lazily in dir on the first write and removed by close. An
+// in-memory spill keeps the bytes in buffers instead; encoders without a memory
+// limit use one to hold the columns kept by AbortMetric.
+type columnSpill struct {
+	dir      string
+	inMemory bool
+	files    [spillColumns]*os.File
+	mem      [spillColumns][]byte
+	sizes    [spillColumns]int
+}
+
+// write appends encoded bytes to the spill file of the given column.
+func (s *columnSpill) write(column int, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if s.inMemory {
+		s.mem[column] = append(s.mem[column], data...)
+		s.sizes[column] += len(data)
+
+		return nil
+	}
+
+	if s.files[column] == nil {
+		f, err := os.CreateTemp(s.dir, "mebo-spill-*")
+		if err != nil {
+			return fmt.Errorf("failed to create spill file: %w", err)
+		}
+		s.files[column] = f
+	}
+
+	n, err := s.files[column].Write(data)
+	s.sizes[column] += n
+	if err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	return nil
+}
+
+// column returns the spilled bytes of a column followed by tail, the column
+// bytes still held in memory.
+func (s *columnSpill) column(column int, tail []byte) ([]byte, error) {
+	if s.inMemory {
+		return slices.Concat(s.mem[column], tail), nil
+	}
+
+	f := s.files[column]
+	if f == nil {
+		return tail, nil
+	}
+
+	buf := make([]byte, s.sizes[column], s.sizes[column]+len(tail))
+	if _, err := f.ReadAt(buf, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read spill file: %w", err)
+	}
+
+	return append(buf, tail...), nil
+}
+
+// remove cuts the bytes [lo, hi) out of the spilled bytes of a column.
+func (s *columnSpill) remove(column, lo, hi int) error {
+	if lo == hi {
+		return nil
+	}
+
+	if s.inMemory {
+		s.mem[column] = slices.Delete(s.mem[column], lo, hi)
+		s.sizes[column] -= hi - lo
+
+		return nil
+	}
+
+	f := s.files[column]
+	tail := make([]byte, s.sizes[column]-hi)
+	if _, err := f.ReadAt(tail, int64(hi)); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read spill file: %w", err)
+	}
+	if _, err := f.WriteAt(tail, int64(lo)); err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+
+	s.sizes[column] -= hi - lo
+	if err := f.Truncate(int64(s.sizes[column])); err != nil {
+		return fmt.Errorf("failed to truncate spill file: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek spill file: %w", err)
+	}
+
+	return nil
+}
+
+// close closes and removes all spill files.
+func (s *columnSpill) close() {
+	s.mem = [spillColumns][]byte{}
+	for i, f := range s.files {
+		if f == nil {
+			continue
+		}
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		s.files[i] = nil
+	}
+}
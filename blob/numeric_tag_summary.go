@@ -0,0 +1,185 @@
+package blob
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/section"
+)
+
+// TagSummaryTopN is the maximum number of most frequent tags a TagSummary holds.
+const TagSummaryTopN = 10
+
+// TagCount is a tag and the number of data points carrying it.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// TagSummary describes the tag cardinality of a metric (see NumericBlob.TagSummary).
+// Empty tags are not counted.
+type TagSummary struct {
+	// Tagged is the number of data points with a non-empty tag.
+	Tagged int
+	// Distinct is the number of distinct non-empty tags.
+	Distinct int
+	// Top holds up to TagSummaryTopN most frequent tags, sorted by Count
+	// descending and then by Tag ascending.
+	Top []TagCount
+}
+
+// HasTagSummaries reports whether the blob records tag summaries (see WithTagSummaries).
+func (b NumericBlob) HasTagSummaries() bool {
+	_, ok := b.metadata.Get(section.MetadataKeyTagSummaries)
+
+	return ok
+}
+
+// TagSummary returns the number of distinct tags and the most frequent tags of
+// the given metric ID, e.g. to monitor label cardinality.
+//
+// Blobs encoded with WithTagSummaries answer from their metadata without
+// decoding any tag; other blobs count the metric's tags in a single pass. Blobs
+// without tags return an empty summary.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - TagSummary: The tag cardinality of the metric
+//   - bool: false if the metric is not found
+//
+// Example:
+//
+//	if s, ok := blob.TagSummary(metricID); ok && s.Distinct > 1000 {
+//	    log.Printf("high tag cardinality: %d distinct, top %q", s.Distinct, s.Top[0].Tag)
+//	}
+func (b NumericBlob) TagSummary(metricID uint64) (TagSummary, bool) {
+	entry, ok := b.index.GetByID(metricID)
+	if !ok {
+		return TagSummary{}, false
+	}
+
+	if record, ok := b.metadata.Get(section.MetadataKeyTagSummaries); ok {
+		if body, ok := findMetricBody(record, metricID, b.Engine()); ok {
+			if summary, err := parseTagSummaryBody(body); err == nil {
+				return summary, true
+			}
+		}
+	}
+
+	if !b.HasTag() {
+		return TagSummary{}, true
+	}
+
+	counts := make(map[string]int)
+	for tag := range b.allTagsFromEntry(entry) {
+		if tag != "" {
+			counts[tag]++
+		}
+	}
+
+	return summarizeTags(counts), true
+}
+
+// TagSummaryByName returns the tag cardinality of the given metric name.
+// See TagSummary for details.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//
+// Returns:
+//   - TagSummary: The tag cardinality of the metric
+//   - bool: false if the metric is not found
+func (b NumericBlob) TagSummaryByName(metricName string) (TagSummary, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return TagSummary{}, false
+	}
+
+	return b.TagSummary(entry.MetricID)
+}
+
+// summarizeTags builds the summary of the given non-empty tag counts.
+func summarizeTags(counts map[string]int) TagSummary {
+	summary := TagSummary{Distinct: len(counts)}
+	top := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		summary.Tagged += count
+		top = append(top, TagCount{Tag: tag, Count: count})
+	}
+
+	slices.SortFunc(top, func(a, b TagCount) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Tag, b.Tag)
+	})
+	if len(top) > 0 {
+		summary.Top = slices.Clip(top[:min(len(top), TagSummaryTopN)])
+	}
+
+	return summary
+}
+
+// appendTagSummaryBody appends the serialized body of the summary to dst.
+func appendTagSummaryBody(dst []byte, summary TagSummary) []byte {
+	dst = binary.AppendUvarint(dst, uint64(summary.Tagged))   //nolint: gosec
+	dst = binary.AppendUvarint(dst, uint64(summary.Distinct)) //nolint: gosec
+	dst = binary.AppendUvarint(dst, uint64(len(summary.Top)))
+	for _, tc := range summary.Top {
+		dst = binary.AppendUvarint(dst, uint64(len(tc.Tag)))
+		dst = append(dst, tc.Tag...)
+		dst = binary.AppendUvarint(dst, uint64(tc.Count)) //nolint: gosec
+	}
+
+	return dst
+}
+
+// parseTagSummaryBody parses a serialized tag summary body.
+func parseTagSummaryBody(data []byte) (TagSummary, error) {
+	var fields [3]uint64
+	offset := 0
+	for i := range fields {
+		v, n := binary.Uvarint(data[offset:])
+		if n <= 0 || v > math.MaxInt32 {
+			return TagSummary{}, fmt.Errorf("%w: invalid tag summary", errs.ErrInvalidMetadata)
+		}
+		fields[i] = v
+		offset += n
+	}
+
+	tagged, distinct, topCount := fields[0], fields[1], fields[2]
+	if topCount > TagSummaryTopN || topCount > distinct || distinct > tagged {
+		return TagSummary{}, fmt.Errorf("%w: invalid tag summary counts", errs.ErrInvalidMetadata)
+	}
+
+	summary := TagSummary{Tagged: int(tagged), Distinct: int(distinct)} //nolint: gosec
+	if topCount > 0 {
+		summary.Top = make([]TagCount, topCount)
+	}
+	for i := range summary.Top {
+		length, n := binary.Uvarint(data[offset:])
+		if n <= 0 || length > uint64(len(data)-offset-n) {
+			return TagSummary{}, fmt.Errorf("%w: tag summary entry %d truncated", errs.ErrInvalidMetadata, i)
+		}
+		offset += n
+		tag := string(data[offset : offset+int(length)]) //nolint: gosec
+		offset += int(length)                            //nolint: gosec
+
+		count, n := binary.Uvarint(data[offset:])
+		if n <= 0 || count == 0 || count > tagged {
+			return TagSummary{}, fmt.Errorf("%w: invalid tag summary entry %d", errs.ErrInvalidMetadata, i)
+		}
+		offset += n
+
+		summary.Top[i] = TagCount{Tag: tag, Count: int(count)} //nolint: gosec
+	}
+
+	return summary, nil
+}
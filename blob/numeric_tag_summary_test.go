@@ -0,0 +1,142 @@
+package blob
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// encodeTagSummaryTestBlob encodes metric "a", added point by point, whose i-th
+// data point is tagged "t<i%12>" (every 5th untagged), and metric "b", added as
+// a batch, whose 20 points are tagged "y" (every 4th) or "x".
+func encodeTagSummaryTestBlob(t *testing.T, start time.Time, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start, append([]NumericEncoderOption{WithTagsEnabled(true)}, opts...)...)
+	require.NoError(t, err)
+
+	require.NoError(t, encoder.StartMetricName("a", 120))
+	for i := range 120 {
+		tag := fmt.Sprintf("t%d", i%12)
+		if i%5 == 0 {
+			tag = ""
+		}
+		require.NoError(t, encoder.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), float64(i), tag))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	timestamps := make([]int64, 20)
+	values := make([]float64, 20)
+	tags := make([]string, 20)
+	for i := range timestamps {
+		timestamps[i] = start.Add(time.Duration(i) * time.Second).UnixMicro()
+		values[i] = float64(i)
+		tags[i] = "x"
+		if i%4 == 0 {
+			tags[i] = "y"
+		}
+	}
+	require.NoError(t, encoder.StartMetricName("b", 20))
+	require.NoError(t, encoder.AddDataPoints(timestamps, values, tags))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericBlob_TagSummary(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	stored := decodeRemapTestBlob(t, encodeTagSummaryTestBlob(t, start, WithTagSummaries(true)))
+	computed := decodeRemapTestBlob(t, encodeTagSummaryTestBlob(t, start))
+	require.True(t, stored.HasTagSummaries())
+	require.False(t, computed.HasTagSummaries())
+
+	for _, name := range []string{"a", "b"} {
+		want, ok := computed.TagSummaryByName(name)
+		require.True(t, ok)
+		got, ok := stored.TagSummaryByName(name)
+		require.True(t, ok)
+		require.Equal(t, want, got, name)
+	}
+
+	a, _ := stored.TagSummaryByName("a")
+	require.Equal(t, 96, a.Tagged)
+	require.Equal(t, 12, a.Distinct)
+	require.Len(t, a.Top, TagSummaryTopN)
+	// Every tag has 8 points, so ties are ordered by tag.
+	require.Equal(t, TagCount{Tag: "t0", Count: 8}, a.Top[0])
+	require.Equal(t, TagCount{Tag: "t10", Count: 8}, a.Top[2])
+	require.Equal(t, TagCount{Tag: "t7", Count: 8}, a.Top[9])
+
+	b, _ := stored.TagSummary(hash.ID("b"))
+	require.Equal(t, TagSummary{Tagged: 20, Distinct: 2, Top: []TagCount{{"x", 15}, {"y", 5}}}, b)
+
+	_, ok := stored.TagSummary(hash.ID("missing"))
+	require.False(t, ok)
+}
+
+func TestNumericBlob_TagSummaryWithoutTags(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	// Only empty tags: the encoder drops the tag column and the summary record.
+	encoder, err := NewNumericEncoder(start, WithTagsEnabled(true), WithTagSummaries(true))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 2))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 1, ""))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+1, 2, ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, data)
+	require.False(t, blob.HasTag())
+	require.False(t, blob.HasTagSummaries())
+	summary, ok := blob.TagSummary(1)
+	require.True(t, ok)
+	require.Equal(t, TagSummary{}, summary)
+}
+
+func TestNumericBlob_TagSummaryFollowsRewrites(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	data := encodeTagSummaryTestBlob(t, start, WithTagSummaries(true))
+
+	// Remapped IDs keep their summaries.
+	remapped, err := RemapMetricIDs(data, map[uint64]uint64{hash.ID("b"): 42})
+	require.NoError(t, err)
+	summary, ok := decodeRemapTestBlob(t, remapped).TagSummary(42)
+	require.True(t, ok)
+	require.Equal(t, 2, summary.Distinct)
+
+	// Edits rebuild the summaries of edited metrics and drop those of dropped ones.
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.SetTag(hash.ID("b"), 0, "z"))
+	require.NoError(t, editor.DropMetric(hash.ID("a")))
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, edited)
+	require.True(t, blob.HasTagSummaries())
+	summary, ok = blob.TagSummaryByName("b")
+	require.True(t, ok)
+	require.Equal(t, TagSummary{Tagged: 20, Distinct: 3, Top: []TagCount{{"x", 15}, {"y", 4}, {"z", 1}}}, summary)
+	record, ok := blob.metadata.Get(section.MetadataKeyTagSummaries)
+	require.True(t, ok)
+	_, ok = findMetricBody(record, hash.ID("a"), blob.Engine())
+	require.False(t, ok)
+
+	// Trimmed blobs recompute the summaries of the kept data points.
+	trimmed, err := decodeRemapTestBlob(t, data).Trim(start, start.Add(4*time.Second))
+	require.NoError(t, err)
+	require.True(t, trimmed.HasTagSummaries())
+	summary, ok = trimmed.TagSummaryByName("b")
+	require.True(t, ok)
+	require.Equal(t, TagSummary{Tagged: 4, Distinct: 2, Top: []TagCount{{"x", 3}, {"y", 1}}}, summary)
+}
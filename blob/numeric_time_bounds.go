@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"time"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/section"
+)
+
+// timeBoundsSize is the size of the time bounds metadata record in bytes.
+const timeBoundsSize = 16
+
+// encodeTimeBounds serializes the earliest and latest timestamps of a blob.
+func encodeTimeBounds(minTs, maxTs int64, engine endian.EndianEngine) []byte {
+	b := make([]byte, timeBoundsSize)
+	engine.PutUint64(b, uint64(minTs))     //nolint: gosec
+	engine.PutUint64(b[8:], uint64(maxTs)) //nolint: gosec
+
+	return b
+}
+
+// timeBounds returns the earliest and latest timestamps of the blob, in the
+// blob's timestamp unit, from the time bounds record if present or by scanning
+// all timestamps otherwise. It returns false for a blob without data points.
+func (b NumericBlob) timeBounds() (minTs, maxTs int64, ok bool) {
+	if v, found := b.metadata.Get(section.MetadataKeyTimeBounds); found && len(v) == timeBoundsSize {
+		engine := b.Engine()
+
+		return int64(engine.Uint64(v)), int64(engine.Uint64(v[8:])), true //nolint: gosec
+	}
+
+	b.index.ForEach(func(entry section.NumericIndexEntry) bool {
+		for ts := range b.allTimestampsFromEntry(entry) {
+			if !ok {
+				minTs, maxTs, ok = ts, ts, true
+			}
+			minTs, maxTs = min(minTs, ts), max(maxTs, ts)
+		}
+
+		return true
+	})
+
+	return minTs, maxTs, ok
+}
+
+// HasTimeBounds reports whether the blob records its time bounds (see
+// WithTimeBounds), so TimeRange and EndTime answer without decoding timestamps.
+func (b NumericBlob) HasTimeBounds() bool {
+	v, ok := b.metadata.Get(section.MetadataKeyTimeBounds)
+
+	return ok && len(v) == timeBoundsSize
+}
+
+// TimeRange returns the times of the earliest and latest data points in the blob.
+//
+// Unlike StartTime, which is the reference time of the blob header, the range
+// covers the actual data points, so it can be used to skip blobs that do not
+// overlap a query window. Blobs encoded with WithTimeBounds answer from their
+// metadata; other blobs scan their timestamps.
+//
+// Returns:
+//   - start: Time of the earliest data point, zero if the blob has no data points
+//   - end: Time of the latest data point (inclusive), zero if the blob has no data points
+//
+// Example:
+//
+//	start, end := blob.TimeRange()
+//	if end.Before(queryStart) || !start.Before(queryEnd) {
+//	    continue // no data point in [queryStart, queryEnd)
+//	}
+func (b NumericBlob) TimeRange() (start, end time.Time) {
+	minTs, maxTs, ok := b.timeBounds()
+	if !ok {
+		return time.Time{}, time.Time{}
+	}
+
+	unit := b.TimestampUnit()
+
+	return unit.Time(minTs), unit.Time(maxTs)
+}
+
+// EndTime returns the time of the latest data point in the blob, or the zero
+// time if the blob has no data points. See TimeRange.
+func (b NumericBlob) EndTime() time.Time {
+	_, end := b.TimeRange()
+
+	return end
+}
@@ -0,0 +1,95 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func TestNumericBlob_TimeRange(t *testing.T) {
+	plain := encodeTrimTestBlob(t)
+	bounded := encodeTrimTestBlob(t, WithTimeBounds(true))
+	require.Len(t, bounded, len(plain)+22)
+
+	for _, data := range [][]byte{plain, bounded} {
+		blob := decodeRemapTestBlob(t, data)
+		require.Equal(t, len(data) == len(bounded), blob.HasTimeBounds())
+
+		start, end := blob.TimeRange()
+		require.Equal(t, trimTestStart.UTC(), start)
+		require.Equal(t, trimTestStart.Add(59*time.Second).UTC(), end)
+		require.Equal(t, end, blob.EndTime())
+	}
+
+	start, end := NumericBlob{}.TimeRange()
+	require.True(t, start.IsZero())
+	require.True(t, end.IsZero())
+}
+
+func TestNumericBlob_TimeRange_Unordered(t *testing.T) {
+	encoder, err := NewNumericEncoder(trimTestStart, WithTimeBounds(true), WithTimestampUnit(format.TimeUnitMillisecond))
+	require.NoError(t, err)
+
+	base := trimTestStart.UnixMilli()
+	require.NoError(t, encoder.StartMetricID(1, 3))
+	require.NoError(t, encoder.AddDataPoint(base+5000, 1, ""))
+	require.NoError(t, encoder.AddDataPoints([]int64{base - 2000, base + 1000}, []float64{2, 3}, nil))
+	require.NoError(t, encoder.EndMetric())
+	require.NoError(t, encoder.StartMetricID(2, 2))
+	require.NoError(t, encoder.AddDataPoints([]int64{base + 9000, base}, []float64{4, 5}, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	maxSize := encoder.MaxFinishedSize()
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), maxSize)
+
+	blob := decodeRemapTestBlob(t, data)
+	require.True(t, blob.HasTimeBounds())
+	start, end := blob.TimeRange()
+	require.Equal(t, trimTestStart.Add(-2*time.Second).UTC(), start)
+	require.Equal(t, trimTestStart.Add(9*time.Second).UTC(), end)
+}
+
+func TestNumericBlob_TimeRange_Trim(t *testing.T) {
+	blob := decodeRemapTestBlob(t, encodeTrimTestBlob(t, WithTimeBounds(true)))
+
+	trimmed, err := blob.Trim(trimTestStart.Add(10*time.Second), trimTestStart.Add(20*time.Second))
+	require.NoError(t, err)
+	require.True(t, trimmed.HasTimeBounds())
+
+	start, end := trimmed.TimeRange()
+	require.Equal(t, trimTestStart.Add(10*time.Second).UTC(), start)
+	require.Equal(t, trimTestStart.Add(19*time.Second).UTC(), end)
+}
+
+func TestBlobEditor_TimeBounds(t *testing.T) {
+	data := createEditorTestBlob(t, WithTimeBounds(true))
+
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	ts := editorStartTime.Add(20 * time.Second).UnixMicro()
+	require.NoError(t, editor.AppendDataPointsByName("mem", []int64{ts}, []float64{1}, nil))
+	appended, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, _ := decodeEditorTestBlob(t, appended)
+	require.True(t, blob.HasTimeBounds())
+	require.Equal(t, editorStartTime.Add(20*time.Second).UTC(), blob.EndTime())
+
+	// Dropping the metric shrinks the bounds back to the remaining metrics.
+	editor, err = NewBlobEditor(appended)
+	require.NoError(t, err)
+	require.NoError(t, editor.DropMetricByName("mem"))
+	dropped, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob, _ = decodeEditorTestBlob(t, dropped)
+	require.True(t, blob.HasTimeBounds())
+	start, end := blob.TimeRange()
+	require.Equal(t, editorStartTime.UTC(), start)
+	require.Equal(t, editorStartTime.Add(9*time.Second).UTC(), end)
+}
@@ -0,0 +1,105 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/sketch"
+	"github.com/arloliu/mebo/section"
+)
+
+// HasValueSums reports whether the blob records value sums (see WithValueSums).
+func (b NumericBlob) HasValueSums() bool {
+	_, ok := b.metadata.Get(section.MetadataKeyValueSums)
+
+	return ok
+}
+
+// ValueSum returns the recorded sum and count of the values of the given metric
+// ID, without decoding any value. The sum was computed with compensated (Kahan)
+// summation at encode time; NaN values are neither summed nor counted.
+//
+// Parameters:
+//   - metricID: The metric ID to look up
+//
+// Returns:
+//   - float64: The sum of the metric's non-NaN values
+//   - int: The number of non-NaN values
+//   - bool: false if the blob was not encoded with WithValueSums or has no such metric
+//
+// Example:
+//
+//	if sum, count, ok := blob.ValueSum(metricID); ok && count > 0 {
+//	    fmt.Printf("mean: %.3f\n", sum/float64(count))
+//	}
+func (b NumericBlob) ValueSum(metricID uint64) (float64, int, bool) {
+	sum, ok := b.valueSum(metricID)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return sum.Value(), int(sum.Count()), true //nolint: gosec
+}
+
+// ValueSumByName returns the recorded sum and count of the values of the given
+// metric name. See ValueSum for details.
+//
+// Parameters:
+//   - metricName: The metric name to look up
+//
+// Returns:
+//   - float64: The sum of the metric's non-NaN values
+//   - int: The number of non-NaN values
+//   - bool: false if the blob was not encoded with WithValueSums or has no such metric
+func (b NumericBlob) ValueSumByName(metricName string) (float64, int, bool) {
+	entry, ok := b.lookupMetricEntry(metricName)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return b.ValueSum(entry.MetricID)
+}
+
+// valueSum returns the recorded compensated sum of the metric.
+func (b NumericBlob) valueSum(metricID uint64) (sketch.KahanSum, bool) {
+	record, ok := b.metadata.Get(section.MetadataKeyValueSums)
+	if !ok {
+		return sketch.KahanSum{}, false
+	}
+
+	body, ok := findMetricBody(record, metricID, b.Engine())
+	if !ok {
+		return sketch.KahanSum{}, false
+	}
+
+	sum, err := parseValueSumBody(body, b.Engine())
+	if err != nil {
+		return sketch.KahanSum{}, false
+	}
+
+	return sum, true
+}
+
+// appendValueSumBody appends the serialized body of the sum to dst.
+func appendValueSumBody(dst []byte, sum sketch.KahanSum, engine endian.EndianEngine) []byte {
+	dst = binary.AppendUvarint(dst, sum.Count())
+	dst = engine.AppendUint64(dst, math.Float64bits(sum.Sum()))
+
+	return engine.AppendUint64(dst, math.Float64bits(sum.Compensation()))
+}
+
+// parseValueSumBody parses a serialized value sum body.
+func parseValueSumBody(data []byte, engine endian.EndianEngine) (sketch.KahanSum, error) {
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > math.MaxInt64 || len(data)-n < 16 {
+		return sketch.KahanSum{}, fmt.Errorf("%w: value sum body truncated", errs.ErrInvalidMetadata)
+	}
+
+	sum := math.Float64frombits(engine.Uint64(data[n:]))
+	comp := math.Float64frombits(engine.Uint64(data[n+8:]))
+
+	return sketch.NewKahanSum(sum, comp, count), nil
+}
@@ -0,0 +1,106 @@
+package blob
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// encodeValueSumTestBlob encodes metrics "a", added point by point, and "b",
+// added as a batch, each holding count data points with value 0.1 and a NaN
+// at every 10th point.
+func encodeValueSumTestBlob(t *testing.T, start time.Time, count int, opts ...NumericEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewNumericEncoder(start, append([]NumericEncoderOption{WithValueSums(true)}, opts...)...)
+	require.NoError(t, err)
+
+	timestamps := make([]int64, count)
+	values := make([]float64, count)
+	for i := range count {
+		timestamps[i] = start.Add(time.Duration(i) * time.Second).UnixMicro()
+		values[i] = 0.1
+		if i%10 == 0 {
+			values[i] = math.NaN()
+		}
+	}
+
+	require.NoError(t, encoder.StartMetricName("a", count))
+	for i := range count {
+		require.NoError(t, encoder.AddDataPoint(timestamps[i], values[i], ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+
+	require.NoError(t, encoder.StartMetricName("b", count))
+	require.NoError(t, encoder.AddDataPoints(timestamps, values, nil))
+	require.NoError(t, encoder.EndMetric())
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestNumericBlob_ValueSum(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blob := decodeRemapTestBlob(t, encodeValueSumTestBlob(t, start, 8000))
+	require.True(t, blob.HasValueSums())
+
+	for _, name := range []string{"a", "b"} {
+		sum, count, ok := blob.ValueSumByName(name)
+		require.True(t, ok)
+		require.Equal(t, 7200, count)
+		require.Equal(t, 720.0, sum)
+	}
+
+	_, _, ok := blob.ValueSum(hash.ID("missing"))
+	require.False(t, ok)
+
+	plain := decodeRemapTestBlob(t, encodeColdTestBlob(t, start, 1))
+	require.False(t, plain.HasValueSums())
+	_, _, ok = plain.ValueSum(plain.MetricIDs()[0])
+	require.False(t, ok)
+
+	_, err := NewInt64Encoder(start, WithValueSums(true))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
+
+func TestNumericBlob_ValueSumFollowsRewrites(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	data := encodeValueSumTestBlob(t, start, 100)
+
+	// Remapped IDs keep their sums.
+	remapped, err := RemapMetricIDs(data, map[uint64]uint64{hash.ID("a"): 42})
+	require.NoError(t, err)
+	_, count, ok := decodeRemapTestBlob(t, remapped).ValueSum(42)
+	require.True(t, ok)
+	require.Equal(t, 90, count)
+
+	// Edits rebuild the sums of appended metrics and drop those of dropped ones.
+	editor, err := NewBlobEditor(data)
+	require.NoError(t, err)
+	require.NoError(t, editor.AppendDataPoints(hash.ID("a"), []int64{start.Add(time.Hour).UnixMicro()}, []float64{1}, nil))
+	require.NoError(t, editor.DropMetric(hash.ID("b")))
+	edited, err := editor.Finish()
+	require.NoError(t, err)
+
+	blob := decodeRemapTestBlob(t, edited)
+	sum, count, ok := blob.ValueSumByName("a")
+	require.True(t, ok)
+	require.Equal(t, 91, count)
+	require.InDelta(t, 10.0, sum, 1e-12)
+	_, _, ok = blob.ValueSumByName("b")
+	require.False(t, ok)
+
+	// Trimmed blobs recompute the sums of the kept data points.
+	trimmed, err := decodeRemapTestBlob(t, data).Trim(start, start.Add(20*time.Second))
+	require.NoError(t, err)
+	_, count, ok = trimmed.ValueSumByName("b")
+	require.True(t, ok)
+	require.Equal(t, 18, count)
+}
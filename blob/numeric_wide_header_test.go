@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// encodeWideHeaderBlob encodes the same metrics with the v1 header and, forced,
+// with the wide header.
+func encodeWideHeaderBlob(t *testing.T, opts ...NumericEncoderOption) (v1, wide []byte) {
+	t.Helper()
+
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	encode := func(forceWide bool) []byte {
+		encoder, err := NewNumericEncoder(startTime, opts...)
+		require.NoError(t, err)
+		encoder.header.Wide = forceWide
+
+		for id := uint64(1); id <= 3; id++ {
+			require.NoError(t, encoder.StartMetricID(id, 10))
+			for i := range 10 {
+				ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+				require.NoError(t, encoder.AddDataPoint(ts, float64(id)*1.5+float64(i), "host"))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	return encode(false), encode(true)
+}
+
+func TestNumericEncoder_WideHeader(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "v1", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "big endian", opts: []NumericEncoderOption{WithTagsEnabled(true), WithBigEndian()}},
+		{name: "shared timestamps", opts: []NumericEncoderOption{WithTagsEnabled(true), WithSharedTimestamps()}},
+		{name: "metadata", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTimestampUnit(format.TimeUnitMillisecond)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1, wide := encodeWideHeaderBlob(t, tt.opts...)
+			require.Len(t, wide, len(v1)+section.WideHeaderSize-section.HeaderSize)
+
+			v1Header, err := section.ParseNumericHeader(v1)
+			require.NoError(t, err)
+			require.False(t, v1Header.Wide)
+
+			wideHeader, err := section.ParseNumericHeader(wide)
+			require.NoError(t, err)
+			require.True(t, wideHeader.Wide)
+			require.Equal(t, v1Header.IndexOffset+32, wideHeader.IndexOffset)
+			require.Equal(t, v1Header.TagPayloadOffset+32, wideHeader.TagPayloadOffset)
+
+			// Everything after the header is identical.
+			require.Equal(t, v1[section.HeaderSize:], wide[section.WideHeaderSize:])
+
+			want := decodeNumericBlob(t, v1)
+			got := decodeNumericBlob(t, wide)
+			for id := uint64(1); id <= 3; id++ {
+				require.Equal(t, collectNumeric(want, id), collectNumeric(got, id))
+			}
+
+			id, err := NumericBlobID(wide)
+			require.NoError(t, err)
+			require.NotZero(t, id)
+		})
+	}
+}
+
+func TestNumericEncoder_WideHeaderRewrites(t *testing.T) {
+	_, wide := encodeWideHeaderBlob(t, WithTagsEnabled(true))
+
+	t.Run("parts", func(t *testing.T) {
+		parts, err := SplitNumericBlob(wide)
+		require.NoError(t, err)
+		header, err := section.ParseNumericHeader(wide)
+		require.NoError(t, err)
+		require.Len(t, parts.Head, int(header.TimestampPayloadOffset))
+
+		decoder, err := NewNumericDecoderFromParts(parts)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		require.Equal(t, collectNumeric(decodeNumericBlob(t, wide), 2), collectNumeric(blob, 2))
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		remapped, err := RemapMetricIDs(wide, map[uint64]uint64{2: 20})
+		require.NoError(t, err)
+
+		blob := decodeNumericBlob(t, remapped)
+		require.Equal(t, collectNumeric(decodeNumericBlob(t, wide), 2), collectNumeric(blob, 20))
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		editor, err := NewBlobEditor(wide)
+		require.NoError(t, err)
+		require.NoError(t, editor.DropMetric(3))
+
+		edited, err := editor.Finish()
+		require.NoError(t, err)
+
+		// Small blobs return to the v1 header when rewritten.
+		header, err := section.ParseNumericHeader(edited)
+		require.NoError(t, err)
+		require.False(t, header.Wide)
+
+		blob := decodeNumericBlob(t, edited)
+		require.False(t, blob.HasMetricID(3))
+		require.Equal(t, collectNumeric(decodeNumericBlob(t, wide), 1), collectNumeric(blob, 1))
+	})
+}
+
+func TestNumericEncoder_WideHeaderMaxFinishedSize(t *testing.T) {
+	encoder, err := NewNumericEncoder(time.Now())
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.NoError(t, encoder.AddDataPoint(1, 1, ""))
+	require.NoError(t, encoder.EndMetric())
+
+	v1Bound := encoder.MaxFinishedSize()
+	encoder.header.Wide = true
+	wideBound := encoder.MaxFinishedSize()
+	require.Equal(t, v1Bound+section.WideHeaderSize-section.HeaderSize, wideBound)
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(data), wideBound)
+}
+
+func decodeNumericBlob(t *testing.T, data []byte) NumericBlob {
+	t.Helper()
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return blob
+}
+
+func collectNumeric(blob NumericBlob, metricID uint64) []NumericDataPoint {
+	var points []NumericDataPoint
+	for _, dp := range blob.All(metricID) {
+		points = append(points, dp)
+	}
+
+	return points
+}
@@ -0,0 +1,326 @@
+package blob
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	ienc "github.com/arloliu/mebo/internal/encoding"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
+)
+
+// QuickVerify checks the structural integrity of an encoded numeric or text blob
+// without decompressing its payloads, e.g. in object store lifecycle hooks that
+// verify blobs after upload.
+//
+// It checks the header magic number and flags, the bounds of every section, the
+// metric names and their hashes, the metadata records and the index entries.
+// Zstd payloads are walked frame by frame and block by block, which detects
+// truncated uploads, and their declared content sizes are checked against the
+// index like the sizes of uncompressed payloads. LZ4 and S2 payloads are only
+// bounds-checked.
+//
+// mebo blobs carry no payload checksums, and Zstd frame checksums can only be
+// verified by decompressing, so QuickVerify cannot detect bit flips inside
+// payloads. Use WriteFileAtomic and ReadSealed, or the checksums of the object
+// store, for end-to-end integrity, and Decode for a full check.
+//
+// Parameters:
+//   - data: Encoded numeric or text blob
+//
+// Returns:
+//   - error: nil if the blob is well-formed, ErrInvalidMagicNumber for data
+//     that is not a mebo blob, or the decoding error of the first malformed section
+//
+// Example:
+//
+//	if err := blob.QuickVerify(uploaded); err != nil {
+//	    return fmt.Errorf("upload of %s is corrupt: %w", key, err)
+//	}
+func QuickVerify(data []byte) error {
+	switch {
+	case section.IsNumericBlob(data):
+		return quickVerifyNumeric(data)
+	case section.IsTextBlob(data):
+		return quickVerifyText(data)
+	default:
+		return fmt.Errorf("%w: not a numeric or text blob", errs.ErrInvalidMagicNumber)
+	}
+}
+
+// quickVerifyNumeric checks the sections of a numeric blob without decompressing payloads.
+func quickVerifyNumeric(data []byte) error {
+	d, err := NewNumericDecoder(data)
+	if err != nil {
+		return err
+	}
+	data = d.data // small blobs are verified in the standard layout
+
+	tsOffset := int(d.header.TimestampPayloadOffset) //nolint: gosec // Parse bounds offsets to the int range
+	valOffset := int(d.header.ValuePayloadOffset)    //nolint: gosec
+	tagOffset := int(d.header.TagPayloadOffset)      //nolint: gosec
+	switch {
+	case tsOffset > valOffset:
+		return fmt.Errorf("%w: %d after value payload offset %d", errs.ErrInvalidTimestampPayloadOffset, tsOffset, valOffset)
+	case valOffset > tagOffset:
+		return fmt.Errorf("%w: %d after tag payload offset %d", errs.ErrInvalidValuePayloadOffset, valOffset, tagOffset)
+	case tagOffset > len(data):
+		return fmt.Errorf("%w: %d exceeds blob length %d", errs.ErrInvalidTagPayloadOffset, tagOffset, len(data))
+	}
+
+	var metricNames []string
+	indexOffset := d.header.Size()
+	if d.header.Flag.HasCompressedMetricNames() {
+		_, namesSize, err := compressedMetricNames(data[indexOffset:], d.engine)
+		if err != nil {
+			return err
+		}
+		indexOffset += namesSize
+	} else if metricNames, indexOffset, err = d.parseMetricNames(); err != nil {
+		return err
+	}
+
+	metadata, indexOffset, err := d.parseMetadata(indexOffset)
+	if err != nil {
+		return err
+	}
+	if _, err := parseMetricMeta(metadata, d.engine); err != nil {
+		return err
+	}
+	if _, _, err := d.metricReferences(metadata); err != nil {
+		return err
+	}
+
+	indexEnd := indexOffset + d.metricCount*d.header.Flag.IndexEntrySize()
+	if indexEnd > tsOffset || (!d.header.Flag.HasSharedTimestamps() && indexEnd != tsOffset) {
+		return fmt.Errorf("%w: index section ends at %d, timestamp payload starts at %d",
+			errs.ErrInvalidIndexEntrySize, indexEnd, tsOffset)
+	}
+
+	tsSize, err := payloadSize(d.header.Flag.TimestampCompression(), data[tsOffset:valOffset])
+	if err != nil {
+		return fmt.Errorf("timestamp payload: %w", err)
+	}
+	valSize, err := payloadSize(d.header.Flag.ValueCompression(), data[valOffset:tagOffset])
+	if err != nil {
+		return fmt.Errorf("value payload: %w", err)
+	}
+	tagSize := 0
+	if d.header.Flag.HasTag() {
+		if tagSize, err = payloadSize(tagCompression(metadata), data[tagOffset:]); err != nil {
+			return fmt.Errorf("tag payload: %w", err)
+		}
+	}
+
+	entries, metricIDs, err := d.parseIndexEntries(indexOffset, tsSize, valSize, tagSize, len(metricNames) > 0)
+	if err != nil {
+		return err
+	}
+
+	if d.header.Flag.HasSharedTimestamps() {
+		if err := section.ApplySharedTimestampTable(data[indexEnd:tsOffset], d.engine, d.metricCount, entries); err != nil {
+			return err
+		}
+	}
+
+	if len(metricNames) > 0 {
+		if err := ienc.VerifyMetricNamesHashes(metricNames, metricIDs, hash.ID); err != nil {
+			return fmt.Errorf("metric name verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// quickVerifyText checks the sections of a text or event blob without decompressing its data.
+func quickVerifyText(data []byte) error {
+	d, err := newTextDecoder(data)
+	if err != nil {
+		return err
+	}
+
+	dataOffset := int(d.header.DataOffset)
+	if dataOffset > len(data) {
+		return fmt.Errorf("%w: data offset %d exceeds data length %d", errs.ErrInvalidTimestampPayloadOffset, dataOffset, len(data))
+	}
+
+	metricNames, indexOffset, err := d.parseMetricNames()
+	if err != nil {
+		return err
+	}
+
+	_, metricIDs, err := d.parseIndexEntries(indexOffset)
+	if err != nil {
+		return err
+	}
+
+	if indexEnd := indexOffset + d.metricCount*section.TextIndexEntrySize; indexEnd != dataOffset {
+		return fmt.Errorf("%w: index section ends at %d, data starts at %d", errs.ErrInvalidIndexEntrySize, indexEnd, dataOffset)
+	}
+
+	rows, tags := data[dataOffset:], []byte(nil)
+	if d.header.Flag.HasTagSection() {
+		if rows, tags, err = splitTagSection(rows, d.engine); err != nil {
+			return err
+		}
+	}
+
+	size, err := payloadSize(d.header.Flag.GetDataCompression(), rows)
+	if err != nil {
+		return fmt.Errorf("data section: %w", err)
+	}
+	if d.header.Flag.HasTagSection() {
+		tagsSize, err := payloadSize(d.header.Flag.GetTagCompression(), tags)
+		if err != nil {
+			return fmt.Errorf("tag section: %w", err)
+		}
+		if size != math.MaxInt && tagsSize != math.MaxInt {
+			size += tagsSize
+		} else {
+			size = math.MaxInt
+		}
+	}
+	if size != math.MaxInt && size != int(d.header.DataSize) {
+		return fmt.Errorf("%w: expected %d, got %d", errs.ErrDataSizeMismatch, d.header.DataSize, size)
+	}
+
+	if len(metricNames) > 0 {
+		if err := ienc.VerifyMetricNamesHashes(metricNames, metricIDs, hash.ID); err != nil {
+			return fmt.Errorf("metric name verification failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// payloadSize returns the decompressed size of a payload section, read without
+// decompressing it, or math.MaxInt if the compression does not record it.
+// Zstd frames are walked by their block headers, which detects truncation.
+func payloadSize(comp format.CompressionType, payload []byte) (int, error) {
+	switch comp {
+	case format.CompressionNone:
+		return len(payload), nil
+	case format.CompressionZstd:
+		size, ok, err := zstdContentSize(payload)
+		if err != nil || !ok {
+			return math.MaxInt, err
+		}
+
+		return size, nil
+	default:
+		return math.MaxInt, nil
+	}
+}
+
+// Zstd frame format constants, see RFC 8878.
+const (
+	zstdMagic          = 0xFD2FB528
+	zstdSkippableMagic = 0x184D2A50 // low 4 bits are user-defined
+	zstdBlockHeader    = 3
+)
+
+// zstdContentSize walks the Zstd frames of data by their headers and block
+// headers, without decompressing them, and returns the sum of their declared
+// content sizes. ok is false if a frame does not declare its content size.
+func zstdContentSize(data []byte) (size int, ok bool, err error) {
+	ok = true
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return 0, false, fmt.Errorf("%w: truncated zstd frame magic", errs.ErrInvalidCompressedFrame)
+		}
+
+		magic := binary.LittleEndian.Uint32(data)
+		if magic&^0xF == zstdSkippableMagic {
+			if len(data) < 8 || uint64(binary.LittleEndian.Uint32(data[4:])) > uint64(len(data)-8) {
+				return 0, false, fmt.Errorf("%w: truncated zstd skippable frame", errs.ErrInvalidCompressedFrame)
+			}
+			data = data[8+int(binary.LittleEndian.Uint32(data[4:])):]
+
+			continue
+		}
+		if magic != zstdMagic {
+			return 0, false, fmt.Errorf("%w: invalid zstd magic 0x%08x", errs.ErrInvalidCompressedFrame, magic)
+		}
+
+		var frameSize int
+		var hasSize bool
+		data, frameSize, hasSize, err = walkZstdFrame(data[4:])
+		if err != nil {
+			return 0, false, err
+		}
+		size += frameSize
+		ok = ok && hasSize
+	}
+
+	return size, ok, nil
+}
+
+// walkZstdFrame walks one Zstd frame following its magic number and returns
+// the data after the frame and the declared content size.
+func walkZstdFrame(data []byte) (rest []byte, size int, hasSize bool, err error) {
+	if len(data) < 1 {
+		return nil, 0, false, fmt.Errorf("%w: truncated zstd frame header", errs.ErrInvalidCompressedFrame)
+	}
+
+	descriptor := data[0]
+	singleSegment := descriptor&0x20 != 0
+	checksum := descriptor&0x04 != 0
+	fcsSize := [4]int{0, 2, 4, 8}[descriptor>>6]
+	if fcsSize == 0 && singleSegment {
+		fcsSize = 1
+	}
+	headerSize := 1 + [4]int{0, 1, 2, 4}[descriptor&0x03] + fcsSize
+	if !singleSegment {
+		headerSize++ // window descriptor
+	}
+	if len(data) < headerSize {
+		return nil, 0, false, fmt.Errorf("%w: truncated zstd frame header", errs.ErrInvalidCompressedFrame)
+	}
+
+	if fcsSize > 0 {
+		var fcs uint64
+		for i, b := range data[headerSize-fcsSize : headerSize] {
+			fcs |= uint64(b) << (8 * i)
+		}
+		if fcsSize == 2 {
+			fcs += 256
+		}
+		if fcs > math.MaxInt {
+			return nil, 0, false, fmt.Errorf("%w: zstd content size %d overflows", errs.ErrInvalidCompressedFrame, fcs)
+		}
+		size, hasSize = int(fcs), true
+	}
+
+	data = data[headerSize:]
+	for last := false; !last; {
+		if len(data) < zstdBlockHeader {
+			return nil, 0, false, fmt.Errorf("%w: truncated zstd block header", errs.ErrInvalidCompressedFrame)
+		}
+
+		header := int(data[0]) | int(data[1])<<8 | int(data[2])<<16
+		last = header&1 != 0
+		blockSize := header >> 3
+		switch (header >> 1) & 0x3 {
+		case 1: // RLE block stores a single byte
+			blockSize = 1
+		case 3:
+			return nil, 0, false, fmt.Errorf("%w: reserved zstd block type", errs.ErrInvalidCompressedFrame)
+		}
+		if len(data)-zstdBlockHeader < blockSize {
+			return nil, 0, false, fmt.Errorf("%w: truncated zstd block", errs.ErrInvalidCompressedFrame)
+		}
+		data = data[zstdBlockHeader+blockSize:]
+	}
+
+	if checksum {
+		if len(data) < 4 {
+			return nil, 0, false, fmt.Errorf("%w: truncated zstd content checksum", errs.ErrInvalidCompressedFrame)
+		}
+		data = data[4:]
+	}
+
+	return data, size, hasSize, nil
+}
@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+func TestQuickVerify_Valid(t *testing.T) {
+	blobs := map[string][]byte{
+		"default":      encodeTrimTestBlob(t),
+		"uncompressed": encodeTrimTestBlob(t, WithTimestampCompression(format.CompressionNone), WithValueCompression(format.CompressionNone), WithTagCompression(format.CompressionNone)),
+		"shared":       encodeTrimTestBlob(t, WithSharedTimestamps()),
+		"aligned":      encodeTrimTestBlob(t, WithWordAlignedOffsets(), WithTimestampCompression(format.CompressionNone)),
+		"time bounds":  encodeTrimTestBlob(t, WithTimeBounds(true)),
+		"lz4":          encodeTrimTestBlob(t, WithTimestampCompression(format.CompressionLZ4), WithValueCompression(format.CompressionLZ4)),
+		"s2":           encodeTrimTestBlob(t, WithTimestampCompression(format.CompressionS2), WithTagCompression(format.CompressionS2)),
+		"name mode":    createEditorTestBlob(t, WithTagsEnabled(true)),
+	}
+
+	textEncoder, err := NewTextEncoder(trimTestStart, WithTextDataCompression(format.CompressionZstd))
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricName("log", 2))
+	require.NoError(t, textEncoder.AddDataPoint(trimTestStart.UnixMicro(), "a", ""))
+	require.NoError(t, textEncoder.AddDataPoint(trimTestStart.UnixMicro()+1, "b", ""))
+	require.NoError(t, textEncoder.EndMetric())
+	blobs["text"], err = textEncoder.Finish()
+	require.NoError(t, err)
+
+	for name, data := range blobs {
+		require.NoError(t, QuickVerify(data), name)
+	}
+}
+
+func TestQuickVerify_Corrupt(t *testing.T) {
+	data := encodeTrimTestBlob(t, WithTimestampCompression(format.CompressionNone), WithValueCompression(format.CompressionNone))
+	header, err := section.ParseNumericHeader(data)
+	require.NoError(t, err)
+	engine := header.Flag.GetEndianEngine()
+
+	require.ErrorIs(t, QuickVerify([]byte("not a blob")), errs.ErrInvalidMagicNumber)
+
+	// Truncated uploads are rejected at any length, without panicking.
+	for n := range len(data) {
+		require.Error(t, QuickVerify(data[:n]), "length %d", n)
+	}
+
+	// A corrupt Zstd frame is detected without decompressing it.
+	zstdData := encodeTrimTestBlob(t)
+	zstdHeader, err := section.ParseNumericHeader(zstdData)
+	require.NoError(t, err)
+	require.Equal(t, format.CompressionZstd, zstdHeader.Flag.ValueCompression())
+	zstdData[zstdHeader.ValuePayloadOffset] ^= 0xff
+	require.ErrorIs(t, QuickVerify(zstdData), errs.ErrInvalidCompressedFrame)
+
+	// Payload offsets out of order.
+	swapped := slices.Clone(data)
+	engine.PutUint32(swapped[20:24], uint32(header.ValuePayloadOffset+1)) //nolint: gosec
+	require.ErrorIs(t, QuickVerify(swapped), errs.ErrInvalidTimestampPayloadOffset)
+
+	// An uncompressed payload shorter than the index claims.
+	short := slices.Clone(data)
+	engine.PutUint32(short[24:28], uint32(header.TimestampPayloadOffset+1)) //nolint: gosec
+	require.ErrorIs(t, QuickVerify(short), errs.ErrInvalidIndexOffsets)
+}
+
+func TestQuickVerify_MetricNames(t *testing.T) {
+	// A detected collision makes the encoder store metric names
+	encoder, err := NewNumericEncoder(editorStartTime)
+	require.NoError(t, err)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		require.NoError(t, encoder.StartMetricName(name, 1))
+		require.NoError(t, encoder.AddDataPoint(editorStartTime.UnixMicro(), 1, ""))
+		require.NoError(t, encoder.EndMetric())
+	}
+	encoder.hasCollision = true
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.NoError(t, QuickVerify(data))
+
+	// A corrupt name no longer matches its metric ID.
+	corrupt := slices.Clone(data)
+	at := bytes.Index(corrupt, []byte("beta"))
+	require.Positive(t, at)
+	corrupt[at] = 'z'
+	require.ErrorIs(t, QuickVerify(corrupt), errs.ErrHashMismatch)
+}
@@ -0,0 +1,368 @@
+package blob
+
+import (
+	"iter"
+	"sync/atomic"
+	"time"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// DecodeSection identifies a payload section decoded by a decoder.
+type DecodeSection uint8
+
+const (
+	// SectionTimestamps is the timestamp payload of a numeric blob.
+	SectionTimestamps DecodeSection = iota
+	// SectionValues is the value payload of a numeric blob.
+	SectionValues
+	// SectionTags is the tag payload of a numeric blob.
+	SectionTags
+	// SectionTextData is the row data payload of a text blob.
+	SectionTextData
+	// SectionTextTags is the separate tag section of a text blob.
+	SectionTextTags
+)
+
+// String returns the name of the section.
+func (s DecodeSection) String() string {
+	switch s {
+	case SectionTimestamps:
+		return "Timestamps"
+	case SectionValues:
+		return "Values"
+	case SectionTags:
+		return "Tags"
+	case SectionTextData:
+		return "TextData"
+	case SectionTextTags:
+		return "TextTags"
+	default:
+		return "Unknown"
+	}
+}
+
+// SectionEvent describes the decompression of one payload section by Decode.
+type SectionEvent struct {
+	// Section is the decoded section.
+	Section DecodeSection
+
+	// Compression is the compression of the stored section.
+	Compression format.CompressionType
+
+	// StoredBytes is the size of the section in the blob.
+	StoredBytes int
+
+	// DecodedBytes is the size of the decompressed section.
+	DecodedBytes int
+
+	// Duration is the time spent decompressing the section.
+	Duration time.Duration
+}
+
+// ScanEvent describes one iteration over a metric of a BlobSet.
+type ScanEvent struct {
+	// MetricID is the scanned metric ID, the hash of Name for by-name scans.
+	MetricID uint64
+
+	// Name is the scanned metric name, or empty for scans by ID.
+	Name string
+
+	// IsText reports whether the scan iterated text blobs.
+	IsText bool
+
+	// Blobs is the number of blobs holding the metric.
+	Blobs int
+
+	// Skipped is the number of blobs of the scanned kind that do not hold the
+	// metric. The scan rules them out by their metric index without decoding any
+	// of their data.
+	Skipped int
+
+	// Bytes is the decoded payload size of the metric summed over those blobs.
+	Bytes int
+
+	// Points is the number of data points yielded.
+	Points int
+
+	// Completed reports whether the iteration ran to the end rather than being
+	// stopped by the caller.
+	Completed bool
+
+	// Duration is the wall time of the iteration, including the time spent in
+	// the caller's loop body.
+	Duration time.Duration
+}
+
+// ReadHooks receives read-path instrumentation events, e.g. to feed
+// OpenTelemetry spans or latency histograms without wrapping every call site.
+//
+// Hooks are called synchronously on the reading goroutine and must be safe for
+// concurrent use when the instrumented blob set is read concurrently. Embed
+// NopReadHooks to implement only some of the methods.
+type ReadHooks interface {
+	// OnDecodeSection is called after Decode decompresses a payload section.
+	OnDecodeSection(event SectionEvent)
+
+	// OnMetricScan is called when an iteration over a metric of a BlobSet ends.
+	OnMetricScan(event ScanEvent)
+}
+
+// NopReadHooks implements ReadHooks with methods that do nothing.
+type NopReadHooks struct{}
+
+var _ ReadHooks = NopReadHooks{}
+
+// OnDecodeSection does nothing.
+func (NopReadHooks) OnDecodeSection(SectionEvent) {}
+
+// OnMetricScan does nothing.
+func (NopReadHooks) OnMetricScan(ScanEvent) {}
+
+// ScanCounters is a ReadHooks that accumulates read statistics, e.g. to verify
+// that sparse metric scans skip the blobs lacking the metric. It is safe for
+// concurrent use; the zero value is ready to use.
+//
+// Example:
+//
+//	counters := &blob.ScanCounters{}
+//	blobSet = blobSet.WithReadHooks(counters)
+//	for _, dp := range blobSet.AllNumerics(metricID) {
+//	    // ...
+//	}
+//	stats := counters.Stats()
+//	fmt.Printf("scanned %d blobs, skipped %d\n", stats.Blobs, stats.Skipped)
+type ScanCounters struct {
+	sections     atomic.Int64
+	decodedBytes atomic.Int64
+	scans        atomic.Int64
+	blobs        atomic.Int64
+	skipped      atomic.Int64
+	points       atomic.Int64
+}
+
+var _ ReadHooks = (*ScanCounters)(nil)
+
+// ScanStats is a snapshot of ScanCounters.
+type ScanStats struct {
+	// Sections is the number of payload sections decompressed by decoders.
+	Sections int64
+
+	// DecodedBytes is the decompressed size of those sections.
+	DecodedBytes int64
+
+	// Scans is the number of metric scans.
+	Scans int64
+
+	// Blobs is the number of blobs holding the scanned metrics, summed over scans.
+	Blobs int64
+
+	// Skipped is the number of blobs skipped for lacking the scanned metrics,
+	// summed over scans.
+	Skipped int64
+
+	// Points is the number of data points yielded, summed over scans.
+	Points int64
+}
+
+// SkipRatio returns the fraction of the blobs considered by the scans that were
+// skipped, or 0 if no blob was considered.
+func (s ScanStats) SkipRatio() float64 {
+	total := s.Blobs + s.Skipped
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Skipped) / float64(total)
+}
+
+// OnDecodeSection counts the decompressed section.
+func (c *ScanCounters) OnDecodeSection(event SectionEvent) {
+	c.sections.Add(1)
+	c.decodedBytes.Add(int64(event.DecodedBytes))
+}
+
+// OnMetricScan counts the scan and its blobs and points.
+func (c *ScanCounters) OnMetricScan(event ScanEvent) {
+	c.scans.Add(1)
+	c.blobs.Add(int64(event.Blobs))
+	c.skipped.Add(int64(event.Skipped))
+	c.points.Add(int64(event.Points))
+}
+
+// Stats returns the accumulated statistics. Counters are read individually, so
+// a snapshot taken during concurrent reads may mix events.
+//
+// Returns:
+//   - ScanStats: The current counter values
+func (c *ScanCounters) Stats() ScanStats {
+	return ScanStats{
+		Sections:     c.sections.Load(),
+		DecodedBytes: c.decodedBytes.Load(),
+		Scans:        c.scans.Load(),
+		Blobs:        c.blobs.Load(),
+		Skipped:      c.skipped.Load(),
+		Points:       c.points.Load(),
+	}
+}
+
+// Reset sets all counters to zero.
+func (c *ScanCounters) Reset() {
+	c.sections.Store(0)
+	c.decodedBytes.Store(0)
+	c.scans.Store(0)
+	c.blobs.Store(0)
+	c.skipped.Store(0)
+	c.points.Store(0)
+}
+
+// WithReadHooks reports the payload sections decompressed by Decode to hooks.
+// A nil hooks disables instrumentation.
+//
+// Example:
+//
+//	decoder, _ := blob.NewNumericDecoder(data, blob.WithReadHooks(hooks))
+func WithReadHooks(hooks ReadHooks) NumericDecoderOption {
+	return options.NoError(func(d *NumericDecoder) {
+		d.hooks = hooks
+	})
+}
+
+// WithTextReadHooks reports the data section decompressed by Decode to hooks.
+// A nil hooks disables instrumentation.
+//
+// Example:
+//
+//	decoder, _ := blob.NewTextDecoder(data, blob.WithTextReadHooks(hooks))
+func WithTextReadHooks(hooks ReadHooks) TextDecoderOption {
+	return options.NoError(func(d *TextDecoder) {
+		d.hooks = hooks
+	})
+}
+
+// WithReadHooks returns a copy of the BlobSet that reports every iteration of
+// AllNumerics, AllNumericsByName, AllTexts and AllTextsByName to hooks. A nil
+// hooks disables instrumentation.
+//
+// Parameters:
+//   - hooks: Receiver of the scan events
+//
+// Returns:
+//   - BlobSet: A new BlobSet sharing the blobs of bs
+//
+// Example:
+//
+//	blobSet = blobSet.WithReadHooks(hooks)
+//	for _, dp := range blobSet.AllNumerics(metricID) { // reported on loop exit
+//	    // ...
+//	}
+func (bs BlobSet) WithReadHooks(hooks ReadHooks) BlobSet {
+	bs.hooks = hooks
+	return bs
+}
+
+// decompressSection decompresses a payload section, reporting it to hooks if set.
+func decompressSection(hooks ReadHooks, section DecodeSection, compression format.CompressionType, stored []byte, decompress func([]byte) ([]byte, error)) ([]byte, error) {
+	if hooks == nil {
+		return decompress(stored)
+	}
+
+	start := time.Now()
+	decoded, err := decompress(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks.OnDecodeSection(SectionEvent{
+		Section:      section,
+		Compression:  compression,
+		StoredBytes:  len(stored),
+		DecodedBytes: len(decoded),
+		Duration:     time.Since(start),
+	})
+
+	return decoded, nil
+}
+
+// observeNumericScan wraps seq to report the scan of a numeric metric to the
+// hooks of bs. An empty metricName denotes a scan by metricID.
+func (bs BlobSet) observeNumericScan(metricID uint64, metricName string, seq iter.Seq2[int, NumericDataPoint]) iter.Seq2[int, NumericDataPoint] {
+	if bs.hooks == nil {
+		return seq
+	}
+
+	event := ScanEvent{MetricID: metricID, Name: metricName}
+	if metricName != "" {
+		event.MetricID = hash.ID(metricName)
+	}
+
+	for _, blob := range bs.numericBlobs {
+		entry, ok := blob.index.GetByID(metricID)
+		if metricName != "" {
+			ok = resolvesName(bs.ambiguous, blob, metricName)
+			if ok {
+				entry, ok = blob.lookupMetricEntry(metricName)
+			}
+		}
+		if ok {
+			event.Blobs++
+			event.Bytes += entry.TimestampLength + entry.ValueLength + entry.TagLength
+		} else {
+			event.Skipped++
+		}
+	}
+
+	return observeScan(bs.hooks, event, seq)
+}
+
+// observeTextScan wraps seq to report the scan of a text metric to the hooks
+// of bs. An empty metricName denotes a scan by metricID.
+func (bs BlobSet) observeTextScan(metricID uint64, metricName string, seq iter.Seq2[int, TextDataPoint]) iter.Seq2[int, TextDataPoint] {
+	if bs.hooks == nil {
+		return seq
+	}
+
+	event := ScanEvent{MetricID: metricID, Name: metricName, IsText: true}
+	if metricName != "" {
+		event.MetricID = hash.ID(metricName)
+	}
+
+	for _, blob := range bs.textBlobs {
+		entry, ok := blob.index.GetByID(metricID)
+		if metricName != "" {
+			ok = resolvesName(bs.ambiguous, blob, metricName)
+			if ok {
+				entry, ok = blob.lookupMetricEntry(metricName)
+			}
+		}
+		if ok {
+			event.Blobs++
+			event.Bytes += int(entry.Size)
+		} else {
+			event.Skipped++
+		}
+	}
+
+	return observeScan(bs.hooks, event, seq)
+}
+
+// observeScan wraps seq to count the yielded points and report event to hooks
+// when the iteration ends.
+func observeScan[V any](hooks ReadHooks, event ScanEvent, seq iter.Seq2[int, V]) iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		start := time.Now()
+		event := event
+		event.Completed = true
+		for i, v := range seq {
+			event.Points++
+			if !yield(i, v) {
+				event.Completed = false
+				break
+			}
+		}
+		event.Duration = time.Since(start)
+		hooks.OnMetricScan(event)
+	}
+}
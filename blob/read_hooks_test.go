@@ -0,0 +1,197 @@
+package blob
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+type recordingHooks struct {
+	mu       sync.Mutex
+	sections []SectionEvent
+	scans    []ScanEvent
+}
+
+func (h *recordingHooks) OnDecodeSection(event SectionEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sections = append(h.sections, event)
+}
+
+func (h *recordingHooks) OnMetricScan(event ScanEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.scans = append(h.scans, event)
+}
+
+func TestReadHooks_DecodeSections(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	t.Run("Numeric", func(t *testing.T) {
+		encoder, err := NewNumericEncoder(start, WithTagsEnabled(true), WithValueCompression(format.CompressionZstd))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricName("cpu", 50))
+		for i := range 50 {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), 1.5, "host=a"))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		hooks := &recordingHooks{}
+		decoder, err := NewNumericDecoder(data, WithReadHooks(hooks))
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.NoError(t, err)
+
+		require.Len(t, hooks.sections, 3)
+		require.Equal(t, []DecodeSection{SectionTimestamps, SectionValues, SectionTags},
+			[]DecodeSection{hooks.sections[0].Section, hooks.sections[1].Section, hooks.sections[2].Section})
+		values := hooks.sections[1]
+		require.Equal(t, format.CompressionZstd, values.Compression)
+		require.Less(t, values.StoredBytes, values.DecodedBytes)
+		require.Equal(t, "Values", values.Section.String())
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		encoder, err := NewTextEncoder(start, WithTextDataCompression(format.CompressionZstd))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(1, 20))
+		for i := range 20 {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), "status=ok", ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		hooks := &recordingHooks{}
+		decoder, err := NewTextDecoder(data, WithTextReadHooks(hooks))
+		require.NoError(t, err)
+		_, err = decoder.Decode()
+		require.NoError(t, err)
+
+		require.Len(t, hooks.sections, 1)
+		require.Equal(t, SectionTextData, hooks.sections[0].Section)
+		require.Equal(t, format.CompressionZstd, hooks.sections[0].Compression)
+	})
+}
+
+func TestReadHooks_MetricScan(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	blobs := []NumericBlob{
+		createChronologyNumericBlob(t, start, map[string][]int64{"cpu": {1, 2, 3}, "mem": {1}}),
+		createChronologyNumericBlob(t, start.Add(time.Hour), map[string][]int64{"cpu": {4, 5}}),
+	}
+
+	hooks := &recordingHooks{}
+	set := NewBlobSet(blobs, nil).WithReadHooks(hooks)
+
+	points := 0
+	for range set.AllNumericsByName("cpu") {
+		points++
+	}
+	require.Equal(t, 5, points)
+	for range set.AllNumerics(hash.ID("cpu")) {
+		break
+	}
+
+	require.Len(t, hooks.scans, 2)
+	full := hooks.scans[0]
+	require.Equal(t, "cpu", full.Name)
+	require.Equal(t, 2, full.Blobs)
+	require.Zero(t, full.Skipped)
+	require.Equal(t, 5, full.Points)
+	require.Positive(t, full.Bytes)
+	require.True(t, full.Completed)
+
+	stopped := hooks.scans[1]
+	require.Empty(t, stopped.Name)
+	require.Equal(t, 1, stopped.Points)
+	require.False(t, stopped.Completed)
+
+	// Sets without hooks and NopReadHooks report nothing.
+	points = 0
+	for range NewBlobSet(blobs, nil).AllNumericsByName("cpu") {
+		points++
+	}
+	for range NewBlobSet(blobs, nil).WithReadHooks(NopReadHooks{}).AllNumerics(hash.ID("cpu")) {
+		points++
+	}
+	require.Equal(t, 10, points)
+	require.Len(t, hooks.scans, 2)
+}
+
+func TestScanCounters_SparseMetric(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+
+	// 24 hourly blobs; "cpu" appears in 3 of them, "mem" in all.
+	blobs := make([]NumericBlob, 24)
+	for hour := range blobs {
+		metrics := map[string][]int64{"mem": {int64(hour)}}
+		if hour%8 == 0 {
+			metrics["cpu"] = []int64{int64(hour), int64(hour) + 1}
+		}
+		blobs[hour] = createChronologyNumericBlob(t, start.Add(time.Duration(hour)*time.Hour), metrics)
+	}
+
+	counters := &ScanCounters{}
+	set := NewBlobSet(blobs, nil).WithReadHooks(counters)
+
+	points := 0
+	for range set.AllNumerics(hash.ID("cpu")) {
+		points++
+	}
+	require.Equal(t, 6, points)
+
+	stats := counters.Stats()
+	require.Equal(t, int64(1), stats.Scans)
+	require.Equal(t, int64(3), stats.Blobs)
+	require.Equal(t, int64(21), stats.Skipped)
+	require.Equal(t, int64(6), stats.Points)
+	require.InDelta(t, 21.0/24, stats.SkipRatio(), 1e-9)
+	require.Zero(t, stats.Sections, "scans must not decompress payloads")
+
+	for range set.AllNumericsByName("mem") {
+		points++
+	}
+	for range set.AllNumerics(hash.ID("missing")) {
+		points++
+	}
+	stats = counters.Stats()
+	require.Equal(t, int64(3), stats.Scans)
+	require.Equal(t, int64(3+24), stats.Blobs)
+	require.Equal(t, int64(21+24), stats.Skipped)
+
+	counters.Reset()
+	require.Equal(t, ScanStats{}, counters.Stats())
+	require.Zero(t, ScanStats{}.SkipRatio())
+}
+
+func TestScanCounters_DecodeSections(t *testing.T) {
+	start := time.Unix(1700000000, 0).UTC()
+	encoder, err := NewNumericEncoder(start, WithTagsEnabled(true))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 10))
+	for i := range 10 {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), float64(i), "host=a"))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	counters := &ScanCounters{}
+	decoder, err := NewNumericDecoder(data, WithReadHooks(counters))
+	require.NoError(t, err)
+	_, err = decoder.Decode()
+	require.NoError(t, err)
+
+	stats := counters.Stats()
+	require.Equal(t, int64(3), stats.Sections)
+	require.Positive(t, stats.DecodedBytes)
+	require.Zero(t, stats.Scans)
+}
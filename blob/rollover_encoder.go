@@ -0,0 +1,267 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// RolloverEncoderConfig holds RolloverEncoder rollover limits and encoder settings.
+type RolloverEncoderConfig struct {
+	targetSize  int
+	maxMetrics  int
+	encoderOpts []NumericEncoderOption
+}
+
+// RolloverOption represents a functional option for configuring a RolloverEncoder.
+type RolloverOption = options.Option[*RolloverEncoderConfig]
+
+// RolloverEncoder wraps NumericEncoder and transparently rolls over to a new
+// blob instead of exceeding a capacity limit.
+//
+// It has the metric-at-a-time API of NumericEncoder. Before a metric is
+// started, the encoder finishes the current blob and continues with a new
+// encoder when the blob holds the maximum number of metrics, has reached the
+// target size, or when ending another metric would overflow the index offset
+// range (the per-metric uint16 offset deltas of the V1 layout). Rollovers
+// happen only between metrics, so a metric is never split across blobs.
+// Finished blobs are passed to the callback given to NewRolloverEncoder.
+//
+// A RolloverEncoder is not safe for concurrent use.
+type RolloverEncoder struct {
+	cfg       RolloverEncoderConfig
+	startTime time.Time
+	onBlob    func(data []byte) error
+	enc       *NumericEncoder
+	pending   [][]byte // finished blobs whose callback failed, oldest first
+	blobs     int
+}
+
+// NewRolloverEncoder creates a RolloverEncoder whose blobs all start at
+// startTime and are passed to onBlob.
+//
+// By default a blob is rolled over only at the MaxMetricCount and index offset
+// limits. Call Flush (or Close) to emit the last, partial blob.
+//
+// Parameters:
+//   - startTime: Start time of every blob
+//   - onBlob: Callback receiving the bytes of each finished blob; the slice is not reused
+//   - opts: Rollover limits and encoder options (WithRolloverTargetSize,
+//     WithRolloverMaxMetrics, WithRolloverEncoderOptions)
+//
+// Returns:
+//   - *RolloverEncoder: New encoder with no metrics
+//   - error: Invalid option or encoder option error
+//
+// Example:
+//
+//	encoder, _ := blob.NewRolloverEncoder(start, func(data []byte) error {
+//	    return store.Put(data)
+//	}, blob.WithRolloverTargetSize(256<<10))
+//	for _, series := range batch {
+//	    encoder.StartMetricName(series.Name, len(series.Timestamps))
+//	    encoder.AddDataPoints(series.Timestamps, series.Values, nil)
+//	    encoder.EndMetric()
+//	}
+//	return encoder.Close()
+func NewRolloverEncoder(startTime time.Time, onBlob func(data []byte) error, opts ...RolloverOption) (*RolloverEncoder, error) {
+	if onBlob == nil {
+		return nil, errors.New("rollover callback must not be nil")
+	}
+
+	e := &RolloverEncoder{
+		cfg:       RolloverEncoderConfig{maxMetrics: MaxMetricCount},
+		startTime: startTime,
+		onBlob:    onBlob,
+	}
+	if err := options.Apply(&e.cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	enc, err := NewNumericEncoder(startTime, e.cfg.encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+	e.enc = enc
+
+	return e, nil
+}
+
+// WithRolloverTargetSize rolls a blob over once its encoded data reaches bytes.
+//
+// The size counts the encoded timestamp, value and tag columns and the index
+// entries, before compression, so finished blobs are usually smaller. Since
+// the size is checked before each metric, a blob may exceed it by up to one
+// metric. Default is no target size.
+func WithRolloverTargetSize(bytes int) RolloverOption {
+	return options.New(func(cfg *RolloverEncoderConfig) error {
+		if bytes <= 0 {
+			return fmt.Errorf("invalid rollover target size: %d, must be positive", bytes)
+		}
+		cfg.targetSize = bytes
+
+		return nil
+	})
+}
+
+// WithRolloverMaxMetrics limits the number of metrics in one blob, between 1
+// and MaxMetricCount. Default is MaxMetricCount.
+func WithRolloverMaxMetrics(n int) RolloverOption {
+	return options.New(func(cfg *RolloverEncoderConfig) error {
+		if n <= 0 || n > MaxMetricCount {
+			return fmt.Errorf("invalid rollover max metrics: %d, must be between 1 and %d", n, MaxMetricCount)
+		}
+		cfg.maxMetrics = n
+
+		return nil
+	})
+}
+
+// WithRolloverEncoderOptions sets the options used to create the encoder of every blob.
+func WithRolloverEncoderOptions(opts ...NumericEncoderOption) RolloverOption {
+	return options.NoError(func(cfg *RolloverEncoderConfig) {
+		cfg.encoderOpts = append(cfg.encoderOpts, opts...)
+	})
+}
+
+// StartMetricID starts a metric identified by ID, rolling over to a new blob
+// first if the current one is full.
+//
+// See NumericEncoder.StartMetricID for the parameters.
+//
+// Returns:
+//   - error: Rollover encoding or callback error, or the same errors as
+//     NumericEncoder.StartMetricID
+func (e *RolloverEncoder) StartMetricID(metricID uint64, numOfDataPoints int) error {
+	if err := e.maybeRollover(); err != nil {
+		return err
+	}
+
+	return e.enc.StartMetricID(metricID, numOfDataPoints)
+}
+
+// StartMetricName starts a metric identified by name, rolling over to a new
+// blob first if the current one is full.
+//
+// See NumericEncoder.StartMetricName for the parameters.
+//
+// Returns:
+//   - error: Rollover encoding or callback error, or the same errors as
+//     NumericEncoder.StartMetricName
+func (e *RolloverEncoder) StartMetricName(metricName string, numOfDataPoints int) error {
+	if err := e.maybeRollover(); err != nil {
+		return err
+	}
+
+	return e.enc.StartMetricName(metricName, numOfDataPoints)
+}
+
+// AddDataPoint adds a data point to the current metric.
+//
+// See NumericEncoder.AddDataPoint for details.
+func (e *RolloverEncoder) AddDataPoint(timestamp int64, value float64, tag string) error {
+	return e.enc.AddDataPoint(timestamp, value, tag)
+}
+
+// AddDataPoints adds data points to the current metric.
+//
+// See NumericEncoder.AddDataPoints for details.
+func (e *RolloverEncoder) AddDataPoints(timestamps []int64, values []float64, tags []string) error {
+	return e.enc.AddDataPoints(timestamps, values, tags)
+}
+
+// EndMetric ends the current metric.
+//
+// See NumericEncoder.EndMetric for details.
+func (e *RolloverEncoder) EndMetric() error {
+	return e.enc.EndMetric()
+}
+
+// AbortMetric discards the current metric.
+//
+// See NumericEncoder.AbortMetric for details.
+func (e *RolloverEncoder) AbortMetric() error {
+	return e.enc.AbortMetric()
+}
+
+// Flush finishes the current blob and passes it to the callback, together
+// with any blob whose callback failed before. It does nothing when no metric
+// was added since the last blob.
+//
+// If the callback fails, the blob is kept and passed again by the next Flush
+// or rollover.
+//
+// Returns:
+//   - error: ErrMetricNotEnded if a metric is started, or an encoding or
+//     callback error
+func (e *RolloverEncoder) Flush() error {
+	if e.enc.curMetricID != 0 {
+		return errs.ErrMetricNotEnded
+	}
+
+	if len(e.enc.indexEntries) > 0 {
+		enc, err := NewNumericEncoder(e.startTime, e.cfg.encoderOpts...)
+		if err != nil {
+			return err
+		}
+
+		data, err := e.enc.Finish()
+		e.enc = enc
+		if err != nil {
+			return err
+		}
+		e.pending = append(e.pending, data)
+	}
+
+	for len(e.pending) > 0 {
+		if err := e.onBlob(e.pending[0]); err != nil {
+			return err
+		}
+		e.pending[0] = nil
+		e.pending = e.pending[1:]
+		e.blobs++
+	}
+
+	return nil
+}
+
+// Close flushes the last blob. The encoder stays usable.
+func (e *RolloverEncoder) Close() error {
+	return e.Flush()
+}
+
+// BlobCount returns the number of blobs passed to the callback so far.
+func (e *RolloverEncoder) BlobCount() int {
+	return e.blobs
+}
+
+// Stats returns the progress and remaining capacity of the current blob.
+//
+// See NumericEncoder.Stats for details.
+func (e *RolloverEncoder) Stats() NumericEncoderStats {
+	return e.enc.Stats()
+}
+
+// maybeRollover flushes the current blob if starting another metric would
+// exceed one of its limits.
+func (e *RolloverEncoder) maybeRollover() error {
+	enc := e.enc
+	n := len(enc.indexEntries)
+	if enc.curMetricID != 0 || n == 0 {
+		return nil
+	}
+
+	full := n >= e.cfg.maxMetrics ||
+		(e.cfg.targetSize > 0 && enc.encodedSize() >= e.cfg.targetSize) ||
+		// Ending the next metric stores the size of the last one as offset deltas.
+		enc.validateV1OffsetDeltas(enc.ts.delta(), enc.val.delta(), enc.tag.delta()) != nil
+
+	if !full {
+		return nil
+	}
+
+	return e.Flush()
+}
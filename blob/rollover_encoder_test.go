@@ -0,0 +1,145 @@
+package blob
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// addRolloverTestMetric adds n data points with tags of tagLen bytes to metric id.
+func addRolloverTestMetric(t *testing.T, encoder *RolloverEncoder, id uint64, n, tagLen int) {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0)
+	tag := strings.Repeat("t", tagLen)
+	require.NoError(t, encoder.StartMetricID(id, n))
+	for i := range n {
+		require.NoError(t, encoder.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), float64(id), tag))
+	}
+	require.NoError(t, encoder.EndMetric())
+}
+
+// decodeRolloverBlobs decodes blobs and returns their metric counts.
+func decodeRolloverBlobs(t *testing.T, blobs [][]byte) []int {
+	t.Helper()
+
+	counts := make([]int, 0, len(blobs))
+	for _, data := range blobs {
+		decoder, err := NewNumericDecoder(data)
+		require.NoError(t, err)
+		b, err := decoder.Decode()
+		require.NoError(t, err)
+		counts = append(counts, b.MetricCount())
+	}
+
+	return counts
+}
+
+func TestRolloverEncoder_OffsetLimit(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	// A plain encoder rejects the metric after one whose tags overflow the uint16 offset delta.
+	plain, err := NewNumericEncoder(start, WithTagsEnabled(true))
+	require.NoError(t, err)
+	for id := uint64(1); id <= 2; id++ {
+		require.NoError(t, plain.StartMetricID(id, 1000))
+		for i := range 1000 {
+			require.NoError(t, plain.AddDataPoint(start.Add(time.Duration(i)*time.Second).UnixMicro(), 1, strings.Repeat("t", 100)))
+		}
+		if id == 1 {
+			require.NoError(t, plain.EndMetric())
+		}
+	}
+	require.ErrorIs(t, plain.EndMetric(), errs.ErrOffsetOutOfRange)
+
+	var blobs [][]byte
+	encoder, err := NewRolloverEncoder(start, func(data []byte) error {
+		blobs = append(blobs, data)
+		return nil
+	}, WithRolloverEncoderOptions(WithTagsEnabled(true)))
+	require.NoError(t, err)
+
+	addRolloverTestMetric(t, encoder, 1, 10, 1)
+	addRolloverTestMetric(t, encoder, 2, 1000, 100)
+	addRolloverTestMetric(t, encoder, 3, 1000, 100)
+	addRolloverTestMetric(t, encoder, 4, 10, 1)
+	require.Equal(t, 2, encoder.BlobCount())
+	require.NoError(t, encoder.Close())
+
+	// Each blob ends with at most one metric overflowing the offset delta range.
+	require.Equal(t, []int{2, 1, 1}, decodeRolloverBlobs(t, blobs))
+}
+
+func TestRolloverEncoder_Limits(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	var blobs [][]byte
+	onBlob := func(data []byte) error {
+		blobs = append(blobs, data)
+		return nil
+	}
+
+	encoder, err := NewRolloverEncoder(start, onBlob, WithRolloverMaxMetrics(3))
+	require.NoError(t, err)
+	for id := uint64(1); id <= 7; id++ {
+		addRolloverTestMetric(t, encoder, id, 5, 0)
+	}
+	require.NoError(t, encoder.Flush())
+	require.Equal(t, []int{3, 3, 1}, decodeRolloverBlobs(t, blobs))
+
+	// Flushing without new metrics emits nothing.
+	require.NoError(t, encoder.Flush())
+	require.Equal(t, 3, encoder.BlobCount())
+
+	blobs = nil
+	encoder, err = NewRolloverEncoder(start, onBlob, WithRolloverTargetSize(1))
+	require.NoError(t, err)
+	for id := uint64(1); id <= 3; id++ {
+		addRolloverTestMetric(t, encoder, id, 5, 0)
+	}
+	require.NoError(t, encoder.Close())
+	require.Equal(t, []int{1, 1, 1}, decodeRolloverBlobs(t, blobs))
+
+	_, err = NewRolloverEncoder(start, nil)
+	require.Error(t, err)
+	_, err = NewRolloverEncoder(start, onBlob, WithRolloverMaxMetrics(0))
+	require.Error(t, err)
+	_, err = NewRolloverEncoder(start, onBlob, WithRolloverTargetSize(0))
+	require.Error(t, err)
+}
+
+func TestRolloverEncoder_CallbackError(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	errStore := errors.New("store unavailable")
+
+	var blobs [][]byte
+	fail := true
+	encoder, err := NewRolloverEncoder(start, func(data []byte) error {
+		if fail {
+			return errStore
+		}
+		blobs = append(blobs, data)
+
+		return nil
+	}, WithRolloverMaxMetrics(1))
+	require.NoError(t, err)
+
+	addRolloverTestMetric(t, encoder, 1, 5, 0)
+	require.ErrorIs(t, encoder.StartMetricID(2, 5), errStore)
+	addRolloverTestMetric(t, encoder, 2, 5, 0)
+
+	// The blob whose callback failed is passed again ahead of the next one.
+	fail = false
+	require.NoError(t, encoder.StartMetricID(3, 1))
+	require.Equal(t, 2, encoder.BlobCount())
+	require.ErrorIs(t, encoder.Flush(), errs.ErrMetricNotEnded)
+	require.NoError(t, encoder.AbortMetric())
+	require.NoError(t, encoder.Flush())
+	require.Equal(t, 2, encoder.BlobCount())
+	require.Equal(t, []int{1, 1}, decodeRolloverBlobs(t, blobs))
+}
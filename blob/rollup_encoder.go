@@ -0,0 +1,449 @@
+package blob
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/internal/options"
+	"github.com/arloliu/mebo/internal/sketch"
+)
+
+// RollupFunc selects how a RollupEncoder combines the values of a window.
+type RollupFunc uint8
+
+const (
+	// RollupMean stores the arithmetic mean of the window's values.
+	RollupMean RollupFunc = iota
+	// RollupMin stores the smallest value of the window.
+	RollupMin
+	// RollupMax stores the largest value of the window.
+	RollupMax
+	// RollupSum stores the sum of the window's values.
+	RollupSum
+	// RollupLast stores the value with the latest timestamp of the window.
+	RollupLast
+	// RollupCount stores the number of values in the window.
+	RollupCount
+)
+
+// String returns the name of the function.
+func (f RollupFunc) String() string {
+	switch f {
+	case RollupMean:
+		return "Mean"
+	case RollupMin:
+		return "Min"
+	case RollupMax:
+		return "Max"
+	case RollupSum:
+		return "Sum"
+	case RollupLast:
+		return "Last"
+	case RollupCount:
+		return "Count"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsValid reports whether f is a known function.
+func (f RollupFunc) IsValid() bool {
+	return f <= RollupCount
+}
+
+// RollupNamer derives the name of a rollup series from the name of its source
+// metric, the rollup window and the aggregate function.
+type RollupNamer func(metricName string, window time.Duration, fn RollupFunc) string
+
+// DefaultRollupNamer names rollup series "<metric>:<window>:<func>", e.g.
+// "metric.cpu.usage:1m:max". The window is written in the largest of the units
+// d, h, m, s, ms, us and ns that divides it evenly.
+func DefaultRollupNamer(metricName string, window time.Duration, fn RollupFunc) string {
+	return metricName + ":" + rollupWindowLabel(window) + ":" + strings.ToLower(fn.String())
+}
+
+// rollupWindowUnits are the units of rollup window labels, largest first.
+var rollupWindowUnits = []struct {
+	d      time.Duration
+	suffix string
+}{
+	{24 * time.Hour, "d"},
+	{time.Hour, "h"},
+	{time.Minute, "m"},
+	{time.Second, "s"},
+	{time.Millisecond, "ms"},
+	{time.Microsecond, "us"},
+}
+
+// rollupWindowLabel formats a positive window compactly, e.g. 1m or 90s.
+func rollupWindowLabel(window time.Duration) string {
+	for _, u := range rollupWindowUnits {
+		if window%u.d == 0 {
+			return fmt.Sprintf("%d%s", window/u.d, u.suffix)
+		}
+	}
+
+	return fmt.Sprintf("%dns", window)
+}
+
+// RollupConfig holds RollupEncoder naming, output and encoder settings.
+type RollupConfig struct {
+	namer       RollupNamer
+	names       []string
+	blobSpan    time.Duration
+	encoderOpts []NumericEncoderOption
+}
+
+// RollupOption represents a functional option for configuring a RollupEncoder.
+type RollupOption = options.Option[*RollupConfig]
+
+// WithRollupNamer sets the function deriving rollup series names. Default is
+// DefaultRollupNamer.
+func WithRollupNamer(namer RollupNamer) RollupOption {
+	return options.New(func(cfg *RollupConfig) error {
+		if namer == nil {
+			return fmt.Errorf("invalid rollup namer: must not be nil")
+		}
+		cfg.namer = namer
+
+		return nil
+	})
+}
+
+// WithRollupMetricNames supplies the names of source metrics stored by ID only.
+//
+// Source blobs encoded without metric names keep just the hashed metric IDs, so
+// the encoder cannot recover the names its series names derive from. Names given
+// here are matched to those IDs by hashing.
+func WithRollupMetricNames(names ...string) RollupOption {
+	return options.NoError(func(cfg *RollupConfig) {
+		cfg.names = append(cfg.names, names...)
+	})
+}
+
+// WithRollupBlobSpan cuts the output into one blob per span, aligned to the
+// Unix epoch, instead of one blob for the whole source set. The span must be a
+// multiple of the rollup window. Use it when a series would otherwise exceed
+// the encoder's per-metric data point limit.
+func WithRollupBlobSpan(span time.Duration) RollupOption {
+	return options.New(func(cfg *RollupConfig) error {
+		if span <= 0 {
+			return fmt.Errorf("invalid rollup blob span: %v, must be positive", span)
+		}
+		cfg.blobSpan = span
+
+		return nil
+	})
+}
+
+// WithRollupEncoderOptions sets the options used to create the encoder of every
+// output blob.
+func WithRollupEncoderOptions(opts ...NumericEncoderOption) RollupOption {
+	return options.NoError(func(cfg *RollupConfig) {
+		cfg.encoderOpts = append(cfg.encoderOpts, opts...)
+	})
+}
+
+// RollupEncoder downsamples the numeric metrics of a BlobSet into new numeric
+// blobs holding one aggregated series per source metric and function, e.g.
+// metric.cpu.usage:1m:max, as a building block for tiered retention.
+//
+// Data points are grouped into windows aligned to the Unix epoch and each
+// series holds one data point per non-empty window, timestamped with the
+// window start. NaN values are ignored; windows holding only NaN values are
+// omitted.
+//
+// A RollupEncoder is not safe for concurrent use.
+type RollupEncoder struct {
+	src    BlobSet
+	window int64 // Window width in the output timestamp unit
+	span   int64 // Output blob span in the output timestamp unit, 0 for one blob
+	fns    []RollupFunc
+	cfg    RollupConfig
+	rawWin time.Duration
+	unit   format.TimeUnit
+	names  map[uint64]string
+}
+
+// rollupBucket accumulates the values of one metric falling into one window.
+type rollupBucket struct {
+	sum    sketch.KahanSum
+	min    float64
+	max    float64
+	last   float64
+	lastTs int64
+}
+
+// add folds a value into the bucket.
+func (b *rollupBucket) add(ts int64, val float64) {
+	if b.sum.Count() == 0 {
+		b.min, b.max = val, val
+	} else {
+		b.min = min(b.min, val)
+		b.max = max(b.max, val)
+	}
+
+	if b.sum.Count() == 0 || ts >= b.lastTs {
+		b.last, b.lastTs = val, ts
+	}
+
+	b.sum.Add(val)
+}
+
+// value returns the bucket's aggregate.
+func (b *rollupBucket) value(fn RollupFunc) float64 {
+	switch fn {
+	case RollupMin:
+		return b.min
+	case RollupMax:
+		return b.max
+	case RollupSum:
+		return b.sum.Value()
+	case RollupLast:
+		return b.last
+	case RollupCount:
+		return float64(b.sum.Count())
+	default:
+		return b.sum.Value() / float64(b.sum.Count())
+	}
+}
+
+// NewRollupEncoder creates a RollupEncoder aggregating the numeric metrics of
+// src into windows of the given width.
+//
+// Series are named by the configured RollupNamer from the source metric name.
+// Names are taken from source blobs that store them (blobs encoded by name
+// whose metric IDs collided) and from WithRollupMetricNames; metrics whose name
+// is unknown are named by their ID as "0x%016x".
+//
+// Parameters:
+//   - src: Source blob set; only its numeric blobs are read
+//   - window: Rollup window, a positive multiple of the output timestamp unit
+//   - aggFns: Aggregate functions, one output series per function and metric
+//   - opts: Naming, output and encoder options (WithRollupNamer,
+//     WithRollupMetricNames, WithRollupBlobSpan, WithRollupEncoderOptions)
+//
+// Returns:
+//   - *RollupEncoder: New rollup encoder
+//   - error: Invalid window, function, option or encoder option error
+//
+// Example:
+//
+//	rollup, _ := blob.NewRollupEncoder(rawSet, time.Minute,
+//	    []blob.RollupFunc{blob.RollupMean, blob.RollupMax},
+//	    blob.WithRollupBlobSpan(24*time.Hour))
+//	blobs, err := rollup.Encode()
+//	// blobs hold e.g. "metric.cpu.usage:1m:mean" and "metric.cpu.usage:1m:max"
+func NewRollupEncoder(src BlobSet, window time.Duration, aggFns []RollupFunc, opts ...RollupOption) (*RollupEncoder, error) {
+	if len(aggFns) == 0 {
+		return nil, fmt.Errorf("invalid rollup functions: at least one is required")
+	}
+
+	for _, fn := range aggFns {
+		if !fn.IsValid() {
+			return nil, fmt.Errorf("invalid rollup function: %d", fn)
+		}
+	}
+
+	cfg := RollupConfig{namer: DefaultRollupNamer}
+	if err := options.Apply(&cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	// A probe encoder validates the encoder options and exposes their effective settings.
+	probe, err := NewNumericEncoder(time.Unix(0, 0), cfg.encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := probe.tsUnit
+	if window <= 0 || window%unit.Duration() != 0 {
+		return nil, fmt.Errorf("invalid rollup window: %v, must be a positive multiple of %v", window, unit.Duration())
+	}
+
+	if cfg.blobSpan%window != 0 {
+		return nil, fmt.Errorf("invalid rollup blob span: %v, must be a multiple of the window %v", cfg.blobSpan, window)
+	}
+
+	r := &RollupEncoder{
+		src:    src,
+		window: int64(window / unit.Duration()),
+		span:   int64(cfg.blobSpan / unit.Duration()),
+		fns:    slices.Clone(aggFns),
+		cfg:    cfg,
+		rawWin: window,
+		unit:   unit,
+		names:  rollupSourceNames(src, cfg.names),
+	}
+
+	return r, nil
+}
+
+// rollupSourceNames maps the metric IDs of src to their names, skipping IDs
+// shared by several names.
+func rollupSourceNames(src BlobSet, extra []string) map[uint64]string {
+	names := make(map[uint64]string)
+	add := func(name string) {
+		id := hash.ID(name)
+		if _, ok := src.ambiguous[id]; !ok {
+			names[id] = name
+		}
+	}
+
+	for i := range src.numericBlobs {
+		for _, name := range src.numericBlobs[i].MetricNames() {
+			add(name)
+		}
+	}
+
+	for _, name := range extra {
+		add(name)
+	}
+
+	return names
+}
+
+// Encode aggregates the source set and encodes the rollup series.
+//
+// Returns:
+//   - [][]byte: Encoded numeric blobs ordered by start time, one per blob span
+//     holding data, or a single blob without WithRollupBlobSpan. Nil if the
+//     source set holds no non-NaN numeric values.
+//   - error: ErrTooManyDataPoints if a series exceeds the encoder's per-metric
+//     data point limit within one blob, or an encoding error
+func (r *RollupEncoder) Encode() ([][]byte, error) {
+	// Metric ID → window start → accumulator
+	metrics := make(map[uint64]map[int64]*rollupBucket)
+	for i := range r.src.numericBlobs {
+		b := r.src.numericBlobs[i]
+		unit := b.TimestampUnit()
+		for _, id := range b.MetricIDs() {
+			buckets := metrics[id]
+			if buckets == nil {
+				buckets = make(map[int64]*rollupBucket)
+				metrics[id] = buckets
+			}
+
+			for _, dp := range b.All(id) {
+				if math.IsNaN(dp.Val) {
+					continue
+				}
+
+				ts := unit.Convert(dp.Ts, r.unit)
+				start := floorDiv(ts, r.window) * r.window
+
+				acc := buckets[start]
+				if acc == nil {
+					acc = &rollupBucket{}
+					buckets[start] = acc
+				}
+				acc.add(ts, dp.Val)
+			}
+		}
+	}
+
+	// Blob span start → metric ID → window starts
+	spans := make(map[int64]map[uint64][]int64)
+	for id, buckets := range metrics {
+		for start := range buckets {
+			spanStart := int64(math.MinInt64)
+			if r.span > 0 {
+				spanStart = floorDiv(start, r.span) * r.span
+			}
+
+			if spans[spanStart] == nil {
+				spans[spanStart] = make(map[uint64][]int64)
+			}
+			spans[spanStart][id] = append(spans[spanStart][id], start)
+		}
+	}
+
+	var blobs [][]byte
+	for _, spanStart := range slices.Sorted(maps.Keys(spans)) {
+		data, err := r.encodeSpan(metrics, spans[spanStart])
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, data)
+	}
+
+	return blobs, nil
+}
+
+// rollupSeries is one output series of a blob.
+type rollupSeries struct {
+	name string
+	id   uint64
+	fn   RollupFunc
+}
+
+// encodeSpan encodes the windows of one output blob.
+func (r *RollupEncoder) encodeSpan(metrics map[uint64]map[int64]*rollupBucket, windows map[uint64][]int64) ([]byte, error) {
+	first := int64(math.MaxInt64)
+	series := make([]rollupSeries, 0, len(windows)*len(r.fns))
+	for id, starts := range windows {
+		slices.Sort(starts)
+		first = min(first, starts[0])
+
+		name, ok := r.names[id]
+		if !ok {
+			name = fmt.Sprintf("0x%016x", id)
+		}
+
+		for _, fn := range r.fns {
+			series = append(series, rollupSeries{name: r.cfg.namer(name, r.rawWin, fn), id: id, fn: fn})
+		}
+	}
+
+	slices.SortFunc(series, func(a, b rollupSeries) int {
+		return cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.fn, b.fn))
+	})
+
+	encoder, err := NewNumericEncoder(r.unit.Time(first), r.cfg.encoderOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range series {
+		starts := windows[s.id]
+		if len(starts) > encoder.MaxDataPoints() {
+			return nil, fmt.Errorf("%w: rollup series %q has %d windows, exceeding %d data points per blob",
+				errs.ErrTooManyDataPoints, s.name, len(starts), encoder.MaxDataPoints())
+		}
+
+		if err := encoder.StartMetricName(s.name, len(starts)); err != nil {
+			return nil, err
+		}
+
+		buckets := metrics[s.id]
+		for _, start := range starts {
+			if err := encoder.AddDataPoint(start, buckets[start].value(s.fn), ""); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// floorDiv returns a/b rounded toward negative infinity, for b > 0.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+
+	return q
+}
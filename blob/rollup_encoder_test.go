@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// decodeRollupBlobs decodes the blobs of a rollup into a blob set.
+func decodeRollupBlobs(t *testing.T, blobs [][]byte) BlobSet {
+	t.Helper()
+
+	bs, err := DecodeBlobSet(blobs...)
+	require.NoError(t, err)
+
+	return bs
+}
+
+func TestRollupEncoder_Encode(t *testing.T) {
+	base := time.Unix(1700000080, 0).UTC() // 40s past a minute boundary
+	src := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base, map[string][]float64{"cpu": {1, 5, 3, math.NaN()}}),
+		encodeAnalyticsBlob(t, base.Add(20*time.Second), map[string][]float64{"cpu": {2, 8}}),
+	}, nil)
+
+	rollup, err := NewRollupEncoder(src, time.Minute, []RollupFunc{RollupMax, RollupMean, RollupCount, RollupLast},
+		WithRollupMetricNames("cpu"))
+	require.NoError(t, err)
+
+	blobs, err := rollup.Encode()
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	bs := decodeRollupBlobs(t, blobs)
+
+	// Windows: [..:00, ..:60) holds 1, 5, 3 (NaN ignored); [..:60, ..:120) holds 2, 8.
+	first := time.Unix(1700000080, 0).Truncate(time.Minute).UnixMicro()
+	second := first + time.Minute.Microseconds()
+	cases := map[string][]float64{
+		"cpu:1m:max":   {5, 8},
+		"cpu:1m:mean":  {3, 5},
+		"cpu:1m:count": {3, 2},
+		"cpu:1m:last":  {3, 8},
+	}
+	for name, want := range cases {
+		var ts []int64
+		var vals []float64
+		for _, dp := range bs.AllNumericsByName(name) {
+			ts = append(ts, dp.Ts)
+			vals = append(vals, dp.Val)
+		}
+		require.Equal(t, []int64{first, second}, ts, name)
+		require.Equal(t, want, vals, name)
+	}
+}
+
+func TestRollupEncoder_Naming(t *testing.T) {
+	base := time.Unix(1700000000, 0).UTC()
+	src := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base, map[string][]float64{"mem": {1, 2}}),
+	}, nil)
+
+	t.Run("unknown name", func(t *testing.T) {
+		rollup, err := NewRollupEncoder(src, 5*time.Minute, []RollupFunc{RollupSum})
+		require.NoError(t, err)
+		blobs, err := rollup.Encode()
+		require.NoError(t, err)
+
+		bs := decodeRollupBlobs(t, blobs)
+		require.Equal(t, 1, bs.MetricLenByName(fmt.Sprintf("0x%016x:5m:sum", hash.ID("mem"))))
+	})
+
+	t.Run("custom namer", func(t *testing.T) {
+		namer := func(name string, window time.Duration, fn RollupFunc) string {
+			return "rollup_" + fn.String() + "_" + name
+		}
+		rollup, err := NewRollupEncoder(src, time.Hour, []RollupFunc{RollupMin},
+			WithRollupMetricNames("mem"), WithRollupNamer(namer))
+		require.NoError(t, err)
+		blobs, err := rollup.Encode()
+		require.NoError(t, err)
+
+		bs := decodeRollupBlobs(t, blobs)
+		v, ok := bs.NumericValueAtByName("rollup_Min_mem", 0)
+		require.True(t, ok)
+		require.Equal(t, 1.0, v)
+	})
+}
+
+func TestRollupEncoder_BlobSpan(t *testing.T) {
+	base := time.Unix(1700000000, 0).Truncate(time.Hour).UTC()
+	src := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base.Add(-30*time.Second), map[string][]float64{"req": make([]float64, 90)}),
+	}, nil)
+
+	rollup, err := NewRollupEncoder(src, 10*time.Second, []RollupFunc{RollupCount},
+		WithRollupMetricNames("req"), WithRollupBlobSpan(time.Hour))
+	require.NoError(t, err)
+	blobs, err := rollup.Encode()
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+
+	bs := decodeRollupBlobs(t, blobs)
+	// Windows -30s, -20s and -10s fall into the previous hour, 0s to 50s into base's.
+	require.Equal(t, base.Add(-30*time.Second), bs.NumericBlobs()[0].StartTime())
+	require.Equal(t, base, bs.NumericBlobs()[1].StartTime())
+	require.Equal(t, 3, bs.NumericBlobs()[0].LenByName("req:10s:count"))
+	require.Equal(t, 6, bs.NumericBlobs()[1].LenByName("req:10s:count"))
+}
+
+func TestRollupEncoder_TooManyWindows(t *testing.T) {
+	probe, err := NewNumericEncoder(time.Unix(0, 0))
+	require.NoError(t, err)
+	half := probe.MaxDataPoints()/2 + 1
+
+	base := time.Unix(1700000000, 0).UTC()
+	src := NewBlobSet([]NumericBlob{
+		encodeAnalyticsBlob(t, base, map[string][]float64{"x": make([]float64, half)}),
+		encodeAnalyticsBlob(t, base.Add(time.Duration(half)*time.Second), map[string][]float64{"x": make([]float64, half)}),
+	}, nil)
+
+	rollup, err := NewRollupEncoder(src, time.Second, []RollupFunc{RollupMean})
+	require.NoError(t, err)
+	_, err = rollup.Encode()
+	require.ErrorIs(t, err, errs.ErrTooManyDataPoints)
+}
+
+func TestNewRollupEncoder_Invalid(t *testing.T) {
+	src := NewBlobSet(nil, nil)
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		fns    []RollupFunc
+		opts   []RollupOption
+	}{
+		{name: "no functions", window: time.Minute},
+		{name: "unknown function", window: time.Minute, fns: []RollupFunc{RollupCount + 1}},
+		{name: "zero window", fns: []RollupFunc{RollupMax}},
+		{name: "sub-unit window", window: time.Nanosecond, fns: []RollupFunc{RollupMax}},
+		{name: "span not multiple", window: time.Minute, fns: []RollupFunc{RollupMax}, opts: []RollupOption{WithRollupBlobSpan(90 * time.Second)}},
+		{name: "nil namer", window: time.Minute, fns: []RollupFunc{RollupMax}, opts: []RollupOption{WithRollupNamer(nil)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRollupEncoder(src, tt.window, tt.fns, tt.opts...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDefaultRollupNamer(t *testing.T) {
+	require.Equal(t, "metric.cpu.usage:1m:max", DefaultRollupNamer("metric.cpu.usage", time.Minute, RollupMax))
+	require.Equal(t, "a:90s:mean", DefaultRollupNamer("a", 90*time.Second, RollupMean))
+	require.Equal(t, "a:1d:sum", DefaultRollupNamer("a", 24*time.Hour, RollupSum))
+	require.Equal(t, "a:250ms:count", DefaultRollupNamer("a", 250*time.Millisecond, RollupCount))
+	require.Equal(t, "Last", RollupLast.String())
+}
@@ -0,0 +1,190 @@
+package blob
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// MaxRoundTripDiffs is the maximum number of differences recorded in a RoundTripReport.
+// Further differences are only counted.
+const MaxRoundTripDiffs = 100
+
+// RoundTripDiffKind identifies what differs between an input and a decoded data point.
+type RoundTripDiffKind uint8
+
+const (
+	// DiffTimestamp means the decoded timestamp differs.
+	DiffTimestamp RoundTripDiffKind = 1 << iota
+	// DiffValue means the decoded value differs at the bit level.
+	DiffValue
+	// DiffTag means the decoded tag differs.
+	DiffTag
+	// DiffMissing means the input point is missing from the decoded blob.
+	DiffMissing
+	// DiffExtra means the decoded blob holds a point beyond the input.
+	DiffExtra
+)
+
+// RoundTripDiff describes one data point that did not survive the round trip.
+type RoundTripDiff struct {
+	// Metric is the metric name.
+	Metric string
+	// Index is the position of the point within the metric.
+	Index int
+	// Kind holds the DiffXxx flags of the differing fields.
+	Kind RoundTripDiffKind
+	// Want is the input point; zero for DiffExtra.
+	Want NumericDataPoint
+	// Got is the decoded point; zero for DiffMissing.
+	Got NumericDataPoint
+}
+
+// RoundTripReport is the result of ValidateRoundTrip.
+type RoundTripReport struct {
+	// Points is the number of input data points.
+	Points int
+	// BlobSize is the size of the encoded blob in bytes.
+	BlobSize int
+	// DiffCount is the total number of differing points.
+	DiffCount int
+	// Diffs holds the first MaxRoundTripDiffs differences, ordered by metric name and index.
+	Diffs []RoundTripDiff
+}
+
+// ValidateRoundTrip encodes sample data with the given encoder options, decodes it
+// again and reports every data point whose timestamp, value bits or tag changed.
+//
+// Use it in CI to prove that an encoder configuration is lossless for representative
+// data: options such as WithValuePrecision, WithQuantization or disabled tags make
+// the round trip lossy, and values are compared with math.Float64bits so even NaN
+// payloads and signed zeros must survive.
+//
+// Parameters:
+//   - metrics: Sample data points keyed by metric name; each metric needs at least one point
+//   - opts: Encoder options under test
+//
+// Returns:
+//   - RoundTripReport: Differences found; Lossless reports whether there were none
+//   - error: Encoding or decoding error
+//
+// Example:
+//
+//	report, err := blob.ValidateRoundTrip(samples, blob.WithValueEncoding(format.TypeChimp))
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	if !report.Lossless() {
+//	    t.Fatalf("%d points changed, first: %+v", report.DiffCount, report.Diffs[0])
+//	}
+func ValidateRoundTrip(metrics map[string][]NumericDataPoint, opts ...NumericEncoderOption) (RoundTripReport, error) {
+	var report RoundTripReport
+
+	names := slices.Sorted(maps.Keys(metrics))
+
+	var start int64
+	for i, name := range names {
+		for j, dp := range metrics[name] {
+			if (i == 0 && j == 0) || dp.Ts < start {
+				start = dp.Ts
+			}
+		}
+	}
+
+	// Probe the configured unit so the blob start time matches the sample timestamps.
+	cfg := NewNumericEncoderConfig(time.Unix(0, 0))
+	if err := options.Apply(cfg, opts...); err != nil {
+		return report, err
+	}
+
+	encoder, err := NewNumericEncoder(cfg.tsUnit.Time(start), opts...)
+	if err != nil {
+		return report, err
+	}
+
+	for _, name := range names {
+		points := metrics[name]
+		if err := encoder.StartMetricName(name, len(points)); err != nil {
+			return report, err
+		}
+		for _, dp := range points {
+			if err := encoder.AddDataPoint(dp.Ts, dp.Val, dp.Tag); err != nil {
+				return report, err
+			}
+		}
+		if err := encoder.EndMetric(); err != nil {
+			return report, err
+		}
+		report.Points += len(points)
+	}
+
+	data, err := encoder.Finish()
+	if err != nil {
+		return report, err
+	}
+	report.BlobSize = len(data)
+
+	decoder, err := NewNumericDecoder(data)
+	if err != nil {
+		return report, err
+	}
+	decoded, err := decoder.Decode()
+	if err != nil {
+		return report, err
+	}
+
+	for _, name := range names {
+		want := metrics[name]
+		n := 0
+		for i, got := range decoded.AllByName(name) {
+			n++
+			if i >= len(want) {
+				report.addDiff(RoundTripDiff{Metric: name, Index: i, Kind: DiffExtra, Got: got})
+				continue
+			}
+			if kind := diffKind(want[i], got); kind != 0 {
+				report.addDiff(RoundTripDiff{Metric: name, Index: i, Kind: kind, Want: want[i], Got: got})
+			}
+		}
+		for i := n; i < len(want); i++ {
+			report.addDiff(RoundTripDiff{Metric: name, Index: i, Kind: DiffMissing, Want: want[i]})
+		}
+	}
+
+	return report, nil
+}
+
+// Lossless reports whether every data point survived the round trip unchanged.
+func (r RoundTripReport) Lossless() bool {
+	return r.DiffCount == 0
+}
+
+// Has reports whether all flags in flag are set.
+func (k RoundTripDiffKind) Has(flag RoundTripDiffKind) bool {
+	return k&flag == flag
+}
+
+func diffKind(want, got NumericDataPoint) RoundTripDiffKind {
+	var kind RoundTripDiffKind
+	if want.Ts != got.Ts {
+		kind |= DiffTimestamp
+	}
+	if math.Float64bits(want.Val) != math.Float64bits(got.Val) {
+		kind |= DiffValue
+	}
+	if want.Tag != got.Tag {
+		kind |= DiffTag
+	}
+
+	return kind
+}
+
+func (r *RoundTripReport) addDiff(d RoundTripDiff) {
+	r.DiffCount++
+	if len(r.Diffs) < MaxRoundTripDiffs {
+		r.Diffs = append(r.Diffs, d)
+	}
+}
@@ -0,0 +1,76 @@
+package blob
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+)
+
+func roundTripSamples() map[string][]NumericDataPoint {
+	cpu := make([]NumericDataPoint, 0, 200)
+	for i := range 200 {
+		cpu = append(cpu, NumericDataPoint{Ts: 1_700_000_000_000_000 + int64(i)*1_000_000, Val: 10 + float64(i)*0.123456789, Tag: "host=a"})
+	}
+
+	return map[string][]NumericDataPoint{
+		"cpu": cpu,
+		"special": {
+			{Ts: 1_700_000_000_000_000, Val: math.NaN()},
+			{Ts: 1_700_000_001_000_000, Val: math.Copysign(0, -1)},
+			{Ts: 1_700_000_002_000_000, Val: math.Inf(1)},
+			{Ts: 1_700_000_003_000_000, Val: math.MaxFloat64},
+		},
+	}
+}
+
+func TestValidateRoundTrip_Lossless(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []NumericEncoderOption
+	}{
+		{name: "Default", opts: []NumericEncoderOption{WithTagsEnabled(true)}},
+		{name: "Gorilla", opts: []NumericEncoderOption{WithTagsEnabled(true), WithValueEncoding(format.TypeGorilla)}},
+		{name: "Chimp", opts: []NumericEncoderOption{WithTagsEnabled(true), WithValueEncoding(format.TypeChimp)}},
+		{name: "DeltaPacked", opts: []NumericEncoderOption{WithTagsEnabled(true), WithTimestampEncoding(format.TypeDeltaPacked)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ValidateRoundTrip(roundTripSamples(), tt.opts...)
+			require.NoError(t, err)
+			require.True(t, report.Lossless(), "%+v", report.Diffs)
+			require.Equal(t, 204, report.Points)
+			require.Positive(t, report.BlobSize)
+		})
+	}
+}
+
+func TestValidateRoundTrip_Lossy(t *testing.T) {
+	report, err := ValidateRoundTrip(roundTripSamples(), WithValuePrecision(2))
+	require.NoError(t, err)
+	require.False(t, report.Lossless())
+	require.Len(t, report.Diffs, MaxRoundTripDiffs)
+	require.Greater(t, report.DiffCount, MaxRoundTripDiffs)
+
+	first := report.Diffs[0]
+	require.Equal(t, "cpu", first.Metric)
+	require.True(t, first.Kind.Has(DiffTag), "tags are disabled by default")
+	require.False(t, first.Kind.Has(DiffTimestamp))
+	require.Equal(t, "host=a", first.Want.Tag)
+	require.Empty(t, first.Got.Tag)
+
+	valueDiffs := 0
+	for _, d := range report.Diffs {
+		if d.Kind.Has(DiffValue) {
+			valueDiffs++
+		}
+	}
+	require.Positive(t, valueDiffs, "rounding to 2 decimals changes values")
+
+	_, err = ValidateRoundTrip(map[string][]NumericDataPoint{"empty": nil})
+	require.Error(t, err)
+	_, err = ValidateRoundTrip(roundTripSamples(), WithValueEncoding(format.TypeDelta))
+	require.Error(t, err)
+}
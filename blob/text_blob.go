@@ -1,9 +1,12 @@
 package blob
 
 import (
+	"encoding/binary"
 	"fmt"
 	"iter"
+	"time"
 
+	"github.com/arloliu/mebo/compress"
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/section"
@@ -14,7 +17,7 @@ import (
 type TextDataPoint struct {
 	// Ts is the timestamp, the unit is defined by the caller when adding data points in TextEncoder
 	Ts int64
-	// Val is the text value (max 255 UTF-8 bytes)
+	// Val is the text value (max 255 UTF-8 bytes, or MaxTextValueLength with WithTextValueCompression)
 	Val string
 	// Tag is the optional tag associated with this data point (max 255 UTF-8 bytes)
 	Tag string
@@ -25,6 +28,7 @@ type TextBlob struct {
 	blobBase                                      // Embedded base: engine, startTime, tsEncType, sameByteOrder, flags
 	index       indexMaps[section.TextIndexEntry] // Metric ID/name → IndexEntry mappings
 	dataPayload []byte                            // Single decompressed data section (row-based)
+	valueDict   compress.Decompressor             // Zstd value dictionary codec, nil if the blob has none
 	// flag is now packed into blobBase.flags (optimized)
 }
 
@@ -86,7 +90,7 @@ func (b TextBlob) MetricNames() []string {
 //	    fmt.Printf("Point %d: ts=%d, val=%s, tag=%s\n", i, dp.Ts, dp.Val, dp.Tag)
 //	}
 func (b TextBlob) All(metricID uint64) iter.Seq2[int, TextDataPoint] {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return func(yield func(int, TextDataPoint) bool) {}
 	}
@@ -114,7 +118,7 @@ func (b TextBlob) AllByName(metricName string) iter.Seq2[int, TextDataPoint] {
 // AllTimestamps returns an iterator over all timestamps for the given metric ID.
 // Returns an empty iterator if the metric ID doesn't exist.
 func (b TextBlob) AllTimestamps(metricID uint64) iter.Seq[int64] {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return func(yield func(int64) bool) {}
 	}
@@ -133,10 +137,24 @@ func (b TextBlob) AllTimestampsByName(metricName string) iter.Seq[int64] {
 	return b.allTimestampsFromEntry(entry)
 }
 
+// AllTimes returns an iterator over all timestamps for the given metric ID as UTC
+// time.Time values. Text blob timestamps are Unix microseconds.
+// Returns an empty iterator if the metric ID doesn't exist.
+func (b TextBlob) AllTimes(metricID uint64) iter.Seq2[int, time.Time] {
+	return allTimes(b.AllTimestamps(metricID), format.TimeUnitMicrosecond)
+}
+
+// AllTimesByName returns an iterator over all timestamps for the given metric name as
+// UTC time.Time values.
+// Returns an empty iterator if the metric name doesn't exist or the blob has no metric names.
+func (b TextBlob) AllTimesByName(metricName string) iter.Seq2[int, time.Time] {
+	return allTimes(b.AllTimestampsByName(metricName), format.TimeUnitMicrosecond)
+}
+
 // AllValues returns an iterator over all text values for the given metric ID.
 // Returns an empty iterator if the metric ID doesn't exist.
 func (b TextBlob) AllValues(metricID uint64) iter.Seq[string] {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return func(yield func(string) bool) {}
 	}
@@ -162,7 +180,7 @@ func (b TextBlob) AllTags(metricID uint64) iter.Seq[string] {
 		return func(yield func(string) bool) {}
 	}
 
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return func(yield func(string) bool) {}
 	}
@@ -205,10 +223,11 @@ func (b TextBlob) LenByName(metricName string) int {
 //   - The metric doesn't exist in this blob
 //   - The index is out of bounds
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) ValueAt(metricID uint64, index int) (string, bool) {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return "", false
 	}
@@ -223,7 +242,8 @@ func (b TextBlob) ValueAt(metricID uint64, index int) (string, bool) {
 //   - The metric name doesn't exist in this blob
 //   - The index is out of bounds
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) ValueAtByName(metricName string, index int) (string, bool) {
 	entry, ok := b.lookupMetricEntry(metricName)
@@ -241,10 +261,11 @@ func (b TextBlob) ValueAtByName(metricName string, index int) (string, bool) {
 //   - The metric doesn't exist in this blob
 //   - The index is out of bounds
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) TimestampAt(metricID uint64, index int) (int64, bool) {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return 0, false
 	}
@@ -259,7 +280,8 @@ func (b TextBlob) TimestampAt(metricID uint64, index int) (int64, bool) {
 //   - The metric name doesn't exist in this blob
 //   - The index is out of bounds
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) TimestampAtByName(metricName string, index int) (int64, bool) {
 	entry, ok := b.lookupMetricEntry(metricName)
@@ -279,10 +301,11 @@ func (b TextBlob) TimestampAtByName(metricName string, index int) (int64, bool)
 //
 // Returns ("", true) if tags are not enabled but the metric and index are valid.
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) TagAt(metricID uint64, index int) (string, bool) {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return "", false
 	}
@@ -303,7 +326,8 @@ func (b TextBlob) TagAt(metricID uint64, index int) (string, bool) {
 //
 // Returns ("", true) if tags are not enabled but the metric and index are valid.
 //
-// Performance: O(n) where n is the index, as we need to skip through row-based data.
+// Performance: O(n) where n is the index, as we need to skip through row-based data,
+// or O(interval) for blobs encoded with WithTextSeekIndex.
 // For frequent random access, consider using iterators instead.
 func (b TextBlob) TagAtByName(metricName string, index int) (string, bool) {
 	entry, ok := b.lookupMetricEntry(metricName)
@@ -334,11 +358,12 @@ func (b TextBlob) allFromEntry(entry section.TextIndexEntry) iter.Seq2[int, Text
 		return func(yield func(int, TextDataPoint) bool) {}
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, _, interval, ok := b.metricRows(entry)
+	if !ok {
+		return func(yield func(int, TextDataPoint) bool) {}
+	}
 
-	return b.decodeDataPoints(dataBytes, count)
+	return b.decodeDataPoints(dataBytes, count, interval)
 }
 
 // allTimestampsFromEntry returns an iterator over all timestamps for the given entry.
@@ -348,11 +373,12 @@ func (b TextBlob) allTimestampsFromEntry(entry section.TextIndexEntry) iter.Seq[
 		return func(yield func(int64) bool) {}
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, _, interval, ok := b.metricRows(entry)
+	if !ok {
+		return func(yield func(int64) bool) {}
+	}
 
-	return b.decodeTimestamps(dataBytes, count)
+	return b.decodeTimestamps(dataBytes, count, interval)
 }
 
 // allValuesFromEntry returns an iterator over all values for the given entry.
@@ -362,11 +388,12 @@ func (b TextBlob) allValuesFromEntry(entry section.TextIndexEntry) iter.Seq[stri
 		return func(yield func(string) bool) {}
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, _, interval, ok := b.metricRows(entry)
+	if !ok {
+		return func(yield func(string) bool) {}
+	}
 
-	return b.decodeValues(dataBytes, count)
+	return b.decodeValues(dataBytes, count, interval)
 }
 
 // allTagsFromEntry returns an iterator over all tags for the given entry.
@@ -376,11 +403,12 @@ func (b TextBlob) allTagsFromEntry(entry section.TextIndexEntry) iter.Seq[string
 		return func(yield func(string) bool) {}
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, _, interval, ok := b.metricRows(entry)
+	if !ok {
+		return func(yield func(string) bool) {}
+	}
 
-	return b.decodeTags(dataBytes, count)
+	return b.decodeTags(dataBytes, count, interval)
 }
 
 // valueAtFromEntry returns the value at the specified index for the given entry.
@@ -390,16 +418,22 @@ func (b TextBlob) valueAtFromEntry(entry section.TextIndexEntry, index int) (str
 		return "", false
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, table, interval, ok := b.metricRows(entry)
+	if !ok {
+		return "", false
+	}
 
-	// Skip to the target index
-	currentOffset := 0
+	// Skip to the target index from the nearest checkpoint
+	currentOffset, start, ok := b.seekRow(dataBytes, table, interval, index)
+	if !ok {
+		return "", false
+	}
 	lastTs := b.startTimeMicros
-	hasTags := b.HasTag()
 
-	for i := range count {
+	// Location of the last stored value, which repeated values refer to
+	valOffset, valLen, valCompressed := -1, 0, false
+
+	for i := start; i < count; i++ {
 		// Decode and skip timestamp
 		_, n, err := b.decodeTimestampAt(dataBytes, currentOffset, &lastTs)
 		if err != nil {
@@ -409,29 +443,23 @@ func (b TextBlob) valueAtFromEntry(entry section.TextIndexEntry, index int) (str
 
 		// NEW LAYOUT: Read grouped length bytes first
 		// Layout is now [LEN_V][LEN_T][VAL][TAG] instead of [LEN_V][VAL][LEN_T][TAG]
-		if currentOffset >= len(dataBytes) {
+		lenV, lenT, compressed, repeat, n, ok := b.readLengths(dataBytes, currentOffset)
+		if !ok {
 			return "", false
 		}
-		lenV := int(dataBytes[currentOffset])
-		currentOffset++
+		currentOffset += n
 
-		lenT := 0
-		if hasTags {
-			if currentOffset >= len(dataBytes) {
-				return "", false
-			}
-			lenT = int(dataBytes[currentOffset])
-			currentOffset++
+		if !repeat {
+			valOffset, valLen, valCompressed = currentOffset, lenV, compressed
 		}
 
 		// If this is our target index, read and return the value
 		if i == index {
-			if currentOffset+lenV > len(dataBytes) {
+			if valOffset < 0 {
 				return "", false
 			}
-			val := string(dataBytes[currentOffset : currentOffset+lenV])
 
-			return val, true
+			return b.readValue(dataBytes, valOffset, valLen, valCompressed)
 		}
 
 		// Skip both value and tag data
@@ -448,16 +476,19 @@ func (b TextBlob) timestampAtFromEntry(entry section.TextIndexEntry, index int)
 		return 0, false
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, table, interval, ok := b.metricRows(entry)
+	if !ok {
+		return 0, false
+	}
 
-	// Skip to the target index
-	currentOffset := 0
+	// Skip to the target index from the nearest checkpoint
+	currentOffset, start, ok := b.seekRow(dataBytes, table, interval, index)
+	if !ok {
+		return 0, false
+	}
 	lastTs := b.startTimeMicros
-	hasTags := b.HasTag()
 
-	for i := range count {
+	for i := start; i < count; i++ {
 		// Decode timestamp
 		ts, n, err := b.decodeTimestampAt(dataBytes, currentOffset, &lastTs)
 		if err != nil {
@@ -471,20 +502,11 @@ func (b TextBlob) timestampAtFromEntry(entry section.TextIndexEntry, index int)
 		}
 
 		// NEW LAYOUT: Read grouped length bytes
-		if currentOffset >= len(dataBytes) {
+		lenV, lenT, _, _, n, ok := b.readLengths(dataBytes, currentOffset)
+		if !ok {
 			return 0, false
 		}
-		lenV := int(dataBytes[currentOffset])
-		currentOffset++
-
-		lenT := 0
-		if hasTags {
-			if currentOffset >= len(dataBytes) {
-				return 0, false
-			}
-			lenT = int(dataBytes[currentOffset])
-			currentOffset++
-		}
+		currentOffset += n
 
 		// Skip both value and tag data
 		currentOffset += lenV + lenT
@@ -500,15 +522,19 @@ func (b TextBlob) tagAtFromEntry(entry section.TextIndexEntry, index int) (strin
 		return "", false
 	}
 
-	offset := int(entry.Offset)
-	size := int(entry.Size)
-	dataBytes := b.dataPayload[offset : offset+size]
+	dataBytes, table, interval, ok := b.metricRows(entry)
+	if !ok {
+		return "", false
+	}
 
-	// Skip to the target index
-	currentOffset := 0
+	// Skip to the target index from the nearest checkpoint
+	currentOffset, start, ok := b.seekRow(dataBytes, table, interval, index)
+	if !ok {
+		return "", false
+	}
 	lastTs := b.startTimeMicros
 
-	for i := range count {
+	for i := start; i < count; i++ {
 		// Skip timestamp
 		_, n, err := b.decodeTimestampAt(dataBytes, currentOffset, &lastTs)
 		if err != nil {
@@ -517,12 +543,11 @@ func (b TextBlob) tagAtFromEntry(entry section.TextIndexEntry, index int) (strin
 		currentOffset += n
 
 		// NEW LAYOUT: Read grouped length bytes
-		if currentOffset+1 >= len(dataBytes) {
+		lenV, lenT, _, _, n, ok := b.readLengths(dataBytes, currentOffset)
+		if !ok {
 			return "", false
 		}
-		lenV := int(dataBytes[currentOffset])
-		lenT := int(dataBytes[currentOffset+1])
-		currentOffset += 2
+		currentOffset += n
 
 		// Skip value data
 		currentOffset += lenV
@@ -532,7 +557,7 @@ func (b TextBlob) tagAtFromEntry(entry section.TextIndexEntry, index int) (strin
 			if currentOffset+lenT > len(dataBytes) {
 				return "", false
 			}
-			tag := string(dataBytes[currentOffset : currentOffset+lenT])
+			tag := b.interner.String(dataBytes[currentOffset : currentOffset+lenT])
 
 			return tag, true
 		}
@@ -546,14 +571,23 @@ func (b TextBlob) tagAtFromEntry(entry section.TextIndexEntry, index int) (strin
 
 // decodeDataPoints decodes a data section and returns an iterator over all data points.
 // The data section contains interleaved timestamps, values, and optional tags.
-func (b TextBlob) decodeDataPoints(dataBytes []byte, count int) iter.Seq2[int, TextDataPoint] {
+// interval is the seek interval of the metric, 0 if it has no seek table.
+func (b TextBlob) decodeDataPoints(dataBytes []byte, count, interval int) iter.Seq2[int, TextDataPoint] {
 	return func(yield func(int, TextDataPoint) bool) {
 		offset := 0
 		// Initialize lastTs to blob start time for delta encoding
 		// For raw encoding, this value is not used
 		lastTs := b.startTimeMicros
+		checkpoint := interval
+		var val string
 
 		for i := range count {
+			// Checkpoint rows encode their delta from blob start time
+			if i == checkpoint && interval > 0 {
+				lastTs = b.startTimeMicros
+				checkpoint += interval
+			}
+
 			// Decode timestamp
 			ts, n, err := b.decodeTimestampAt(dataBytes, offset, &lastTs)
 			if err != nil {
@@ -562,27 +596,19 @@ func (b TextBlob) decodeDataPoints(dataBytes []byte, count int) iter.Seq2[int, T
 			offset += n
 
 			// NEW LAYOUT: Read grouped length bytes first
-			if offset >= len(dataBytes) {
+			lenV, lenT, compressed, repeat, n, ok := b.readLengths(dataBytes, offset)
+			if !ok || (repeat && i == 0) {
 				return
 			}
-			lenV := int(dataBytes[offset])
-			offset++
+			offset += n
 
-			lenT := 0
-			if b.HasTag() {
-				if offset >= len(dataBytes) {
+			// Read value, a repeated value keeps the previous one
+			if !repeat {
+				if val, ok = b.readValue(dataBytes, offset, lenV, compressed); !ok {
 					return
 				}
-				lenT = int(dataBytes[offset])
-				offset++
-			}
-
-			// Read value
-			if offset+lenV > len(dataBytes) {
-				return
+				offset += lenV
 			}
-			val := string(dataBytes[offset : offset+lenV])
-			offset += lenV
 
 			// Read tag if enabled
 			var tag string
@@ -590,7 +616,7 @@ func (b TextBlob) decodeDataPoints(dataBytes []byte, count int) iter.Seq2[int, T
 				if offset+lenT > len(dataBytes) {
 					return
 				}
-				tag = string(dataBytes[offset : offset+lenT])
+				tag = b.interner.String(dataBytes[offset : offset+lenT])
 				offset += lenT
 			}
 
@@ -602,13 +628,20 @@ func (b TextBlob) decodeDataPoints(dataBytes []byte, count int) iter.Seq2[int, T
 }
 
 // decodeTimestamps decodes only timestamps from the data section.
-func (b TextBlob) decodeTimestamps(dataBytes []byte, count int) iter.Seq[int64] {
+func (b TextBlob) decodeTimestamps(dataBytes []byte, count, interval int) iter.Seq[int64] {
 	return func(yield func(int64) bool) {
 		offset := 0
 		// Initialize lastTs to blob start time for delta encoding
 		lastTs := b.startTimeMicros
+		checkpoint := interval
+
+		for i := range count {
+			// Checkpoint rows encode their delta from blob start time
+			if i == checkpoint && interval > 0 {
+				lastTs = b.startTimeMicros
+				checkpoint += interval
+			}
 
-		for range count {
 			// Decode timestamp
 			ts, n, err := b.decodeTimestampAt(dataBytes, offset, &lastTs)
 			if err != nil {
@@ -617,20 +650,11 @@ func (b TextBlob) decodeTimestamps(dataBytes []byte, count int) iter.Seq[int64]
 			offset += n
 
 			// NEW LAYOUT: Read grouped length bytes
-			if offset >= len(dataBytes) {
+			lenV, lenT, _, _, n, ok := b.readLengths(dataBytes, offset)
+			if !ok {
 				return
 			}
-			lenV := int(dataBytes[offset])
-			offset++
-
-			lenT := 0
-			if b.HasTag() {
-				if offset >= len(dataBytes) {
-					return
-				}
-				lenT = int(dataBytes[offset])
-				offset++
-			}
+			offset += n
 
 			// Skip both value and tag data
 			offset += lenV + lenT
@@ -643,13 +667,21 @@ func (b TextBlob) decodeTimestamps(dataBytes []byte, count int) iter.Seq[int64]
 }
 
 // decodeValues decodes only values from the data section.
-func (b TextBlob) decodeValues(dataBytes []byte, count int) iter.Seq[string] {
+func (b TextBlob) decodeValues(dataBytes []byte, count, interval int) iter.Seq[string] {
 	return func(yield func(string) bool) {
 		offset := 0
 		// Initialize lastTs to blob start time for delta encoding
 		lastTs := b.startTimeMicros
+		checkpoint := interval
+		var val string
+
+		for i := range count {
+			// Checkpoint rows encode their delta from blob start time
+			if i == checkpoint && interval > 0 {
+				lastTs = b.startTimeMicros
+				checkpoint += interval
+			}
 
-		for range count {
 			// Skip timestamp
 			_, n, err := b.decodeTimestampAt(dataBytes, offset, &lastTs)
 			if err != nil {
@@ -658,27 +690,19 @@ func (b TextBlob) decodeValues(dataBytes []byte, count int) iter.Seq[string] {
 			offset += n
 
 			// NEW LAYOUT: Read grouped length bytes
-			if offset >= len(dataBytes) {
+			lenV, lenT, compressed, repeat, n, ok := b.readLengths(dataBytes, offset)
+			if !ok || (repeat && i == 0) {
 				return
 			}
-			lenV := int(dataBytes[offset])
-			offset++
+			offset += n
 
-			lenT := 0
-			if b.HasTag() {
-				if offset >= len(dataBytes) {
+			// Read value, a repeated value keeps the previous one
+			if !repeat {
+				if val, ok = b.readValue(dataBytes, offset, lenV, compressed); !ok {
 					return
 				}
-				lenT = int(dataBytes[offset])
-				offset++
-			}
-
-			// Read value
-			if offset+lenV > len(dataBytes) {
-				return
+				offset += lenV
 			}
-			val := string(dataBytes[offset : offset+lenV])
-			offset += lenV
 
 			// Skip tag data
 			offset += lenT
@@ -691,13 +715,20 @@ func (b TextBlob) decodeValues(dataBytes []byte, count int) iter.Seq[string] {
 }
 
 // decodeTags decodes only tags from the data section.
-func (b TextBlob) decodeTags(dataBytes []byte, count int) iter.Seq[string] {
+func (b TextBlob) decodeTags(dataBytes []byte, count, interval int) iter.Seq[string] {
 	return func(yield func(string) bool) {
 		offset := 0
 		// Initialize lastTs to blob start time for delta encoding
 		lastTs := b.startTimeMicros
+		checkpoint := interval
+
+		for i := range count {
+			// Checkpoint rows encode their delta from blob start time
+			if i == checkpoint && interval > 0 {
+				lastTs = b.startTimeMicros
+				checkpoint += interval
+			}
 
-		for range count {
 			// Skip timestamp
 			_, n, err := b.decodeTimestampAt(dataBytes, offset, &lastTs)
 			if err != nil {
@@ -706,12 +737,11 @@ func (b TextBlob) decodeTags(dataBytes []byte, count int) iter.Seq[string] {
 			offset += n
 
 			// NEW LAYOUT: Read grouped length bytes
-			if offset+1 >= len(dataBytes) {
+			lenV, lenT, _, _, n, ok := b.readLengths(dataBytes, offset)
+			if !ok {
 				return
 			}
-			lenV := int(dataBytes[offset])
-			lenT := int(dataBytes[offset+1])
-			offset += 2
+			offset += n
 
 			// Skip value data
 			offset += lenV
@@ -720,7 +750,7 @@ func (b TextBlob) decodeTags(dataBytes []byte, count int) iter.Seq[string] {
 			if offset+lenT > len(dataBytes) {
 				return
 			}
-			tag := string(dataBytes[offset : offset+lenT])
+			tag := b.interner.String(dataBytes[offset : offset+lenT])
 			offset += lenT
 
 			if !yield(tag) {
@@ -730,6 +760,68 @@ func (b TextBlob) decodeTags(dataBytes []byte, count int) iter.Seq[string] {
 	}
 }
 
+// readLengths reads the grouped value and tag lengths of the row at offset and
+// returns them with the number of bytes read. compressed reports a value stored
+// by per-value compression, and repeat a value equal to the previous one, which
+// is not stored (lenV is 0). ok is false if the lengths are truncated.
+func (b TextBlob) readLengths(data []byte, offset int) (lenV, lenT int, compressed, repeat bool, n int, ok bool) {
+	if offset >= len(data) {
+		return 0, 0, false, false, 0, false
+	}
+
+	if b.HasValueCompression() || b.HasValueRLE() {
+		marker, m := binary.Uvarint(data[offset:])
+		if m <= 0 {
+			return 0, 0, false, false, 0, false
+		}
+		n = m
+
+		if b.HasValueRLE() {
+			repeat = marker == 0
+			if !repeat {
+				marker--
+			}
+		}
+		if b.HasValueCompression() {
+			compressed = marker&1 != 0
+			marker >>= 1
+		}
+		if marker > uint64(len(data)) {
+			return 0, 0, false, false, 0, false
+		}
+		lenV = int(marker) //nolint: gosec // bounded by len(data)
+	} else {
+		lenV, n = int(data[offset]), 1
+	}
+
+	if b.HasTag() {
+		if offset+n >= len(data) {
+			return 0, 0, false, false, 0, false
+		}
+		lenT = int(data[offset+n])
+		n++
+	}
+
+	return lenV, lenT, compressed, repeat, n, true
+}
+
+// readValue reads the value of lenV bytes at offset, decompressing it if compressed.
+func (b TextBlob) readValue(data []byte, offset, lenV int, compressed bool) (string, bool) {
+	if offset+lenV > len(data) {
+		return "", false
+	}
+
+	raw := data[offset : offset+lenV]
+	if compressed {
+		var err error
+		if raw, err = decompressTextValue(raw, b.valueDict); err != nil {
+			return "", false
+		}
+	}
+
+	return b.interner.String(raw), true
+}
+
 // decodeTimestampAt decodes a single timestamp at the given offset.
 // Returns the timestamp, bytes consumed, and any error.
 // Updates lastTs for delta encoding.
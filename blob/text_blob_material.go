@@ -57,7 +57,7 @@ func (b TextBlob) Materialize() MaterializedTextBlob {
 	}
 
 	// Decode all metrics using optimized direct decoding
-	for metricID, entry := range b.index.byID {
+	for metricID, entry := range b.index.resolve().byID {
 		// Pre-allocate slices with exact size for direct indexing (no append overhead)
 		count := int(entry.Count)
 		timestamps := make([]int64, count)
@@ -96,8 +96,8 @@ func (b TextBlob) Materialize() MaterializedTextBlob {
 	}
 
 	// Copy metric name mappings (if available)
-	if b.index.byName != nil {
-		for name, entry := range b.index.byName {
+	if byName := b.index.nameMap(); byName != nil {
+		for name, entry := range byName {
 			material.names[name] = entry.MetricID
 		}
 	}
@@ -310,7 +310,7 @@ type MaterializedTextMetric struct {
 //	val, _ := metric.ValueAt(500)  // O(1), ~5ns
 //	ts, _ := metric.TimestampAt(500)
 func (b TextBlob) MaterializeMetric(metricID uint64) (MaterializedTextMetric, bool) {
-	entry, ok := b.index.byID[metricID]
+	entry, ok := b.index.GetByID(metricID)
 	if !ok {
 		return MaterializedTextMetric{}, false
 	}
@@ -487,5 +487,5 @@ func (s TextBlobSet) MetricDuration(metricID uint64) int64 {
 //	duration := blobSet.MetricDurationByName("log.message")
 //	fmt.Printf("Metric spans %d timestamp units\n", duration)
 func (s TextBlobSet) MetricDurationByName(metricName string) int64 {
-	return calculateDurationByName(s.blobs, metricName)
+	return calculateDurationByName(s.blobs, nil, metricName)
 }
@@ -7,6 +7,8 @@ type materializedTextMetricSet struct {
 	timestamps []int64  // Flattened timestamps across all blobs
 	values     []string // Flattened text values across all blobs
 	tags       []string // Flattened tags across all blobs (empty if no tags)
+	timeOrder  []int    // Global indices sorted by timestamp (nil if timestamps are sorted)
+	timeIndex  bool     // Whether timeOrder was built (WithTimeIndex)
 }
 
 // MaterializedTextBlobSet represents a TextBlobSet with all data pre-decoded and
@@ -52,7 +54,9 @@ type materializedTextMetricSet struct {
 //   - You only need sequential iteration (use TextBlobSet.All())
 //   - Memory is constrained
 //   - You're only accessing a few data points
-func (s *TextBlobSet) Materialize() MaterializedTextBlobSet {
+func (s *TextBlobSet) Materialize(opts ...MaterializeOption) MaterializedTextBlobSet {
+	cfg := newMaterializeConfig(opts)
+
 	if len(s.blobs) == 0 {
 		return MaterializedTextBlobSet{
 			data:  make(map[uint64]materializedTextMetricSet),
@@ -99,13 +103,21 @@ func (s *TextBlobSet) Materialize() MaterializedTextBlobSet {
 	// Step 5: Build metric name mapping if available
 	for i := range s.blobs {
 		blob := &s.blobs[i]
-		if blob.index.byName != nil {
-			for name, entry := range blob.index.byName {
+		if byName := blob.index.nameMap(); byName != nil {
+			for name, entry := range byName {
 				material.names[name] = entry.MetricID
 			}
 		}
 	}
 
+	if cfg.TimeIndex {
+		for metricID, metricSet := range material.data {
+			metricSet.timeOrder = buildTimeOrder(metricSet.timestamps)
+			metricSet.timeIndex = true
+			material.data[metricID] = metricSet
+		}
+	}
+
 	return material
 }
 
@@ -398,6 +410,33 @@ func (m MaterializedTextBlobSet) TagAtByName(metricName string, index int) (stri
 	return m.TagAt(metricID, index)
 }
 
+// SearchTime returns the global index of the earliest data point of the given metric
+// ID whose timestamp is at or after ts. Among equal timestamps the lowest global index
+// is returned. Returns (0, false) if the metric ID is not found or no data point is at
+// or after ts.
+//
+// With WithTimeIndex this is an O(log n) binary search that handles overlapping blobs;
+// otherwise the metric's timestamps are scanned linearly.
+func (m MaterializedTextBlobSet) SearchTime(metricID uint64, ts int64) (int, bool) {
+	metricSet, ok := m.data[metricID]
+	if !ok {
+		return 0, false
+	}
+
+	return searchTime(metricSet.timestamps, metricSet.timeOrder, metricSet.timeIndex, ts)
+}
+
+// SearchTimeByName returns the global index of the earliest data point of the given
+// metric name whose timestamp is at or after ts. See SearchTime for details.
+func (m MaterializedTextBlobSet) SearchTimeByName(metricName string, ts int64) (int, bool) {
+	metricID, ok := m.names[metricName]
+	if !ok {
+		return 0, false
+	}
+
+	return m.SearchTime(metricID, ts)
+}
+
 // MetricCount returns the total number of unique metrics across all blobs.
 func (m MaterializedTextBlobSet) MetricCount() int {
 	return len(m.data)
@@ -416,12 +455,32 @@ func (m MaterializedTextBlobSet) DataPointCount(metricID uint64) int {
 	return len(metricSet.values)
 }
 
+// DataPointCountByName returns the total number of data points for the given metric
+// name across all blobs.
+//
+// Returns 0 if the metric name doesn't exist or names are not available.
+func (m MaterializedTextBlobSet) DataPointCountByName(metricName string) int {
+	metricID, ok := m.names[metricName]
+	if !ok {
+		return 0
+	}
+
+	return m.DataPointCount(metricID)
+}
+
 // HasMetricID returns true if the given metric ID exists in the materialized data.
 func (m MaterializedTextBlobSet) HasMetricID(metricID uint64) bool {
 	_, ok := m.data[metricID]
 	return ok
 }
 
+// HasMetricName returns true if the given metric name exists in the materialized data.
+// Returns false if metric names are not available in any blob.
+func (m MaterializedTextBlobSet) HasMetricName(metricName string) bool {
+	_, ok := m.names[metricName]
+	return ok
+}
+
 // MetricIDs returns a slice of all metric IDs in the materialized data.
 // The order is non-deterministic (map iteration order).
 func (m MaterializedTextBlobSet) MetricIDs() []uint64 {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
 )
 
 // Helper function to create a test TextBlobSet with specified number of blobs
@@ -284,6 +285,68 @@ func TestMaterializedTextBlobSet_MetadataMethods(t *testing.T) {
 	require.Equal(t, 40, mat.DataPointCount(200)) // 2 blobs × 20 points
 }
 
+// TestMaterializedTextBlobSet_ByName tests by-name accessors across blobs
+func TestMaterializedTextBlobSet_ByName(t *testing.T) {
+	baseTime := time.Now()
+	blobs := make([]TextBlob, 2)
+
+	for blobIdx := range 2 {
+		startTime := baseTime.Add(time.Duration(blobIdx) * time.Hour)
+		encoder, err := NewTextEncoder(startTime, WithTextTagsEnabled(true))
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricName("service.status", 2))
+		for i := range 2 {
+			ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, "status_"+string(rune('A'+blobIdx*2+i)), "host"+string(rune('0'+blobIdx))))
+		}
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewTextDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		blobs[blobIdx] = blob
+	}
+
+	blobSet, err := NewTextBlobSet(blobs)
+	require.NoError(t, err)
+	mat := blobSet.Materialize()
+
+	require.True(t, mat.HasMetricName("service.status"))
+	require.False(t, mat.HasMetricName("nonexistent"))
+	require.Equal(t, 4, mat.DataPointCountByName("service.status"))
+	require.Equal(t, 0, mat.DataPointCountByName("nonexistent"))
+
+	val, ok := mat.ValueAtByName("service.status", 3)
+	require.True(t, ok)
+	require.Equal(t, "status_D", val)
+
+	ts, ok := mat.TimestampAtByName("service.status", 2)
+	require.True(t, ok)
+	require.Equal(t, baseTime.Add(time.Hour).UnixMicro(), ts)
+
+	tag, ok := mat.TagAtByName("service.status", 2)
+	require.True(t, ok)
+	require.Equal(t, "host1", tag)
+
+	// By-name and by-ID access agree
+	id := hash.ID("service.status")
+	for i := range 4 {
+		byName, _ := mat.ValueAtByName("service.status", i)
+		byID, _ := mat.ValueAt(id, i)
+		require.Equal(t, byID, byName)
+	}
+
+	_, ok = mat.ValueAtByName("service.status", 4)
+	require.False(t, ok)
+	_, ok = mat.TagAtByName("nonexistent", 0)
+	require.False(t, ok)
+}
+
 // TestMaterializedTextBlobSet_Correctness validates correctness against sequential iteration
 func TestMaterializedTextBlobSet_Correctness(t *testing.T) {
 	metricID := uint64(100)
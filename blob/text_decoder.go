@@ -9,6 +9,7 @@ import (
 	"github.com/arloliu/mebo/format"
 	ienc "github.com/arloliu/mebo/internal/encoding"
 	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/internal/options"
 	"github.com/arloliu/mebo/section"
 )
 
@@ -29,6 +30,41 @@ type TextDecoder struct {
 	metricCount int
 	engine      endian.EndianEngine
 	header      *section.TextHeader
+	interner    *ienc.Interner // Shared by all strings of the decoded blob, nil if disabled
+	lazyIndex   bool           // Defer building the metric index until the first lookup
+	hooks       ReadHooks      // Section decode instrumentation, nil if disabled
+}
+
+// TextDecoderOption is a functional option for configuring TextDecoder.
+type TextDecoderOption = options.Option[*TextDecoder]
+
+// WithTextStringInterning deduplicates the value and tag strings produced by the
+// decoded text blob.
+//
+// Identical values and tags across points and metrics share one allocation, and
+// lookups of already-seen strings do not allocate. This cuts retained heap by large
+// factors for blobs with repetitive values (status codes, host names) at the cost
+// of a map lookup per string. The interner lives as long as the decoded blob and is
+// safe for concurrent use.
+func WithTextStringInterning(enabled bool) TextDecoderOption {
+	return options.NoError(func(d *TextDecoder) {
+		d.interner = nil
+		if enabled {
+			d.interner = ienc.NewInterner()
+		}
+	})
+}
+
+// WithTextLazyIndex defers building the decoded text blob's metric ID and name
+// maps until the first metric lookup.
+//
+// Workloads that decode blobs only to verify or re-upload them never look metrics
+// up, so the lazy mode skips that work. The maps are built once, on the first call
+// to a lookup or iteration method, and are safe under concurrent first use.
+func WithTextLazyIndex(enabled bool) TextDecoderOption {
+	return options.NoError(func(d *TextDecoder) {
+		d.lazyIndex = enabled
+	})
 }
 
 // NewTextDecoder creates a new TextDecoder for the given encoded data.
@@ -38,15 +74,35 @@ type TextDecoder struct {
 //
 // Parameters:
 //   - data: Encoded blob byte slice (must contain valid header)
+//   - opts: Optional decoder options (e.g., WithTextStringInterning)
 //
 // Returns:
 //   - *TextDecoder: New decoder instance ready for decoding
-//   - error: Header parsing error or invalid data format
-func NewTextDecoder(data []byte) (*TextDecoder, error) {
+//   - error: Header parsing error or invalid data format, or ErrInvalidMagicNumber
+//     for event blobs (use NewEventDecoder)
+func NewTextDecoder(data []byte, opts ...TextDecoderOption) (*TextDecoder, error) {
+	decoder, err := newTextDecoder(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if decoder.header.Flag.IsEvent() {
+		return nil, fmt.Errorf("%w: event blob, use NewEventDecoder", errs.ErrInvalidMagicNumber)
+	}
+
+	return decoder, nil
+}
+
+// newTextDecoder creates a TextDecoder for text and event blobs.
+func newTextDecoder(data []byte, opts ...TextDecoderOption) (*TextDecoder, error) {
 	decoder := &TextDecoder{
 		data: data,
 	}
 
+	if err := options.Apply(decoder, opts...); err != nil {
+		return nil, err
+	}
+
 	if err := decoder.parseHeader(); err != nil {
 		return nil, err
 	}
@@ -64,37 +120,8 @@ func NewTextDecoder(data []byte) (*TextDecoder, error) {
 //   - error: Payload offset validation errors, decompression errors, index parsing errors,
 //     or metric name verification failures
 func (d *TextDecoder) Decode() (TextBlob, error) {
-	// Pack flags into single uint16 for size optimization
-	var flags uint16
-	if d.header.Flag.IsBigEndian() {
-		flags |= section.FlagEndianLittleEndian // 1=big endian
-	}
-	if d.header.Flag.GetTimestampEncoding() == format.TypeRaw {
-		flags |= section.FlagTsEncRaw
-	}
-	if d.header.Flag.HasTag() {
-		flags |= section.FlagTagEnabled
-	}
-	if d.header.Flag.HasMetricNames() {
-		flags |= section.FlagMetricNames
-	}
-
-	blob := TextBlob{
-		blobBase: blobBase{
-			tsEncType:     d.header.Flag.GetTimestampEncoding(),
-			flags:         flags, // Packed flags (optimized)
-			formatVersion: blobFormatV1,
-			sameByteOrder: endian.CompareNativeEndian(d.engine),
-			endianType: func() uint8 {
-				if d.header.Flag.IsBigEndian() {
-					return 1
-				}
-
-				return 0
-			}(), // 0=little, 1=big
-			startTimeMicros: d.header.StartTime, // Direct int64 assignment (optimized)
-		},
-	}
+	blob := TextBlob{blobBase: newTextBlobBase(d.header)}
+	blob.interner = d.interner
 
 	// Validate payload offsets
 	dataOffset := int(d.header.DataOffset)
@@ -114,34 +141,56 @@ func (d *TextDecoder) Decode() (TextBlob, error) {
 		return blob, err
 	}
 
-	// Step 3: Build index entry map
-	blob.index.byID = make(map[uint64]section.TextIndexEntry, d.metricCount)
-	for _, entry := range indexEntries {
-		blob.index.byID[entry.MetricID] = entry
-	}
-
-	// Step 4: Verify and populate metric name map (if metric names present)
+	// Step 3: Verify metric names (if present) against their hashed IDs
 	if len(metricNames) > 0 {
 		if err := ienc.VerifyMetricNamesHashes(metricNames, metricIDs, hash.ID); err != nil {
 			return blob, fmt.Errorf("metric name verification failed: %w", err)
 		}
+	}
 
-		// Populate metric name map for ByName lookups
-		// metricNames[i] corresponds to indexEntries[i] (consistent ordering)
-		blob.index.byName = make(map[string]section.TextIndexEntry, d.metricCount)
-		for i, name := range metricNames {
-			blob.index.byName[name] = indexEntries[i]
+	// Step 4: Build index entry and metric name maps (metricNames[i] corresponds
+	// to indexEntries[i]), deferred to the first lookup with lazy indexing
+	build := func() indexMaps[section.TextIndexEntry] {
+		var index indexMaps[section.TextIndexEntry]
+		index.byID = make(map[uint64]section.TextIndexEntry, len(indexEntries))
+		for _, entry := range indexEntries {
+			index.byID[entry.MetricID] = entry
 		}
+		index.byName = nameIndex(metricNames, indexEntries)
+
+		return index
+	}
+	if d.lazyIndex {
+		blob.index = newLazyIndexMaps(build)
+	} else {
+		blob.index = build()
 	}
 
-	// Step 5: Decompress data payload
-	dataPayload, err := d.decompressData(dataOffset)
+	// Step 5: Decompress data payload, restoring the tags of a separate tag section
+	var dataPayload []byte
+	if d.header.Flag.HasTagSection() {
+		dataPayload, err = d.decompressTagSection(blob, dataOffset, indexEntries)
+	} else {
+		dataPayload, err = d.decompressData(dataOffset)
+	}
 	if err != nil {
 		return blob, err
 	}
 
 	blob.dataPayload = dataPayload
 
+	if dictOffset := int(d.header.ValueDictionaryOffset()); dictOffset != 0 {
+		if dictOffset >= len(dataPayload) {
+			return blob, fmt.Errorf("%w: dictionary offset %d exceeds data size %d",
+				errs.ErrInvalidValueDictionary, dictOffset, len(dataPayload))
+		}
+		if blob.valueDict, err = compress.NewZstdDictCompressor(dataPayload[dictOffset:]); err != nil {
+			return blob, err
+		}
+	}
+
+	blob.blobID = newLazyBlobID(d.data[:dataOffset], d.data[dataOffset:])
+
 	return blob, nil
 }
 
@@ -196,6 +245,23 @@ func (d *TextDecoder) parseIndexEntries(startOffset int) ([]section.TextIndexEnt
 			errs.ErrInvalidIndexEntrySize, expectedIndexSize, len(d.data)-startOffset)
 	}
 
+	// Event blobs end the data section with their text dictionary, and text
+	// blobs with their value dictionary if any
+	rowsSize := d.header.DataSize
+	if d.header.Flag.IsEvent() {
+		rowsSize = d.header.EventDictionaryOffset()
+		if rowsSize > d.header.DataSize {
+			return nil, nil, fmt.Errorf("%w: dictionary offset %d exceeds data size %d",
+				errs.ErrInvalidEventDictionary, rowsSize, d.header.DataSize)
+		}
+	} else if dictOffset := d.header.ValueDictionaryOffset(); dictOffset != 0 {
+		if dictOffset >= d.header.DataSize {
+			return nil, nil, fmt.Errorf("%w: dictionary offset %d exceeds data size %d",
+				errs.ErrInvalidValueDictionary, dictOffset, d.header.DataSize)
+		}
+		rowsSize = dictOffset
+	}
+
 	indexEntries := make([]section.TextIndexEntry, d.metricCount)
 	metricIDs := make([]uint64, d.metricCount)
 
@@ -210,12 +276,12 @@ func (d *TextDecoder) parseIndexEntries(startOffset int) ([]section.TextIndexEnt
 		// For last entry, use total data size from header
 		if i == d.metricCount-1 {
 			// Validate last entry offset doesn't exceed data size (prevents uint32 underflow)
-			if entry.Offset > d.header.DataSize {
+			if entry.Offset > rowsSize {
 				return nil, nil, fmt.Errorf("%w: last entry offset %d exceeds data size %d",
-					errs.ErrInvalidIndexOffsets, entry.Offset, d.header.DataSize)
+					errs.ErrInvalidIndexOffsets, entry.Offset, rowsSize)
 			}
 
-			entry.Size = d.header.DataSize - entry.Offset
+			entry.Size = rowsSize - entry.Offset
 		}
 
 		indexEntries[i] = entry
@@ -242,6 +308,15 @@ func (d *TextDecoder) decompressData(dataOffset int) ([]byte, error) {
 
 	// If no compression, return the raw data section
 	if compressionType == 0 {
+		if d.hooks != nil {
+			d.hooks.OnDecodeSection(SectionEvent{
+				Section:      SectionTextData,
+				Compression:  format.CompressionNone,
+				StoredBytes:  len(d.data) - dataOffset,
+				DecodedBytes: len(d.data) - dataOffset,
+			})
+		}
+
 		return d.data[dataOffset:], nil
 	}
 
@@ -254,7 +329,7 @@ func (d *TextDecoder) decompressData(dataOffset int) ([]byte, error) {
 		return nil, fmt.Errorf("failed to create decompression codec: %w", err)
 	}
 
-	decompressedData, err := codec.Decompress(compressedData)
+	decompressedData, err := decompressSection(d.hooks, SectionTextData, compressionType, compressedData, codec.Decompress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decompress data: %w", err)
 	}
@@ -268,3 +343,47 @@ func (d *TextDecoder) decompressData(dataOffset int) ([]byte, error) {
 
 	return decompressedData, nil
 }
+
+// newTextBlobBase returns the blob base of a text blob with the given header.
+func newTextBlobBase(header *section.TextHeader) blobBase {
+	flag := header.Flag
+
+	// Pack flags into single uint16 for size optimization
+	var flags uint16
+	if flag.IsBigEndian() {
+		flags |= section.FlagEndianLittleEndian // 1=big endian
+	}
+	if flag.GetTimestampEncoding() == format.TypeRaw {
+		flags |= section.FlagTsEncRaw
+	}
+	if flag.HasTag() {
+		flags |= section.FlagTagEnabled
+	}
+	if flag.HasMetricNames() {
+		flags |= section.FlagMetricNames
+	}
+	if flag.HasValueCompression() {
+		flags |= section.FlagValueCompression
+	}
+	if flag.HasSeekIndex() {
+		flags |= section.FlagSeekIndex
+	}
+	if flag.HasValueRLE() {
+		flags |= section.FlagValueRLE
+	}
+
+	return blobBase{
+		tsEncType:     flag.GetTimestampEncoding(),
+		flags:         flags, // Packed flags (optimized)
+		formatVersion: blobFormatV1,
+		sameByteOrder: endian.CompareNativeEndian(header.GetEndianEngine()),
+		endianType: func() uint8 {
+			if flag.IsBigEndian() {
+				return 1
+			}
+
+			return 0
+		}(), // 0=little, 1=big
+		startTimeMicros: header.StartTime, // Direct int64 assignment (optimized)
+	}
+}
@@ -3,6 +3,7 @@ package blob
 import (
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/stretchr/testify/require"
 
@@ -504,3 +505,81 @@ func TestTextDecoder_MalformedIndex_IndexOffsetExceedsDataSize(t *testing.T) {
 		require.ErrorIs(t, err, errs.ErrInvalidIndexOffsets)
 	})
 }
+
+func TestTextDecoder_WithTextStringInterning(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewTextEncoder(startTime, WithTextTagsEnabled(true))
+	require.NoError(t, err)
+
+	for id := uint64(1); id <= 2; id++ {
+		require.NoError(t, encoder.StartMetricID(id, 4))
+		for i := range 4 {
+			ts := startTime.Add(time.Duration(i) * time.Second).UnixMicro()
+			require.NoError(t, encoder.AddDataPoint(ts, "status=ok", "host=a"))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data, WithTextStringInterning(true))
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+
+	firstVal, ok := blob.ValueAt(1, 0)
+	require.True(t, ok)
+	firstTag, ok := blob.TagAt(1, 0)
+	require.True(t, ok)
+
+	for id := uint64(1); id <= 2; id++ {
+		for _, dp := range blob.All(id) {
+			require.Same(t, unsafe.StringData(firstVal), unsafe.StringData(dp.Val))
+			require.Same(t, unsafe.StringData(firstTag), unsafe.StringData(dp.Tag))
+		}
+		for val := range blob.AllValues(id) {
+			require.Same(t, unsafe.StringData(firstVal), unsafe.StringData(val))
+		}
+		for tag := range blob.AllTags(id) {
+			require.Same(t, unsafe.StringData(firstTag), unsafe.StringData(tag))
+		}
+		val, ok := blob.ValueAt(id, 3)
+		require.True(t, ok)
+		require.Same(t, unsafe.StringData(firstVal), unsafe.StringData(val))
+	}
+}
+
+func TestTextDecoder_WithTextLazyIndex(t *testing.T) {
+	startTime := time.Now()
+	encoder, err := NewTextEncoder(startTime)
+	require.NoError(t, err)
+
+	for _, name := range []string{"status", "version"} {
+		require.NoError(t, encoder.StartMetricName(name, 2))
+		for i := range 2 {
+			require.NoError(t, encoder.AddDataPoint(startTime.Add(time.Duration(i)*time.Second).UnixMicro(), name, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	eager, err := decoder.Decode()
+	require.NoError(t, err)
+
+	decoder, err = NewTextDecoder(data, WithTextLazyIndex(true))
+	require.NoError(t, err)
+	lazy, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Nil(t, lazy.index.lazy.index.byID)
+
+	val, ok := lazy.ValueAtByName("version", 1)
+	require.True(t, ok)
+	require.Equal(t, "version", val)
+	require.NotNil(t, lazy.index.lazy.index.byID)
+	require.Equal(t, eager.Materialize(), lazy.Materialize())
+}
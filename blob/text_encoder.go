@@ -1,6 +1,7 @@
 package blob
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math"
 	"time"
@@ -20,9 +21,13 @@ import (
 // Unlike NumericEncoder which uses columnar storage (separate timestamp and value sections),
 // TextEncoder uses row-based storage where each data point is encoded as:
 //   - Timestamp (varint delta or int64 raw)
-//   - Value (uint8 length + string, max 255 UTF-8 bytes)
+//   - Value (uint8 length + string, max 255 UTF-8 bytes; with WithTextValueCompression,
+//     uvarint length with a compression marker, max MaxTextValueLength bytes)
 //   - Tag (uint8 length + string, max 255 UTF-8 bytes, optional)
 //
+// WithTextSeekIndex prefixes long metrics with a table of row offsets, and
+// WithTextValueRLE stores repeated values as repeat markers.
+//
 // The entire data section is compressed as a single unit after encoding.
 //
 // Note: The TextEncoder is NOT thread-safe. Each encoder instance should be used by a single goroutine at a time.
@@ -40,6 +45,12 @@ type TextEncoder struct {
 	// Delta encoding state - tracks last timestamp for efficient delta calculation
 	lastTimestamp int64 // Last encoded timestamp (reset to 0 in EndMetric for each new metric)
 
+	// Seek table and repeated value state of the current metric
+	seekRows  int    // seek interval of the current metric, 0 if it has no seek table
+	seekTable int    // data encoder offset of the current metric's seek table
+	rowsStart int    // data encoder offset of the current metric's first row
+	lastValue string // previous value of the current metric, for WithTextValueRLE
+
 	// Data encoder state tracking
 	dataState encoderState // data encoder state (24 bytes)
 
@@ -51,7 +62,8 @@ type TextEncoder struct {
 	identifierMode metricIdentifierMode // Locked after first StartMetric call
 
 	// Header immutability - track pending changes to apply in Finish()
-	hasCollision bool // Set when hash collision detected, applied to cloned header in Finish()
+	hasCollision  bool // Set when hash collision detected, applied to cloned header in Finish()
+	valueDictUsed bool // Set when a value is compressed against valueDict, which Finish then stores
 
 	// Pooled buffer for building data points
 	buf *pool.ByteBuffer
@@ -88,6 +100,11 @@ func NewTextEncoder(blobTS time.Time, opts ...TextEncoderOption) (*TextEncoder,
 	// Initialize data encoder
 	encoder.dataEncoder = ienc.NewVarStringEncoder(encoder.engine)
 
+	// Without tags there is no tag section
+	if !encoder.header.Flag.HasTag() {
+		encoder.header.Flag.SetTagCompression(0)
+	}
+
 	if err := encoder.setCodecs(*encoder.header); err != nil {
 		return nil, err
 	}
@@ -164,6 +181,16 @@ func (e *TextEncoder) startMetric(metricID uint64, numOfDataPoints int) error {
 	e.claimed = numOfDataPoints
 	e.added = 0
 	e.lastTimestamp = 0 // Initialize for delta encoding
+	e.lastValue = ""
+
+	// Reserve the seek table, filled in as checkpoint rows are added
+	e.seekRows = 0
+	e.seekTable = e.dataEncoder.Size()
+	if e.seekInterval > 0 && numOfDataPoints > e.seekInterval {
+		e.seekRows = e.seekInterval
+		e.dataEncoder.WriteRaw(make([]byte, seekTableSize(numOfDataPoints, e.seekRows)))
+	}
+	e.rowsStart = e.dataEncoder.Size()
 
 	return nil
 }
@@ -242,7 +269,8 @@ func (e *TextEncoder) StartMetricName(metricName string, numOfDataPoints int) er
 // Parameters:
 //   - timestamp: Caller-defined timestamp value (e.g. microseconds since Unix epoch).
 //     The unit must be consistent across all data points in the blob.
-//   - value: Text value string (max 255 UTF-8 bytes).
+//   - value: Text value string (max 255 UTF-8 bytes, or MaxTextValueLength bytes with
+//     WithTextValueCompression).
 //   - tag: Optional tag string (max 255 UTF-8 bytes, ignored if tag support is disabled).
 //
 // Returns:
@@ -257,6 +285,35 @@ func (e *TextEncoder) AddDataPoint(timestamp int64, value string, tag string) er
 		return fmt.Errorf("%w: claimed %d points, trying to add %d", errs.ErrTooManyDataPoints, e.claimed, e.added+1)
 	}
 
+	// Validate lengths before encoding
+	maxValueLen := ienc.MaxTextLength
+	if e.header.Flag.HasValueCompression() {
+		maxValueLen = MaxTextValueLength
+	}
+	if len(value) > maxValueLen {
+		return fmt.Errorf("value length %d exceeds maximum %d", len(value), maxValueLen)
+	}
+	if e.header.Flag.HasTag() && len(tag) > ienc.MaxTextLength {
+		return fmt.Errorf("tag length %d exceeds maximum %d", len(tag), ienc.MaxTextLength)
+	}
+
+	// Checkpoint rows of the seek table do not depend on previous rows
+	checkpoint := e.seekRows > 0 && e.added > 0 && e.added%e.seekRows == 0
+	repeat := e.header.Flag.HasValueRLE() && e.added > 0 && !checkpoint && value == e.lastValue
+
+	var stored []byte
+	var compressed bool
+	if !repeat {
+		var err error
+		if stored, compressed, err = e.encodeValue(value); err != nil {
+			return err
+		}
+	}
+
+	if checkpoint {
+		e.setSeekOffset(e.added / e.seekRows)
+	}
+
 	// Encode timestamp based on encoding type
 	e.buf.Reset()
 	tsEncoding := e.header.Flag.GetTimestampEncoding()
@@ -273,8 +330,8 @@ func (e *TextEncoder) AddDataPoint(timestamp int64, value string, tag string) er
 		// First data point: delta from blob start time
 		// Subsequent data points: delta from previous timestamp
 		var baseTs int64
-		if e.added == 0 {
-			// First data point: calculate delta from blob start time
+		if e.added == 0 || checkpoint {
+			// First data point and checkpoints: calculate delta from blob start time
 			baseTs = e.header.StartTime
 		} else {
 			// Subsequent data points: calculate delta from previous timestamp
@@ -301,33 +358,44 @@ func (e *TextEncoder) AddDataPoint(timestamp int64, value string, tag string) er
 		}
 	}
 
-	// Validate lengths before encoding
-	if len(value) > ienc.MaxTextLength {
-		return fmt.Errorf("value length %d exceeds maximum %d", len(value), ienc.MaxTextLength)
-	}
-	if e.header.Flag.HasTag() && len(tag) > ienc.MaxTextLength {
-		return fmt.Errorf("tag length %d exceeds maximum %d", len(tag), ienc.MaxTextLength)
-	}
-
 	// NEW LAYOUT: Group length bytes together before data
 	// Write [LEN_V][LEN_T] (if tags enabled), then [VAL][TAG]
 	// This improves cache locality during random access operations
 
 	// Write all length bytes together
 	e.buf.Reset()
-	e.buf.MustWrite([]byte{byte(len(value))}) //nolint:gosec // MaxTextLength bounds the value length to one byte.
+	if e.header.Flag.HasValueCompression() || e.header.Flag.HasValueRLE() {
+		// LEN_V is a uvarint of the stored length, with the compression marker in
+		// bit 0 if per-value compression is enabled, plus one if repeated values
+		// are enabled, where 0 marks a repeat of the previous value
+		marker := uint64(len(stored))
+		if e.header.Flag.HasValueCompression() {
+			marker <<= 1
+			if compressed {
+				marker |= 1
+			}
+		}
+		if e.header.Flag.HasValueRLE() && !repeat {
+			marker++
+		}
+		var lenBuf [binary.MaxVarintLen64]byte
+		e.buf.MustWrite(lenBuf[:binary.PutUvarint(lenBuf[:], marker)])
+	} else {
+		e.buf.MustWrite([]byte{byte(len(value))}) //nolint:gosec // MaxTextLength bounds the value length to one byte.
+	}
 	if e.header.Flag.HasTag() {
 		e.buf.MustWrite([]byte{byte(len(tag))}) //nolint:gosec // MaxTextLength bounds the tag length to one byte.
 	}
 	e.dataEncoder.WriteRaw(e.buf.Bytes())
 
 	// Write all data together
-	e.dataEncoder.WriteRaw([]byte(value))
+	e.dataEncoder.WriteRaw(stored)
 	if e.header.Flag.HasTag() {
 		e.dataEncoder.WriteRaw([]byte(tag))
 	}
 
 	e.added++
+	e.lastValue = value
 
 	return nil
 }
@@ -353,10 +421,11 @@ func (e *TextEncoder) EndMetric() error {
 
 	//nolint:gosec
 	entry := section.TextIndexEntry{
-		MetricID: e.curMetricID,
-		Count:    uint16(e.added),
-		Offset:   uint32(dataOffset),
-		Size:     uint32(dataLength),
+		MetricID:  e.curMetricID,
+		Count:     uint16(e.added),
+		Reserved1: uint16(e.seekRows),
+		Offset:    uint32(dataOffset),
+		Size:      uint32(dataLength),
 	}
 
 	// Add entry to index
@@ -370,6 +439,44 @@ func (e *TextEncoder) EndMetric() error {
 	e.claimed = 0
 	e.added = 0
 	e.lastTimestamp = 0 // Reset for next metric's delta encoding
+	e.lastValue = ""
+
+	return nil
+}
+
+// AbortMetric discards the current metric: its partially written data points,
+// its claimed data point count and its identifier. The encoder returns to the
+// state it had before the metric was started, so ingestion can recover from a
+// mid-metric upstream error and continue with the next metric, or start the
+// same metric again. Completed metrics are unaffected.
+//
+// Returns:
+//   - error: ErrNoMetricStarted if no metric is started
+func (e *TextEncoder) AbortMetric() error {
+	if e.curMetricID == 0 {
+		return errs.ErrNoMetricStarted
+	}
+
+	e.dataEncoder.Truncate(e.dataState.offset, e.dataState.length)
+
+	switch {
+	case len(e.indexEntries) == 0:
+		e.identifierMode = modeUndefined
+		e.collisionTracker = nil
+		e.usedIDs = nil
+		e.hasCollision = false
+	case e.collisionTracker != nil:
+		e.collisionTracker.Remove(e.collisionTracker.Count() - 1)
+		e.hasCollision = e.collisionTracker.HasCollision()
+	default:
+		delete(e.usedIDs, e.curMetricID)
+	}
+
+	e.curMetricID = 0
+	e.claimed = 0
+	e.added = 0
+	e.lastTimestamp = 0
+	e.lastValue = ""
 
 	return nil
 }
@@ -396,6 +503,44 @@ func (e *TextEncoder) FinishInto(dst []byte) ([]byte, error) {
 	return e.finishAppend(dst)
 }
 
+// FinishTo completes the encoding like Finish, but writes the encoded blob to
+// the start of dst's backing array, equivalent to FinishInto(dst[:0]). If
+// cap(dst) is at least MaxFinishedSize, no allocation occurs.
+//
+// Returns:
+//   - []byte: The complete encoded blob, backed by dst when it fits
+//   - error: ErrMetricNotEnded, ErrNoMetricsAdded, or compression errors
+//     (same conditions as Finish)
+func (e *TextEncoder) FinishTo(dst []byte) ([]byte, error) {
+	data, err := e.finishAppend(dst[:0])
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// MaxFinishedSize returns an upper bound of the size in bytes of the blob that
+// Finish would produce from the data added so far. The bound assumes an
+// uncompressed data payload. It does not modify the encoder.
+//
+// Returns:
+//   - int: Upper bound of the finished blob size in bytes
+func (e *TextEncoder) MaxFinishedSize() int {
+	size := section.HeaderSize + len(e.indexEntries)*section.TextIndexEntrySize + e.dataEncoder.Size() + len(e.valueDict)
+	if e.header.Flag.HasTagSection() {
+		size += tagSectionTrailerSize
+	}
+	if e.identifierMode == modeNameManaged && e.collisionTracker != nil {
+		size += 2
+		for _, name := range e.collisionTracker.GetMetricNames() {
+			size += 2 + len(name)
+		}
+	}
+
+	return size
+}
+
 func (e *TextEncoder) finishAppend(dst []byte) ([]byte, error) {
 	// Return buffers to pool even on error paths
 	defer func() {
@@ -428,14 +573,32 @@ func (e *TextEncoder) finishAppend(dst []byte) ([]byte, error) {
 	var compressedData []byte
 	var err error
 
+	// The value dictionary follows the rows of the last metric
+	if e.valueDictUsed {
+		header.SetValueDictionaryOffset(uint32(len(dataBytes))) //nolint:gosec
+		dataBytes = append(dataBytes[:len(dataBytes):len(dataBytes)], e.valueDict...)
+	}
+
 	// DataSize always stores the uncompressed size for verification and Size calculation
 	header.DataSize = uint32(len(dataBytes)) //nolint:gosec
 
+	// The tag section is split from the rows with their tags inline
+	var tagSection []byte
+	if header.Flag.HasTagSection() {
+		if dataBytes, tagSection, err = e.storeTagSection(header, dataBytes); err != nil {
+			return dst, err
+		}
+	}
+
 	if e.dataCodec != nil {
-		compressedData, err = e.dataCodec.Compress(dataBytes)
+		var compressed bool
+		compressedData, compressed, err = compressOrRaw(e.dataCodec, dataBytes)
 		if err != nil {
 			return dst, fmt.Errorf("failed to compress data: %w", err)
 		}
+		if !compressed {
+			header.Flag.SetDataCompression(format.CompressionNone)
+		}
 	} else {
 		compressedData = dataBytes
 	}
@@ -461,7 +624,7 @@ func (e *TextEncoder) finishAppend(dst []byte) ([]byte, error) {
 	// Pre-calculate exact blob size
 	headerSize := section.HeaderSize
 	indexEntriesSize := len(e.indexEntries) * section.TextIndexEntrySize
-	blobSize := headerSize + len(namesPayload) + indexEntriesSize + len(compressedData)
+	blobSize := headerSize + len(namesPayload) + indexEntriesSize + len(compressedData) + len(tagSection)
 
 	// Extend dst by exactly blobSize bytes (allocating only when capacity is
 	// insufficient) and assemble the blob in the appended region.
@@ -491,8 +654,9 @@ func (e *TextEncoder) finishAppend(dst []byte) ([]byte, error) {
 	}
 	offset += indexEntriesSize
 
-	// Write compressed data
-	copy(blob[offset:], compressedData)
+	// Write compressed data, followed by the tag section if any
+	offset += copy(blob[offset:], compressedData)
+	copy(blob[offset:], tagSection)
 
 	return full, nil
 }
@@ -2,6 +2,7 @@ package blob
 
 import (
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/arloliu/mebo/compress"
@@ -17,10 +18,20 @@ import (
 // concrete encoders to focus on their specific encoding logic while reusing
 // common configuration and state management.
 type TextEncoderConfig struct {
-	header       *section.TextHeader
-	indexEntries []section.TextIndexEntry
-	dataCodec    compress.Codec
-	engine       endian.EndianEngine
+	header        *section.TextHeader
+	indexEntries  []section.TextIndexEntry
+	dataCodec     compress.Codec
+	tagCodec      compress.Codec // codec of the separate tag section, nil if tags are inline
+	engine        endian.EndianEngine
+	deterministic bool // pin the data compressor to a fixed configuration
+
+	// Per-value compression, enabled by WithTextValueCompression
+	valueThreshold   int                    // minimum length of compressed values, 0 if disabled
+	valueCompression format.CompressionType // codec type of compressed values
+	valueCodec       compress.Codec         // codec of compressed values, nil if disabled
+	valueDict        []byte                 // Zstd dictionary of compressed values, nil if none (WithTextValueDictionary)
+
+	seekInterval int // rows between seek table checkpoints, 0 if disabled (WithTextSeekIndex)
 }
 
 // NewTextEncoderConfig creates a new TextEncoderConfig with the given start time.
@@ -62,6 +73,47 @@ func (c *TextEncoderConfig) setDataCompression(comp format.CompressionType) erro
 	}
 }
 
+// setTagCompression stores tags in a separate tag section compressed with comp.
+func (c *TextEncoderConfig) setTagCompression(comp format.CompressionType) error {
+	switch comp {
+	case format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4:
+		c.header.Flag.SetTagCompression(comp)
+		return nil
+	default:
+		return fmt.Errorf("invalid tag compression: %v", comp)
+	}
+}
+
+// setValueCompression enables per-value compression of values of at least threshold bytes.
+func (c *TextEncoderConfig) setValueCompression(threshold int, comp format.CompressionType) error {
+	if threshold < 1 || threshold > MaxTextValueLength {
+		return fmt.Errorf("invalid value compression threshold: %d, must be in [1, %d]", threshold, MaxTextValueLength)
+	}
+
+	switch comp { //nolint: exhaustive
+	case format.CompressionZstd, format.CompressionS2, format.CompressionLZ4:
+		c.valueThreshold = threshold
+		c.valueCompression = comp
+		c.header.Flag.SetValueCompression(true)
+
+		return nil
+	default:
+		return fmt.Errorf("invalid value compression: %v", comp)
+	}
+}
+
+// setSeekIndex enables per-metric seek tables with a checkpoint every interval rows.
+func (c *TextEncoderConfig) setSeekIndex(interval int) error {
+	if interval < 0 || interval > math.MaxUint16 {
+		return fmt.Errorf("invalid seek index interval: %d, must be in [0, %d]", interval, math.MaxUint16)
+	}
+
+	c.seekInterval = interval
+	c.header.Flag.SetSeekIndex(interval > 0)
+
+	return nil
+}
+
 // setEndianess sets the endianness option.
 func (c *TextEncoderConfig) setEndianess(endiness endianness) {
 	if endiness == bigEndianOpt {
@@ -107,11 +159,35 @@ func (c *TextEncoderConfig) setCodecs(header section.TextHeader) error {
 
 	// Initialize data codec
 	dataCompType := header.Flag.GetDataCompression()
-	c.dataCodec, err = compress.CreateCodec(dataCompType, "data")
+	c.dataCodec, err = newCodec(dataCompType, "data", c.deterministic)
 	if err != nil {
 		return fmt.Errorf("failed to create data codec: %w", err)
 	}
 
+	if header.Flag.HasTagSection() {
+		c.tagCodec, err = newCodec(header.Flag.GetTagCompression(), "tag", c.deterministic)
+		if err != nil {
+			return fmt.Errorf("failed to create tag codec: %w", err)
+		}
+	}
+
+	if c.valueDict != nil && (c.valueThreshold == 0 || c.valueCompression != format.CompressionZstd) {
+		return fmt.Errorf("value dictionary requires WithTextValueCompression with %s", format.CompressionZstd)
+	}
+
+	switch {
+	case c.valueDict != nil:
+		c.valueCodec, err = compress.NewZstdDictCompressor(c.valueDict)
+		if err != nil {
+			return fmt.Errorf("failed to create value codec: %w", err)
+		}
+	case c.valueThreshold > 0:
+		c.valueCodec, err = newCodec(c.valueCompression, "value", c.deterministic)
+		if err != nil {
+			return fmt.Errorf("failed to create value codec: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -169,6 +245,152 @@ func WithTextDataCompression(codec format.CompressionType) TextEncoderOption {
 	})
 }
 
+// WithTextTagCompression stores the tags of all data points in a separate tag
+// section compressed with codec, instead of inline in the rows of the data
+// section. Tags are often few distinct strings that compress better together
+// than interleaved with timestamps and values, and a cheaper codec can be
+// chosen for them than for the data section. format.CompressionNone stores the
+// tag section uncompressed.
+//
+// The choice is recorded in the header flags. Decoding restores the tags
+// inline, so reading a blob costs the same as without this option. A tag
+// section that does not shrink is stored uncompressed. Without
+// WithTextTagsEnabled(true) this option has no effect.
+//
+// Blobs encoded with this option cannot be read by decoders that predate it.
+// Event encoders reject this option.
+//
+// Parameters:
+//   - codec: format.CompressionNone, format.CompressionZstd,
+//     format.CompressionS2 or format.CompressionLZ4
+//
+// Returns:
+//   - TextEncoderOption: Option that fails for an invalid codec
+//
+// Example:
+//
+//	encoder, err := blob.NewTextEncoder(start,
+//	    blob.WithTextTagsEnabled(true),
+//	    blob.WithTextTagCompression(format.CompressionLZ4),
+//	)
+func WithTextTagCompression(codec format.CompressionType) TextEncoderOption {
+	return options.New(func(cfg *TextEncoderConfig) error {
+		return cfg.setTagCompression(codec)
+	})
+}
+
+// WithTextValueCompression compresses each value of at least threshold bytes
+// individually, so a few long values such as stack traces do not inflate the
+// data section while short values stay raw and cheap to read.
+//
+// Values that do not shrink are stored raw. With this option, values may be up
+// to MaxTextValueLength bytes instead of 255. The data section compression still
+// applies on top; consider WithTextDataCompression(format.CompressionNone) when
+// most of the data is in compressed values.
+//
+// Blobs encoded with this option set a dedicated header flag and cannot be read
+// by decoders that predate it. Event encoders reject this option.
+//
+// Parameters:
+//   - threshold: Minimum value length in bytes to compress (1 to MaxTextValueLength)
+//   - codec: format.CompressionZstd, format.CompressionS2 or format.CompressionLZ4
+//
+// Returns:
+//   - TextEncoderOption: Option that fails for an invalid threshold or codec
+//
+// Example:
+//
+//	encoder, err := blob.NewTextEncoder(start,
+//	    blob.WithTextValueCompression(128, format.CompressionZstd),
+//	    blob.WithTextDataCompression(format.CompressionNone),
+//	)
+func WithTextValueCompression(threshold int, codec format.CompressionType) TextEncoderOption {
+	return options.New(func(cfg *TextEncoderConfig) error {
+		return cfg.setValueCompression(threshold, codec)
+	})
+}
+
+// WithTextValueDictionary compresses the values selected by
+// WithTextValueCompression against a shared Zstd dictionary stored inside the
+// blob. Short values that have little to compress on their own, such as JSON
+// log lines or error messages with a common structure, shrink well when the
+// dictionary holds typical content. Readers need no external dictionary.
+//
+// The dictionary is raw content, e.g. a concatenation of representative values,
+// and is stored once per blob at the end of the data section, only if at least
+// one value was compressed. Requires WithTextValueCompression with
+// format.CompressionZstd, in any option order.
+//
+// Parameters:
+//   - dict: Dictionary content (1 to MaxTextValueDictionarySize bytes); retained
+//     by the encoder and must not be modified
+//
+// Returns:
+//   - TextEncoderOption: Option that fails for an empty or oversized dictionary
+//
+// Example:
+//
+//	encoder, err := blob.NewTextEncoder(start,
+//	    blob.WithTextValueCompression(32, format.CompressionZstd),
+//	    blob.WithTextValueDictionary([]byte(`{"level":"error","service":"checkout","msg":"`)),
+//	    blob.WithTextDataCompression(format.CompressionNone),
+//	)
+func WithTextValueDictionary(dict []byte) TextEncoderOption {
+	return options.New(func(cfg *TextEncoderConfig) error {
+		if len(dict) == 0 || len(dict) > MaxTextValueDictionarySize {
+			return fmt.Errorf("invalid value dictionary size: %d, must be in [1, %d]", len(dict), MaxTextValueDictionarySize)
+		}
+		cfg.valueDict = dict
+
+		return nil
+	})
+}
+
+// WithTextSeekIndex makes random access to long series cheap: every metric with
+// more than interval data points starts with a table of the byte offsets of
+// every interval-th row, so ValueAt, TimestampAt and TagAt decode at most
+// interval rows instead of every row before the requested index.
+//
+// The table costs 4 bytes per checkpoint, and each checkpoint row stores its
+// timestamp relative to the blob start time rather than to the previous row.
+// Metrics with at most interval data points are encoded as without this option.
+//
+// Blobs encoded with this option set a dedicated header flag and cannot be read
+// by decoders that predate it.
+//
+// Parameters:
+//   - interval: Number of rows between checkpoints (1 to 65535), or 0 to disable
+//
+// Returns:
+//   - TextEncoderOption: Option that fails for an out-of-range interval
+//
+// Example:
+//
+//	encoder, err := blob.NewTextEncoder(start, blob.WithTextSeekIndex(64))
+func WithTextSeekIndex(interval int) TextEncoderOption {
+	return options.New(func(cfg *TextEncoderConfig) error {
+		return cfg.setSeekIndex(interval)
+	})
+}
+
+// WithTextValueRLE stores a value equal to the previous value of the same
+// metric as a one-byte repeat marker instead of the value bytes, which shrinks
+// slowly changing series such as states or status strings before the data
+// section is compressed, and skips per-value compression of repeated values.
+//
+// Blobs encoded with this option set a dedicated header flag and cannot be read
+// by decoders that predate it.
+// Default is false.
+//
+// Example:
+//
+//	encoder, err := blob.NewTextEncoder(start, blob.WithTextValueRLE(true))
+func WithTextValueRLE(enabled bool) TextEncoderOption {
+	return options.NoError(func(cfg *TextEncoderConfig) {
+		cfg.header.Flag.SetValueRLE(enabled)
+	})
+}
+
 // WithTextTagsEnabled enables per-point tags when set to true.
 // Tags are stored as text strings with a maximum length of 255 UTF-8 bytes.
 // Default is false.
@@ -193,3 +415,13 @@ func WithTextBigEndian() TextEncoderOption {
 		c.setEndianess(bigEndianOpt)
 	})
 }
+
+// WithTextDeterministicOutput guarantees that identical input yields byte-identical blobs,
+// pinning the data compressor to a fixed configuration.
+// See WithDeterministicOutput for details.
+// Default is false.
+func WithTextDeterministicOutput(enabled bool) TextEncoderOption {
+	return options.NoError(func(c *TextEncoderConfig) {
+		c.deterministic = enabled
+	})
+}
@@ -1,15 +1,18 @@
 package blob
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/arloliu/mebo/compress"
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/hash"
+	"github.com/arloliu/mebo/section"
 )
 
 // ==============================================================================
@@ -403,6 +406,36 @@ func TestTextEncoder_FinishInto(t *testing.T) {
 	})
 }
 
+func TestTextEncoder_FinishTo(t *testing.T) {
+	blobTS := time.Unix(1700000000, 0).UTC()
+	encode := func() *TextEncoder {
+		encoder, err := NewTextEncoder(blobTS, WithTextTagsEnabled(true))
+		require.NoError(t, err)
+		for _, name := range []string{"status", "version"} {
+			require.NoError(t, encoder.StartMetricName(name, 3))
+			for i := range 3 {
+				require.NoError(t, encoder.AddDataPoint(blobTS.UnixMicro()+int64(i)*1000000, "value", "host=a"))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		return encoder
+	}
+
+	want, err := encode().Finish()
+	require.NoError(t, err)
+
+	encoder := encode()
+	bound := encoder.MaxFinishedSize()
+	require.GreaterOrEqual(t, bound, len(want))
+
+	dst := make([]byte, 0, bound)
+	got, err := encoder.FinishTo(dst)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Same(t, &dst[:1][0], &got[0])
+}
+
 func TestTextEncoder_Finish_Success_IDMode(t *testing.T) {
 	blobTS := time.Now()
 	encoder, err := NewTextEncoder(blobTS)
@@ -493,6 +526,72 @@ func TestTextEncoder_CompressionTypes(t *testing.T) {
 	}
 }
 
+func TestTextEncoder_SkipsIneffectiveCompression(t *testing.T) {
+	blobTS := time.Now()
+	encode := func(t *testing.T, count int) []byte {
+		t.Helper()
+		encoder, err := NewTextEncoder(blobTS, WithTextDataCompression(format.CompressionZstd))
+		require.NoError(t, err)
+		require.NoError(t, encoder.StartMetricID(12345, count))
+		for i := range count {
+			require.NoError(t, encoder.AddDataPoint(blobTS.Add(time.Duration(i)*time.Second).UnixMicro(), "status=ok", ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	for _, tt := range []struct {
+		count int
+		want  format.CompressionType
+	}{
+		{count: 1, want: format.CompressionNone},
+		{count: 500, want: format.CompressionZstd},
+	} {
+		data := encode(t, tt.count)
+
+		var header section.TextHeader
+		require.NoError(t, header.Parse(data[:section.HeaderSize]))
+		require.Equal(t, tt.want, header.Flag.GetDataCompression())
+
+		decoder, err := NewTextDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+		val, ok := blob.ValueAt(12345, tt.count-1)
+		require.True(t, ok)
+		require.Equal(t, "status=ok", val)
+	}
+}
+
+func TestTextEncoder_DeterministicOutput(t *testing.T) {
+	blobTS := time.Unix(1700000000, 0)
+	encode := func(t *testing.T, dst []byte) []byte {
+		t.Helper()
+		encoder, err := NewTextEncoder(blobTS, WithTextTagsEnabled(true), WithTextDeterministicOutput(true))
+		require.NoError(t, err)
+		require.Equal(t, compress.NewDeterministicZstdCompressor(), encoder.DataCodec())
+
+		for m := range 10 {
+			require.NoError(t, encoder.StartMetricName(fmt.Sprintf("log.%d", m), 5))
+			for i := range 5 {
+				require.NoError(t, encoder.AddDataPoint(blobTS.Add(time.Duration(i)*time.Second).UnixMicro(), fmt.Sprintf("event %d/%d", m, i), "svc"))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+		data, err := encoder.FinishInto(dst)
+		require.NoError(t, err)
+
+		return data
+	}
+
+	want := encode(t, nil)
+	got := encode(t, bytes.Repeat([]byte{0xFF}, 2*len(want))[:0])
+	require.Equal(t, want, got)
+}
+
 // ==============================================================================
 // Multiple Metrics Tests
 // ==============================================================================
@@ -933,3 +1032,37 @@ func TestTextEncoder_DeltaEncoding_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestTextEncoder_AbortMetric(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	encode := func(abort bool) []byte {
+		encoder, err := NewTextEncoder(start, WithTextTagsEnabled(true))
+		require.NoError(t, err)
+
+		require.NoError(t, encoder.StartMetricName("status", 2))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), "up", "a"))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+1, "down", "b"))
+		require.NoError(t, encoder.EndMetric())
+
+		if abort {
+			require.NoError(t, encoder.StartMetricName("bogus", 3))
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), "x", "y"))
+			require.NoError(t, encoder.AbortMetric())
+		}
+
+		require.NoError(t, encoder.StartMetricName("mode", 1))
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), "auto", ""))
+		require.NoError(t, encoder.EndMetric())
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	require.Equal(t, encode(false), encode(true))
+
+	encoder, err := NewTextEncoder(start)
+	require.NoError(t, err)
+	require.ErrorIs(t, encoder.AbortMetric(), errs.ErrNoMetricStarted)
+}
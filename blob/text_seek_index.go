@@ -0,0 +1,89 @@
+package blob
+
+import (
+	"github.com/arloliu/mebo/section"
+)
+
+// Seek index layout (TextFlag seek index bit set, see WithTextSeekIndex):
+//
+//	[OFFSET_1 uint32]...[OFFSET_K uint32][ROW_0][ROW_1]...[ROW_N-1]
+//
+// A metric of N rows with seek interval I (the Reserved1 field of its index
+// entry, 0 if the metric has no table) starts with K = (N-1)/I row offsets in
+// the blob byte order. OFFSET_k is the byte offset of row k*I, relative to the
+// first row. Checkpoint rows do not depend on previous rows: delta timestamps
+// are relative to the blob start time and values are never repeat markers, so
+// decoding can start at any checkpoint.
+//
+// Repeated value layout (TextFlag repeated value bit set, see WithTextValueRLE):
+//
+// LEN_V is a uvarint of the value length (with the per-value compression marker
+// if enabled) plus one, or 0 for a value equal to the previous value of the
+// metric, which is then not stored.
+
+// seekTableEntrySize is the size in bytes of a seek table offset.
+const seekTableEntrySize = 4
+
+// seekTableSize returns the size in bytes of the seek table of a metric of
+// count rows with a checkpoint every interval rows.
+func seekTableSize(count, interval int) int {
+	return (count - 1) / interval * seekTableEntrySize
+}
+
+// setSeekOffset records the offset of the checkpoint row about to be written as
+// the k-th (1-based) entry of the current metric's seek table.
+func (e *TextEncoder) setSeekOffset(k int) {
+	// The table was reserved by startMetric, before the metric's first row
+	pos := e.seekTable + (k-1)*seekTableEntrySize
+	e.engine.PutUint32(e.dataEncoder.Bytes()[pos:], uint32(e.dataEncoder.Size()-e.rowsStart)) //nolint:gosec // bounded by TextMaxOffset
+}
+
+// HasSeekIndex reports whether the blob was encoded with WithTextSeekIndex, so
+// random access to its long metrics starts from the nearest checkpoint row.
+func (b TextBlob) HasSeekIndex() bool {
+	return (b.flags & section.FlagSeekIndex) != 0
+}
+
+// HasValueRLE reports whether the blob was encoded with WithTextValueRLE, so
+// repeated values are stored as repeat markers.
+func (b TextBlob) HasValueRLE() bool {
+	return (b.flags & section.FlagValueRLE) != 0
+}
+
+// metricRows returns the rows of the metric of entry without its seek table,
+// the seek table, and the metric's seek interval, 0 if it has no seek table.
+// ok is false if the seek table exceeds the metric's data.
+func (b TextBlob) metricRows(entry section.TextIndexEntry) (rows, table []byte, interval int, ok bool) {
+	offset := int(entry.Offset)
+	size := int(entry.Size)
+	dataBytes := b.dataPayload[offset : offset+size]
+
+	if !b.HasSeekIndex() || entry.Reserved1 == 0 || entry.Count == 0 {
+		return dataBytes, nil, 0, true
+	}
+
+	interval = int(entry.Reserved1)
+	tableSize := seekTableSize(int(entry.Count), interval)
+	if tableSize > len(dataBytes) {
+		return nil, nil, 0, false
+	}
+
+	return dataBytes[tableSize:], dataBytes[:tableSize], interval, true
+}
+
+// seekRow returns the offset in rows of the last checkpoint row at or before
+// index, and the index of that row. Without a seek table, it is the first row.
+// ok is false if the offset exceeds rows.
+func (b TextBlob) seekRow(rows, table []byte, interval, index int) (offset, row int, ok bool) {
+	if interval == 0 || index < interval {
+		return 0, 0, true
+	}
+
+	k := index / interval
+	offset = int(b.Engine().Uint32(table[(k-1)*seekTableEntrySize:]))
+	if offset > len(rows) {
+		return 0, 0, false
+	}
+
+	return offset, k * interval, true
+}
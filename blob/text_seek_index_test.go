@@ -0,0 +1,236 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// seekTestSeries returns n data points with runs of repeated values, an
+// occasional long value and irregular timestamps.
+func seekTestSeries(start time.Time, n int) ([]int64, []string, []string) {
+	timestamps := make([]int64, n)
+	values := make([]string, n)
+	tags := make([]string, n)
+
+	ts := start.UnixMicro()
+	for i := range n {
+		ts += int64(1 + (i*7919)%3_000_000)
+		timestamps[i] = ts
+		switch {
+		case i%17 == 5:
+			values[i] = strings.Repeat(fmt.Sprintf("stack frame %d\n", i/17), 12)
+		case i%13 == 0:
+			values[i] = ""
+		default:
+			values[i] = fmt.Sprintf("state-%d", i/6)
+		}
+		tags[i] = fmt.Sprintf("host=%d", i%3)
+	}
+
+	return timestamps, values, tags
+}
+
+func TestTextSeekIndex(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	counts := []int{1, 16, 17, 33, 250}
+
+	tests := []struct {
+		name string
+		opts []TextEncoderOption
+	}{
+		{name: "delta", opts: []TextEncoderOption{WithTextSeekIndex(16)}},
+		{name: "raw", opts: []TextEncoderOption{WithTextSeekIndex(16), WithTextTimestampEncoding(format.TypeRaw)}},
+		{name: "every row", opts: []TextEncoderOption{WithTextSeekIndex(1)}},
+		{name: "rle", opts: []TextEncoderOption{WithTextValueRLE(true)}},
+		{name: "seek and rle", opts: []TextEncoderOption{WithTextSeekIndex(16), WithTextValueRLE(true), WithTextBigEndian()}},
+		{name: "seek rle and value compression", opts: []TextEncoderOption{
+			WithTextSeekIndex(7),
+			WithTextValueRLE(true),
+			WithTextValueCompression(64, format.CompressionZstd),
+			WithTextDataCompression(format.CompressionNone),
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, tagged := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s tags=%t", tt.name, tagged), func(t *testing.T) {
+				opts := append([]TextEncoderOption{WithTextTimestampEncoding(format.TypeDelta), WithTextTagsEnabled(tagged)}, tt.opts...)
+				encoder, err := NewTextEncoder(start, opts...)
+				require.NoError(t, err)
+
+				for _, n := range counts {
+					timestamps, values, tags := seekTestSeries(start, n)
+					require.NoError(t, encoder.StartMetricName(fmt.Sprintf("m%d", n), n))
+					for i := range n {
+						require.NoError(t, encoder.AddDataPoint(timestamps[i], values[i], tags[i]))
+					}
+					require.NoError(t, encoder.EndMetric())
+				}
+				data, err := encoder.Finish()
+				require.NoError(t, err)
+				require.NoError(t, QuickVerify(data))
+
+				decoder, err := NewTextDecoder(data)
+				require.NoError(t, err)
+				blob, err := decoder.Decode()
+				require.NoError(t, err)
+
+				for _, n := range counts {
+					name := fmt.Sprintf("m%d", n)
+					timestamps, values, tags := seekTestSeries(start, n)
+					if !tagged {
+						tags = make([]string, n)
+					}
+
+					require.Equal(t, timestamps, slices.Collect(blob.AllTimestampsByName(name)), name)
+					require.Equal(t, values, slices.Collect(blob.AllValuesByName(name)), name)
+					if tagged {
+						require.Equal(t, tags, slices.Collect(blob.AllTagsByName(name)), name)
+					}
+					for i, dp := range blob.AllByName(name) {
+						require.Equal(t, TextDataPoint{Ts: timestamps[i], Val: values[i], Tag: tags[i]}, dp, name)
+					}
+
+					material, ok := blob.MaterializeMetricByName(name)
+					require.True(t, ok)
+					require.Equal(t, values, material.Values, name)
+
+					// Random access in reverse order starts from the nearest checkpoint
+					for i := n - 1; i >= 0; i-- {
+						ts, ok := blob.TimestampAtByName(name, i)
+						require.True(t, ok)
+						require.Equal(t, timestamps[i], ts, "%s[%d]", name, i)
+						val, ok := blob.ValueAtByName(name, i)
+						require.True(t, ok)
+						require.Equal(t, values[i], val, "%s[%d]", name, i)
+						tag, ok := blob.TagAtByName(name, i)
+						require.True(t, ok)
+						require.Equal(t, tags[i], tag, "%s[%d]", name, i)
+					}
+					_, ok = blob.ValueAtByName(name, n)
+					require.False(t, ok)
+				}
+			})
+		}
+	}
+}
+
+func TestTextSeekIndex_Layout(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	timestamps, values, tags := seekTestSeries(start, 100)
+
+	encode := func(opts ...TextEncoderOption) TextBlob {
+		encoder, err := NewTextEncoder(start, append(opts, WithTextDataCompression(format.CompressionNone))...)
+		require.NoError(t, err)
+		for _, n := range []int{10, 100} {
+			require.NoError(t, encoder.StartMetricName(fmt.Sprintf("m%d", n), n))
+			for i := range n {
+				require.NoError(t, encoder.AddDataPoint(timestamps[i], values[i], tags[i]))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := NewTextDecoder(data)
+		require.NoError(t, err)
+		blob, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return blob
+	}
+
+	plain := encode()
+	require.False(t, plain.HasSeekIndex())
+	require.False(t, plain.HasValueRLE())
+
+	seek := encode(WithTextSeekIndex(10))
+	require.True(t, seek.HasSeekIndex())
+	short, _ := seek.index.GetByID(hash.ID("m10"))
+	long, _ := seek.index.GetByID(hash.ID("m100"))
+	require.Zero(t, short.Reserved1, "metrics within one interval have no seek table")
+	require.Equal(t, uint16(10), long.Reserved1)
+	require.Greater(t, len(seek.dataPayload), len(plain.dataPayload))
+
+	rle := encode(WithTextValueRLE(true))
+	require.True(t, rle.HasValueRLE())
+	require.Less(t, len(rle.dataPayload), len(plain.dataPayload), "repeated values are not stored")
+	require.Equal(t, values, slices.Collect(rle.AllValuesByName("m100")))
+}
+
+func TestTextSeekIndex_Options(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewTextEncoder(start, WithTextSeekIndex(-1))
+	require.Error(t, err)
+	_, err = NewTextEncoder(start, WithTextSeekIndex(math.MaxUint16+1))
+	require.Error(t, err)
+
+	// Disabling the seek index clears the header flag
+	encoder, err := NewTextEncoder(start, WithTextSeekIndex(8), WithTextSeekIndex(0))
+	require.NoError(t, err)
+	require.False(t, encoder.header.Flag.HasSeekIndex())
+
+	// An aborted metric drops its seek table
+	encoder, err = NewTextEncoder(start, WithTextSeekIndex(2), WithTextValueRLE(true))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("status", 5))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), "up", ""))
+	require.NoError(t, encoder.AbortMetric())
+	require.Zero(t, encoder.dataEncoder.Size())
+
+	require.NoError(t, encoder.StartMetricName("status", 5))
+	for i := range 5 {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), "up", ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, []string{"up", "up", "up", "up", "up"}, slices.Collect(blob.AllValuesByName("status")))
+	val, ok := blob.ValueAtByName("status", 3)
+	require.True(t, ok)
+	require.Equal(t, "up", val)
+}
+
+func TestTextSeekIndex_Event(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	texts := []string{"deploy", "deploy", "rollback", "deploy", "deploy", "deploy"}
+
+	encoder, err := NewEventEncoder(start, WithTextSeekIndex(2), WithTextValueRLE(true))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("deploys", len(texts)))
+	for i, text := range texts {
+		require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), text, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewEventDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	id := hash.ID("deploys")
+	for i, text := range texts {
+		got, ok := blob.TextAt(id, i)
+		require.True(t, ok)
+		require.Equal(t, text, got)
+	}
+	for i, dp := range blob.AllByName("deploys") {
+		require.Equal(t, texts[i], dp.Text)
+	}
+}
@@ -0,0 +1,212 @@
+package blob
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// tagSectionTrailerSize is the size of the trailer that ends a text blob with a
+// separate tag section, a uint32 holding the stored size of the tag section.
+//
+// Such a blob stores [data section][tag section][trailer] after its index. The
+// data section holds the rows without their tag bytes, and the tag section the
+// tag bytes of all rows in row order. Index entries, seek tables and the header
+// DataSize describe the rows with their tags inline, as decoders restore them.
+const tagSectionTrailerSize = 4
+
+// walkTextTags calls fn with the offset and length of the tag bytes of every
+// row with a non-empty tag, in row order.
+//
+// With inline true, data holds rows with their tag bytes. Otherwise data holds
+// rows without their tag bytes and offset is where they belong, while entry
+// offsets still refer to the rows with their tags inline.
+//
+// Parameters:
+//   - data: Uncompressed data section
+//   - entries: Index entries of the blob, in data order
+//   - inline: Whether data holds the tag bytes of its rows
+//   - fn: Function called for each non-empty tag
+//
+// Returns:
+//   - error: ErrInvalidIndexOffsets if a metric does not start within data, or
+//     ErrInvalidTagPayloadOffset if a row exceeds data
+func (b TextBlob) walkTextTags(data []byte, entries []section.TextIndexEntry, inline bool, fn func(offset, length int)) error {
+	shift := 0 // tag bytes of the previous rows missing from data
+	end := 0
+	for _, entry := range entries {
+		offset := int(entry.Offset) - shift
+		if offset < end || offset > len(data) {
+			return fmt.Errorf("%w: metric ID 0x%016x offset %d outside data", errs.ErrInvalidIndexOffsets, entry.MetricID, entry.Offset)
+		}
+
+		if b.HasSeekIndex() && entry.Reserved1 != 0 && entry.Count != 0 {
+			offset += seekTableSize(int(entry.Count), int(entry.Reserved1))
+		}
+
+		var lastTs int64
+		for row := 0; row < int(entry.Count); row++ {
+			if offset > len(data) {
+				return fmt.Errorf("%w: metric ID 0x%016x row %d exceeds data", errs.ErrInvalidTagPayloadOffset, entry.MetricID, row)
+			}
+
+			_, n, err := b.decodeTimestampAt(data, offset, &lastTs)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return fmt.Errorf("%w: metric ID 0x%016x row %d", errs.ErrInvalidTimestampData, entry.MetricID, row)
+			}
+			offset += n
+
+			lenV, lenT, _, repeat, n, ok := b.readLengths(data, offset)
+			if !ok {
+				return fmt.Errorf("%w: metric ID 0x%016x row %d lengths exceed data", errs.ErrInvalidTagPayloadOffset, entry.MetricID, row)
+			}
+			offset += n
+			if !repeat {
+				offset += lenV
+			}
+			if offset > len(data) || (inline && offset+lenT > len(data)) {
+				return fmt.Errorf("%w: metric ID 0x%016x row %d exceeds data", errs.ErrInvalidTagPayloadOffset, entry.MetricID, row)
+			}
+
+			if lenT > 0 {
+				fn(offset, lenT)
+			}
+			if inline {
+				offset += lenT
+			} else {
+				shift += lenT
+			}
+		}
+		end = offset
+	}
+
+	return nil
+}
+
+// splitTextTags splits the tag bytes of the rows of data into a separate tag
+// section, returning the rows without their tags and the tag section.
+func (b TextBlob) splitTextTags(data []byte, entries []section.TextIndexEntry) (rows, tags []byte, err error) {
+	rows = make([]byte, 0, len(data))
+	last := 0
+	err = b.walkTextTags(data, entries, true, func(offset, length int) {
+		rows = append(rows, data[last:offset]...)
+		tags = append(tags, data[offset:offset+length]...)
+		last = offset + length
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(rows, data[last:]...), tags, nil
+}
+
+// joinTextTags restores the tag bytes of the tag section into the rows of data,
+// the inverse of splitTextTags.
+func (b TextBlob) joinTextTags(data, tags []byte, entries []section.TextIndexEntry, size int) ([]byte, error) {
+	joined := make([]byte, 0, size)
+	last, next := 0, 0
+	overflow := false
+	err := b.walkTextTags(data, entries, false, func(offset, length int) {
+		if overflow || next+length > len(tags) {
+			overflow = true
+			return
+		}
+		joined = append(joined, data[last:offset]...)
+		joined = append(joined, tags[next:next+length]...)
+		last, next = offset, next+length
+	})
+	if err != nil {
+		return nil, err
+	}
+	if overflow || next != len(tags) {
+		return nil, fmt.Errorf("%w: tag section holds %d bytes, rows reference %d", errs.ErrInvalidTagPayloadOffset, len(tags), next)
+	}
+
+	return append(joined, data[last:]...), nil
+}
+
+// storeTagSection replaces the data of a blob with tags inline by its data
+// section, tag section and trailer, compressing the tag section with codec.
+// A tag section that does not shrink is stored raw with CompressionNone.
+func (e *TextEncoder) storeTagSection(header *section.TextHeader, data []byte) (rows, tags []byte, err error) {
+	view := TextBlob{blobBase: newTextBlobBase(header)}
+	rows, tags, err = view.splitTextTags(data, e.indexEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored, compressed, err := compressOrRaw(e.tagCodec, tags)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compress tags: %w", err)
+	}
+	if !compressed {
+		header.Flag.SetTagCompression(format.CompressionNone)
+	}
+
+	trailer := make([]byte, tagSectionTrailerSize)
+	e.engine.PutUint32(trailer, uint32(len(stored))) //nolint:gosec // bounded by the data size
+
+	return rows, append(stored, trailer...), nil
+}
+
+// splitTagSection splits the stored data of a blob with a separate tag section
+// into its stored data section and stored tag section.
+func splitTagSection(stored []byte, engine endian.EndianEngine) (rows, tags []byte, err error) {
+	if len(stored) < tagSectionTrailerSize {
+		return nil, nil, fmt.Errorf("%w: missing tag section trailer", errs.ErrInvalidTagPayloadOffset)
+	}
+
+	end := len(stored) - tagSectionTrailerSize
+	tagsSize := int(engine.Uint32(stored[end:]))
+	if tagsSize > end {
+		return nil, nil, fmt.Errorf("%w: tag section size %d exceeds data length %d", errs.ErrInvalidTagPayloadOffset, tagsSize, end)
+	}
+
+	return stored[:end-tagsSize], stored[end-tagsSize : end], nil
+}
+
+// decompressTagSection splits the stored data of a blob with a separate tag
+// section, decompresses its data and tag sections, and restores the tags
+// inline.
+func (d *TextDecoder) decompressTagSection(blob TextBlob, dataOffset int, entries []section.TextIndexEntry) ([]byte, error) {
+	storedRows, storedTags, err := splitTagSection(d.data[dataOffset:], d.engine)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.decompressSection(SectionTextData, d.header.Flag.GetDataCompression(), storedRows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	tags, err := d.decompressSection(SectionTextTags, d.header.Flag.GetTagCompression(), storedTags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress tags: %w", err)
+	}
+
+	data, err := blob.joinTextTags(rows, tags, entries, int(d.header.DataSize))
+	if err != nil {
+		return nil, err
+	}
+	if uint32(len(data)) != d.header.DataSize { //nolint:gosec
+		return nil, fmt.Errorf("%w: expected %d, got %d", errs.ErrDataSizeMismatch, d.header.DataSize, len(data))
+	}
+
+	return data, nil
+}
+
+// decompressSection decompresses a stored section of a text blob with comp.
+func (d *TextDecoder) decompressSection(kind DecodeSection, comp format.CompressionType, stored []byte) ([]byte, error) {
+	codec, err := compress.CreateCodec(comp, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decompression codec: %w", err)
+	}
+
+	return decompressSection(d.hooks, kind, comp, stored, codec.Decompress)
+}
@@ -0,0 +1,124 @@
+package blob
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// encodeTagSectionTestBlob encodes metrics of the given sizes with the
+// seekTestSeries data points.
+func encodeTagSectionTestBlob(t *testing.T, start time.Time, counts []int, opts ...TextEncoderOption) []byte {
+	t.Helper()
+
+	encoder, err := NewTextEncoder(start, append([]TextEncoderOption{WithTextTagsEnabled(true)}, opts...)...)
+	require.NoError(t, err)
+	for _, n := range counts {
+		timestamps, values, tags := seekTestSeries(start, n)
+		tags[0] = "" // rows without tags have no tag bytes
+		require.NoError(t, encoder.StartMetricName(fmt.Sprintf("m%d", n), n))
+		for i := range n {
+			require.NoError(t, encoder.AddDataPoint(timestamps[i], values[i], tags[i]))
+		}
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestTextTagCompression(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	counts := []int{1, 16, 17, 250}
+
+	tests := []struct {
+		name  string
+		codec format.CompressionType
+		opts  []TextEncoderOption
+	}{
+		{name: "zstd data lz4 tags", codec: format.CompressionLZ4},
+		{name: "uncompressed tags", codec: format.CompressionNone},
+		{name: "raw timestamps", codec: format.CompressionZstd, opts: []TextEncoderOption{
+			WithTextTimestampEncoding(format.TypeRaw),
+			WithTextDataCompression(format.CompressionS2),
+			WithTextBigEndian(),
+		}},
+		{name: "seek rle and value compression", codec: format.CompressionS2, opts: []TextEncoderOption{
+			WithTextSeekIndex(7),
+			WithTextValueRLE(true),
+			WithTextValueCompression(64, format.CompressionZstd),
+			WithTextValueDictionary([]byte("stack frame state-")),
+			WithTextDataCompression(format.CompressionNone),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inline := encodeTagSectionTestBlob(t, start, counts, tt.opts...)
+			data := encodeTagSectionTestBlob(t, start, counts, append(tt.opts, WithTextTagCompression(tt.codec))...)
+			require.NoError(t, QuickVerify(data))
+
+			decoder, err := NewTextDecoder(data)
+			require.NoError(t, err)
+			require.True(t, decoder.header.Flag.HasTagSection())
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+
+			want, err := NewTextDecoder(inline)
+			require.NoError(t, err)
+			require.False(t, want.header.Flag.HasTagSection())
+			wantBlob, err := want.Decode()
+			require.NoError(t, err)
+			require.Equal(t, wantBlob.dataPayload, blob.dataPayload)
+
+			for _, n := range counts {
+				name := fmt.Sprintf("m%d", n)
+				timestamps, values, tags := seekTestSeries(start, n)
+				tags[0] = ""
+
+				require.Equal(t, timestamps, slices.Collect(blob.AllTimestampsByName(name)), name)
+				require.Equal(t, values, slices.Collect(blob.AllValuesByName(name)), name)
+				require.Equal(t, tags, slices.Collect(blob.AllTagsByName(name)), name)
+				tag, ok := blob.TagAtByName(name, n-1)
+				require.True(t, ok)
+				require.Equal(t, tags[n-1], tag)
+			}
+
+			// A corrupted trailer or truncated blob is rejected
+			for _, n := range []int{len(data) - 1, len(data) - tagSectionTrailerSize} {
+				decoder, err := NewTextDecoder(data[:n])
+				require.NoError(t, err)
+				_, err = decoder.Decode()
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTextTagCompression_Options(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewTextEncoder(start, WithTextTagCompression(format.CompressionType(0x7)))
+	require.Error(t, err)
+
+	// Without tags, there is no tag section
+	encoder, err := NewTextEncoder(start, WithTextTagCompression(format.CompressionLZ4))
+	require.NoError(t, err)
+	require.False(t, encoder.TextHeader().Flag.HasTagSection())
+
+	encoder, err = NewTextEncoder(start, WithTextTagCompression(format.CompressionLZ4), WithTextTagsEnabled(true))
+	require.NoError(t, err)
+	require.True(t, encoder.TextHeader().Flag.HasTagSection())
+	require.Equal(t, format.CompressionLZ4, encoder.TextHeader().Flag.GetTagCompression())
+	require.Equal(t, format.CompressionZstd, encoder.TextHeader().Flag.GetDataCompression())
+
+	_, err = NewEventEncoder(start, WithTextTagsEnabled(true), WithTextTagCompression(format.CompressionLZ4))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+}
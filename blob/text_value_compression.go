@@ -0,0 +1,102 @@
+package blob
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/arloliu/mebo/compress"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/section"
+)
+
+// MaxTextValueLength is the maximum length in bytes of a text value in blobs
+// encoded with WithTextValueCompression. Other text values and all tags are
+// limited to 255 bytes.
+const MaxTextValueLength = math.MaxUint16
+
+// MaxTextValueDictionarySize is the maximum size in bytes of a value
+// dictionary set by WithTextValueDictionary.
+const MaxTextValueDictionarySize = 64 << 10
+
+// Per-value compression row layout (TextFlag bit 3 set):
+//
+//	[TIMESTAMP][LEN_V uvarint][LEN_T uint8, if tags][VAL][TAG]
+//
+// LEN_V is the stored value length shifted left by one, with the lowest bit set
+// for a compressed value. A compressed value is stored as one byte holding its
+// format.CompressionType, followed by the compressed bytes.
+//
+// With WithTextValueDictionary, Zstd values are compressed against a raw
+// dictionary appended to the data section after the last row; the header's
+// reserved word holds its offset (see section.TextHeader.ValueDictionaryOffset).
+
+// encodeValue returns the bytes to store for value and whether they are compressed.
+// Values below the threshold, and values that do not shrink, are stored raw.
+func (e *TextEncoder) encodeValue(value string) ([]byte, bool, error) {
+	if e.valueCodec == nil || len(value) < e.valueThreshold {
+		return []byte(value), false, nil
+	}
+
+	compressed, err := e.valueCodec.Compress([]byte(value))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if len(compressed)+1 >= len(value) {
+		return []byte(value), false, nil
+	}
+
+	if e.valueDict != nil {
+		e.valueDictUsed = true
+	}
+
+	stored := make([]byte, 0, len(compressed)+1)
+	stored = append(stored, byte(e.valueCompression))
+	stored = append(stored, compressed...)
+
+	return stored, true, nil
+}
+
+// decompressTextValue decompresses a value stored by per-value compression.
+// dict decompresses Zstd values of blobs with a value dictionary, nil if none.
+func decompressTextValue(stored []byte, dict compress.Decompressor) ([]byte, error) {
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("%w: empty compressed value", errs.ErrInvalidCompressedFrame)
+	}
+
+	comp := format.CompressionType(stored[0])
+	if comp == format.CompressionNone {
+		return nil, fmt.Errorf("%w: compressed value marked as uncompressed", errs.ErrUnsupportedCompression)
+	}
+
+	var codec compress.Decompressor = dict
+	if comp != format.CompressionZstd || dict == nil {
+		var err error
+		if codec, err = compress.GetCodec(comp); err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := codec.Decompress(stored[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(value) > MaxTextValueLength {
+		return nil, fmt.Errorf("%w: decompressed value length %d exceeds maximum %d",
+			errs.ErrInvalidCompressedFrame, len(value), MaxTextValueLength)
+	}
+
+	return value, nil
+}
+
+// HasValueCompression reports whether the blob was encoded with
+// WithTextValueCompression, so its long values are compressed individually.
+func (b TextBlob) HasValueCompression() bool {
+	return (b.flags & section.FlagValueCompression) != 0
+}
+
+// HasValueDictionary reports whether the blob stores a value compression
+// dictionary (see WithTextValueDictionary).
+func (b TextBlob) HasValueDictionary() bool {
+	return b.valueDict != nil
+}
@@ -0,0 +1,194 @@
+package blob
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestTextValueCompression(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	trace := strings.Repeat("panic: runtime error\n\tat github.com/arloliu/mebo/blob.decode(text_blob.go:42)\n", 20)
+	values := []string{"ok", trace, "warn", trace[:300], strings.Repeat("x", 64)}
+	tags := []string{"a", "b", "", "d", "e"}
+
+	tests := []struct {
+		name  string
+		codec format.CompressionType
+		tsEnc format.EncodingType
+	}{
+		{name: "zstd", codec: format.CompressionZstd, tsEnc: format.TypeDelta},
+		{name: "s2", codec: format.CompressionS2, tsEnc: format.TypeRaw},
+		{name: "lz4", codec: format.CompressionLZ4, tsEnc: format.TypeDelta},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder, err := NewTextEncoder(start,
+				WithTextTagsEnabled(true),
+				WithTextTimestampEncoding(tt.tsEnc),
+				WithTextDataCompression(format.CompressionNone),
+				WithTextValueCompression(128, tt.codec),
+			)
+			require.NoError(t, err)
+			require.NoError(t, encoder.StartMetricName("log", len(values)))
+			for i, v := range values {
+				require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), v, tags[i]))
+			}
+			require.NoError(t, encoder.EndMetric())
+
+			data, err := encoder.Finish()
+			require.NoError(t, err)
+			require.Less(t, len(data), len(trace), "long values are stored compressed")
+
+			decoder, err := NewTextDecoder(data)
+			require.NoError(t, err)
+			blob, err := decoder.Decode()
+			require.NoError(t, err)
+			require.True(t, blob.HasValueCompression())
+
+			require.Equal(t, values, slices.Collect(blob.AllValuesByName("log")))
+			require.Equal(t, tags, slices.Collect(blob.AllTagsByName("log")))
+			require.Len(t, slices.Collect(blob.AllTimestampsByName("log")), len(values))
+			for i, dp := range blob.AllByName("log") {
+				require.Equal(t, values[i], dp.Val)
+				require.Equal(t, tags[i], dp.Tag)
+				require.Equal(t, start.UnixMicro()+int64(i), dp.Ts)
+			}
+
+			val, ok := blob.ValueAtByName("log", 3)
+			require.True(t, ok)
+			require.Equal(t, values[3], val)
+			tag, ok := blob.TagAtByName("log", 4)
+			require.True(t, ok)
+			require.Equal(t, "e", tag)
+			ts, ok := blob.TimestampAtByName("log", 4)
+			require.True(t, ok)
+			require.Equal(t, start.UnixMicro()+4, ts)
+
+			require.NoError(t, QuickVerify(data))
+		})
+	}
+}
+
+func TestTextValueCompression_Limits(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewTextEncoder(start, WithTextValueCompression(0, format.CompressionZstd))
+	require.Error(t, err)
+	_, err = NewTextEncoder(start, WithTextValueCompression(64, format.CompressionNone))
+	require.Error(t, err)
+	_, err = NewEventEncoder(start, WithTextValueCompression(64, format.CompressionZstd))
+	require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+
+	// Without the option values are limited to 255 bytes.
+	encoder, err := NewTextEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.Error(t, encoder.AddDataPoint(start.UnixMicro(), strings.Repeat("x", 256), ""))
+
+	encoder, err = NewTextEncoder(start, WithTextValueCompression(64, format.CompressionS2))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, 1))
+	require.Error(t, encoder.AddDataPoint(start.UnixMicro(), strings.Repeat("x", MaxTextValueLength+1), ""))
+
+	// A rejected value leaves no partial row behind.
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), strings.Repeat("x", MaxTextValueLength), ""))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	val, ok := blob.ValueAt(1, 0)
+	require.True(t, ok)
+	require.Len(t, val, MaxTextValueLength)
+}
+
+func TestTextValueDictionary(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	dict := []byte(`{"level":"error","service":"checkout","region":"us-east-1","msg":"upstream request timeout"}`)
+	encode := func(t *testing.T, values []string, opts ...TextEncoderOption) []byte {
+		t.Helper()
+
+		opts = append([]TextEncoderOption{
+			WithTextTagsEnabled(true),
+			WithTextDataCompression(format.CompressionNone),
+			WithTextValueCompression(32, format.CompressionZstd),
+		}, opts...)
+		encoder, err := NewTextEncoder(start, opts...)
+		require.NoError(t, err)
+		for _, name := range []string{"api", "worker"} {
+			require.NoError(t, encoder.StartMetricName(name, len(values)))
+			for i, v := range values {
+				require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), v, name))
+			}
+			require.NoError(t, encoder.EndMetric())
+		}
+
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	values := []string{
+		"ok",
+		`{"level":"error","service":"checkout","region":"us-east-1","msg":"upstream request timeout"}`,
+		`{"level":"error","service":"checkout","region":"us-east-1","msg":"connection reset"}`,
+		"short",
+	}
+
+	plain := encode(t, values)
+	data := encode(t, values, WithTextValueDictionary(dict))
+	require.Less(t, len(data)-len(dict), len(plain), "values compress better against the dictionary")
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	blob, err := decoder.Decode()
+	require.NoError(t, err)
+	require.True(t, blob.HasValueDictionary())
+
+	for _, name := range []string{"api", "worker"} {
+		require.Equal(t, values, slices.Collect(blob.AllValuesByName(name)))
+		require.Equal(t, slices.Repeat([]string{name}, len(values)), slices.Collect(blob.AllTagsByName(name)))
+		val, ok := blob.ValueAtByName(name, 2)
+		require.True(t, ok)
+		require.Equal(t, values[2], val)
+	}
+	require.NoError(t, QuickVerify(data))
+
+	// Without compressed values, the dictionary is not stored.
+	short := encode(t, []string{"ok", "short"}, WithTextValueDictionary(dict))
+	require.Equal(t, encode(t, []string{"ok", "short"}), short)
+	decoder, err = NewTextDecoder(short)
+	require.NoError(t, err)
+	blob, err = decoder.Decode()
+	require.NoError(t, err)
+	require.False(t, blob.HasValueDictionary())
+}
+
+func TestTextValueDictionary_Options(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	_, err := NewTextEncoder(start, WithTextValueDictionary(nil))
+	require.Error(t, err)
+	_, err = NewTextEncoder(start, WithTextValueDictionary(make([]byte, MaxTextValueDictionarySize+1)))
+	require.Error(t, err)
+
+	// The dictionary requires Zstd per-value compression.
+	_, err = NewTextEncoder(start, WithTextValueDictionary([]byte("dict")))
+	require.Error(t, err)
+	_, err = NewTextEncoder(start, WithTextValueDictionary([]byte("dict")), WithTextValueCompression(16, format.CompressionS2))
+	require.Error(t, err)
+	_, err = NewTextEncoder(start, WithTextValueDictionary([]byte("dict")), WithTextValueCompression(16, format.CompressionZstd))
+	require.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// ValidateOptions checks numeric encoder options without creating an encoder,
+// so services can validate options built from a configuration file at startup.
+//
+// The options are applied in order to a scratch configuration, as
+// NewNumericEncoder would, but an invalid option does not stop the check: every
+// invalid option (e.g., Gorilla timestamp encoding), every conflicting
+// combination (e.g., WithValueAlignment without raw value encoding) and every
+// compression unavailable in this build is reported. NewNumericEncoder succeeds
+// with options that pass, except for resources acquired at creation (e.g., the
+// spill directory of WithMaxEncoderMemory).
+//
+// Parameters:
+//   - opts: Numeric encoder options to check
+//
+// Returns:
+//   - error: nil if the options are valid, otherwise all problems found, joined
+//     with errors.Join
+//
+// Example:
+//
+//	opts := optionsFromConfig(cfg)
+//	if err := blob.ValidateOptions(opts...); err != nil {
+//	    log.Fatalf("invalid mebo encoder configuration: %v", err)
+//	}
+func ValidateOptions(opts ...NumericEncoderOption) error {
+	config := NewNumericEncoderConfig(time.Unix(0, 0))
+
+	var problems []error
+	for _, opt := range opts {
+		if err := options.Apply(config, opt); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if err := config.validate(); err != nil {
+		problems = append(problems, err)
+	}
+
+	if err := config.setCodecs(*config.header); err != nil {
+		problems = append(problems, err)
+	}
+
+	return errors.Join(problems...)
+}
+
+// ValidateTextOptions checks text encoder options without creating an encoder,
+// the text counterpart of ValidateOptions.
+//
+// Parameters:
+//   - opts: Text encoder options to check
+//
+// Returns:
+//   - error: nil if the options are valid, otherwise all problems found, joined
+//     with errors.Join
+//
+// Example:
+//
+//	if err := blob.ValidateTextOptions(opts...); err != nil {
+//	    log.Fatalf("invalid mebo text encoder configuration: %v", err)
+//	}
+func ValidateTextOptions(opts ...TextEncoderOption) error {
+	config := NewTextEncoderConfig(time.Unix(0, 0))
+
+	var problems []error
+	for _, opt := range opts {
+		if err := options.Apply(config, opt); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	if err := config.setCodecs(*config.header); err != nil {
+		problems = append(problems, err)
+	}
+
+	return errors.Join(problems...)
+}
+
+// validate reports the option combinations an encoder cannot honor, joined with
+// errors.Join, or nil if there are none.
+func (c *NumericEncoderConfig) validate() error {
+	var problems []error
+
+	if c.offsetUnit > 1 && c.sharedTimestamps {
+		problems = append(problems, fmt.Errorf("%w: aligned offsets cannot be combined with shared timestamps", errs.ErrUnsupportedBlobFeature))
+	}
+
+	if c.valueAlignment > 0 && c.header.Flag.ValueEncoding() != format.TypeRaw {
+		problems = append(problems, fmt.Errorf("%w: value alignment requires raw value encoding", errs.ErrUnsupportedBlobFeature))
+	}
+
+	if c.extEncoder != nil && c.maxMemory > 0 {
+		problems = append(problems, fmt.Errorf("%w: extension value encoders cannot be combined with spilling", errs.ErrUnsupportedBlobFeature))
+	}
+
+	if c.edgePoints > 0 {
+		problems = append(problems, c.validateEdgeProfile()...)
+	}
+
+	if c.window && c.tsUnit.Timestamp(c.windowEnd) <= c.tsUnit.Timestamp(c.windowStart) {
+		problems = append(problems, fmt.Errorf("%w: time window %s to %s is empty in unit %s",
+			errs.ErrInvalidTimeRange, c.windowStart, c.windowEnd, c.tsUnit))
+	}
+
+	return errors.Join(problems...)
+}
+
+// validateEdgeProfile reports the options WithEdgeProfile cannot combine with,
+// as they allocate per data point or use maps.
+func (c *NumericEncoderConfig) validateEdgeProfile() []error {
+	var conflicts []string
+	flag := c.header.Flag
+	if flag.TimestampEncoding() != format.TypeRaw || flag.ValueEncoding() != format.TypeRaw || c.extEncoder != nil {
+		conflicts = append(conflicts, "non-raw encodings")
+	}
+	if flag.TimestampCompression() != format.CompressionNone || flag.ValueCompression() != format.CompressionNone {
+		conflicts = append(conflicts, "compression")
+	}
+	if flag.HasTag() {
+		conflicts = append(conflicts, "tags")
+	}
+	if c.sharedTimestamps {
+		conflicts = append(conflicts, "shared timestamps")
+	}
+	if c.sketches {
+		conflicts = append(conflicts, "sketches")
+	}
+	if c.tagSummaries {
+		conflicts = append(conflicts, "tag summaries")
+	}
+	if c.metricRefs {
+		conflicts = append(conflicts, "metric references")
+	}
+	if c.maxMemory > 0 {
+		conflicts = append(conflicts, "spilling")
+	}
+
+	problems := make([]error, len(conflicts))
+	for i, conflict := range conflicts {
+		problems[i] = fmt.Errorf("%w: edge profile cannot be combined with %s", errs.ErrUnsupportedBlobFeature, conflict)
+	}
+
+	return problems
+}
@@ -0,0 +1,85 @@
+package blob
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestValidateOptions(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, ValidateOptions())
+		require.NoError(t, ValidateOptions(
+			WithTimestampEncoding(format.TypeDeltaPacked),
+			WithValueEncoding(format.TypeRaw),
+			WithValueAlignment(64),
+			WithValueCompression(format.CompressionZstd),
+			WithTagsEnabled(true),
+		))
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		err := ValidateOptions(WithTimestampEncoding(format.TypeGorilla))
+		require.ErrorContains(t, err, "not supported for timestamps")
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		err := ValidateOptions(
+			WithValueEncoding(format.TypeGorilla),
+			WithValueAlignment(32),
+			WithSharedTimestamps(),
+		)
+		require.ErrorIs(t, err, errs.ErrUnsupportedBlobFeature)
+		require.ErrorContains(t, err, "value alignment requires raw value encoding")
+		require.ErrorContains(t, err, "cannot be combined with shared timestamps")
+	})
+
+	t.Run("empty time window in unit", func(t *testing.T) {
+		start := time.Unix(1700000000, 0)
+		err := ValidateOptions(
+			WithTimestampUnit(format.TimeUnitSecond),
+			WithTimeWindow(start, start.Add(time.Millisecond), TimeWindowReject),
+		)
+		require.ErrorIs(t, err, errs.ErrInvalidTimeRange)
+	})
+
+	t.Run("reports every problem", func(t *testing.T) {
+		err := ValidateOptions(
+			WithTimestampEncoding(format.TypeChimp),
+			WithValueCompression(format.CompressionType(0xff)),
+			WithValueEncoding(format.TypeChimp),
+			WithValueAlignment(64),
+		)
+		require.Error(t, err)
+		require.Len(t, strings.Split(err.Error(), "\n"), 3)
+	})
+
+	t.Run("matches encoder construction", func(t *testing.T) {
+		for _, opts := range [][]NumericEncoderOption{
+			{WithValueEncoding(format.TypeALP), WithSharedTimestamps()},
+			{WithValueEncoding(format.TypeChimp), WithValueAlignment(32)},
+			{WithWordAlignedOffsets(), WithSharedTimestamps()},
+		} {
+			_, encErr := NewNumericEncoder(time.Unix(0, 0), opts...)
+			err := ValidateOptions(opts...)
+			require.Equal(t, encErr == nil, err == nil)
+		}
+	})
+}
+
+func TestValidateTextOptions(t *testing.T) {
+	require.NoError(t, ValidateTextOptions(WithTextTagsEnabled(true), WithTextDataCompression(format.CompressionS2)))
+
+	err := ValidateTextOptions(
+		WithTextTimestampEncoding(format.TypeGorilla),
+		WithTextValueDictionary([]byte("dictionary")),
+	)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "value dictionary requires WithTextValueCompression")
+	require.Len(t, strings.Split(err.Error(), "\n"), 2)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime/cgo"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+// errInvalidInput reports malformed arguments passed across the C boundary.
+var errInvalidInput = errors.New("invalid input")
+
+// numericHandle is the value stored behind a C blob handle.
+type numericHandle struct {
+	blob blob.NumericBlob
+	data []byte // Go-owned copy of the encoded blob; the decoded blob references it
+}
+
+// decodeNumeric decodes a Go-owned copy of a numeric blob and returns a handle to it.
+// The handle must be released with releaseHandle.
+func decodeNumeric(data []byte) (cgo.Handle, error) {
+	decoder, err := blob.NewNumericDecoder(data)
+	if err != nil {
+		return 0, err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return 0, err
+	}
+
+	return cgo.NewHandle(&numericHandle{blob: b, data: data}), nil
+}
+
+// lookupNumeric returns the blob behind a handle. A zero handle yields false;
+// any other handle must have been returned by decodeNumeric and not yet released.
+func lookupNumeric(h uintptr) (*numericHandle, bool) {
+	if h == 0 {
+		return nil, false
+	}
+
+	nh, ok := cgo.Handle(h).Value().(*numericHandle)
+
+	return nh, ok
+}
+
+// releaseHandle releases a handle returned by decodeNumeric. A zero handle is ignored.
+func releaseHandle(h uintptr) {
+	if h != 0 {
+		cgo.Handle(h).Delete()
+	}
+}
+
+// readNumeric copies up to len(ts) data points of a metric into ts and values.
+// Returns the number of points copied, or false if the metric doesn't exist.
+func readNumeric(b blob.NumericBlob, metricID uint64, ts []int64, values []float64) (int, bool) {
+	if !b.HasMetricID(metricID) {
+		return 0, false
+	}
+
+	n := 0
+	for _, dp := range b.All(metricID) {
+		if n >= len(ts) {
+			break
+		}
+		ts[n], values[n] = dp.Ts, dp.Val
+		n++
+	}
+
+	return n, true
+}
+
+// encodeNumeric encodes metrics laid out as flat columns: metric i owns counts[i]
+// consecutive entries of ts and values, starting after all previous metrics.
+func encodeNumeric(startMicros int64, ids []uint64, counts []int64, ts []int64, values []float64) ([]byte, error) {
+	total := int64(0)
+	for i, count := range counts {
+		if count <= 0 || count > math.MaxInt32 {
+			return nil, fmt.Errorf("%w: metric %d has %d points", errInvalidInput, ids[i], count)
+		}
+		total += count
+	}
+	if total != int64(len(ts)) || total != int64(len(values)) {
+		return nil, fmt.Errorf("%w: counts sum to %d, got %d timestamps and %d values", errInvalidInput, total, len(ts), len(values))
+	}
+
+	encoder, err := blob.NewNumericEncoder(time.UnixMicro(startMicros))
+	if err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	for i, id := range ids {
+		count := int(counts[i])
+		if err = encoder.StartMetricID(id, count); err != nil {
+			return nil, err
+		}
+		if err = encoder.AddDataPoints(ts[offset:offset+count], values[offset:offset+count], nil); err != nil {
+			return nil, err
+		}
+		if err = encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+		offset += count
+	}
+
+	return encoder.Finish()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeNumeric(t *testing.T) {
+	data, err := encodeNumeric(1_700_000_000_000_000,
+		[]uint64{10, 20},
+		[]int64{3, 1},
+		[]int64{1_700_000_000_000_000, 1_700_000_001_000_000, 1_700_000_002_000_000, 1_700_000_000_000_000},
+		[]float64{1.5, 2.5, 3.5, 42},
+	)
+	require.NoError(t, err)
+
+	h, err := decodeNumeric(data)
+	require.NoError(t, err)
+
+	nh, ok := lookupNumeric(uintptr(h))
+	require.True(t, ok)
+	require.ElementsMatch(t, []uint64{10, 20}, nh.blob.MetricIDs())
+
+	ts, values := make([]int64, 2), make([]float64, 2)
+	n, ok := readNumeric(nh.blob, 10, ts, values)
+	require.True(t, ok)
+	require.Equal(t, 2, n, "read is limited by the output capacity")
+	require.Equal(t, []int64{1_700_000_000_000_000, 1_700_000_001_000_000}, ts)
+	require.Equal(t, []float64{1.5, 2.5}, values)
+
+	_, ok = readNumeric(nh.blob, 99, ts, values)
+	require.False(t, ok)
+
+	releaseHandle(uintptr(h))
+	releaseHandle(0)
+
+	_, ok = lookupNumeric(0)
+	require.False(t, ok)
+}
+
+func TestEncodeNumeric_InvalidInput(t *testing.T) {
+	_, err := encodeNumeric(0, []uint64{1}, []int64{2}, []int64{1, 2}, []float64{1})
+	require.ErrorIs(t, err, errInvalidInput)
+
+	_, err = encodeNumeric(0, []uint64{1}, []int64{0}, nil, nil)
+	require.ErrorIs(t, err, errInvalidInput)
+
+	_, err = decodeNumeric([]byte("garbage"))
+	require.Error(t, err)
+}
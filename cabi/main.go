@@ -0,0 +1,230 @@
+// Command cabi builds mebo as a C shared library for use from C, Python (ctypes) and
+// other languages with a C FFI.
+//
+// Build it with:
+//
+//	make build-cshared        # or: cd cabi && go build -buildmode=c-shared -o libmebo.so .
+//
+// which produces libmebo.so (libmebo.dylib on macOS) and the matching libmebo.h.
+//
+// # Conventions
+//
+//   - Decoded blobs are referenced by opaque mebo_blob handles; release them with
+//     mebo_blob_free. Handles are safe to use from multiple threads.
+//   - Functions returning int64_t report failures as -1.
+//   - Memory returned through out-parameters (encoded blobs, error strings) is
+//     allocated with malloc and must be released with mebo_free.
+//   - Timestamps are int64 values in the blob's unit (microseconds by default).
+//
+// See cabi/python/mebo.py for a ctypes wrapper.
+package main
+
+/*
+#include <stddef.h>
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef uintptr_t mebo_blob;
+
+// mebo_point_fn receives one data point. Returning non-zero stops the iteration.
+// tag is not NUL-terminated and is only valid for the duration of the call.
+typedef int (*mebo_point_fn)(void *user, int64_t ts, double value, const char *tag, size_t tag_len);
+
+static inline int mebo_call_point_fn(mebo_point_fn fn, void *user, int64_t ts, double value, const char *tag, size_t tag_len) {
+	return fn(user, ts, value, tag, tag_len);
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/arloliu/mebo"
+)
+
+func main() {}
+
+// mebo_decode_numeric decodes a numeric blob. The input is copied, so the caller may
+// free it once the call returns.
+//
+// Returns a blob handle, or 0 on failure with *err (if err is not NULL) set to an
+// error message the caller must release with mebo_free.
+//
+//export mebo_decode_numeric
+func mebo_decode_numeric(data unsafe.Pointer, length C.size_t, err **C.char) C.mebo_blob {
+	if data == nil || length == 0 {
+		setError(err, errInvalidInput)
+		return 0
+	}
+
+	h, decodeErr := decodeNumeric(C.GoBytes(data, C.int(length))) //nolint: gosec
+	if decodeErr != nil {
+		setError(err, decodeErr)
+		return 0
+	}
+
+	return C.mebo_blob(h)
+}
+
+// mebo_blob_free releases a blob handle. Passing 0 is a no-op.
+//
+//export mebo_blob_free
+func mebo_blob_free(b C.mebo_blob) {
+	releaseHandle(uintptr(b))
+}
+
+// mebo_blob_metric_count returns the number of metrics in the blob, or -1 for an invalid handle.
+//
+//export mebo_blob_metric_count
+func mebo_blob_metric_count(b C.mebo_blob) C.int64_t {
+	nh, ok := lookupNumeric(uintptr(b))
+	if !ok {
+		return -1
+	}
+
+	return C.int64_t(nh.blob.MetricCount())
+}
+
+// mebo_blob_metric_ids writes up to capacity metric IDs into out.
+//
+// Returns the total number of metrics in the blob (which may exceed capacity), or -1
+// for an invalid handle.
+//
+//export mebo_blob_metric_ids
+func mebo_blob_metric_ids(b C.mebo_blob, out *C.uint64_t, capacity C.size_t) C.int64_t {
+	nh, ok := lookupNumeric(uintptr(b))
+	if !ok {
+		return -1
+	}
+
+	ids := nh.blob.MetricIDs()
+	if out != nil && capacity > 0 {
+		copy(unsafe.Slice((*uint64)(unsafe.Pointer(out)), int(capacity)), ids) //nolint: gosec
+	}
+
+	return C.int64_t(len(ids))
+}
+
+// mebo_blob_len returns the number of data points of a metric, or -1 if the handle is
+// invalid or the metric doesn't exist.
+//
+//export mebo_blob_len
+func mebo_blob_len(b C.mebo_blob, metricID C.uint64_t) C.int64_t {
+	nh, ok := lookupNumeric(uintptr(b))
+	if !ok || !nh.blob.HasMetricID(uint64(metricID)) {
+		return -1
+	}
+
+	return C.int64_t(nh.blob.Len(uint64(metricID)))
+}
+
+// mebo_blob_read copies up to capacity data points of a metric into the ts and values
+// arrays, which must each hold at least capacity elements.
+//
+// Returns the number of points copied, or -1 if the handle is invalid, the metric
+// doesn't exist, or an output array is NULL.
+//
+//export mebo_blob_read
+func mebo_blob_read(b C.mebo_blob, metricID C.uint64_t, ts *C.int64_t, values *C.double, capacity C.size_t) C.int64_t {
+	nh, ok := lookupNumeric(uintptr(b))
+	if !ok || ts == nil || values == nil {
+		return -1
+	}
+
+	n, ok := readNumeric(nh.blob, uint64(metricID),
+		unsafe.Slice((*int64)(unsafe.Pointer(ts)), int(capacity)),       //nolint: gosec
+		unsafe.Slice((*float64)(unsafe.Pointer(values)), int(capacity)), //nolint: gosec
+	)
+	if !ok {
+		return -1
+	}
+
+	return C.int64_t(n)
+}
+
+// mebo_blob_iterate calls fn for every data point of a metric, in order, until fn
+// returns non-zero.
+//
+// Returns the number of points delivered to fn, or -1 if the handle is invalid, the
+// metric doesn't exist, or fn is NULL.
+//
+//export mebo_blob_iterate
+func mebo_blob_iterate(b C.mebo_blob, metricID C.uint64_t, fn C.mebo_point_fn, user unsafe.Pointer) C.int64_t {
+	nh, ok := lookupNumeric(uintptr(b))
+	if !ok || fn == nil || !nh.blob.HasMetricID(uint64(metricID)) {
+		return -1
+	}
+
+	n := C.int64_t(0)
+	for _, dp := range nh.blob.All(uint64(metricID)) {
+		n++
+		tag := (*C.char)(unsafe.Pointer(unsafe.StringData(dp.Tag)))
+		if C.mebo_call_point_fn(fn, user, C.int64_t(dp.Ts), C.double(dp.Val), tag, C.size_t(len(dp.Tag))) != 0 {
+			break
+		}
+	}
+
+	return n
+}
+
+// mebo_metric_id returns the metric ID mebo derives from a metric name.
+//
+//export mebo_metric_id
+func mebo_metric_id(name *C.char, length C.size_t) C.uint64_t {
+	return C.uint64_t(mebo.MetricID(C.GoStringN(name, C.int(length)))) //nolint: gosec
+}
+
+// mebo_encode_numeric encodes metrics with the default encoder settings.
+//
+// Metric i has ids[i] and counts[i] data points; ts and values hold the points of all
+// metrics back to back, in metric order. On success *out and *out_len receive the
+// encoded blob, which the caller must release with mebo_free.
+//
+// Returns 0 on success, or -1 with *err (if err is not NULL) set to an error message
+// the caller must release with mebo_free.
+//
+//export mebo_encode_numeric
+func mebo_encode_numeric(startMicros C.int64_t, ids *C.uint64_t, counts *C.int64_t, metrics C.size_t,
+	ts *C.int64_t, values *C.double, out *unsafe.Pointer, outLen *C.size_t, err **C.char,
+) C.int {
+	if ids == nil || counts == nil || metrics == 0 || ts == nil || values == nil || out == nil || outLen == nil {
+		setError(err, errInvalidInput)
+		return -1
+	}
+
+	goCounts := unsafe.Slice((*int64)(unsafe.Pointer(counts)), int(metrics)) //nolint: gosec
+	total := 0
+	for _, count := range goCounts {
+		total += int(count)
+	}
+
+	data, encodeErr := encodeNumeric(int64(startMicros),
+		unsafe.Slice((*uint64)(unsafe.Pointer(ids)), int(metrics)), //nolint: gosec
+		goCounts,
+		unsafe.Slice((*int64)(unsafe.Pointer(ts)), max(total, 0)),       //nolint: gosec
+		unsafe.Slice((*float64)(unsafe.Pointer(values)), max(total, 0)), //nolint: gosec
+	)
+	if encodeErr != nil {
+		setError(err, encodeErr)
+		return -1
+	}
+
+	*out = C.CBytes(data)
+	*outLen = C.size_t(len(data))
+
+	return 0
+}
+
+// mebo_free releases memory returned by this library. Passing NULL is a no-op.
+//
+//export mebo_free
+func mebo_free(p unsafe.Pointer) {
+	C.free(p)
+}
+
+// setError stores a malloc-allocated copy of the error message in *dst when dst is not NULL.
+func setError(dst **C.char, err error) {
+	if dst != nil {
+		*dst = C.CString(err.Error())
+	}
+}
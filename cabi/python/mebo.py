@@ -0,0 +1,158 @@
+"""Thin ctypes wrapper around the mebo C shared library.
+
+Build the library first (``make build-cshared`` from the repository root), then:
+
+    import mebo
+
+    lib = mebo.Library("cabi/libmebo.so")
+    with lib.decode_numeric(open("metrics.mebo", "rb").read()) as blob:
+        for metric_id in blob.metric_ids():
+            timestamps, values = blob.read(metric_id)
+
+``read`` returns ctypes arrays; wrap them with ``numpy.frombuffer`` for zero-copy
+NumPy access.
+"""
+
+import ctypes
+
+POINT_FN = ctypes.CFUNCTYPE(
+    ctypes.c_int, ctypes.c_void_p, ctypes.c_int64, ctypes.c_double, ctypes.c_void_p, ctypes.c_size_t
+)
+
+
+class MeboError(Exception):
+    """Raised when the mebo library reports an error."""
+
+
+class Library:
+    """Bindings for a loaded libmebo shared library."""
+
+    def __init__(self, path):
+        lib = ctypes.CDLL(path)
+
+        lib.mebo_decode_numeric.argtypes = [ctypes.c_void_p, ctypes.c_size_t, ctypes.POINTER(ctypes.c_void_p)]
+        lib.mebo_decode_numeric.restype = ctypes.c_size_t
+        lib.mebo_blob_free.argtypes = [ctypes.c_size_t]
+        lib.mebo_blob_free.restype = None
+        lib.mebo_blob_metric_count.argtypes = [ctypes.c_size_t]
+        lib.mebo_blob_metric_count.restype = ctypes.c_int64
+        lib.mebo_blob_metric_ids.argtypes = [ctypes.c_size_t, ctypes.POINTER(ctypes.c_uint64), ctypes.c_size_t]
+        lib.mebo_blob_metric_ids.restype = ctypes.c_int64
+        lib.mebo_blob_len.argtypes = [ctypes.c_size_t, ctypes.c_uint64]
+        lib.mebo_blob_len.restype = ctypes.c_int64
+        lib.mebo_blob_read.argtypes = [
+            ctypes.c_size_t, ctypes.c_uint64,
+            ctypes.POINTER(ctypes.c_int64), ctypes.POINTER(ctypes.c_double), ctypes.c_size_t,
+        ]
+        lib.mebo_blob_read.restype = ctypes.c_int64
+        lib.mebo_blob_iterate.argtypes = [ctypes.c_size_t, ctypes.c_uint64, POINT_FN, ctypes.c_void_p]
+        lib.mebo_blob_iterate.restype = ctypes.c_int64
+        lib.mebo_metric_id.argtypes = [ctypes.c_char_p, ctypes.c_size_t]
+        lib.mebo_metric_id.restype = ctypes.c_uint64
+        lib.mebo_encode_numeric.argtypes = [
+            ctypes.c_int64, ctypes.POINTER(ctypes.c_uint64), ctypes.POINTER(ctypes.c_int64), ctypes.c_size_t,
+            ctypes.POINTER(ctypes.c_int64), ctypes.POINTER(ctypes.c_double),
+            ctypes.POINTER(ctypes.c_void_p), ctypes.POINTER(ctypes.c_size_t), ctypes.POINTER(ctypes.c_void_p),
+        ]
+        lib.mebo_encode_numeric.restype = ctypes.c_int
+        lib.mebo_free.argtypes = [ctypes.c_void_p]
+        lib.mebo_free.restype = None
+
+        self._lib = lib
+
+    def metric_id(self, name):
+        """Return the metric ID mebo derives from a metric name."""
+        data = name.encode("utf-8")
+        return self._lib.mebo_metric_id(data, len(data))
+
+    def decode_numeric(self, data):
+        """Decode a numeric blob and return a NumericBlob."""
+        err = ctypes.c_void_p()
+        handle = self._lib.mebo_decode_numeric(data, len(data), ctypes.byref(err))
+        if not handle:
+            raise MeboError(self._take_error(err))
+        return NumericBlob(self._lib, handle)
+
+    def encode_numeric(self, start_us, metrics):
+        """Encode ``{metric_id: (timestamps, values)}`` with the default settings and return bytes."""
+        ids, counts, ts, values = [], [], [], []
+        for metric_id, (metric_ts, metric_values) in metrics.items():
+            if len(metric_ts) != len(metric_values):
+                raise ValueError(f"metric {metric_id}: {len(metric_ts)} timestamps but {len(metric_values)} values")
+            ids.append(metric_id)
+            counts.append(len(metric_ts))
+            ts.extend(metric_ts)
+            values.extend(metric_values)
+
+        out, out_len, err = ctypes.c_void_p(), ctypes.c_size_t(), ctypes.c_void_p()
+        rc = self._lib.mebo_encode_numeric(
+            start_us,
+            (ctypes.c_uint64 * len(ids))(*ids), (ctypes.c_int64 * len(counts))(*counts), len(ids),
+            (ctypes.c_int64 * len(ts))(*ts), (ctypes.c_double * len(values))(*values),
+            ctypes.byref(out), ctypes.byref(out_len), ctypes.byref(err),
+        )
+        if rc != 0:
+            raise MeboError(self._take_error(err))
+        try:
+            return ctypes.string_at(out, out_len.value)
+        finally:
+            self._lib.mebo_free(out)
+
+    def _take_error(self, err):
+        if not err.value:
+            return "unknown error"
+        try:
+            return ctypes.string_at(err).decode("utf-8")
+        finally:
+            self._lib.mebo_free(err)
+
+
+class NumericBlob:
+    """A decoded numeric blob. Call close() (or use ``with``) to release it."""
+
+    def __init__(self, lib, handle):
+        self._lib = lib
+        self._handle = handle
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+
+    def close(self):
+        if self._handle:
+            self._lib.mebo_blob_free(self._handle)
+            self._handle = 0
+
+    def metric_ids(self):
+        count = self._lib.mebo_blob_metric_count(self._handle)
+        ids = (ctypes.c_uint64 * count)()
+        self._lib.mebo_blob_metric_ids(self._handle, ids, count)
+        return list(ids)
+
+    def __len__(self):
+        return self._lib.mebo_blob_metric_count(self._handle)
+
+    def read(self, metric_id):
+        """Return ``(timestamps, values)`` ctypes arrays for a metric."""
+        n = self._lib.mebo_blob_len(self._handle, metric_id)
+        if n < 0:
+            raise KeyError(metric_id)
+        ts, values = (ctypes.c_int64 * n)(), (ctypes.c_double * n)()
+        got = self._lib.mebo_blob_read(self._handle, metric_id, ts, values, n)
+        if got != n:
+            raise MeboError(f"read {got} of {n} points")
+        return ts, values
+
+    def points(self, metric_id):
+        """Return a list of ``(ts, value, tag)`` tuples using the iterator callback."""
+        out = []
+
+        def collect(_user, ts, value, tag, tag_len):
+            out.append((ts, value, ctypes.string_at(tag, tag_len).decode("utf-8") if tag_len else ""))
+            return 0
+
+        if self._lib.mebo_blob_iterate(self._handle, metric_id, POINT_FN(collect), None) < 0:
+            raise KeyError(metric_id)
+        return out
@@ -0,0 +1,320 @@
+package compaction
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// Entry describes one stored numeric blob in a Manifest.
+type Entry struct {
+	// Key identifies the blob in the Store.
+	Key string
+
+	// Tier is the index of the Policy tier the blob's data belongs to.
+	Tier int
+
+	// Start is the earliest data timestamp of the blob.
+	Start time.Time
+
+	// End is the latest data timestamp of the blob.
+	End time.Time
+}
+
+// Manifest lists the stored blobs of a series archive.
+type Manifest struct {
+	// Entries are the stored blobs, sorted by Start and then Tier after Compact.
+	Entries []Entry
+}
+
+// Store fetches and stores encoded blobs for Compact.
+type Store interface {
+	// Get returns the encoded blob stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Put stores a compacted blob described by entry (whose Key is empty) and
+	// returns the key it was stored under.
+	Put(ctx context.Context, entry Entry, data []byte) (string, error)
+}
+
+// Result is the outcome of Compact.
+type Result struct {
+	// Manifest is the updated manifest: kept entries plus Added.
+	Manifest Manifest
+
+	// Added are the entries of the compacted blobs written to the Store.
+	Added []Entry
+
+	// Removed are the entries superseded by Added or expired by the policy. Their
+	// blobs are still in the Store and may be deleted once Manifest is persisted.
+	Removed []Entry
+}
+
+// NewEntry describes an encoded numeric blob for a Manifest, reading its data
+// time range from the blob.
+//
+// Parameters:
+//   - key: Store key of the blob
+//   - tier: Policy tier the blob belongs to (0 for freshly ingested raw blobs)
+//   - data: Encoded numeric blob
+//
+// Returns:
+//   - Entry: Entry with Start and End set to the blob's data time range
+//   - error: Decoding error, or ErrMetricNotFound if the blob holds no data points
+func NewEntry(key string, tier int, data []byte) (Entry, error) {
+	decoder, err := blob.NewNumericDecoder(data)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	unit := b.TimestampUnit()
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, id := range b.MetricIDs() {
+		for ts := range b.AllTimestamps(id) {
+			first = min(first, ts)
+			last = max(last, ts)
+		}
+	}
+
+	if first > last {
+		return Entry{}, fmt.Errorf("%w: blob %q holds no data points", errs.ErrMetricNotFound, key)
+	}
+
+	return Entry{Key: key, Tier: tier, Start: unit.Time(first), End: unit.Time(last)}, nil
+}
+
+// Compact applies policy to the entries of manifest as of now.
+//
+// Entries whose age (now minus End) still falls in their tier are kept. Entries
+// past the last tier's retention expire. All other entries move to the tier
+// matching their age: entries moving to the same tier within the same
+// Policy.Window are fetched from store one at a time, downsampled together into
+// one new blob, and written back with Store.Put.
+//
+// Compact does not delete blobs; see Result.Removed.
+//
+// Parameters:
+//   - ctx: Context checked between source blobs
+//   - store: Source and destination of encoded blobs
+//   - manifest: Current manifest
+//   - policy: Retention and downsampling policy
+//   - now: Reference time for entry ages
+//
+// Returns:
+//   - Result: Updated manifest with the added and removed entries
+//   - error: ErrInvalidCompactionPolicy, context, Store, decoding or encoding errors
+func Compact(ctx context.Context, store Store, manifest Manifest, policy Policy, now time.Time) (Result, error) {
+	if err := policy.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	groups := make(map[groupKey][]Entry)
+	for _, entry := range manifest.Entries {
+		target, ok := policy.tierFor(now.Sub(entry.End))
+		switch {
+		case !ok:
+			result.Removed = append(result.Removed, entry)
+		case target <= entry.Tier:
+			result.Manifest.Entries = append(result.Manifest.Entries, entry)
+		default:
+			key := groupKey{tier: target, window: entry.Start.Truncate(policy.window()).UnixMicro()}
+			groups[key] = append(groups[key], entry)
+		}
+	}
+
+	keys := slices.SortedFunc(maps.Keys(groups), func(a, b groupKey) int {
+		return cmp.Or(cmp.Compare(a.window, b.window), cmp.Compare(a.tier, b.tier))
+	})
+
+	for _, key := range keys {
+		added, ok, err := compactGroup(ctx, store, policy, key, groups[key])
+		if err != nil {
+			return Result{}, err
+		}
+
+		if ok {
+			result.Added = append(result.Added, added)
+			result.Manifest.Entries = append(result.Manifest.Entries, added)
+		}
+		result.Removed = append(result.Removed, groups[key]...)
+	}
+
+	slices.SortStableFunc(result.Manifest.Entries, func(a, b Entry) int {
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.Tier, b.Tier))
+	})
+
+	return result, nil
+}
+
+// groupKey identifies the entries compacted into one blob.
+type groupKey struct {
+	tier   int
+	window int64 // Window start, microseconds
+}
+
+// bucket accumulates the points of one metric falling into one resolution bucket.
+type bucket struct {
+	count  int
+	sum    float64
+	min    float64
+	max    float64
+	last   float64
+	lastTs int64
+}
+
+// add folds a point into the bucket.
+func (b *bucket) add(ts int64, val float64) {
+	if b.count == 0 {
+		b.min, b.max = val, val
+	} else {
+		b.min = min(b.min, val)
+		b.max = max(b.max, val)
+	}
+
+	if b.count == 0 || ts >= b.lastTs {
+		b.last, b.lastTs = val, ts
+	}
+
+	b.count++
+	b.sum += val
+}
+
+// value returns the bucket's aggregate.
+func (b *bucket) value(agg Aggregation) float64 {
+	switch agg {
+	case AggregateMin:
+		return b.min
+	case AggregateMax:
+		return b.max
+	case AggregateSum:
+		return b.sum
+	case AggregateLast:
+		return b.last
+	case AggregateCount:
+		return float64(b.count)
+	default:
+		return b.sum / float64(b.count)
+	}
+}
+
+// compactGroup downsamples the blobs of entries into one blob of the group's
+// tier and stores it. It reports false if the entries hold no data points.
+func compactGroup(ctx context.Context, store Store, policy Policy, key groupKey, entries []Entry) (Entry, bool, error) {
+	tier := policy.Tiers[key.tier]
+	resolution := int64(tier.Resolution / time.Microsecond)
+
+	// Metric ID → bucket start (microseconds) → accumulator
+	metrics := make(map[uint64]map[int64]*bucket)
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return Entry{}, false, err
+		}
+
+		data, err := store.Get(ctx, entry.Key)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("get blob %q: %w", entry.Key, err)
+		}
+
+		decoder, err := blob.NewNumericDecoder(data)
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("decode blob %q: %w", entry.Key, err)
+		}
+
+		b, err := decoder.Decode()
+		if err != nil {
+			return Entry{}, false, fmt.Errorf("decode blob %q: %w", entry.Key, err)
+		}
+
+		unit := b.TimestampUnit()
+		for _, id := range b.MetricIDs() {
+			buckets := metrics[id]
+			if buckets == nil {
+				buckets = make(map[int64]*bucket)
+				metrics[id] = buckets
+			}
+
+			for _, dp := range b.All(id) {
+				ts := unit.Convert(dp.Ts, format.TimeUnitMicrosecond)
+				start := floorDiv(ts, resolution) * resolution
+
+				acc := buckets[start]
+				if acc == nil {
+					acc = &bucket{}
+					buckets[start] = acc
+				}
+				acc.add(ts, dp.Val)
+			}
+		}
+	}
+
+	if len(metrics) == 0 {
+		return Entry{}, false, nil
+	}
+
+	encoder, err := blob.NewNumericEncoder(time.UnixMicro(key.window).UTC(), policy.EncoderOptions...)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	first, last := int64(math.MaxInt64), int64(math.MinInt64)
+	for _, id := range slices.Sorted(maps.Keys(metrics)) {
+		buckets := metrics[id]
+		if len(buckets) > encoder.MaxDataPoints() {
+			return Entry{}, false, fmt.Errorf("metric %#x has %d buckets in window %s, exceeding %d data points",
+				id, len(buckets), time.UnixMicro(key.window).UTC(), encoder.MaxDataPoints())
+		}
+
+		if err := encoder.StartMetricID(id, len(buckets)); err != nil {
+			return Entry{}, false, err
+		}
+
+		for _, start := range slices.Sorted(maps.Keys(buckets)) {
+			if err := encoder.AddDataPoint(start, buckets[start].value(tier.Aggregation), ""); err != nil {
+				return Entry{}, false, err
+			}
+			first = min(first, start)
+			last = max(last, start)
+		}
+
+		if err := encoder.EndMetric(); err != nil {
+			return Entry{}, false, err
+		}
+	}
+
+	data, err := encoder.Finish()
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	added := Entry{Tier: key.tier, Start: time.UnixMicro(first).UTC(), End: time.UnixMicro(last).UTC()}
+	added.Key, err = store.Put(ctx, added, data)
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("put compacted blob: %w", err)
+	}
+
+	return added, true, nil
+}
+
+// floorDiv returns a/b rounded toward negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+
+	return q
+}
@@ -0,0 +1,197 @@
+package compaction
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+type memoryStore struct {
+	blobs map[string][]byte
+	gets  int
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{blobs: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.gets++
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("blob %q not found", key)
+	}
+
+	return data, nil
+}
+
+func (s *memoryStore) Put(_ context.Context, entry Entry, data []byte) (string, error) {
+	key := fmt.Sprintf("tier%d/%d", entry.Tier, entry.Start.UnixMicro())
+	s.blobs[key] = data
+
+	return key, nil
+}
+
+// addRaw stores a raw blob with metric 1 holding the given values every interval from start.
+func (s *memoryStore) addRaw(t *testing.T, key string, start time.Time, interval time.Duration, values ...float64) Entry {
+	t.Helper()
+
+	encoder, err := blob.NewNumericEncoder(start, blob.WithTimestampUnit(format.TimeUnitMillisecond))
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricID(1, len(values)))
+	for i, v := range values {
+		require.NoError(t, encoder.AddDataPoint(start.Add(time.Duration(i)*interval).UnixMilli(), v, ""))
+	}
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	s.blobs[key] = data
+	entry, err := NewEntry(key, 0, data)
+	require.NoError(t, err)
+
+	return entry
+}
+
+func decodeValues(t *testing.T, data []byte) ([]int64, []float64) {
+	t.Helper()
+
+	decoder, err := blob.NewNumericDecoder(data)
+	require.NoError(t, err)
+	b, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return slices.Collect(b.AllTimestamps(1)), slices.Collect(b.AllValues(1))
+}
+
+func TestCompact(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := Policy{
+		Tiers: []Tier{
+			{Retention: 7 * day},
+			{Resolution: time.Minute, Retention: 90 * day, Aggregation: AggregateMean},
+			{Resolution: time.Hour, Retention: 365 * day, Aggregation: AggregateMax},
+		},
+	}
+
+	store := newMemoryStore()
+	fresh := store.addRaw(t, "fresh", now.Add(-day), 10*time.Second, 1, 2, 3)
+	// Two raw blobs of the same day move to the 1m tier together.
+	oldA := store.addRaw(t, "oldA", now.Add(-10*day), 20*time.Second, 1, 2, 3, 4, 5, 6)
+	oldB := store.addRaw(t, "oldB", now.Add(-10*day+time.Hour), 30*time.Second, 10, 20)
+	older := store.addRaw(t, "older", now.Add(-100*day), 20*time.Minute, 5, 9, 7, 1)
+	expired := store.addRaw(t, "expired", now.Add(-400*day), time.Second, 1)
+
+	require.Equal(t, now.Add(-day).Add(20*time.Second), fresh.End)
+
+	manifest := Manifest{Entries: []Entry{expired, fresh, oldB, older, oldA}}
+	result, err := Compact(context.Background(), store, manifest, policy, now)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []Entry{expired, oldA, oldB, older}, result.Removed)
+	require.Len(t, result.Added, 2)
+	require.Len(t, result.Manifest.Entries, 3)
+	require.Equal(t, fresh, result.Manifest.Entries[2])
+
+	hourly := result.Manifest.Entries[0]
+	require.Equal(t, 2, hourly.Tier)
+	ts, vals := decodeValues(t, store.blobs[hourly.Key])
+	require.Equal(t, []float64{9, 1}, vals)
+	require.Equal(t, now.Add(-100*day).UnixMicro(), ts[0])
+	require.Equal(t, hourly.End.UnixMicro(), ts[1])
+
+	minutely := result.Manifest.Entries[1]
+	require.Equal(t, 1, minutely.Tier)
+	require.Equal(t, oldA.Start, minutely.Start)
+	ts, vals = decodeValues(t, store.blobs[minutely.Key])
+	require.Equal(t, []float64{2, 5, 15}, vals)
+	require.Equal(t, []int64{
+		oldA.Start.UnixMicro(),
+		oldA.Start.Add(time.Minute).UnixMicro(),
+		oldB.Start.UnixMicro(),
+	}, ts)
+
+	// A second pass at the same time is a no-op.
+	store.gets = 0
+	again, err := Compact(context.Background(), store, result.Manifest, policy, now)
+	require.NoError(t, err)
+	require.Equal(t, result.Manifest, again.Manifest)
+	require.Empty(t, again.Added)
+	require.Empty(t, again.Removed)
+	require.Zero(t, store.gets)
+}
+
+func TestCompact_Errors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := Policy{Tiers: []Tier{{Retention: time.Hour}, {Resolution: time.Minute, Retention: 48 * time.Hour}}}
+
+	store := newMemoryStore()
+	old := store.addRaw(t, "old", now.Add(-3*time.Hour), time.Second, 1, 2)
+	manifest := Manifest{Entries: []Entry{old}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Compact(ctx, store, manifest, policy, now)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = Compact(context.Background(), store, Manifest{Entries: []Entry{{Key: "missing", End: old.End}}}, policy, now)
+	require.ErrorContains(t, err, "missing")
+
+	_, err = Compact(context.Background(), store, manifest, Policy{}, now)
+	require.ErrorIs(t, err, errs.ErrInvalidCompactionPolicy)
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name  string
+		tiers []Tier
+		valid bool
+	}{
+		{name: "raw then 1m", tiers: []Tier{{Retention: 7 * day}, {Resolution: time.Minute, Retention: 90 * day}}, valid: true},
+		{name: "downsampled only", tiers: []Tier{{Resolution: time.Minute, Retention: day}}, valid: true},
+		{name: "no tiers"},
+		{name: "zero retention", tiers: []Tier{{}}},
+		{name: "raw after first", tiers: []Tier{{Retention: day}, {Retention: 2 * day}}},
+		{name: "decreasing retention", tiers: []Tier{{Retention: 2 * day}, {Resolution: time.Minute, Retention: day}}},
+		{name: "finer resolution", tiers: []Tier{{Resolution: time.Hour, Retention: day}, {Resolution: time.Minute, Retention: 2 * day}}},
+		{name: "too many points per window", tiers: []Tier{{Resolution: time.Second, Retention: day}}},
+		{name: "sub-microsecond", tiers: []Tier{{Resolution: 1500 * time.Nanosecond, Retention: day}}},
+		{name: "unknown aggregation", tiers: []Tier{{Resolution: time.Minute, Retention: day, Aggregation: AggregateCount + 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Policy{Tiers: tt.tiers}.Validate()
+			if tt.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, errs.ErrInvalidCompactionPolicy)
+		})
+	}
+}
+
+func TestBucket_Aggregations(t *testing.T) {
+	var b bucket
+	b.add(30, 4)
+	b.add(10, 1)
+	b.add(20, 7)
+
+	require.InDelta(t, 4.0, b.value(AggregateMean), 1e-12)
+	require.Equal(t, 1.0, b.value(AggregateMin))
+	require.Equal(t, 7.0, b.value(AggregateMax))
+	require.Equal(t, 12.0, b.value(AggregateSum))
+	require.Equal(t, 4.0, b.value(AggregateLast))
+	require.Equal(t, 3.0, b.value(AggregateCount))
+	require.Equal(t, "Last", AggregateLast.String())
+}
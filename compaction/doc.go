@@ -0,0 +1,44 @@
+// Package compaction applies retention and downsampling policies to a set of
+// stored numeric blobs.
+//
+// A Manifest lists the stored blobs with their tier and data time range. Given
+// a Policy such as "keep raw data 7 days, 1-minute averages 90 days", Compact
+// decides for every entry whether it stays, moves to a coarser tier, or expires,
+// then downsamples the moving entries into new blobs and returns the updated
+// manifest:
+//
+//	policy := compaction.Policy{
+//	    Tiers: []compaction.Tier{
+//	        {Retention: 7 * 24 * time.Hour},
+//	        {Resolution: time.Minute, Retention: 90 * 24 * time.Hour, Aggregation: compaction.AggregateMean},
+//	    },
+//	}
+//
+//	result, err := compaction.Compact(ctx, store, manifest, policy, time.Now())
+//	if err != nil {
+//	    return err
+//	}
+//	for _, entry := range result.Removed {
+//	    deleteBlob(entry.Key) // superseded or expired
+//	}
+//	saveManifest(result.Manifest)
+//
+// # Memory
+//
+// Entries moving to the same tier are grouped by Policy.Window, and one group is
+// compacted at a time: its blobs are fetched from the Store and folded into
+// per-bucket accumulators one by one, and the group's compacted blob is written
+// before the next group starts. Memory is therefore bounded by one source blob
+// plus the buckets of one window, independent of the manifest size.
+//
+// # Semantics
+//
+// Timestamps are converted to microseconds using each blob's timestamp unit, and
+// buckets are aligned to multiples of the tier resolution since the Unix epoch.
+// Every coarser tier aggregates the points of the tier it is compacted from, so
+// a Mean of 1-minute means weights each minute equally. Tags are not carried into
+// aggregated blobs, and only numeric blobs are supported.
+//
+// Compact never deletes blobs: it returns the entries to delete in Result.Removed,
+// so the caller can persist the new manifest before removing anything.
+package compaction
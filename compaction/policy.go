@@ -0,0 +1,159 @@
+package compaction
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+)
+
+// DefaultWindow is the time span covered by one compacted blob when
+// Policy.Window is zero.
+const DefaultWindow = 24 * time.Hour
+
+// Aggregation selects how a downsampling tier combines the points of a bucket.
+type Aggregation uint8
+
+const (
+	// AggregateMean stores the arithmetic mean of the bucket's values.
+	AggregateMean Aggregation = iota
+	// AggregateMin stores the smallest value of the bucket.
+	AggregateMin
+	// AggregateMax stores the largest value of the bucket.
+	AggregateMax
+	// AggregateSum stores the sum of the bucket's values.
+	AggregateSum
+	// AggregateLast stores the value with the latest timestamp of the bucket.
+	AggregateLast
+	// AggregateCount stores the number of points in the bucket.
+	AggregateCount
+)
+
+// String returns the name of the aggregation.
+func (a Aggregation) String() string {
+	switch a {
+	case AggregateMean:
+		return "Mean"
+	case AggregateMin:
+		return "Min"
+	case AggregateMax:
+		return "Max"
+	case AggregateSum:
+		return "Sum"
+	case AggregateLast:
+		return "Last"
+	case AggregateCount:
+		return "Count"
+	default:
+		return "Unknown"
+	}
+}
+
+// Tier is one retention stage of a Policy.
+type Tier struct {
+	// Resolution is the bucket width of the tier, or 0 for raw data.
+	// Only the first tier may be raw.
+	Resolution time.Duration
+
+	// Retention is the data age up to which data stays in this tier. The age of
+	// a manifest entry is measured from its End time.
+	Retention time.Duration
+
+	// Aggregation combines the points of a bucket. Ignored for raw tiers.
+	Aggregation Aggregation
+}
+
+// Policy describes how long data is kept at which resolution.
+//
+// Tiers are ordered from finest to coarsest with increasing retention. Data
+// older than the last tier's retention expires.
+type Policy struct {
+	// Tiers are the retention stages, finest first.
+	Tiers []Tier
+
+	// Window is the time span covered by one compacted blob, aligned to the Unix
+	// epoch. Zero means DefaultWindow.
+	Window time.Duration
+
+	// EncoderOptions configure the encoder of compacted blobs.
+	EncoderOptions []blob.NumericEncoderOption
+}
+
+// Validate checks that the policy is usable by Compact.
+//
+// Returns:
+//   - error: ErrInvalidCompactionPolicy describing the first problem found
+func (p Policy) Validate() error {
+	if len(p.Tiers) == 0 {
+		return fmt.Errorf("%w: no tiers", errs.ErrInvalidCompactionPolicy)
+	}
+
+	window := p.window()
+	if window < 0 {
+		return fmt.Errorf("%w: negative window %s", errs.ErrInvalidCompactionPolicy, window)
+	}
+
+	for i, tier := range p.Tiers {
+		if tier.Retention <= 0 {
+			return fmt.Errorf("%w: tier %d has non-positive retention %s", errs.ErrInvalidCompactionPolicy, i, tier.Retention)
+		}
+
+		if tier.Resolution < 0 || (tier.Resolution == 0 && i > 0) {
+			return fmt.Errorf("%w: tier %d must have a positive resolution", errs.ErrInvalidCompactionPolicy, i)
+		}
+
+		if tier.Resolution > 0 && tier.Resolution%time.Microsecond != 0 {
+			return fmt.Errorf("%w: tier %d resolution %s is not a whole number of microseconds",
+				errs.ErrInvalidCompactionPolicy, i, tier.Resolution)
+		}
+
+		if tier.Resolution > 0 && window/tier.Resolution > math.MaxUint16 {
+			return fmt.Errorf("%w: tier %d resolution %s yields more than %d points per window %s",
+				errs.ErrInvalidCompactionPolicy, i, tier.Resolution, math.MaxUint16, window)
+		}
+
+		if tier.Aggregation > AggregateCount {
+			return fmt.Errorf("%w: tier %d has unknown aggregation %d", errs.ErrInvalidCompactionPolicy, i, tier.Aggregation)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := p.Tiers[i-1]
+		if tier.Retention <= prev.Retention {
+			return fmt.Errorf("%w: tier %d retention %s must exceed tier %d retention %s",
+				errs.ErrInvalidCompactionPolicy, i, tier.Retention, i-1, prev.Retention)
+		}
+
+		if tier.Resolution < prev.Resolution {
+			return fmt.Errorf("%w: tier %d resolution %s is finer than tier %d resolution %s",
+				errs.ErrInvalidCompactionPolicy, i, tier.Resolution, i-1, prev.Resolution)
+		}
+	}
+
+	return nil
+}
+
+// window returns the effective compacted blob window.
+func (p Policy) window() time.Duration {
+	if p.Window == 0 {
+		return DefaultWindow
+	}
+
+	return p.Window
+}
+
+// tierFor returns the tier that data of the given age belongs to, or false if
+// the data has expired.
+func (p Policy) tierFor(age time.Duration) (int, bool) {
+	for i, tier := range p.Tiers {
+		if age < tier.Retention {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
@@ -181,6 +181,22 @@
 //   - S2: ~256KB compression, ~64KB decompression
 //   - Zstd: ~2-4MB compression, ~1-2MB decompression
 //
+// # Build Tags (WebAssembly, TinyGo)
+//
+// All codecs are pure Go and build for js/wasm and wasip1. Two build tags shrink
+// the footprint for browsers and other constrained targets:
+//
+//   - mebo_lite: Zstd uses one lazily created low-memory encoder/decoder shared under
+//     a mutex instead of per-goroutine pools. Enabled automatically under TinyGo.
+//   - mebo_nozstd: Excludes the Zstd implementation entirely; Zstd payloads fail with
+//     errs.ErrUnsupportedCompression. Use when blobs are written with None, S2 or LZ4.
+//
+// Example:
+//
+//	GOOS=js GOARCH=wasm go build -tags mebo_lite,mebo_nozstd ./cmd/viewer
+//
+// Both modes read blobs written by the default build.
+//
 // # Thread Safety
 //
 // All codec implementations are thread-safe and can be safely shared across goroutines.
@@ -14,7 +14,9 @@ package compress
 //   - Decompression: ~2-5 ns/byte
 //   - Compression ratio: 5:1 to 20:1 for delta-encoded timestamps
 //   - Memory usage: Moderate (creates encoder/decoder per operation)
-type ZstdCompressor struct{}
+type ZstdCompressor struct {
+	deterministic bool // use the fixed encoder configuration (see NewDeterministicZstdCompressor)
+}
 
 var _ Codec = (*ZstdCompressor)(nil)
 
@@ -33,3 +35,25 @@ var _ Codec = (*ZstdCompressor)(nil)
 func NewZstdCompressor() ZstdCompressor {
 	return ZstdCompressor{}
 }
+
+// NewDeterministicZstdCompressor creates a Zstd compressor with a fixed encoder
+// configuration.
+//
+// The default compressor is tuned for throughput and may change its encoder
+// settings between releases or build tags. The deterministic compressor always
+// uses a single-threaded encoder with a fixed level and a 1 MiB window, so the
+// same input yields byte-identical frames in default and lite builds. Frames
+// decode with any Zstd decompressor.
+//
+// Returns:
+//   - ZstdCompressor: New deterministic Zstd compressor instance
+//
+// Example:
+//
+//	compressor := NewDeterministicZstdCompressor()
+//	a, _ := compressor.Compress(data)
+//	b, _ := compressor.Compress(data)
+//	// bytes.Equal(a, b) == true
+func NewDeterministicZstdCompressor() ZstdCompressor {
+	return ZstdCompressor{deterministic: true}
+}
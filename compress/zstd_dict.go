@@ -0,0 +1,106 @@
+//go:build !mebo_nozstd
+
+package compress
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// zstdRawDictID is the dictionary ID recorded in frames produced by a
+// ZstdDictCompressor. Raw content dictionaries carry no ID of their own; the
+// decoder only needs to register the same content under the same ID.
+const zstdRawDictID = 1
+
+// ZstdDictCompressor provides Zstandard compression with a raw content
+// dictionary shared by many small payloads, such as the individually
+// compressed values of a blob. Content that also appears in the dictionary is
+// encoded as back-references into it, so short payloads compress well
+// without carrying their own history.
+//
+// Frames decode only with a ZstdDictCompressor created from the same
+// dictionary content. The encoder and decoder are created on first use; a
+// ZstdDictCompressor is safe for concurrent use.
+type ZstdDictCompressor struct {
+	dict []byte
+
+	encOnce sync.Once
+	encoder *zstd.Encoder
+	encErr  error
+
+	decOnce sync.Once
+	decoder *zstd.Decoder
+	decErr  error
+}
+
+var _ Codec = (*ZstdDictCompressor)(nil)
+
+// NewZstdDictCompressor creates a Zstd compressor using dict as raw content
+// dictionary. Compression uses the fixed encoder configuration of
+// NewDeterministicZstdCompressor, so the same input and dictionary yield
+// byte-identical frames in default and lite builds.
+//
+// Parameters:
+//   - dict: Dictionary content, typically samples of the data to compress;
+//     retained by the compressor and must not be modified
+//
+// Returns:
+//   - *ZstdDictCompressor: New dictionary compressor
+//   - error: Error if dict is empty
+//
+// Example:
+//
+//	compressor, err := NewZstdDictCompressor([]byte(`{"level":"error","msg":"`))
+//	compressed, err := compressor.Compress([]byte(`{"level":"error","msg":"timeout"}`))
+func NewZstdDictCompressor(dict []byte) (*ZstdDictCompressor, error) {
+	if len(dict) == 0 {
+		return nil, errors.New("zstd dictionary must not be empty")
+	}
+
+	return &ZstdDictCompressor{dict: dict}, nil
+}
+
+// Compress compresses the input data using the dictionary.
+func (c *ZstdDictCompressor) Compress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	c.encOnce.Do(func() {
+		opts := append(zstdFixedEncoderOptions(), zstd.WithEncoderDictRaw(zstdRawDictID, c.dict))
+		c.encoder, c.encErr = zstd.NewWriter(nil, opts...)
+	})
+	if c.encErr != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", c.encErr)
+	}
+
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+// Decompress decompresses data compressed with the same dictionary.
+func (c *ZstdDictCompressor) Decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	c.decOnce.Do(func() {
+		c.decoder, c.decErr = zstd.NewReader(nil,
+			zstd.WithDecoderLowmem(true),
+			zstd.WithDecoderMaxMemory(uint64(maxDecompressSize)),
+			zstd.WithDecoderDictRaw(zstdRawDictID, c.dict),
+		)
+	})
+	if c.decErr != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", c.decErr)
+	}
+
+	result, err := c.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("zstd decompression failed: %w", err)
+	}
+
+	return result, nil
+}
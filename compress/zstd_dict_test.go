@@ -0,0 +1,43 @@
+//go:build !mebo_nozstd
+
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZstdDictCompressor(t *testing.T) {
+	dict := []byte(`{"level":"error","service":"checkout","msg":"upstream request timeout after retries"}`)
+	data := []byte(`{"level":"error","service":"checkout","msg":"upstream request timeout"}`)
+
+	codec, err := NewZstdDictCompressor(dict)
+	require.NoError(t, err)
+
+	compressed, err := codec.Compress(data)
+	require.NoError(t, err)
+
+	plain, err := NewDeterministicZstdCompressor().Compress(data)
+	require.NoError(t, err)
+	require.Less(t, len(compressed), len(plain))
+
+	// Frames decode with a compressor created from the same dictionary only.
+	other, err := NewZstdDictCompressor(bytes.Clone(dict))
+	require.NoError(t, err)
+	decompressed, err := other.Decompress(compressed)
+	require.NoError(t, err)
+	require.Equal(t, data, decompressed)
+
+	_, err = NewZstdCompressor().Decompress(compressed)
+	require.Error(t, err)
+
+	// Compression is deterministic.
+	again, err := other.Compress(data)
+	require.NoError(t, err)
+	require.Equal(t, compressed, again)
+
+	_, err = NewZstdDictCompressor(nil)
+	require.Error(t, err)
+}
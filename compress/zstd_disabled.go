@@ -0,0 +1,49 @@
+//go:build mebo_nozstd
+
+package compress
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+// Compress always fails: Zstd support was excluded with the mebo_nozstd build tag.
+func (c ZstdCompressor) Compress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%w: zstd excluded by the mebo_nozstd build tag", errs.ErrUnsupportedCompression)
+}
+
+// Decompress always fails: Zstd support was excluded with the mebo_nozstd build tag.
+func (c ZstdCompressor) Decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%w: zstd excluded by the mebo_nozstd build tag", errs.ErrUnsupportedCompression)
+}
+
+// ZstdDictCompressor is unavailable: Zstd support was excluded with the
+// mebo_nozstd build tag.
+type ZstdDictCompressor struct{}
+
+var _ Codec = (*ZstdDictCompressor)(nil)
+
+// NewZstdDictCompressor always fails: Zstd support was excluded with the
+// mebo_nozstd build tag.
+func NewZstdDictCompressor(_ []byte) (*ZstdDictCompressor, error) {
+	return nil, fmt.Errorf("%w: zstd excluded by the mebo_nozstd build tag", errs.ErrUnsupportedCompression)
+}
+
+// Compress always fails: Zstd support was excluded with the mebo_nozstd build tag.
+func (c *ZstdDictCompressor) Compress(_ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("%w: zstd excluded by the mebo_nozstd build tag", errs.ErrUnsupportedCompression)
+}
+
+// Decompress always fails: Zstd support was excluded with the mebo_nozstd build tag.
+func (c *ZstdDictCompressor) Decompress(_ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("%w: zstd excluded by the mebo_nozstd build tag", errs.ErrUnsupportedCompression)
+}
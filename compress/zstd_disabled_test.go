@@ -0,0 +1,25 @@
+//go:build mebo_nozstd
+
+package compress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func TestZstdDisabled(t *testing.T) {
+	codec := NewZstdCompressor()
+
+	_, err := codec.Compress([]byte("payload"))
+	require.ErrorIs(t, err, errs.ErrUnsupportedCompression)
+
+	_, err = codec.Decompress([]byte("payload"))
+	require.ErrorIs(t, err, errs.ErrUnsupportedCompression)
+
+	out, err := codec.Decompress(nil)
+	require.NoError(t, err)
+	require.Nil(t, out)
+}
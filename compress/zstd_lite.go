@@ -0,0 +1,72 @@
+//go:build (mebo_lite || tinygo) && !mebo_nozstd
+
+package compress
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// In lite mode (WebAssembly, TinyGo, embedded) a single low-memory encoder and
+// decoder are created on first use and shared under a mutex, instead of pooling
+// one high-memory instance per goroutine. This trades parallel throughput for a
+// small, predictable heap.
+var (
+	zstdLiteMu      sync.Mutex
+	zstdLiteEncoder *zstd.Encoder
+	zstdLiteDecoder *zstd.Decoder
+)
+
+// Compress compresses the input data using Zstandard compression.
+// Uses a shared low-memory encoder (lite build). The lite encoder already uses the
+// fixed configuration, so deterministic compressors share it.
+func (c ZstdCompressor) Compress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	zstdLiteMu.Lock()
+	defer zstdLiteMu.Unlock()
+
+	if zstdLiteEncoder == nil {
+		encoder, err := zstd.NewWriter(nil, zstdFixedEncoderOptions()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		zstdLiteEncoder = encoder
+	}
+
+	return zstdLiteEncoder.EncodeAll(data, nil), nil
+}
+
+// Decompress decompresses Zstd-compressed data.
+// Uses a shared low-memory decoder (lite build).
+func (c ZstdCompressor) Decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	zstdLiteMu.Lock()
+	defer zstdLiteMu.Unlock()
+
+	if zstdLiteDecoder == nil {
+		decoder, err := zstd.NewReader(nil,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderLowmem(true),
+			zstd.WithDecoderMaxMemory(uint64(maxDecompressSize)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		zstdLiteDecoder = decoder
+	}
+
+	decompressed, err := zstdLiteDecoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("zstd decompression failed: %w", err)
+	}
+
+	return decompressed, nil
+}
@@ -0,0 +1,43 @@
+//go:build (mebo_lite || tinygo) && !mebo_nozstd
+
+package compress
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestZstdLite_RoundTripConcurrent(t *testing.T) {
+	codec := NewZstdCompressor()
+	data := bytes.Repeat([]byte("mebo lite zstd payload "), 4096)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			compressed, err := codec.Compress(data)
+			require.NoError(t, err)
+			require.Less(t, len(compressed), len(data))
+
+			decompressed, err := codec.Decompress(compressed)
+			require.NoError(t, err)
+			require.Equal(t, data, decompressed)
+		})
+	}
+	wg.Wait()
+
+	_, err := codec.Decompress([]byte("not zstd"))
+	require.Error(t, err)
+}
+
+func TestZstdLite_MatchesDeterministic(t *testing.T) {
+	data := bytes.Repeat([]byte("mebo lite zstd payload "), 4096)
+
+	lite, err := NewZstdCompressor().Compress(data)
+	require.NoError(t, err)
+	deterministic, err := NewDeterministicZstdCompressor().Compress(data)
+	require.NoError(t, err)
+	require.Equal(t, lite, deterministic)
+}
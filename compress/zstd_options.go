@@ -0,0 +1,23 @@
+//go:build !mebo_nozstd
+
+package compress
+
+import "github.com/klauspost/compress/zstd"
+
+// zstdFixedWindowSize bounds the encoder window of fixed-configuration encoders.
+// Blobs produced with a larger window (by pooled encoders) still decode, since
+// the decoder honors the window declared in each frame.
+const zstdFixedWindowSize = 1 << 20
+
+// zstdFixedEncoderOptions returns the single-threaded, low-memory encoder
+// configuration shared by the lite build and deterministic compressors, so both
+// produce the same frames for the same input.
+func zstdFixedEncoderOptions() []zstd.EOption {
+	return []zstd.EOption{
+		zstd.WithEncoderLevel(zstd.SpeedDefault),
+		zstd.WithEncoderCRC(false),
+		zstd.WithEncoderConcurrency(1),
+		zstd.WithLowerEncoderMem(true),
+		zstd.WithWindowSize(zstdFixedWindowSize),
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !mebo_nozstd
+
+package compress
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicZstdCompressor(t *testing.T) {
+	var data []byte
+	for i := range 200_000 {
+		data = fmt.Appendf(data, "metric=%d value=%d;", i%97, i*7%1013)
+	}
+	require.Greater(t, len(data), zstdFixedWindowSize)
+
+	codec := NewDeterministicZstdCompressor()
+	want, err := codec.Compress(data)
+	require.NoError(t, err)
+
+	reference, err := zstd.NewWriter(nil, zstdFixedEncoderOptions()...)
+	require.NoError(t, err)
+	require.Equal(t, reference.EncodeAll(data, nil), want)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			got, err := codec.Compress(data)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+	wg.Wait()
+
+	decompressed, err := NewZstdCompressor().Decompress(want)
+	require.NoError(t, err)
+	require.Equal(t, data, decompressed)
+}
@@ -1,3 +1,5 @@
+//go:build !mebo_lite && !tinygo && !mebo_nozstd
+
 package compress
 
 import (
@@ -43,6 +45,20 @@ var zstdEncoderPool = sync.Pool{
 	},
 }
 
+// zstdFixedEncoderPool pools encoders with the fixed configuration used by
+// deterministic compressors (see zstdFixedEncoderOptions).
+var zstdFixedEncoderPool = sync.Pool{
+	New: func() any {
+		encoder, err := zstd.NewWriter(nil, zstdFixedEncoderOptions()...)
+		if err != nil {
+			// This should never happen with valid options
+			panic(fmt.Sprintf("failed to create zstd encoder for pool: %v", err))
+		}
+
+		return encoder
+	},
+}
+
 // Compress compresses the input data using Zstandard compression.
 // Uses a pooled encoder for better performance (eliminates allocation overhead).
 func (c ZstdCompressor) Compress(data []byte) ([]byte, error) {
@@ -51,8 +67,12 @@ func (c ZstdCompressor) Compress(data []byte) ([]byte, error) {
 	}
 
 	// Get encoder from pool (reuses "warmed up" encoder)
-	encoder, _ := zstdEncoderPool.Get().(*zstd.Encoder)
-	defer zstdEncoderPool.Put(encoder)
+	pool := &zstdEncoderPool
+	if c.deterministic {
+		pool = &zstdFixedEncoderPool
+	}
+	encoder, _ := pool.Get().(*zstd.Encoder)
+	defer pool.Put(encoder)
 
 	// EncodeAll is stateless - safe to use with pooled encoder
 	compressed := encoder.EncodeAll(data, nil)
@@ -0,0 +1,67 @@
+package conformance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+)
+
+func TestGenerate(t *testing.T) {
+	vectors, err := Generate()
+	require.NoError(t, err)
+	require.Len(t, vectors, 3*4*4*2*2+5+2*4*2*2+1)
+
+	seen := make(map[string]bool, len(vectors))
+	for _, v := range vectors {
+		require.False(t, seen[v.Name], "duplicate vector name %s", v.Name)
+		seen[v.Name] = true
+
+		require.NoError(t, Verify(v), v.Name)
+	}
+
+	again, err := Generate()
+	require.NoError(t, err)
+	for i := range vectors {
+		require.Equal(t, vectors[i].Blob, again[i].Blob, "vector %s is not deterministic", vectors[i].Name)
+	}
+}
+
+func TestWriteDirLoadDir(t *testing.T) {
+	vectors, err := Generate()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, WriteDir(dir, vectors))
+
+	loaded, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, loaded, len(vectors))
+	for _, v := range loaded {
+		require.NoError(t, Verify(v), v.Name)
+	}
+}
+
+func TestVerifyJSON(t *testing.T) {
+	vectors, err := Generate()
+	require.NoError(t, err)
+	v := vectors[len(vectors)-1]
+
+	decoded, err := json.Marshal(v.Metrics)
+	require.NoError(t, err)
+	require.NoError(t, VerifyJSON(v, decoded))
+
+	tampered, err := Decode(v.Kind, v.Blob)
+	require.NoError(t, err)
+	tampered[0].Points[3].Tag = "wrong"
+	decoded, err = json.Marshal(tampered)
+	require.NoError(t, err)
+	require.ErrorIs(t, VerifyJSON(v, decoded), errs.ErrConformanceMismatch)
+
+	require.Error(t, VerifyJSON(v, []byte("{")))
+
+	_, err = Decode("unknown", v.Blob)
+	require.Error(t, err)
+}
@@ -0,0 +1,406 @@
+package conformance
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+// Default sizes of the random series generated by Differential.
+const (
+	DefaultDifferentialRounds    = 4
+	DefaultDifferentialMetrics   = 4
+	DefaultDifferentialMaxPoints = 200
+)
+
+// Combination is one encoder configuration exercised by Differential.
+type Combination struct {
+	// Kind is the blob type.
+	Kind Kind
+
+	// TimestampEncoding is the timestamp encoding.
+	TimestampEncoding format.EncodingType
+
+	// ValueEncoding is the value encoding of numeric blobs, ignored for text blobs.
+	ValueEncoding format.EncodingType
+
+	// Compression is the payload compression: timestamps and values of numeric
+	// blobs, the data section of text blobs.
+	Compression format.CompressionType
+
+	// Tags enables per-point tags.
+	Tags bool
+
+	// BigEndian selects big-endian byte order.
+	BigEndian bool
+}
+
+// Name returns the combination name, in the naming scheme of the vectors
+// returned by Generate.
+func (c Combination) Name() string {
+	if c.Kind == KindText {
+		return vectorName(c.Kind, c.TimestampEncoding.String(), c.Compression.String(), tagsLabel(c.Tags), endianLabel(c.BigEndian))
+	}
+
+	return vectorName(c.Kind, c.TimestampEncoding.String(), c.ValueEncoding.String(), c.Compression.String(), tagsLabel(c.Tags), endianLabel(c.BigEndian))
+}
+
+// Config returns the combination in the Config schema of the vectors.
+func (c Combination) Config() Config {
+	cfg := Config{
+		TimestampEncoding: c.TimestampEncoding.String(),
+		Tags:              c.Tags,
+		BigEndian:         c.BigEndian,
+	}
+	if c.Kind == KindText {
+		cfg.DataCompression = c.Compression.String()
+		return cfg
+	}
+
+	cfg.ValueEncoding = c.ValueEncoding.String()
+	cfg.TimestampCompression = c.Compression.String()
+	cfg.ValueCompression = c.Compression.String()
+
+	return cfg
+}
+
+// Combinations returns every encoding and compression combination supported by
+// the encoders: for numeric blobs each timestamp encoding (Raw, Delta,
+// DeltaPacked) with each value encoding (Raw, Gorilla, Chimp, ALP), and for text
+// blobs each timestamp encoding (Raw, Delta), each with every compression codec,
+// tags on/off and both byte orders.
+//
+// Returns:
+//   - []Combination: Combinations in a stable order with unique names
+func Combinations() []Combination {
+	var combos []Combination
+	for _, spec := range numericSpecs() {
+		if spec.names || spec.v2 || spec.unit != format.TimeUnitMicrosecond || spec.tagComp != format.CompressionZstd {
+			continue
+		}
+		combos = append(combos, Combination{
+			Kind:              KindNumeric,
+			TimestampEncoding: spec.tsEnc,
+			ValueEncoding:     spec.valEnc,
+			Compression:       spec.comp,
+			Tags:              spec.tags,
+			BigEndian:         spec.big,
+		})
+	}
+
+	for _, spec := range textSpecs() {
+		if spec.names {
+			continue
+		}
+		combos = append(combos, Combination{
+			Kind:              KindText,
+			TimestampEncoding: spec.tsEnc,
+			Compression:       spec.comp,
+			Tags:              spec.tags,
+			BigEndian:         spec.big,
+		})
+	}
+
+	return combos
+}
+
+// Encode encodes metrics with this package's writer using the combination.
+// It is the reference encoder Differential compares custom encoders against.
+//
+// Parameters:
+//   - metrics: Metrics to encode, each with at least one point; numeric points
+//     use ValueBits, text points use Text
+//
+// Returns:
+//   - []byte: Encoded blob
+//   - error: Encoding error
+func (c Combination) Encode(metrics []Metric) ([]byte, error) {
+	switch c.Kind {
+	case KindNumeric:
+		spec := numericSpec{tsEnc: c.TimestampEncoding, valEnc: c.ValueEncoding, comp: c.Compression, tagComp: format.CompressionZstd, tags: c.Tags, big: c.BigEndian}
+		return encodeNumeric(spec, metrics)
+	case KindText:
+		spec := textSpec{tsEnc: c.TimestampEncoding, comp: c.Compression, tags: c.Tags, big: c.BigEndian}
+		return encodeText(spec, metrics)
+	default:
+		return nil, fmt.Errorf("unknown vector kind %q", c.Kind)
+	}
+}
+
+// DifferentialOptions configures Differential.
+//
+// Zero values select the defaults: DefaultDifferentialRounds random series per
+// combination, each with DefaultDifferentialMetrics metrics of up to
+// DefaultDifferentialMaxPoints points, over every combination, using this
+// package's encoder and decoder.
+type DifferentialOptions struct {
+	// Seed makes the random series reproducible. A failure reports the seed and
+	// round that produced it.
+	Seed uint64
+
+	// Rounds is the number of random series encoded per combination.
+	Rounds int
+
+	// Metrics is the number of metrics per series.
+	Metrics int
+
+	// MaxPoints is the maximum number of points per metric, at most 65535.
+	MaxPoints int
+
+	// Combinations restricts the exercised combinations; nil means Combinations().
+	Combinations []Combination
+
+	// Encode is the encoder under test; nil means Combination.Encode. Its output
+	// must decode with this package's decoder.
+	Encode func(c Combination, metrics []Metric) ([]byte, error)
+
+	// Decode is the decoder under test; nil means Decode. It must decode the
+	// output of this package's encoder. Metrics may be returned in any order.
+	Decode func(kind Kind, data []byte) ([]Metric, error)
+}
+
+// Differential encodes random series with every combination and asserts that
+// they decode bit-exactly to the input.
+//
+// The series mix the shapes the encoders specialize in: regular and jittered
+// timestamps with repeats, counters and slowly drifting decimals for Gorilla and
+// ALP, random bit patterns including NaN payloads, infinities and signed zeros,
+// and empty, repeated and multi-byte text values.
+//
+// Downstream implementations prove compatibility by plugging in their encoder,
+// decoder, or both: each custom encoder output is decoded with this package's
+// decoder, and each custom decoder decodes this package's encoder output, so
+// both directions of the wire format are checked.
+//
+// Parameters:
+//   - opts: Series sizes, combinations and the implementations under test
+//
+// Returns:
+//   - error: nil if every round trip matches; an error naming the combination,
+//     seed and round, wrapping errs.ErrConformanceMismatch for a mismatch, or
+//     the encoding or decoding error
+//
+// Example:
+//
+//	func TestCustomEncoder(t *testing.T) {
+//	    err := conformance.Differential(conformance.DifferentialOptions{
+//	        Seed:   42,
+//	        Encode: myEncoder,
+//	    })
+//	    require.NoError(t, err)
+//	}
+func Differential(opts DifferentialOptions) error {
+	opts = opts.withDefaults()
+	if opts.MaxPoints > math.MaxUint16 {
+		return fmt.Errorf("%w: max points %d exceeds %d", errs.ErrInvalidNumOfDataPoints, opts.MaxPoints, math.MaxUint16)
+	}
+
+	combos := opts.Combinations
+	if combos == nil {
+		combos = Combinations()
+	}
+
+	for _, c := range combos {
+		for round := range opts.Rounds {
+			rng := rand.New(rand.NewPCG(opts.Seed, uint64(round))) //nolint: gosec
+			want := RandomMetrics(rng, c.Kind, c.Tags, opts.Metrics, opts.MaxPoints)
+
+			if err := opts.roundTrip(c, want); err != nil {
+				return fmt.Errorf("%s seed %d round %d: %w", c.Name(), opts.Seed, round, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// withDefaults fills in the zero fields of opts.
+func (opts DifferentialOptions) withDefaults() DifferentialOptions {
+	if opts.Rounds <= 0 {
+		opts.Rounds = DefaultDifferentialRounds
+	}
+	if opts.Metrics <= 0 {
+		opts.Metrics = DefaultDifferentialMetrics
+	}
+	if opts.MaxPoints <= 0 {
+		opts.MaxPoints = DefaultDifferentialMaxPoints
+	}
+
+	return opts
+}
+
+// roundTrip checks that want survives the custom encoder with the reference
+// decoder and, if a custom decoder is set, the reference encoder with the
+// custom decoder.
+func (opts DifferentialOptions) roundTrip(c Combination, want []Metric) error {
+	encode := opts.Encode
+	if encode == nil {
+		encode = Combination.Encode
+	}
+
+	if err := checkRoundTrip(c, want, encode, Decode); err != nil {
+		return err
+	}
+
+	if opts.Decode == nil {
+		return nil
+	}
+
+	return checkRoundTrip(c, want, Combination.Encode, opts.Decode)
+}
+
+// checkRoundTrip encodes want with encode, decodes it with decode and compares.
+func checkRoundTrip(c Combination, want []Metric, encode func(Combination, []Metric) ([]byte, error), decode func(Kind, []byte) ([]Metric, error)) error {
+	data, err := encode(c, want)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	got, err := decode(c.Kind, data)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	sortMetrics(got)
+
+	return Compare(want, got)
+}
+
+// RandomMetrics generates random metrics for Differential and fuzz tests.
+//
+// Each metric has a distinct random ID and between 1 and maxPoints points with
+// non-decreasing timestamps. Numeric metrics set ValueBits, text metrics set
+// Text, and every point carries a tag if tags is true.
+//
+// Parameters:
+//   - rng: Source of randomness
+//   - kind: KindNumeric or KindText
+//   - tags: Whether points carry tags
+//   - metrics: Number of metrics
+//   - maxPoints: Maximum number of points per metric
+//
+// Returns:
+//   - []Metric: Metrics sorted by ID
+func RandomMetrics(rng *rand.Rand, kind Kind, tags bool, metrics, maxPoints int) []Metric {
+	out := make([]Metric, 0, metrics)
+	seen := make(map[uint64]bool, metrics)
+	start := StartTime.UnixMicro()
+
+	for len(out) < metrics {
+		id := rng.Uint64()
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		points := make([]Point, 1+rng.IntN(maxPoints))
+		ts := start + rng.Int64N(int64(time.Minute/time.Microsecond))
+		step := randomStep(rng)
+		shape := rng.IntN(numericShapes)
+		for i := range points {
+			ts += randomDelta(rng, step)
+
+			p := Point{Ts: ts}
+			if kind == KindText {
+				p.Text = randomText(rng, i)
+			} else {
+				p.ValueBits = randomValueBits(rng, shape, i, points)
+			}
+			if tags {
+				p.Tag = randomTag(rng)
+			}
+			points[i] = p
+		}
+
+		out = append(out, Metric{ID: id, Points: points})
+	}
+
+	sortMetrics(out)
+
+	return out
+}
+
+// randomStep returns the nominal timestamp delta of a series in microseconds.
+func randomStep(rng *rand.Rand) int64 {
+	steps := []int64{1, 1000, 1_000_000, 15_000_000, 3_600_000_000}
+	return steps[rng.IntN(len(steps))]
+}
+
+// randomDelta returns the delta to the next timestamp: mostly the nominal step,
+// sometimes jittered, repeated or a large gap.
+func randomDelta(rng *rand.Rand, step int64) int64 {
+	switch r := rng.IntN(10); {
+	case r < 6:
+		return step
+	case r < 8:
+		return step + rng.Int64N(step/10+1)
+	case r < 9:
+		return 0
+	default:
+		return step * (2 + rng.Int64N(1000))
+	}
+}
+
+const numericShapes = 5
+
+// randomValueBits returns the bit pattern of point i of a numeric series of the
+// given shape.
+func randomValueBits(rng *rand.Rand, shape, i int, points []Point) uint64 {
+	prev := 0.0
+	if i > 0 {
+		prev = math.Float64frombits(points[i-1].ValueBits)
+	}
+
+	switch shape {
+	case 0: // Counter
+		return math.Float64bits(prev + float64(rng.IntN(100)))
+	case 1: // Decimal gauge with two fractional digits
+		return math.Float64bits(math.Round((50+rng.NormFloat64()*10)*100) / 100)
+	case 2: // Mostly constant
+		if i > 0 && rng.IntN(4) > 0 {
+			return points[i-1].ValueBits
+		}
+
+		return math.Float64bits(rng.Float64() * 1000)
+	case 3: // Arbitrary bit patterns, including NaN payloads and subnormals
+		return rng.Uint64()
+	default: // Special values mixed into a random walk
+		if rng.IntN(4) == 0 {
+			return math.Float64bits(specialValues[rng.IntN(len(specialValues))])
+		}
+
+		return math.Float64bits(prev + rng.NormFloat64())
+	}
+}
+
+// randomText returns a text value: repeated, empty, ASCII or multi-byte.
+func randomText(rng *rand.Rand, i int) string {
+	switch rng.IntN(4) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("state-%d", rng.IntN(3))
+	case 2:
+		return fmt.Sprintf("line %d: 温度=%d°C %s", i, rng.IntN(100), strings.Repeat("x", rng.IntN(64)))
+	default:
+		b := make([]byte, rng.IntN(32))
+		for j := range b {
+			b[j] = byte(' ' + rng.IntN('~'-' '+1)) //nolint: gosec
+		}
+
+		return string(b)
+	}
+}
+
+// randomTag returns a tag from a small set, occasionally empty.
+func randomTag(rng *rand.Rand) string {
+	if rng.IntN(5) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("host=%d", rng.IntN(4))
+}
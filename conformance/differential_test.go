@@ -0,0 +1,85 @@
+package conformance
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestCombinations(t *testing.T) {
+	combos := Combinations()
+	require.Len(t, combos, 3*4*4*2*2+2*4*2*2)
+
+	seen := make(map[string]bool, len(combos))
+	for _, c := range combos {
+		require.False(t, seen[c.Name()], "duplicate combination %s", c.Name())
+		seen[c.Name()] = true
+	}
+
+	c := Combination{Kind: KindNumeric, TimestampEncoding: format.TypeDelta, ValueEncoding: format.TypeGorilla, Compression: format.CompressionZstd, Tags: true}
+	require.Equal(t, "numeric-delta-gorilla-zstd-tags-le", c.Name())
+	require.Equal(t, "Gorilla", c.Config().ValueEncoding)
+}
+
+func TestDifferential(t *testing.T) {
+	require.NoError(t, Differential(DifferentialOptions{Seed: 1}))
+}
+
+func TestDifferential_CustomImplementations(t *testing.T) {
+	var encoded, decoded int
+	opts := DifferentialOptions{
+		Seed:   7,
+		Rounds: 2,
+		Encode: func(c Combination, metrics []Metric) ([]byte, error) {
+			encoded++
+			return c.Encode(metrics)
+		},
+		Decode: func(kind Kind, data []byte) ([]Metric, error) {
+			decoded++
+			return Decode(kind, data)
+		},
+	}
+	require.NoError(t, Differential(opts))
+	require.Equal(t, 2*len(Combinations()), encoded)
+	require.Equal(t, 2*len(Combinations()), decoded)
+
+	// A decoder that loses a point must be caught.
+	opts.Decode = func(kind Kind, data []byte) ([]Metric, error) {
+		metrics, err := Decode(kind, data)
+		if err != nil {
+			return nil, err
+		}
+		metrics[0].Points = metrics[0].Points[1:]
+
+		return metrics, nil
+	}
+	err := Differential(opts)
+	require.ErrorIs(t, err, errs.ErrConformanceMismatch)
+	require.Contains(t, err.Error(), "seed 7 round 0")
+
+	require.ErrorIs(t, Differential(DifferentialOptions{MaxPoints: 1 << 16}), errs.ErrInvalidNumOfDataPoints)
+}
+
+func TestRandomMetrics(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 0))
+	metrics := RandomMetrics(rng, KindText, true, 8, 50)
+	require.Len(t, metrics, 8)
+
+	for i, m := range metrics {
+		if i > 0 {
+			require.Less(t, metrics[i-1].ID, m.ID)
+		}
+		require.NotEmpty(t, m.Points)
+		require.LessOrEqual(t, len(m.Points), 50)
+		for j := 1; j < len(m.Points); j++ {
+			require.LessOrEqual(t, m.Points[j-1].Ts, m.Points[j].Ts)
+		}
+	}
+
+	again := RandomMetrics(rand.New(rand.NewPCG(3, 0)), KindText, true, 8, 50)
+	require.Equal(t, metrics, again)
+}
@@ -0,0 +1,75 @@
+// Package conformance provides canonical test vectors for the mebo binary format.
+//
+// Each Vector pairs an encoded blob with the exact data points it must decode to,
+// covering the combinations of timestamp/value encodings, compression codecs, tags,
+// endianness and optional layout features supported by this package. Vectors are
+// fully deterministic, so regenerating them with the same mebo version produces
+// byte-identical blobs.
+//
+// The package serves two audiences:
+//
+//   - Non-Go implementations (Rust, Python, ...) load the vectors written by WriteDir,
+//     decode every "<name>.mebo" file with their own reader, and compare against the
+//     expected points in "<name>.json".
+//   - Go test harnesses drive a foreign reader programmatically and check its output
+//     with VerifyJSON, or check this package's own decoder with Verify.
+//
+// # Expected Output Format
+//
+// Each "<name>.json" file holds a Vector: its name, kind ("numeric" or "text"), the
+// encoder configuration, and the expected metrics sorted by metric ID. Numeric values
+// are stored as their IEEE-754 bit pattern ("value_bits") so NaN payloads, -0 and
+// infinities survive the JSON round trip. Fields holding zero values are omitted.
+//
+//	{
+//	  "name": "numeric-delta-gorilla-zstd-tags-le",
+//	  "kind": "numeric",
+//	  "config": {"timestamp_encoding": "Delta", "value_encoding": "Gorilla", ...},
+//	  "metrics": [
+//	    {"id": 1, "points": [{"ts": 1704067200000000, "value_bits": 4607182418800017408, "tag": "host=0"}]}
+//	  ]
+//	}
+//
+// The decoded JSON a foreign reader hands to VerifyJSON uses the same schema as the
+// "metrics" array.
+//
+// # Usage
+//
+// Generating vectors for another implementation:
+//
+//	vectors, err := conformance.Generate()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if err := conformance.WriteDir("testdata/mebo-vectors", vectors); err != nil {
+//	    log.Fatal(err)
+//	}
+//
+// Verifying a foreign reader from a Go harness:
+//
+//	vectors, _ := conformance.LoadDir("testdata/mebo-vectors")
+//	for _, v := range vectors {
+//	    decoded := runForeignReader(v.Blob) // JSON array of metrics
+//	    if err := conformance.VerifyJSON(v, decoded); err != nil {
+//	        t.Errorf("%s: %v", v.Name, err)
+//	    }
+//	}
+//
+// # Differential Testing
+//
+// Differential complements the fixed vectors with random series: it encodes them
+// with every encoding and compression combination and asserts bit-exact round
+// trips. Teams embedding a custom encoder or decoder plug it into
+// DifferentialOptions to prove it interoperates with this package in both
+// directions:
+//
+//	err := conformance.Differential(conformance.DifferentialOptions{
+//	    Seed:   uint64(time.Now().UnixNano()),
+//	    Encode: func(c conformance.Combination, metrics []conformance.Metric) ([]byte, error) {
+//	        return myEncoder(c.Config(), metrics)
+//	    },
+//	})
+//	if err != nil {
+//	    t.Fatal(err) // names the combination, seed and round to reproduce
+//	}
+package conformance
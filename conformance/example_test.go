@@ -0,0 +1,41 @@
+package conformance_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arloliu/mebo/conformance"
+)
+
+func Example() {
+	vectors, err := conformance.Generate()
+	if err != nil {
+		panic(err)
+	}
+
+	dir, err := os.MkdirTemp("", "mebo-vectors")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := conformance.WriteDir(dir, vectors); err != nil {
+		panic(err)
+	}
+
+	loaded, err := conformance.LoadDir(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	failures := 0
+	for _, v := range loaded {
+		if err := conformance.Verify(v); err != nil {
+			failures++
+		}
+	}
+	fmt.Println("failures:", failures)
+
+	// Output:
+	// failures: 0
+}
@@ -0,0 +1,83 @@
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	blobExt     = ".mebo"
+	expectedExt = ".json"
+)
+
+// WriteDir writes each vector to dir as "<name>.mebo" (the encoded blob) and
+// "<name>.json" (the vector with its expected metrics). The directory is created
+// if needed; existing files with the same names are overwritten.
+//
+// Parameters:
+//   - dir: Output directory
+//   - vectors: Vectors to write, typically from Generate
+//
+// Returns:
+//   - error: File system or encoding error
+func WriteDir(dir string, vectors []Vector) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	for _, v := range vectors {
+		expected, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("vector %s: %w", v.Name, err)
+		}
+
+		if err := os.WriteFile(filepath.Join(dir, v.Name+blobExt), v.Blob, 0o644); err != nil { //nolint: gosec
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, v.Name+expectedExt), append(expected, '\n'), 0o644); err != nil { //nolint: gosec
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadDir reads the vectors written by WriteDir, sorted by name.
+//
+// Parameters:
+//   - dir: Directory containing "<name>.json" and "<name>.mebo" pairs
+//
+// Returns:
+//   - []Vector: Loaded vectors with Blob populated
+//   - error: File system or JSON error, or a JSON file without its blob
+func LoadDir(dir string) ([]Vector, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+expectedExt))
+	if err != nil {
+		return nil, err
+	}
+
+	vectors := make([]Vector, 0, len(paths))
+	for _, path := range paths {
+		expected, err := os.ReadFile(path) //nolint: gosec
+		if err != nil {
+			return nil, err
+		}
+
+		var v Vector
+		if err := json.Unmarshal(expected, &v); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		v.Blob, err = os.ReadFile(strings.TrimSuffix(path, expectedExt) + blobExt) //nolint: gosec
+		if err != nil {
+			return nil, err
+		}
+
+		vectors = append(vectors, v)
+	}
+
+	return vectors, nil
+}
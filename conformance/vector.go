@@ -0,0 +1,435 @@
+package conformance
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+const (
+	// KindNumeric identifies a vector holding a numeric blob.
+	KindNumeric Kind = "numeric"
+	// KindText identifies a vector holding a text blob.
+	KindText Kind = "text"
+)
+
+const (
+	vectorMetrics = 3
+	vectorPoints  = 16
+)
+
+// StartTime is the fixed blob start time used by every generated vector.
+var StartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// specialValues cycles through float64 values that stress bit-exact decoding.
+var specialValues = []float64{
+	0, math.Copysign(0, -1), 1.5, math.NaN(), math.Inf(1), math.Inf(-1),
+	math.SmallestNonzeroFloat64, math.MaxFloat64, -273.15, 1e-300,
+}
+
+// Kind identifies the blob type of a vector.
+type Kind string
+
+// Config describes the encoder configuration a vector was generated with.
+// Encodings, codecs and units use the names returned by their String methods.
+type Config struct {
+	TimestampEncoding    string `json:"timestamp_encoding"`
+	ValueEncoding        string `json:"value_encoding,omitempty"`
+	TimestampCompression string `json:"timestamp_compression,omitempty"`
+	ValueCompression     string `json:"value_compression,omitempty"`
+	TagCompression       string `json:"tag_compression,omitempty"`
+	DataCompression      string `json:"data_compression,omitempty"`
+	TimestampUnit        string `json:"timestamp_unit,omitempty"`
+	Tags                 bool   `json:"tags,omitempty"`
+	BigEndian            bool   `json:"big_endian,omitempty"`
+	MetricNames          bool   `json:"metric_names,omitempty"`
+	LayoutV2             bool   `json:"layout_v2,omitempty"`
+	SharedTimestamps     bool   `json:"shared_timestamps,omitempty"`
+}
+
+// Point is one expected data point.
+//
+// Numeric vectors set ValueBits to math.Float64bits of the value; text vectors set Text.
+// Tag is empty when tags are disabled.
+type Point struct {
+	Ts        int64  `json:"ts"`
+	ValueBits uint64 `json:"value_bits,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Tag       string `json:"tag,omitempty"`
+}
+
+// Metric holds the expected data points of one metric.
+// Name is set only when the blob stores metric names.
+type Metric struct {
+	ID     uint64  `json:"id"`
+	Name   string  `json:"name,omitempty"`
+	Points []Point `json:"points"`
+}
+
+// Vector is a canonical encoded blob together with its expected decoded content.
+type Vector struct {
+	Name    string   `json:"name"`
+	Kind    Kind     `json:"kind"`
+	Config  Config   `json:"config"`
+	Metrics []Metric `json:"metrics"`
+
+	// Blob is the encoded blob. It is stored next to the JSON file by WriteDir
+	// rather than inside it.
+	Blob []byte `json:"-"`
+}
+
+// numericSpec is the internal description of a numeric vector.
+type numericSpec struct {
+	name    string
+	tsEnc   format.EncodingType
+	valEnc  format.EncodingType
+	comp    format.CompressionType
+	tagComp format.CompressionType
+	unit    format.TimeUnit
+	tags    bool
+	big     bool
+	names   bool
+	v2      bool
+	shared  bool
+}
+
+// textSpec is the internal description of a text vector.
+type textSpec struct {
+	name  string
+	tsEnc format.EncodingType
+	comp  format.CompressionType
+	tags  bool
+	big   bool
+	names bool
+}
+
+// Generate returns the canonical conformance vectors.
+//
+// Numeric vectors cover every combination of timestamp encoding (Raw, Delta,
+// DeltaPacked), value encoding (Raw, Gorilla, Chimp, ALP), payload compression
+// (None, Zstd, S2, LZ4), tags on/off and endianness, plus vectors for metric names,
+// the V2 layout with shared timestamps, non-default timestamp units and tag
+// compression. Text vectors cover every combination of timestamp encoding (Raw,
+// Delta), data compression, tags on/off and endianness, plus metric names.
+//
+// Returns:
+//   - []Vector: Vectors in a stable order with unique names
+//   - error: Encoding error (indicates a bug in the encoder)
+func Generate() ([]Vector, error) {
+	var vectors []Vector
+
+	for _, spec := range numericSpecs() {
+		v, err := generateNumeric(spec)
+		if err != nil {
+			return nil, fmt.Errorf("vector %s: %w", spec.name, err)
+		}
+		vectors = append(vectors, v)
+	}
+
+	for _, spec := range textSpecs() {
+		v, err := generateText(spec)
+		if err != nil {
+			return nil, fmt.Errorf("vector %s: %w", spec.name, err)
+		}
+		vectors = append(vectors, v)
+	}
+
+	return vectors, nil
+}
+
+func numericSpecs() []numericSpec {
+	tsEncs := []format.EncodingType{format.TypeRaw, format.TypeDelta, format.TypeDeltaPacked}
+	valEncs := []format.EncodingType{format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP}
+
+	var specs []numericSpec
+	for _, tsEnc := range tsEncs {
+		for _, valEnc := range valEncs {
+			for _, comp := range compressions() {
+				for _, tags := range []bool{false, true} {
+					for _, big := range []bool{false, true} {
+						spec := numericSpec{tsEnc: tsEnc, valEnc: valEnc, comp: comp, tagComp: format.CompressionZstd, tags: tags, big: big}
+						spec.name = vectorName(KindNumeric, tsEnc.String(), valEnc.String(), comp.String(), tagsLabel(tags), endianLabel(big))
+						specs = append(specs, spec)
+					}
+				}
+			}
+		}
+	}
+
+	base := numericSpec{tsEnc: format.TypeDelta, valEnc: format.TypeGorilla, comp: format.CompressionNone, tagComp: format.CompressionZstd}
+
+	names := base
+	names.name, names.names, names.tags = "numeric-metric-names", true, true
+
+	shared := base
+	shared.name, shared.v2, shared.shared = "numeric-v2-shared-timestamps", true, true
+
+	sharedNames := shared
+	sharedNames.name, sharedNames.names, sharedNames.big = "numeric-v2-shared-timestamps-names-be", true, true
+
+	unit := base
+	unit.name, unit.unit = "numeric-timestamp-unit-ms", format.TimeUnitMillisecond
+
+	tagComp := base
+	tagComp.name, tagComp.tags, tagComp.tagComp = "numeric-tag-compression-lz4", true, format.CompressionLZ4
+
+	return append(specs, names, shared, sharedNames, unit, tagComp)
+}
+
+func textSpecs() []textSpec {
+	var specs []textSpec
+	for _, tsEnc := range []format.EncodingType{format.TypeRaw, format.TypeDelta} {
+		for _, comp := range compressions() {
+			for _, tags := range []bool{false, true} {
+				for _, big := range []bool{false, true} {
+					specs = append(specs, textSpec{
+						name:  vectorName(KindText, tsEnc.String(), comp.String(), tagsLabel(tags), endianLabel(big)),
+						tsEnc: tsEnc,
+						comp:  comp,
+						tags:  tags,
+						big:   big,
+					})
+				}
+			}
+		}
+	}
+
+	return append(specs, textSpec{name: "text-metric-names", tsEnc: format.TypeDelta, comp: format.CompressionZstd, tags: true, names: true})
+}
+
+func generateNumeric(spec numericSpec) (Vector, error) {
+	metrics := expectedMetrics(KindNumeric, spec.tags, spec.names, spec.unit)
+
+	data, err := encodeNumeric(spec, metrics)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	// Numeric blobs persist metric names only when metric IDs collide.
+	for i := range metrics {
+		metrics[i].Name = ""
+	}
+
+	cfg := Config{
+		TimestampEncoding:    spec.tsEnc.String(),
+		ValueEncoding:        spec.valEnc.String(),
+		TimestampCompression: spec.comp.String(),
+		ValueCompression:     spec.comp.String(),
+		Tags:                 spec.tags,
+		BigEndian:            spec.big,
+		MetricNames:          spec.names,
+		LayoutV2:             spec.v2,
+		SharedTimestamps:     spec.shared,
+	}
+	if spec.tags {
+		cfg.TagCompression = spec.tagComp.String()
+	}
+	if spec.unit != format.TimeUnitMicrosecond {
+		cfg.TimestampUnit = spec.unit.String()
+	}
+
+	return Vector{Name: spec.name, Kind: KindNumeric, Config: cfg, Metrics: metrics, Blob: data}, nil
+}
+
+func generateText(spec textSpec) (Vector, error) {
+	metrics := expectedMetrics(KindText, spec.tags, spec.names, format.TimeUnitMicrosecond)
+
+	data, err := encodeText(spec, metrics)
+	if err != nil {
+		return Vector{}, err
+	}
+
+	cfg := Config{
+		TimestampEncoding: spec.tsEnc.String(),
+		DataCompression:   spec.comp.String(),
+		Tags:              spec.tags,
+		BigEndian:         spec.big,
+		MetricNames:       spec.names,
+	}
+
+	return Vector{Name: spec.name, Kind: KindText, Config: cfg, Metrics: metrics, Blob: data}, nil
+}
+
+// encodeNumeric encodes metrics into a numeric blob configured by spec,
+// starting metrics by name if spec.names is set.
+func encodeNumeric(spec numericSpec, metrics []Metric) ([]byte, error) {
+	opts := []blob.NumericEncoderOption{
+		blob.WithTimestampEncoding(spec.tsEnc),
+		blob.WithValueEncoding(spec.valEnc),
+		blob.WithTimestampCompression(spec.comp),
+		blob.WithValueCompression(spec.comp),
+		blob.WithTagsEnabled(spec.tags),
+		blob.WithTagCompression(spec.tagComp),
+		blob.WithTimestampUnit(spec.unit),
+	}
+	if spec.big {
+		opts = append(opts, blob.WithBigEndian())
+	}
+	if spec.v2 {
+		opts = append(opts, blob.WithBlobLayoutV2())
+	}
+	if spec.shared {
+		opts = append(opts, blob.WithSharedTimestamps())
+	}
+
+	encoder, err := blob.NewNumericEncoder(StartTime, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		if spec.names {
+			err = encoder.StartMetricName(m.Name, len(m.Points))
+		} else {
+			err = encoder.StartMetricID(m.ID, len(m.Points))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range m.Points {
+			if err = encoder.AddDataPoint(p.Ts, math.Float64frombits(p.ValueBits), p.Tag); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// encodeText encodes metrics into a text blob configured by spec, starting
+// metrics by name if spec.names is set.
+func encodeText(spec textSpec, metrics []Metric) ([]byte, error) {
+	opts := []blob.TextEncoderOption{
+		blob.WithTextTimestampEncoding(spec.tsEnc),
+		blob.WithTextDataCompression(spec.comp),
+		blob.WithTextTagsEnabled(spec.tags),
+	}
+	if spec.big {
+		opts = append(opts, blob.WithTextBigEndian())
+	}
+
+	encoder, err := blob.NewTextEncoder(StartTime, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		if spec.names {
+			err = encoder.StartMetricName(m.Name, len(m.Points))
+		} else {
+			err = encoder.StartMetricID(m.ID, len(m.Points))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range m.Points {
+			if err = encoder.AddDataPoint(p.Ts, p.Text, p.Tag); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// expectedMetrics builds the deterministic input data shared by all vectors of a kind.
+// Metrics are returned sorted by ID, matching the order Decode produces.
+func expectedMetrics(kind Kind, tags, names bool, unit format.TimeUnit) []Metric {
+	start := unit.Timestamp(StartTime)
+	step := format.TimeUnitMicrosecond.Convert(int64(time.Second/time.Microsecond), unit)
+
+	metrics := make([]Metric, 0, vectorMetrics)
+	for m := range vectorMetrics {
+		metric := Metric{ID: uint64(m + 1)} //nolint: gosec
+		if names {
+			metric.Name = fmt.Sprintf("conformance.metric.%d", m)
+			metric.ID = hash.ID(metric.Name)
+		}
+
+		metric.Points = make([]Point, vectorPoints)
+		for i := range vectorPoints {
+			// Identical timestamps across metrics keep shared timestamp tables effective;
+			// the jitter exercises non-constant deltas.
+			p := Point{Ts: start + int64(i)*step + int64(i*37%5)*step/100}
+			if kind == KindNumeric {
+				p.ValueBits = math.Float64bits(numericValue(m, i))
+			} else {
+				p.Text = textValue(m, i)
+			}
+			if tags {
+				p.Tag = fmt.Sprintf("host=%d", (i+m)%3)
+			}
+			metric.Points[i] = p
+		}
+		metrics = append(metrics, metric)
+	}
+
+	sortMetrics(metrics)
+
+	return metrics
+}
+
+// numericValue returns the value of point i of metric m: a counter, a decimal
+// series and a cycle of special values.
+func numericValue(m, i int) float64 {
+	switch m {
+	case 0:
+		return float64(i * 10)
+	case 1:
+		return 20.5 + float64(i%4)*0.25 - float64(i%3)*1.125
+	default:
+		return specialValues[i%len(specialValues)]
+	}
+}
+
+// textValue returns the value of point i of metric m, including empty and
+// multi-byte UTF-8 strings.
+func textValue(m, i int) string {
+	switch m {
+	case 0:
+		return fmt.Sprintf("status-%d", i%4)
+	case 1:
+		return strings.Repeat("é", i%5)
+	default:
+		return fmt.Sprintf("log line %d: 温度=%d°C", i, 20+i)
+	}
+}
+
+func compressions() []format.CompressionType {
+	return []format.CompressionType{format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4}
+}
+
+func vectorName(kind Kind, parts ...string) string {
+	return strings.ToLower(string(kind) + "-" + strings.Join(parts, "-"))
+}
+
+func tagsLabel(tags bool) string {
+	if tags {
+		return "tags"
+	}
+
+	return "notags"
+}
+
+func endianLabel(big bool) string {
+	if big {
+		return "be"
+	}
+
+	return "le"
+}
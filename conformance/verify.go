@@ -0,0 +1,171 @@
+package conformance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// Decode decodes a blob with this package's reader into the vector schema.
+//
+// Metrics are sorted by ID. Metric names are filled in when the blob stores them.
+//
+// Parameters:
+//   - kind: Blob type (KindNumeric or KindText)
+//   - data: Encoded blob
+//
+// Returns:
+//   - []Metric: Decoded metrics in the same schema as Vector.Metrics
+//   - error: Decoding error, or an error for an unknown kind
+func Decode(kind Kind, data []byte) ([]Metric, error) {
+	switch kind {
+	case KindNumeric:
+		decoder, err := blob.NewNumericDecoder(data)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decoder.Decode()
+		if err != nil {
+			return nil, err
+		}
+
+		names := namesByID(b.MetricNames())
+		metrics := make([]Metric, 0, b.MetricCount())
+		for _, id := range b.MetricIDs() {
+			metric := Metric{ID: id, Name: names[id], Points: make([]Point, 0, b.Len(id))}
+			for _, dp := range b.All(id) {
+				metric.Points = append(metric.Points, Point{Ts: dp.Ts, ValueBits: math.Float64bits(dp.Val), Tag: dp.Tag})
+			}
+			metrics = append(metrics, metric)
+		}
+		sortMetrics(metrics)
+
+		return metrics, nil
+	case KindText:
+		decoder, err := blob.NewTextDecoder(data)
+		if err != nil {
+			return nil, err
+		}
+		b, err := decoder.Decode()
+		if err != nil {
+			return nil, err
+		}
+
+		names := namesByID(b.MetricNames())
+		metrics := make([]Metric, 0, b.MetricCount())
+		for _, id := range b.MetricIDs() {
+			metric := Metric{ID: id, Name: names[id], Points: make([]Point, 0, b.Len(id))}
+			for _, dp := range b.All(id) {
+				metric.Points = append(metric.Points, Point{Ts: dp.Ts, Text: dp.Val, Tag: dp.Tag})
+			}
+			metrics = append(metrics, metric)
+		}
+		sortMetrics(metrics)
+
+		return metrics, nil
+	default:
+		return nil, fmt.Errorf("unknown vector kind %q", kind)
+	}
+}
+
+// Verify decodes the vector's blob with this package's reader and compares the
+// result against the expected metrics.
+//
+// Returns:
+//   - error: nil on match; a decoding error; or an error wrapping
+//     errs.ErrConformanceMismatch describing the first difference
+func Verify(v Vector) error {
+	got, err := Decode(v.Kind, v.Blob)
+	if err != nil {
+		return err
+	}
+
+	return Compare(v.Metrics, got)
+}
+
+// VerifyJSON compares the decoded output of a foreign reader against the vector.
+//
+// Parameters:
+//   - v: The vector whose blob was decoded
+//   - decoded: JSON array of metrics in the Vector.Metrics schema; metric order is
+//     not significant
+//
+// Returns:
+//   - error: nil on match; a JSON error; or an error wrapping
+//     errs.ErrConformanceMismatch describing the first difference
+func VerifyJSON(v Vector, decoded []byte) error {
+	var got []Metric
+	if err := json.Unmarshal(decoded, &got); err != nil {
+		return fmt.Errorf("invalid decoded JSON: %w", err)
+	}
+	sortMetrics(got)
+
+	return Compare(v.Metrics, got)
+}
+
+// Compare reports the first difference between expected and decoded metrics.
+//
+// Both slices must be sorted by metric ID. Values are compared bit-for-bit, so
+// NaN payloads and the sign of zero must be preserved.
+//
+// Returns:
+//   - error: nil on match, otherwise an error wrapping errs.ErrConformanceMismatch
+func Compare(want, got []Metric) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("%w: metric count %d, want %d", errs.ErrConformanceMismatch, len(got), len(want))
+	}
+
+	for i := range want {
+		w, g := want[i], got[i]
+		if w.ID != g.ID {
+			return fmt.Errorf("%w: metric #%d has ID %d, want %d", errs.ErrConformanceMismatch, i, g.ID, w.ID)
+		}
+		if w.Name != g.Name {
+			return fmt.Errorf("%w: metric %d has name %q, want %q", errs.ErrConformanceMismatch, w.ID, g.Name, w.Name)
+		}
+		if len(w.Points) != len(g.Points) {
+			return fmt.Errorf("%w: metric %d has %d points, want %d", errs.ErrConformanceMismatch, w.ID, len(g.Points), len(w.Points))
+		}
+
+		for j := range w.Points {
+			if w.Points[j] != g.Points[j] {
+				return fmt.Errorf("%w: metric %d point %d is %+v, want %+v", errs.ErrConformanceMismatch, w.ID, j, g.Points[j], w.Points[j])
+			}
+		}
+	}
+
+	return nil
+}
+
+// namesByID maps metric IDs to the metric names stored in a blob.
+func namesByID(names []string) map[uint64]string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint64]string, len(names))
+	for _, name := range names {
+		byID[hash.ID(name)] = name
+	}
+
+	return byID
+}
+
+// sortMetrics sorts metrics by ID.
+func sortMetrics(metrics []Metric) {
+	slices.SortFunc(metrics, func(a, b Metric) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package datagen
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// DefaultStart is the start time of generated series when Config.Start is zero.
+var DefaultStart = time.Unix(1700000000, 0).UTC()
+
+// Config configures Generate.
+type Config struct {
+	// NumMetrics is the number of series to generate.
+	NumMetrics int
+
+	// Points is the number of data points per series.
+	Points int
+
+	// Start is the reference time; the first data point follows it by one
+	// interval. Zero selects DefaultStart.
+	Start time.Time
+
+	// Interval is the spacing of data points. Zero selects one second.
+	Interval time.Duration
+
+	// TimestampJitter is the deviation of each interval as a percentage of the
+	// interval, e.g. 2.0 = ±2%. Jitter accumulates, like real scrape drift.
+	TimestampJitter float64
+
+	// Values returns the shape of the series with the given index in
+	// [0, NumMetrics). Nil selects a random walk starting at 100 + 10*index
+	// with steps of up to ±5%, the default of the tests/measure tool.
+	Values func(metric int) Shape
+
+	// Seed makes the generated data reproducible.
+	Seed uint64
+}
+
+// Series is one generated metric.
+type Series struct {
+	// Name is the metric name, "metric.<index>".
+	Name string
+
+	// MetricID is the ID mebo derives from Name.
+	MetricID uint64
+
+	// Timestamps are the data point timestamps in microseconds.
+	Timestamps []int64
+
+	// Values are the data point values, one per timestamp.
+	Values []float64
+}
+
+// Generate creates cfg.NumMetrics series with cfg.Points data points each.
+//
+// Each series draws its timestamps and values from separate random streams
+// seeded by cfg.Seed and the series index, so results are reproducible and the
+// timestamps do not depend on the value shape.
+//
+// Parameters:
+//   - cfg: Generator configuration
+//
+// Returns:
+//   - []Series: The generated series, in index order
+//
+// Example:
+//
+//	series := datagen.Generate(datagen.Config{NumMetrics: 200, Points: 100, TimestampJitter: 2.0, Seed: 42})
+func Generate(cfg Config) []Series {
+	start := cfg.Start
+	if start.IsZero() {
+		start = DefaultStart
+	}
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = time.Second
+	}
+	points := max(cfg.Points, 0)
+	jitter := cfg.TimestampJitter / 100.0
+
+	series := make([]Series, 0, max(cfg.NumMetrics, 0))
+	for i := range max(cfg.NumMetrics, 0) {
+		name := fmt.Sprintf("metric.%d", i)
+		s := Series{
+			Name:       name,
+			MetricID:   hash.ID(name),
+			Timestamps: make([]int64, points),
+			Values:     make([]float64, points),
+		}
+
+		tsRand := rand.New(rand.NewPCG(cfg.Seed, uint64(2*i)))    //nolint: gosec // reproducible test data
+		valRand := rand.New(rand.NewPCG(cfg.Seed, uint64(2*i+1))) //nolint: gosec // reproducible test data
+
+		current := start
+		for j := range s.Timestamps {
+			factor := (tsRand.Float64()*2.0 - 1.0) * jitter
+			current = current.Add(interval + time.Duration(float64(interval)*factor))
+			s.Timestamps[j] = current.UnixMicro()
+		}
+
+		shape := defaultShape(i)
+		if cfg.Values != nil {
+			shape = cfg.Values(i)
+		}
+		shape.Fill(s.Values, valRand)
+
+		series = append(series, s)
+	}
+
+	return series
+}
+
+// Encode encodes the series into a numeric blob, by metric name.
+//
+// Parameters:
+//   - series: Series to encode, e.g. from Generate
+//   - start: Start time of the blob
+//   - opts: Encoder options
+//
+// Returns:
+//   - []byte: The encoded blob
+//   - error: Encoder errors, e.g. for more data points per series than the
+//     selected encodings support
+//
+// Example:
+//
+//	data, err := datagen.Encode(datagen.Generate(cfg), datagen.DefaultStart, blob.WithTimestampEncoding(format.TypeDelta))
+func Encode(series []Series, start time.Time, opts ...blob.NumericEncoderOption) ([]byte, error) {
+	encoder, err := blob.NewNumericEncoder(start, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range series {
+		if err := encoder.StartMetricName(s.Name, len(s.Timestamps)); err != nil {
+			return nil, fmt.Errorf("failed to start series %s: %w", s.Name, err)
+		}
+		if err := encoder.AddDataPoints(s.Timestamps, s.Values, nil); err != nil {
+			return nil, fmt.Errorf("failed to add data points of series %s: %w", s.Name, err)
+		}
+		if err := encoder.EndMetric(); err != nil {
+			return nil, fmt.Errorf("failed to end series %s: %w", s.Name, err)
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// defaultShape returns the random walk of the tests/measure tool for the series index.
+func defaultShape(metric int) Shape {
+	return RandomWalk(100.0+float64(metric)*10.0, 5.0)
+}
@@ -0,0 +1,61 @@
+package datagen
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := Config{NumMetrics: 3, Points: 50, TimestampJitter: 2.0, Seed: 42}
+	series := Generate(cfg)
+	require.Len(t, series, 3)
+	require.Equal(t, series, Generate(cfg))
+
+	for i, s := range series {
+		require.Equal(t, hash.ID(s.Name), s.MetricID)
+		require.Len(t, s.Values, 50)
+		require.InDelta(t, 100+10*float64(i), s.Values[0], (100+10*float64(i))*0.05)
+
+		prev := DefaultStart.UnixMicro()
+		for _, ts := range s.Timestamps {
+			require.InDelta(t, time.Second.Microseconds(), ts-prev, 0.02*float64(time.Second.Microseconds()))
+			prev = ts
+		}
+	}
+
+	// Changing the value shape keeps the timestamps.
+	cfg.Values = func(int) Shape { return Constant(1) }
+	constant := Generate(cfg)
+	require.Equal(t, series[1].Timestamps, constant[1].Timestamps)
+	require.Equal(t, []float64{1, 1}, constant[1].Values[:2])
+
+	cfg.Seed = 43
+	require.NotEqual(t, series[0].Timestamps, Generate(cfg)[0].Timestamps)
+	require.Empty(t, Generate(Config{NumMetrics: -1}))
+}
+
+func TestEncode(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	series := Generate(Config{NumMetrics: 2, Points: 10, Start: start, Interval: time.Minute, Seed: 1})
+
+	data, err := Encode(series, start, blob.WithSketches(true))
+	require.NoError(t, err)
+
+	decoder, err := blob.NewNumericDecoder(data)
+	require.NoError(t, err)
+	b, err := decoder.Decode()
+	require.NoError(t, err)
+
+	for _, s := range series {
+		require.Equal(t, s.Values, slices.Collect(b.AllValuesByName(s.Name)))
+	}
+
+	_, err = Encode([]Series{{Name: "bad", Timestamps: []int64{1}}}, start)
+	require.Error(t, err)
+}
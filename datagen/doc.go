@@ -0,0 +1,29 @@
+// Package datagen generates deterministic synthetic time series for benchmarks,
+// demos and capacity modeling.
+//
+// Values are described by composable shapes: a random walk drifting like the
+// values of the tests/measure tool, seasonal waves, spikes, step functions,
+// noise and trends, combined with Sum and rounded with Round. Timestamps follow
+// a fixed interval with optional jitter. Every metric draws timestamps and
+// values from its own random streams derived from Config.Seed, so the same
+// configuration always yields the same data, and changing the value shape does
+// not change the timestamps.
+//
+//	series := datagen.Generate(datagen.Config{
+//	    NumMetrics:      100,
+//	    Points:          3600,
+//	    TimestampJitter: 2.0,
+//	    Seed:            42,
+//	    Values: func(metric int) datagen.Shape {
+//	        // Daily load curve with noise and occasional spikes
+//	        return datagen.Round(datagen.Sum(
+//	            datagen.Constant(50),
+//	            datagen.Seasonal(20, 86400, 0),
+//	            datagen.Noise(2),
+//	            datagen.Spikes(0.001, 40),
+//	        ), 1)
+//	    },
+//	})
+//
+//	data, err := datagen.Encode(series, time.Unix(1700000000, 0), blob.WithValueEncoding(format.TypeChimp))
+package datagen
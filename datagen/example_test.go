@@ -0,0 +1,27 @@
+package datagen_test
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/datagen"
+)
+
+func Example() {
+	series := datagen.Generate(datagen.Config{
+		NumMetrics: 2,
+		Points:     5,
+		Seed:       42,
+		Values: func(metric int) datagen.Shape {
+			// A trend per metric with a small wave on top, at 1 decimal
+			return datagen.Round(datagen.Sum(datagen.Linear(10*float64(metric), 1), datagen.Seasonal(0.5, 4, 1)), 1)
+		},
+	})
+
+	for _, s := range series {
+		fmt.Println(s.Name, s.Values)
+	}
+
+	// Output:
+	// metric.0 [0.5 1 1.5 3 4.5]
+	// metric.1 [10.5 11 11.5 13 14.5]
+}
@@ -0,0 +1,169 @@
+package datagen
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// Shape generates the values of one series.
+//
+// Implementations must draw all randomness from r, so a shape yields the same
+// values for the same random stream. Shapes are descriptions without state:
+// one Shape may generate any number of series.
+type Shape interface {
+	// Fill writes len(dst) consecutive values into dst.
+	Fill(dst []float64, r *rand.Rand)
+}
+
+// ShapeFunc adapts a function to the Shape interface.
+type ShapeFunc func(dst []float64, r *rand.Rand)
+
+// Fill calls f(dst, r).
+func (f ShapeFunc) Fill(dst []float64, r *rand.Rand) {
+	f(dst, r)
+}
+
+// Constant returns a shape with every value equal to v.
+func Constant(v float64) Shape {
+	return ShapeFunc(func(dst []float64, _ *rand.Rand) {
+		for i := range dst {
+			dst[i] = v
+		}
+	})
+}
+
+// Linear returns a trend starting at start and changing by slope per data point.
+func Linear(start, slope float64) Shape {
+	return ShapeFunc(func(dst []float64, _ *rand.Rand) {
+		for i := range dst {
+			dst[i] = start + slope*float64(i)
+		}
+	})
+}
+
+// RandomWalk returns values drifting from start by a random step of up to
+// ±stepPercent of the current value per data point, like gauges of typical
+// monitoring metrics. This is the value model of the tests/measure tool.
+//
+// Parameters:
+//   - start: Value before the first step
+//   - stepPercent: Maximum step as a percentage of the current value, e.g. 5.0 = ±5%
+//
+// Returns:
+//   - Shape: The random walk
+func RandomWalk(start, stepPercent float64) Shape {
+	factor := stepPercent / 100.0
+
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		v := start
+		for i := range dst {
+			v += v * (r.Float64()*2.0 - 1.0) * factor
+			dst[i] = v
+		}
+	})
+}
+
+// Seasonal returns a sine wave oscillating around 0, e.g. a daily load cycle.
+//
+// Parameters:
+//   - amplitude: Peak deviation from 0
+//   - period: Length of one cycle in data points (values <= 0 yield zeros)
+//   - phase: Offset of the cycle in data points
+//
+// Returns:
+//   - Shape: The wave
+func Seasonal(amplitude float64, period, phase int) Shape {
+	if period <= 0 {
+		return Constant(0)
+	}
+
+	return ShapeFunc(func(dst []float64, _ *rand.Rand) {
+		for i := range dst {
+			dst[i] = amplitude * math.Sin(2*math.Pi*float64(i+phase)/float64(period))
+		}
+	})
+}
+
+// Spikes returns zeros with occasional spikes, e.g. latency outliers. Added to
+// a baseline with Sum.
+//
+// Parameters:
+//   - probability: Chance of a spike at each data point, in [0, 1]
+//   - height: Mean spike height; each spike is uniformly drawn from [0.5, 1.5) * height
+//
+// Returns:
+//   - Shape: The spikes
+func Spikes(probability, height float64) Shape {
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		for i := range dst {
+			dst[i] = 0
+			if r.Float64() < probability {
+				dst[i] = height * (0.5 + r.Float64())
+			}
+		}
+	})
+}
+
+// Steps returns a step function holding a random level drawn uniformly from
+// [low, high) for every data points before jumping to the next level, e.g.
+// replica counts or configuration values.
+//
+// Parameters:
+//   - every: Number of data points per level (values < 1 are treated as 1)
+//   - low: Inclusive lower bound of the levels
+//   - high: Exclusive upper bound of the levels
+//
+// Returns:
+//   - Shape: The step function
+func Steps(every int, low, high float64) Shape {
+	every = max(every, 1)
+
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		var level float64
+		for i := range dst {
+			if i%every == 0 {
+				level = low + r.Float64()*(high-low)
+			}
+			dst[i] = level
+		}
+	})
+}
+
+// Noise returns normally distributed values with mean 0 and the given
+// standard deviation.
+func Noise(stddev float64) Shape {
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		for i := range dst {
+			dst[i] = r.NormFloat64() * stddev
+		}
+	})
+}
+
+// Sum returns the point-wise sum of the shapes, e.g. a baseline plus a season
+// plus noise. The shapes draw from the random stream in argument order.
+func Sum(shapes ...Shape) Shape {
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		clear(dst)
+		scratch := make([]float64, len(dst))
+		for _, s := range shapes {
+			s.Fill(scratch, r)
+			for i, v := range scratch {
+				dst[i] += v
+			}
+		}
+	})
+}
+
+// Round returns the values of shape rounded to the given number of decimal
+// digits, like sensors reporting fixed precision. Rounded values compress far
+// better than full-precision ones, which matters for capacity modeling.
+func Round(shape Shape, decimals int) Shape {
+	scale := math.Pow(10, float64(decimals))
+
+	return ShapeFunc(func(dst []float64, r *rand.Rand) {
+		shape.Fill(dst, r)
+		for i, v := range dst {
+			dst[i] = math.Round(v*scale) / scale
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package datagen
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fill(shape Shape, n int) []float64 {
+	dst := make([]float64, n)
+	shape.Fill(dst, rand.New(rand.NewPCG(1, 2))) //nolint: gosec
+
+	return dst
+}
+
+func TestShapes(t *testing.T) {
+	require.Equal(t, []float64{3, 3, 3}, fill(Constant(3), 3))
+	require.Equal(t, []float64{1, 1.5, 2}, fill(Linear(1, 0.5), 3))
+	require.Equal(t, []float64{0, 0}, fill(Seasonal(5, 0, 0), 2))
+
+	wave := fill(Seasonal(10, 4, 0), 5)
+	require.InDeltaSlice(t, []float64{0, 10, 0, -10, 0}, wave, 1e-9)
+
+	walk := fill(RandomWalk(100, 5), 1000)
+	prev := 100.0
+	for _, v := range walk {
+		require.InDelta(t, prev, v, prev*0.05+1e-9)
+		prev = v
+	}
+	require.Equal(t, walk, fill(RandomWalk(100, 5), 1000), "shapes are deterministic")
+
+	steps := fill(Steps(10, 2, 4), 30)
+	for i, v := range steps {
+		require.Equal(t, steps[i/10*10], v)
+		require.True(t, v >= 2 && v < 4)
+	}
+
+	spikes := fill(Spikes(0.1, 100), 10000)
+	count := 0
+	for _, v := range spikes {
+		if v != 0 {
+			count++
+			require.True(t, v >= 50 && v < 150)
+		}
+	}
+	require.InDelta(t, 1000, count, 150)
+}
+
+func TestComposition(t *testing.T) {
+	sum := fill(Sum(Constant(50), Linear(0, 1), Noise(0)), 3)
+	require.Equal(t, []float64{50, 51, 52}, sum)
+
+	rounded := fill(Round(Sum(Constant(1), Noise(1)), 1), 100)
+	for _, v := range rounded {
+		require.Equal(t, math.Round(v*10)/10, v)
+	}
+
+	custom := ShapeFunc(func(dst []float64, _ *rand.Rand) {
+		for i := range dst {
+			dst[i] = float64(i * i)
+		}
+	})
+	require.Equal(t, []float64{1, 2, 5}, fill(Sum(custom, Constant(1)), 3))
+}
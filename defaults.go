@@ -0,0 +1,139 @@
+package mebo
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+var (
+	// userNumericDefaults holds the process-wide numeric encoder options set by
+	// SetDefaultEncoderOptions, nil if unset.
+	userNumericDefaults atomic.Pointer[[]blob.NumericEncoderOption]
+
+	// userTextDefaults holds the process-wide text encoder options set by
+	// SetDefaultTextEncoderOptions, nil if unset.
+	userTextDefaults atomic.Pointer[[]blob.TextEncoderOption]
+)
+
+// SetDefaultEncoderOptions sets process-wide numeric encoder options, so large
+// codebases configure compression, encodings and tag policy once at startup
+// instead of repeating the same option list at every construction site.
+//
+// The options are applied by NewNumericEncoder, NewDefaultNumericEncoder and
+// NewTaggedNumericEncoder after the built-in defaults and before the options
+// passed to the constructor, which therefore still override them. Encoders
+// created with blob.NewNumericEncoder are not affected. Each call replaces the
+// previous defaults; calling it without options restores the built-in defaults.
+//
+// The options are checked with blob.ValidateOptions and are not installed if
+// invalid. It is safe to call concurrently with the constructors, but encoders
+// already created keep their configuration.
+//
+// Parameters:
+//   - opts: Numeric encoder options applied to every encoder created by this package
+//
+// Returns:
+//   - error: The option error if the options are invalid
+//
+// Example:
+//
+//	func main() {
+//	    if err := mebo.SetDefaultEncoderOptions(
+//	        blob.WithValueCompression(format.CompressionZstd),
+//	        blob.WithTimestampEncoding(format.TypeDeltaPacked),
+//	    ); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    // ...
+//	    encoder, _ := mebo.NewDefaultNumericEncoder(time.Now()) // Zstd values, packed timestamps
+//	}
+func SetDefaultEncoderOptions(opts ...blob.NumericEncoderOption) error {
+	if len(opts) == 0 {
+		userNumericDefaults.Store(nil)
+		return nil
+	}
+
+	opts = slices.Clone(opts)
+	if err := blob.ValidateOptions(append(slices.Clone(defaultNumericOptions), opts...)...); err != nil {
+		return err
+	}
+	userNumericDefaults.Store(&opts)
+
+	return nil
+}
+
+// DefaultEncoderOptions returns the numeric encoder options set by
+// SetDefaultEncoderOptions, or nil if none are set.
+func DefaultEncoderOptions() []blob.NumericEncoderOption {
+	if opts := userNumericDefaults.Load(); opts != nil {
+		return slices.Clone(*opts)
+	}
+
+	return nil
+}
+
+// SetDefaultTextEncoderOptions sets process-wide text encoder options, the text
+// counterpart of SetDefaultEncoderOptions.
+//
+// The options are applied by NewTextEncoder, NewDefaultTextEncoder and
+// NewTaggedTextEncoder after the built-in defaults and before the options
+// passed to the constructor. Calling it without options restores the built-in
+// defaults.
+//
+// Parameters:
+//   - opts: Text encoder options applied to every text encoder created by this package
+//
+// Returns:
+//   - error: The option error if the options are invalid
+//
+// Example:
+//
+//	err := mebo.SetDefaultTextEncoderOptions(
+//	    blob.WithTextDataCompression(format.CompressionS2),
+//	)
+func SetDefaultTextEncoderOptions(opts ...blob.TextEncoderOption) error {
+	if len(opts) == 0 {
+		userTextDefaults.Store(nil)
+		return nil
+	}
+
+	opts = slices.Clone(opts)
+	if err := blob.ValidateTextOptions(append(slices.Clone(defaultTextOptions), opts...)...); err != nil {
+		return err
+	}
+	userTextDefaults.Store(&opts)
+
+	return nil
+}
+
+// DefaultTextEncoderOptions returns the text encoder options set by
+// SetDefaultTextEncoderOptions, or nil if none are set.
+func DefaultTextEncoderOptions() []blob.TextEncoderOption {
+	if opts := userTextDefaults.Load(); opts != nil {
+		return slices.Clone(*opts)
+	}
+
+	return nil
+}
+
+// numericOptions returns base followed by the process-wide numeric defaults and opts.
+func numericOptions(base []blob.NumericEncoderOption, opts ...blob.NumericEncoderOption) []blob.NumericEncoderOption {
+	all := slices.Clone(base)
+	if defaults := userNumericDefaults.Load(); defaults != nil {
+		all = append(all, *defaults...)
+	}
+
+	return append(all, opts...)
+}
+
+// textOptions returns base followed by the process-wide text defaults and opts.
+func textOptions(base []blob.TextEncoderOption, opts ...blob.TextEncoderOption) []blob.TextEncoderOption {
+	all := slices.Clone(base)
+	if defaults := userTextDefaults.Load(); defaults != nil {
+		all = append(all, *defaults...)
+	}
+
+	return append(all, opts...)
+}
@@ -0,0 +1,107 @@
+package mebo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+)
+
+// decodeNumericDefaultsBlob encodes one data point with encoder and decodes the blob.
+func decodeNumericDefaultsBlob(t *testing.T, encoder *blob.NumericEncoder, tag string) blob.NumericBlob {
+	t.Helper()
+
+	start := time.Unix(1700000000, 0)
+	require.NoError(t, encoder.StartMetricName("cpu", 1))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), 1, tag))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewNumericDecoder(data)
+	require.NoError(t, err)
+	b, err := decoder.Decode()
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestSetDefaultEncoderOptions(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetDefaultEncoderOptions()) })
+
+	start := time.Unix(1700000000, 0)
+	require.Nil(t, DefaultEncoderOptions())
+
+	require.NoError(t, SetDefaultEncoderOptions(
+		blob.WithBigEndian(),
+		blob.WithTimestampEncoding(format.TypeRaw),
+		blob.WithValueEncoding(format.TypeChimp),
+	))
+	require.Len(t, DefaultEncoderOptions(), 3)
+
+	encoder, err := NewDefaultNumericEncoder(start)
+	require.NoError(t, err)
+	b := decodeNumericDefaultsBlob(t, encoder, "")
+	require.True(t, b.IsBigEndian())
+	require.Equal(t, format.TypeRaw, b.TimestampEncoding())
+	require.Equal(t, format.TypeChimp, b.ValueEncoding())
+	require.False(t, b.HasTag())
+
+	// Constructor options override the process-wide defaults.
+	encoder, err = NewNumericEncoder(start, blob.WithValueEncoding(format.TypeRaw))
+	require.NoError(t, err)
+	b = decodeNumericDefaultsBlob(t, encoder, "")
+	require.True(t, b.IsBigEndian())
+	require.Equal(t, format.TypeRaw, b.ValueEncoding())
+
+	// Tagged encoders keep tags enabled over a tag policy in the defaults.
+	require.NoError(t, SetDefaultEncoderOptions(blob.WithTagsEnabled(false)))
+	encoder, err = NewTaggedNumericEncoder(start)
+	require.NoError(t, err)
+	require.True(t, decodeNumericDefaultsBlob(t, encoder, "host=a").HasTag())
+
+	// Invalid options are rejected and keep the previous defaults.
+	require.Error(t, SetDefaultEncoderOptions(blob.WithValueEncoding(format.EncodingType(0xff))))
+	require.Len(t, DefaultEncoderOptions(), 1)
+
+	// Resetting restores the built-in defaults.
+	require.NoError(t, SetDefaultEncoderOptions())
+	require.Nil(t, DefaultEncoderOptions())
+	encoder, err = NewDefaultNumericEncoder(start)
+	require.NoError(t, err)
+	b = decodeNumericDefaultsBlob(t, encoder, "")
+	require.True(t, b.IsLittleEndian())
+	require.Equal(t, format.TypeGorilla, b.ValueEncoding())
+}
+
+func TestSetDefaultTextEncoderOptions(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetDefaultTextEncoderOptions()) })
+
+	start := time.Unix(1700000000, 0)
+	require.NoError(t, SetDefaultTextEncoderOptions(
+		blob.WithTextTimestampEncoding(format.TypeRaw),
+		blob.WithTextTagsEnabled(true),
+	))
+	require.Len(t, DefaultTextEncoderOptions(), 2)
+
+	encoder, err := NewDefaultTextEncoder(start)
+	require.NoError(t, err)
+	require.NoError(t, encoder.StartMetricName("status", 1))
+	require.NoError(t, encoder.AddDataPoint(start.UnixMicro(), "OK", "host=a"))
+	require.NoError(t, encoder.EndMetric())
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+
+	decoder, err := NewTextDecoder(data)
+	require.NoError(t, err)
+	b, err := decoder.Decode()
+	require.NoError(t, err)
+	require.Equal(t, format.TypeRaw, b.TimestampEncoding())
+	require.True(t, b.HasTag())
+
+	require.Error(t, SetDefaultTextEncoderOptions(blob.WithTextTimestampEncoding(format.TypeGorilla)))
+	require.Len(t, DefaultTextEncoderOptions(), 2)
+}
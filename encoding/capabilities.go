@@ -0,0 +1,55 @@
+package encoding
+
+// CapabilityReporter is implemented by decoders that report the access patterns
+// they serve efficiently, so callers can choose an access strategy (direct At
+// versus materializing the column) without hard-coding per-format knowledge.
+//
+// All built-in decoders implement it. Custom decoders may implement it too;
+// decoders that do not are treated as sequential-only (see CapabilitiesOf).
+type CapabilityReporter interface {
+	// SupportsRandomAccess reports whether At runs in (near) constant time,
+	// without decoding the values that precede index.
+	SupportsRandomAccess() bool
+
+	// SupportsReverse reports whether values can be read from last to first
+	// without decoding the column front to back first.
+	SupportsReverse() bool
+}
+
+// Capabilities describes the access patterns a decoder serves efficiently.
+type Capabilities struct {
+	// RandomAccess reports whether At runs in (near) constant time.
+	RandomAccess bool
+	// Reverse reports whether values can be read from last to first without
+	// decoding the column front to back first.
+	Reverse bool
+}
+
+// CapabilitiesOf returns the capabilities reported by decoder.
+//
+// Decoders that do not implement CapabilityReporter report no capabilities:
+// every access beyond sequential iteration is assumed to decode from the start
+// of the column.
+//
+// Parameters:
+//   - decoder: Any columnar decoder
+//
+// Returns:
+//   - Capabilities: The reported capabilities, or the zero value
+//
+// Example:
+//
+//	if encoding.CapabilitiesOf(decoder).RandomAccess {
+//	    v, _ := decoder.At(data, index, count)
+//	}
+func CapabilitiesOf(decoder any) Capabilities {
+	reporter, ok := decoder.(CapabilityReporter)
+	if !ok {
+		return Capabilities{}
+	}
+
+	return Capabilities{
+		RandomAccess: reporter.SupportsRandomAccess(),
+		Reverse:      reporter.SupportsReverse(),
+	}
+}
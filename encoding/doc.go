@@ -46,6 +46,19 @@
 //	func (e *MyCustomEncoder) Reset() { /* ... */ }
 //	func (e *MyCustomEncoder) Finish() { /* ... */ }
 //
+// Register a matching ColumnarDecoder[float64] under a stable extension ID so the
+// standard decoders can read the values back. The blob header records the reserved
+// format.TypeExtension value encoding and the blob metadata records the ID:
+//
+//	func init() {
+//	    err := format.RegisterEncoding(0x100, "my-codec", func(engine endian.EndianEngine) encoding.ColumnarDecoder[float64] {
+//	        return NewMyCustomDecoder()
+//	    })
+//	    if err != nil {
+//	        panic(err)
+//	    }
+//	}
+//
 // Then use it with the blob package:
 //
 //	import (
@@ -53,8 +66,8 @@
 //	    "mypackage/myencoder"
 //	)
 //
-//	encoder := blob.NewNumericEncoder(
-//	    blob.WithValueEncoder(myencoder.NewMyCustomEncoder()),
+//	encoder, err := blob.NewNumericEncoder(startTime,
+//	    blob.WithValueEncoder(0x100, myencoder.NewMyCustomEncoder()),
 //	)
 //
 // # Built-in Implementations
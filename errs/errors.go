@@ -6,7 +6,7 @@ import "errors"
 var (
 	ErrInvalidMagicNumber            = errors.New("invalid magic number in header options")
 	ErrInvalidMetricCount            = errors.New("invalid metric count, must be between 0 and 65535")
-	ErrInvalidHeaderSize             = errors.New("invalid header size, must be exactly 32 bytes")
+	ErrInvalidHeaderSize             = errors.New("invalid header size, must be exactly 32 bytes (64 bytes for wide numeric headers)")
 	ErrInvalidHeaderFlags            = errors.New("invalid header flags")
 	ErrInvalidMetricID               = errors.New("invalid metric ID, must be non-zero")
 	ErrInvalidMetricName             = errors.New("invalid metric name")
@@ -23,6 +23,8 @@ var (
 	ErrNoMetricsAdded                = errors.New("no metrics added to encoder")
 	ErrTooManyDataPoints             = errors.New("too many data points, exceeds claimed count")
 	ErrNoDataPointsAdded             = errors.New("no data points added, add at least one data point before ending the metric")
+	ErrNaNValue                      = errors.New("NaN value rejected by the encoder's NaN policy")
+	ErrTimestampOutOfWindow          = errors.New("timestamp outside the encoder's time window")
 	ErrOffsetOutOfRange              = errors.New("offset out of range, too many data points")
 	ErrDataPointCountMismatch        = errors.New("data point count mismatch")
 	ErrHashCollision                 = errors.New("hash collision detected")
@@ -31,6 +33,11 @@ var (
 	ErrInvalidMetricNamesCount       = errors.New("invalid metric names count")
 	ErrMixedIdentifierMode           = errors.New("cannot mix StartMetricID and StartMetricName in the same encoder")
 	ErrInvalidSharedTimestampTable   = errors.New("invalid shared timestamp table")
+	ErrInvalidMetadata               = errors.New("invalid metadata section")
+	ErrInvalidMetricReference        = errors.New("invalid metric reference")
+	ErrInvalidMetricMeta             = errors.New("invalid metric metadata")
+	ErrInvalidBlobPart               = errors.New("invalid blob part")
+	ErrInvalidMetricRemap            = errors.New("invalid metric remap")
 	ErrInvalidReservedBytes          = errors.New("non-zero reserved bytes in extended index entry")
 	ErrIndexEntryOverflow            = errors.New("extended index entry field exceeds platform int range")
 	ErrBlobSizeExceedsLimit          = errors.New("blob size exceeds maximum uint32 limit for header offsets")
@@ -41,7 +48,26 @@ var (
 	ErrUnsupportedCompression        = errors.New("unsupported compression type")
 	ErrHeaderOffsetOverflow          = errors.New("header offset exceeds platform int range")
 	ErrDecompressedSizeExceedsLimit  = errors.New("decompressed size exceeds limit")
+	ErrInvalidCompressedFrame        = errors.New("invalid compressed frame")
 	ErrInvalidALPScheme              = errors.New("invalid ALP scheme byte")
+	ErrConformanceMismatch           = errors.New("decoded data does not match conformance vector")
+	ErrLateDataPoint                 = errors.New("data point is older than the lateness window")
+	ErrBlobNotSealed                 = errors.New("blob file is not sealed")
+	ErrBlobSealMismatch              = errors.New("blob file does not match its sealed marker")
+	ErrAmbiguousMetric               = errors.New("metric name is ambiguous: its metric ID is shared by several names")
+	ErrMetricNotFound                = errors.New("metric not found")
+	ErrIndexOutOfRange               = errors.New("data point index out of range")
+	ErrInvalidTimeRange              = errors.New("invalid time range, end must be after start")
+	ErrTagsDisabled                  = errors.New("tags are not enabled in the blob")
+	ErrUnsupportedBlobFeature        = errors.New("blob uses a feature not supported by this operation")
+	ErrDuplicateEncoding             = errors.New("extension encoding ID already registered")
+	ErrInvalidEventDictionary        = errors.New("invalid event text dictionary")
+	ErrInvalidValueDictionary        = errors.New("invalid text value compression dictionary")
+	ErrInvalidCompactionPolicy       = errors.New("invalid compaction policy")
+	ErrInvalidTuningSample           = errors.New("invalid tuning sample")
+	ErrInvalidTuningConfig           = errors.New("invalid tuning configuration")
+	ErrInvalidMaterializedData       = errors.New("invalid materialized blob set data")
+	ErrInvalidResumeToken            = errors.New("invalid resume token")
 	// ErrInvalidALPColumn indicates an ALP column whose body is shorter than
 	// its header-declared layout, or whose header fields are out of range.
 	ErrInvalidALPColumn = errors.New("invalid ALP column")
@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+// Command wasm_viewer decodes mebo blobs in the browser.
+//
+// Build it in lite mode, which swaps the pooled high-memory Zstd codec for a single
+// low-memory instance:
+//
+//	GOOS=js GOARCH=wasm go build -tags mebo_lite -o mebo.wasm ./examples/wasm_viewer
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// Add -tags mebo_lite,mebo_nozstd to drop Zstd entirely when the blobs use None, S2
+// or LZ4 compression. With TinyGo, lite mode is enabled automatically:
+//
+//	tinygo build -target wasm -o mebo.wasm ./examples/wasm_viewer
+//
+// The module registers a global meboDecodeNumeric(Uint8Array) function that returns
+// an object mapping each metric ID (as a decimal string) to {ts: [...], values: [...]}:
+//
+//	const resp = await fetch("https://bucket.example.com/metrics.mebo");
+//	const series = meboDecodeNumeric(new Uint8Array(await resp.arrayBuffer()));
+package main
+
+import (
+	"strconv"
+	"syscall/js"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+func main() {
+	js.Global().Set("meboDecodeNumeric", js.FuncOf(decodeNumeric))
+
+	select {} // keep the Go runtime alive for callbacks
+}
+
+func decodeNumeric(_ js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return js.Global().Get("Error").New("meboDecodeNumeric expects one Uint8Array argument")
+	}
+
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	decoder, err := blob.NewNumericDecoder(data)
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+	b, err := decoder.Decode()
+	if err != nil {
+		return js.Global().Get("Error").New(err.Error())
+	}
+
+	result := make(map[string]any, b.MetricCount())
+	for _, id := range b.MetricIDs() {
+		ts := make([]any, 0, b.Len(id))
+		values := make([]any, 0, b.Len(id))
+		for _, dp := range b.All(id) {
+			// JS numbers are float64; microsecond timestamps stay exact until year 2255.
+			ts = append(ts, float64(dp.Ts))
+			values = append(values, dp.Val)
+		}
+		result[strconv.FormatUint(id, 10)] = map[string]any{"ts": ts, "values": values}
+	}
+
+	return js.ValueOf(result)
+}
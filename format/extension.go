@@ -0,0 +1,95 @@
+package format
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/arloliu/mebo/encoding"
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+)
+
+// ExtensionDecoderFactory creates a value decoder for an extension encoding.
+//
+// The engine is the byte order of the blob being decoded. The returned decoder
+// must be safe for concurrent reads, like the built-in decoders.
+type ExtensionDecoderFactory func(engine endian.EndianEngine) encoding.ColumnarDecoder[float64]
+
+// ExtensionEncoding describes a registered extension value encoding.
+type ExtensionEncoding struct {
+	// ID identifies the encoding in blob metadata.
+	ID uint16
+	// Name is a human-readable name used in diagnostics.
+	Name string
+	// NewDecoder creates decoders for value columns written by the encoding.
+	NewDecoder ExtensionDecoderFactory
+}
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = map[uint16]ExtensionEncoding{}
+)
+
+// RegisterEncoding registers an extension value encoding so blobs written with
+// it can be decoded by the standard decoders.
+//
+// Blobs encoded with an extension encoder carry TypeExtension in the header
+// value encoding bits and the concrete ID in their metadata section. Decoding
+// such a blob requires the same ID to be registered in the decoding process,
+// typically from an init function of the package providing the encoder.
+//
+// IDs are global to the process and must be stable across releases, since they
+// are persisted in blobs.
+//
+// Parameters:
+//   - id: Stable identifier of the encoding
+//   - name: Human-readable name of the encoding
+//   - factory: Creates decoders for the encoded value columns
+//
+// Returns:
+//   - error: ErrDuplicateEncoding if id is already registered, or
+//     ErrUnsupportedEncoding if factory is nil
+//
+// Example:
+//
+//	func init() {
+//	    err := format.RegisterEncoding(0x100, "my-codec", func(engine endian.EndianEngine) encoding.ColumnarDecoder[float64] {
+//	        return NewMyCustomDecoder(engine)
+//	    })
+//	    if err != nil {
+//	        panic(err)
+//	    }
+//	}
+func RegisterEncoding(id uint16, name string, factory ExtensionDecoderFactory) error {
+	if factory == nil {
+		return fmt.Errorf("%w: nil decoder factory for extension encoding %q", errs.ErrUnsupportedEncoding, name)
+	}
+
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+
+	if ext, ok := extensions[id]; ok {
+		return fmt.Errorf("%w: ID %d is used by %q", errs.ErrDuplicateEncoding, id, ext.Name)
+	}
+
+	extensions[id] = ExtensionEncoding{ID: id, Name: name, NewDecoder: factory}
+
+	return nil
+}
+
+// LookupEncoding returns the extension encoding registered under id.
+//
+// Parameters:
+//   - id: Identifier passed to RegisterEncoding
+//
+// Returns:
+//   - ExtensionEncoding: The registered encoding
+//   - bool: false if no encoding is registered under id
+func LookupEncoding(id uint16) (ExtensionEncoding, bool) {
+	extensionsMu.RLock()
+	defer extensionsMu.RUnlock()
+
+	ext, ok := extensions[id]
+
+	return ext, ok
+}
@@ -1,9 +1,13 @@
 // Package format defines types and constants for data encoding and compression formats.
 package format
 
+import "time"
+
 type (
 	EncodingType    uint8
 	CompressionType uint8
+	TimeUnit        uint8
+	MetricKind      uint8
 )
 
 const (
@@ -13,12 +17,22 @@ const (
 	TypeChimp       EncodingType = 0x4 // TypeChimp represents Chimp encoding for numeric values.
 	TypeDeltaPacked EncodingType = 0x5 // TypeDeltaPacked represents delta-of-delta encoding with Group Varint packing for timestamps.
 	TypeALP         EncodingType = 0x6 // TypeALP represents Adaptive Lossless floating-Point encoding for numeric values.
+	TypeExtension   EncodingType = 0xF // TypeExtension represents a registered extension encoding; its ID is stored in blob metadata.
 
 	CompressionNone CompressionType = 0x1 // CompressionNone represents no compression.
 	CompressionZstd CompressionType = 0x2 // CompressionZstd represents Zstandard compression.
 	CompressionS2   CompressionType = 0x3 // CompressionS2 represents S2 compression.
 	CompressionLZ4  CompressionType = 0x4 // CompressionLZ4 represents LZ4 compression.
 
+	TimeUnitMicrosecond TimeUnit = 0x0 // TimeUnitMicrosecond represents Unix microseconds (the default).
+	TimeUnitNanosecond  TimeUnit = 0x1 // TimeUnitNanosecond represents Unix nanoseconds.
+	TimeUnitMillisecond TimeUnit = 0x2 // TimeUnitMillisecond represents Unix milliseconds.
+	TimeUnitSecond      TimeUnit = 0x3 // TimeUnitSecond represents Unix seconds.
+
+	MetricKindUnknown   MetricKind = 0x0 // MetricKindUnknown represents a metric without declared semantics (the default).
+	MetricKindGauge     MetricKind = 0x1 // MetricKindGauge represents a value that can go up and down.
+	MetricKindCounter   MetricKind = 0x2 // MetricKindCounter represents a monotonically increasing cumulative value.
+	MetricKindHistogram MetricKind = 0x3 // MetricKindHistogram represents a series of a histogram, such as a bucket, sum or count.
 )
 
 func (e EncodingType) String() string {
@@ -35,6 +49,8 @@ func (e EncodingType) String() string {
 		return "DeltaPacked"
 	case TypeALP:
 		return "ALP"
+	case TypeExtension:
+		return "Extension"
 	default:
 		return "Unknown"
 	}
@@ -54,3 +70,104 @@ func (c CompressionType) String() string {
 		return "Unknown"
 	}
 }
+
+func (u TimeUnit) String() string {
+	switch u {
+	case TimeUnitMicrosecond:
+		return "Microsecond"
+	case TimeUnitNanosecond:
+		return "Nanosecond"
+	case TimeUnitMillisecond:
+		return "Millisecond"
+	case TimeUnitSecond:
+		return "Second"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsValid reports whether u is a known time unit.
+func (u TimeUnit) IsValid() bool {
+	return u <= TimeUnitSecond
+}
+
+// Duration returns the length of one tick of the unit, or 0 for an unknown unit.
+func (u TimeUnit) Duration() time.Duration {
+	switch u {
+	case TimeUnitMicrosecond:
+		return time.Microsecond
+	case TimeUnitNanosecond:
+		return time.Nanosecond
+	case TimeUnitMillisecond:
+		return time.Millisecond
+	case TimeUnitSecond:
+		return time.Second
+	default:
+		return 0
+	}
+}
+
+// Time converts a Unix timestamp expressed in the unit to a UTC time.Time.
+func (u TimeUnit) Time(ts int64) time.Time {
+	switch u {
+	case TimeUnitNanosecond:
+		return time.Unix(0, ts).UTC()
+	case TimeUnitMillisecond:
+		return time.UnixMilli(ts).UTC()
+	case TimeUnitSecond:
+		return time.Unix(ts, 0).UTC()
+	default:
+		return time.UnixMicro(ts).UTC()
+	}
+}
+
+// Timestamp converts t to a Unix timestamp expressed in the unit, truncating
+// any finer precision.
+func (u TimeUnit) Timestamp(t time.Time) int64 {
+	switch u {
+	case TimeUnitNanosecond:
+		return t.UnixNano()
+	case TimeUnitMillisecond:
+		return t.UnixMilli()
+	case TimeUnitSecond:
+		return t.Unix()
+	default:
+		return t.UnixMicro()
+	}
+}
+
+// Convert converts a timestamp expressed in the unit to the target unit.
+// Conversions to a coarser unit truncate toward zero; conversions to a finer
+// unit can overflow for timestamps far from the Unix epoch.
+func (u TimeUnit) Convert(ts int64, to TimeUnit) int64 {
+	from, target := u.Duration(), to.Duration()
+	if from == 0 || target == 0 || from == target {
+		return ts
+	}
+
+	if from > target {
+		return ts * int64(from/target)
+	}
+
+	return ts / int64(target/from)
+}
+
+func (k MetricKind) String() string {
+	switch k {
+	case MetricKindUnknown:
+		return "Unknown"
+	case MetricKindGauge:
+		return "Gauge"
+	case MetricKindCounter:
+		return "Counter"
+	case MetricKindHistogram:
+		return "Histogram"
+	default:
+		return "Invalid"
+	}
+}
+
+// IsValid reports whether k is a known metric kind.
+func (k MetricKind) IsValid() bool {
+	return k <= MetricKindHistogram
+}
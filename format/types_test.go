@@ -1,8 +1,13 @@
 package format_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/arloliu/mebo/encoding"
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 )
 
@@ -14,3 +19,77 @@ func TestTypeALPString(t *testing.T) {
 		t.Fatalf("got %q", format.TypeALP.String())
 	}
 }
+
+func TestTimeUnit(t *testing.T) {
+	ts := time.Date(2025, 3, 1, 12, 30, 45, 123456789, time.UTC)
+
+	tests := []struct {
+		unit format.TimeUnit
+		name string
+		want time.Time
+	}{
+		{unit: format.TimeUnitMicrosecond, name: "Microsecond", want: ts.Truncate(time.Microsecond)},
+		{unit: format.TimeUnitNanosecond, name: "Nanosecond", want: ts},
+		{unit: format.TimeUnitMillisecond, name: "Millisecond", want: ts.Truncate(time.Millisecond)},
+		{unit: format.TimeUnitSecond, name: "Second", want: ts.Truncate(time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.unit.IsValid() || tt.unit.String() != tt.name {
+				t.Fatalf("unexpected unit %v", tt.unit)
+			}
+			if got := tt.unit.Time(tt.unit.Timestamp(ts)); !got.Equal(tt.want) {
+				t.Fatalf("round trip: got %v, want %v", got, tt.want)
+			}
+			if got := tt.unit.Convert(tt.unit.Timestamp(ts), format.TimeUnitMicrosecond); got != tt.want.UnixMicro() {
+				t.Fatalf("convert to microseconds: got %d, want %d", got, tt.want.UnixMicro())
+			}
+		})
+	}
+
+	if got := format.TimeUnitSecond.Convert(90, format.TimeUnitMillisecond); got != 90000 {
+		t.Fatalf("convert seconds to milliseconds: got %d", got)
+	}
+	if unknown := format.TimeUnit(9); unknown.IsValid() || unknown.Duration() != 0 || unknown.Convert(5, format.TimeUnitSecond) != 5 {
+		t.Fatalf("unknown unit must be invalid and convert as identity")
+	}
+}
+
+func TestMetricKind(t *testing.T) {
+	if format.MetricKindUnknown != 0 || format.MetricKindCounter.String() != "Counter" || !format.MetricKindHistogram.IsValid() {
+		t.Fatalf("unexpected metric kind definitions")
+	}
+	if invalid := format.MetricKind(4); invalid.IsValid() || invalid.String() != "Invalid" {
+		t.Fatalf("metric kind 4 must be invalid")
+	}
+}
+
+func TestRegisterEncoding(t *testing.T) {
+	factory := func(endian.EndianEngine) encoding.ColumnarDecoder[float64] { return nil }
+
+	if err := format.RegisterEncoding(0x7F01, "test-ext", factory); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	ext, ok := format.LookupEncoding(0x7F01)
+	if !ok || ext.ID != 0x7F01 || ext.Name != "test-ext" || ext.NewDecoder == nil {
+		t.Fatalf("unexpected lookup result %+v, %v", ext, ok)
+	}
+
+	if err := format.RegisterEncoding(0x7F01, "other", factory); !errors.Is(err, errs.ErrDuplicateEncoding) {
+		t.Fatalf("duplicate register: got %v", err)
+	}
+
+	if err := format.RegisterEncoding(0x7F02, "nil", nil); !errors.Is(err, errs.ErrUnsupportedEncoding) {
+		t.Fatalf("nil factory: got %v", err)
+	}
+
+	if _, ok := format.LookupEncoding(0x7F02); ok {
+		t.Fatal("nil factory must not be registered")
+	}
+
+	if format.TypeExtension.String() != "Extension" {
+		t.Fatalf("got %q", format.TypeExtension.String())
+	}
+}
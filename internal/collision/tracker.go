@@ -1,6 +1,8 @@
 package collision
 
 import (
+	"slices"
+
 	"github.com/arloliu/mebo/errs"
 )
 
@@ -10,6 +12,7 @@ import (
 type Tracker struct {
 	metricNames     map[uint64]string // Hash → name mapping for collision detection
 	metricNamesList []string          // Ordered list for payload encoding
+	metricHashes    []uint64          // Hashes of metricNamesList, in the same order
 	hasCollision    bool              // Whether a collision has been detected
 }
 
@@ -68,10 +71,28 @@ func (t *Tracker) TrackMetric(name string, hash uint64) error {
 	// Track the metric
 	t.metricNames[hash] = name
 	t.metricNamesList = append(t.metricNamesList, name)
+	t.metricHashes = append(t.metricHashes, hash)
 
 	return nil
 }
 
+// Remove untracks the i-th tracked metric name, e.g. when its metric is
+// discarded before the blob is finished, and recomputes the collision state
+// of the remaining names.
+func (t *Tracker) Remove(i int) {
+	t.metricNamesList = slices.Delete(t.metricNamesList, i, i+1)
+	t.metricHashes = slices.Delete(t.metricHashes, i, i+1)
+
+	clear(t.metricNames)
+	t.hasCollision = false
+	for j, name := range t.metricNamesList {
+		if existing, exists := t.metricNames[t.metricHashes[j]]; exists && existing != name {
+			t.hasCollision = true
+		}
+		t.metricNames[t.metricHashes[j]] = name
+	}
+}
+
 // HasCollision returns true if a collision has been detected.
 func (t *Tracker) HasCollision() bool {
 	return t.hasCollision
@@ -96,5 +117,6 @@ func (t *Tracker) Reset() {
 		delete(t.metricNames, k)
 	}
 	t.metricNamesList = t.metricNamesList[:0]
+	t.metricHashes = t.metricHashes[:0]
 	t.hasCollision = false
 }
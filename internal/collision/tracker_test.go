@@ -147,6 +147,26 @@ func TestTracker_Reset(t *testing.T) {
 	require.Equal(t, []string{"disk.usage"}, tracker.GetMetricNames())
 }
 
+func TestTracker_Remove(t *testing.T) {
+	tracker := NewTracker()
+
+	require.NoError(t, tracker.TrackMetric("cpu.usage", 0x1111111111111111))
+	require.NoError(t, tracker.TrackMetric("mem.usage", 0x2222222222222222))
+	require.NoError(t, tracker.TrackMetric("disk.usage", 0x1111111111111111))
+	require.True(t, tracker.HasCollision())
+
+	// Removing one of the colliding names clears the collision.
+	tracker.Remove(2)
+	require.False(t, tracker.HasCollision())
+	require.Equal(t, []string{"cpu.usage", "mem.usage"}, tracker.GetMetricNames())
+
+	// A removed name can be tracked again.
+	tracker.Remove(0)
+	require.Equal(t, []string{"mem.usage"}, tracker.GetMetricNames())
+	require.NoError(t, tracker.TrackMetric("cpu.usage", 0x1111111111111111))
+	require.ErrorIs(t, tracker.TrackMetric("mem.usage", 0x2222222222222222), errs.ErrMetricAlreadyStarted)
+}
+
 func TestTracker_Reset_PreservesCapacity(t *testing.T) {
 	tracker := NewTracker()
 
@@ -33,6 +33,9 @@ type TagEncoder = metadata.TagEncoder
 // TagDecoder decodes tag strings in the established length-prefixed format.
 type TagDecoder = metadata.TagDecoder
 
+// TagCursor incrementally decodes the length-prefixed tag stream.
+type TagCursor = metadata.TagCursor
+
 // VarStringEncoder encodes variable-length text values and timestamp varints.
 type VarStringEncoder = metadata.VarStringEncoder
 
@@ -84,6 +87,12 @@ type NumericGorillaDecoder = gorilla.NumericGorillaDecoder
 // GorillaValState incrementally decodes Gorilla-compressed float values.
 type GorillaValState = gorilla.GorillaValState
 
+// Interner deduplicates decoded tag and text strings.
+type Interner = metadata.Interner
+
+// GorillaTuning holds the encoder-side Gorilla tuning knobs.
+type GorillaTuning = gorilla.Tuning
+
 // NumericChimpEncoder encodes Chimp-compressed float values.
 type NumericChimpEncoder = chimp.NumericChimpEncoder
 
@@ -109,6 +118,26 @@ func NewTagDecoder(engine endian.EndianEngine) TagDecoder {
 	return metadata.NewTagDecoder(engine)
 }
 
+// NewTagDecoderWithInterner creates a tag decoder that interns decoded tags.
+func NewTagDecoderWithInterner(engine endian.EndianEngine, interner *Interner) TagDecoder {
+	return metadata.NewTagDecoderWithInterner(engine, interner)
+}
+
+// NewBitmapTagDecoder creates a tag decoder for the presence bitmap tag layout.
+func NewBitmapTagDecoder(engine endian.EndianEngine, interner *Interner) TagDecoder {
+	return metadata.NewBitmapTagDecoder(engine, interner)
+}
+
+// NewTagCursor creates a cursor over a length-prefixed tag stream.
+func NewTagCursor(data []byte, interner *Interner) TagCursor {
+	return metadata.NewTagCursor(data, interner)
+}
+
+// NewInterner creates an empty string interner.
+func NewInterner() *Interner {
+	return metadata.NewInterner()
+}
+
 // NewVarStringEncoder creates a variable-length string encoder using engine.
 func NewVarStringEncoder(engine endian.EndianEngine) *VarStringEncoder {
 	return metadata.NewVarStringEncoder(engine)
@@ -184,6 +213,16 @@ func NewNumericGorillaEncoder() *NumericGorillaEncoder {
 	return gorilla.NewNumericGorillaEncoder()
 }
 
+// NewNumericGorillaEncoderWithTuning creates a Gorilla float encoder with custom tuning.
+func NewNumericGorillaEncoderWithTuning(tuning GorillaTuning) *NumericGorillaEncoder {
+	return gorilla.NewNumericGorillaEncoderWithTuning(tuning)
+}
+
+// DefaultGorillaTuning returns the tuning matching the original Gorilla algorithm.
+func DefaultGorillaTuning() GorillaTuning {
+	return gorilla.DefaultTuning()
+}
+
 // NewNumericGorillaDecoder creates a Gorilla float decoder.
 func NewNumericGorillaDecoder() NumericGorillaDecoder {
 	return gorilla.NewNumericGorillaDecoder()
@@ -265,18 +304,18 @@ func FusedDeltaGorillaAll(tsData, valData []byte, count int) iter.Seq2[int64, fl
 }
 
 // FusedDeltaGorillaTagAll decodes Delta, Gorilla, and tags together.
-func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, yield func(int, int64, float64, string) bool) {
-	fused.FusedDeltaGorillaTagAll(tsData, valData, tagData, count, yield)
+func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, interner *Interner, yield func(int, int64, float64, string) bool) {
+	fused.FusedDeltaGorillaTagAll(tsData, valData, tagData, count, interner, yield)
 }
 
 // FusedDeltaTagAll decodes Delta timestamps and tags together.
-func FusedDeltaTagAll(tsData, tagData []byte, count int, yield func(int, int64, string) bool) {
-	fused.FusedDeltaTagAll(tsData, tagData, count, yield)
+func FusedDeltaTagAll(tsData, tagData []byte, count int, interner *Interner, yield func(int, int64, string) bool) {
+	fused.FusedDeltaTagAll(tsData, tagData, count, interner, yield)
 }
 
 // FusedGorillaTagAll decodes Gorilla values and tags together.
-func FusedGorillaTagAll(valData, tagData []byte, count int, yield func(int, float64, string) bool) {
-	fused.FusedGorillaTagAll(valData, tagData, count, yield)
+func FusedGorillaTagAll(valData, tagData []byte, count int, interner *Interner, yield func(int, float64, string) bool) {
+	fused.FusedGorillaTagAll(valData, tagData, count, interner, yield)
 }
 
 // FusedDeltaChimpAll returns fused Delta and Chimp values.
@@ -285,13 +324,13 @@ func FusedDeltaChimpAll(tsData, valData []byte, count int) iter.Seq2[int64, floa
 }
 
 // FusedDeltaChimpTagAll decodes Delta, Chimp, and tags together.
-func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, yield func(int, int64, float64, string) bool) {
-	fused.FusedDeltaChimpTagAll(tsData, valData, tagData, count, yield)
+func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, interner *Interner, yield func(int, int64, float64, string) bool) {
+	fused.FusedDeltaChimpTagAll(tsData, valData, tagData, count, interner, yield)
 }
 
 // FusedChimpTagAll decodes Chimp values and tags together.
-func FusedChimpTagAll(valData, tagData []byte, count int, yield func(int, float64, string) bool) {
-	fused.FusedChimpTagAll(valData, tagData, count, yield)
+func FusedChimpTagAll(valData, tagData []byte, count int, interner *Interner, yield func(int, float64, string) bool) {
+	fused.FusedChimpTagAll(valData, tagData, count, interner, yield)
 }
 
 // FusedDeltaPackedGorillaAll returns fused packed Delta and Gorilla values.
@@ -300,8 +339,8 @@ func FusedDeltaPackedGorillaAll(tsData, valData []byte, count int) iter.Seq2[int
 }
 
 // FusedDeltaPackedGorillaTagAll decodes packed Delta, Gorilla, and tags together.
-func FusedDeltaPackedGorillaTagAll(tsData, valData, tagData []byte, count int, yield func(int, int64, float64, string) bool) {
-	fused.FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, count, yield)
+func FusedDeltaPackedGorillaTagAll(tsData, valData, tagData []byte, count int, interner *Interner, yield func(int, int64, float64, string) bool) {
+	fused.FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, count, interner, yield)
 }
 
 // FusedDeltaPackedChimpAll returns fused packed Delta and Chimp values.
@@ -310,13 +349,13 @@ func FusedDeltaPackedChimpAll(tsData, valData []byte, count int) iter.Seq2[int64
 }
 
 // FusedDeltaPackedChimpTagAll decodes packed Delta, Chimp, and tags together.
-func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, yield func(int, int64, float64, string) bool) {
-	fused.FusedDeltaPackedChimpTagAll(tsData, valData, tagData, count, yield)
+func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, interner *Interner, yield func(int, int64, float64, string) bool) {
+	fused.FusedDeltaPackedChimpTagAll(tsData, valData, tagData, count, interner, yield)
 }
 
 // FusedDeltaPackedTagAll decodes packed Delta timestamps and tags together.
-func FusedDeltaPackedTagAll(tsData, tagData []byte, count int, yield func(int, int64, string) bool) {
-	fused.FusedDeltaPackedTagAll(tsData, tagData, count, yield)
+func FusedDeltaPackedTagAll(tsData, tagData []byte, count int, interner *Interner, yield func(int, int64, string) bool) {
+	fused.FusedDeltaPackedTagAll(tsData, tagData, count, interner, yield)
 }
 
 // RawTimestampsEach decodes raw timestamps and calls yield for each timestamp.
@@ -339,6 +378,26 @@ func DecodeMetricNames(data []byte, engine endian.EndianEngine) ([]string, int,
 	return metadata.DecodeMetricNames(data, engine)
 }
 
+// TagBitmapSize returns the presence bitmap size in bytes of count tags.
+func TagBitmapSize(count int) int {
+	return metadata.TagBitmapSize(count)
+}
+
+// CountEmptyTags returns the number of empty tags in length-prefixed tag data.
+func CountEmptyTags(data []byte, count int) (int, bool) {
+	return metadata.CountEmptyTags(data, count)
+}
+
+// AppendBitmapTags converts length-prefixed tag data to the presence bitmap layout.
+func AppendBitmapTags(dst, data []byte, count int) ([]byte, bool) {
+	return metadata.AppendBitmapTags(dst, data, count)
+}
+
+// AppendPlainTags converts presence bitmap tag data to the length-prefixed layout.
+func AppendPlainTags(dst, data []byte, count int) ([]byte, bool) {
+	return metadata.AppendPlainTags(dst, data, count)
+}
+
 // VerifyMetricNamesHashes verifies names hash to the corresponding metric IDs.
 func VerifyMetricNamesHashes(names []string, metricIDs []uint64, hashFunc func(string) uint64) error {
 	return metadata.VerifyMetricNamesHashes(names, metricIDs, hashFunc)
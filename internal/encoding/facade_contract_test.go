@@ -3,6 +3,7 @@ package encoding
 import (
 	"testing"
 
+	"github.com/arloliu/mebo/encoding"
 	"github.com/arloliu/mebo/endian"
 	"github.com/arloliu/mebo/internal/encoding/timestamp/delta"
 	"github.com/arloliu/mebo/internal/encoding/timestamp/deltapacked"
@@ -206,3 +207,36 @@ func requireTagDecoderAlias(t *testing.T, decoder TagDecoder) {
 	t.Helper()
 	require.NotNil(t, decoder.All)
 }
+
+func TestDecoderCapabilities(t *testing.T) {
+	engine := endian.GetLittleEndianEngine()
+
+	tests := []struct {
+		name    string
+		decoder any
+		want    bool
+	}{
+		{name: "TimestampRaw", decoder: NewTimestampRawDecoder(engine), want: true},
+		{name: "TimestampRawUnsafe", decoder: NewTimestampRawUnsafeDecoder(engine), want: true},
+		{name: "TimestampDelta", decoder: NewTimestampDeltaDecoder(), want: false},
+		{name: "TimestampDeltaPacked", decoder: NewTimestampDeltaPackedDecoder(), want: false},
+		{name: "TimestampSimple8b", decoder: NewTimestampSimple8bDecoder(engine), want: false},
+		{name: "NumericRaw", decoder: NewNumericRawDecoder(engine), want: true},
+		{name: "NumericRawUnsafe", decoder: NewNumericRawUnsafeDecoder(engine), want: true},
+		{name: "NumericGorilla", decoder: NewNumericGorillaDecoder(), want: false},
+		{name: "NumericChimp", decoder: NewNumericChimpDecoder(), want: false},
+		{name: "NumericALP", decoder: NewNumericALPDecoder(engine), want: true},
+		{name: "Tag", decoder: NewTagDecoder(engine), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Implements(t, (*encoding.CapabilityReporter)(nil), tt.decoder)
+			caps := encoding.CapabilitiesOf(tt.decoder)
+			require.Equal(t, tt.want, caps.RandomAccess)
+			require.Equal(t, tt.want, caps.Reverse)
+		})
+	}
+
+	require.Equal(t, encoding.Capabilities{}, encoding.CapabilitiesOf(struct{}{}))
+}
@@ -56,13 +56,14 @@ func FusedDeltaGorillaAll(tsData, valData []byte, count int) iter.Seq2[int64, fl
 //   - valData: Gorilla XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //
 // Returns:
 //   - iter.Seq2[int64, float64]: first return is timestamp, second is value
 //
 // The tag is provided via a callback to avoid allocating a 3-tuple struct per iteration.
 // Use FusedDeltaGorillaTagAllWith for the full (ts, val, tag) iteration.
-func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, tagYield func(int, int64, float64, string) bool) {
+func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, interner *metadata.Interner, tagYield func(int, int64, float64, string) bool) {
 	if count == 0 || len(tsData) == 0 || len(valData) == 0 {
 		return
 	}
@@ -80,7 +81,7 @@ func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, tagYiel
 	val := gc.First()
 
 	// Initialize tag state
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -121,8 +122,9 @@ func FusedDeltaGorillaTagAll(tsData, valData, tagData []byte, count int, tagYiel
 //   - tsData: Delta-of-delta encoded timestamp bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - yield: Callback receiving (index, timestamp, tag)
-func FusedDeltaTagAll(tsData, tagData []byte, count int, yield func(int, int64, string) bool) {
+func FusedDeltaTagAll(tsData, tagData []byte, count int, interner *metadata.Interner, yield func(int, int64, string) bool) {
 	if count == 0 || len(tsData) == 0 {
 		return
 	}
@@ -134,7 +136,7 @@ func FusedDeltaTagAll(tsData, tagData []byte, count int, yield func(int, int64,
 	}
 
 	// Initialize tag state
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -169,8 +171,9 @@ func FusedDeltaTagAll(tsData, tagData []byte, count int, yield func(int, int64,
 //   - valData: Gorilla XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - yield: Callback receiving (index, value, tag)
-func FusedGorillaTagAll(valData, tagData []byte, count int, yield func(int, float64, string) bool) {
+func FusedGorillaTagAll(valData, tagData []byte, count int, interner *metadata.Interner, yield func(int, float64, string) bool) {
 	if count == 0 || len(valData) == 0 {
 		return
 	}
@@ -182,7 +185,7 @@ func FusedGorillaTagAll(valData, tagData []byte, count int, yield func(int, floa
 	val := gc.First()
 
 	// Initialize tag state
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -253,8 +256,9 @@ func FusedDeltaChimpAll(tsData, valData []byte, count int) iter.Seq2[int64, floa
 //   - valData: Chimp XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - tagYield: Callback receiving (index, timestamp, value, tag)
-func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, tagYield func(int, int64, float64, string) bool) {
+func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, interner *metadata.Interner, tagYield func(int, int64, float64, string) bool) {
 	if count == 0 || len(tsData) == 0 || len(valData) == 0 {
 		return
 	}
@@ -272,7 +276,7 @@ func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, tagYield
 	val := cc.First()
 
 	// Initialize tag state
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -312,8 +316,9 @@ func FusedDeltaChimpTagAll(tsData, valData, tagData []byte, count int, tagYield
 //   - valData: Chimp XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - yield: Callback receiving (index, value, tag)
-func FusedChimpTagAll(valData, tagData []byte, count int, yield func(int, float64, string) bool) {
+func FusedChimpTagAll(valData, tagData []byte, count int, interner *metadata.Interner, yield func(int, float64, string) bool) {
 	if count == 0 || len(valData) == 0 {
 		return
 	}
@@ -325,7 +330,7 @@ func FusedChimpTagAll(valData, tagData []byte, count int, yield func(int, float6
 	val := cc.First()
 
 	// Initialize tag state
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -381,8 +386,9 @@ func FusedDeltaPackedGorillaAll(tsData, valData []byte, count int) iter.Seq2[int
 //   - valData: Gorilla XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - tagYield: Callback receiving (index, timestamp, value, tag); return false to stop
-func FusedDeltaPackedGorillaTagAll(tsData, valData, tagData []byte, count int, tagYield func(int, int64, float64, string) bool) {
+func FusedDeltaPackedGorillaTagAll(tsData, valData, tagData []byte, count int, interner *metadata.Interner, tagYield func(int, int64, float64, string) bool) {
 	if count == 0 || len(tsData) == 0 || len(valData) == 0 {
 		return
 	}
@@ -398,7 +404,7 @@ func FusedDeltaPackedGorillaTagAll(tsData, valData, tagData []byte, count int, t
 		return
 	}
 
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -450,8 +456,9 @@ func FusedDeltaPackedChimpAll(tsData, valData []byte, count int) iter.Seq2[int64
 //   - valData: Chimp XOR compressed value bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - tagYield: Callback receiving (index, timestamp, value, tag); return false to stop
-func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, tagYield func(int, int64, float64, string) bool) {
+func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, interner *metadata.Interner, tagYield func(int, int64, float64, string) bool) {
 	if count == 0 || len(tsData) == 0 || len(valData) == 0 {
 		return
 	}
@@ -467,7 +474,7 @@ func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, tag
 		return
 	}
 
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -501,8 +508,9 @@ func FusedDeltaPackedChimpTagAll(tsData, valData, tagData []byte, count int, tag
 //   - tsData: Group Varint packed delta-of-delta encoded timestamp bytes
 //   - tagData: Varint length-prefixed tag bytes
 //   - count: Number of data points to decode
+//   - interner: Optional tag interner, nil disables interning
 //   - yield: Callback receiving (index, timestamp, tag); return false to stop
-func FusedDeltaPackedTagAll(tsData, tagData []byte, count int, yield func(int, int64, string) bool) {
+func FusedDeltaPackedTagAll(tsData, tagData []byte, count int, interner *metadata.Interner, yield func(int, int64, string) bool) {
 	if count == 0 || len(tsData) == 0 {
 		return
 	}
@@ -512,7 +520,7 @@ func FusedDeltaPackedTagAll(tsData, tagData []byte, count int, yield func(int, i
 		return
 	}
 
-	tagCursor := metadata.NewTagCursor(tagData)
+	tagCursor := metadata.NewTagCursor(tagData, interner)
 	tag, tagOk := tagCursor.Next()
 	if !tagOk {
 		return
@@ -189,7 +189,7 @@ func TestFusedDeltaGorillaTagAll(t *testing.T) {
 	var gotTags []string
 	var gotIndices []int
 
-	FusedDeltaGorillaTagAll(tsData, valData, tagData, 5, func(i int, ts int64, val float64, tag string) bool {
+	FusedDeltaGorillaTagAll(tsData, valData, tagData, 5, nil, func(i int, ts int64, val float64, tag string) bool {
 		gotIndices = append(gotIndices, i)
 		gotTS = append(gotTS, ts)
 		gotVals = append(gotVals, val)
@@ -259,7 +259,7 @@ func TestFusedGorillaTagAll(t *testing.T) {
 	var gotVals []float64
 	var gotTags []string
 
-	FusedGorillaTagAll(valData, tagData, 5, func(i int, val float64, tag string) bool {
+	FusedGorillaTagAll(valData, tagData, 5, nil, func(i int, val float64, tag string) bool {
 		gotVals = append(gotVals, val)
 		gotTags = append(gotTags, tag)
 		return true
@@ -292,7 +292,7 @@ func TestFusedDeltaTagAll(t *testing.T) {
 	var gotTS []int64
 	var gotTags []string
 
-	FusedDeltaTagAll(tsData, tagData, 5, func(i int, ts int64, tag string) bool {
+	FusedDeltaTagAll(tsData, tagData, 5, nil, func(i int, ts int64, tag string) bool {
 		gotTS = append(gotTS, ts)
 		gotTags = append(gotTags, tag)
 		return true
@@ -312,7 +312,7 @@ func TestFusedDeltaPackedGorillaTagAll_PartialGroupAlignment(t *testing.T) {
 	gotTimestamps := make([]int64, 0, len(timestamps))
 	gotValues := make([]float64, 0, len(values))
 	gotTags := make([]string, 0, len(tags))
-	FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, len(timestamps), func(i int, ts int64, val float64, tag string) bool {
+	FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, len(timestamps), nil, func(i int, ts int64, val float64, tag string) bool {
 		gotIndices = append(gotIndices, i)
 		gotTimestamps = append(gotTimestamps, ts)
 		gotValues = append(gotValues, val)
@@ -366,7 +366,7 @@ func TestFusedDeltaPackedGorillaTagAll_MalformedStreamsStopAtValidData(t *testin
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			gotIndices := make([]int, 0, tt.wantCount)
-			FusedDeltaPackedGorillaTagAll(tt.tsData, tt.valData, tt.tagData, len(timestamps), func(i int, _ int64, _ float64, _ string) bool {
+			FusedDeltaPackedGorillaTagAll(tt.tsData, tt.valData, tt.tagData, len(timestamps), nil, func(i int, _ int64, _ float64, _ string) bool {
 				gotIndices = append(gotIndices, i)
 				return true
 			})
@@ -385,7 +385,7 @@ func TestFusedDeltaPackedGorillaTagAll_CallbackShortCircuit(t *testing.T) {
 
 	const stopAfter = 3
 	callbackCount := 0
-	FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, len(timestamps), func(i int, ts int64, val float64, tag string) bool {
+	FusedDeltaPackedGorillaTagAll(tsData, valData, tagData, len(timestamps), nil, func(i int, ts int64, val float64, tag string) bool {
 		callbackCount++
 		require.Equal(t, callbackCount-1, i)
 		require.Equal(t, timestamps[i], ts)
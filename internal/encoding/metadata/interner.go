@@ -0,0 +1,67 @@
+package metadata
+
+import "sync"
+
+// Interner deduplicates strings decoded from byte slices, so repeated tags and
+// text values share a single heap allocation.
+//
+// Lookups on already-interned strings do not allocate. The interner is safe for
+// concurrent use. A nil *Interner is valid and performs no interning, which lets
+// decoders call it unconditionally.
+type Interner struct {
+	mu      sync.RWMutex
+	strings map[string]string
+}
+
+// NewInterner creates an empty string interner.
+//
+// Returns:
+//   - *Interner: A new interner ready for use
+func NewInterner() *Interner {
+	return &Interner{strings: make(map[string]string)}
+}
+
+// String returns the interned string with the contents of b.
+//
+// Parameters:
+//   - b: String bytes (not retained)
+//
+// Returns:
+//   - string: The canonical string equal to string(b)
+func (in *Interner) String(b []byte) string {
+	if in == nil {
+		return string(b)
+	}
+
+	// The map index with a string(b) conversion is optimized to not allocate.
+	in.mu.RLock()
+	s, ok := in.strings[string(b)]
+	in.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	s = string(b)
+
+	in.mu.Lock()
+	if existing, ok := in.strings[s]; ok {
+		s = existing // Interned concurrently by another goroutine
+	} else {
+		in.strings[s] = s
+	}
+	in.mu.Unlock()
+
+	return s
+}
+
+// Len returns the number of distinct interned strings.
+func (in *Interner) Len() int {
+	if in == nil {
+		return 0
+	}
+
+	in.mu.RLock()
+	defer in.mu.RUnlock()
+
+	return len(in.strings)
+}
@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+
+	a := in.String([]byte("host=a"))
+	b := in.String([]byte("host=a"))
+	require.Equal(t, "host=a", a)
+	require.Same(t, unsafe.StringData(a), unsafe.StringData(b))
+	require.Equal(t, "host=b", in.String([]byte("host=b")))
+	require.Empty(t, in.String(nil))
+	require.Equal(t, 3, in.Len())
+
+	buf := []byte("host=a")
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = in.String(buf)
+	})
+	require.Zero(t, allocs)
+
+	var nilInterner *Interner
+	require.Equal(t, "x", nilInterner.String([]byte("x")))
+	require.Zero(t, nilInterner.Len())
+}
+
+func TestInterner_Concurrent(t *testing.T) {
+	in := NewInterner()
+	results := make([]string, 8)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Go(func() {
+			for range 100 {
+				results[i] = in.String([]byte("shared"))
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, s := range results {
+		require.Same(t, unsafe.StringData(results[0]), unsafe.StringData(s))
+	}
+	require.Equal(t, 1, in.Len())
+}
+
+func TestTagDecoder_Interner(t *testing.T) {
+	engine := endian.GetLittleEndianEngine()
+	encoder := NewTagEncoder(engine)
+	defer encoder.Finish()
+	encoder.WriteSlice([]string{"a", "b", "a", "a"})
+	data := encoder.Bytes()
+
+	in := NewInterner()
+	decoder := NewTagDecoderWithInterner(engine, in)
+
+	var tags []string
+	for tag := range decoder.All(data, 4) {
+		tags = append(tags, tag)
+	}
+	require.Equal(t, []string{"a", "b", "a", "a"}, tags)
+	require.Same(t, unsafe.StringData(tags[0]), unsafe.StringData(tags[3]))
+
+	tag, ok := decoder.At(data, 2, 4)
+	require.True(t, ok)
+	require.Same(t, unsafe.StringData(tags[0]), unsafe.StringData(tag))
+
+	cursor := NewTagCursor(data, in)
+	tag, ok = cursor.Next()
+	require.True(t, ok)
+	require.Same(t, unsafe.StringData(tags[0]), unsafe.StringData(tag))
+	require.Equal(t, 2, in.Len())
+}
@@ -201,16 +201,20 @@ func (e *TagEncoder) WriteSlice(tags []string) {
 }
 
 type TagDecoder struct {
-	engine endian.EndianEngine
+	engine   endian.EndianEngine
+	interner *Interner // Optional, nil disables interning
+	bitmap   bool      // Tags use the presence bitmap layout (see AppendBitmapTags)
 }
 
 // TagCursor incrementally decodes the length-prefixed tag stream used by fused iteration.
 type TagCursor struct {
-	data   []byte
-	offset int
+	data     []byte
+	offset   int
+	interner *Interner // Optional, nil disables interning
 }
 
 var _ encoding.ColumnarDecoder[string] = TagDecoder{}
+var _ encoding.CapabilityReporter = TagDecoder{}
 
 // NewTagDecoder creates a new tag decoder.
 // The engine parameter is kept for interface compatibility but not used
@@ -227,9 +231,43 @@ func NewTagDecoder(engine endian.EndianEngine) TagDecoder {
 	}
 }
 
+// NewTagDecoderWithInterner creates a new tag decoder that deduplicates decoded
+// tags through interner.
+//
+// Parameters:
+//   - engine: Endian engine (currently unused but kept for interface compatibility)
+//   - interner: String interner, nil disables interning
+//
+// Returns:
+//   - TagDecoder: A new decoder instance (stateless, can be reused)
+func NewTagDecoderWithInterner(engine endian.EndianEngine, interner *Interner) TagDecoder {
+	return TagDecoder{
+		engine:   engine,
+		interner: interner,
+	}
+}
+
+// NewBitmapTagDecoder creates a tag decoder for tags in the presence bitmap layout
+// (see AppendBitmapTags). A non-nil interner deduplicates the decoded tags.
+//
+// Parameters:
+//   - engine: Endian engine (currently unused but kept for interface compatibility)
+//   - interner: String interner, nil disables interning
+//
+// Returns:
+//   - TagDecoder: A new decoder instance (stateless, can be reused)
+func NewBitmapTagDecoder(engine endian.EndianEngine, interner *Interner) TagDecoder {
+	return TagDecoder{
+		engine:   engine,
+		interner: interner,
+		bitmap:   true,
+	}
+}
+
 // NewTagCursor creates a cursor over encoded tag data.
-func NewTagCursor(data []byte) TagCursor {
-	return TagCursor{data: data}
+// A non-nil interner deduplicates the decoded tags.
+func NewTagCursor(data []byte, interner *Interner) TagCursor {
+	return TagCursor{data: data, interner: interner}
 }
 
 // Next returns the next tag and false when the payload is exhausted or malformed.
@@ -240,12 +278,25 @@ func (c *TagCursor) Next() (string, bool) {
 	}
 
 	c.offset += varintSize
-	tag := string(c.data[c.offset : c.offset+tagLen])
+	tag := c.interner.String(c.data[c.offset : c.offset+tagLen])
 	c.offset += tagLen
 
 	return tag, true
 }
 
+// Skip advances past the next tag without decoding it and returns false when
+// the payload is exhausted or malformed.
+func (c *TagCursor) Skip() bool {
+	tagLen, varintSize, ok := decodeTagAt(c.data, c.offset)
+	if !ok {
+		return false
+	}
+
+	c.offset += varintSize + tagLen
+
+	return true
+}
+
 // All returns an iterator that yields all decoded items from the provided encoded data.
 //
 // Parameters:
@@ -255,6 +306,10 @@ func (c *TagCursor) Next() (string, bool) {
 // Returns:
 //   - iter.Seq[string]: Iterator yielding decoded string tags
 func (d TagDecoder) All(data []byte, count int) iter.Seq[string] {
+	if d.bitmap {
+		return d.allBitmap(data, count)
+	}
+
 	return func(yield func(string) bool) {
 		offset := 0
 		for range count {
@@ -265,7 +320,7 @@ func (d TagDecoder) All(data []byte, count int) iter.Seq[string] {
 
 			// Read tag bytes
 			offset += n
-			tag := string(data[offset : offset+tagLen])
+			tag := d.interner.String(data[offset : offset+tagLen])
 			offset += tagLen
 
 			if !yield(tag) {
@@ -275,6 +330,18 @@ func (d TagDecoder) All(data []byte, count int) iter.Seq[string] {
 	}
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: tags are length-prefixed, so At scans from the start.
+func (d TagDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d TagDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At retrieves the tag at the specified index from the encoded data.
 // The index is zero-based, so index 0 retrieves the first tag.
 //
@@ -293,6 +360,10 @@ func (d TagDecoder) At(data []byte, index int, count int) (string, bool) {
 		return "", false
 	}
 
+	if d.bitmap {
+		return d.atBitmap(data, index, count)
+	}
+
 	offset := 0
 	for i := 0; i <= index; i++ {
 		tagLen, n, ok := decodeTagAt(data, offset)
@@ -303,7 +374,7 @@ func (d TagDecoder) At(data []byte, index int, count int) (string, bool) {
 		offset += n
 
 		if i == index {
-			tag := string(data[offset : offset+tagLen])
+			tag := d.interner.String(data[offset : offset+tagLen])
 			return tag, true
 		}
 
@@ -313,6 +384,61 @@ func (d TagDecoder) At(data []byte, index int, count int) (string, bool) {
 	return "", false
 }
 
+// allBitmap iterates tags in the presence bitmap layout.
+func (d TagDecoder) allBitmap(data []byte, count int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		offset := TagBitmapSize(count)
+		if len(data) < offset {
+			return
+		}
+
+		for i := range count {
+			tag := ""
+			if data[i>>3]&(1<<(i&7)) != 0 {
+				tagLen, n, ok := decodeTagAt(data, offset)
+				if !ok {
+					return
+				}
+
+				offset += n
+				tag = d.interner.String(data[offset : offset+tagLen])
+				offset += tagLen
+			}
+
+			if !yield(tag) {
+				return
+			}
+		}
+	}
+}
+
+// atBitmap retrieves a tag in the presence bitmap layout. Empty tags are answered
+// from the bitmap alone; present tags skip only the present tags before them.
+func (d TagDecoder) atBitmap(data []byte, index int, count int) (string, bool) {
+	bitmapSize := TagBitmapSize(count)
+	if len(data) < bitmapSize {
+		return "", false
+	}
+
+	rank, present := bitmapTagRank(data[:bitmapSize], index)
+	if !present {
+		return "", true
+	}
+
+	offset, ok := skipTags(data, bitmapSize, rank)
+	if !ok {
+		return "", false
+	}
+
+	tagLen, n, ok := decodeTagAt(data, offset)
+	if !ok {
+		return "", false
+	}
+	offset += n
+
+	return d.interner.String(data[offset : offset+tagLen]), true
+}
+
 // decodeTagAt decodes tag metadata at the given offset.
 // Returns the tag length in bytes, the varint size, and whether the operation succeeded.
 // This helper eliminates code duplication between All() and At() methods.
@@ -0,0 +1,139 @@
+package metadata
+
+import "math/bits"
+
+// The presence bitmap tag layout stores the tags of one metric as:
+//
+//	[bitmap: TagBitmapSize(count) bytes][length:uvarint][bytes:UTF-8] for each non-empty tag
+//
+// Bit i%8 of bitmap byte i/8 is set when tag i is non-empty. Empty tags take no
+// space beyond their bit, which beats the one-byte zero-length varint of the plain
+// layout for metrics where most points carry no tag.
+
+// TagBitmapSize returns the size in bytes of the presence bitmap of count tags.
+func TagBitmapSize(count int) int {
+	return (count + 7) / 8
+}
+
+// CountEmptyTags returns the number of empty tags in plain encoded tag data.
+//
+// Parameters:
+//   - data: Encoded byte slice from TagEncoder.Bytes()
+//   - count: Number of tags in data
+//
+// Returns:
+//   - int: Number of empty tags
+//   - bool: false if data is malformed
+func CountEmptyTags(data []byte, count int) (int, bool) {
+	empty, offset := 0, 0
+	for range count {
+		tagLen, n, ok := decodeTagAt(data, offset)
+		if !ok {
+			return 0, false
+		}
+
+		if tagLen == 0 {
+			empty++
+		}
+		offset += n + tagLen
+	}
+
+	return empty, true
+}
+
+// AppendBitmapTags appends the presence bitmap layout of plain encoded tag data to dst.
+//
+// Parameters:
+//   - dst: Destination slice
+//   - data: Encoded byte slice from TagEncoder.Bytes()
+//   - count: Number of tags in data
+//
+// Returns:
+//   - []byte: dst with the converted tags appended
+//   - bool: false if data is malformed
+func AppendBitmapTags(dst, data []byte, count int) ([]byte, bool) {
+	bitmapStart := len(dst)
+	dst = append(dst, make([]byte, TagBitmapSize(count))...)
+
+	offset := 0
+	for i := range count {
+		tagLen, n, ok := decodeTagAt(data, offset)
+		if !ok {
+			return dst[:bitmapStart], false
+		}
+
+		if tagLen > 0 {
+			dst[bitmapStart+(i>>3)] |= 1 << (i & 7)
+			dst = append(dst, data[offset:offset+n+tagLen]...)
+		}
+		offset += n + tagLen
+	}
+
+	return dst, true
+}
+
+// AppendPlainTags appends the plain length-prefixed layout of presence bitmap
+// encoded tag data to dst.
+//
+// Parameters:
+//   - dst: Destination slice
+//   - data: Tag data in the presence bitmap layout
+//   - count: Number of tags in data
+//
+// Returns:
+//   - []byte: dst with the converted tags appended
+//   - bool: false if data is malformed
+func AppendPlainTags(dst, data []byte, count int) ([]byte, bool) {
+	bitmapSize := TagBitmapSize(count)
+	if len(data) < bitmapSize {
+		return dst, false
+	}
+
+	start := len(dst)
+	offset := bitmapSize
+	for i := range count {
+		if data[i>>3]&(1<<(i&7)) == 0 {
+			dst = append(dst, 0)
+			continue
+		}
+
+		tagLen, n, ok := decodeTagAt(data, offset)
+		if !ok {
+			return dst[:start], false
+		}
+
+		dst = append(dst, data[offset:offset+n+tagLen]...)
+		offset += n + tagLen
+	}
+
+	return dst, true
+}
+
+// bitmapTagRank reports whether the tag at index is present in a presence bitmap
+// and returns its rank: the number of present tags before it.
+func bitmapTagRank(bitmap []byte, index int) (int, bool) {
+	if bitmap[index>>3]&(1<<(index&7)) == 0 {
+		return 0, false
+	}
+
+	rank := 0
+	for _, b := range bitmap[:index>>3] {
+		rank += bits.OnesCount8(b)
+	}
+	rank += bits.OnesCount8(bitmap[index>>3] & (1<<(index&7) - 1))
+
+	return rank, true
+}
+
+// skipTags returns the offset after n encoded tags starting at offset.
+func skipTags(data []byte, offset, n int) (int, bool) {
+	for range n {
+		tagLen, varintSize, ok := decodeTagAt(data, offset)
+		if !ok {
+			return 0, false
+		}
+		offset += varintSize + tagLen
+	}
+
+	return offset, true
+}
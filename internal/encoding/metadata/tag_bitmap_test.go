@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBitmapTags_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{name: "AllEmpty", tags: []string{"", "", ""}},
+		{name: "Sparse", tags: []string{"", "", "", "", "", "", "", "", "error", "", "timeout", ""}},
+		{name: "Dense", tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}},
+	}
+
+	engine := endian.GetLittleEndianEngine()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoder := NewTagEncoder(engine)
+			t.Cleanup(encoder.Finish)
+			encoder.WriteSlice(tt.tags)
+			plain := encoder.Bytes()
+			count := len(tt.tags)
+
+			empty, ok := CountEmptyTags(plain, count)
+			require.True(t, ok)
+			wantEmpty := 0
+			for _, tag := range tt.tags {
+				if tag == "" {
+					wantEmpty++
+				}
+			}
+			require.Equal(t, wantEmpty, empty)
+
+			bitmap, ok := AppendBitmapTags([]byte{0xFF}, plain, count)
+			require.True(t, ok)
+			require.Equal(t, byte(0xFF), bitmap[0])
+			bitmap = bitmap[1:]
+			require.Len(t, bitmap, len(plain)-wantEmpty+TagBitmapSize(count))
+
+			decoder := NewBitmapTagDecoder(engine, nil)
+			require.Equal(t, tt.tags, collect(decoder.All(bitmap, count)))
+			for i, want := range tt.tags {
+				tag, ok := decoder.At(bitmap, i, count)
+				require.True(t, ok)
+				require.Equal(t, want, tag)
+			}
+			_, ok = decoder.At(bitmap, count, count)
+			require.False(t, ok)
+
+			back, ok := AppendPlainTags(nil, bitmap, count)
+			require.True(t, ok)
+			require.Equal(t, plain, back)
+		})
+	}
+}
+
+func TestBitmapTags_Malformed(t *testing.T) {
+	engine := endian.GetLittleEndianEngine()
+
+	// Truncated plain data
+	_, ok := CountEmptyTags([]byte{0, 5, 'a'}, 2)
+	require.False(t, ok)
+	_, ok = AppendBitmapTags(nil, []byte{0, 5, 'a'}, 2)
+	require.False(t, ok)
+
+	// Bitmap marks tag 1 present but no tag data follows
+	data := []byte{0b10}
+	_, ok = AppendPlainTags(nil, data, 2)
+	require.False(t, ok)
+	decoder := NewBitmapTagDecoder(engine, nil)
+	_, ok = decoder.At(data, 1, 2)
+	require.False(t, ok)
+	tag, ok := decoder.At(data, 0, 2)
+	require.True(t, ok)
+	require.Empty(t, tag)
+	require.Equal(t, []string{""}, collect(decoder.All(data, 2)))
+
+	// Missing bitmap
+	_, ok = AppendPlainTags(nil, nil, 9)
+	require.False(t, ok)
+	require.Empty(t, collect(decoder.All(nil, 9)))
+}
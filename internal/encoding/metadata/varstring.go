@@ -158,6 +158,17 @@ func (e *VarStringEncoder) WriteRaw(data []byte) {
 	e.buf.MustWrite(data)
 }
 
+// Truncate discards the data written after the encoder held size bytes and
+// count strings, e.g. to drop a partially written row.
+//
+// Parameters:
+//   - size: Byte size to truncate to, as previously returned by Size
+//   - count: String count to restore, as previously returned by Len
+func (e *VarStringEncoder) Truncate(size, count int) {
+	e.buf.SetLength(size)
+	e.count = count
+}
+
 // Bytes returns the encoded data as a byte slice.
 //
 // The returned slice shares the underlying buffer with the encoder.
@@ -246,3 +246,20 @@ func TestVarStringEncoder_BoundaryCase_256Chars(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "exceeds maximum")
 }
+
+func TestVarStringEncoder_Truncate(t *testing.T) {
+	encoder := NewVarStringEncoder(endian.GetLittleEndianEngine())
+	defer encoder.Reset()
+
+	require.NoError(t, encoder.Write("keep"))
+	size, count := encoder.Size(), encoder.Len()
+	require.NoError(t, encoder.Write("drop"))
+	encoder.WriteVarint(42)
+
+	encoder.Truncate(size, count)
+	require.Equal(t, 1, encoder.Len())
+	require.Equal(t, []byte{4, 'k', 'e', 'e', 'p'}, encoder.Bytes())
+
+	require.NoError(t, encoder.Write("x"))
+	require.Equal(t, []byte{4, 'k', 'e', 'e', 'p', 1, 'x'}, encoder.Bytes())
+}
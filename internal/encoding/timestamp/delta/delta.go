@@ -446,6 +446,7 @@ func (e *TimestampDeltaEncoder) Finish() {
 type TimestampDeltaDecoder struct{}
 
 var _ encoding.ColumnarDecoder[int64] = TimestampDeltaDecoder{}
+var _ encoding.CapabilityReporter = TimestampDeltaDecoder{}
 
 // NewTimestampDeltaDecoder creates a new high-performance delta-of-delta timestamp decoder.
 //
@@ -778,6 +779,18 @@ func (d TimestampDeltaDecoder) DecodeAll(data []byte, count int, dst []int64) in
 	return count
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: each timestamp is a delta-of-delta of its predecessors, so At decodes from the start.
+func (d TimestampDeltaDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d TimestampDeltaDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At returns the timestamp(as int64) at the specified index in the delta-of-delta encoded data.
 //
 // This method provides efficient random access to timestamps by decoding only
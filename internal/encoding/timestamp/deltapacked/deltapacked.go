@@ -431,6 +431,7 @@ type DeltaPackedTsState struct {
 }
 
 var _ encoding.ColumnarDecoder[int64] = TimestampDeltaPackedDecoder{}
+var _ encoding.CapabilityReporter = TimestampDeltaPackedDecoder{}
 
 // NewTimestampDeltaPackedDecoder creates a new Group Varint delta-of-delta timestamp decoder.
 //
@@ -812,6 +813,18 @@ func decodePackedGroupScalar(data []byte, offset int, zz *[groupSize]uint64, byt
 	return pos - offset, true
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: each timestamp is a delta-of-delta of its predecessors, so At decodes from the start.
+func (d TimestampDeltaPackedDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d TimestampDeltaPackedDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At returns the timestamp at the specified index.
 //
 // Parameters:
@@ -198,6 +198,21 @@ func (e *TimestampRawEncoder) Size() int {
 	return e.buf.Len()
 }
 
+// Grow preallocates the internal buffer for n more timestamps, so that the next
+// n writes do not allocate.
+//
+// Panics if Finish() has been called (nil buffer).
+//
+// Parameters:
+//   - n: Number of timestamps to preallocate
+func (e *TimestampRawEncoder) Grow(n int) {
+	if e.buf == nil {
+		panic("encoder already finished - cannot grow after Finish()")
+	}
+
+	e.buf.Grow(n * 8)
+}
+
 // Reset clears the encoder state, allowing it to be reused for a new sequence of timestamps.
 //
 // Due to the raw encoding strategy, Reset is implemented as a no-op to retain
@@ -250,6 +265,7 @@ type TimestampRawDecoder struct {
 }
 
 var _ encoding.ColumnarDecoder[int64] = TimestampRawDecoder{}
+var _ encoding.CapabilityReporter = TimestampRawDecoder{}
 
 // NewTimestampRawDecoder creates a new raw timestamp decoder using the specified endian engine.
 //
@@ -345,6 +361,18 @@ func (d TimestampRawDecoder) DecodeAll(data []byte, count int, dst []int64) int
 	return count
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// true: timestamps are fixed-width, so At indexes the payload directly.
+func (d TimestampRawDecoder) SupportsRandomAccess() bool {
+	return true
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns true, via At.
+func (d TimestampRawDecoder) SupportsReverse() bool {
+	return true
+}
+
 // At retrieves the timestamp at the specified index from the encoded data.
 //
 // The data should be the byte slice payload produced by a corresponding TimestampEncoder.
@@ -388,6 +416,7 @@ func (d TimestampRawDecoder) At(data []byte, index int, count int) (int64, bool)
 type TimestampRawUnsafeDecoder struct{}
 
 var _ encoding.ColumnarDecoder[int64] = TimestampRawUnsafeDecoder{}
+var _ encoding.CapabilityReporter = TimestampRawUnsafeDecoder{}
 
 // NewTimestampRawUnsafeDecoder creates a new raw timestamp decoder.
 //
@@ -463,6 +492,18 @@ func (d TimestampRawUnsafeDecoder) DecodeAll(data []byte, count int, dst []int64
 	return n
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// true: timestamps are fixed-width, so At indexes the payload directly.
+func (d TimestampRawUnsafeDecoder) SupportsRandomAccess() bool {
+	return true
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns true, via At.
+func (d TimestampRawUnsafeDecoder) SupportsReverse() bool {
+	return true
+}
+
 // At retrieves the timestamp at the specified index from the encoded data.
 //
 // The data should be the byte slice payload produced by a TimestampRawEncoder.
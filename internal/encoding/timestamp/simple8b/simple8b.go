@@ -312,6 +312,7 @@ type TimestampSimple8bDecoder struct {
 }
 
 var _ encoding.ColumnarDecoder[int64] = TimestampSimple8bDecoder{}
+var _ encoding.CapabilityReporter = TimestampSimple8bDecoder{}
 
 // NewTimestampSimple8bDecoder creates a Simple8b timestamp decoder. The engine
 // must match the encoder's.
@@ -399,6 +400,18 @@ func (d TimestampSimple8bDecoder) All(data []byte, count int) iter.Seq[int64] {
 	}
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: timestamps are delta-encoded in variable-width words, so At decodes from the start.
+func (d TimestampSimple8bDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d TimestampSimple8bDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At returns the timestamp at index via an O(n) scan (consistent with the Delta
 // and Gorilla decoders, which are also O(n) for random access).
 func (d TimestampSimple8bDecoder) At(data []byte, index int, count int) (int64, bool) {
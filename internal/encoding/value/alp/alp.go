@@ -863,6 +863,7 @@ type NumericALPDecoder struct {
 }
 
 var _ encoding.ColumnarDecoder[float64] = NumericALPDecoder{}
+var _ encoding.CapabilityReporter = NumericALPDecoder{}
 
 func NewNumericALPDecoder(engine endian.EndianEngine) NumericALPDecoder {
 	return NumericALPDecoder{engine: engine}
@@ -1284,6 +1285,18 @@ func (d NumericALPDecoder) DecodeAll(data []byte, count int, dst []float64) int
 	}
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// true: values are bit-packed at a fixed width per column, so At reads a single code.
+func (d NumericALPDecoder) SupportsRandomAccess() bool {
+	return true
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns true, via At.
+func (d NumericALPDecoder) SupportsReverse() bool {
+	return true
+}
+
 // At decodes a single value directly — an O(1) windowed bit read plus an
 // O(log k) binary search over the column's exception sidecar for ALP main/RD
 // (k = exceptions in the column, not count; see atMain/atRD), unlike the O(n)
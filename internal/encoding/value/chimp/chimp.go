@@ -342,6 +342,7 @@ func (e *NumericChimpEncoder) flushBits() {
 type NumericChimpDecoder struct{}
 
 var _ encoding.ColumnarDecoder[float64] = NumericChimpDecoder{}
+var _ encoding.CapabilityReporter = NumericChimpDecoder{}
 
 // NewNumericChimpDecoder creates a new Chimp decoder for float64 values.
 //
@@ -609,6 +610,18 @@ func (d NumericChimpDecoder) DecodeAll(data []byte, count int, dst []float64) in
 	return produced
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: each value is XORed against its predecessor, so At decodes from the start.
+func (d NumericChimpDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d NumericChimpDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At retrieves the float64 value at the specified index from the Chimp-compressed data.
 //
 // Parameters:
@@ -14,8 +14,37 @@ import (
 
 const (
 	gorillaSmallSequenceThreshold = 64
+
+	// maxWindowWaste is the largest number of bits a reused window can waste,
+	// i.e. window reuse is unrestricted.
+	maxWindowWaste = 64
 )
 
+// Tuning holds the encoder-side Gorilla tuning knobs.
+//
+// Both knobs only change which control-bit paths the encoder takes, so the
+// produced stream is decoded by the standard Gorilla decoder unchanged.
+type Tuning struct {
+	// MaxWindowWaste is the maximum number of wasted bits (previous window size
+	// minus the XOR's meaningful bits) tolerated when reusing the previous
+	// leading/trailing zero window. Exceeding it opens a new, tighter window.
+	// 64 (the default) always reuses a fitting window, as in the original paper.
+	MaxWindowWaste int
+
+	// RebaseInterval forces a fresh window every N values, discarding window
+	// state accumulated from earlier values. 0 (the default) disables it.
+	RebaseInterval int
+}
+
+// DefaultTuning returns the tuning used by NewNumericGorillaEncoder, which
+// matches the original Gorilla algorithm.
+//
+// Returns:
+//   - Tuning: Unrestricted window reuse with re-baselining disabled
+func DefaultTuning() Tuning {
+	return Tuning{MaxWindowWaste: maxWindowWaste}
+}
+
 // NumericGorillaEncoder implements Facebook's Gorilla compression algorithm for float64 time-series values.
 //
 // The algorithm uses XOR-based compression with leading/trailing zero optimization:
@@ -43,8 +72,10 @@ type NumericGorillaEncoder struct {
 	prevTrailing  int    // Trailing zeros in previous XOR
 	prevBlockSize int    // Cached block size: 64 - prevLeading - prevTrailing (performance optimization)
 	firstValue    bool   // True if this is the first value
+	maxWaste      int    // Max wasted bits when reusing the previous window
+	rebase        int    // Force a fresh window every rebase values (0 = disabled)
 
-	// Offset: 64, cold path field, place one cache line away to improve cache locality
+	// Offset: 80, cold path field, kept after the hot path fields to improve cache locality
 	buf *pool.ByteBuffer // Byte buffer for storing encoded data
 }
 
@@ -67,9 +98,27 @@ var _ encoding.ColumnarEncoder[float64] = (*NumericGorillaEncoder)(nil)
 // Returns:
 //   - *NumericGorillaEncoder: A new encoder instance ready for float64 encoding
 func NewNumericGorillaEncoder() *NumericGorillaEncoder {
+	return NewNumericGorillaEncoderWithTuning(DefaultTuning())
+}
+
+// NewNumericGorillaEncoderWithTuning creates a new Gorilla encoder with custom tuning.
+//
+// Long series can accumulate a wide leading/trailing zero window after a single
+// outlier; subsequent small changes keep reusing it and waste bits on every value.
+// Limiting window waste or periodically re-baselining lets the encoder recover.
+// Out-of-range values are clamped: MaxWindowWaste to [0, 64], RebaseInterval to >= 0.
+//
+// Parameters:
+//   - tuning: Encoder tuning knobs, see Tuning
+//
+// Returns:
+//   - *NumericGorillaEncoder: A new encoder instance ready for float64 encoding
+func NewNumericGorillaEncoderWithTuning(tuning Tuning) *NumericGorillaEncoder {
 	return &NumericGorillaEncoder{
 		buf:        pool.GetBlobBuffer(),
 		firstValue: true,
+		maxWaste:   min(max(tuning.MaxWindowWaste, 0), maxWindowWaste),
+		rebase:     max(tuning.RebaseInterval, 0),
 	}
 }
 
@@ -243,6 +292,12 @@ func (e *NumericGorillaEncoder) writeValue(valBits uint64) {
 	xor := valBits ^ e.prevValue
 	e.prevValue = valBits
 
+	// Re-baseline: drop the window so the next non-zero XOR opens a fresh one.
+	// count is 1-based, so this fires at value indices N, 2N, ...
+	if e.rebase > 0 && (e.count-1)%e.rebase == 0 {
+		e.prevBlockSize = 0
+	}
+
 	if xor == 0 {
 		// Value unchanged: a single 0 bit. bitBuf is MSB-aligned, so only the
 		// count advances; the bit itself is already zero.
@@ -278,7 +333,9 @@ func (e *NumericGorillaEncoder) writeValue(valBits uint64) {
 	//   - count == 2: second value (first XOR, no previous block to reuse)
 	//   - count > 2: can potentially reuse previous block
 	// We also need prevBlockSize > 0 to ensure we have valid previous block info
-	if e.count > 2 && e.prevBlockSize > 0 && leading >= e.prevLeading && trailing >= e.prevTrailing {
+	// The waste check only matters when tuning restricts reuse (maxWaste < 64).
+	if e.count > 2 && e.prevBlockSize > 0 && leading >= e.prevLeading && trailing >= e.prevTrailing &&
+		e.prevBlockSize-(64-leading-trailing) <= e.maxWaste {
 		// Same block: control bit 1, reuse bit 0, then meaningful bits
 		e.appendBits(0b10, 2)
 		e.appendBits(xor>>uint(e.prevTrailing), e.prevBlockSize)
@@ -353,6 +410,7 @@ func (e *NumericGorillaEncoder) flushBits() {
 type NumericGorillaDecoder struct{}
 
 var _ encoding.ColumnarDecoder[float64] = NumericGorillaDecoder{}
+var _ encoding.CapabilityReporter = NumericGorillaDecoder{}
 
 // NewNumericGorillaDecoder creates a new Gorilla decoder for float64 values.
 //
@@ -686,6 +744,18 @@ func (d NumericGorillaDecoder) DecodeAll(data []byte, count int, dst []float64)
 	return produced
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// false: each value is XORed against its predecessor, so At decodes from the start.
+func (d NumericGorillaDecoder) SupportsRandomAccess() bool {
+	return false
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns false.
+func (d NumericGorillaDecoder) SupportsReverse() bool {
+	return false
+}
+
 // At retrieves the float64 value at the specified index from the Gorilla-compressed data.
 //
 // This method decodes values sequentially up to the requested index.
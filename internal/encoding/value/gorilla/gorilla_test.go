@@ -383,6 +383,68 @@ func TestNumericGorillaRoundTrip_LargeDataset(t *testing.T) {
 	}
 }
 
+func TestNumericGorillaEncoder_Tuning(t *testing.T) {
+	// One wide XOR after the first value, followed by a long run of single-bit
+	// toggles: the default policy keeps reusing the 52-bit window.
+	base := math.Float64bits(1.0)
+	values := make([]float64, 1000)
+	values[0] = 1.0
+	values[1] = math.Float64frombits(base ^ 0x000F_FFFF_FFFF_FFFF)
+	for i := 2; i < len(values); i++ {
+		values[i] = math.Float64frombits(math.Float64bits(values[i-1]) ^ 1<<36)
+	}
+
+	encode := func(tuning Tuning) []byte {
+		encoder := NewNumericGorillaEncoderWithTuning(tuning)
+		t.Cleanup(encoder.Finish)
+		encoder.WriteSlice(values)
+
+		return encoder.Bytes()
+	}
+
+	decode := func(t *testing.T, data []byte) {
+		t.Helper()
+
+		decoder := NewNumericGorillaDecoder()
+		decoded := make([]float64, 0, len(values))
+		for v := range decoder.All(data, len(values)) {
+			decoded = append(decoded, v)
+		}
+		require.Equal(t, values, decoded)
+
+		val, ok := decoder.At(data, 777, len(values))
+		require.True(t, ok)
+		require.Equal(t, values[777], val)
+	}
+
+	defaultData := encode(DefaultTuning())
+	decode(t, defaultData)
+
+	tests := []struct {
+		name   string
+		tuning Tuning
+	}{
+		{name: "WindowWasteLimit", tuning: Tuning{MaxWindowWaste: 8}},
+		{name: "ExactWindowReuse", tuning: Tuning{MaxWindowWaste: 0}},
+		{name: "RebaseInterval", tuning: Tuning{MaxWindowWaste: maxWindowWaste, RebaseInterval: 64}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := encode(tt.tuning)
+			decode(t, data)
+			require.Less(t, len(data), len(defaultData))
+		})
+	}
+
+	t.Run("ClampedOutOfRange", func(t *testing.T) {
+		encoder := NewNumericGorillaEncoderWithTuning(Tuning{MaxWindowWaste: 1000, RebaseInterval: -5})
+		defer encoder.Finish()
+		require.Equal(t, maxWindowWaste, encoder.maxWaste)
+		require.Zero(t, encoder.rebase)
+	})
+}
+
 func TestNumericGorillaRoundTrip_RandomAccess(t *testing.T) {
 	encoder := NewNumericGorillaEncoder()
 	expected := []float64{10.0, 20.0, 30.0, 40.0, 50.0, 60.0, 70.0, 80.0, 90.0, 100.0}
@@ -183,6 +183,21 @@ func (e *NumericRawEncoder) Size() int {
 	return e.buf.Len()
 }
 
+// Grow preallocates the internal buffer for n more float values, so that the next
+// n writes do not allocate.
+//
+// Panics if Finish() has been called (nil buffer).
+//
+// Parameters:
+//   - n: Number of float values to preallocate
+func (e *NumericRawEncoder) Grow(n int) {
+	if e.buf == nil {
+		panic("encoder already finished - cannot grow after Finish()")
+	}
+
+	e.buf.Grow(n * 8)
+}
+
 // Reset clears the encoder state, allowing it to be reused for a new sequence of timestamps.
 //
 // Due to the raw encoding strategy, Reset is implemented as a no-op to retain
@@ -237,6 +252,7 @@ type NumericRawDecoder struct {
 }
 
 var _ encoding.ColumnarDecoder[float64] = NumericRawDecoder{}
+var _ encoding.CapabilityReporter = NumericRawDecoder{}
 
 // NewNumericRawDecoder creates a new raw numeric decoder using the specified endian engine.
 //
@@ -313,6 +329,18 @@ func (d NumericRawDecoder) DecodeAll(data []byte, count int, dst []float64) int
 	return count
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// true: values are fixed-width, so At indexes the payload directly.
+func (d NumericRawDecoder) SupportsRandomAccess() bool {
+	return true
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns true, via At.
+func (d NumericRawDecoder) SupportsReverse() bool {
+	return true
+}
+
 // At retrieves the float64 value at the specified index from the encoded data.
 //
 // The data should be the byte slice payload produced by a NumericRawEncoder.
@@ -360,6 +388,7 @@ type NumericRawUnsafeDecoder struct {
 }
 
 var _ encoding.ColumnarDecoder[float64] = NumericRawUnsafeDecoder{}
+var _ encoding.CapabilityReporter = NumericRawUnsafeDecoder{}
 
 // NewNumericRawUnsafeDecoder creates a new raw numeric decoder using unsafe operations for optimal performance.
 //
@@ -448,6 +477,18 @@ func (d NumericRawUnsafeDecoder) DecodeAll(data []byte, count int, dst []float64
 	return count
 }
 
+// SupportsRandomAccess reports whether At runs in constant time. It returns
+// true: values are fixed-width, so At indexes the payload directly.
+func (d NumericRawUnsafeDecoder) SupportsRandomAccess() bool {
+	return true
+}
+
+// SupportsReverse reports whether values can be read from last to first
+// without a front-to-back decode. It returns true, via At.
+func (d NumericRawUnsafeDecoder) SupportsReverse() bool {
+	return true
+}
+
 // At retrieves the float64 value at the specified index from the encoded data using unsafe memory operations.
 //
 // The data should be the byte slice payload produced by a NumericRawEncoder.
@@ -0,0 +1,80 @@
+package sketch
+
+import "math"
+
+// KahanSum is a compensated (Kahan-Babuska-Neumaier) sum of a stream of values
+// together with the number of values, so means of long or mixed-magnitude
+// series do not accumulate rounding errors.
+//
+// The running sum is kept next to a compensation term holding the low-order
+// bits lost by each addition. NaN values are ignored.
+//
+// The zero value is an empty sum. KahanSum is not safe for concurrent use.
+type KahanSum struct {
+	sum   float64
+	comp  float64
+	count uint64
+}
+
+// NewKahanSum restores a sum from its parts, e.g. after deserialization.
+func NewKahanSum(sum, comp float64, count uint64) KahanSum {
+	return KahanSum{sum: sum, comp: comp, count: count}
+}
+
+// Add adds a value. NaN values are ignored.
+func (s *KahanSum) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+
+	s.add(v)
+	s.count++
+}
+
+// Merge adds all values summed by other to s, keeping both compensations.
+func (s *KahanSum) Merge(other KahanSum) {
+	if other.count == 0 {
+		return
+	}
+
+	s.add(other.sum)
+	s.add(other.comp)
+	s.count += other.count
+}
+
+// Count returns the number of added values.
+func (s KahanSum) Count() uint64 {
+	return s.count
+}
+
+// Sum returns the running sum without its compensation.
+func (s KahanSum) Sum() float64 {
+	return s.sum
+}
+
+// Compensation returns the low-order bits lost by the running sum.
+func (s KahanSum) Compensation() float64 {
+	return s.comp
+}
+
+// Value returns the compensated sum. Sums holding infinite values return the
+// running sum, since the compensation of an infinite sum is undefined.
+func (s KahanSum) Value() float64 {
+	if math.IsInf(s.sum, 0) || math.IsNaN(s.sum) {
+		return s.sum
+	}
+
+	return s.sum + s.comp
+}
+
+// add adds v to the running sum, accumulating the rounding error of the
+// addition in the compensation.
+func (s *KahanSum) add(v float64) {
+	t := s.sum + v
+	if math.Abs(s.sum) >= math.Abs(v) {
+		s.comp += (s.sum - t) + v
+	} else {
+		s.comp += (v - t) + s.sum
+	}
+	s.sum = t
+}
@@ -0,0 +1,58 @@
+package sketch
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKahanSum(t *testing.T) {
+	// 1 + 1e100 + 1 - 1e100 loses both ones in naive summation.
+	var s KahanSum
+	for _, v := range []float64{1, 1e100, math.NaN(), 1, -1e100} {
+		s.Add(v)
+	}
+	require.Equal(t, uint64(4), s.Count())
+	require.Equal(t, 2.0, s.Value())
+
+	// Summing 0.1 ten million times drifts naively but not when compensated.
+	var tenth KahanSum
+	naive := 0.0
+	for range 10_000_000 {
+		tenth.Add(0.1)
+		naive += 0.1
+	}
+	require.NotEqual(t, 1e6, naive)
+	require.Equal(t, 1e6, tenth.Value())
+
+	restored := NewKahanSum(s.Sum(), s.Compensation(), s.Count())
+	require.Equal(t, s, restored)
+
+	var empty KahanSum
+	require.Equal(t, 0.0, empty.Value())
+	require.Equal(t, uint64(0), empty.Count())
+}
+
+func TestKahanSum_Merge(t *testing.T) {
+	var a, b, whole KahanSum
+	for i := range 1000 {
+		v := 0.1 * float64(i%7)
+		whole.Add(v)
+		if i < 300 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+
+	a.Merge(b)
+	a.Merge(KahanSum{})
+	require.Equal(t, whole.Count(), a.Count())
+	require.Equal(t, whole.Value(), a.Value())
+
+	var inf KahanSum
+	inf.Add(math.Inf(1))
+	inf.Add(1)
+	require.True(t, math.IsInf(inf.Value(), 1))
+}
@@ -0,0 +1,124 @@
+package sketch
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+)
+
+// Item is a key tracked by a SpaceSaving sketch.
+type Item struct {
+	Key   uint64
+	Count float64 // estimated weight, an upper bound of the true weight
+	Error float64 // maximum overestimation of Count
+}
+
+// SpaceSaving is a weighted space-saving sketch (Metwally et al.) tracking the
+// heaviest keys of a stream in bounded memory.
+//
+// It monitors at most capacity keys. A new key arriving while the sketch is
+// full replaces the lightest monitored key and inherits its weight as error, so
+// every key whose true weight exceeds total/capacity is guaranteed to be kept.
+// Counts are exact while fewer than capacity distinct keys were added.
+//
+// SpaceSaving is not safe for concurrent use.
+type SpaceSaving struct {
+	capacity int
+	items    itemHeap // min-heap by Count
+}
+
+// itemHeap is a min-heap of items that keeps the position index up to date.
+type itemHeap struct {
+	items []Item
+	index map[uint64]int // key -> position in items
+}
+
+// NewSpaceSaving creates an empty sketch monitoring at most capacity keys. A
+// capacity of 0 or less is treated as 1.
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	capacity = max(capacity, 1)
+
+	return &SpaceSaving{
+		capacity: capacity,
+		items:    itemHeap{items: make([]Item, 0, capacity), index: make(map[uint64]int, capacity)},
+	}
+}
+
+// Add adds weight to key. Non-positive weights are ignored.
+func (s *SpaceSaving) Add(key uint64, weight float64) {
+	if !(weight > 0) {
+		return
+	}
+
+	if i, ok := s.items.index[key]; ok {
+		s.items.items[i].Count += weight
+		heap.Fix(&s.items, i)
+
+		return
+	}
+
+	if len(s.items.items) < s.capacity {
+		heap.Push(&s.items, Item{Key: key, Count: weight})
+
+		return
+	}
+
+	// Replace the lightest key, which the new key may have been counted as.
+	lightest := s.items.items[0]
+	delete(s.items.index, lightest.Key)
+	s.items.items[0] = Item{Key: key, Count: lightest.Count + weight, Error: lightest.Count}
+	s.items.index[key] = 0
+	heap.Fix(&s.items, 0)
+}
+
+// Len returns the number of monitored keys.
+func (s *SpaceSaving) Len() int {
+	return len(s.items.items)
+}
+
+// TopK returns up to k monitored keys with the highest counts, sorted by count
+// descending and then by key ascending.
+func (s *SpaceSaving) TopK(k int) []Item {
+	if k <= 0 {
+		return nil
+	}
+
+	items := slices.Clone(s.items.items)
+	slices.SortFunc(items, func(a, b Item) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return items[:min(k, len(items))]
+}
+
+func (h itemHeap) Len() int { return len(h.items) }
+
+func (h itemHeap) Less(i, j int) bool { return h.items[i].Count < h.items[j].Count }
+
+func (h itemHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Key] = i
+	h.index[h.items[j].Key] = j
+}
+
+func (h *itemHeap) Push(x any) {
+	item, ok := x.(Item)
+	if !ok {
+		return
+	}
+
+	h.index[item.Key] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *itemHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, last.Key)
+
+	return last
+}
@@ -0,0 +1,40 @@
+package sketch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpaceSaving_ExactBelowCapacity(t *testing.T) {
+	s := NewSpaceSaving(10)
+	for key := range uint64(5) {
+		for range key + 1 {
+			s.Add(key, 2)
+		}
+	}
+	s.Add(9, 0)
+
+	require.Equal(t, 5, s.Len())
+	require.Equal(t, []Item{{Key: 4, Count: 10}, {Key: 3, Count: 8}}, s.TopK(2))
+	require.Len(t, s.TopK(100), 5)
+	require.Empty(t, s.TopK(0))
+}
+
+func TestSpaceSaving_HeavyHitters(t *testing.T) {
+	s := NewSpaceSaving(8)
+	for i := range uint64(10000) {
+		// Keys 0-2 are heavy, the rest is a long tail of distinct keys.
+		s.Add(i%3, 1)
+		s.Add(1000+i, 1)
+	}
+
+	top := s.TopK(3)
+	require.Len(t, top, 3)
+	for i, item := range top {
+		require.Equal(t, uint64(i), item.Key)
+		require.GreaterOrEqual(t, item.Count, 3333.0)
+		require.LessOrEqual(t, item.Count-item.Error, 3334.0)
+	}
+	require.Equal(t, 8, s.Len())
+}
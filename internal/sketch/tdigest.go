@@ -0,0 +1,223 @@
+// Package sketch provides streaming summaries for approximate analytics over
+// blob data: a merging t-digest for quantiles, a weighted space-saving
+// counter for top-k heavy hitters and a compensated sum for means.
+package sketch
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// DefaultCompression is the t-digest compression used when none is given. It
+// keeps at most about DefaultCompression centroids, with quantile errors well
+// below 1% of the rank in the middle and far smaller near the tails.
+const DefaultCompression = 100
+
+// Centroid is a cluster of values summarized by their mean and count.
+type Centroid struct {
+	Mean   float64
+	Weight float64
+}
+
+// TDigest is a merging t-digest (Dunning & Ertl) estimating quantiles of a
+// stream of values in bounded memory.
+//
+// Values are buffered and periodically merged into centroids sorted by mean.
+// Centroids near the median may hold many values, while those near the tails
+// stay small, which keeps extreme quantiles accurate.
+//
+// TDigest is not safe for concurrent use.
+type TDigest struct {
+	compression float64
+	centroids   []Centroid // merged centroids sorted by mean
+	buffer      []Centroid // values added since the last merge
+	count       float64
+	min, max    float64
+}
+
+// NewTDigest creates an empty t-digest. A compression of 0 or less selects
+// DefaultCompression.
+func NewTDigest(compression float64) *TDigest {
+	if compression <= 0 {
+		compression = DefaultCompression
+	}
+
+	return &TDigest{
+		compression: compression,
+		centroids:   make([]Centroid, 0, int(2*compression)),
+		buffer:      make([]Centroid, 0, int(5*compression)),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// NewTDigestFromCentroids restores a t-digest from its centroids, e.g. after
+// deserialization. The centroids must be sorted by mean and lie within
+// [minVal, maxVal]; the slice is retained, not copied.
+func NewTDigestFromCentroids(compression float64, centroids []Centroid, minVal, maxVal float64) *TDigest {
+	t := NewTDigest(compression)
+	if len(centroids) == 0 {
+		return t
+	}
+
+	t.centroids = centroids
+	for _, c := range centroids {
+		t.count += c.Weight
+	}
+	t.min, t.max = minVal, maxVal
+
+	return t
+}
+
+// Add adds a value with weight 1. NaN values are ignored.
+func (t *TDigest) Add(v float64) {
+	t.AddWeighted(v, 1)
+}
+
+// AddWeighted adds a value with the given weight. NaN values and non-positive
+// weights are ignored.
+func (t *TDigest) AddWeighted(v, weight float64) {
+	if math.IsNaN(v) || !(weight > 0) {
+		return
+	}
+
+	t.buffer = append(t.buffer, Centroid{Mean: v, Weight: weight})
+	t.count += weight
+	t.min = min(t.min, v)
+	t.max = max(t.max, v)
+
+	if len(t.buffer) == cap(t.buffer) {
+		t.compress()
+	}
+}
+
+// Merge adds all values summarized by other to t. other is not modified.
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil || other.count == 0 {
+		return
+	}
+
+	t.buffer = append(t.buffer, other.centroids...)
+	t.buffer = append(t.buffer, other.buffer...)
+	t.count += other.count
+	t.min = min(t.min, other.min)
+	t.max = max(t.max, other.max)
+
+	t.compress()
+}
+
+// Reset empties the digest, keeping its allocated memory.
+func (t *TDigest) Reset() {
+	t.centroids = t.centroids[:0]
+	t.buffer = t.buffer[:0]
+	t.count = 0
+	t.min, t.max = math.Inf(1), math.Inf(-1)
+}
+
+// Count returns the total weight of the added values.
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// Min returns the smallest added value, or +Inf if the digest is empty.
+func (t *TDigest) Min() float64 {
+	return t.min
+}
+
+// Max returns the largest added value, or -Inf if the digest is empty.
+func (t *TDigest) Max() float64 {
+	return t.max
+}
+
+// Centroids returns the merged centroids sorted by mean. The slice is owned
+// by the digest and valid until the next modification.
+func (t *TDigest) Centroids() []Centroid {
+	t.compress()
+
+	return t.centroids
+}
+
+// Quantile returns the estimated value at quantile q in [0, 1], interpolating
+// between centroids. It returns NaN for an empty digest or a q outside [0, 1].
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.count == 0 || !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+
+	t.compress()
+
+	switch {
+	case q == 0:
+		return t.min
+	case q == 1:
+		return t.max
+	case len(t.centroids) == 1:
+		return t.centroids[0].Mean
+	}
+
+	target := q * t.count
+	cumulative := 0.0
+	for i, c := range t.centroids {
+		center := cumulative + c.Weight/2
+		if target < center {
+			if i == 0 {
+				// Between the minimum and the center of the first centroid
+				return t.min + (c.Mean-t.min)*target/center
+			}
+
+			prev := t.centroids[i-1]
+			prevCenter := cumulative - prev.Weight/2
+
+			return prev.Mean + (c.Mean-prev.Mean)*(target-prevCenter)/(center-prevCenter)
+		}
+		cumulative += c.Weight
+	}
+
+	// Between the center of the last centroid and the maximum
+	last := t.centroids[len(t.centroids)-1]
+	lastCenter := t.count - last.Weight/2
+
+	return last.Mean + (t.max-last.Mean)*(target-lastCenter)/(t.count-lastCenter)
+}
+
+// compress merges the buffered values into the centroids.
+//
+// Adjacent centroids are merged while the merged centroid spans at most one
+// unit of the k1 scale function k(q) = compression / (2π) * asin(2q - 1), which
+// bounds the number of centroids by the compression and keeps tails small.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+
+	all := append(t.centroids, t.buffer...)
+	t.buffer = t.buffer[:0]
+	slices.SortFunc(all, func(a, b Centroid) int {
+		return cmp.Compare(a.Mean, b.Mean)
+	})
+
+	merged := make([]Centroid, 0, cap(t.centroids))
+	cur := all[0]
+	weightSoFar := 0.0
+	for _, c := range all[1:] {
+		proposed := cur.Weight + c.Weight
+		if t.scale((weightSoFar+proposed)/t.count)-t.scale(weightSoFar/t.count) <= 1 {
+			cur.Mean += (c.Mean - cur.Mean) * c.Weight / proposed
+			cur.Weight = proposed
+
+			continue
+		}
+
+		merged = append(merged, cur)
+		weightSoFar += cur.Weight
+		cur = c
+	}
+
+	t.centroids = append(merged, cur)
+}
+
+// scale is the k1 scale function mapping a quantile to centroid index units.
+func (t *TDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*min(q, 1)-1)
+}
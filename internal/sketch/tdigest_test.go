@@ -0,0 +1,88 @@
+package sketch
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTDigest_Quantile(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2)) //nolint: gosec
+	values := make([]float64, 100000)
+	td := NewTDigest(0)
+	for i := range values {
+		values[i] = rng.Float64() * 1000
+		td.Add(values[i])
+	}
+	td.Add(math.NaN())
+	slices.Sort(values)
+
+	require.Equal(t, float64(len(values)), td.Count())
+	require.Equal(t, values[0], td.Quantile(0))
+	require.Equal(t, values[len(values)-1], td.Quantile(1))
+	require.LessOrEqual(t, len(td.Centroids()), 2*DefaultCompression)
+
+	for _, q := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		want := values[int(q*float64(len(values)))]
+		require.InDelta(t, want, td.Quantile(q), 5, "q=%v", q)
+	}
+
+	require.True(t, math.IsNaN(td.Quantile(1.5)))
+	require.True(t, math.IsNaN(NewTDigest(0).Quantile(0.5)))
+}
+
+func TestTDigest_Merge(t *testing.T) {
+	a, b, all := NewTDigest(50), NewTDigest(50), NewTDigest(50)
+	for i := range 10000 {
+		v := float64(i)
+		all.Add(v)
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+
+	a.Merge(b)
+	require.Equal(t, all.Count(), a.Count())
+	require.Equal(t, 0.0, a.Min())
+	require.Equal(t, 9999.0, a.Max())
+	require.InDelta(t, all.Quantile(0.5), a.Quantile(0.5), 50)
+	require.InDelta(t, 9900, a.Quantile(0.99), 20)
+}
+
+func TestTDigest_SmallCounts(t *testing.T) {
+	td := NewTDigest(0)
+	td.Add(7)
+	require.Equal(t, 7.0, td.Quantile(0.5))
+
+	td.Add(1)
+	td.Add(4)
+	require.Equal(t, 4.0, td.Quantile(0.5))
+	require.Equal(t, 1.0, td.Min())
+	require.Equal(t, 7.0, td.Max())
+}
+
+func TestTDigest_FromCentroids(t *testing.T) {
+	td := NewTDigest(0)
+	for i := range 1000 {
+		td.Add(float64(i))
+	}
+
+	restored := NewTDigestFromCentroids(0, slices.Clone(td.Centroids()), td.Min(), td.Max())
+	require.Equal(t, td.Count(), restored.Count())
+	for _, q := range []float64{0, 0.25, 0.5, 0.99, 1} {
+		require.Equal(t, td.Quantile(q), restored.Quantile(q))
+	}
+
+	// A restored digest keeps accepting values.
+	restored.Add(5000)
+	require.Equal(t, 5000.0, restored.Max())
+
+	td.Reset()
+	require.Zero(t, td.Count())
+	require.True(t, math.IsNaN(td.Quantile(0.5)))
+}
@@ -60,9 +60,11 @@
 package mebo
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/arloliu/mebo/internal/hash"
 )
@@ -107,6 +109,8 @@ var defaultTextOptions = []blob.TextEncoderOption{
 //
 // Returns an error if the configuration is invalid.
 //
+// Options set by SetDefaultEncoderOptions are applied before opts.
+//
 // Example:
 //
 //	encoder, err := mebo.NewNumericEncoder(time.Now(),
@@ -114,7 +118,7 @@ var defaultTextOptions = []blob.TextEncoderOption{
 //	    blob.WithValueCompression(format.CompressionZstd),
 //	)
 func NewNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOption) (*blob.NumericEncoder, error) {
-	return blob.NewNumericEncoder(startTime, opts...)
+	return blob.NewNumericEncoder(startTime, numericOptions(nil, opts...)...)
 }
 
 // NewDefaultNumericEncoder creates a numeric encoder with recommended default settings.
@@ -131,7 +135,8 @@ func NewNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOption) (
 //   - Your metrics don't need tags
 //   - You're storing typical numeric time-series data
 //
-// For tagged metrics, use NewTaggedNumericEncoder instead.
+// For tagged metrics, use NewTaggedNumericEncoder instead. Options set by
+// SetDefaultEncoderOptions override the settings above.
 //
 // Parameters:
 //   - startTime: The earliest timestamp in the blob
@@ -147,7 +152,7 @@ func NewNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOption) (
 //	    log.Fatal(err)
 //	}
 func NewDefaultNumericEncoder(startTime time.Time) (*blob.NumericEncoder, error) {
-	return blob.NewNumericEncoder(startTime, defaultNumericOptions...)
+	return blob.NewNumericEncoder(startTime, numericOptions(defaultNumericOptions)...)
 }
 
 // NewTaggedNumericEncoder creates a numeric encoder with tag support enabled.
@@ -162,7 +167,8 @@ func NewDefaultNumericEncoder(startTime time.Time) (*blob.NumericEncoder, error)
 //   - Any contextual string data
 //
 // The encoder inherits default settings (Delta timestamps, Gorilla values, no compression)
-// but you can override them with additional options.
+// and the options set by SetDefaultEncoderOptions, but you can override them with
+// additional options.
 //
 // Parameters:
 //   - startTime: The earliest timestamp in the blob
@@ -184,7 +190,7 @@ func NewDefaultNumericEncoder(startTime time.Time) (*blob.NumericEncoder, error)
 //	    encoder.AddDataPoint(ts.UnixMicro(), 42.0+float64(i), "host=server1")
 //	}
 func NewTaggedNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOption) (*blob.NumericEncoder, error) {
-	allOpts := append(append(defaultNumericOptions, blob.WithTagsEnabled(true)), opts...)
+	allOpts := numericOptions(defaultNumericOptions, append([]blob.NumericEncoderOption{blob.WithTagsEnabled(true)}, opts...)...)
 	return blob.NewNumericEncoder(startTime, allOpts...)
 }
 
@@ -195,6 +201,7 @@ func NewTaggedNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOpt
 //
 // Parameters:
 //   - data: The raw blob bytes (from encoder.Finish().Bytes() or storage)
+//   - opts: Optional decoder options (e.g., blob.WithValueTransform)
 //
 // Returns:
 //   - *blob.NumericDecoder: The created numeric decoder.
@@ -215,8 +222,8 @@ func NewTaggedNumericEncoder(startTime time.Time, opts ...blob.NumericEncoderOpt
 //	for dp := range decoder.All(metricID) {
 //	    fmt.Printf("ts=%d, val=%f\n", dp.Ts, dp.Val)
 //	}
-func NewNumericDecoder(data []byte) (*blob.NumericDecoder, error) {
-	return blob.NewNumericDecoder(data)
+func NewNumericDecoder(data []byte, opts ...blob.NumericDecoderOption) (*blob.NumericDecoder, error) {
+	return blob.NewNumericDecoder(data, opts...)
 }
 
 // NewTextEncoder creates a new text metric encoder with custom options.
@@ -240,17 +247,21 @@ func NewNumericDecoder(data []byte) (*blob.NumericDecoder, error) {
 //   - blob.WithTextTimestampEncoding(format.TypeRaw|TypeDelta)
 //   - blob.WithTextDataCompression(format.CompressionNone|Zstd|S2|LZ4)
 //   - blob.WithTextTagsEnabled(true|false)
+//   - blob.WithTextTagCompression(format.CompressionNone|Zstd|S2|LZ4)
+//   - blob.WithTextValueCompression(threshold, format.CompressionZstd|S2|LZ4)
 //
 // Note: Text values are stored as length-prefixed strings. Compression is highly
 // recommended for text data (CompressionZstd or CompressionS2).
 //
+// Options set by SetDefaultTextEncoderOptions are applied before opts.
+//
 // Example:
 //
 //	encoder, err := mebo.NewTextEncoder(time.Now(),
 //	    blob.WithTextDataCompression(format.CompressionZstd),
 //	)
 func NewTextEncoder(startTime time.Time, opts ...blob.TextEncoderOption) (*blob.TextEncoder, error) {
-	return blob.NewTextEncoder(startTime, opts...)
+	return blob.NewTextEncoder(startTime, textOptions(nil, opts...)...)
 }
 
 // NewDefaultTextEncoder creates a text encoder with recommended default settings.
@@ -266,6 +277,8 @@ func NewTextEncoder(startTime time.Time, opts ...blob.TextEncoderOption) (*blob.
 //   - Your metrics don't need tags
 //   - You're storing typical text time-series data
 //
+// Options set by SetDefaultTextEncoderOptions override the settings above.
+//
 // Parameters:
 //   - startTime: The earliest timestamp in the blob
 //
@@ -287,14 +300,15 @@ func NewTextEncoder(startTime time.Time, opts ...blob.TextEncoderOption) (*blob.
 //	    encoder.AddDataPoint(ts.UnixMicro(), status, "")
 //	}
 func NewDefaultTextEncoder(startTime time.Time) (*blob.TextEncoder, error) {
-	return blob.NewTextEncoder(startTime, defaultTextOptions...)
+	return blob.NewTextEncoder(startTime, textOptions(defaultTextOptions)...)
 }
 
 // NewTaggedTextEncoder creates a text encoder with tag support enabled.
 //
 // Similar to NewTaggedNumericEncoder but for string values. Use when you need both
 // string values and metadata tags.
-// It inherits default settings (Delta timestamps, Zstd compression) with tags enabled.
+// It inherits default settings (Delta timestamps, Zstd compression) and the options set by
+// SetDefaultTextEncoderOptions, with tags enabled.
 //
 // Parameters:
 //   - startTime: The earliest timestamp in the blob
@@ -316,7 +330,7 @@ func NewDefaultTextEncoder(startTime time.Time) (*blob.TextEncoder, error) {
 //	    encoder.AddDataPoint(ts.UnixMicro(), "ERROR", "service=api")
 //	}
 func NewTaggedTextEncoder(startTime time.Time, opts ...blob.TextEncoderOption) (*blob.TextEncoder, error) {
-	allOpts := append(append(defaultTextOptions, blob.WithTextTagsEnabled(true)), opts...)
+	allOpts := textOptions(defaultTextOptions, append([]blob.TextEncoderOption{blob.WithTextTagsEnabled(true)}, opts...)...)
 	return blob.NewTextEncoder(startTime, allOpts...)
 }
 
@@ -556,3 +570,48 @@ func NewBlobSet(numericBlobs []blob.NumericBlob, textBlobs []blob.TextBlob) blob
 func MetricID(name string) uint64 {
 	return hash.ID(name)
 }
+
+// MetricIDs converts a batch of metric names to their 64-bit hash identifiers,
+// checking the batch for collisions.
+//
+// Use MetricIDs to validate a known metric universe once, e.g. at startup, when
+// encoding with StartMetricID: encoders only detect colliding IDs when the second
+// metric is started, and ID mode cannot store names to resolve them.
+//
+// Parameters:
+//   - names: Metric names to hash
+//
+// Returns:
+//   - []uint64: Metric IDs, where ids[i] = MetricID(names[i])
+//   - error: ErrHashCollision if two distinct names hash to the same ID, or
+//     ErrInvalidMetricName if a name is empty or listed more than once
+//
+// Example:
+//
+//	ids, err := mebo.MetricIDs([]string{"cpu.usage", "memory.bytes"})
+//	if err != nil {
+//	    return err
+//	}
+//	encoder.StartMetricID(ids[0], 100)
+func MetricIDs(names []string) ([]uint64, error) {
+	ids := make([]uint64, len(names))
+	seen := make(map[uint64]string, len(names))
+	for i, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("%w: empty metric name at index %d", errs.ErrInvalidMetricName, i)
+		}
+
+		id := hash.ID(name)
+		if prev, ok := seen[id]; ok {
+			if prev == name {
+				return nil, fmt.Errorf("%w: metric name %q listed more than once", errs.ErrInvalidMetricName, name)
+			}
+
+			return nil, fmt.Errorf("%w: metric names %q and %q share ID 0x%016x", errs.ErrHashCollision, prev, name, id)
+		}
+		seen[id] = name
+		ids[i] = id
+	}
+
+	return ids, nil
+}
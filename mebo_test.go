@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 )
 
@@ -288,6 +289,23 @@ func TestMetricID(t *testing.T) {
 	require.NotEqual(t, id1, differentID)
 }
 
+func TestMetricIDs(t *testing.T) {
+	ids, err := MetricIDs([]string{"cpu.usage", "memory.bytes"})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{MetricID("cpu.usage"), MetricID("memory.bytes")}, ids)
+
+	ids, err = MetricIDs(nil)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	_, err = MetricIDs([]string{"cpu.usage", "memory.bytes", "cpu.usage"})
+	require.ErrorIs(t, err, errs.ErrInvalidMetricName)
+	require.ErrorContains(t, err, "listed more than once")
+
+	_, err = MetricIDs([]string{"cpu.usage", ""})
+	require.ErrorIs(t, err, errs.ErrInvalidMetricName)
+}
+
 // Helper function to create test numeric blob
 func createTestNumericBlob(t *testing.T, startTime time.Time, offset int) blob.NumericBlob {
 	t.Helper()
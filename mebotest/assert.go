@@ -0,0 +1,181 @@
+package mebotest
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// point is a decoded data point.
+type point struct {
+	ts  int64
+	val float64
+	tag string
+}
+
+// AssertSeriesEqual asserts that the metric holds exactly the given timestamps
+// and values, in order. Values are compared with ==, except that NaN equals NaN;
+// tags are ignored.
+//
+// Parameters:
+//   - t: The test
+//   - b: The blob to check
+//   - metric: Metric name, resolved like NumericBlob.AllByName
+//   - wantTs: Expected timestamps
+//   - wantVals: Expected values, one per timestamp
+//
+// Returns:
+//   - bool: true if the series matches, false after reporting the first difference
+//
+// Example:
+//
+//	mebotest.AssertSeriesEqual(t, b, "cpu", []int64{ts1, ts2}, []float64{0.5, 0.7})
+func AssertSeriesEqual(t testing.TB, b blob.NumericBlob, metric string, wantTs []int64, wantVals []float64) bool {
+	t.Helper()
+
+	if len(wantTs) != len(wantVals) {
+		t.Errorf("mebotest: %d expected timestamps but %d expected values for metric %q", len(wantTs), len(wantVals), metric)
+		return false
+	}
+
+	if !b.HasMetricName(metric) && !b.HasMetricID(hash.ID(metric)) {
+		t.Errorf("mebotest: metric %q not found", metric)
+		return false
+	}
+
+	want := make([]point, len(wantTs))
+	for i, ts := range wantTs {
+		want[i] = point{ts: ts, val: wantVals[i]}
+	}
+
+	got := make([]point, 0, b.LenByName(metric))
+	for _, dp := range b.AllByName(metric) {
+		got = append(got, point{ts: dp.Ts, val: dp.Val})
+	}
+
+	if msg := diffPoints(want, got); msg != "" {
+		t.Errorf("mebotest: metric %q: %s", metric, msg)
+		return false
+	}
+
+	return true
+}
+
+// AssertBlobEqual asserts that two blobs hold the same metrics with the same
+// data points, e.g. a blob before and after a round trip through storage.
+//
+// Blobs are compared by content, not by bytes: encodings, compression and
+// metric order may differ. Timestamps, values and tags must match exactly,
+// except that NaN equals NaN.
+//
+// Parameters:
+//   - t: The test
+//   - want: The expected blob
+//   - got: The actual blob
+//
+// Returns:
+//   - bool: true if the blobs match, false after reporting every differing metric
+//
+// Example:
+//
+//	mebotest.AssertBlobEqual(t, original, mebotest.DecodeNumeric(t, stored))
+func AssertBlobEqual(t testing.TB, want, got blob.NumericBlob) bool {
+	t.Helper()
+
+	wantIDs, gotIDs := sortedIDs(want), sortedIDs(got)
+	names := metricNames(want, got)
+	equal := true
+
+	for _, id := range wantIDs {
+		if _, found := slices.BinarySearch(gotIDs, id); !found {
+			t.Errorf("mebotest: metric %s missing", metricLabel(names, id))
+			equal = false
+
+			continue
+		}
+
+		if msg := diffPoints(points(want, id), points(got, id)); msg != "" {
+			t.Errorf("mebotest: metric %s: %s", metricLabel(names, id), msg)
+			equal = false
+		}
+	}
+
+	for _, id := range gotIDs {
+		if _, found := slices.BinarySearch(wantIDs, id); !found {
+			t.Errorf("mebotest: unexpected metric %s", metricLabel(names, id))
+			equal = false
+		}
+	}
+
+	return equal
+}
+
+// diffPoints describes the first difference between two point sequences, or
+// returns "" if they are equal.
+func diffPoints(want, got []point) string {
+	for i := range min(len(want), len(got)) {
+		w, g := want[i], got[i]
+		if w.ts != g.ts || !sameValue(w.val, g.val) || w.tag != g.tag {
+			return fmt.Sprintf("data point %d differs: want %s, got %s", i, formatPoint(w), formatPoint(g))
+		}
+	}
+
+	if len(want) != len(got) {
+		return fmt.Sprintf("want %d data points, got %d", len(want), len(got))
+	}
+
+	return ""
+}
+
+// sameValue reports whether two values are equal, treating NaN as equal to NaN.
+func sameValue(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
+
+// points returns the data points of the metric.
+func points(b blob.NumericBlob, id uint64) []point {
+	pts := make([]point, 0, b.Len(id))
+	for _, dp := range b.All(id) {
+		pts = append(pts, point{ts: dp.Ts, val: dp.Val, tag: dp.Tag})
+	}
+
+	return pts
+}
+
+// sortedIDs returns the metric IDs of the blob in ascending order.
+func sortedIDs(b blob.NumericBlob) []uint64 {
+	ids := b.MetricIDs()
+	slices.Sort(ids)
+
+	return ids
+}
+
+// metricNames maps the metric IDs of the blobs to their names, where stored.
+func metricNames(blobs ...blob.NumericBlob) map[uint64]string {
+	names := make(map[uint64]string)
+	for _, b := range blobs {
+		for _, name := range b.MetricNames() {
+			names[hash.ID(name)] = name
+		}
+	}
+
+	return names
+}
+
+// metricLabel returns the quoted name of the metric, or its ID if unnamed.
+func metricLabel(names map[uint64]string, id uint64) string {
+	if name, ok := names[id]; ok {
+		return fmt.Sprintf("%q", name)
+	}
+
+	return formatID(id)
+}
+
+// formatID formats a metric ID like mebo error messages do.
+func formatID(id uint64) string {
+	return fmt.Sprintf("0x%016x", id)
+}
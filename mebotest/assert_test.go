@@ -0,0 +1,87 @@
+package mebotest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// recorder is a testing.TB that records reported failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSeriesEqual(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	cpu := LinearSeries("cpu", start, time.Second, 1, math.NaN(), 3)
+	b := NumericBlob(t, start, []Series{cpu})
+
+	require.True(t, AssertSeriesEqual(t, b, "cpu", cpu.Timestamps, []float64{1, math.NaN(), 3}))
+
+	tests := []struct {
+		name     string
+		metric   string
+		wantTs   []int64
+		wantVals []float64
+		errMsg   string
+	}{
+		{name: "value", metric: "cpu", wantTs: cpu.Timestamps, wantVals: []float64{1, 2, 3}, errMsg: "data point 1 differs"},
+		{name: "length", metric: "cpu", wantTs: cpu.Timestamps[:2], wantVals: []float64{1, math.NaN()}, errMsg: "want 2 data points, got 3"},
+		{name: "missing", metric: "mem", wantTs: nil, wantVals: nil, errMsg: `metric "mem" not found`},
+		{name: "mismatched want", metric: "cpu", wantTs: cpu.Timestamps, wantVals: nil, errMsg: "3 expected timestamps but 0 expected values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{TB: t}
+			require.False(t, AssertSeriesEqual(rec, b, tt.metric, tt.wantTs, tt.wantVals))
+			require.Len(t, rec.errors, 1)
+			require.Contains(t, rec.errors[0], tt.errMsg)
+		})
+	}
+}
+
+func TestAssertBlobEqual(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	cpu := Series{Name: "cpu", Timestamps: []int64{1, 2}, Values: []float64{0.5, 0.7}, Tags: []string{"a", "b"}}
+	mem := LinearSeries("mem", start, time.Second, 10, 20)
+	want := NumericBlob(t, start, []Series{cpu, mem})
+
+	// Different encodings and metric order still compare equal.
+	got := NumericBlob(t, start, []Series{mem, cpu},
+		blob.WithTimestampEncoding(format.TypeRaw), blob.WithValueEncoding(format.TypeChimp))
+	require.True(t, AssertBlobEqual(t, want, got))
+
+	cpu.Tags = []string{"a", "c"}
+	disk := LinearSeries("disk", start, time.Second, 1)
+	rec := &recorder{TB: t}
+	require.False(t, AssertBlobEqual(rec, want, NumericBlob(t, start, []Series{cpu, disk})))
+	require.ElementsMatch(t, []string{
+		`mebotest: metric ` + formatID(hash.ID("cpu")) + `: data point 1 differs: want (2, 0.7, "b"), got (2, 0.7, "c")`,
+		`mebotest: metric ` + formatID(hash.ID("mem")) + ` missing`,
+		`mebotest: unexpected metric ` + formatID(hash.ID("disk")),
+	}, rec.errors)
+
+	// Stored metric names label the metrics.
+	require.Equal(t, `"cpu"`, metricLabel(map[uint64]string{1: "cpu"}, 1))
+}
+
+func TestEncodeNumeric_IDs(t *testing.T) {
+	b := NumericBlob(t, time.Unix(1700000000, 0), []Series{{ID: 7, Timestamps: []int64{1}, Values: []float64{2}}})
+	require.Equal(t, []uint64{7}, b.MetricIDs())
+	require.Equal(t, "0x0000000000000007", seriesLabel(Series{ID: 7}))
+}
@@ -0,0 +1,30 @@
+// Package mebotest provides helpers for testing applications that embed mebo.
+//
+// It covers the three steps of a typical round-trip test without hand-written
+// decode and collect loops: building fixture blobs from a few series, asserting
+// decoded series and blobs, and comparing blobs against golden snapshot files.
+//
+//	func TestExport(t *testing.T) {
+//	    start := time.Unix(1700000000, 0)
+//	    input := mebotest.NumericBlob(t, start, []mebotest.Series{
+//	        mebotest.LinearSeries("cpu", start, time.Second, 1, 2, 3),
+//	    })
+//
+//	    got := mebotest.DecodeNumeric(t, exportAndReimport(input))
+//	    mebotest.AssertBlobEqual(t, input, got)
+//	    mebotest.AssertSeriesEqual(t, got, "cpu",
+//	        []int64{start.UnixMicro(), start.Add(time.Second).UnixMicro(), start.Add(2 * time.Second).UnixMicro()},
+//	        []float64{1, 2, 3})
+//	    mebotest.AssertGolden(t, "testdata/export.golden", got)
+//	}
+//
+// Assertions report failures with t.Errorf and return whether they passed, like
+// testify's assert package; fixture builders stop the test with t.Fatalf.
+//
+// # Golden Files
+//
+// AssertGolden compares the Snapshot of a blob, a line-oriented text rendering
+// of every data point, with the content of a file. Run the tests with the
+// environment variable MEBO_UPDATE_GOLDEN=1 to write the current snapshots
+// instead, then review the diff of the golden files before committing them.
+package mebotest
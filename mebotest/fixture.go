@@ -0,0 +1,160 @@
+package mebotest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+// Series is the data of one metric of a fixture blob.
+type Series struct {
+	// Name identifies the metric. If empty, ID is used instead; all series of a
+	// blob must use either names or IDs.
+	Name string
+
+	// ID identifies the metric when Name is empty.
+	ID uint64
+
+	// Timestamps are the data point timestamps in the blob's timestamp unit
+	// (microseconds by default).
+	Timestamps []int64
+
+	// Values are the data point values, one per timestamp.
+	Values []float64
+
+	// Tags are the optional data point tags, one per timestamp. Blobs get tag
+	// support if any series has tags.
+	Tags []string
+}
+
+// LinearSeries returns a series named name with one data point per value,
+// spaced step apart from start, with microsecond timestamps.
+//
+// Parameters:
+//   - name: Metric name
+//   - start: Time of the first data point
+//   - step: Interval between data points
+//   - values: Data point values
+//
+// Returns:
+//   - Series: The series without tags
+//
+// Example:
+//
+//	cpu := mebotest.LinearSeries("cpu", start, 10*time.Second, 0.5, 0.7, 0.6)
+func LinearSeries(name string, start time.Time, step time.Duration, values ...float64) Series {
+	timestamps := make([]int64, len(values))
+	for i := range values {
+		timestamps[i] = start.Add(time.Duration(i) * step).UnixMicro()
+	}
+
+	return Series{Name: name, Timestamps: timestamps, Values: values}
+}
+
+// EncodeNumeric encodes the series into a numeric blob starting at start,
+// stopping the test on any error.
+//
+// Parameters:
+//   - t: The test
+//   - start: Start time of the blob
+//   - series: Metrics to encode, in order
+//   - opts: Encoder options, applied after WithTagsEnabled(true) if any series has tags
+//
+// Returns:
+//   - []byte: The encoded blob
+func EncodeNumeric(t testing.TB, start time.Time, series []Series, opts ...blob.NumericEncoderOption) []byte {
+	t.Helper()
+
+	for _, s := range series {
+		if len(s.Tags) > 0 {
+			opts = append([]blob.NumericEncoderOption{blob.WithTagsEnabled(true)}, opts...)
+			break
+		}
+	}
+
+	encoder, err := blob.NewNumericEncoder(start, opts...)
+	if err != nil {
+		t.Fatalf("mebotest: failed to create numeric encoder: %v", err)
+	}
+
+	for _, s := range series {
+		if s.Name != "" {
+			err = encoder.StartMetricName(s.Name, len(s.Timestamps))
+		} else {
+			err = encoder.StartMetricID(s.ID, len(s.Timestamps))
+		}
+		if err == nil {
+			err = encoder.AddDataPoints(s.Timestamps, s.Values, s.Tags)
+		}
+		if err == nil {
+			err = encoder.EndMetric()
+		}
+		if err != nil {
+			t.Fatalf("mebotest: failed to encode series %s: %v", seriesLabel(s), err)
+		}
+	}
+
+	data, err := encoder.Finish()
+	if err != nil {
+		t.Fatalf("mebotest: failed to finish numeric blob: %v", err)
+	}
+
+	return data
+}
+
+// NumericBlob encodes the series into a numeric blob and decodes it, stopping
+// the test on any error. See EncodeNumeric.
+//
+// Parameters:
+//   - t: The test
+//   - start: Start time of the blob
+//   - series: Metrics to encode, in order
+//   - opts: Encoder options
+//
+// Returns:
+//   - blob.NumericBlob: The decoded blob
+//
+// Example:
+//
+//	b := mebotest.NumericBlob(t, start, []mebotest.Series{
+//	    {Name: "cpu", Timestamps: []int64{ts1, ts2}, Values: []float64{1, 2}, Tags: []string{"a", "b"}},
+//	}, blob.WithValueEncoding(format.TypeChimp))
+func NumericBlob(t testing.TB, start time.Time, series []Series, opts ...blob.NumericEncoderOption) blob.NumericBlob {
+	t.Helper()
+
+	return DecodeNumeric(t, EncodeNumeric(t, start, series, opts...))
+}
+
+// DecodeNumeric decodes an encoded numeric blob, stopping the test on any error.
+//
+// Parameters:
+//   - t: The test
+//   - data: The encoded blob
+//
+// Returns:
+//   - blob.NumericBlob: The decoded blob
+func DecodeNumeric(t testing.TB, data []byte) blob.NumericBlob {
+	t.Helper()
+
+	decoder, err := blob.NewNumericDecoder(data)
+	if err != nil {
+		t.Fatalf("mebotest: failed to create numeric decoder: %v", err)
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("mebotest: failed to decode numeric blob: %v", err)
+	}
+
+	return b
+}
+
+// seriesLabel returns the name of the series, or its ID if unnamed.
+func seriesLabel(s Series) string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return formatID(s.ID)
+}
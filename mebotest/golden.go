@@ -0,0 +1,136 @@
+package mebotest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden write
+// the current snapshots to the golden files instead of comparing them.
+const UpdateGoldenEnv = "MEBO_UPDATE_GOLDEN"
+
+// Snapshot renders the content of a blob as stable, line-oriented text for
+// golden files: the start time, then every metric in metric ID order with one
+// line per data point. Metrics are labeled by ID, preceded by their quoted name
+// if the blob stores metric names. Values are formatted so that they parse back
+// to the same float64.
+//
+// Parameters:
+//   - b: The blob to render
+//
+// Returns:
+//   - string: The snapshot text
+//
+// Example output:
+//
+//	start 2023-11-14T22:13:20Z
+//	metric 0x4196f531ce64819b (2 points)
+//	  (1700000000000000, 0.5, "host=a")
+//	  (1700000010000000, 0.7, "host=a")
+func Snapshot(b blob.NumericBlob) string {
+	names := metricNames(b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "start %s\n", b.StartTime().UTC().Format(time.RFC3339Nano))
+	for _, id := range sortedIDs(b) {
+		pts := points(b, id)
+		if name, ok := names[id]; ok {
+			fmt.Fprintf(&sb, "metric %q %s (%d points)\n", name, formatID(id), len(pts))
+		} else {
+			fmt.Fprintf(&sb, "metric %s (%d points)\n", formatID(id), len(pts))
+		}
+
+		for _, p := range pts {
+			sb.WriteString("  ")
+			sb.WriteString(formatPoint(p))
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+// AssertGolden asserts that the Snapshot of the blob equals the content of the
+// golden file at path. With the environment variable MEBO_UPDATE_GOLDEN set to
+// a non-empty value, it writes the snapshot to path instead, creating missing
+// directories.
+//
+// Parameters:
+//   - t: The test
+//   - path: Path of the golden file, usually under testdata/
+//   - b: The blob to check
+//
+// Returns:
+//   - bool: true if the snapshot matches or was written, false after reporting
+//     the first differing line
+//
+// Example:
+//
+//	mebotest.AssertGolden(t, "testdata/downsampled.golden", result)
+func AssertGolden(t testing.TB, path string, b blob.NumericBlob) bool {
+	t.Helper()
+
+	got := Snapshot(b)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Errorf("mebotest: failed to create golden file directory: %v", err)
+			return false
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil { //nolint: gosec
+			t.Errorf("mebotest: failed to write golden file: %v", err)
+			return false
+		}
+		t.Logf("mebotest: updated golden file %s", path)
+
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("mebotest: failed to read golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+		return false
+	}
+
+	if msg := diffLines(string(want), got); msg != "" {
+		t.Errorf("mebotest: snapshot differs from golden file %s (run with %s=1 to update it): %s", path, UpdateGoldenEnv, msg)
+		return false
+	}
+
+	return true
+}
+
+// diffLines describes the first differing line of two texts, or returns "" if
+// they are equal.
+func diffLines(want, got string) string {
+	if want == got {
+		return ""
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Sprintf("line %d: want %q, got %q", i+1, w, g)
+		}
+	}
+
+	return "trailing newlines differ"
+}
+
+// formatPoint formats a data point as (timestamp, value, "tag").
+func formatPoint(p point) string {
+	return fmt.Sprintf("(%d, %s, %q)", p.ts, strconv.FormatFloat(p.val, 'g', -1, 64), p.tag)
+}
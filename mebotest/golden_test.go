@@ -0,0 +1,38 @@
+package mebotest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertGolden(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	b := NumericBlob(t, start, []Series{
+		{Name: "cpu", Timestamps: []int64{1, 2}, Values: []float64{0.5, 1e-300}, Tags: []string{"host=a", ""}},
+	})
+
+	require.Equal(t, "start 2023-11-14T22:13:20Z\n"+
+		"metric 0x4196f531ce64819b (2 points)\n"+
+		"  (1, 0.5, \"host=a\")\n"+
+		"  (2, 1e-300, \"\")\n", Snapshot(b))
+
+	path := filepath.Join(t.TempDir(), "golden", "cpu.golden")
+
+	rec := &recorder{TB: t}
+	require.False(t, AssertGolden(rec, path, b))
+	require.Contains(t, rec.errors[0], UpdateGoldenEnv+"=1 to create it")
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	require.True(t, AssertGolden(t, path, b))
+	t.Setenv(UpdateGoldenEnv, "")
+	require.True(t, AssertGolden(t, path, b))
+
+	require.NoError(t, os.WriteFile(path, []byte("start 2023-11-14T22:13:20Z\nmetric\n"), 0o600))
+	rec = &recorder{TB: t}
+	require.False(t, AssertGolden(rec, path, b))
+	require.Contains(t, rec.errors[0], `line 2: want "metric", got "metric 0x4196f531ce64819b (2 points)"`)
+}
@@ -0,0 +1,25 @@
+// Package pathological generates float value patterns that are hard on XOR-based
+// value encodings, together with the Gorilla-encoded size expected for each.
+//
+// Gorilla stores unchanged values in a single bit and small changes in a few
+// bits, but degrades to up to 77 bits per value when consecutive values share
+// no leading or trailing bits. The patterns of this package probe both ends:
+// denormals, alternating signs, exponent jumps, long constant runs, special
+// values and random bit patterns.
+//
+// Each Pattern is deterministic for a given seed and carries an Envelope, the
+// range of bits per value its Gorilla encoding is expected to take. Users can
+// predict worst-case blob sizes from the envelopes, and encoder changes can be
+// validated by checking GorillaSize against them:
+//
+//	for _, p := range pathological.All() {
+//	    values := p.Generate(1000, seed)
+//	    if size := pathological.GorillaSize(values); !p.Envelope.Contains(len(values), size) {
+//	        t.Errorf("%s: %d bytes outside %v", p.Name, size, p.Envelope)
+//	    }
+//	}
+//
+// The generated values also make good seed corpora for fuzz and property tests
+// of anything storing floats in mebo blobs; Bytes flattens them into the []byte
+// form accepted by testing.F.Add.
+package pathological
@@ -0,0 +1,84 @@
+package pathological
+
+import (
+	"fmt"
+	"math"
+
+	ienc "github.com/arloliu/mebo/internal/encoding"
+)
+
+// gorillaFirstValueBits is the size of the first value of a Gorilla stream,
+// which is stored uncompressed.
+const gorillaFirstValueBits = 64
+
+// Envelope bounds the Gorilla-encoded size of a pattern in bits per value,
+// excluding the first value, which always takes 64 bits.
+//
+// The bounds are measured on the patterns of this package with long series and
+// various seeds; they hold for series of 100 values or more. Shorter series are
+// dominated by the first value and window setup costs.
+type Envelope struct {
+	// MinBitsPerValue is the expected lower bound of bits per value.
+	MinBitsPerValue float64
+
+	// MaxBitsPerValue is the expected upper bound of bits per value.
+	MaxBitsPerValue float64
+}
+
+// Bounds returns the expected range of the Gorilla-encoded size of n values.
+//
+// Parameters:
+//   - n: Number of values
+//
+// Returns:
+//   - minBytes: Expected minimum size in bytes
+//   - maxBytes: Expected maximum size in bytes
+func (e Envelope) Bounds(n int) (minBytes, maxBytes int) {
+	if n <= 0 {
+		return 0, 0
+	}
+
+	rest := float64(n - 1)
+	minBits := gorillaFirstValueBits + rest*e.MinBitsPerValue
+	maxBits := gorillaFirstValueBits + rest*e.MaxBitsPerValue
+
+	return int(math.Floor(minBits / 8)), int(math.Ceil(maxBits / 8))
+}
+
+// Contains reports whether size bytes is within the expected Gorilla-encoded size
+// of n values.
+//
+// Parameters:
+//   - n: Number of values
+//   - size: Encoded size in bytes, e.g. from GorillaSize
+//
+// Returns:
+//   - bool: true if size is within Bounds(n)
+func (e Envelope) Contains(n, size int) bool {
+	lo, hi := e.Bounds(n)
+
+	return size >= lo && size <= hi
+}
+
+// String returns the envelope as a bits per value range.
+func (e Envelope) String() string {
+	return fmt.Sprintf("[%g, %g] bits/value", e.MinBitsPerValue, e.MaxBitsPerValue)
+}
+
+// GorillaSize returns the size in bytes of values encoded with mebo's Gorilla
+// value encoding, as stored in the value payload of a numeric blob before
+// compression.
+//
+// Parameters:
+//   - values: Values of one metric
+//
+// Returns:
+//   - int: Encoded size in bytes
+func GorillaSize(values []float64) int {
+	encoder := ienc.NewNumericGorillaEncoder()
+	defer encoder.Finish()
+
+	encoder.WriteSlice(values)
+
+	return len(encoder.Bytes())
+}
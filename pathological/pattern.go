@@ -0,0 +1,253 @@
+package pathological
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+)
+
+// ConstantRunLength is the number of equal values per run in ConstantRuns.
+const ConstantRunLength = 64
+
+// Pattern is a named generator of pathological float values.
+type Pattern struct {
+	// Name identifies the pattern, e.g. "denormals".
+	Name string
+
+	// Description explains what makes the pattern pathological.
+	Description string
+
+	// Envelope is the expected Gorilla-encoded size of the generated values.
+	Envelope Envelope
+
+	generate func(rng *rand.Rand, n int) []float64
+}
+
+// Generate returns n values of the pattern. The same n and seed always produce
+// the same values.
+//
+// Parameters:
+//   - n: Number of values (negative counts produce no values)
+//   - seed: Seed of the pseudo-random generator
+//
+// Returns:
+//   - []float64: Generated values
+func (p Pattern) Generate(n int, seed uint64) []float64 {
+	rng := rand.New(rand.NewPCG(seed, 0)) //nolint: gosec // reproducible test data, not security sensitive
+
+	return p.generate(rng, max(n, 0))
+}
+
+// All returns every pattern of the package, in a stable order.
+//
+// Returns:
+//   - []Pattern: Denormals, AlternatingSigns, ExponentJumps, ConstantRuns,
+//     SpecialValues and RandomBits
+func All() []Pattern {
+	return []Pattern{
+		Denormals(),
+		AlternatingSigns(),
+		ExponentJumps(),
+		ConstantRuns(),
+		SpecialValues(),
+		RandomBits(),
+	}
+}
+
+// Lookup returns the pattern with the given name.
+//
+// Parameters:
+//   - name: Pattern name, e.g. "exponent-jumps"
+//
+// Returns:
+//   - Pattern: The pattern
+//   - bool: true if a pattern with the name exists
+func Lookup(name string) (Pattern, bool) {
+	for _, p := range All() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Pattern{}, false
+}
+
+// Denormals returns a pattern of random positive subnormal values.
+//
+// Subnormals have a zero exponent and a random mantissa, so consecutive values
+// differ in up to 52 bits.
+func Denormals() Pattern {
+	return Pattern{
+		Name:        "denormals",
+		Description: "random positive subnormal values",
+		Envelope:    Envelope{MinBitsPerValue: 52, MaxBitsPerValue: 57},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = math.Float64frombits(rng.Uint64N(1<<52-1) + 1)
+			}
+
+			return values
+		},
+	}
+}
+
+// AlternatingSigns returns a pattern flipping the sign of a constant magnitude
+// on every value.
+//
+// Only the sign bit changes, so every XOR has a single meaningful bit and Gorilla
+// reuses its window at 3 bits per value, compared with 1 bit for a constant series.
+func AlternatingSigns() Pattern {
+	return Pattern{
+		Name:        "alternating-signs",
+		Description: "constant magnitude with the sign flipping on every value",
+		Envelope:    Envelope{MinBitsPerValue: 3, MaxBitsPerValue: 3.2},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			magnitude := 1 + rng.Float64()*1000
+
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = magnitude
+				if i%2 == 1 {
+					values[i] = -magnitude
+				}
+			}
+
+			return values
+		},
+	}
+}
+
+// ExponentJumps returns a pattern of values whose binary exponent jumps randomly
+// across the whole normal range, with random mantissas.
+//
+// Consecutive values share almost no leading or trailing bits, close to the
+// Gorilla worst case.
+func ExponentJumps() Pattern {
+	return Pattern{
+		Name:        "exponent-jumps",
+		Description: "random mantissas with exponents jumping across the normal range",
+		Envelope:    Envelope{MinBitsPerValue: 63, MaxBitsPerValue: 68},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = math.Ldexp(1+rng.Float64(), rng.IntN(2000)-1000)
+			}
+
+			return values
+		},
+	}
+}
+
+// ConstantRuns returns a pattern of runs of ConstantRunLength equal values, each
+// run starting at a new random value.
+//
+// Unchanged values take a single bit, so the run boundaries dominate the size.
+func ConstantRuns() Pattern {
+	return Pattern{
+		Name:        "constant-runs",
+		Description: "runs of equal values starting at random values",
+		Envelope:    Envelope{MinBitsPerValue: 1.5, MaxBitsPerValue: 2.3},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			values := make([]float64, n)
+
+			var v float64
+			for i := range values {
+				if i%ConstantRunLength == 0 {
+					v = rng.NormFloat64() * 1e6
+				}
+				values[i] = v
+			}
+
+			return values
+		},
+	}
+}
+
+// SpecialValues returns a pattern of random picks among NaN, ±Inf, ±0, the
+// largest and smallest normal values and the smallest subnormal.
+//
+// These values have extreme bit patterns, so consecutive distinct values differ
+// in most bits, while repeated picks cost a single bit.
+func SpecialValues() Pattern {
+	specials := []float64{
+		math.NaN(),
+		math.Inf(1),
+		math.Inf(-1),
+		0,
+		math.Copysign(0, -1),
+		math.MaxFloat64,
+		-math.MaxFloat64,
+		0x1p-1022, // smallest positive normal
+		math.SmallestNonzeroFloat64,
+	}
+
+	return Pattern{
+		Name:        "special-values",
+		Description: "random picks among NaN, infinities, signed zeros and extreme magnitudes",
+		Envelope:    Envelope{MinBitsPerValue: 50, MaxBitsPerValue: 66},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = specials[rng.IntN(len(specials))]
+			}
+
+			return values
+		},
+	}
+}
+
+// RandomBits returns a pattern of uniformly random 64-bit patterns, including
+// NaN payloads.
+//
+// Random bits are incompressible: nearly every value opens a new window, close
+// to the Gorilla worst case of 77 bits per value.
+func RandomBits() Pattern {
+	return Pattern{
+		Name:        "random-bits",
+		Description: "uniformly random bit patterns, including NaN payloads",
+		Envelope:    Envelope{MinBitsPerValue: 64, MaxBitsPerValue: 69},
+		generate: func(rng *rand.Rand, n int) []float64 {
+			values := make([]float64, n)
+			for i := range values {
+				values[i] = math.Float64frombits(rng.Uint64())
+			}
+
+			return values
+		},
+	}
+}
+
+// Bytes flattens values into their little-endian IEEE 754 bit patterns, the
+// []byte form accepted by testing.F.Add for fuzz seed corpora.
+//
+// Parameters:
+//   - values: Values to flatten
+//
+// Returns:
+//   - []byte: 8 bytes per value
+func Bytes(values []float64) []byte {
+	b := make([]byte, 0, len(values)*8)
+	for _, v := range values {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	}
+
+	return b
+}
+
+// FromBytes is the inverse of Bytes; trailing bytes that do not form a whole
+// value are ignored.
+//
+// Parameters:
+//   - b: Little-endian IEEE 754 bit patterns, 8 bytes per value
+//
+// Returns:
+//   - []float64: Decoded values
+func FromBytes(b []byte) []float64 {
+	values := make([]float64, len(b)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+	}
+
+	return values
+}
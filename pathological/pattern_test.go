@@ -0,0 +1,108 @@
+package pathological
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+)
+
+func TestPatterns_WithinEnvelope(t *testing.T) {
+	for _, p := range All() {
+		t.Run(p.Name, func(t *testing.T) {
+			for _, n := range []int{100, 1000, 10000} {
+				for seed := range uint64(5) {
+					values := p.Generate(n, seed)
+					require.Len(t, values, n)
+
+					size := GorillaSize(values)
+					lo, hi := p.Envelope.Bounds(n)
+					require.True(t, p.Envelope.Contains(n, size), "n=%d seed=%d: %d bytes outside [%d, %d]", n, seed, size, lo, hi)
+				}
+			}
+		})
+	}
+}
+
+func TestPattern_Deterministic(t *testing.T) {
+	for _, p := range All() {
+		a := p.Generate(50, 1)
+		require.Equal(t, Bytes(a), Bytes(p.Generate(50, 1)), p.Name)
+		require.Empty(t, p.Generate(-1, 1))
+	}
+
+	require.NotEqual(t, Bytes(RandomBits().Generate(10, 1)), Bytes(RandomBits().Generate(10, 2)))
+}
+
+func TestLookup(t *testing.T) {
+	p, ok := Lookup("exponent-jumps")
+	require.True(t, ok)
+	require.Equal(t, ExponentJumps().Envelope, p.Envelope)
+
+	_, ok = Lookup("missing")
+	require.False(t, ok)
+}
+
+func TestEnvelope_Bounds(t *testing.T) {
+	e := Envelope{MinBitsPerValue: 1, MaxBitsPerValue: 8}
+
+	lo, hi := e.Bounds(9)
+	require.Equal(t, 9, lo)  // (64 + 8) / 8
+	require.Equal(t, 16, hi) // (64 + 64) / 8
+	require.True(t, e.Contains(9, 12))
+	require.False(t, e.Contains(9, 17))
+
+	lo, hi = e.Bounds(0)
+	require.Zero(t, lo)
+	require.Zero(t, hi)
+	require.Equal(t, "[1, 8] bits/value", e.String())
+}
+
+func TestBytes_RoundTrip(t *testing.T) {
+	values := SpecialValues().Generate(100, 3)
+	got := FromBytes(append(Bytes(values), 0xff))
+
+	require.Len(t, got, len(values))
+	for i := range values {
+		require.Equal(t, math.Float64bits(values[i]), math.Float64bits(got[i]))
+	}
+}
+
+// FuzzGorillaRoundTrip checks that every value survives a Gorilla blob round trip
+// bit for bit, seeded with the patterns of the package.
+func FuzzGorillaRoundTrip(f *testing.F) {
+	for _, p := range All() {
+		f.Add(Bytes(p.Generate(64, 0)))
+	}
+
+	start := time.Unix(1700000000, 0)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		encoder, err := blob.NewNumericEncoder(start, blob.WithValueEncoding(format.TypeGorilla))
+		require.NoError(t, err)
+
+		values := FromBytes(data)
+		if len(values) == 0 || len(values) > encoder.MaxDataPoints() {
+			return
+		}
+		require.NoError(t, encoder.StartMetricID(1, len(values)))
+		for i, v := range values {
+			require.NoError(t, encoder.AddDataPoint(start.UnixMicro()+int64(i), v, ""))
+		}
+		require.NoError(t, encoder.EndMetric())
+		encoded, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := blob.NewNumericDecoder(encoded)
+		require.NoError(t, err)
+		decoded, err := decoder.Decode()
+		require.NoError(t, err)
+
+		got := slices.Collect(decoded.AllValues(1))
+		require.Equal(t, Bytes(values), Bytes(got))
+	})
+}
@@ -0,0 +1,45 @@
+// Package promread answers Prometheus remote-read requests from a blob.BlobSet.
+//
+// Mounting the Handler as a Prometheus remote_read endpoint lets mebo-backed
+// archives be queried through Prometheus, and from there in Grafana, without
+// re-ingesting the data:
+//
+//	remote_read:
+//	  - url: http://archive:9201/api/v1/read
+//	    read_recent: true
+//
+// # Mapping
+//
+// Every numeric metric with a known name becomes one or more series. The metric
+// name is the __name__ label, and each distinct tag contributes the labels parsed
+// from it (by default "key=value" pairs separated by commas, see ParseTag), so a
+// metric whose points carry two different tags yields two series. Timestamps are
+// converted from the blob's timestamp unit to milliseconds. Text metrics are not
+// exposed because remote-read samples are floats.
+//
+// Numeric blobs only store metric names when asked to (or on hash collision), so
+// names are gathered from three places: blobs that carry names, names registered
+// with WithMetricNames, and the value of an equality __name__ matcher, which is
+// hashed to the metric ID directly.
+//
+// # Protocol
+//
+// Requests and responses are snappy-compressed protobuf messages of the
+// prometheus.ReadRequest and prometheus.ReadResponse types. The package carries its
+// own minimal codec for these messages, so it adds no protobuf dependency. Only the
+// SAMPLES response type is produced; Prometheus accepts it even when it also
+// offers STREAMED_XOR_CHUNKS.
+//
+// # Usage
+//
+//	set, err := blob.DecodeBlobSet(blobs...)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	h, err := promread.New(set, promread.WithMetricNames("http_requests_total", "cpu_usage"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Handle("/api/v1/read", h)
+package promread
@@ -0,0 +1,359 @@
+package promread
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+const (
+	// nameLabel is the label holding the metric name.
+	nameLabel = "__name__"
+
+	// maxRequestSize caps the compressed size of a remote-read request body.
+	maxRequestSize = 32 << 20
+
+	// maxDecodedSize caps the decompressed size of a remote-read request body,
+	// which snappy reads from the untrusted body header before decoding.
+	maxDecodedSize = 64 << 20
+)
+
+var (
+	errTooManySamples           = errors.New("too many samples")
+	errUnsupportedResponseTypes = errors.New("none of the accepted response types is supported, only SAMPLES is")
+)
+
+// Handler answers Prometheus remote-read requests from a blob.BlobSet. It implements
+// http.Handler and is safe for concurrent use.
+type Handler struct {
+	cfg Config
+	set atomic.Pointer[blob.BlobSet]
+}
+
+// matcher is a LabelMatcher with its regular expression compiled.
+type matcher struct {
+	LabelMatcher
+	re *regexp.Regexp
+}
+
+// series accumulates the samples of one output time series.
+type series struct {
+	key string
+	ts  TimeSeries
+}
+
+// New creates a remote-read handler backed by set.
+//
+// Parameters:
+//   - set: Blob set to serve; replace it later with SetBlobSet
+//   - opts: Optional configuration (WithMetricNames, WithTagParser, WithMaxSamples)
+//
+// Returns:
+//   - *Handler: HTTP handler for the Prometheus remote_read endpoint
+//   - error: Invalid option error
+func New(set blob.BlobSet, opts ...Option) (*Handler, error) {
+	cfg := defaultConfig()
+	if err := options.Apply(&cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	h := &Handler{cfg: cfg}
+	h.set.Store(&set)
+
+	return h, nil
+}
+
+// ParseTag is the default tag parser. It splits a tag such as
+// "job=api,instance=host-1" into labels, skipping empty keys and parts without "=".
+func ParseTag(tag string) []Label {
+	if tag == "" {
+		return nil
+	}
+
+	var labels []Label
+	for part := range strings.SplitSeq(tag, ",") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		labels = append(labels, Label{Name: name, Value: strings.TrimSpace(value)})
+	}
+
+	return labels
+}
+
+// BlobSet returns the blob set currently being served.
+func (h *Handler) BlobSet() blob.BlobSet {
+	return *h.set.Load()
+}
+
+// SetBlobSet atomically replaces the served blob set. Requests already in flight
+// finish against the previous set.
+func (h *Handler) SetBlobSet(set blob.BlobSet) {
+	h.set.Store(&set)
+}
+
+// ServeHTTP implements http.Handler for snappy-compressed protobuf remote-read requests.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	compressed, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	size, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if size > maxDecodedSize {
+		http.Error(w, fmt.Sprintf("decoded request of %d bytes exceeds %d bytes", size, maxDecodedSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	data, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid snappy body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var req ReadRequest
+	if err := req.Unmarshal(data); err != nil {
+		http.Error(w, fmt.Sprintf("invalid read request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.Read(&req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errTooManySamples) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	_, _ = w.Write(snappy.Encode(nil, resp.Marshal()))
+}
+
+// Read answers a decoded remote-read request with one QueryResult per query.
+//
+// Series are sorted by labels and samples by timestamp; both query bounds are
+// inclusive, as in Prometheus.
+//
+// Parameters:
+//   - req: Decoded remote-read request
+//
+// Returns:
+//   - *ReadResponse: Matching series for each query
+//   - error: Invalid matcher, unsupported response types or sample limit exceeded
+func (h *Handler) Read(req *ReadRequest) (*ReadResponse, error) {
+	if len(req.AcceptedResponseTypes) > 0 && !slices.Contains(req.AcceptedResponseTypes, ResponseTypeSamples) {
+		return nil, errUnsupportedResponseTypes
+	}
+
+	set := h.BlobSet()
+	names := h.metricNames(set)
+	budget := h.cfg.MaxSamples
+
+	resp := &ReadResponse{Results: make([]QueryResult, 0, len(req.Queries))}
+	for i := range req.Queries {
+		ts, err := h.query(set, names, &req.Queries[i], &budget)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+		resp.Results = append(resp.Results, QueryResult{Timeseries: ts})
+	}
+
+	return resp, nil
+}
+
+// metricNames returns the sorted, de-duplicated names stored in the numeric blobs
+// or registered with WithMetricNames.
+func (h *Handler) metricNames(set blob.BlobSet) []string {
+	names := slices.Clone(h.cfg.MetricNames)
+	for _, b := range set.NumericBlobs() {
+		names = append(names, b.MetricNames()...)
+	}
+	slices.Sort(names)
+
+	return slices.Compact(names)
+}
+
+// query evaluates one query, charging the returned samples against budget.
+func (h *Handler) query(set blob.BlobSet, names []string, q *Query, budget *int) ([]TimeSeries, error) {
+	matchers, err := compileMatchers(q.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	// An equality matcher on the name selects a single metric, known or not.
+	for _, m := range matchers {
+		if m.Name == nameLabel && m.Type == MatchEqual {
+			names = []string{m.Value}
+			break
+		}
+	}
+
+	byLabels := make(map[string]*series)
+	for _, name := range names {
+		if !matchesName(matchers, name) {
+			continue
+		}
+
+		// Tags map to series; nil marks tags whose labels fail the matchers.
+		byTag := make(map[string]*series)
+		for _, b := range set.NumericBlobs() {
+			unit := b.TimestampUnit()
+			for _, dp := range b.AllByName(name) {
+				ms := unit.Convert(dp.Ts, format.TimeUnitMillisecond)
+				if ms < q.StartTimestampMs || ms > q.EndTimestampMs {
+					continue
+				}
+
+				s, seen := byTag[dp.Tag]
+				if !seen {
+					s = h.seriesFor(name, dp.Tag, matchers, byLabels)
+					byTag[dp.Tag] = s
+				}
+				if s == nil {
+					continue
+				}
+
+				if *budget == 0 {
+					return nil, fmt.Errorf("%w: limit is %d", errTooManySamples, h.cfg.MaxSamples)
+				}
+				*budget--
+				s.ts.Samples = append(s.ts.Samples, Sample{Value: dp.Val, Timestamp: ms})
+			}
+		}
+	}
+
+	out := slices.Collect(maps.Values(byLabels))
+	slices.SortFunc(out, func(a, b *series) int { return cmp.Compare(a.key, b.key) })
+
+	result := make([]TimeSeries, 0, len(out))
+	for _, s := range out {
+		slices.SortStableFunc(s.ts.Samples, func(a, b Sample) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+		result = append(result, s.ts)
+	}
+
+	return result, nil
+}
+
+// seriesFor returns the series for a metric name and tag, or nil if its labels
+// do not satisfy the matchers. Tags that parse to the same labels share a series.
+func (h *Handler) seriesFor(name, tag string, matchers []matcher, byLabels map[string]*series) *series {
+	labels := []Label{{Name: nameLabel, Value: name}}
+	for _, l := range h.cfg.TagParser(tag) {
+		if l.Name != nameLabel {
+			labels = append(labels, l)
+		}
+	}
+	slices.SortStableFunc(labels, func(a, b Label) int { return cmp.Compare(a.Name, b.Name) })
+	// Keep the first value of a repeated label name.
+	labels = slices.CompactFunc(labels, func(a, b Label) bool { return a.Name == b.Name })
+
+	for _, m := range matchers {
+		if !m.matches(labelValue(labels, m.Name)) {
+			return nil
+		}
+	}
+
+	var key strings.Builder
+	for _, l := range labels {
+		key.WriteString(l.Name)
+		key.WriteByte(0xff)
+		key.WriteString(l.Value)
+		key.WriteByte(0xff)
+	}
+
+	if s, ok := byLabels[key.String()]; ok {
+		return s
+	}
+
+	s := &series{key: key.String(), ts: TimeSeries{Labels: labels}}
+	byLabels[s.key] = s
+
+	return s
+}
+
+func compileMatchers(in []LabelMatcher) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(in))
+	for _, m := range in {
+		cm := matcher{LabelMatcher: m}
+		switch m.Type {
+		case MatchEqual, MatchNotEqual:
+		case MatchRegexp, MatchNotRegexp:
+			// Prometheus regular expressions are fully anchored.
+			re, err := regexp.Compile("^(?:" + m.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp for label %q: %w", m.Name, err)
+			}
+			cm.re = re
+		default:
+			return nil, fmt.Errorf("unknown matcher type %d for label %q", m.Type, m.Name)
+		}
+		matchers = append(matchers, cm)
+	}
+
+	return matchers, nil
+}
+
+func matchesName(matchers []matcher, name string) bool {
+	for _, m := range matchers {
+		if m.Name == nameLabel && !m.matches(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// labelValue returns the value of a label, or "" if absent, as Prometheus does.
+func labelValue(labels []Label, name string) string {
+	for _, l := range labels {
+		if l.Name == name {
+			return l.Value
+		}
+	}
+
+	return ""
+}
+
+func (m matcher) matches(v string) bool {
+	switch m.Type {
+	case MatchEqual:
+		return v == m.Value
+	case MatchNotEqual:
+		return v != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
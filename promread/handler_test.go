@@ -0,0 +1,239 @@
+package promread
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+)
+
+var testStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestBlobSet builds two numeric blobs, one with microsecond and one with
+// millisecond timestamps, plus a text blob that must never be exposed.
+func newTestBlobSet(t *testing.T) blob.BlobSet {
+	t.Helper()
+
+	encode := func(start time.Time, unit format.TimeUnit) []byte {
+		enc, err := blob.NewNumericEncoder(start, blob.WithTagsEnabled(true), blob.WithTimestampUnit(unit))
+		require.NoError(t, err)
+
+		require.NoError(t, enc.StartMetricName("http_requests_total", 4))
+		for i, tag := range []string{"job=api,instance=a", "job=api,instance=b", "instance=a, job=api", "job=api,instance=b"} {
+			ts := unit.Timestamp(start.Add(time.Duration(i) * time.Second))
+			require.NoError(t, enc.AddDataPoint(ts, float64(i), tag))
+		}
+		require.NoError(t, enc.EndMetric())
+
+		require.NoError(t, enc.StartMetricName("up", 1))
+		require.NoError(t, enc.AddDataPoint(unit.Timestamp(start), 1, ""))
+		require.NoError(t, enc.EndMetric())
+
+		data, err := enc.Finish()
+		require.NoError(t, err)
+
+		return data
+	}
+
+	textEnc, err := blob.NewTextEncoder(testStart)
+	require.NoError(t, err)
+	require.NoError(t, textEnc.StartMetricName("build_info", 1))
+	require.NoError(t, textEnc.AddDataPoint(testStart.UnixMicro(), "v1", ""))
+	require.NoError(t, textEnc.EndMetric())
+	textData, err := textEnc.Finish()
+	require.NoError(t, err)
+
+	set, err := blob.DecodeBlobSet(
+		encode(testStart, format.TimeUnitMicrosecond),
+		encode(testStart.Add(time.Hour), format.TimeUnitMillisecond),
+		textData,
+	)
+	require.NoError(t, err)
+
+	return set
+}
+
+func post(t *testing.T, h http.Handler, req *ReadRequest) (*ReadResponse, *httptest.ResponseRecorder) {
+	t.Helper()
+
+	body := snappy.Encode(nil, req.Marshal())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/read", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		return nil, rec
+	}
+
+	require.Equal(t, "application/x-protobuf", rec.Header().Get("Content-Type"))
+	require.Equal(t, "snappy", rec.Header().Get("Content-Encoding"))
+
+	data, err := snappy.Decode(nil, rec.Body.Bytes())
+	require.NoError(t, err)
+
+	var resp ReadResponse
+	require.NoError(t, resp.Unmarshal(data))
+
+	return &resp, rec
+}
+
+func allTime(matchers ...LabelMatcher) Query {
+	return Query{StartTimestampMs: math.MinInt64, EndTimestampMs: math.MaxInt64, Matchers: matchers}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	h, err := New(newTestBlobSet(t))
+	require.NoError(t, err)
+
+	startMs := testStart.UnixMilli()
+	resp, rec := post(t, h, &ReadRequest{
+		Queries: []Query{{
+			StartTimestampMs: startMs + 1000,
+			EndTimestampMs:   testStart.Add(time.Hour).UnixMilli() + 1000,
+			Matchers:         []LabelMatcher{{Type: MatchEqual, Name: "__name__", Value: "http_requests_total"}},
+		}},
+		AcceptedResponseTypes: []ResponseType{ResponseTypeStreamedXORChunks, ResponseTypeSamples},
+	})
+	require.NotNil(t, resp, rec.Body.String())
+	require.Len(t, resp.Results, 1)
+
+	hourMs := time.Hour.Milliseconds()
+	require.Equal(t, []TimeSeries{
+		{
+			Labels: []Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "instance", Value: "a"}, {Name: "job", Value: "api"}},
+			Samples: []Sample{
+				{Value: 2, Timestamp: startMs + 2000},
+				{Value: 0, Timestamp: startMs + hourMs},
+			},
+		},
+		{
+			Labels: []Label{{Name: "__name__", Value: "http_requests_total"}, {Name: "instance", Value: "b"}, {Name: "job", Value: "api"}},
+			Samples: []Sample{
+				{Value: 1, Timestamp: startMs + 1000},
+				{Value: 3, Timestamp: startMs + 3000},
+				{Value: 1, Timestamp: startMs + hourMs + 1000},
+			},
+		},
+	}, resp.Results[0].Timeseries)
+}
+
+func TestHandler_Matchers(t *testing.T) {
+	h, err := New(newTestBlobSet(t), WithMetricNames("up", "http_requests_total", "build_info", "missing"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		matchers []LabelMatcher
+		series   int
+	}{
+		{name: "AllNames", matchers: []LabelMatcher{{Type: MatchRegexp, Name: "__name__", Value: ".+"}}, series: 3},
+		{name: "NameRegexpAnchored", matchers: []LabelMatcher{{Type: MatchRegexp, Name: "__name__", Value: "u"}}, series: 0},
+		{name: "NotName", matchers: []LabelMatcher{{Type: MatchNotEqual, Name: "__name__", Value: "up"}}, series: 2},
+		{name: "Label", matchers: []LabelMatcher{{Type: MatchEqual, Name: "instance", Value: "b"}}, series: 1},
+		{name: "MissingLabelIsEmpty", matchers: []LabelMatcher{{Type: MatchEqual, Name: "job", Value: ""}}, series: 1},
+		{name: "NotRegexp", matchers: []LabelMatcher{{Type: MatchNotRegexp, Name: "instance", Value: "a|b"}}, series: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := h.Read(&ReadRequest{Queries: []Query{allTime(tt.matchers...)}})
+			require.NoError(t, err)
+			require.Len(t, resp.Results[0].Timeseries, tt.series)
+		})
+	}
+
+	_, err = h.Read(&ReadRequest{Queries: []Query{allTime(LabelMatcher{Type: MatchRegexp, Name: "job", Value: "("})}})
+	require.Error(t, err)
+	_, err = h.Read(&ReadRequest{Queries: []Query{allTime(LabelMatcher{Type: 9, Name: "job"})}})
+	require.Error(t, err)
+}
+
+func TestHandler_UnknownNamesNeedRegistration(t *testing.T) {
+	h, err := New(newTestBlobSet(t))
+	require.NoError(t, err)
+
+	// Numeric blobs carry no names here, so only equality name matchers find metrics.
+	resp, err := h.Read(&ReadRequest{Queries: []Query{allTime(LabelMatcher{Type: MatchRegexp, Name: "__name__", Value: "up"})}})
+	require.NoError(t, err)
+	require.Empty(t, resp.Results[0].Timeseries)
+
+	h, err = New(newTestBlobSet(t), WithMetricNames("up"))
+	require.NoError(t, err)
+	resp, err = h.Read(&ReadRequest{Queries: []Query{allTime(LabelMatcher{Type: MatchRegexp, Name: "__name__", Value: "up"})}})
+	require.NoError(t, err)
+	require.Len(t, resp.Results[0].Timeseries, 1)
+	require.Len(t, resp.Results[0].Timeseries[0].Samples, 2)
+}
+
+func TestHandler_Errors(t *testing.T) {
+	h, err := New(newTestBlobSet(t), WithMaxSamples(3), WithTagParser(func(string) []Label { return nil }))
+	require.NoError(t, err)
+
+	nameQuery := allTime(LabelMatcher{Type: MatchEqual, Name: "__name__", Value: "http_requests_total"})
+
+	_, rec := post(t, h, &ReadRequest{Queries: []Query{nameQuery}})
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	_, rec = post(t, h, &ReadRequest{
+		Queries:               []Query{nameQuery},
+		AcceptedResponseTypes: []ResponseType{ResponseTypeStreamedXORChunks},
+	})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte("not snappy"))))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A forged header claiming a 4 GiB body is rejected before decoding
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x0f})))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	_, err = New(blob.BlobSet{}, WithMaxSamples(0))
+	require.Error(t, err)
+	_, err = New(blob.BlobSet{}, WithTagParser(nil))
+	require.Error(t, err)
+}
+
+func TestHandler_SetBlobSet(t *testing.T) {
+	h, err := New(blob.BlobSet{})
+	require.NoError(t, err)
+
+	query := &ReadRequest{Queries: []Query{allTime(LabelMatcher{Type: MatchEqual, Name: "__name__", Value: "up"})}}
+	resp, err := h.Read(query)
+	require.NoError(t, err)
+	require.Empty(t, resp.Results[0].Timeseries)
+
+	h.SetBlobSet(newTestBlobSet(t))
+	resp, err = h.Read(query)
+	require.NoError(t, err)
+	require.Len(t, resp.Results[0].Timeseries, 1)
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []Label
+	}{
+		{tag: "", want: nil},
+		{tag: "job=api", want: []Label{{Name: "job", Value: "api"}}},
+		{tag: " job = api ,instance=a", want: []Label{{Name: "job", Value: "api"}, {Name: "instance", Value: "a"}}},
+		{tag: "novalue,=x,k=", want: []Label{{Name: "k", Value: ""}}},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, ParseTag(tt.tag), tt.tag)
+	}
+}
@@ -0,0 +1,69 @@
+package promread
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// DefaultMaxSamples is the default cap on samples returned by one request, matching
+// Prometheus' own remote-read sample limit.
+const DefaultMaxSamples = 50_000_000
+
+// Config holds remote-read handler settings.
+type Config struct {
+	// MetricNames are names to expose in addition to those stored in the blobs.
+	MetricNames []string
+
+	// TagParser converts a point tag into labels. Labels named __name__ are ignored.
+	TagParser func(tag string) []Label
+
+	// MaxSamples caps the samples returned by one request across all its queries.
+	MaxSamples int
+}
+
+// Option is a functional option for Config.
+type Option = options.Option[*Config]
+
+// defaultConfig returns the default handler configuration.
+func defaultConfig() Config {
+	return Config{
+		TagParser:  ParseTag,
+		MaxSamples: DefaultMaxSamples,
+	}
+}
+
+// WithMetricNames registers metric names to expose. Use it for numeric blobs
+// encoded without metric names; the names are matched by their metric ID.
+func WithMetricNames(names ...string) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.MetricNames = append(cfg.MetricNames, names...)
+	})
+}
+
+// WithTagParser sets the function converting point tags into labels.
+// Default is ParseTag.
+func WithTagParser(parser func(tag string) []Label) Option {
+	return options.New(func(cfg *Config) error {
+		if parser == nil {
+			return errors.New("tag parser must not be nil")
+		}
+		cfg.TagParser = parser
+
+		return nil
+	})
+}
+
+// WithMaxSamples sets the maximum number of samples returned by one request.
+// It must be positive. Default is DefaultMaxSamples.
+func WithMaxSamples(n int) Option {
+	return options.New(func(cfg *Config) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max samples: %d, must be positive", n)
+		}
+		cfg.MaxSamples = n
+
+		return nil
+	})
+}
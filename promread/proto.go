@@ -0,0 +1,557 @@
+package promread
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Protobuf wire types used by the remote-read messages.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errMalformedProto = errors.New("malformed protobuf message")
+
+// MatchType is the type of a LabelMatcher.
+type MatchType int32
+
+// Label matcher types, numbered as in prometheus.LabelMatcher.Type.
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+// ResponseType is a remote-read response type a client accepts.
+type ResponseType int32
+
+// Response types, numbered as in prometheus.ReadRequest.ResponseType.
+const (
+	// ResponseTypeSamples is a single ReadResponse holding raw samples.
+	ResponseTypeSamples ResponseType = 0
+	// ResponseTypeStreamedXORChunks is a stream of XOR-encoded chunk frames.
+	ResponseTypeStreamedXORChunks ResponseType = 1
+)
+
+// ReadRequest is a prometheus.ReadRequest.
+type ReadRequest struct {
+	Queries               []Query
+	AcceptedResponseTypes []ResponseType
+}
+
+// Query is a prometheus.Query. Read hints are not decoded.
+type Query struct {
+	StartTimestampMs int64
+	EndTimestampMs   int64
+	Matchers         []LabelMatcher
+}
+
+// LabelMatcher is a prometheus.LabelMatcher.
+type LabelMatcher struct {
+	Type  MatchType
+	Name  string
+	Value string
+}
+
+// ReadResponse is a prometheus.ReadResponse with one result per query.
+type ReadResponse struct {
+	Results []QueryResult
+}
+
+// QueryResult is a prometheus.QueryResult.
+type QueryResult struct {
+	Timeseries []TimeSeries
+}
+
+// TimeSeries is a prometheus.TimeSeries.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Label is a prometheus.Label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a prometheus.Sample with a millisecond timestamp.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// protoReader walks the fields of one protobuf message.
+type protoReader struct {
+	buf []byte
+}
+
+// Marshal encodes the request in protobuf wire format.
+func (r *ReadRequest) Marshal() []byte {
+	var b []byte
+	for i := range r.Queries {
+		b = appendMessage(b, 1, r.Queries[i].marshal())
+	}
+
+	if len(r.AcceptedResponseTypes) > 0 {
+		var packed []byte
+		for _, t := range r.AcceptedResponseTypes {
+			packed = binary.AppendUvarint(packed, uint64(t)) //nolint: gosec
+		}
+		b = appendMessage(b, 2, packed)
+	}
+
+	return b
+}
+
+// Unmarshal decodes a request from protobuf wire format.
+func (r *ReadRequest) Unmarshal(data []byte) error {
+	*r = ReadRequest{}
+
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case field == 1 && wire == wireBytes:
+			msg, err := pr.bytes()
+			if err != nil {
+				return err
+			}
+
+			var q Query
+			if err := q.unmarshal(msg); err != nil {
+				return err
+			}
+			r.Queries = append(r.Queries, q)
+		case field == 2 && wire == wireBytes:
+			packed, err := pr.bytes()
+			if err != nil {
+				return err
+			}
+
+			sub := protoReader{buf: packed}
+			for len(sub.buf) > 0 {
+				v, err := sub.varint()
+				if err != nil {
+					return err
+				}
+				r.AcceptedResponseTypes = append(r.AcceptedResponseTypes, ResponseType(v)) //nolint: gosec
+			}
+		case field == 2 && wire == wireVarint:
+			v, err := pr.varint()
+			if err != nil {
+				return err
+			}
+			r.AcceptedResponseTypes = append(r.AcceptedResponseTypes, ResponseType(v)) //nolint: gosec
+		default:
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Marshal encodes the response in protobuf wire format.
+func (r *ReadResponse) Marshal() []byte {
+	var b []byte
+	for i := range r.Results {
+		var result []byte
+		for j := range r.Results[i].Timeseries {
+			result = appendMessage(result, 1, r.Results[i].Timeseries[j].marshal())
+		}
+		b = appendMessage(b, 1, result)
+	}
+
+	return b
+}
+
+// Unmarshal decodes a response from protobuf wire format.
+func (r *ReadResponse) Unmarshal(data []byte) error {
+	*r = ReadResponse{}
+
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		if field != 1 || wire != wireBytes {
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		msg, err := pr.bytes()
+		if err != nil {
+			return err
+		}
+
+		var result QueryResult
+		sub := protoReader{buf: msg}
+		for len(sub.buf) > 0 {
+			field, wire, err := sub.key()
+			if err != nil {
+				return err
+			}
+
+			if field != 1 || wire != wireBytes {
+				if err := sub.skip(wire); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			series, err := sub.bytes()
+			if err != nil {
+				return err
+			}
+
+			var ts TimeSeries
+			if err := ts.unmarshal(series); err != nil {
+				return err
+			}
+			result.Timeseries = append(result.Timeseries, ts)
+		}
+		r.Results = append(r.Results, result)
+	}
+
+	return nil
+}
+
+func appendKey(b []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wire)) //nolint: gosec
+}
+
+func appendMessage(b []byte, field int, msg []byte) []byte {
+	b = appendKey(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(msg)))
+
+	return append(b, msg...)
+}
+
+func appendString(b []byte, field int, s string) []byte {
+	if s == "" {
+		return b
+	}
+
+	b = appendKey(b, field, wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(s)))
+
+	return append(b, s...)
+}
+
+func appendInt64(b []byte, field int, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+
+	return binary.AppendUvarint(appendKey(b, field, wireVarint), uint64(v)) //nolint: gosec
+}
+
+func appendDouble(b []byte, field int, v float64) []byte {
+	bits := math.Float64bits(v)
+	if bits == 0 {
+		return b
+	}
+
+	return binary.LittleEndian.AppendUint64(appendKey(b, field, wireFixed64), bits)
+}
+
+func (q *Query) marshal() []byte {
+	var b []byte
+	b = appendInt64(b, 1, q.StartTimestampMs)
+	b = appendInt64(b, 2, q.EndTimestampMs)
+	for _, m := range q.Matchers {
+		var mb []byte
+		mb = appendInt64(mb, 1, int64(m.Type))
+		mb = appendString(mb, 2, m.Name)
+		mb = appendString(mb, 3, m.Value)
+		b = appendMessage(b, 3, mb)
+	}
+
+	return b
+}
+
+func (q *Query) unmarshal(data []byte) error {
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case field == 1 && wire == wireVarint:
+			v, err := pr.varint()
+			if err != nil {
+				return err
+			}
+			q.StartTimestampMs = int64(v) //nolint: gosec
+		case field == 2 && wire == wireVarint:
+			v, err := pr.varint()
+			if err != nil {
+				return err
+			}
+			q.EndTimestampMs = int64(v) //nolint: gosec
+		case field == 3 && wire == wireBytes:
+			msg, err := pr.bytes()
+			if err != nil {
+				return err
+			}
+
+			var m LabelMatcher
+			if err := m.unmarshal(msg); err != nil {
+				return err
+			}
+			q.Matchers = append(q.Matchers, m)
+		default:
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *LabelMatcher) unmarshal(data []byte) error {
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case field == 1 && wire == wireVarint:
+			v, err := pr.varint()
+			if err != nil {
+				return err
+			}
+			m.Type = MatchType(v) //nolint: gosec
+		case field == 2 && wire == wireBytes:
+			if m.Name, err = pr.string(); err != nil {
+				return err
+			}
+		case field == 3 && wire == wireBytes:
+			if m.Value, err = pr.string(); err != nil {
+				return err
+			}
+		default:
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ts *TimeSeries) marshal() []byte {
+	var b []byte
+	for _, l := range ts.Labels {
+		var lb []byte
+		lb = appendString(lb, 1, l.Name)
+		lb = appendString(lb, 2, l.Value)
+		b = appendMessage(b, 1, lb)
+	}
+
+	var sb []byte
+	for _, s := range ts.Samples {
+		sb = appendDouble(sb[:0], 1, s.Value)
+		sb = appendInt64(sb, 2, s.Timestamp)
+		b = appendMessage(b, 2, sb)
+	}
+
+	return b
+}
+
+func (ts *TimeSeries) unmarshal(data []byte) error {
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		if wire != wireBytes || (field != 1 && field != 2) {
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		msg, err := pr.bytes()
+		if err != nil {
+			return err
+		}
+
+		if field == 1 {
+			var l Label
+			if err := l.unmarshal(msg); err != nil {
+				return err
+			}
+			ts.Labels = append(ts.Labels, l)
+
+			continue
+		}
+
+		var s Sample
+		if err := s.unmarshal(msg); err != nil {
+			return err
+		}
+		ts.Samples = append(ts.Samples, s)
+	}
+
+	return nil
+}
+
+func (l *Label) unmarshal(data []byte) error {
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case field == 1 && wire == wireBytes:
+			if l.Name, err = pr.string(); err != nil {
+				return err
+			}
+		case field == 2 && wire == wireBytes:
+			if l.Value, err = pr.string(); err != nil {
+				return err
+			}
+		default:
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Sample) unmarshal(data []byte) error {
+	pr := protoReader{buf: data}
+	for len(pr.buf) > 0 {
+		field, wire, err := pr.key()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case field == 1 && wire == wireFixed64:
+			v, err := pr.fixed64()
+			if err != nil {
+				return err
+			}
+			s.Value = math.Float64frombits(v)
+		case field == 2 && wire == wireVarint:
+			v, err := pr.varint()
+			if err != nil {
+				return err
+			}
+			s.Timestamp = int64(v) //nolint: gosec
+		default:
+			if err := pr.skip(wire); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (pr *protoReader) key() (field int, wire int, err error) {
+	v, err := pr.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	field, wire = int(v>>3), int(v&7) //nolint: gosec
+	if field == 0 {
+		return 0, 0, fmt.Errorf("%w: protobuf field number 0", errMalformedProto)
+	}
+
+	return field, wire, nil
+}
+
+func (pr *protoReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(pr.buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("%w: malformed protobuf varint", errMalformedProto)
+	}
+	pr.buf = pr.buf[n:]
+
+	return v, nil
+}
+
+func (pr *protoReader) fixed64() (uint64, error) {
+	if len(pr.buf) < 8 {
+		return 0, fmt.Errorf("%w: truncated protobuf fixed64", errMalformedProto)
+	}
+	v := binary.LittleEndian.Uint64(pr.buf)
+	pr.buf = pr.buf[8:]
+
+	return v, nil
+}
+
+func (pr *protoReader) bytes() ([]byte, error) {
+	n, err := pr.varint()
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(len(pr.buf)) {
+		return nil, fmt.Errorf("%w: truncated protobuf field", errMalformedProto)
+	}
+	b := pr.buf[:n]
+	pr.buf = pr.buf[n:]
+
+	return b, nil
+}
+
+func (pr *protoReader) string() (string, error) {
+	b, err := pr.bytes()
+
+	return string(b), err
+}
+
+func (pr *protoReader) skip(wire int) error {
+	var err error
+	switch wire {
+	case wireVarint:
+		_, err = pr.varint()
+	case wireFixed64:
+		_, err = pr.fixed64()
+	case wireBytes:
+		_, err = pr.bytes()
+	case wireFixed32:
+		if len(pr.buf) < 4 {
+			return fmt.Errorf("%w: truncated protobuf fixed32", errMalformedProto)
+		}
+		pr.buf = pr.buf[4:]
+	default:
+		return fmt.Errorf("%w: unsupported protobuf wire type %d", errMalformedProto, wire)
+	}
+
+	return err
+}
@@ -0,0 +1,81 @@
+package promread
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRequest_RoundTrip(t *testing.T) {
+	req := ReadRequest{
+		Queries: []Query{
+			{
+				StartTimestampMs: -5,
+				EndTimestampMs:   1_700_000_000_000,
+				Matchers: []LabelMatcher{
+					{Type: MatchEqual, Name: "__name__", Value: "up"},
+					{Type: MatchNotRegexp, Name: "job", Value: "a.*"},
+				},
+			},
+			{},
+		},
+		AcceptedResponseTypes: []ResponseType{ResponseTypeStreamedXORChunks, ResponseTypeSamples},
+	}
+
+	var got ReadRequest
+	require.NoError(t, got.Unmarshal(req.Marshal()))
+	require.Equal(t, req, got)
+}
+
+func TestReadRequest_UnpackedResponseTypesAndUnknownFields(t *testing.T) {
+	var data []byte
+	data = appendInt64(data, 2, int64(ResponseTypeStreamedXORChunks))
+	data = appendMessage(data, 1, appendMessage(appendInt64(nil, 2, 10), 4, []byte{0x08, 0x01})) // hints are skipped
+	data = appendDouble(data, 9, 1.5)
+
+	var got ReadRequest
+	require.NoError(t, got.Unmarshal(data))
+	require.Equal(t, []ResponseType{ResponseTypeStreamedXORChunks}, got.AcceptedResponseTypes)
+	require.Equal(t, []Query{{EndTimestampMs: 10}}, got.Queries)
+}
+
+func TestReadRequest_Malformed(t *testing.T) {
+	valid := (&ReadRequest{Queries: []Query{{Matchers: []LabelMatcher{{Name: "job", Value: "api"}}}}}).Marshal()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Truncated", data: valid[:len(valid)-1]},
+		{name: "BadVarint", data: []byte{0x80}},
+		{name: "FieldZero", data: []byte{0x00, 0x00}},
+		{name: "BadWireType", data: []byte{0x0b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ReadRequest
+			require.ErrorIs(t, got.Unmarshal(tt.data), errMalformedProto)
+		})
+	}
+}
+
+func TestReadResponse_RoundTrip(t *testing.T) {
+	resp := ReadResponse{
+		Results: []QueryResult{
+			{
+				Timeseries: []TimeSeries{
+					{
+						Labels:  []Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "api"}},
+						Samples: []Sample{{Value: 0, Timestamp: 0}, {Value: math.Inf(-1), Timestamp: -1}, {Value: 2.5, Timestamp: 1000}},
+					},
+				},
+			},
+			{},
+		},
+	}
+
+	var got ReadResponse
+	require.NoError(t, got.Unmarshal(resp.Marshal()))
+	require.Equal(t, resp, got)
+}
@@ -11,15 +11,27 @@ const (
 	TagMask              = 0x0001 // Mask for tag bit (bit 0)
 	EndiannessMask       = 0x0002 // Mask for endianness bit (bit 1)
 	MetricNamesMask      = 0x0004 // Mask for metric names payload bit (bit 2)
-	ReservedBitsMask     = 0x0008 // Mask for reserved bit (bit 3) — used by text flags
+	ReservedBitsMask     = 0x0008 // Mask for reserved bit (bit 3) — used by event flags
+	ValueCompressionMask = 0x0008 // Mask for per-value compression bit (bit 3) — used by text flags
 	SharedTimestampsMask = 0x0008 // Mask for shared timestamps bit (bit 3) — used by numeric flags
 	MagicNumberMask      = 0xFFF0 // Mask for magic number (bits 4-15)
+	MetadataMask         = 0x80   // Mask for metadata section bit (bit 7 of CompressionType) — used by numeric flags
+	SortedIndexMask      = 0x08   // Mask for sorted index bit (bit 3 of CompressionType) — used by numeric flags
+	CompressedNamesMask  = 0x08   // Mask for compressed metric names bit (bit 3 of EncodingType) — used by numeric flags
+	TextTsEncodingMask   = 0x0F   // Mask for timestamp encoding (bits 0-3 of TimestampEncoding) — used by text flags
+	SeekIndexMask        = 0x10   // Mask for seek index bit (bit 4 of TimestampEncoding) — used by text flags
+	ValueRLEMask         = 0x20   // Mask for repeated value bit (bit 5 of TimestampEncoding) — used by text flags
+	TextDataCompMask     = 0x0F   // Mask for data compression (bits 0-3 of DataCompression) — used by text flags
+	TagCompressionMask   = 0xF0   // Mask for tag section compression (bits 4-7 of DataCompression) — used by text flags
 
 	// Magic numbers (bits 4-15)
-	MagicNumericV1Opt    = 0xEA10 // MagicNumericV1Opt is a version 1 magic number for float blob format.
-	MagicNumericV2Opt    = 0xEA20 // MagicNumericV2Opt is a version 2 magic number for float blob format with shared timestamps.
-	MagicNumericV2ExtOpt = 0xEA30 // MagicNumericV2ExtOpt is a version 2 magic number with extended (32-byte) index entries.
-	MagicTextV1Opt       = 0xEB10 // MagicTextV1 is a version 1 magic number for text blob format.
+	MagicNumericV1Opt      = 0xEA10 // MagicNumericV1Opt is a version 1 magic number for float blob format.
+	MagicNumericV2Opt      = 0xEA20 // MagicNumericV2Opt is a version 2 magic number for float blob format with shared timestamps.
+	MagicNumericV2ExtOpt   = 0xEA30 // MagicNumericV2ExtOpt is a version 2 magic number with extended (32-byte) index entries.
+	MagicNumericV2NoTagOpt = 0xEA40 // MagicNumericV2NoTagOpt is a version 2 magic number with tagless (16-byte, uint24 offset) index entries.
+	MagicNumericSmallOpt   = 0xEA50 // MagicNumericSmallOpt is the magic number of single-metric numeric blobs with an 8-byte header.
+	MagicTextV1Opt         = 0xEB10 // MagicTextV1 is a version 1 magic number for text blob format.
+	MagicEventV1Opt        = 0xEC10 // MagicEventV1Opt is a version 1 magic number for event blob format (dictionary-coded text).
 
 	// Timestamp encodings (bits 0-3) - using types package constants
 	TimestampEncodingNRaw = uint8(format.TypeRaw)   // TimestampTypeRaw represents raw timestamps with no format.
@@ -49,21 +61,36 @@ const (
 	FlagValEncRaw          = 0x0004 // 0=raw, 1=gorilla
 	FlagTagEnabled         = 0x0008 // 0=disabled, 1=enabled
 	FlagMetricNames        = 0x0010 // 0=disabled, 1=enabled
+	FlagValueCompression   = 0x0020 // 0=disabled, 1=enabled (text blobs)
+	FlagSeekIndex          = 0x0040 // 0=disabled, 1=enabled (text blobs)
+	FlagValueRLE           = 0x0080 // 0=disabled, 1=enabled (text blobs)
 )
 
 // offset and section sizes in the blob file
 const (
 	HeaderSize               = 32                           // fixed header size in bytes (shared by all blob types)
+	WideHeaderSize           = 64                           // size in bytes of the wide (v2) numeric header with 64-bit payload offsets
+	SmallHeaderSize          = 8                            // size in bytes of the header of small numeric blobs
 	NumericIndexEntrySize    = 16                           // fixed index entry size for numeric value blob in bytes (compact, 0xEA20)
 	NumericExtIndexEntrySize = 32                           // fixed index entry size for numeric value blob in bytes (extended, 0xEA30)
 	TextIndexEntrySize       = 16                           // fixed index entry size for text value blob in bytes
 	IndexOffsetOffset        = HeaderSize                   // byte offset where index section starts
 	NumericMaxOffset         = math.MaxUint16               // maximum offset delta of compact (uint16) numeric index entries
 	NumericExtMaxOffset      = math.MaxUint32 & math.MaxInt // maximum offset delta of extended (uint32) numeric index entries, capped for 32-bit safety
+	NumericNoTagMaxOffset    = 1<<24 - 1                    // maximum offset delta of tagless (uint24) numeric index entries
 	NumericMaxCount          = math.MaxUint32 & math.MaxInt // maximum data point count per metric (uint32 Count field), capped for 32-bit safety
+	NumericOffsetWordSize    = 8                            // offset delta unit in bytes of word-aligned numeric blobs
 	TextMaxOffset            = math.MaxUint32               // maximum offset value of text value blob index
 
+	// WideHeaderMarker is set in the metric count word (bytes 12-15) of a wide
+	// numeric header. Metric counts never exceed 65535, so v1 headers never set it,
+	// and decoders predating wide headers reject the resulting count.
+	WideHeaderMarker = uint32(1) << 31
+
 	// maxSafeUint32 is the largest uint32 value safely convertible to int on the current platform.
 	// On 64-bit: math.MaxUint32 (0xFFFFFFFF); on 32-bit: math.MaxInt32 (0x7FFFFFFF).
 	maxSafeUint32 = uint32(math.MaxUint32 & math.MaxInt)
+
+	// maxSafeUint64 is the largest uint64 value safely convertible to int on the current platform.
+	maxSafeUint64 = uint64(math.MaxInt)
 )
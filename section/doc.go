@@ -87,7 +87,8 @@
 //
 //	Byte 3 (CompressionType, 8 bits):
 //	  Bits 0-3: Timestamp compression (0x1=None, 0x2=Zstd, 0x3=S2, 0x4=LZ4)
-//	  Bits 4-7: Value compression (0x1=None, 0x2=Zstd, 0x3=S2, 0x4=LZ4)
+//	  Bits 4-6: Value compression (0x1=None, 0x2=Zstd, 0x3=S2, 0x4=LZ4)
+//	  Bit 7: Metadata section present (numeric only, 0=absent, 1=present)
 //
 // Example flag decoding:
 //
@@ -127,7 +128,7 @@
 //	-------|------------|--------|----------------------------------
 //	0-7    | MetricID   | uint64 | xxHash64 of metric name
 //	8-9    | Count      | uint16 | Number of data points
-//	10-11  | Reserved1  | uint16 | Reserved for future use
+//	10-11  | Reserved1  | uint16 | Seek interval with a seek index, else 0
 //	12-15  | Offset     | uint32 | Absolute byte offset in data section
 //
 // # Delta Offset Encoding
@@ -165,6 +166,7 @@
 //
 //	MagicNumericV1Opt = 0xEA10  // Numeric blob format v1
 //	MagicTextV1Opt  = 0xEB10  // Text blob format v1
+//	MagicEventV1Opt = 0xEC10  // Event blob format v1 (text layout with a text dictionary)
 //
 // Encoding type constants:
 //
@@ -18,10 +18,12 @@ type NumericFlag struct {
 	Options uint16
 
 	// EncodingType is an enum indicating the encoding used for this metric blob.
-	// bit 0-3 for timestamp encoding, bit 4-7 for value format.
+	// bit 0-2 for timestamp encoding, bit 3 is the compressed metric names flag
+	// (see HasCompressedMetricNames), bit 4-7 for value format.
 	EncodingType uint8
 	// CompressionType is an enum indicating the compression used for this metric blob.
-	// bit 0-3 for timestamp compression, bit 4-7 for value compression.
+	// bit 0-2 for timestamp compression, bit 3 is the sorted index flag (see IsIndexSorted),
+	// bit 4-6 for value compression, bit 7 is the metadata section flag (see HasMetadata).
 	CompressionType uint8
 }
 
@@ -33,10 +35,12 @@ var (
 	}
 
 	validValueEncodings = map[uint8]struct{}{
-		uint8(format.TypeRaw):     {},
-		uint8(format.TypeGorilla): {},
-		uint8(format.TypeChimp):   {},
-		uint8(format.TypeALP):     {},
+		uint8(format.TypeRaw):       {},
+		uint8(format.TypeDelta):     {},
+		uint8(format.TypeGorilla):   {},
+		uint8(format.TypeChimp):     {},
+		uint8(format.TypeALP):       {},
+		uint8(format.TypeExtension): {},
 	}
 
 	validTimestampCompressions = map[uint8]struct{}{
@@ -137,6 +141,27 @@ func (f *NumericFlag) SetHasSharedTimestamps(enabled bool) {
 	}
 }
 
+// HasMetadata returns whether the optional metadata section is present.
+//
+// When true, a metadata section (see Metadata) follows the metric names payload
+// and precedes the index entries. Decoders that predate the section reject such
+// blobs as having an invalid value compression.
+//
+// Returns:
+//   - bool: true if the metadata section is present, false otherwise
+func (f NumericFlag) HasMetadata() bool {
+	return (f.CompressionType & MetadataMask) != 0
+}
+
+// SetHasMetadata enables or disables the metadata section flag.
+func (f *NumericFlag) SetHasMetadata(enabled bool) {
+	if enabled {
+		f.CompressionType |= MetadataMask
+	} else {
+		f.CompressionType &^= MetadataMask
+	}
+}
+
 // IsLittleEndian returns whether the data is little-endian.
 func (f NumericFlag) IsLittleEndian() bool {
 	return (f.Options & EndiannessMask) == 0
@@ -162,18 +187,20 @@ func (f NumericFlag) GetMagicNumber() uint16 {
 	return f.Options & MagicNumberMask
 }
 
-// TimestampEncoding returns the timestamp encoding type from bits 0-3 of EncodingType.
+// TimestampEncoding returns the timestamp encoding type from bits 0-2 of EncodingType.
 func (f NumericFlag) TimestampEncoding() format.EncodingType {
-	return format.EncodingType(f.EncodingType & 0x0F)
+	return format.EncodingType(f.EncodingType & 0x07)
 }
 
-// SetTimestampEncoding sets the timestamp encoding type in bits 0-3 of EncodingType.
+// SetTimestampEncoding sets the timestamp encoding type in bits 0-2 of EncodingType.
+// Bit 3 (compressed metric names flag) is preserved.
 func (f *NumericFlag) SetTimestampEncoding(enc format.EncodingType) {
-	f.EncodingType &^= 0x0F // Clear bits 0-3
-	f.EncodingType |= (uint8(enc) & 0x0F)
+	f.EncodingType &^= 0x07 // Clear bits 0-2
+	f.EncodingType |= (uint8(enc) & 0x07)
 }
 
 // ValueEncoding returns the value encoding type from bits 4-7 of EncodingType.
+// format.TypeDelta marks int64 values stored with delta-of-delta encoding.
 func (f NumericFlag) ValueEncoding() format.EncodingType {
 	return format.EncodingType((f.EncodingType >> 4) & 0x0F)
 }
@@ -184,41 +211,85 @@ func (f *NumericFlag) SetValueEncoding(enc format.EncodingType) {
 	f.EncodingType |= (uint8(enc) & 0x0F) << 4
 }
 
-// TimestampCompression returns the timestamp compression type from bits 0-3 of CompressionType.
+// TimestampCompression returns the timestamp compression type from bits 0-2 of CompressionType.
 func (f NumericFlag) TimestampCompression() format.CompressionType {
-	return format.CompressionType(f.CompressionType & 0x0F)
+	return format.CompressionType(f.CompressionType & 0x07)
 }
 
-// SetTimestampCompression sets the timestamp compression type in bits 0-3 of CompressionType.
+// SetTimestampCompression sets the timestamp compression type in bits 0-2 of CompressionType.
+// Bit 3 (sorted index flag) is preserved.
 func (f *NumericFlag) SetTimestampCompression(compression format.CompressionType) {
-	f.CompressionType &^= 0x0F // Clear bits 0-3
-	f.CompressionType |= (uint8(compression) & 0x0F)
+	f.CompressionType &^= 0x07 // Clear bits 0-2
+	f.CompressionType |= (uint8(compression) & 0x07)
 }
 
-// ValueCompression returns the value compression type from bits 4-7 of CompressionType.
+// ValueCompression returns the value compression type from bits 4-6 of CompressionType.
 func (f NumericFlag) ValueCompression() format.CompressionType {
-	return format.CompressionType((f.CompressionType >> 4) & 0x0F)
+	return format.CompressionType((f.CompressionType >> 4) & 0x07)
 }
 
-// SetValueCompression sets the value compression type in bits 4-7 of CompressionType.
+// SetValueCompression sets the value compression type in bits 4-6 of CompressionType.
+// Bit 7 (metadata section flag) is preserved.
 func (f *NumericFlag) SetValueCompression(compression format.CompressionType) {
-	f.CompressionType &^= 0xF0 // Clear bits 4-7
-	f.CompressionType |= (uint8(compression) & 0x0F) << 4
+	f.CompressionType &^= 0x70 // Clear bits 4-6
+	f.CompressionType |= (uint8(compression) & 0x07) << 4
+}
+
+// IsIndexSorted returns whether index entries are sorted by MetricID.
+//
+// V2 blobs always sort their index. V1 blobs set the sorted index flag when encoded
+// with WithSortedIndex, letting decoders binary search the raw index instead of
+// building a hash map. Decoders that predate the flag reject such blobs as having
+// an invalid timestamp compression.
+//
+// Returns:
+//   - bool: true if index entries are sorted by MetricID, false otherwise
+func (f NumericFlag) IsIndexSorted() bool {
+	return f.IsV2() || (f.CompressionType&SortedIndexMask) != 0
+}
+
+// SetIndexSorted enables or disables the sorted index flag.
+func (f *NumericFlag) SetIndexSorted(enabled bool) {
+	if enabled {
+		f.CompressionType |= SortedIndexMask
+	} else {
+		f.CompressionType &^= SortedIndexMask
+	}
+}
+
+// HasCompressedMetricNames returns whether the metric names payload is Zstd compressed.
+//
+// Only meaningful when HasMetricNames is true. Decoders that predate the flag reject
+// such blobs as having an invalid timestamp encoding.
+//
+// Returns:
+//   - bool: true if the metric names payload is compressed, false otherwise
+func (f NumericFlag) HasCompressedMetricNames() bool {
+	return (f.EncodingType & CompressedNamesMask) != 0
+}
+
+// SetCompressedMetricNames enables or disables the compressed metric names flag.
+func (f *NumericFlag) SetCompressedMetricNames(enabled bool) {
+	if enabled {
+		f.EncodingType |= CompressedNamesMask
+	} else {
+		f.EncodingType &^= CompressedNamesMask
+	}
 }
 
 // IsValidMagicNumber checks if the magic number is valid.
-// Accepts V1, V2 compact, and V2 extended numeric magic numbers.
+// Accepts V1, V2 compact, V2 extended and V2 tagless numeric magic numbers.
 func (f NumericFlag) IsValidMagicNumber() bool {
 	magic := f.GetMagicNumber()
 
-	return magic == MagicNumericV1Opt || magic == MagicNumericV2Opt || magic == MagicNumericV2ExtOpt
+	return magic == MagicNumericV1Opt || magic == MagicNumericV2Opt || magic == MagicNumericV2ExtOpt || magic == MagicNumericV2NoTagOpt
 }
 
-// IsV2 returns whether this flag indicates a V2 format blob (compact or extended).
+// IsV2 returns whether this flag indicates a V2 format blob (compact, extended or tagless).
 func (f NumericFlag) IsV2() bool {
 	magic := f.GetMagicNumber()
 
-	return magic == MagicNumericV2Opt || magic == MagicNumericV2ExtOpt
+	return magic == MagicNumericV2Opt || magic == MagicNumericV2ExtOpt || magic == MagicNumericV2NoTagOpt
 }
 
 // IsV2Ext returns whether this flag indicates a V2 extended format blob with 32-byte index entries.
@@ -226,8 +297,15 @@ func (f NumericFlag) IsV2Ext() bool {
 	return f.GetMagicNumber() == MagicNumericV2ExtOpt
 }
 
+// IsV2NoTag returns whether this flag indicates a V2 tagless format blob, whose 16-byte
+// index entries drop the tag offset in favor of uint24 timestamp and value offsets.
+func (f NumericFlag) IsV2NoTag() bool {
+	return f.GetMagicNumber() == MagicNumericV2NoTagOpt
+}
+
 // IndexEntrySize returns the index entry size in bytes based on the magic number.
-// Returns NumericExtIndexEntrySize (32) for extended V2, NumericIndexEntrySize (16) otherwise.
+// Returns NumericExtIndexEntrySize (32) for extended V2, NumericIndexEntrySize (16) otherwise,
+// including the tagless V2 format.
 func (f NumericFlag) IndexEntrySize() int {
 	if f.IsV2Ext() {
 		return NumericExtIndexEntrySize
@@ -238,7 +316,7 @@ func (f NumericFlag) IndexEntrySize() int {
 
 // IsValidEncoding checks if the encoding types are valid.
 func (f NumericFlag) IsValidEncoding() bool {
-	timestampEncoding := f.EncodingType & 0x0F
+	timestampEncoding := f.EncodingType & 0x07
 	valueEncoding := (f.EncodingType >> 4) & 0x0F
 
 	_, validTimestamp := validTimestampEncodings[timestampEncoding]
@@ -249,8 +327,8 @@ func (f NumericFlag) IsValidEncoding() bool {
 
 // IsValidCompression checks if the compression types are valid.
 func (f NumericFlag) IsValidCompression() bool {
-	timestampCompression := f.CompressionType & 0x0F
-	valueCompression := (f.CompressionType >> 4) & 0x0F
+	timestampCompression := f.CompressionType & 0x07
+	valueCompression := (f.CompressionType >> 4) & 0x07
 
 	_, validTimestamp := validTimestampCompressions[timestampCompression]
 	_, validValue := validValueCompressions[valueCompression]
@@ -272,6 +350,21 @@ func (f NumericFlag) Validate() error {
 		return errs.ErrInvalidHeaderFlags
 	}
 
+	// Tagless index entries have no room for tag offsets
+	if f.IsV2NoTag() && f.HasTag() {
+		return errs.ErrInvalidHeaderFlags
+	}
+
+	// Only a metric names payload can be compressed
+	if f.HasCompressedMetricNames() && !f.HasMetricNames() {
+		return errs.ErrInvalidHeaderFlags
+	}
+
+	// The extension encoding ID is stored in the metadata section
+	if f.ValueEncoding() == format.TypeExtension && !f.HasMetadata() {
+		return errs.ErrInvalidHeaderFlags
+	}
+
 	return nil
 }
 
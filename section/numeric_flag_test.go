@@ -3,6 +3,7 @@ package section
 import (
 	"testing"
 
+	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
 	"github.com/stretchr/testify/require"
 )
@@ -22,6 +23,7 @@ func TestNumericFlag_IsValidMagicNumber(t *testing.T) {
 		{name: "V1 magic", magic: MagicNumericV1Opt, want: true},
 		{name: "V2 compact magic", magic: MagicNumericV2Opt, want: true},
 		{name: "V2 extended magic", magic: MagicNumericV2ExtOpt, want: true},
+		{name: "V2 tagless magic", magic: MagicNumericV2NoTagOpt, want: true},
 		{name: "text magic", magic: MagicTextV1Opt, want: false},
 		{name: "zero", magic: 0x0000, want: false},
 		{name: "random", magic: 0xFFFF, want: false},
@@ -44,6 +46,7 @@ func TestNumericFlag_IsV2(t *testing.T) {
 		{name: "V1 magic", magic: MagicNumericV1Opt, want: false},
 		{name: "V2 compact magic", magic: MagicNumericV2Opt, want: true},
 		{name: "V2 extended magic", magic: MagicNumericV2ExtOpt, want: true},
+		{name: "V2 tagless magic", magic: MagicNumericV2NoTagOpt, want: true},
 	}
 
 	for _, tt := range tests {
@@ -73,6 +76,67 @@ func TestNumericFlag_IsV2Ext(t *testing.T) {
 	}
 }
 
+func TestNumericFlag_IsV2NoTag(t *testing.T) {
+	f := NewNumericFlag()
+	f.Options = (f.Options &^ MagicNumberMask) | MagicNumericV2NoTagOpt
+	require.True(t, f.IsV2NoTag())
+	require.False(t, f.IsV2Ext())
+	require.NoError(t, f.Validate())
+
+	// Tagless index entries cannot address a tag payload
+	f.Options |= TagMask
+	require.ErrorIs(t, f.Validate(), errs.ErrInvalidHeaderFlags)
+
+	f = NumericFlag{Options: MagicNumericV2ExtOpt}
+	require.False(t, f.IsV2NoTag())
+}
+
+func TestNumericFlag_IndexSorted(t *testing.T) {
+	f := NewNumericFlag()
+	f.SetTimestampCompression(format.CompressionLZ4)
+	require.False(t, f.IsIndexSorted())
+
+	f.SetIndexSorted(true)
+	require.True(t, f.IsIndexSorted())
+	require.Equal(t, format.CompressionLZ4, f.TimestampCompression())
+	require.NoError(t, f.Validate())
+
+	// Changing the timestamp compression keeps the flag
+	f.SetTimestampCompression(format.CompressionS2)
+	require.True(t, f.IsIndexSorted())
+
+	f.SetIndexSorted(false)
+	require.False(t, f.IsIndexSorted())
+	require.Equal(t, format.CompressionS2, f.TimestampCompression())
+
+	// V2 indexes are always sorted
+	f.Options = (f.Options &^ MagicNumberMask) | MagicNumericV2Opt
+	require.True(t, f.IsIndexSorted())
+}
+
+func TestNumericFlag_CompressedMetricNames(t *testing.T) {
+	f := NewNumericFlag()
+	f.SetTimestampEncoding(format.TypeDeltaPacked)
+	require.False(t, f.HasCompressedMetricNames())
+
+	// The flag requires a metric names payload
+	f.SetCompressedMetricNames(true)
+	require.ErrorIs(t, f.Validate(), errs.ErrInvalidHeaderFlags)
+
+	f.SetHasMetricNames(true)
+	require.True(t, f.HasCompressedMetricNames())
+	require.Equal(t, format.TypeDeltaPacked, f.TimestampEncoding())
+	require.NoError(t, f.Validate())
+
+	// Changing the timestamp encoding keeps the flag
+	f.SetTimestampEncoding(format.TypeDelta)
+	require.True(t, f.HasCompressedMetricNames())
+
+	f.SetCompressedMetricNames(false)
+	require.False(t, f.HasCompressedMetricNames())
+	require.Equal(t, format.TypeDelta, f.TimestampEncoding())
+}
+
 func TestNumericFlag_IndexEntrySize(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -82,6 +146,7 @@ func TestNumericFlag_IndexEntrySize(t *testing.T) {
 		{name: "V1 magic", magic: MagicNumericV1Opt, want: NumericIndexEntrySize},
 		{name: "V2 compact magic", magic: MagicNumericV2Opt, want: NumericIndexEntrySize},
 		{name: "V2 extended magic", magic: MagicNumericV2ExtOpt, want: NumericExtIndexEntrySize},
+		{name: "V2 tagless magic", magic: MagicNumericV2NoTagOpt, want: NumericIndexEntrySize},
 	}
 
 	for _, tt := range tests {
@@ -91,3 +156,23 @@ func TestNumericFlag_IndexEntrySize(t *testing.T) {
 		})
 	}
 }
+
+func TestNumericFlag_Metadata(t *testing.T) {
+	f := NewNumericFlag()
+	f.SetValueCompression(format.CompressionLZ4)
+	require.False(t, f.HasMetadata())
+
+	f.SetHasMetadata(true)
+	require.True(t, f.HasMetadata())
+	require.Equal(t, format.CompressionLZ4, f.ValueCompression())
+	require.NoError(t, f.Validate())
+
+	// Changing value compression must preserve the metadata bit
+	f.SetValueCompression(format.CompressionS2)
+	require.True(t, f.HasMetadata())
+	require.Equal(t, format.CompressionS2, f.ValueCompression())
+
+	f.SetHasMetadata(false)
+	require.False(t, f.HasMetadata())
+	require.Equal(t, format.CompressionS2, f.ValueCompression())
+}
@@ -1,6 +1,7 @@
 package section
 
 import (
+	"math"
 	"time"
 	"unsafe"
 
@@ -9,6 +10,12 @@ import (
 
 // Numeric
 // Header represents the fixed-size header section at the start of the metric blob.
+//
+// The header is 32 bytes (v1) unless a payload offset exceeds math.MaxUint32, in
+// which case it is 64 bytes (wide, v2): the v1 layout with WideHeaderMarker set in
+// the metric count word and the v1 payload offset fields zeroed, followed by the
+// three payload offsets as uint64 values at byte offsets 32-55 and 8 reserved
+// zero bytes. Decoders read both layouts; see Size.
 type NumericHeader struct {
 	// StartTime is the start time of the metric. the unix timestamp in microseconds.
 	StartTime int64 // byte offset 4-11
@@ -18,16 +25,20 @@ type NumericHeader struct {
 	IndexOffset uint32 // byte offset 16-19
 	// TimestampPayloadOffset is the byte offset to the start of the timestamp payload section.
 	// It records the offset after the index section.
-	TimestampPayloadOffset uint32 // byte offset 20-23
+	TimestampPayloadOffset uint64 // byte offset 20-23, wide: 32-39
 	// ValuePayloadOffset is the byte offset to the start of the value payload section.
 	// It records the offset after the encoded and compressed (if any) timestamp payload section.
-	ValuePayloadOffset uint32 // byte offset 24-27
+	ValuePayloadOffset uint64 // byte offset 24-27, wide: 40-47
 	// TagPayloadOffset is the byte offset to the start of the tag payload section.
 	// It records the offset after the encoded and compressed (if any) value payload section.
-	TagPayloadOffset uint32 // byte offset 28-31
+	TagPayloadOffset uint64 // byte offset 28-31, wide: 48-55
 
 	// Flag is a packed field for various flags and magic number.
 	Flag NumericFlag // byte offset 0-3
+
+	// Wide selects the 64-byte wide layout even if every payload offset fits in
+	// uint32. It is set by Parse for wide headers.
+	Wide bool
 }
 
 // NewNumeric
@@ -47,12 +58,12 @@ func NewNumericHeader(startTime time.Time) *NumericHeader {
 // Parse parses the header from a byte slice.
 //
 // Parameters:
-//   - data: Byte slice containing header (must be exactly 32 bytes)
+//   - data: Byte slice containing header (must be exactly 32 bytes, or 64 bytes for a wide header)
 //
 // Returns:
-//   - error: ErrInvalidHeaderSize if data is not 32 bytes, or flag validation errors
+//   - error: ErrInvalidHeaderSize if data does not match the header size, or flag validation errors
 func (h *NumericHeader) Parse(data []byte) error {
-	if len(data) != HeaderSize {
+	if len(data) != HeaderSize && len(data) != WideHeaderSize {
 		return errs.ErrInvalidHeaderSize
 	}
 
@@ -71,26 +82,63 @@ func (h *NumericHeader) Parse(data []byte) error {
 	startTimeUint := engine.Uint64(data[4:12])
 	h.StartTime = *(*int64)(unsafe.Pointer(&startTimeUint))
 
-	h.MetricCount = engine.Uint32(data[12:16])
+	countWord := engine.Uint32(data[12:16])
+	h.Wide = countWord&WideHeaderMarker != 0
+	if (len(data) == WideHeaderSize) != h.Wide {
+		return errs.ErrInvalidHeaderSize
+	}
+
+	h.MetricCount = countWord &^ WideHeaderMarker
 	h.IndexOffset = engine.Uint32(data[16:20])
-	h.TimestampPayloadOffset = engine.Uint32(data[20:24])
-	h.ValuePayloadOffset = engine.Uint32(data[24:28])
-	h.TagPayloadOffset = engine.Uint32(data[28:32])
+	if h.Wide {
+		h.TimestampPayloadOffset = engine.Uint64(data[32:40])
+		h.ValuePayloadOffset = engine.Uint64(data[40:48])
+		h.TagPayloadOffset = engine.Uint64(data[48:56])
+	} else {
+		h.TimestampPayloadOffset = uint64(engine.Uint32(data[20:24]))
+		h.ValuePayloadOffset = uint64(engine.Uint32(data[24:28]))
+		h.TagPayloadOffset = uint64(engine.Uint32(data[28:32]))
+	}
 
 	if h.MetricCount > maxSafeUint32 ||
-		h.TimestampPayloadOffset > maxSafeUint32 ||
-		h.ValuePayloadOffset > maxSafeUint32 ||
-		h.TagPayloadOffset > maxSafeUint32 {
+		h.TimestampPayloadOffset > maxSafeUint64 ||
+		h.ValuePayloadOffset > maxSafeUint64 ||
+		h.TagPayloadOffset > maxSafeUint64 {
 		return errs.ErrHeaderOffsetOverflow
 	}
 
 	return h.Flag.Validate()
 }
 
+// IsWide reports whether the header uses the 64-byte wide layout, either because
+// Wide is set or because a payload offset exceeds math.MaxUint32.
+//
+// Returns:
+//   - bool: true if Bytes produces a wide header
+func (h *NumericHeader) IsWide() bool {
+	return h.Wide ||
+		h.TimestampPayloadOffset > math.MaxUint32 ||
+		h.ValuePayloadOffset > math.MaxUint32 ||
+		h.TagPayloadOffset > math.MaxUint32
+}
+
+// Size returns the serialized size of the header: HeaderSize, or WideHeaderSize
+// for a wide header. Sections following the header start at this offset.
+//
+// Returns:
+//   - int: Header size in bytes
+func (h *NumericHeader) Size() int {
+	if h.IsWide() {
+		return WideHeaderSize
+	}
+
+	return HeaderSize
+}
+
 // Bytes serializes the Numeric
-// Header into a byte slice.
+// Header into a byte slice of Size bytes.
 func (h *NumericHeader) Bytes() []byte {
-	b := make([]byte, HeaderSize)
+	b := make([]byte, h.Size())
 
 	engine := h.Flag.GetEndianEngine()
 
@@ -101,11 +149,21 @@ func (h *NumericHeader) Bytes() []byte {
 	b[3] = h.Flag.CompressionType
 	// Use bitwise conversion to avoid overflow warning - timestamps are stored as-is in binary
 	engine.PutUint64(b[4:12], *(*uint64)(unsafe.Pointer(&h.StartTime)))
-	engine.PutUint32(b[12:16], h.MetricCount)
 	engine.PutUint32(b[16:20], h.IndexOffset)
-	engine.PutUint32(b[20:24], h.TimestampPayloadOffset)
-	engine.PutUint32(b[24:28], h.ValuePayloadOffset)
-	engine.PutUint32(b[28:32], h.TagPayloadOffset)
+
+	if len(b) == WideHeaderSize {
+		engine.PutUint32(b[12:16], h.MetricCount|WideHeaderMarker)
+		engine.PutUint64(b[32:40], h.TimestampPayloadOffset)
+		engine.PutUint64(b[40:48], h.ValuePayloadOffset)
+		engine.PutUint64(b[48:56], h.TagPayloadOffset)
+
+		return b
+	}
+
+	engine.PutUint32(b[12:16], h.MetricCount)
+	engine.PutUint32(b[20:24], uint32(h.TimestampPayloadOffset)) //nolint: gosec // IsWide guarantees the offsets fit
+	engine.PutUint32(b[24:28], uint32(h.ValuePayloadOffset))     //nolint: gosec
+	engine.PutUint32(b[28:32], uint32(h.TagPayloadOffset))       //nolint: gosec
 
 	return b
 }
@@ -118,10 +176,11 @@ func (h *NumericHeader) StartTimeAsTime() time.Time {
 	return time.UnixMicro(h.StartTime)
 }
 
-// ParseNumericHeader parses a NumericHeader from a byte slice.
+// ParseNumericHeader parses a NumericHeader from a byte slice, reading the wide
+// layout if the header is marked wide.
 //
 // Parameters:
-//   - data: Byte slice containing header (must be at least 32 bytes)
+//   - data: Byte slice containing header (must be at least 32 bytes, or 64 bytes for a wide header)
 //
 // Returns:
 //   - NumericHeader: Parsed header struct
@@ -131,22 +190,43 @@ func ParseNumericHeader(data []byte) (NumericHeader, error) {
 		return NumericHeader{}, errs.ErrInvalidHeaderSize
 	}
 
+	size := HeaderSize
+	if isWideNumericHeader(data) {
+		if len(data) < WideHeaderSize {
+			return NumericHeader{}, errs.ErrInvalidHeaderSize
+		}
+		size = WideHeaderSize
+	}
+
 	h := NumericHeader{}
-	if err := h.Parse(data[:HeaderSize]); err != nil {
+	if err := h.Parse(data[:size]); err != nil {
 		return NumericHeader{}, err
 	}
 
 	return h, nil
 }
 
+// isWideNumericHeader reports whether the header at the start of data, at least
+// HeaderSize bytes long, carries WideHeaderMarker.
+func isWideNumericHeader(data []byte) bool {
+	flag := NumericFlag{Options: uint16(data[0]) | (uint16(data[1]) << 8)}
+
+	return flag.GetEndianEngine().Uint32(data[12:16])&WideHeaderMarker != 0
+}
+
 // IsNumericBlob checks if the given data slice represents a numeric blob by inspecting the magic number.
+// Small numeric blobs (see IsNumericSmallBlob) are numeric blobs as well.
 //
 // Parameters:
-//   - data: Byte slice containing the blob data (must be at least 32 bytes)
+//   - data: Byte slice containing the blob data (must be at least 32 bytes, or 8 bytes for a small blob)
 //
 // Returns:
 //   - bool: True if the data represents a numeric blob, false otherwise
 func IsNumericBlob(data []byte) bool {
+	if IsNumericSmallBlob(data) {
+		return true
+	}
+
 	if len(data) < HeaderSize {
 		return false
 	}
@@ -154,5 +234,6 @@ func IsNumericBlob(data []byte) bool {
 	options := uint16(data[0]) | (uint16(data[1]) << 8)
 	magicNumber := options & MagicNumberMask
 
-	return magicNumber == MagicNumericV1Opt || magicNumber == MagicNumericV2Opt || magicNumber == MagicNumericV2ExtOpt
+	return magicNumber == MagicNumericV1Opt || magicNumber == MagicNumericV2Opt ||
+		magicNumber == MagicNumericV2ExtOpt || magicNumber == MagicNumericV2NoTagOpt
 }
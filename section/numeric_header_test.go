@@ -17,7 +17,7 @@ func TestNewNumericHeader(t *testing.T) {
 	require.Equal(t, startTime.UnixMicro(), header.StartTime)
 	require.Equal(t, uint32(IndexOffsetOffset), header.IndexOffset)
 	require.Equal(t, uint32(0), header.MetricCount)
-	require.Equal(t, uint32(0), header.TimestampPayloadOffset)
+	require.Equal(t, uint64(0), header.TimestampPayloadOffset)
 	require.True(t, header.Flag.IsValidMagicNumber())
 	require.True(t, header.Flag.IsLittleEndian())
 }
@@ -153,6 +153,67 @@ func TestParseNumericHeader(t *testing.T) {
 	})
 }
 
+func TestNumericHeader_Wide(t *testing.T) {
+	startTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Selected for large offsets", func(t *testing.T) {
+		for _, bigEndian := range []bool{false, true} {
+			header := NewNumericHeader(startTime)
+			if bigEndian {
+				header.Flag.WithBigEndian()
+			}
+			header.MetricCount = 65535
+			header.TimestampPayloadOffset = 1 << 20
+			header.ValuePayloadOffset = 3 << 30
+			header.TagPayloadOffset = 5 << 31
+			require.True(t, header.IsWide())
+			require.Equal(t, WideHeaderSize, header.Size())
+
+			data := header.Bytes()
+			require.Len(t, data, WideHeaderSize)
+			require.Equal(t, make([]byte, 12), data[20:32])
+			require.Equal(t, make([]byte, 8), data[56:64])
+
+			parsed, err := ParseNumericHeader(append(data, 1, 2, 3))
+			require.NoError(t, err)
+			require.True(t, parsed.Wide)
+			require.Equal(t, header.MetricCount, parsed.MetricCount)
+			require.Equal(t, header.IndexOffset, parsed.IndexOffset)
+			require.Equal(t, header.TimestampPayloadOffset, parsed.TimestampPayloadOffset)
+			require.Equal(t, header.ValuePayloadOffset, parsed.ValuePayloadOffset)
+			require.Equal(t, header.TagPayloadOffset, parsed.TagPayloadOffset)
+		}
+	})
+
+	t.Run("Forced for small offsets", func(t *testing.T) {
+		header := NewNumericHeader(startTime)
+		header.TagPayloadOffset = 100
+		require.False(t, header.IsWide())
+		require.Len(t, header.Bytes(), HeaderSize)
+
+		header.Wide = true
+		parsed, err := ParseNumericHeader(header.Bytes())
+		require.NoError(t, err)
+		require.True(t, parsed.Wide)
+		require.Equal(t, uint64(100), parsed.TagPayloadOffset)
+	})
+
+	t.Run("Size mismatch", func(t *testing.T) {
+		header := NewNumericHeader(startTime)
+		header.Wide = true
+		data := header.Bytes()
+
+		_, err := ParseNumericHeader(data[:WideHeaderSize-1])
+		require.ErrorIs(t, err, errs.ErrInvalidHeaderSize)
+
+		parsed := &NumericHeader{}
+		require.ErrorIs(t, parsed.Parse(data[:HeaderSize]), errs.ErrInvalidHeaderSize)
+
+		header.Wide = false
+		require.ErrorIs(t, parsed.Parse(append(header.Bytes(), make([]byte, 32)...)), errs.ErrInvalidHeaderSize)
+	})
+}
+
 func TestIsNumericBlob(t *testing.T) {
 	t.Run("Valid numeric blob", func(t *testing.T) {
 		header := NewNumericHeader(time.Now())
@@ -205,6 +205,32 @@ func (e *NumericIndexEntry) WriteToSlice32(data []byte, offset int, engine endia
 	return offset + NumericExtIndexEntrySize
 }
 
+// WriteToSliceNoTag writes a 16-byte tagless index entry to a pre-allocated slice
+// and returns the next position.
+//
+// Blobs without tags never use the tag offset, so the tagless layout spends those
+// bytes on wider offsets:
+//   - [0:8]   MetricID   (uint64)
+//   - [8:10]  Count      (uint16)
+//   - [10:13] TsOffset   (uint24)
+//   - [13:16] ValOffset  (uint24)
+//
+// Parameters:
+//   - data: Pre-allocated byte slice (must have space for 16 bytes at offset)
+//   - offset: Starting position in data slice
+//   - engine: Endian engine for byte order
+//
+// Returns:
+//   - int: Next write position (offset + 16)
+func (e *NumericIndexEntry) WriteToSliceNoTag(data []byte, offset int, engine endian.EndianEngine) int {
+	engine.PutUint64(data[offset:offset+8], e.MetricID)
+	engine.PutUint16(data[offset+8:offset+10], uint16(e.Count))             //nolint: gosec
+	putUint24(data[offset+10:offset+13], uint32(e.TimestampOffset), engine) //nolint: gosec
+	putUint24(data[offset+13:offset+16], uint32(e.ValueOffset), engine)     //nolint: gosec
+
+	return offset + NumericIndexEntrySize
+}
+
 // NewNumericIndexEntry creates a new NumericIndexEntry with the specified metric ID and count.
 //
 // Offsets are initialized to zero and should be set by the encoder.
@@ -291,6 +317,49 @@ func ParseNumericIndexEntryExt(data []byte, engine endian.EndianEngine) (Numeric
 	}, nil
 }
 
+// ParseNumericIndexEntryNoTag parses a 16-byte tagless NumericIndexEntry from a byte slice.
+//
+// Layout: MetricID(8) + Count(uint16, 2) + TsOffset(uint24, 3) + ValOffset(uint24, 3) = 16.
+// The parsed TagOffset is always zero.
+//
+// Parameters:
+//   - data: Byte slice containing index entry (must be at least 16 bytes)
+//   - engine: Endian engine for byte order
+//
+// Returns:
+//   - NumericIndexEntry: Parsed index entry
+//   - error: ErrInvalidIndexEntrySize if data is too short
+func ParseNumericIndexEntryNoTag(data []byte, engine endian.EndianEngine) (NumericIndexEntry, error) {
+	if len(data) < NumericIndexEntrySize {
+		return NumericIndexEntry{}, errs.ErrInvalidIndexEntrySize
+	}
+
+	return NumericIndexEntry{
+		MetricID:        engine.Uint64(data[0:8]),
+		Count:           int(engine.Uint16(data[8:10])),
+		TimestampOffset: int(uint24(data[10:13], engine)),
+		ValueOffset:     int(uint24(data[13:16], engine)),
+	}, nil
+}
+
+// putUint24 writes the low 24 bits of v into b[0:3] in the engine's byte order.
+func putUint24(b []byte, v uint32, engine endian.EndianEngine) {
+	if engine == endian.GetBigEndianEngine() {
+		b[0], b[1], b[2] = byte(v>>16), byte(v>>8), byte(v)
+	} else {
+		b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+	}
+}
+
+// uint24 reads a 24-bit unsigned integer from b[0:3] in the engine's byte order.
+func uint24(b []byte, engine endian.EndianEngine) uint32 {
+	if engine == endian.GetBigEndianEngine() {
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	}
+
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
+
 // GetMetricID returns the metric ID for this entry.
 //
 // This method is used by the generic indexMaps type for type-safe access.
@@ -296,6 +296,34 @@ func TestNumericIndexEntry_WriteToSlice32_Sequential(t *testing.T) {
 	}
 }
 
+func TestNumericIndexEntry_WriteToSliceNoTag_RoundTrip(t *testing.T) {
+	entries := []NumericIndexEntry{
+		{MetricID: 1111, Count: 10, TimestampOffset: 0, ValueOffset: 0},
+		{MetricID: 2222, Count: 65535, TimestampOffset: 70_000, ValueOffset: 0x123456},
+		{MetricID: 3333, Count: 1, TimestampOffset: NumericNoTagMaxOffset, ValueOffset: NumericNoTagMaxOffset},
+	}
+
+	for _, engine := range []endian.EndianEngine{endian.GetLittleEndianEngine(), endian.GetBigEndianEngine()} {
+		buf := make([]byte, NumericIndexEntrySize*len(entries))
+
+		offset := 0
+		for i := range entries {
+			offset = entries[i].WriteToSliceNoTag(buf, offset, engine)
+		}
+		require.Equal(t, len(buf), offset)
+
+		for i := range entries {
+			start := i * NumericIndexEntrySize
+			parsed, err := ParseNumericIndexEntryNoTag(buf[start:start+NumericIndexEntrySize], engine)
+			require.NoError(t, err)
+			require.Equal(t, entries[i], parsed)
+		}
+	}
+
+	_, err := ParseNumericIndexEntryNoTag(make([]byte, NumericIndexEntrySize-1), endian.GetLittleEndianEngine())
+	require.ErrorIs(t, err, errs.ErrInvalidIndexEntrySize)
+}
+
 func TestParseNumericIndexEntryExt_TooShort(t *testing.T) {
 	engine := endian.GetLittleEndianEngine()
 	data := make([]byte, NumericExtIndexEntrySize-1)
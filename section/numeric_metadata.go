@@ -0,0 +1,239 @@
+package section
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+)
+
+// MetadataKey identifies a record in the optional blob metadata section.
+type MetadataKey uint16
+
+const (
+	// MetadataKeyValuePrecision records the number of decimal digits values were
+	// rounded to before encoding. The value is a single byte.
+	MetadataKeyValuePrecision MetadataKey = 0x0001
+
+	// MetadataKeyQuantizationStep records the step values were snapped to before
+	// encoding. The value is the IEEE 754 bit pattern of a float64 (8 bytes).
+	MetadataKeyQuantizationStep MetadataKey = 0x0002
+
+	// MetadataKeyMetricReferences records metrics whose values are stored as
+	// deltas against a reference metric. The value is a sequence of
+	// (MetricID uint64, ReferenceMetricID uint64) pairs sorted by MetricID.
+	MetadataKeyMetricReferences MetadataKey = 0x0003
+
+	// MetadataKeyTimestampUnit records the unit of the stored timestamps as a
+	// single format.TimeUnit byte. Absent means Unix microseconds.
+	MetadataKeyTimestampUnit MetadataKey = 0x0004
+
+	// MetadataKeyTagCompression records the compression of the tag payload as a
+	// single format.CompressionType byte. Absent means Zstd.
+	MetadataKeyTagCompression MetadataKey = 0x0005
+
+	// MetadataKeyOffsetUnit records the unit of the index entry offset deltas in
+	// bytes as a single byte. Every metric section of the payloads is padded to a
+	// multiple of the unit. Absent means byte offsets.
+	MetadataKeyOffsetUnit MetadataKey = 0x0006
+
+	// MetadataKeyTagPresenceBitmap records that every metric's tag section uses the
+	// presence bitmap layout: a bitmap of non-empty tags followed by those tags only.
+	// The value is empty. Absent means every tag is stored length-prefixed.
+	MetadataKeyTagPresenceBitmap MetadataKey = 0x0007
+
+	// MetadataKeyValueExtension records the ID of the extension encoding (see
+	// format.RegisterEncoding) of the value payload as a uint16 (2 bytes). It is
+	// present exactly when the header value encoding is format.TypeExtension.
+	MetadataKeyValueExtension MetadataKey = 0x0008
+
+	// MetadataKeyMetricMeta records the kind and unit of metrics. The value is a
+	// sequence of (MetricID uint64, Kind uint8, UnitLength uint8, Unit) entries
+	// sorted by MetricID; metrics without metadata are omitted.
+	MetadataKeyMetricMeta MetadataKey = 0x0009
+
+	// MetadataKeyTimeBounds records the earliest and latest data point timestamps
+	// of the blob as two int64 values (16 bytes), in the blob's timestamp unit.
+	MetadataKeyTimeBounds MetadataKey = 0x000A
+
+	// MetadataKeyMetricSketches records a t-digest of the values of each metric.
+	// The value is a Count uint32 followed by Count (MetricID uint64, Offset
+	// uint32) directory entries sorted by MetricID and the sketch bodies; each
+	// Offset points into the bodies. A body is Min float64, Max float64, a
+	// uvarint centroid count and that many (Mean float64, Weight uvarint) pairs.
+	MetadataKeyMetricSketches MetadataKey = 0x000B
+
+	// MetadataKeyTagSummaries records the tag cardinality of each metric, in the
+	// directory layout of MetadataKeyMetricSketches. A body is the uvarint number
+	// of data points with a non-empty tag, the uvarint number of distinct
+	// non-empty tags, a uvarint count of top tags and that many (Length uvarint,
+	// Tag, Count uvarint) entries, most frequent first.
+	MetadataKeyTagSummaries MetadataKey = 0x000C
+
+	// MetadataKeyValueSums records the compensated sum of each metric's values,
+	// in the directory layout of MetadataKeyMetricSketches. A body is the
+	// uvarint number of summed (non-NaN) values, the running Sum float64 and
+	// its Compensation float64; the sum is Sum + Compensation.
+	MetadataKeyValueSums MetadataKey = 0x000D
+)
+
+// MetadataRecord is a single key/value record of the metadata section.
+type MetadataRecord struct {
+	// Key identifies the record.
+	Key MetadataKey
+
+	// Value is the raw record payload; its interpretation depends on Key.
+	Value []byte
+}
+
+// Metadata holds the records of the optional blob metadata section.
+//
+// The section is positioned after the metric names payload (if any) and before
+// the index entries. Its presence is signaled by NumericFlag.HasMetadata().
+// Records are kept sorted by key so the serialized form is deterministic.
+type Metadata struct {
+	// Records contains each metadata record, sorted by Key.
+	Records []MetadataRecord
+}
+
+// IsEmpty returns whether the metadata holds no records.
+func (m *Metadata) IsEmpty() bool {
+	return len(m.Records) == 0
+}
+
+// Get returns the value of the record with the given key.
+//
+// Parameters:
+//   - key: The metadata key to look up
+//
+// Returns:
+//   - []byte: The record value (shared with the metadata, must not be modified)
+//   - bool: true if the record exists, false otherwise
+func (m *Metadata) Get(key MetadataKey) ([]byte, bool) {
+	i, found := m.search(key)
+	if !found {
+		return nil, false
+	}
+
+	return m.Records[i].Value, true
+}
+
+// Set inserts or replaces the record with the given key.
+//
+// Parameters:
+//   - key: The metadata key
+//   - value: The record value (retained, not copied)
+func (m *Metadata) Set(key MetadataKey, value []byte) {
+	i, found := m.search(key)
+	if found {
+		m.Records[i].Value = value
+		return
+	}
+
+	m.Records = slices.Insert(m.Records, i, MetadataRecord{Key: key, Value: value})
+}
+
+// Delete removes the record with the given key, if present.
+func (m *Metadata) Delete(key MetadataKey) {
+	if i, found := m.search(key); found {
+		m.Records = slices.Delete(m.Records, i, i+1)
+	}
+}
+
+// Size returns the serialized byte size of the metadata section.
+//
+// Format: RecordCount(2B) + for each record: Key(2B) + Length(4B) + Value(Length bytes)
+//
+// Returns:
+//   - int: Total byte size of the serialized section
+func (m *Metadata) Size() int {
+	size := 2 // RecordCount
+
+	for i := range m.Records {
+		size += 6 + len(m.Records[i].Value) // Key(2) + Length(4) + Value
+	}
+
+	return size
+}
+
+// WriteToSlice serializes the metadata section into the given byte slice.
+//
+// Parameters:
+//   - data: Pre-allocated byte slice (must have space for Size() bytes at offset)
+//   - offset: Starting position in data slice
+//   - engine: Endian engine for byte order
+//
+// Returns:
+//   - int: Next write position after the section
+func (m *Metadata) WriteToSlice(data []byte, offset int, engine endian.EndianEngine) int {
+	engine.PutUint16(data[offset:offset+2], uint16(len(m.Records))) //nolint: gosec
+	offset += 2
+
+	for i := range m.Records {
+		r := &m.Records[i]
+		engine.PutUint16(data[offset:offset+2], uint16(r.Key))
+		offset += 2
+
+		engine.PutUint32(data[offset:offset+4], uint32(len(r.Value))) //nolint: gosec
+		offset += 4
+
+		offset += copy(data[offset:], r.Value)
+	}
+
+	return offset
+}
+
+// ParseMetadata parses a metadata section from the beginning of a byte slice.
+//
+// Record values reference the input slice; they are not copied.
+//
+// Parameters:
+//   - data: Byte slice starting with the serialized section (may contain trailing data)
+//   - engine: Endian engine for byte order
+//
+// Returns:
+//   - Metadata: Parsed metadata
+//   - int: Number of bytes consumed
+//   - error: ErrInvalidMetadata if the section is truncated, has duplicate keys,
+//     or is not sorted by key
+func ParseMetadata(data []byte, engine endian.EndianEngine) (Metadata, int, error) {
+	if len(data) < 2 {
+		return Metadata{}, 0, fmt.Errorf("%w: metadata section too short", errs.ErrInvalidMetadata)
+	}
+
+	count := int(engine.Uint16(data[0:2]))
+	offset := 2
+
+	records := make([]MetadataRecord, count)
+	for i := range count {
+		if offset+6 > len(data) {
+			return Metadata{}, 0, fmt.Errorf("%w: metadata section truncated at record %d", errs.ErrInvalidMetadata, i)
+		}
+
+		key := MetadataKey(engine.Uint16(data[offset : offset+2]))
+		length := uint64(engine.Uint32(data[offset+2 : offset+6]))
+		offset += 6
+
+		if uint64(len(data)-offset) < length {
+			return Metadata{}, 0, fmt.Errorf("%w: metadata record %d value truncated", errs.ErrInvalidMetadata, i)
+		}
+
+		if i > 0 && key <= records[i-1].Key {
+			return Metadata{}, 0, fmt.Errorf("%w: metadata keys must be unique and sorted", errs.ErrInvalidMetadata)
+		}
+
+		end := offset + int(length) //nolint: gosec
+		records[i] = MetadataRecord{Key: key, Value: data[offset:end:end]}
+		offset = end
+	}
+
+	return Metadata{Records: records}, offset, nil
+}
+
+// search returns the position of key in the sorted records and whether it exists.
+func (m *Metadata) search(key MetadataKey) (int, bool) {
+	return slices.BinarySearchFunc(m.Records, key, func(r MetadataRecord, k MetadataKey) int {
+		return int(r.Key) - int(k)
+	})
+}
@@ -0,0 +1,85 @@
+package section
+
+import (
+	"testing"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	for _, engine := range []endian.EndianEngine{endian.GetLittleEndianEngine(), endian.GetBigEndianEngine()} {
+		var md Metadata
+		md.Set(MetadataKeyQuantizationStep, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+		md.Set(MetadataKeyValuePrecision, []byte{3})
+		md.Set(MetadataKey(0x7F), nil)
+
+		// Records are kept sorted by key regardless of insertion order
+		require.Equal(t, MetadataKeyValuePrecision, md.Records[0].Key)
+		require.Equal(t, MetadataKeyQuantizationStep, md.Records[1].Key)
+
+		data := make([]byte, md.Size()+4) // trailing bytes belong to the next section
+		end := md.WriteToSlice(data, 0, engine)
+		require.Equal(t, md.Size(), end)
+
+		parsed, n, err := ParseMetadata(data, engine)
+		require.NoError(t, err)
+		require.Equal(t, end, n)
+		require.Len(t, parsed.Records, 3)
+
+		v, ok := parsed.Get(MetadataKeyValuePrecision)
+		require.True(t, ok)
+		require.Equal(t, []byte{3}, v)
+
+		v, ok = parsed.Get(MetadataKeyQuantizationStep)
+		require.True(t, ok)
+		require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, v)
+
+		v, ok = parsed.Get(MetadataKey(0x7F))
+		require.True(t, ok)
+		require.Empty(t, v)
+	}
+}
+
+func TestMetadataSetReplacesAndDelete(t *testing.T) {
+	var md Metadata
+	require.True(t, md.IsEmpty())
+
+	md.Set(MetadataKeyValuePrecision, []byte{1})
+	md.Set(MetadataKeyValuePrecision, []byte{2})
+	require.Len(t, md.Records, 1)
+
+	v, ok := md.Get(MetadataKeyValuePrecision)
+	require.True(t, ok)
+	require.Equal(t, []byte{2}, v)
+
+	md.Delete(MetadataKeyValuePrecision)
+	md.Delete(MetadataKeyQuantizationStep) // absent key is a no-op
+	require.True(t, md.IsEmpty())
+
+	_, ok = md.Get(MetadataKeyValuePrecision)
+	require.False(t, ok)
+}
+
+func TestParseMetadataRejectsInvalidData(t *testing.T) {
+	engine := endian.GetLittleEndianEngine()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty", data: []byte{}},
+		{name: "TruncatedRecordHeader", data: []byte{1, 0, 1, 0, 1}},
+		{name: "TruncatedValue", data: []byte{1, 0, 1, 0, 4, 0, 0, 0, 0xAA}},
+		{name: "DuplicateKeys", data: []byte{2, 0, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0}},
+		{name: "UnsortedKeys", data: []byte{2, 0, 2, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseMetadata(tt.data, engine)
+			require.ErrorIs(t, err, errs.ErrInvalidMetadata)
+		})
+	}
+}
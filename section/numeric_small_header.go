@@ -0,0 +1,188 @@
+package section
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/arloliu/mebo/endian"
+	"github.com/arloliu/mebo/errs"
+)
+
+// smallMetadataMask marks a metadata section in byte 3 of a small header.
+const smallMetadataMask = 0x01
+
+// NumericSmallHeader is the 8-byte header of small numeric blobs, a layout for
+// blobs of a single metric that replaces the 32-byte header and 16-byte index
+// entry of the standard layout:
+//
+//	byte 0-1: Options, little-endian: MagicNumericSmallOpt, tag and endianness bits
+//	byte 2:   EncodingType, as in NumericFlag
+//	byte 3:   bit 0 set if a metadata section follows the index, other bits zero
+//	byte 4-7: Data point count of the metric
+//
+// The header is followed by the merged index (see NumericSmallIndex), the
+// optional metadata section, and the uncompressed timestamp, value and tag
+// payloads; the tag payload extends to the end of the blob.
+type NumericSmallHeader struct {
+	// Flag is the equivalent flag of a standard V1 blob: the V1 magic number,
+	// uncompressed payloads and the metadata bit.
+	Flag NumericFlag // byte offset 0-3
+	// Count is the number of data points of the metric, max to 65535.
+	Count uint32 // byte offset 4-7
+}
+
+// NumericSmallIndex is the merged index of a small numeric blob, following its
+// header: the metric ID in the blob byte order, the start time as a zigzag
+// varint and the timestamp and value payload sizes as uvarints.
+type NumericSmallIndex struct {
+	// MetricID is the ID of the metric of the blob.
+	MetricID uint64
+	// StartTime is the start time of the blob in microseconds.
+	StartTime int64
+	// TimestampSize is the size in bytes of the timestamp payload.
+	TimestampSize int
+	// ValueSize is the size in bytes of the value payload.
+	ValueSize int
+}
+
+// Parse parses the header from a byte slice.
+//
+// Parameters:
+//   - data: Byte slice containing the header (must be exactly 8 bytes)
+//
+// Returns:
+//   - error: ErrInvalidHeaderSize, ErrInvalidMagicNumber, ErrInvalidHeaderFlags
+//     for reserved bits or invalid encodings, or ErrInvalidNumOfDataPoints
+func (h *NumericSmallHeader) Parse(data []byte) error {
+	if len(data) != SmallHeaderSize {
+		return errs.ErrInvalidHeaderSize
+	}
+
+	options := uint16(data[0]) | (uint16(data[1]) << 8)
+	if options&MagicNumberMask != MagicNumericSmallOpt {
+		return errs.ErrInvalidMagicNumber
+	}
+	if options&^(MagicNumberMask|TagMask|EndiannessMask) != 0 || data[3]&^smallMetadataMask != 0 {
+		return errs.ErrInvalidHeaderFlags
+	}
+
+	flag := NumericFlag{
+		Options:         options&^MagicNumberMask | MagicNumericV1Opt,
+		EncodingType:    data[2],
+		CompressionType: TimestampCompressionNone | ValueCompressionNone,
+	}
+	flag.SetHasMetadata(data[3]&smallMetadataMask != 0)
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+
+	h.Flag = flag
+	h.Count = flag.GetEndianEngine().Uint32(data[4:8])
+	if h.Count == 0 || h.Count > math.MaxUint16 {
+		return errs.ErrInvalidNumOfDataPoints
+	}
+
+	return nil
+}
+
+// Bytes serializes the header into a byte slice of SmallHeaderSize bytes.
+func (h *NumericSmallHeader) Bytes() []byte {
+	b := make([]byte, SmallHeaderSize)
+
+	options := h.Flag.Options&(TagMask|EndiannessMask) | MagicNumericSmallOpt
+	b[0] = byte(options) //nolint:gosec // The binary format stores the low byte first.
+	b[1] = byte(options >> 8)
+	b[2] = h.Flag.EncodingType
+	if h.Flag.HasMetadata() {
+		b[3] = smallMetadataMask
+	}
+	h.Flag.GetEndianEngine().PutUint32(b[4:8], h.Count)
+
+	return b
+}
+
+// AppendTo appends the encoded index to dst.
+//
+// Parameters:
+//   - dst: Destination slice
+//   - engine: Byte order of the blob
+//
+// Returns:
+//   - []byte: dst with the index appended
+func (x NumericSmallIndex) AppendTo(dst []byte, engine endian.EndianEngine) []byte {
+	dst = engine.AppendUint64(dst, x.MetricID)
+	dst = binary.AppendVarint(dst, x.StartTime)
+	dst = binary.AppendUvarint(dst, uint64(x.TimestampSize)) //nolint:gosec // sizes are non-negative
+	dst = binary.AppendUvarint(dst, uint64(x.ValueSize))     //nolint:gosec // sizes are non-negative
+
+	return dst
+}
+
+// Size returns the encoded size of the index in bytes.
+func (x NumericSmallIndex) Size() int {
+	var buf [3 * binary.MaxVarintLen64]byte
+	n := len(binary.AppendVarint(buf[:0], x.StartTime))
+	n += len(binary.AppendUvarint(buf[:0], uint64(x.TimestampSize))) //nolint:gosec // sizes are non-negative
+	n += len(binary.AppendUvarint(buf[:0], uint64(x.ValueSize)))     //nolint:gosec // sizes are non-negative
+
+	return 8 + n
+}
+
+// ParseNumericSmallIndex parses the merged index of a small numeric blob.
+//
+// Parameters:
+//   - data: Byte slice starting with the index
+//   - engine: Byte order of the blob
+//
+// Returns:
+//   - NumericSmallIndex: Parsed index
+//   - int: Size of the index in bytes
+//   - error: ErrInvalidMetricID for a zero metric ID, or
+//     ErrInvalidTimestampPayloadOffset for a truncated or malformed index
+func ParseNumericSmallIndex(data []byte, engine endian.EndianEngine) (NumericSmallIndex, int, error) {
+	if len(data) < 8 {
+		return NumericSmallIndex{}, 0, errs.ErrInvalidTimestampPayloadOffset
+	}
+
+	x := NumericSmallIndex{MetricID: engine.Uint64(data)}
+	if x.MetricID == 0 {
+		return NumericSmallIndex{}, 0, errs.ErrInvalidMetricID
+	}
+
+	offset := 8
+	start, n := binary.Varint(data[offset:])
+	if n <= 0 {
+		return NumericSmallIndex{}, 0, errs.ErrInvalidTimestampPayloadOffset
+	}
+	x.StartTime, offset = start, offset+n
+
+	var sizes [2]uint64
+	for i := range sizes {
+		sizes[i], n = binary.Uvarint(data[offset:])
+		if n <= 0 || sizes[i] > uint64(len(data)) {
+			return NumericSmallIndex{}, 0, errs.ErrInvalidTimestampPayloadOffset
+		}
+		offset += n
+	}
+	x.TimestampSize, x.ValueSize = int(sizes[0]), int(sizes[1]) //nolint:gosec // bounded by len(data)
+
+	return x, offset, nil
+}
+
+// IsNumericSmallBlob checks if the given data slice represents a small numeric
+// blob by inspecting the magic number.
+//
+// Parameters:
+//   - data: Byte slice containing the blob data (must be at least 8 bytes)
+//
+// Returns:
+//   - bool: True if the data represents a small numeric blob, false otherwise
+func IsNumericSmallBlob(data []byte) bool {
+	if len(data) < SmallHeaderSize {
+		return false
+	}
+
+	options := uint16(data[0]) | (uint16(data[1]) << 8)
+
+	return options&MagicNumberMask == MagicNumericSmallOpt
+}
@@ -12,17 +12,26 @@ type TextFlag struct {
 	// Bit 0 is tag flag, 0 means no tags, 1 means per-point tags are present.
 	// Bit 1 is endianness flag, 0 means little-endian, 1 means big-endian.
 	// Bit 2 is metric names payload flag, 0 means no metric names, 1 means metric names payload is present.
-	// Bit 3 is reserved for future use, must be set to 0.
+	// Bit 3 is per-value compression flag, 1 means value lengths carry a compression marker
+	// (text blobs only, reserved and must be 0 in event blobs).
 	// Bits 4-15 are magic number to identify the blob format:
 	//   - 0xEB10 (0b1110_1011_0001_0000): Text value blob format v1
+	//   - 0xEC10 (0b1110_1100_0001_0000): Event blob format v1, the text layout whose
+	//     values are codes into a text dictionary stored at the end of the data section
 	Options uint16
 
-	// TimestampEncoding indicates the encoding used for timestamps.
+	// TimestampEncoding indicates the encoding used for timestamps in bits 0-3.
 	// Valid values: TypeRaw, TypeDelta
+	// Bit 4 is the seek index flag, 1 means metrics may start with a table of row offsets.
+	// Bit 5 is the repeated value flag, 1 means value lengths may mark a repeat of the previous value.
+	// Bits 6-7 are reserved and must be 0.
 	TimestampEncoding uint8
 
-	// DataCompression indicates the compression used for the data section.
+	// DataCompression indicates the compression used for the data section in bits 0-3.
 	// Valid values: CompressionNone, CompressionZstd, CompressionS2, CompressionLz4
+	// Bits 4-7 are the tag section compression, 0 means tags are stored inline in
+	// the data section, any other valid compression means they are stored in a
+	// separate tag section compressed with it (text blobs only, 0 in event blobs).
 	DataCompression uint8
 }
 
@@ -69,9 +78,71 @@ func (f *TextFlag) SetHasMetricNames(enabled bool) {
 	}
 }
 
+// HasValueCompression returns whether values above a size threshold are compressed individually.
+// When enabled, each value length is a uvarint whose lowest bit marks a compressed value.
+func (f TextFlag) HasValueCompression() bool {
+	return (f.Options & ValueCompressionMask) != 0
+}
+
+// SetValueCompression enables or disables per-value compression.
+func (f *TextFlag) SetValueCompression(enabled bool) {
+	if enabled {
+		f.Options |= ValueCompressionMask
+	} else {
+		f.Options &^= ValueCompressionMask
+	}
+}
+
+// HasSeekIndex returns whether metrics may start with a seek table of row offsets.
+// When enabled, the seek interval of each metric is stored in its index entry.
+func (f TextFlag) HasSeekIndex() bool {
+	return (f.TimestampEncoding & SeekIndexMask) != 0
+}
+
+// SetSeekIndex enables or disables per-metric seek tables.
+func (f *TextFlag) SetSeekIndex(enabled bool) {
+	if enabled {
+		f.TimestampEncoding |= SeekIndexMask
+	} else {
+		f.TimestampEncoding &^= SeekIndexMask
+	}
+}
+
+// HasValueRLE returns whether a value equal to the previous value of its metric
+// is stored as a repeat marker instead of the value bytes.
+func (f TextFlag) HasValueRLE() bool {
+	return (f.TimestampEncoding & ValueRLEMask) != 0
+}
+
+// SetValueRLE enables or disables repeat markers for repeated values.
+func (f *TextFlag) SetValueRLE(enabled bool) {
+	if enabled {
+		f.TimestampEncoding |= ValueRLEMask
+	} else {
+		f.TimestampEncoding &^= ValueRLEMask
+	}
+}
+
 // IsValidMagicNumber checks if the magic number in the Options field is valid.
 func (f TextFlag) IsValidMagicNumber() bool {
-	return f.GetMagicNumber() == MagicTextV1Opt
+	magic := f.GetMagicNumber()
+
+	return magic == MagicTextV1Opt || magic == MagicEventV1Opt
+}
+
+// IsEvent returns whether the flag identifies an event blob.
+func (f TextFlag) IsEvent() bool {
+	return f.GetMagicNumber() == MagicEventV1Opt
+}
+
+// SetEvent switches the magic number between the event and the text blob format.
+func (f *TextFlag) SetEvent(enabled bool) {
+	f.Options &^= MagicNumberMask
+	if enabled {
+		f.Options |= MagicEventV1Opt
+	} else {
+		f.Options |= MagicTextV1Opt
+	}
 }
 
 // IsLittleEndian returns whether the data is little-endian.
@@ -101,45 +172,73 @@ func (f TextFlag) GetMagicNumber() uint16 {
 
 // SetTimestampEncoding sets the timestamp encoding type.
 func (f *TextFlag) SetTimestampEncoding(encoding format.EncodingType) {
-	f.TimestampEncoding = uint8(encoding)
+	f.TimestampEncoding = (f.TimestampEncoding &^ TextTsEncodingMask) | (uint8(encoding) & TextTsEncodingMask)
 }
 
 // GetTimestampEncoding returns the timestamp encoding type.
 func (f TextFlag) GetTimestampEncoding() format.EncodingType {
-	return format.EncodingType(f.TimestampEncoding)
+	return format.EncodingType(f.TimestampEncoding & TextTsEncodingMask)
 }
 
 // SetDataCompression sets the data compression type.
 func (f *TextFlag) SetDataCompression(compression format.CompressionType) {
-	f.DataCompression = uint8(compression)
+	f.DataCompression = (f.DataCompression &^ TextDataCompMask) | (uint8(compression) & TextDataCompMask)
 }
 
 // GetDataCompression returns the data compression type.
 func (f TextFlag) GetDataCompression() format.CompressionType {
-	return format.CompressionType(f.DataCompression)
+	return format.CompressionType(f.DataCompression & TextDataCompMask)
+}
+
+// HasTagSection returns whether tags are stored in a separate tag section
+// instead of inline in the data section.
+func (f TextFlag) HasTagSection() bool {
+	return (f.DataCompression & TagCompressionMask) != 0
+}
+
+// SetTagCompression sets the compression type of the separate tag section.
+// A zero compression type stores tags inline in the data section.
+func (f *TextFlag) SetTagCompression(compression format.CompressionType) {
+	f.DataCompression = (f.DataCompression &^ TagCompressionMask) | (uint8(compression)<<4)&TagCompressionMask
+}
+
+// GetTagCompression returns the compression type of the separate tag section,
+// or 0 if tags are stored inline in the data section.
+func (f TextFlag) GetTagCompression() format.CompressionType {
+	return format.CompressionType((f.DataCompression & TagCompressionMask) >> 4)
 }
 
 // Validate checks if the flag header contains valid values.
 func (f TextFlag) Validate() error {
 	// Check magic number
-	if f.GetMagicNumber() != MagicTextV1Opt {
+	if !f.IsValidMagicNumber() {
 		return errs.ErrInvalidHeaderFlags
 	}
 
 	// Check reserved bits are zero
-	if (f.Options & ReservedBitsMask) != 0 {
+	if f.IsEvent() && (f.Options&ReservedBitsMask) != 0 {
 		return errs.ErrInvalidHeaderFlags
 	}
 
-	// Validate timestamp encoding
-	if _, ok := validTimestampEncodings[f.TimestampEncoding]; !ok {
+	// Validate timestamp encoding and reserved bits
+	if f.TimestampEncoding&^(TextTsEncodingMask|SeekIndexMask|ValueRLEMask) != 0 {
+		return errs.ErrInvalidHeaderFlags
+	}
+	if _, ok := validTimestampEncodings[f.TimestampEncoding&TextTsEncodingMask]; !ok {
 		return errs.ErrInvalidHeaderFlags
 	}
 
 	// Validate data compression (use same map as timestamp compressions - they're the same)
-	if _, ok := validTimestampCompressions[f.DataCompression]; !ok {
+	if _, ok := validTimestampCompressions[f.DataCompression&TextDataCompMask]; !ok {
 		return errs.ErrInvalidHeaderFlags
 	}
 
+	// A tag section requires tags and is not part of the event layout
+	if f.HasTagSection() {
+		if _, ok := validTimestampCompressions[uint8(f.GetTagCompression())]; !ok || !f.HasTag() || f.IsEvent() {
+			return errs.ErrInvalidHeaderFlags
+		}
+	}
+
 	return nil
 }
@@ -2,6 +2,7 @@ package section
 
 import (
 	"testing"
+	"time"
 
 	"github.com/arloliu/mebo/errs"
 	"github.com/arloliu/mebo/format"
@@ -83,6 +84,32 @@ func TestTextFlag_TimestampEncoding(t *testing.T) {
 	require.Equal(t, format.TypeRaw, flag.GetTimestampEncoding())
 }
 
+func TestTextFlag_SeekIndexAndValueRLE(t *testing.T) {
+	flag := NewTextFlag()
+	require.False(t, flag.HasSeekIndex())
+	require.False(t, flag.HasValueRLE())
+
+	flag.SetSeekIndex(true)
+	flag.SetValueRLE(true)
+	require.True(t, flag.HasSeekIndex())
+	require.True(t, flag.HasValueRLE())
+	require.NoError(t, flag.Validate())
+
+	// The feature bits survive timestamp encoding changes and are not part of it
+	flag.SetTimestampEncoding(format.TypeDelta)
+	require.Equal(t, format.TypeDelta, flag.GetTimestampEncoding())
+	require.True(t, flag.HasSeekIndex())
+	require.True(t, flag.HasValueRLE())
+
+	flag.SetSeekIndex(false)
+	flag.SetValueRLE(false)
+	require.Equal(t, uint8(format.TypeDelta), flag.TimestampEncoding)
+
+	// Bits 6-7 are reserved
+	flag.TimestampEncoding |= 0x40
+	require.ErrorIs(t, flag.Validate(), errs.ErrInvalidHeaderFlags)
+}
+
 func TestTextFlag_DataCompression(t *testing.T) {
 	flag := NewTextFlag()
 
@@ -103,6 +130,33 @@ func TestTextFlag_DataCompression(t *testing.T) {
 	}
 }
 
+func TestTextFlag_TagCompression(t *testing.T) {
+	flag := NewTextFlag()
+	require.False(t, flag.HasTagSection())
+
+	// The tag section compression shares the byte with the data compression
+	flag.SetTagCompression(format.CompressionLZ4)
+	flag.SetDataCompression(format.CompressionS2)
+	require.True(t, flag.HasTagSection())
+	require.Equal(t, format.CompressionLZ4, flag.GetTagCompression())
+	require.Equal(t, format.CompressionS2, flag.GetDataCompression())
+
+	// A tag section requires tags and is not part of the event layout
+	require.ErrorIs(t, flag.Validate(), errs.ErrInvalidHeaderFlags)
+	flag.WithTag()
+	require.NoError(t, flag.Validate())
+	flag.SetEvent(true)
+	require.ErrorIs(t, flag.Validate(), errs.ErrInvalidHeaderFlags)
+	flag.SetEvent(false)
+
+	flag.DataCompression |= 0x70
+	require.ErrorIs(t, flag.Validate(), errs.ErrInvalidHeaderFlags)
+
+	flag.SetTagCompression(0)
+	require.False(t, flag.HasTagSection())
+	require.Equal(t, format.CompressionS2, flag.GetDataCompression())
+}
+
 func TestTextFlag_Validate_Success(t *testing.T) {
 	flag := NewTextFlag()
 
@@ -123,6 +177,7 @@ func TestTextFlag_Validate_Success(t *testing.T) {
 
 func TestTextFlag_Validate_ReservedBits(t *testing.T) {
 	flag := NewTextFlag()
+	flag.SetEvent(true)
 
 	// Set reserved bits (should fail validation)
 	flag.Options |= ReservedBitsMask
@@ -131,6 +186,19 @@ func TestTextFlag_Validate_ReservedBits(t *testing.T) {
 	require.ErrorIs(t, err, errs.ErrInvalidHeaderFlags)
 }
 
+func TestTextFlag_ValueCompression(t *testing.T) {
+	flag := NewTextFlag()
+	require.False(t, flag.HasValueCompression())
+
+	flag.SetValueCompression(true)
+	require.True(t, flag.HasValueCompression())
+	require.NoError(t, flag.Validate())
+	require.Equal(t, uint16(MagicTextV1Opt), flag.GetMagicNumber())
+
+	flag.SetValueCompression(false)
+	require.False(t, flag.HasValueCompression())
+}
+
 func TestTextFlag_Validate_MagicNumber(t *testing.T) {
 	flag := NewTextFlag()
 
@@ -219,3 +287,28 @@ func TestTextFlag_MagicNumberPreserved(t *testing.T) {
 	magic := flag.GetMagicNumber()
 	require.Equal(t, uint16(MagicTextV1Opt), magic)
 }
+
+func TestTextFlag_Event(t *testing.T) {
+	flag := NewTextFlag()
+	flag.WithTag()
+	require.False(t, flag.IsEvent())
+
+	flag.SetEvent(true)
+	require.True(t, flag.IsEvent())
+	require.True(t, flag.HasTag())
+	require.NoError(t, flag.Validate())
+
+	header, err := NewTextHeader(time.Now(), 1)
+	require.NoError(t, err)
+	header.Flag = flag
+	require.True(t, IsEventBlob(header.Bytes()))
+	require.False(t, IsTextBlob(header.Bytes()))
+
+	var parsed TextHeader
+	require.NoError(t, parsed.Parse(header.Bytes()))
+	require.True(t, parsed.Flag.IsEvent())
+
+	flag.SetEvent(false)
+	require.False(t, flag.IsEvent())
+	require.Equal(t, uint16(MagicTextV1Opt), flag.GetMagicNumber())
+}
@@ -20,7 +20,11 @@ type TextHeader struct {
 	// Flag is a packed field for various flags and magic number (0xEB10).
 	Flag TextFlag // 4 bytes, offset 0-3
 
-	Reserved [4]byte // Reserved for future use, must be zero, offset 28-31
+	// Reserved is reserved for future use and must be zero, except in event blobs
+	// where it holds the event dictionary offset (see EventDictionaryOffset), and
+	// in text blobs with per-value compression where it holds the value
+	// dictionary offset (see ValueDictionaryOffset).
+	Reserved [4]byte // 4 bytes, offset 28-31
 
 	// StartTime is the start time of the metric, unix timestamp in microseconds.
 	StartTime int64 // 8 bytes, offset 4-11
@@ -127,6 +131,37 @@ func (h *TextHeader) GetEndianEngine() endian.EndianEngine {
 	return endian.GetLittleEndianEngine()
 }
 
+// EventDictionaryOffset returns the offset of the event text dictionary within
+// the uncompressed data section of an event blob. The metric rows occupy the
+// data section up to this offset and the dictionary fills the rest.
+func (h *TextHeader) EventDictionaryOffset() uint32 {
+	return h.GetEndianEngine().Uint32(h.Reserved[:])
+}
+
+// SetEventDictionaryOffset sets the offset of the event text dictionary within
+// the uncompressed data section of an event blob.
+func (h *TextHeader) SetEventDictionaryOffset(offset uint32) {
+	h.GetEndianEngine().PutUint32(h.Reserved[:], offset)
+}
+
+// ValueDictionaryOffset returns the offset of the per-value compression
+// dictionary within the uncompressed data section of a text blob, or 0 if the
+// blob has none. The metric rows occupy the data section up to this offset and
+// the dictionary fills the rest.
+func (h *TextHeader) ValueDictionaryOffset() uint32 {
+	if h.Flag.IsEvent() || !h.Flag.HasValueCompression() {
+		return 0
+	}
+
+	return h.GetEndianEngine().Uint32(h.Reserved[:])
+}
+
+// SetValueDictionaryOffset sets the offset of the per-value compression
+// dictionary within the uncompressed data section of a text blob.
+func (h *TextHeader) SetValueDictionaryOffset(offset uint32) {
+	h.GetEndianEngine().PutUint32(h.Reserved[:], offset)
+}
+
 // IsValidFlags checks if the header flags are valid for text value blob.
 func (h *TextHeader) IsValidFlags() bool {
 	if err := h.Flag.Validate(); err != nil {
@@ -174,3 +209,21 @@ func IsTextBlob(data []byte) bool {
 
 	return magic == MagicTextV1Opt
 }
+
+// IsEventBlob checks if the given data slice represents an event blob by inspecting the magic number.
+//
+// Parameters:
+//   - data: Byte slice containing the blob data (must be at least 32 bytes)
+//
+// Returns:
+//   - bool: True if the data represents an event blob, false otherwise
+func IsEventBlob(data []byte) bool {
+	if len(data) < HeaderSize {
+		return false
+	}
+
+	options := uint16(data[0]) | (uint16(data[1]) << 8)
+	magic := options & MagicNumberMask
+
+	return magic == MagicEventV1Opt
+}
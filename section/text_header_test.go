@@ -348,3 +348,16 @@ func TestIsNumericBlob_vs_IsTextBlob(t *testing.T) {
 		require.True(t, IsTextBlob(data))
 	})
 }
+
+func TestTextHeader_EventDictionaryOffset(t *testing.T) {
+	header, err := NewTextHeader(time.Now(), 1)
+	require.NoError(t, err)
+	header.Flag.SetEvent(true)
+	header.Flag.WithBigEndian()
+	header.SetEventDictionaryOffset(0x01020304)
+
+	var parsed TextHeader
+	require.NoError(t, parsed.Parse(header.Bytes()))
+	require.Equal(t, uint32(0x01020304), parsed.EventDictionaryOffset())
+	require.Equal(t, [4]byte{1, 2, 3, 4}, parsed.Reserved)
+}
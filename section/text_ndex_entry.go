@@ -29,7 +29,9 @@ type TextIndexEntry struct {
 	// Count is the number of data points for this metric.
 	Count uint16 // 2 bytes, offset 8-9
 
-	// Reserved for future use, must be set to 0.
+	// Reserved1 is the seek interval of the metric in blobs with a seek index
+	// (see TextFlag.HasSeekIndex), 0 if the metric has no seek table. It is
+	// reserved and must be set to 0 in other blobs.
 	Reserved1 uint16 // 2 bytes, offset 10-11
 
 	// Offset is the absolute byte offset from the start of the data section.
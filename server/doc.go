@@ -0,0 +1,40 @@
+// Package server exposes a blob.BlobSet as a read-only HTTP/JSON API.
+//
+// It lets teams stand up a query endpoint over mebo blobs without writing decode
+// plumbing: blobs are decoded server-side and returned as JSON, or streamed as
+// newline-delimited JSON for large ranges. The package depends only on net/http,
+// so it can be mounted in any existing router, or placed behind a gRPC/HTTP gateway.
+//
+// # Endpoints
+//
+//	GET /v1/blobs           Headers of all blobs in the set
+//	GET /v1/blobs/{index}   Header of one blob (numeric blobs first, then text blobs)
+//	GET /v1/metrics         Per-metric statistics (blob.BlobSet.Stats)
+//	GET /v1/points          Range query returning a PointsResponse
+//	GET /v1/stream          Range query streamed as one Point per line (NDJSON)
+//
+// The points and stream endpoints select a metric with either id (decimal or
+// 0x-prefixed hex) or name, and accept optional filters:
+//
+//	start   Inclusive lower timestamp bound, in the blob's timestamp unit
+//	end     Exclusive upper timestamp bound
+//	limit   Maximum number of points (points: capped at Config.MaxPoints)
+//
+// Errors are returned as {"error": "..."} with a 4xx status code.
+//
+// # Usage
+//
+//	set, err := blob.DecodeBlobSet(blobs...)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	srv, err := server.New(set, server.WithMaxPoints(50_000))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Handle("/mebo/", http.StripPrefix("/mebo", srv))
+//
+//	// Later, swap in a refreshed blob set without restarting:
+//	srv.SetBlobSet(newSet)
+package server
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/arloliu/mebo/internal/options"
+)
+
+const (
+	// DefaultMaxPoints is the default cap on points returned by one /v1/points request.
+	DefaultMaxPoints = 100_000
+
+	// DefaultStreamFlushEvery is the default number of streamed points between flushes.
+	DefaultStreamFlushEvery = 1024
+)
+
+// Config holds server settings.
+type Config struct {
+	// MaxPoints caps the points returned by /v1/points. Larger ranges are truncated
+	// and flagged; use /v1/stream to read them in full.
+	MaxPoints int
+
+	// StreamFlushEvery is the number of points written by /v1/stream between flushes.
+	StreamFlushEvery int
+}
+
+// Option is a functional option for Config.
+type Option = options.Option[*Config]
+
+// defaultConfig returns the default server configuration.
+func defaultConfig() Config {
+	return Config{
+		MaxPoints:        DefaultMaxPoints,
+		StreamFlushEvery: DefaultStreamFlushEvery,
+	}
+}
+
+// WithMaxPoints sets the maximum number of points returned by one /v1/points request.
+// It must be positive. Default is DefaultMaxPoints.
+func WithMaxPoints(n int) Option {
+	return options.New(func(cfg *Config) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max points: %d, must be positive", n)
+		}
+		cfg.MaxPoints = n
+
+		return nil
+	})
+}
+
+// WithStreamFlushEvery sets how many points /v1/stream writes between flushes.
+// It must be positive. Default is DefaultStreamFlushEvery.
+func WithStreamFlushEvery(n int) Option {
+	return options.New(func(cfg *Config) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid stream flush interval: %d, must be positive", n)
+		}
+		cfg.StreamFlushEvery = n
+
+		return nil
+	})
+}
@@ -0,0 +1,441 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+const (
+	// KindNumeric identifies numeric blobs and metrics in responses.
+	KindNumeric = "numeric"
+	// KindText identifies text blobs and metrics in responses.
+	KindText = "text"
+)
+
+var (
+	errMissingMetric = errors.New("exactly one of id or name is required")
+	errUnknownMetric = errors.New("metric not found")
+)
+
+// Server serves a blob.BlobSet over HTTP. It implements http.Handler and is safe
+// for concurrent use.
+type Server struct {
+	cfg Config
+	set atomic.Pointer[blob.BlobSet]
+	mux *http.ServeMux
+}
+
+// BlobHeader describes one blob of the set.
+type BlobHeader struct {
+	Index             int       `json:"index"`
+	Kind              string    `json:"kind"`
+	StartTime         time.Time `json:"start_time"`
+	MetricCount       int       `json:"metric_count"`
+	TimestampEncoding string    `json:"timestamp_encoding"`
+	ValueEncoding     string    `json:"value_encoding,omitempty"`
+	TimestampUnit     string    `json:"timestamp_unit,omitempty"`
+	BigEndian         bool      `json:"big_endian"`
+	Tags              bool      `json:"tags"`
+	MetricNames       bool      `json:"metric_names"`
+	V2Layout          bool      `json:"v2_layout"`
+}
+
+// MetricInfo summarizes one metric across the set.
+type MetricInfo struct {
+	ID             uint64 `json:"id"`
+	Name           string `json:"name,omitempty"`
+	Kind           string `json:"kind"`
+	BlobCount      int    `json:"blob_count"`
+	PointCount     int    `json:"point_count"`
+	Bytes          int    `json:"bytes"`
+	FirstTimestamp int64  `json:"first_ts"`
+	LastTimestamp  int64  `json:"last_ts"`
+}
+
+// Float is a float64 that encodes NaN and ±Inf as the JSON strings "NaN", "+Inf"
+// and "-Inf", which plain JSON numbers cannot represent.
+type Float float64
+
+// Point is one decoded data point. Value is set for numeric metrics and Text for
+// text metrics.
+type Point struct {
+	Ts    int64   `json:"ts"`
+	Value *Float  `json:"value,omitempty"`
+	Text  *string `json:"text,omitempty"`
+	Tag   string  `json:"tag,omitempty"`
+}
+
+// PointsResponse is the body returned by /v1/points.
+type PointsResponse struct {
+	ID        uint64  `json:"id,omitempty"`
+	Name      string  `json:"name,omitempty"`
+	Kind      string  `json:"kind"`
+	Points    []Point `json:"points"`
+	Truncated bool    `json:"truncated,omitempty"`
+}
+
+// query is a parsed /v1/points or /v1/stream request.
+type query struct {
+	id     uint64
+	name   string
+	byName bool
+	start  int64
+	end    int64
+	limit  int
+}
+
+// New creates a server backed by set.
+//
+// Parameters:
+//   - set: Blob set to serve; replace it later with SetBlobSet
+//   - opts: Optional configuration (WithMaxPoints, WithStreamFlushEvery)
+//
+// Returns:
+//   - *Server: HTTP handler serving the /v1 endpoints
+//   - error: Invalid option error
+func New(set blob.BlobSet, opts ...Option) (*Server, error) {
+	cfg := defaultConfig()
+	if err := options.Apply(&cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	s := &Server{cfg: cfg, mux: http.NewServeMux()}
+	s.set.Store(&set)
+
+	s.mux.HandleFunc("GET /v1/blobs", s.handleBlobs)
+	s.mux.HandleFunc("GET /v1/blobs/{index}", s.handleBlob)
+	s.mux.HandleFunc("GET /v1/metrics", s.handleMetrics)
+	s.mux.HandleFunc("GET /v1/points", s.handlePoints)
+	s.mux.HandleFunc("GET /v1/stream", s.handleStream)
+
+	return s, nil
+}
+
+// MarshalJSON encodes finite values as JSON numbers and NaN/±Inf as strings.
+func (f Float) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	default:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	}
+}
+
+// UnmarshalJSON decodes JSON numbers and the strings produced by MarshalJSON.
+func (f *Float) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid float %q: %w", s, err)
+		}
+		*f = Float(v)
+
+		return nil
+	}
+
+	var v float64
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = Float(v)
+
+	return nil
+}
+
+// BlobSet returns the blob set currently being served.
+func (s *Server) BlobSet() blob.BlobSet {
+	return *s.set.Load()
+}
+
+// SetBlobSet atomically replaces the served blob set. Requests already in flight
+// finish against the previous set.
+func (s *Server) SetBlobSet(set blob.BlobSet) {
+	s.set.Store(&set)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleBlobs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, blobHeaders(s.BlobSet()))
+}
+
+func (s *Server) handleBlob(w http.ResponseWriter, r *http.Request) {
+	headers := blobHeaders(s.BlobSet())
+
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 || index >= len(headers) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("blob %q not found", r.PathValue("index")))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, headers[index])
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	stats := s.BlobSet().Stats()
+
+	metrics := make([]MetricInfo, 0, len(stats.Metrics))
+	for _, m := range stats.Metrics {
+		metrics = append(metrics, MetricInfo{
+			ID:             m.MetricID,
+			Name:           m.Name,
+			Kind:           kindOf(m.IsText),
+			BlobCount:      m.BlobCount,
+			PointCount:     m.PointCount,
+			Bytes:          m.Bytes,
+			FirstTimestamp: m.FirstTimestamp,
+			LastTimestamp:  m.LastTimestamp,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+func (s *Server) handlePoints(w http.ResponseWriter, r *http.Request) {
+	q, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Truncation is reported only when the server cap, not the caller's limit, cut the range.
+	limit, capped := q.limit, q.limit == 0 || q.limit > s.cfg.MaxPoints
+	if capped {
+		limit = s.cfg.MaxPoints
+	}
+
+	kind, points, err := s.points(q)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	resp := PointsResponse{ID: q.id, Name: q.name, Kind: kind, Points: []Point{}}
+	for p := range points {
+		if len(resp.Points) == limit {
+			resp.Truncated = capped
+			break
+		}
+		resp.Points = append(resp.Points, p)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	q, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	_, points, err := s.points(q)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	n := 0
+	for p := range points {
+		if q.limit > 0 && n == q.limit {
+			break
+		}
+		if err := enc.Encode(p); err != nil {
+			return // client went away
+		}
+		n++
+
+		if n%s.cfg.StreamFlushEvery == 0 {
+			if r.Context().Err() != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// points resolves the queried metric and returns its kind and an iterator over the
+// points within [q.start, q.end).
+func (s *Server) points(q query) (string, iter.Seq[Point], error) {
+	set := s.BlobSet()
+
+	var isNumeric, isText bool
+	if q.byName {
+		isNumeric, isText = set.IsNumericMetricByName(q.name), set.IsTextMetricByName(q.name)
+	} else {
+		isNumeric, isText = set.IsNumericMetric(q.id), set.IsTextMetric(q.id)
+	}
+
+	inRange := func(ts int64) bool { return ts >= q.start && ts < q.end }
+
+	switch {
+	case isNumeric:
+		var seq iter.Seq2[int, blob.NumericDataPoint]
+		if q.byName {
+			seq = set.AllNumericsByName(q.name)
+		} else {
+			seq = set.AllNumerics(q.id)
+		}
+
+		return KindNumeric, func(yield func(Point) bool) {
+			for _, dp := range seq {
+				if !inRange(dp.Ts) {
+					continue
+				}
+				v := Float(dp.Val)
+				if !yield(Point{Ts: dp.Ts, Value: &v, Tag: dp.Tag}) {
+					return
+				}
+			}
+		}, nil
+	case isText:
+		var seq iter.Seq2[int, blob.TextDataPoint]
+		if q.byName {
+			seq = set.AllTextsByName(q.name)
+		} else {
+			seq = set.AllTexts(q.id)
+		}
+
+		return KindText, func(yield func(Point) bool) {
+			for _, dp := range seq {
+				if !inRange(dp.Ts) {
+					continue
+				}
+				text := dp.Val
+				if !yield(Point{Ts: dp.Ts, Text: &text, Tag: dp.Tag}) {
+					return
+				}
+			}
+		}, nil
+	default:
+		return "", nil, errUnknownMetric
+	}
+}
+
+// parseQuery parses the metric selector and filters of a points or stream request.
+func parseQuery(r *http.Request) (query, error) {
+	values := r.URL.Query()
+	q := query{start: math.MinInt64, end: math.MaxInt64}
+
+	id, name := values.Get("id"), values.Get("name")
+	switch {
+	case id != "" && name == "":
+		v, err := strconv.ParseUint(id, 0, 64)
+		if err != nil {
+			return q, fmt.Errorf("invalid id %q", id)
+		}
+		q.id = v
+	case name != "" && id == "":
+		q.name, q.byName = name, true
+	default:
+		return q, errMissingMetric
+	}
+
+	for key, dst := range map[string]*int64{"start": &q.start, "end": &q.end} {
+		if raw := values.Get(key); raw != "" {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return q, fmt.Errorf("invalid %s %q", key, raw)
+			}
+			*dst = v
+		}
+	}
+	if q.start >= q.end {
+		return q, fmt.Errorf("invalid range: start %d must be before end %d", q.start, q.end)
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return q, fmt.Errorf("invalid limit %q", raw)
+		}
+		q.limit = v
+	}
+
+	return q, nil
+}
+
+// blobHeaders describes the numeric blobs of set followed by its text blobs.
+func blobHeaders(set blob.BlobSet) []BlobHeader {
+	numerics, texts := set.NumericBlobs(), set.TextBlobs()
+
+	headers := make([]BlobHeader, 0, len(numerics)+len(texts))
+	for _, b := range numerics {
+		headers = append(headers, BlobHeader{
+			Index:             len(headers),
+			Kind:              KindNumeric,
+			StartTime:         b.StartTime(),
+			MetricCount:       b.MetricCount(),
+			TimestampEncoding: b.TimestampEncodingType().String(),
+			ValueEncoding:     b.ValueEncoding().String(),
+			TimestampUnit:     b.TimestampUnit().String(),
+			BigEndian:         b.IsBigEndian(),
+			Tags:              b.HasTag(),
+			MetricNames:       b.HasMetricNames(),
+			V2Layout:          b.IsV2Layout(),
+		})
+	}
+	for _, b := range texts {
+		headers = append(headers, BlobHeader{
+			Index:             len(headers),
+			Kind:              KindText,
+			StartTime:         b.StartTime(),
+			MetricCount:       b.MetricCount(),
+			TimestampEncoding: b.TimestampEncodingType().String(),
+			BigEndian:         b.IsBigEndian(),
+			Tags:              b.HasTag(),
+			MetricNames:       b.HasMetricNames(),
+			V2Layout:          b.IsV2Layout(),
+		})
+	}
+
+	return headers
+}
+
+func kindOf(isText bool) string {
+	if isText {
+		return KindText
+	}
+
+	return KindNumeric
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+)
+
+var testStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestBlobSet(t *testing.T) blob.BlobSet {
+	t.Helper()
+
+	numEncoder, err := blob.NewNumericEncoder(testStart, blob.WithTagsEnabled(true))
+	require.NoError(t, err)
+	require.NoError(t, numEncoder.StartMetricName("cpu", 10))
+	for i := range 10 {
+		v := float64(i)
+		if i == 9 {
+			v = math.NaN()
+		}
+		require.NoError(t, numEncoder.AddDataPoint(testStart.Add(time.Duration(i)*time.Second).UnixMicro(), v, "host=a"))
+	}
+	require.NoError(t, numEncoder.EndMetric())
+	numData, err := numEncoder.Finish()
+	require.NoError(t, err)
+
+	textEncoder, err := blob.NewTextEncoder(testStart)
+	require.NoError(t, err)
+	require.NoError(t, textEncoder.StartMetricID(7, 2))
+	require.NoError(t, textEncoder.AddDataPoint(testStart.UnixMicro(), "ok", ""))
+	require.NoError(t, textEncoder.AddDataPoint(testStart.Add(time.Second).UnixMicro(), "", ""))
+	require.NoError(t, textEncoder.EndMetric())
+	textData, err := textEncoder.Finish()
+	require.NoError(t, err)
+
+	set, err := blob.DecodeBlobSet(numData, textData)
+	require.NoError(t, err)
+
+	return set
+}
+
+func get(t *testing.T, srv http.Handler, target string, out any) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+
+	return rec.Code
+}
+
+func TestServer_Blobs(t *testing.T) {
+	srv, err := New(newTestBlobSet(t))
+	require.NoError(t, err)
+
+	var headers []BlobHeader
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/blobs", &headers))
+	require.Len(t, headers, 2)
+	require.Equal(t, KindNumeric, headers[0].Kind)
+	require.True(t, headers[0].Tags)
+	require.Equal(t, KindText, headers[1].Kind)
+	require.True(t, headers[1].StartTime.Equal(testStart))
+
+	var header BlobHeader
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/blobs/1", &header))
+	require.Equal(t, headers[1], header)
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/v1/blobs/2", nil))
+
+	var metrics []MetricInfo
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/metrics", &metrics))
+	require.Len(t, metrics, 2)
+}
+
+func TestServer_Points(t *testing.T) {
+	srv, err := New(newTestBlobSet(t), WithMaxPoints(5))
+	require.NoError(t, err)
+
+	start := testStart.Add(2 * time.Second).UnixMicro()
+	end := testStart.Add(4 * time.Second).UnixMicro()
+
+	var resp PointsResponse
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/points?name=cpu&start="+itoa(start)+"&end="+itoa(end), &resp))
+	require.Equal(t, KindNumeric, resp.Kind)
+	require.Len(t, resp.Points, 2)
+	require.Equal(t, start, resp.Points[0].Ts)
+	require.InDelta(t, 2.0, float64(*resp.Points[0].Value), 0)
+	require.Equal(t, "host=a", resp.Points[0].Tag)
+	require.False(t, resp.Truncated)
+
+	resp = PointsResponse{}
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/points?name=cpu", &resp))
+	require.Len(t, resp.Points, 5)
+	require.True(t, resp.Truncated, "server cap cuts the range")
+
+	resp = PointsResponse{}
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/points?name=cpu&limit=3", &resp))
+	require.Len(t, resp.Points, 3)
+	require.False(t, resp.Truncated, "caller limit is not truncation")
+
+	resp = PointsResponse{}
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/points?id=0x7", &resp))
+	require.Equal(t, KindText, resp.Kind)
+	require.Len(t, resp.Points, 2)
+	require.Equal(t, "ok", *resp.Points[0].Text)
+	require.Empty(t, *resp.Points[1].Text)
+
+	tests := []struct {
+		name   string
+		target string
+		status int
+	}{
+		{name: "NoSelector", target: "/v1/points", status: http.StatusBadRequest},
+		{name: "BothSelectors", target: "/v1/points?id=7&name=cpu", status: http.StatusBadRequest},
+		{name: "BadID", target: "/v1/points?id=abc", status: http.StatusBadRequest},
+		{name: "BadRange", target: "/v1/points?id=7&start=5&end=5", status: http.StatusBadRequest},
+		{name: "BadLimit", target: "/v1/points?id=7&limit=0", status: http.StatusBadRequest},
+		{name: "UnknownMetric", target: "/v1/points?id=99", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]string
+			require.Equal(t, tt.status, get(t, srv, tt.target, &body))
+			require.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestServer_Stream(t *testing.T) {
+	srv, err := New(newTestBlobSet(t), WithMaxPoints(1), WithStreamFlushEvery(3))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stream?name=cpu", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+	require.True(t, rec.Flushed)
+
+	var points []Point
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var p Point
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+		points = append(points, p)
+	}
+	require.Len(t, points, 10, "streaming is not capped by MaxPoints")
+	require.True(t, math.IsNaN(float64(*points[9].Value)))
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/stream?name=cpu&limit=4", nil))
+	require.Equal(t, 4, strings.Count(rec.Body.String(), "\n"))
+}
+
+func TestServer_SetBlobSet(t *testing.T) {
+	srv, err := New(blob.BlobSet{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, get(t, srv, "/v1/points?name=cpu", nil))
+
+	srv.SetBlobSet(newTestBlobSet(t))
+	require.Equal(t, http.StatusOK, get(t, srv, "/v1/points?name=cpu", nil))
+
+	_, err = New(blob.BlobSet{}, WithMaxPoints(0))
+	require.Error(t, err)
+	_, err = New(blob.BlobSet{}, WithStreamFlushEvery(-1))
+	require.Error(t, err)
+}
+
+func TestFloat_JSON(t *testing.T) {
+	for _, v := range []float64{1.25, 0, math.Inf(1), math.Inf(-1), math.NaN()} {
+		data, err := json.Marshal(Float(v))
+		require.NoError(t, err)
+
+		var got Float
+		require.NoError(t, json.Unmarshal(data, &got))
+		if math.IsNaN(v) {
+			require.True(t, math.IsNaN(float64(got)))
+		} else {
+			require.InDelta(t, v, float64(got), 0)
+		}
+	}
+
+	var f Float
+	require.Error(t, json.Unmarshal([]byte(`"nope"`), &f))
+}
+
+func itoa(v int64) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
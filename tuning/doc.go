@@ -0,0 +1,39 @@
+// Package tuning recommends numeric encoder options by measuring sample data.
+//
+// The best encoding and compression depend on the data: Gorilla suits slowly
+// changing values, ALP suits decimal values, and compression pays off only for
+// larger blobs. Run encodes a sample with every combination of the
+// encoding/compression matrix, measures blob size and encode/decode time, and
+// returns a Report with the recommended encoder options:
+//
+//	sample, err := tuning.SampleFromBlobs(productionBlobs)
+//	if err != nil {
+//	    return err
+//	}
+//
+//	report, err := tuning.Run(sample)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(report) // table of all candidates, smallest first
+//
+//	encoder, err := blob.NewNumericEncoder(startTime, report.EncoderOptions()...)
+//
+// Without production data, Generate and RunGenerated create synthetic samples
+// using the same random-walk model as the tests/measure tool.
+//
+// # Objectives
+//
+// ObjectiveSize (the default) recommends the smallest blob. ObjectiveDecodeSpeed
+// and ObjectiveEncodeSpeed recommend the fastest candidate whose blob is at most
+// SizeTolerance (default 5%) larger than the smallest one. Timings are wall-clock
+// measurements averaged over WithRounds runs, so speed recommendations may vary
+// between runs; size recommendations are deterministic.
+//
+// # Restricting the Matrix
+//
+// The full matrix has 3 timestamp encodings, 4 value encodings and 4 compressions
+// per payload, i.e. 192 candidates. WithTimestampEncodings, WithValueEncodings
+// and WithCompressions restrict it, and WithEncoderOptions applies production
+// settings such as the endianness or timestamp unit to every candidate.
+package tuning
@@ -0,0 +1,128 @@
+package tuning
+
+import (
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// Objective selects what the recommended candidate optimizes for.
+type Objective uint8
+
+const (
+	// ObjectiveSize recommends the candidate with the smallest blob. Ties are
+	// broken by matrix order, so the recommendation is deterministic.
+	ObjectiveSize Objective = iota
+	// ObjectiveDecodeSpeed recommends the fastest decoding candidate among those
+	// within the size tolerance of the smallest blob.
+	ObjectiveDecodeSpeed
+	// ObjectiveEncodeSpeed recommends the fastest encoding candidate among those
+	// within the size tolerance of the smallest blob.
+	ObjectiveEncodeSpeed
+)
+
+// String returns the name of the objective.
+func (o Objective) String() string {
+	switch o {
+	case ObjectiveSize:
+		return "Size"
+	case ObjectiveDecodeSpeed:
+		return "DecodeSpeed"
+	case ObjectiveEncodeSpeed:
+		return "EncodeSpeed"
+	default:
+		return "Unknown"
+	}
+}
+
+// DefaultSizeTolerance is the default fraction by which a speed-optimized
+// recommendation may exceed the smallest blob size.
+const DefaultSizeTolerance = 0.05
+
+// Config holds the tuning matrix and recommendation parameters.
+type Config struct {
+	// TimestampEncodings are the timestamp encodings to measure.
+	TimestampEncodings []format.EncodingType
+	// ValueEncodings are the value encodings to measure.
+	ValueEncodings []format.EncodingType
+	// Compressions are the compressions to measure, for both timestamp and value payloads.
+	Compressions []format.CompressionType
+	// EncoderOptions are applied to every candidate before its own options.
+	EncoderOptions []blob.NumericEncoderOption
+	// Objective selects the recommended candidate.
+	Objective Objective
+	// SizeTolerance is the fraction by which speed objectives may exceed the smallest size.
+	SizeTolerance float64
+	// Rounds is the number of encode and decode runs averaged per candidate.
+	Rounds int
+}
+
+// defaultConfig returns the full encoding/compression matrix optimized for size.
+func defaultConfig() Config {
+	return Config{
+		TimestampEncodings: []format.EncodingType{format.TypeRaw, format.TypeDelta, format.TypeDeltaPacked},
+		ValueEncodings:     []format.EncodingType{format.TypeRaw, format.TypeGorilla, format.TypeChimp, format.TypeALP},
+		Compressions: []format.CompressionType{
+			format.CompressionNone, format.CompressionZstd, format.CompressionS2, format.CompressionLZ4,
+		},
+		Objective:     ObjectiveSize,
+		SizeTolerance: DefaultSizeTolerance,
+		Rounds:        1,
+	}
+}
+
+// Option is a functional option for Config.
+type Option = options.Option[*Config]
+
+// WithTimestampEncodings restricts the timestamp encodings of the matrix.
+func WithTimestampEncodings(encs ...format.EncodingType) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.TimestampEncodings = encs
+	})
+}
+
+// WithValueEncodings restricts the value encodings of the matrix.
+func WithValueEncodings(encs ...format.EncodingType) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.ValueEncodings = encs
+	})
+}
+
+// WithCompressions restricts the compressions of the matrix. Timestamp and value
+// payload compressions are combined independently.
+func WithCompressions(comps ...format.CompressionType) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.Compressions = comps
+	})
+}
+
+// WithEncoderOptions sets encoder options applied to every candidate, such as
+// the endianness or timestamp unit used in production.
+func WithEncoderOptions(opts ...blob.NumericEncoderOption) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.EncoderOptions = opts
+	})
+}
+
+// WithObjective sets the objective of the recommendation.
+func WithObjective(obj Objective) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.Objective = obj
+	})
+}
+
+// WithSizeTolerance sets the fraction by which speed objectives may exceed the
+// smallest blob size, e.g. 0.1 = 10%. Negative values are treated as zero.
+func WithSizeTolerance(tolerance float64) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.SizeTolerance = max(tolerance, 0)
+	})
+}
+
+// WithRounds sets the number of encode and decode runs averaged per candidate.
+// Values below 1 are treated as 1.
+func WithRounds(rounds int) Option {
+	return options.NoError(func(cfg *Config) {
+		cfg.Rounds = max(rounds, 1)
+	})
+}
@@ -0,0 +1,160 @@
+package tuning
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/format"
+)
+
+// Candidate is one combination of the encoding/compression matrix.
+type Candidate struct {
+	TimestampEncoding    format.EncodingType
+	ValueEncoding        format.EncodingType
+	TimestampCompression format.CompressionType
+	ValueCompression     format.CompressionType
+}
+
+// Options returns the encoder options selecting the candidate.
+//
+// Returns:
+//   - []blob.NumericEncoderOption: Timestamp/value encoding and compression options
+func (c Candidate) Options() []blob.NumericEncoderOption {
+	return []blob.NumericEncoderOption{
+		blob.WithTimestampEncoding(c.TimestampEncoding),
+		blob.WithValueEncoding(c.ValueEncoding),
+		blob.WithTimestampCompression(c.TimestampCompression),
+		blob.WithValueCompression(c.ValueCompression),
+	}
+}
+
+// String returns a compact description such as "Delta/Gorilla ts=None val=Zstd".
+func (c Candidate) String() string {
+	return fmt.Sprintf("%s/%s ts=%s val=%s",
+		c.TimestampEncoding, c.ValueEncoding, c.TimestampCompression, c.ValueCompression)
+}
+
+// Result holds the measurements of one candidate.
+type Result struct {
+	Candidate
+
+	// BlobSize is the size of the encoded sample in bytes.
+	BlobSize int
+	// BytesPerPoint is BlobSize divided by the number of data points.
+	BytesPerPoint float64
+	// CompressionRatio is the raw baseline size divided by BlobSize.
+	CompressionRatio float64
+	// SavingsPercent is the saving against 16 bytes per point (8-byte timestamp and value).
+	SavingsPercent float64
+	// EncodeTime is the average time to encode the sample.
+	EncodeTime time.Duration
+	// DecodeTime is the average time to decode the blob and iterate all data points.
+	DecodeTime time.Duration
+}
+
+// Report is the outcome of a tuning run.
+type Report struct {
+	// Objective is the objective used to pick Best.
+	Objective Objective
+	// SizeTolerance is the size tolerance used by speed objectives.
+	SizeTolerance float64
+	// NumMetrics is the number of metrics of the sample.
+	NumMetrics int
+	// TotalPoints is the number of data points of the sample.
+	TotalPoints int
+	// RawSize is the blob size of the Raw/Raw baseline without compression.
+	RawSize int
+	// Results holds one result per candidate in matrix order.
+	Results []Result
+	// Best is the recommended candidate.
+	Best Result
+}
+
+// EncoderOptions returns the encoder options of the recommended candidate. Append
+// them after any production options, such as the endianness, when creating
+// encoders.
+//
+// Returns:
+//   - []blob.NumericEncoderOption: The recommended encoding and compression options
+//
+// Example:
+//
+//	report, _ := tuning.Run(sample)
+//	encoder, err := blob.NewNumericEncoder(startTime, report.EncoderOptions()...)
+func (r *Report) EncoderOptions() []blob.NumericEncoderOption {
+	return r.Best.Options()
+}
+
+// BySize returns the results ordered by blob size, smallest first. Results of
+// equal size keep their matrix order.
+//
+// Returns:
+//   - []Result: A sorted copy of Results
+func (r *Report) BySize() []Result {
+	results := slices.Clone(r.Results)
+	slices.SortStableFunc(results, func(a, b Result) int {
+		return cmp.Compare(a.BlobSize, b.BlobSize)
+	})
+
+	return results
+}
+
+// String renders the results ordered by blob size as a table, marking the
+// recommended candidate with '*'.
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Tuning report: %d metrics, %d points, raw %d bytes, objective %s\n",
+		r.NumMetrics, r.TotalPoints, r.RawSize, r.Objective)
+	fmt.Fprintf(&sb, "  %-40s %10s %8s %7s %8s %12s %12s\n",
+		"Candidate", "Bytes", "B/point", "Ratio", "Savings", "Encode", "Decode")
+
+	for _, res := range r.BySize() {
+		mark := ' '
+		if res.Candidate == r.Best.Candidate {
+			mark = '*'
+		}
+		fmt.Fprintf(&sb, "%c %-40s %10d %8.2f %6.2fx %7.1f%% %12s %12s\n",
+			mark, res.Candidate, res.BlobSize, res.BytesPerPoint, res.CompressionRatio,
+			res.SavingsPercent, res.EncodeTime, res.DecodeTime)
+	}
+
+	return sb.String()
+}
+
+// recommend picks the best result for the objective.
+func recommend(results []Result, obj Objective, tolerance float64) Result {
+	best := results[0]
+	for _, res := range results[1:] {
+		if res.BlobSize < best.BlobSize {
+			best = res
+		}
+	}
+	if obj == ObjectiveSize {
+		return best
+	}
+
+	limit := float64(best.BlobSize) * (1 + tolerance)
+	for _, res := range results {
+		if float64(res.BlobSize) > limit {
+			continue
+		}
+
+		switch obj {
+		case ObjectiveDecodeSpeed:
+			if res.DecodeTime < best.DecodeTime {
+				best = res
+			}
+		case ObjectiveEncodeSpeed:
+			if res.EncodeTime < best.EncodeTime {
+				best = res
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,184 @@
+package tuning
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/internal/hash"
+)
+
+// Series is the sample data of one metric.
+type Series struct {
+	// MetricID is the metric ID, must be non-zero and unique within a sample.
+	MetricID uint64
+
+	// Timestamps are the data point timestamps in microseconds.
+	Timestamps []int64
+
+	// Values are the data point values, one per timestamp.
+	Values []float64
+
+	// Tags are the optional data point tags. When any series of a sample has
+	// tags, every candidate is encoded with tags enabled.
+	Tags []string
+}
+
+// Len returns the number of data points of the series.
+func (s Series) Len() int {
+	return len(s.Timestamps)
+}
+
+// validate checks that the series can be encoded.
+func (s Series) validate() error {
+	if s.MetricID == 0 {
+		return fmt.Errorf("%w: zero metric ID", errs.ErrInvalidTuningSample)
+	}
+
+	if len(s.Timestamps) == 0 {
+		return fmt.Errorf("%w: metric %016x has no data points", errs.ErrInvalidTuningSample, s.MetricID)
+	}
+
+	if len(s.Values) != len(s.Timestamps) {
+		return fmt.Errorf("%w: metric %016x has %d timestamps but %d values",
+			errs.ErrInvalidTuningSample, s.MetricID, len(s.Timestamps), len(s.Values))
+	}
+
+	if len(s.Tags) != 0 && len(s.Tags) != len(s.Timestamps) {
+		return fmt.Errorf("%w: metric %016x has %d timestamps but %d tags",
+			errs.ErrInvalidTuningSample, s.MetricID, len(s.Timestamps), len(s.Tags))
+	}
+
+	return nil
+}
+
+// GeneratorConfig configures synthetic sample data for Generate.
+type GeneratorConfig struct {
+	// NumMetrics is the number of metrics to generate.
+	NumMetrics int
+
+	// Points is the number of data points per metric.
+	Points int
+
+	// ValueJitter is the random walk step of the values as a percentage of the
+	// current value, e.g. 5.0 = ±5%.
+	ValueJitter float64
+
+	// TimestampJitter is the deviation from the 1-second interval as a percentage
+	// of the interval, e.g. 2.0 = ±2%.
+	TimestampJitter float64
+
+	// Seed makes the generated data reproducible.
+	Seed uint64
+}
+
+// generatorStartTime is the fixed start time of generated samples.
+var generatorStartTime = time.Unix(1700000000, 0)
+
+// Generate creates synthetic sample data resembling typical monitoring metrics:
+// 1-second intervals with timestamp jitter and random-walk values.
+//
+// The data matches the generator of the tests/measure tool, so recommendations
+// can be compared with its published measurements.
+//
+// Parameters:
+//   - cfg: Generator configuration
+//
+// Returns:
+//   - []Series: NumMetrics series with Points data points each
+//
+// Example:
+//
+//	sample := tuning.Generate(tuning.GeneratorConfig{
+//	    NumMetrics:      200,
+//	    Points:          100,
+//	    ValueJitter:     5.0,
+//	    TimestampJitter: 2.0,
+//	    Seed:            42,
+//	})
+func Generate(cfg GeneratorConfig) []Series {
+	rng := rand.New(rand.NewPCG(cfg.Seed, 0)) //nolint: gosec // reproducible test data, not security sensitive
+
+	baseInterval := time.Second
+	jitterPercent := cfg.TimestampJitter / 100.0
+	deltaPercent := cfg.ValueJitter / 100.0
+
+	sample := make([]Series, 0, max(cfg.NumMetrics, 0))
+	for i := range max(cfg.NumMetrics, 0) {
+		s := Series{
+			MetricID:   hash.ID(fmt.Sprintf("metric.%d", i+1000)),
+			Timestamps: make([]int64, cfg.Points),
+			Values:     make([]float64, cfg.Points),
+		}
+
+		currentTime := generatorStartTime
+		currentValue := 100.0 + float64(i)*10.0
+		for j := range cfg.Points {
+			jitterFactor := (rng.Float64()*2.0 - 1.0) * jitterPercent
+			currentTime = currentTime.Add(baseInterval + time.Duration(float64(baseInterval)*jitterFactor))
+			s.Timestamps[j] = currentTime.UnixMicro()
+
+			deltaFactor := (rng.Float64()*2.0 - 1.0) * deltaPercent
+			currentValue += currentValue * deltaFactor
+			s.Values[j] = currentValue
+		}
+
+		sample = append(sample, s)
+	}
+
+	return sample
+}
+
+// SampleFromBlobs extracts sample data from encoded numeric blobs, so production
+// data can be re-tuned. Data points of a metric spread over several blobs are
+// concatenated in blob order.
+//
+// Timestamps are returned as stored; blobs encoded with a timestamp unit other
+// than microseconds should be tuned with the same unit via WithEncoderOptions.
+//
+// Parameters:
+//   - blobs: Numeric blobs to extract the sample from
+//
+// Returns:
+//   - []Series: One series per metric, in the order the blobs list them
+//   - error: ErrInvalidTuningSample if the blobs hold no data points
+func SampleFromBlobs(blobs []blob.NumericBlob) ([]Series, error) {
+	byID := make(map[uint64]int)
+	var sample []Series
+	var tagged []bool
+
+	for _, b := range blobs {
+		for _, metricID := range b.MetricIDs() {
+			i, ok := byID[metricID]
+			if !ok {
+				i = len(sample)
+				byID[metricID] = i
+				sample = append(sample, Series{MetricID: metricID})
+				tagged = append(tagged, false)
+			}
+
+			s := &sample[i]
+			for _, dp := range b.All(metricID) {
+				s.Timestamps = append(s.Timestamps, dp.Ts)
+				s.Values = append(s.Values, dp.Val)
+				s.Tags = append(s.Tags, dp.Tag)
+				tagged[i] = tagged[i] || dp.Tag != ""
+			}
+		}
+	}
+
+	// Drop the tags of series without any tagged data point.
+	for i := range sample {
+		if !tagged[i] {
+			sample[i].Tags = nil
+		}
+	}
+
+	if len(sample) == 0 {
+		return nil, fmt.Errorf("%w: no data points in blobs", errs.ErrInvalidTuningSample)
+	}
+
+	return sample, nil
+}
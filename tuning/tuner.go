@@ -0,0 +1,262 @@
+package tuning
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+	"github.com/arloliu/mebo/internal/options"
+)
+
+// Run encodes the sample with every candidate of the encoding/compression
+// matrix, measures blob size and encode/decode time, and recommends the best
+// candidate for the configured objective.
+//
+// By default the full matrix of timestamp encodings (Raw, Delta, DeltaPacked),
+// value encodings (Raw, Gorilla, Chimp, ALP) and compressions (None, Zstd, S2,
+// LZ4) for each payload is measured, and the smallest blob is recommended.
+// The sample is encoded as a single blob, so it should resemble the blobs
+// written in production in both metric count and points per metric.
+//
+// Parameters:
+//   - sample: Series to encode, see Generate and SampleFromBlobs
+//   - opts: Options restricting the matrix or changing the objective
+//
+// Returns:
+//   - *Report: Measurements of all candidates and the recommendation
+//   - error: ErrInvalidTuningSample, ErrInvalidTuningConfig, or an encoding error
+//
+// Example:
+//
+//	report, err := tuning.Run(sample, tuning.WithObjective(tuning.ObjectiveDecodeSpeed))
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(report)
+//	encoder, err := blob.NewNumericEncoder(startTime, report.EncoderOptions()...)
+func Run(sample []Series, opts ...Option) (*Report, error) {
+	cfg := defaultConfig()
+	if err := options.Apply(&cfg, opts...); err != nil {
+		return nil, err
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	totalPoints, tagged, err := validateSample(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	t := tuner{cfg: cfg, sample: sample, start: startTime(sample), totalPoints: totalPoints, tagged: tagged}
+
+	raw := Candidate{
+		TimestampEncoding:    format.TypeRaw,
+		ValueEncoding:        format.TypeRaw,
+		TimestampCompression: format.CompressionNone,
+		ValueCompression:     format.CompressionNone,
+	}
+	rawData, err := t.encode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("raw baseline: %w", err)
+	}
+
+	report := &Report{
+		Objective:     cfg.Objective,
+		SizeTolerance: cfg.SizeTolerance,
+		NumMetrics:    len(sample),
+		TotalPoints:   totalPoints,
+		RawSize:       len(rawData),
+	}
+
+	for _, tsEnc := range cfg.TimestampEncodings {
+		for _, valEnc := range cfg.ValueEncodings {
+			for _, tsComp := range cfg.Compressions {
+				for _, valComp := range cfg.Compressions {
+					c := Candidate{
+						TimestampEncoding:    tsEnc,
+						ValueEncoding:        valEnc,
+						TimestampCompression: tsComp,
+						ValueCompression:     valComp,
+					}
+
+					res, err := t.measure(c, report.RawSize)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", c, err)
+					}
+					report.Results = append(report.Results, res)
+				}
+			}
+		}
+	}
+
+	report.Best = recommend(report.Results, cfg.Objective, cfg.SizeTolerance)
+
+	return report, nil
+}
+
+// RunGenerated runs the tuning matrix on synthetic data created by Generate.
+//
+// Parameters:
+//   - gen: Generator configuration of the sample
+//   - opts: Options restricting the matrix or changing the objective
+//
+// Returns:
+//   - *Report: Measurements of all candidates and the recommendation
+//   - error: ErrInvalidTuningSample, ErrInvalidTuningConfig, or an encoding error
+func RunGenerated(gen GeneratorConfig, opts ...Option) (*Report, error) {
+	return Run(Generate(gen), opts...)
+}
+
+// validateConfig checks that the matrix is non-empty and the objective is known.
+func validateConfig(cfg Config) error {
+	if len(cfg.TimestampEncodings) == 0 || len(cfg.ValueEncodings) == 0 || len(cfg.Compressions) == 0 {
+		return fmt.Errorf("%w: empty candidate matrix", errs.ErrInvalidTuningConfig)
+	}
+
+	if cfg.Objective > ObjectiveEncodeSpeed {
+		return fmt.Errorf("%w: unknown objective %d", errs.ErrInvalidTuningConfig, cfg.Objective)
+	}
+
+	return nil
+}
+
+// validateSample checks the sample and returns its point count and whether any
+// series carries tags.
+func validateSample(sample []Series) (int, bool, error) {
+	if len(sample) == 0 {
+		return 0, false, fmt.Errorf("%w: no series", errs.ErrInvalidTuningSample)
+	}
+
+	seen := make(map[uint64]struct{}, len(sample))
+	totalPoints := 0
+	tagged := false
+	for _, s := range sample {
+		if err := s.validate(); err != nil {
+			return 0, false, err
+		}
+
+		if _, ok := seen[s.MetricID]; ok {
+			return 0, false, fmt.Errorf("%w: duplicate metric %016x", errs.ErrInvalidTuningSample, s.MetricID)
+		}
+		seen[s.MetricID] = struct{}{}
+
+		totalPoints += s.Len()
+		tagged = tagged || len(s.Tags) != 0
+	}
+
+	return totalPoints, tagged, nil
+}
+
+// tuner encodes and decodes one sample with different candidates.
+type tuner struct {
+	cfg         Config
+	sample      []Series
+	start       time.Time
+	totalPoints int
+	tagged      bool
+}
+
+// measure encodes and decodes the sample Rounds times with the candidate.
+func (t tuner) measure(c Candidate, rawSize int) (Result, error) {
+	var data []byte
+	var encodeTime, decodeTime time.Duration
+
+	for range t.cfg.Rounds {
+		start := time.Now()
+		encoded, err := t.encode(c)
+		if err != nil {
+			return Result{}, err
+		}
+		encodeTime += time.Since(start)
+		data = encoded
+
+		start = time.Now()
+		if err := t.decode(data); err != nil {
+			return Result{}, err
+		}
+		decodeTime += time.Since(start)
+	}
+
+	size := len(data)
+	baseline := t.totalPoints * 16
+
+	return Result{
+		Candidate:        c,
+		BlobSize:         size,
+		BytesPerPoint:    float64(size) / float64(t.totalPoints),
+		CompressionRatio: float64(rawSize) / float64(size),
+		SavingsPercent:   (1.0 - float64(size)/float64(baseline)) * 100.0,
+		EncodeTime:       encodeTime / time.Duration(t.cfg.Rounds),
+		DecodeTime:       decodeTime / time.Duration(t.cfg.Rounds),
+	}, nil
+}
+
+// encode encodes the sample as one blob with the candidate's options.
+func (t tuner) encode(c Candidate) ([]byte, error) {
+	opts := slices.Concat(t.cfg.EncoderOptions, c.Options())
+	if t.tagged {
+		opts = append(opts, blob.WithTagsEnabled(true))
+	}
+
+	encoder, err := blob.NewNumericEncoder(t.start, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range t.sample {
+		if err := encoder.StartMetricID(s.MetricID, s.Len()); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.AddDataPoints(s.Timestamps, s.Values, s.Tags); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.EndMetric(); err != nil {
+			return nil, err
+		}
+	}
+
+	return encoder.Finish()
+}
+
+// decode decodes the blob and iterates all data points, checking the point count.
+func (t tuner) decode(data []byte) error {
+	decoder, err := blob.NewNumericDecoder(data)
+	if err != nil {
+		return err
+	}
+
+	b, err := decoder.Decode()
+	if err != nil {
+		return err
+	}
+
+	points := 0
+	for _, s := range t.sample {
+		for range b.All(s.MetricID) {
+			points++
+		}
+	}
+
+	if points != t.totalPoints {
+		return fmt.Errorf("%w: decoded %d of %d points", errs.ErrDataPointCountMismatch, points, t.totalPoints)
+	}
+
+	return nil
+}
+
+// startTime returns the earliest timestamp of the sample as blob start time.
+func startTime(sample []Series) time.Time {
+	earliest := sample[0].Timestamps[0]
+	for _, s := range sample {
+		earliest = min(earliest, slices.Min(s.Timestamps))
+	}
+
+	return time.UnixMicro(earliest)
+}
@@ -0,0 +1,197 @@
+package tuning
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/arloliu/mebo/blob"
+	"github.com/arloliu/mebo/errs"
+	"github.com/arloliu/mebo/format"
+)
+
+func testSample() []Series {
+	return Generate(GeneratorConfig{
+		NumMetrics:      20,
+		Points:          100,
+		ValueJitter:     2.0,
+		TimestampJitter: 1.0,
+		Seed:            7,
+	})
+}
+
+func TestRun_RecommendsSmallest(t *testing.T) {
+	sample := testSample()
+
+	report, err := Run(sample,
+		WithTimestampEncodings(format.TypeRaw, format.TypeDelta),
+		WithValueEncodings(format.TypeRaw, format.TypeGorilla),
+		WithCompressions(format.CompressionNone, format.CompressionZstd),
+	)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 16)
+	require.Equal(t, 20, report.NumMetrics)
+	require.Equal(t, 2000, report.TotalPoints)
+
+	for _, res := range report.Results {
+		require.GreaterOrEqual(t, res.BlobSize, report.Best.BlobSize)
+		require.Greater(t, res.CompressionRatio, 0.0)
+	}
+
+	// The Raw/Raw uncompressed candidate is the baseline itself.
+	require.Equal(t, report.RawSize, report.Results[0].BlobSize)
+	require.InDelta(t, 1.0, report.Results[0].CompressionRatio, 1e-9)
+	require.NotEqual(t, format.TypeRaw, report.Best.TimestampEncoding)
+
+	// The recommended options reproduce the measured blob size.
+	encoder, err := blob.NewNumericEncoder(time.UnixMicro(sample[0].Timestamps[0]), report.EncoderOptions()...)
+	require.NoError(t, err)
+	for _, s := range sample {
+		require.NoError(t, encoder.StartMetricID(s.MetricID, s.Len()))
+		require.NoError(t, encoder.AddDataPoints(s.Timestamps, s.Values, nil))
+		require.NoError(t, encoder.EndMetric())
+	}
+	data, err := encoder.Finish()
+	require.NoError(t, err)
+	require.Len(t, data, report.Best.BlobSize)
+}
+
+func TestRun_FullMatrix(t *testing.T) {
+	report, err := RunGenerated(GeneratorConfig{NumMetrics: 4, Points: 50, ValueJitter: 5.0, Seed: 1})
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3*4*4*4)
+
+	bySize := report.BySize()
+	require.Equal(t, report.Best.BlobSize, bySize[0].BlobSize)
+	for i := 1; i < len(bySize); i++ {
+		require.LessOrEqual(t, bySize[i-1].BlobSize, bySize[i].BlobSize)
+	}
+
+	out := report.String()
+	require.Contains(t, out, "* "+report.Best.Candidate.String())
+	require.Equal(t, len(report.Results)+2, strings.Count(out, "\n"))
+}
+
+func TestRun_SpeedObjectiveWithinTolerance(t *testing.T) {
+	report, err := Run(testSample(),
+		WithValueEncodings(format.TypeGorilla, format.TypeChimp),
+		WithCompressions(format.CompressionNone, format.CompressionS2),
+		WithObjective(ObjectiveDecodeSpeed),
+		WithSizeTolerance(0.2),
+		WithRounds(2),
+	)
+	require.NoError(t, err)
+	require.Equal(t, ObjectiveDecodeSpeed, report.Objective)
+
+	smallest := report.BySize()[0]
+	require.LessOrEqual(t, float64(report.Best.BlobSize), float64(smallest.BlobSize)*1.2)
+	for _, res := range report.Results {
+		if float64(res.BlobSize) <= float64(smallest.BlobSize)*1.2 {
+			require.GreaterOrEqual(t, res.DecodeTime, report.Best.DecodeTime)
+		}
+	}
+}
+
+func TestRun_Tags(t *testing.T) {
+	sample := testSample()[:2]
+	sample[0].Tags = make([]string, sample[0].Len())
+	sample[0].Tags[3] = "host=a"
+
+	report, err := Run(sample, WithCompressions(format.CompressionNone))
+	require.NoError(t, err)
+	require.Len(t, report.Results, 12)
+}
+
+func TestRun_InvalidSample(t *testing.T) {
+	valid := testSample()[0]
+
+	tests := []struct {
+		name   string
+		sample []Series
+	}{
+		{name: "empty", sample: nil},
+		{name: "zero metric ID", sample: []Series{{Timestamps: valid.Timestamps, Values: valid.Values}}},
+		{name: "no points", sample: []Series{{MetricID: 1}}},
+		{name: "value count mismatch", sample: []Series{{MetricID: 1, Timestamps: valid.Timestamps, Values: valid.Values[1:]}}},
+		{name: "tag count mismatch", sample: []Series{{MetricID: 1, Timestamps: valid.Timestamps, Values: valid.Values, Tags: []string{"a"}}}},
+		{name: "duplicate metric", sample: []Series{valid, valid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(tt.sample)
+			require.ErrorIs(t, err, errs.ErrInvalidTuningSample)
+		})
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	_, err := Run(testSample(), WithCompressions())
+	require.ErrorIs(t, err, errs.ErrInvalidTuningConfig)
+
+	_, err = Run(testSample(), WithObjective(Objective(42)))
+	require.ErrorIs(t, err, errs.ErrInvalidTuningConfig)
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	cfg := GeneratorConfig{NumMetrics: 3, Points: 10, ValueJitter: 5.0, TimestampJitter: 2.0, Seed: 42}
+
+	a := Generate(cfg)
+	require.Equal(t, a, Generate(cfg))
+	require.Len(t, a, 3)
+
+	for _, s := range a {
+		require.NotZero(t, s.MetricID)
+		require.Len(t, s.Values, 10)
+		for i := 1; i < s.Len(); i++ {
+			require.Greater(t, s.Timestamps[i], s.Timestamps[i-1])
+		}
+	}
+
+	cfg.Seed = 43
+	require.NotEqual(t, a, Generate(cfg))
+}
+
+func TestSampleFromBlobs(t *testing.T) {
+	encode := func(start int64, withTags bool) blob.NumericBlob {
+		encoder, err := blob.NewNumericEncoder(time.UnixMicro(start), blob.WithTagsEnabled(withTags))
+		require.NoError(t, err)
+		for id := uint64(1); id <= 2; id++ {
+			require.NoError(t, encoder.StartMetricID(id, 2))
+			require.NoError(t, encoder.AddDataPoint(start, float64(id), ""))
+			tag := ""
+			if withTags && id == 1 {
+				tag = "t"
+			}
+			require.NoError(t, encoder.AddDataPoint(start+1, float64(id), tag))
+			require.NoError(t, encoder.EndMetric())
+		}
+		data, err := encoder.Finish()
+		require.NoError(t, err)
+
+		decoder, err := blob.NewNumericDecoder(data)
+		require.NoError(t, err)
+		b, err := decoder.Decode()
+		require.NoError(t, err)
+
+		return b
+	}
+
+	sample, err := SampleFromBlobs([]blob.NumericBlob{encode(100, false), encode(200, true)})
+	require.NoError(t, err)
+	require.Len(t, sample, 2)
+
+	if sample[0].MetricID != 1 {
+		sample[0], sample[1] = sample[1], sample[0]
+	}
+	require.Equal(t, uint64(1), sample[0].MetricID)
+	require.Equal(t, []int64{100, 101, 200, 201}, sample[0].Timestamps)
+	require.Equal(t, []string{"", "", "", "t"}, sample[0].Tags)
+	require.Equal(t, []float64{2, 2, 2, 2}, sample[1].Values)
+	require.Nil(t, sample[1].Tags)
+
+	_, err = SampleFromBlobs(nil)
+	require.ErrorIs(t, err, errs.ErrInvalidTuningSample)
+}